@@ -20,12 +20,14 @@ import (
 	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
 	"github.com/rudderlabs/rudder-server/gateway"
 	gwThrottler "github.com/rudderlabs/rudder-server/gateway/throttler"
+	"github.com/rudderlabs/rudder-server/internal/dlq"
 	drain_config "github.com/rudderlabs/rudder-server/internal/drain-config"
 	"github.com/rudderlabs/rudder-server/internal/pulsar"
 	"github.com/rudderlabs/rudder-server/jobsdb"
 	"github.com/rudderlabs/rudder-server/processor"
 	"github.com/rudderlabs/rudder-server/router"
 	"github.com/rudderlabs/rudder-server/router/batchrouter"
+	"github.com/rudderlabs/rudder-server/router/circuitbreaker"
 	routerManager "github.com/rudderlabs/rudder-server/router/manager"
 	rtThrottler "github.com/rudderlabs/rudder-server/router/throttler"
 	schema_forwarder "github.com/rudderlabs/rudder-server/schema-forwarder"
@@ -33,6 +35,7 @@ import (
 	sourcedebugger "github.com/rudderlabs/rudder-server/services/debugger/source"
 	transformationdebugger "github.com/rudderlabs/rudder-server/services/debugger/transformation"
 	"github.com/rudderlabs/rudder-server/services/fileuploader"
+	"github.com/rudderlabs/rudder-server/services/secrets"
 	"github.com/rudderlabs/rudder-server/services/transformer"
 	"github.com/rudderlabs/rudder-server/services/transientsource"
 	"github.com/rudderlabs/rudder-server/utils/crash"
@@ -155,6 +158,7 @@ func (a *embeddedApp) StartRudderCore(ctx context.Context, options *app.Options)
 		jobsdb.WithClearDB(options.ClearDB),
 		jobsdb.WithDSLimit(a.config.routerDSLimit),
 		jobsdb.WithSkipMaintenanceErr(config.GetBool("Router.jobsDB.skipMaintenanceError", false)),
+		jobsdb.WithColdTierStorage(fileuploader.NewDefaultProvider()),
 	)
 	defer routerDB.Close()
 	batchRouterDB := jobsdb.NewForReadWrite(
@@ -162,17 +166,19 @@ func (a *embeddedApp) StartRudderCore(ctx context.Context, options *app.Options)
 		jobsdb.WithClearDB(options.ClearDB),
 		jobsdb.WithDSLimit(a.config.batchRouterDSLimit),
 		jobsdb.WithSkipMaintenanceErr(config.GetBool("BatchRouter.jobsDB.skipMaintenanceError", false)),
+		jobsdb.WithColdTierStorage(fileuploader.NewDefaultProvider()),
 	)
 	defer batchRouterDB.Close()
 
-	// We need two errorDBs, one in read & one in write mode to support separate gateway to store failures
-	errDBForRead := jobsdb.NewForRead(
+	// We need two errorDBs, one in read & one in write mode to support separate gateway to store failures.
+	// The reading one is writable too, since admin operations update the statuses of its jobs.
+	errDB := jobsdb.NewForReadWrite(
 		"proc_error",
 		jobsdb.WithClearDB(options.ClearDB),
 		jobsdb.WithDSLimit(a.config.processorDSLimit),
 		jobsdb.WithSkipMaintenanceErr(config.GetBool("Processor.jobsDB.skipMaintenanceError", false)),
 	)
-	defer errDBForRead.Close()
+	defer errDB.Close()
 	errDBForWrite := jobsdb.NewForWrite(
 		"proc_error",
 		jobsdb.WithClearDB(options.ClearDB),
@@ -249,7 +255,7 @@ func (a *embeddedApp) StartRudderCore(ctx context.Context, options *app.Options)
 		gwDBForProcessor,
 		routerDB,
 		batchRouterDB,
-		errDBForRead,
+		errDB,
 		errDBForWrite,
 		schemaDB,
 		archivalDB,
@@ -264,12 +270,25 @@ func (a *embeddedApp) StartRudderCore(ctx context.Context, options *app.Options)
 		trackedUsersReporter,
 		processor.WithAdaptiveLimit(adaptiveLimit),
 	)
+	deadLetterQueue, err := setupDeadLetterQueue(ctx, g, config, a.log.Child("dlq"), routerDB, batchRouterDB)
+	if err != nil {
+		return err
+	}
+	var deadLetterQueueWriter dlq.Writer
+	if deadLetterQueue != nil {
+		defer deadLetterQueue.Stop()
+		deadLetterQueueWriter = deadLetterQueue
+	}
+	jobsDBAdmin := setupJobsDBAdmin(a.log.Child("jobsdb-admin"), reporting, errDBForWrite, deadLetterQueueWriter, gwDBForProcessor, routerDB, batchRouterDB, errDB)
+
+	circuitBreakers := setupCircuitBreakers(config, a.log)
+
 	throttlerFactory, err := rtThrottler.NewFactory(config, stats.Default)
 	if err != nil {
 		return fmt.Errorf("failed to create rt throttler factory: %w", err)
 	}
 	rtFactory := &router.Factory{
-		Logger:                     logger.NewLogger().Child("router"),
+		Logger:                     secrets.NewRedactingLogger(logger.NewLogger().Child("router")),
 		Reporting:                  reporting,
 		BackendConfig:              backendconfig.DefaultBackendConfig,
 		RouterDB:                   routerDB,
@@ -280,6 +299,8 @@ func (a *embeddedApp) StartRudderCore(ctx context.Context, options *app.Options)
 		ThrottlerFactory:           throttlerFactory,
 		Debugger:                   destinationHandle,
 		AdaptiveLimit:              adaptiveLimit,
+		DeadLetterQueue:            deadLetterQueueWriter,
+		CircuitBreakers:            circuitBreakers,
 	}
 	brtFactory := &batchrouter.Factory{
 		Reporting:        reporting,
@@ -290,6 +311,7 @@ func (a *embeddedApp) StartRudderCore(ctx context.Context, options *app.Options)
 		RsourcesService:  rsourcesService,
 		Debugger:         destinationHandle,
 		AdaptiveLimit:    adaptiveLimit,
+		DeadLetterQueue:  deadLetterQueueWriter,
 	}
 	rt := routerManager.New(rtFactory, brtFactory, backendconfig.DefaultBackendConfig, logger.NewLogger())
 
@@ -298,7 +320,7 @@ func (a *embeddedApp) StartRudderCore(ctx context.Context, options *app.Options)
 		GatewayDB:       gwDBForProcessor,
 		RouterDB:        routerDB,
 		BatchRouterDB:   batchRouterDB,
-		ErrorDB:         errDBForRead,
+		ErrorDB:         errDB,
 		EventSchemaDB:   schemaDB,
 		ArchivalDB:      archivalDB,
 		Processor:       proc,
@@ -328,15 +350,27 @@ func (a *embeddedApp) StartRudderCore(ctx context.Context, options *app.Options)
 	g.Go(crash.Wrapper(func() (err error) {
 		return drainConfigManager.CleanupRoutine(ctx)
 	}))
+	internalHttpHandlers := map[string]http.Handler{
+		"/drain":           drainConfigManager.DrainConfigHttpHandler(),
+		"/jobsdb":          jobsDBAdmin.HttpHandler(),
+		"/circuitbreakers": circuitbreaker.NewAdmin(circuitBreakers).HttpHandler(),
+	}
+	if deadLetterQueue != nil {
+		internalHttpHandlers["/dlq"] = deadLetterQueue.HttpHandler()
+	}
+	replayJobs, err := setupReplayJobs(ctx, g, config, a.log.Child("replay-jobs"), gatewayDB, fileUploaderProvider)
+	if err != nil {
+		return err
+	}
+	if replayJobs != nil {
+		defer replayJobs.Stop()
+		internalHttpHandlers["/replay"] = replayJobs.HttpHandler()
+	}
 	streamMsgValidator := stream.NewMessageValidator()
 	gw := gateway.Handle{}
 	err = gw.Setup(ctx, config, logger.NewLogger().Child("gateway"), stats.Default, a.app, backendconfig.DefaultBackendConfig,
 		gatewayDB, errDBForWrite, rateLimiter, a.versionHandler, rsourcesService, transformerFeaturesService, sourceHandle,
-		streamMsgValidator, gateway.WithInternalHttpHandlers(
-			map[string]http.Handler{
-				"/drain": drainConfigManager.DrainConfigHttpHandler(),
-			},
-		))
+		streamMsgValidator, gateway.WithInternalHttpHandlers(internalHttpHandlers))
 	if err != nil {
 		return fmt.Errorf("could not setup gateway: %w", err)
 	}
@@ -349,6 +383,9 @@ func (a *embeddedApp) StartRudderCore(ctx context.Context, options *app.Options)
 	g.Go(func() error {
 		return gw.StartWebHandler(ctx)
 	})
+	g.Go(func() error {
+		return gw.StartGrpcHandler(ctx)
+	})
 	if a.config.enableReplay {
 		var replayDB jobsdb.Handle
 		err := replayDB.Setup(
@@ -151,5 +151,8 @@ func (a *gatewayApp) StartRudderCore(ctx context.Context, options *app.Options)
 	g.Go(func() error {
 		return gw.StartWebHandler(ctx)
 	})
+	g.Go(func() error {
+		return gw.StartGrpcHandler(ctx)
+	})
 	return g.Wait()
 }
@@ -19,18 +19,21 @@ import (
 	"github.com/rudderlabs/rudder-server/app/cluster"
 	"github.com/rudderlabs/rudder-server/archiver"
 	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
+	"github.com/rudderlabs/rudder-server/internal/dlq"
 	drain_config "github.com/rudderlabs/rudder-server/internal/drain-config"
 	"github.com/rudderlabs/rudder-server/internal/pulsar"
 	"github.com/rudderlabs/rudder-server/jobsdb"
 	proc "github.com/rudderlabs/rudder-server/processor"
 	"github.com/rudderlabs/rudder-server/router"
 	"github.com/rudderlabs/rudder-server/router/batchrouter"
+	"github.com/rudderlabs/rudder-server/router/circuitbreaker"
 	routerManager "github.com/rudderlabs/rudder-server/router/manager"
 	"github.com/rudderlabs/rudder-server/router/throttler"
 	schema_forwarder "github.com/rudderlabs/rudder-server/schema-forwarder"
 	destinationdebugger "github.com/rudderlabs/rudder-server/services/debugger/destination"
 	transformationdebugger "github.com/rudderlabs/rudder-server/services/debugger/transformation"
 	"github.com/rudderlabs/rudder-server/services/fileuploader"
+	"github.com/rudderlabs/rudder-server/services/secrets"
 	"github.com/rudderlabs/rudder-server/services/transformer"
 	"github.com/rudderlabs/rudder-server/services/transientsource"
 	"github.com/rudderlabs/rudder-server/utils/crash"
@@ -156,6 +159,7 @@ func (a *processorApp) StartRudderCore(ctx context.Context, options *app.Options
 		jobsdb.WithClearDB(options.ClearDB),
 		jobsdb.WithDSLimit(a.config.routerDSLimit),
 		jobsdb.WithSkipMaintenanceErr(config.GetBool("Router.jobsDB.skipMaintenanceError", false)),
+		jobsdb.WithColdTierStorage(fileuploader.NewDefaultProvider()),
 	)
 	defer routerDB.Close()
 	batchRouterDB := jobsdb.NewForReadWrite(
@@ -163,15 +167,17 @@ func (a *processorApp) StartRudderCore(ctx context.Context, options *app.Options
 		jobsdb.WithClearDB(options.ClearDB),
 		jobsdb.WithDSLimit(a.config.batchRouterDSLimit),
 		jobsdb.WithSkipMaintenanceErr(config.GetBool("BatchRouter.jobsDB.skipMaintenanceError", false)),
+		jobsdb.WithColdTierStorage(fileuploader.NewDefaultProvider()),
 	)
 	defer batchRouterDB.Close()
-	errDBForRead := jobsdb.NewForRead(
+	// errDB is writable, since admin operations update the statuses of its jobs
+	errDB := jobsdb.NewForReadWrite(
 		"proc_error",
 		jobsdb.WithClearDB(options.ClearDB),
 		jobsdb.WithDSLimit(a.config.processorDSLimit),
 		jobsdb.WithSkipMaintenanceErr(config.GetBool("Processor.jobsDB.skipMaintenanceError", false)),
 	)
-	defer errDBForRead.Close()
+	defer errDB.Close()
 	errDBForWrite := jobsdb.NewForWrite(
 		"proc_error",
 		jobsdb.WithClearDB(options.ClearDB),
@@ -249,7 +255,7 @@ func (a *processorApp) StartRudderCore(ctx context.Context, options *app.Options
 		gwDBForProcessor,
 		routerDB,
 		batchRouterDB,
-		errDBForRead,
+		errDB,
 		errDBForWrite,
 		schemaDB,
 		archivalDB,
@@ -264,12 +270,25 @@ func (a *processorApp) StartRudderCore(ctx context.Context, options *app.Options
 		trackedUsersReporter,
 		proc.WithAdaptiveLimit(adaptiveLimit),
 	)
+	deadLetterQueue, err := setupDeadLetterQueue(ctx, g, config, a.log.Child("dlq"), routerDB, batchRouterDB)
+	if err != nil {
+		return err
+	}
+	var deadLetterQueueWriter dlq.Writer
+	if deadLetterQueue != nil {
+		defer deadLetterQueue.Stop()
+		deadLetterQueueWriter = deadLetterQueue
+	}
+	jobsDBAdmin := setupJobsDBAdmin(a.log.Child("jobsdb-admin"), reporting, errDBForWrite, deadLetterQueueWriter, gwDBForProcessor, routerDB, batchRouterDB, errDB)
+
+	circuitBreakers := setupCircuitBreakers(config, a.log)
+
 	throttlerFactory, err := throttler.NewFactory(config, stats.Default)
 	if err != nil {
 		return fmt.Errorf("failed to create throttler factory: %w", err)
 	}
 	rtFactory := &router.Factory{
-		Logger:                     logger.NewLogger().Child("router"),
+		Logger:                     secrets.NewRedactingLogger(logger.NewLogger().Child("router")),
 		Reporting:                  reporting,
 		BackendConfig:              backendconfig.DefaultBackendConfig,
 		RouterDB:                   routerDB,
@@ -280,6 +299,8 @@ func (a *processorApp) StartRudderCore(ctx context.Context, options *app.Options
 		ThrottlerFactory:           throttlerFactory,
 		Debugger:                   destinationHandle,
 		AdaptiveLimit:              adaptiveLimit,
+		DeadLetterQueue:            deadLetterQueueWriter,
+		CircuitBreakers:            circuitBreakers,
 	}
 	brtFactory := &batchrouter.Factory{
 		Reporting:        reporting,
@@ -290,6 +311,7 @@ func (a *processorApp) StartRudderCore(ctx context.Context, options *app.Options
 		RsourcesService:  rsourcesService,
 		Debugger:         destinationHandle,
 		AdaptiveLimit:    adaptiveLimit,
+		DeadLetterQueue:  deadLetterQueueWriter,
 	}
 	rt := routerManager.New(rtFactory, brtFactory, backendconfig.DefaultBackendConfig, logger.NewLogger())
 
@@ -299,7 +321,7 @@ func (a *processorApp) StartRudderCore(ctx context.Context, options *app.Options
 		GatewayDB:        gwDBForProcessor,
 		RouterDB:         routerDB,
 		BatchRouterDB:    batchRouterDB,
-		ErrorDB:          errDBForRead,
+		ErrorDB:          errDB,
 		SchemaForwarder:  schemaForwarder,
 		EventSchemaDB:    schemaDB,
 		ArchivalDB:       archivalDB,
@@ -315,7 +337,10 @@ func (a *processorApp) StartRudderCore(ctx context.Context, options *app.Options
 	}
 
 	g.Go(func() error {
-		return a.startHealthWebHandler(ctx, gwDBForProcessor)
+		return a.startHealthWebHandler(ctx, gwDBForProcessor, map[string]http.Handler{
+			"/jobsdb":          jobsDBAdmin.HttpHandler(),
+			"/circuitbreakers": circuitbreaker.NewAdmin(circuitBreakers).HttpHandler(),
+		})
 	})
 
 	g.Go(func() error {
@@ -339,12 +364,18 @@ func (a *processorApp) StartRudderCore(ctx context.Context, options *app.Options
 	return g.Wait()
 }
 
-func (a *processorApp) startHealthWebHandler(ctx context.Context, db *jobsdb.Handle) error {
+// startHealthWebHandler serves the health endpoints along with the provided internal handlers, which are mounted under /internal, same as in the gateway
+func (a *processorApp) startHealthWebHandler(ctx context.Context, db *jobsdb.Handle, internalHttpHandlers map[string]http.Handler) error {
 	// Port where Processor health handler is running
 	a.log.Infof("Starting in %d", a.config.http.webPort)
 	srvMux := chi.NewMux()
 	srvMux.HandleFunc("/health", app.LivenessHandler(db))
 	srvMux.HandleFunc("/", app.LivenessHandler(db))
+	srvMux.Route("/internal", func(r chi.Router) {
+		for path, handler := range internalHttpHandlers {
+			r.Mount(path, handler)
+		}
+	})
 	srv := &http.Server{
 		Addr:              ":" + strconv.Itoa(a.config.http.webPort),
 		Handler:           crash.Handler(srvMux),
@@ -11,13 +11,22 @@ import (
 	"github.com/rudderlabs/rudder-go-kit/config"
 	"github.com/rudderlabs/rudder-go-kit/logger"
 	"github.com/rudderlabs/rudder-go-kit/stats"
+	"github.com/rudderlabs/rudder-server/admin"
 	"github.com/rudderlabs/rudder-server/app"
 	"github.com/rudderlabs/rudder-server/app/cluster"
 	"github.com/rudderlabs/rudder-server/app/cluster/state"
+	"github.com/rudderlabs/rudder-server/enterprise/replay/replayjob"
+	"github.com/rudderlabs/rudder-server/internal/dlq"
 	"github.com/rudderlabs/rudder-server/internal/enricher"
+	"github.com/rudderlabs/rudder-server/jobsdb"
+	"github.com/rudderlabs/rudder-server/router"
+	"github.com/rudderlabs/rudder-server/router/circuitbreaker"
+	"github.com/rudderlabs/rudder-server/services/fileuploader"
 	"github.com/rudderlabs/rudder-server/services/rsources"
 	"github.com/rudderlabs/rudder-server/services/validators"
+	"github.com/rudderlabs/rudder-server/utils/crash"
 	"github.com/rudderlabs/rudder-server/utils/misc"
+	"github.com/rudderlabs/rudder-server/utils/types"
 	"github.com/rudderlabs/rudder-server/utils/types/deployment"
 	"github.com/rudderlabs/rudder-server/utils/types/servermode"
 )
@@ -152,3 +161,62 @@ func setupPipelineEnrichers(conf *config.Config, log logger.Logger, stats stats.
 
 	return enrichers, nil
 }
+
+// setupDeadLetterQueue sets up the dead-letter queue for aborted router & batch router jobs, if it is enabled.
+// It returns a nil queue if disabled, otherwise the queue needs to be stopped after use.
+func setupDeadLetterQueue(ctx context.Context, g *errgroup.Group, conf *config.Config, log logger.Logger, jobsDBs ...jobsdb.JobsDB) (*dlq.DLQ, error) {
+	if !conf.GetBool("DLQ.enabled", false) {
+		return nil, nil
+	}
+	log.Infof("Setting up the dead-letter queue")
+	deadLetterQueue, err := dlq.New(conf, log)
+	if err != nil {
+		return nil, fmt.Errorf("dead-letter queue setup: %w", err)
+	}
+	for _, jobsDB := range jobsDBs {
+		deadLetterQueue.RegisterJobsDB(jobsDB)
+	}
+	admin.RegisterAdminHandler("DLQ", dlq.NewAdmin(deadLetterQueue))
+	g.Go(crash.Wrapper(func() error {
+		return deadLetterQueue.CleanupRoutine(ctx)
+	}))
+	return deadLetterQueue, nil
+}
+
+// setupJobsDBAdmin exposes inspection and surgery operations of the provided jobsdbs over the admin rpc interface.
+// Jobs of the router and batch router jobsdbs aborted through the admin are handled like the ones aborted by the routers themselves,
+// i.e. they are stored into the error db, reported and copied into the dead-letter queue (if not nil).
+// The returned admin can also be used for exposing them over http.
+func setupJobsDBAdmin(log logger.Logger, reporting types.Reporting, errDBForWrite jobsdb.JobsDB, deadLetterQueue dlq.Writer, gwDB, routerDB, batchRouterDB, errDB *jobsdb.Handle) *jobsdb.Admin {
+	jobsDBAdmin := jobsdb.NewAdmin(log, gwDB, routerDB, batchRouterDB, errDB)
+	jobsDBAdmin.SetAbortHook(routerDB.Identifier(), router.AdminAbortHook(types.ROUTER, routerDB, errDBForWrite, reporting, deadLetterQueue))
+	jobsDBAdmin.SetAbortHook(batchRouterDB.Identifier(), router.AdminAbortHook(types.BATCH_ROUTER, batchRouterDB, errDBForWrite, reporting, deadLetterQueue))
+	admin.RegisterAdminHandler("JobsDB", jobsDBAdmin)
+	return jobsDBAdmin
+}
+
+// setupCircuitBreakers sets up the registry of the router's per-destination circuit breakers and exposes it over the admin rpc interface.
+// Circuit breakers are only used for destination types having Router[.<destType>].circuitBreaker.enabled set.
+func setupCircuitBreakers(conf *config.Config, log logger.Logger) *circuitbreaker.Registry {
+	registry := circuitbreaker.NewRegistry(conf, log, stats.Default)
+	admin.RegisterAdminHandler("RouterCircuitBreakers", circuitbreaker.NewAdmin(registry))
+	return registry
+}
+
+// setupReplayJobs sets up on-demand replays of archived gateway events, if enabled.
+// It returns a nil manager if disabled, otherwise the manager needs to be stopped after use.
+func setupReplayJobs(ctx context.Context, g *errgroup.Group, conf *config.Config, log logger.Logger, gwDB jobsdb.JobsDB, storage fileuploader.Provider) (*replayjob.Manager, error) {
+	if !conf.GetBool("Replay.jobs.enabled", false) {
+		return nil, nil
+	}
+	log.Infof("Setting up replay jobs")
+	manager, err := replayjob.New(conf, log, gwDB, storage)
+	if err != nil {
+		return nil, fmt.Errorf("replay jobs setup: %w", err)
+	}
+	admin.RegisterAdminHandler("ReplayJobs", replayjob.NewAdmin(manager))
+	g.Go(crash.Wrapper(func() error {
+		return manager.Run(ctx)
+	}))
+	return manager, nil
+}
@@ -7,6 +7,7 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/url"
 	"reflect"
@@ -24,6 +25,7 @@ import (
 	"github.com/rudderlabs/rudder-server/rruntime"
 	"github.com/rudderlabs/rudder-server/services/controlplane/identity"
 	"github.com/rudderlabs/rudder-server/services/diagnostics"
+	"github.com/rudderlabs/rudder-server/services/secrets"
 	"github.com/rudderlabs/rudder-server/utils/pubsub"
 	"github.com/rudderlabs/rudder-server/utils/sysUtils"
 	"github.com/rudderlabs/rudder-server/utils/types"
@@ -45,7 +47,7 @@ var (
 
 	// DefaultBackendConfig will be initialized be Setup to either a WorkspaceConfig or MultiWorkspaceConfig.
 	DefaultBackendConfig     BackendConfig
-	pkgLogger                = logger.NewLogger().Child("backend-config")
+	pkgLogger                = secrets.NewRedactingLogger(logger.NewLogger().Child("backend-config"))
 	IoUtil                   = sysUtils.NewIoUtil()
 	Diagnostics              diagnostics.DiagnosticsI
 	cacheOverride            cache.Cache
@@ -90,8 +92,13 @@ type backendConfigImpl struct {
 	curSourceJSONLock sync.RWMutex
 	usingCache        bool
 	cache             cache.Cache
+	secrets           *secrets.Resolver // optional, resolves secret references in destination configs if set
 }
 
+// topicRawBackendConfig provides updates on full backend config, without its secret references resolved.
+// It is used for caching the config, so that resolved secrets are never persisted.
+const topicRawBackendConfig Topic = "rawBackendConfig"
+
 func loadConfig() {
 	configBackendURL = config.GetString("CONFIG_BACKEND_URL", "https://api.rudderstack.com")
 	cpRouterURL = config.GetString("CP_ROUTER_URL", "https://cp-router.rudderlabs.com")
@@ -209,6 +216,8 @@ func (bc *backendConfigImpl) configUpdate(ctx context.Context) {
 			return sourceJSON[workspace].Sources[i].ID < sourceJSON[workspace].Sources[j].ID
 		})
 	}
+	rawSourceJSON := sourceJSON
+	sourceJSON = bc.resolveSecrets(ctx, rawSourceJSON)
 
 	bc.curSourceJSONLock.Lock()
 	if !reflect.DeepEqual(bc.curSourceJSON, sourceJSON) {
@@ -227,6 +236,7 @@ func (bc *backendConfigImpl) configUpdate(ctx context.Context) {
 		bc.curSourceJSON = sourceJSON
 		bc.curSourceJSONLock.Unlock()
 		LastSync = time.Now().Format(time.RFC3339) // TODO fix concurrent access
+		bc.eb.Publish(string(topicRawBackendConfig), rawSourceJSON)
 		bc.eb.Publish(string(TopicBackendConfig), sourceJSON)
 		bc.eb.Publish(string(TopicProcessConfig), filteredSourcesJSON)
 	} else {
@@ -238,6 +248,42 @@ func (bc *backendConfigImpl) configUpdate(ctx context.Context) {
 	bc.initializedLock.Unlock()
 }
 
+// resolveSecrets returns a copy of the config with the secret references of its destination configs resolved.
+// References which cannot be resolved are left in place, with the resolution errors being logged.
+func (bc *backendConfigImpl) resolveSecrets(ctx context.Context, sourceJSON map[string]ConfigT) map[string]ConfigT {
+	if bc.secrets == nil {
+		return sourceJSON
+	}
+	statResolutionErrors := stats.Default.NewStat("config_backend.secret_resolution_errors", stats.CountType)
+	resolved := make(map[string]ConfigT, len(sourceJSON))
+	for workspaceID, wConfig := range sourceJSON {
+		sources := make([]SourceT, len(wConfig.Sources))
+		for i, source := range wConfig.Sources {
+			destinations := make([]DestinationT, len(source.Destinations))
+			for j, destination := range source.Destinations {
+				if secrets.HasReferences(destination.Config) {
+					destinationConfig, err := bc.secrets.Resolve(ctx, destination.Config)
+					if err != nil {
+						statResolutionErrors.Increment()
+						pkgLogger.Errorw("Failed to resolve secrets of destination config",
+							"workspaceId", workspaceID,
+							"destinationId", destination.ID,
+							"error", err,
+						)
+					}
+					destination.Config = destinationConfig.(map[string]interface{})
+				}
+				destinations[j] = destination
+			}
+			source.Destinations = destinations
+			sources[i] = source
+		}
+		wConfig.Sources = sources
+		resolved[workspaceID] = wConfig
+	}
+	return resolved
+}
+
 func (bc *backendConfigImpl) pollConfigUpdate(ctx context.Context) {
 	for {
 		bc.configUpdate(ctx)
@@ -277,6 +323,9 @@ func newForDeployment(deploymentType deployment.Type, region string, configEnvHa
 	if err != nil {
 		return nil, fmt.Errorf("invalid config backend URL: %v", err)
 	}
+	if backendConfig.secrets, err = secrets.New(config.Default, pkgLogger); err != nil {
+		return nil, fmt.Errorf("secrets provider setup: %w", err)
+	}
 
 	switch deploymentType {
 	case deployment.DedicatedType:
@@ -333,14 +382,21 @@ func (bc *backendConfigImpl) StartWithIDs(ctx context.Context, _ string) {
 	if bc.cache == nil {
 		identifier := bc.Identity()
 		u, _ := identifier.BasicAuth()
-		secret := sha256.Sum256([]byte(u))
 		cacheKey := identifier.ID()
+		secrets, keysConfigured := cacheSecrets(u)
 		bc.cache, err = cache.Start(
 			ctx,
-			secret,
+			secrets,
+			// a cache encrypted with a rotated workspace token cannot be decrypted anymore, thus it gets discarded,
+			// whereas explicitly configured keys are expected to be rotated without losing the cache
+			!keysConfigured,
 			cacheKey,
-			func() pubsub.DataChannel { return bc.Subscribe(ctx, TopicBackendConfig) },
+			func() pubsub.DataChannel { return bc.Subscribe(ctx, topicRawBackendConfig) },
 		)
+		if errors.Is(err, cache.ErrUndecryptable) {
+			// running without the cached config would leave the server without any config while the control plane is down
+			panic(fmt.Errorf("backend config cache cannot be decrypted, check BackendConfig.cache.encryptionKeys and BackendConfig.cache.workspaceTokenFallback: %w", err))
+		}
 		if err != nil {
 			// the only reason why we should resume by using no cache,
 			// would be if no database configuration has been set
@@ -359,6 +415,26 @@ func (bc *backendConfigImpl) StartWithIDs(ctx context.Context, _ string) {
 	})
 }
 
+// cacheSecrets returns the secrets of the config cache, derived from the keys configured through BackendConfig.cache.encryptionKeys.
+// The first key is used for encrypting the cache, whereas the rest are only used for decrypting it, so that keys can be rotated by
+// prepending a new key and removing the old one once the cache has been re-encrypted. The secret derived from the workspace token is
+// the only one used if no keys are configured. Otherwise, it is only used for decrypting caches encrypted before keys were configured
+// while BackendConfig.cache.workspaceTokenFallback is set, which is meant to be unset once the cache has been re-encrypted.
+// It also returns whether any keys are configured.
+func cacheSecrets(workspaceToken string) ([][32]byte, bool) {
+	var secrets [][32]byte
+	for _, key := range config.GetStringSlice("BackendConfig.cache.encryptionKeys", nil) {
+		if key != "" {
+			secrets = append(secrets, sha256.Sum256([]byte(key)))
+		}
+	}
+	keysConfigured := len(secrets) > 0
+	if !keysConfigured || config.GetBool("BackendConfig.cache.workspaceTokenFallback", false) {
+		secrets = append(secrets, sha256.Sum256([]byte(workspaceToken)))
+	}
+	return secrets, keysConfigured
+}
+
 func (bc *backendConfigImpl) Stop() {
 	if bc.cancel != nil {
 		bc.cancel()
@@ -12,6 +12,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -29,6 +32,7 @@ import (
 	adminpkg "github.com/rudderlabs/rudder-server/admin"
 	"github.com/rudderlabs/rudder-server/backend-config/internal/cache"
 	"github.com/rudderlabs/rudder-server/services/diagnostics"
+	"github.com/rudderlabs/rudder-server/services/secrets"
 	"github.com/rudderlabs/rudder-server/utils/pubsub"
 	"github.com/rudderlabs/rudder-server/utils/types/deployment"
 )
@@ -337,6 +341,53 @@ func TestConfigUpdate(t *testing.T) {
 		require.Equal(t, (<-chBackend).Data, map[string]ConfigT{workspaces: sampleConfigWithConnection})
 		require.Equal(t, bc.curSourceJSON[workspaces].Connections, sampleConfigWithConnection.Connections)
 	})
+
+	t.Run("new config with secret references", func(t *testing.T) {
+		var (
+			ctrl        = gomock.NewController(t)
+			ctx, cancel = context.WithCancel(context.Background())
+			workspaces  = "foo"
+			cacheStore  = cache.NewMockCache(ctrl)
+		)
+		defer ctrl.Finish()
+		defer cancel()
+
+		secretsDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(secretsDir, "webhook.json"), []byte(`{"token":"resolved-token"}`), 0o600))
+		provider, err := secrets.NewFileProvider(secretsDir)
+		require.NoError(t, err)
+
+		withSecrets := func(token string) ConfigT {
+			return ConfigT{
+				WorkspaceID: sampleWorkspaceID,
+				Sources: []SourceT{{
+					ID: "s-1",
+					Destinations: []DestinationT{{
+						ID:     "d-1",
+						Config: map[string]interface{}{"url": "https://example.com", "headers": []interface{}{map[string]interface{}{"to": "Authorization", "from": token}}},
+					}},
+				}},
+			}
+		}
+		wc := NewMockworkspaceConfig(ctrl)
+		wc.EXPECT().Get(gomock.Eq(ctx)).Return(map[string]ConfigT{workspaces: withSecrets("Bearer ${secret:webhook#token}")}, nil).Times(1)
+
+		var pubSub pubsub.PublishSubscriber
+		bc := &backendConfigImpl{
+			eb:              &pubSub,
+			workspaceConfig: wc,
+			cache:           cacheStore,
+			secrets:         secrets.NewResolver(provider, time.Minute, logger.NOP),
+		}
+
+		chRaw := pubSub.Subscribe(ctx, string(topicRawBackendConfig))
+		chBackend := pubSub.Subscribe(ctx, string(TopicBackendConfig))
+
+		bc.configUpdate(ctx)
+		require.True(t, bc.initialized)
+		require.Equal(t, map[string]ConfigT{workspaces: withSecrets("Bearer resolved-token")}, (<-chBackend).Data)
+		require.Equal(t, map[string]ConfigT{workspaces: withSecrets("Bearer ${secret:webhook#token}")}, (<-chRaw).Data, "the config is cached without its secrets resolved")
+	})
 }
 
 func TestFilterProcessorEnabledDestinations(t *testing.T) {
@@ -599,6 +650,93 @@ func TestCache(t *testing.T) {
 		require.Equal(t, map[string]ConfigT{sampleWorkspaceID: sampleBackendConfig}, config)
 	})
 
+	t.Run("rotates cache encryption keys", func(t *testing.T) {
+		var (
+			ctrl           = gomock.NewController(t)
+			workspaces     = "bar"
+			workspaceToken = `token`
+		)
+		defer ctrl.Finish()
+		decrypt := func(secret [32]byte) (map[string]ConfigT, error) {
+			var configBytes []byte
+			err := db.QueryRowContext(context.Background(), `SELECT config FROM config_cache WHERE key = $1`, workspaces).Scan(&configBytes)
+			if err != nil {
+				return nil, err
+			}
+			cipherBlock, err := aes.NewCipher(secret[:])
+			if err != nil {
+				return nil, err
+			}
+			gcm, err := cipher.NewGCM(cipherBlock)
+			if err != nil {
+				return nil, err
+			}
+			out, err := gcm.Open(nil, configBytes[:gcm.NonceSize()], configBytes[gcm.NonceSize():], nil)
+			if err != nil {
+				return nil, err
+			}
+			var config map[string]ConfigT
+			return config, json.Unmarshal(out, &config)
+		}
+		start := func(ctx context.Context, token string) *backendConfigImpl {
+			wc := NewMockworkspaceConfig(ctrl)
+			wc.EXPECT().Identity().Return(&mockIdentifier{key: workspaces, token: token}).Times(1)
+			wc.EXPECT().Get(gomock.Any()).Return(map[string]ConfigT{sampleWorkspaceID: sampleBackendConfig}, nil).AnyTimes()
+			bc := &backendConfigImpl{
+				workspaceConfig: wc,
+				eb:              pubsub.New(),
+				curSourceJSON:   map[string]ConfigT{},
+			}
+			bc.StartWithIDs(ctx, workspaces)
+			return bc
+		}
+
+		// cache encrypted with the secret derived from the workspace token
+		ctx, cancel := context.WithCancel(context.Background())
+		start(ctx, workspaceToken).WaitForConfig(ctx)
+		require.Eventually(t, func() bool {
+			_, err := decrypt(sha256.Sum256([]byte(workspaceToken)))
+			return err == nil
+		}, 10*time.Second, 100*time.Millisecond)
+		cancel()
+
+		// the cache is re-encrypted with the configured key, falling back to the workspace token derived key for decrypting it
+		t.Setenv("RSERVER_BACKEND_CONFIG_CACHE_ENCRYPTION_KEYS", "new-key")
+		t.Setenv("RSERVER_BACKEND_CONFIG_CACHE_WORKSPACE_TOKEN_FALLBACK", "true")
+		ctx, cancel = context.WithCancel(context.Background())
+		bc := start(ctx, workspaceToken)
+		cached, err := bc.cache.Get(ctx)
+		require.NoError(t, err)
+		require.NotEmpty(t, cached)
+		require.Eventually(t, func() bool {
+			config, err := decrypt(sha256.Sum256([]byte("new-key")))
+			return err == nil && reflect.DeepEqual(map[string]ConfigT{sampleWorkspaceID: sampleBackendConfig}, config)
+		}, 10*time.Second, 100*time.Millisecond)
+		cancel()
+
+		// the workspace token derived key is retired once the fallback is unset
+		t.Setenv("RSERVER_BACKEND_CONFIG_CACHE_WORKSPACE_TOKEN_FALLBACK", "false")
+		ctx, cancel = context.WithCancel(context.Background())
+		cached, err = start(ctx, workspaceToken).cache.Get(ctx)
+		require.NoError(t, err, "the cache is decrypted with the configured key")
+		require.NotEmpty(t, cached)
+		cancel()
+		t.Setenv("RSERVER_BACKEND_CONFIG_CACHE_ENCRYPTION_KEYS", "other-key")
+		require.Panics(t, func() { start(context.Background(), workspaceToken) }, "the workspace token derived key isn't used along with configured keys")
+
+		// without configured keys, a cache encrypted with a rotated workspace token is discarded and overwritten
+		t.Setenv("RSERVER_BACKEND_CONFIG_CACHE_ENCRYPTION_KEYS", "")
+		ctx, cancel = context.WithCancel(context.Background())
+		defer cancel()
+		var bc2 *backendConfigImpl
+		require.NotPanics(t, func() { bc2 = start(ctx, "rotated-token") })
+		bc2.WaitForConfig(ctx)
+		require.Eventually(t, func() bool {
+			config, err := decrypt(sha256.Sum256([]byte("rotated-token")))
+			return err == nil && reflect.DeepEqual(map[string]ConfigT{sampleWorkspaceID: sampleBackendConfig}, config)
+		}, 10*time.Second, 100*time.Millisecond)
+	})
+
 	t.Run(`panics if database is configured but connection fails during cache setup`, func(t *testing.T) {
 		t.Setenv("JOBS_DB_DB_NAME", `nodb`)
 		t.Setenv("JOBS_DB_HOST", "nodb")
@@ -7,6 +7,7 @@ import (
 	"crypto/cipher"
 	"crypto/rand"
 	"database/sql"
+	"errors"
 	"fmt"
 
 	jsoniter "github.com/json-iterator/go"
@@ -21,6 +22,9 @@ import (
 var (
 	pkgLogger = logger.NewLogger().Child("backend-config-cache")
 	json      = jsoniter.ConfigCompatibleWithStandardLibrary
+
+	// ErrUndecryptable is returned when the cached config cannot be decrypted with any of the provided secrets
+	ErrUndecryptable = errors.New("cached config cannot be decrypted with any of the provided secrets")
 )
 
 type Cache interface {
@@ -29,18 +33,26 @@ type Cache interface {
 
 type cacheStore struct {
 	*sql.DB
-	secret [32]byte
-	key    string
+	secrets              [][32]byte
+	key                  string
+	discardUndecryptable bool
 }
 
 // Start returns a new Cache instance, and starts a goroutine to cache the config
 //
-// secret is the secret key to encrypt the config with before storing it
+// secrets are the secret keys the config can be encrypted with: the first one is used for encrypting the config before storing it,
+// whereas all of them are tried for decrypting it, so that secrets can be rotated without losing the cached config.
+// A cached config which was encrypted with any other than the first secret is re-encrypted with it during startup.
+// An ErrUndecryptable error is returned if the cached config cannot be decrypted with any of the secrets,
+// unless discardUndecryptable is set, in which case the cached config is discarded and overwritten by the next config update.
 //
 // key is the key to use to store and fetch the config from the cache store
 //
 // ch is the channel to listen on for config updates and store them
-func Start(ctx context.Context, secret [32]byte, key string, channelProvider func() pubsub.DataChannel) (Cache, error) {
+func Start(ctx context.Context, secrets [][32]byte, discardUndecryptable bool, key string, channelProvider func() pubsub.DataChannel) (Cache, error) {
+	if len(secrets) == 0 {
+		return nil, errors.New("at least one secret is required")
+	}
 	var (
 		err    error
 		dbConn *sql.DB
@@ -53,8 +65,9 @@ func Start(ctx context.Context, secret [32]byte, key string, channelProvider fun
 	}
 	dbStore := cacheStore{
 		dbConn,
-		secret,
+		secrets,
 		key,
+		discardUndecryptable,
 	}
 
 	// apply migrations
@@ -71,6 +84,14 @@ func Start(ctx context.Context, secret [32]byte, key string, channelProvider fun
 		return nil, err
 	}
 
+	// make sure that the cached config can be decrypted, instead of finding out when the control plane is down
+	err = dbStore.verify(ctx)
+	if err != nil {
+		pkgLogger.Errorf("failed to verify cached config: %v", err)
+		dbConn.Close()
+		return nil, err
+	}
+
 	go func() {
 		// subscribe to config and write to db
 		for config := range channelProvider() {
@@ -131,7 +152,40 @@ func (db *cacheStore) Get(ctx context.Context) ([]byte, error) {
 		return nil, err
 	}
 	// decrypt and return
-	return db.decryptAES(config)
+	decrypted, _, err := db.decryptAES(config)
+	return decrypted, err
+}
+
+// verify checks that the cached config, if any, can be decrypted and re-encrypts it with the primary secret if needed.
+// A cached config which cannot be decrypted is discarded if discardUndecryptable is set.
+func (db *cacheStore) verify(ctx context.Context) error {
+	var encrypted []byte
+	err := db.QueryRowContext(ctx, `SELECT config FROM config_cache WHERE key = $1`, db.key).Scan(&encrypted)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading cached config: %w", err)
+	}
+	decrypted, secretIndex, err := db.decryptAES(encrypted)
+	if errors.Is(err, ErrUndecryptable) && db.discardUndecryptable {
+		pkgLogger.Warnf("discarding cached config, since it cannot be decrypted, e.g. due to the secret having been rotated: %v", err)
+		_, err = db.ExecContext(ctx, `DELETE FROM config_cache WHERE key = $1`, db.key)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if secretIndex == 0 {
+		return nil
+	}
+	pkgLogger.Infof("re-encrypting cached config with the primary secret, since it was encrypted with secret #%d", secretIndex)
+	reencrypted, err := db.encryptAES(decrypted)
+	if err != nil {
+		return err
+	}
+	_, err = db.ExecContext(ctx, `UPDATE config_cache SET config = $2 WHERE key = $1`, db.key, reencrypted)
+	return err
 }
 
 // setupDBConn sets up the database connection, creates the config table if it doesn't exist
@@ -167,8 +221,9 @@ func migrate(db *sql.DB) error {
 	return m.Migrate("config_cache")
 }
 
+// encryptAES encrypts the data with the primary secret
 func (db *cacheStore) encryptAES(data []byte) ([]byte, error) {
-	gcm, err := newGCM(db.secret)
+	gcm, err := newGCM(db.secrets[0])
 	if err != nil {
 		return nil, fmt.Errorf("failed to create encrypt gcm: %w", err)
 	}
@@ -179,18 +234,23 @@ func (db *cacheStore) encryptAES(data []byte) ([]byte, error) {
 	return gcm.Seal(nonce, nonce, data, nil), nil
 }
 
-func (db *cacheStore) decryptAES(data []byte) ([]byte, error) {
-	gcm, err := newGCM(db.secret)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create decrypt gcm: %w", err)
-	}
-	nonceSize := gcm.NonceSize()
-	nonce, ciphertext := data[:nonceSize], data[nonceSize:]
-	out, err := gcm.Open(nil, nonce, ciphertext, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to decrypt: %w", err)
+// decryptAES decrypts the data with the first secret that succeeds, returning the index of the secret along with the decrypted data
+func (db *cacheStore) decryptAES(data []byte) ([]byte, int, error) {
+	for i, secret := range db.secrets {
+		gcm, err := newGCM(secret)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to create decrypt gcm: %w", err)
+		}
+		nonceSize := gcm.NonceSize()
+		if len(data) < nonceSize {
+			return nil, 0, fmt.Errorf("failed to decrypt: %w", ErrUndecryptable)
+		}
+		nonce, ciphertext := data[:nonceSize], data[nonceSize:]
+		if out, err := gcm.Open(nil, nonce, ciphertext, nil); err == nil {
+			return out, i, nil
+		}
 	}
-	return out, nil
+	return nil, 0, fmt.Errorf("failed to decrypt: %w", ErrUndecryptable)
 }
 
 func newGCM(secret [32]byte) (cipher.AEAD, error) {
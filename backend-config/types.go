@@ -1,6 +1,7 @@
 package backendconfig
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/rudderlabs/rudder-server/utils/misc"
@@ -68,12 +69,38 @@ type SourceT struct {
 	Destinations               []DestinationT
 	WriteKey                   string
 	DgSourceTrackingPlanConfig DgSourceTrackingPlanConfigT
+	SchemaEnforcement          SchemaEnforcementT
+	BotManagement              BotManagementT
+	RateLimit                  RateLimitT
 	Transient                  bool
 	GeoEnrichment              struct {
 		Enabled bool
 	}
 }
 
+// Bot policies, deciding what the gateway does with events detected as bot traffic
+const (
+	// BotPolicyFlag annotates bot events, setting context.isBot and context.botRule
+	BotPolicyFlag = "flag"
+	// BotPolicyDrop discards bot events
+	BotPolicyDrop = "drop"
+	// BotPolicyRoute sends bot events only to the source's bot destinations, which in turn don't receive any other events
+	BotPolicyRoute = "route"
+)
+
+// BotManagementT is the source's policy for events detected as bot traffic by the gateway
+type BotManagementT struct {
+	Policy         string   `json:"policy"`         // one of flag, drop or route, bot events only being counted if empty
+	DestinationIDs []string `json:"destinationIds"` // destinations receiving the bot events if policy is route
+}
+
+// RateLimitT overrides the gateway's default rate limits for the source, each limit which is set taking precedence over its default
+type RateLimitT struct {
+	EventLimit     int64 `json:"eventLimit"`     // events allowed for the source per window, unlimited if zero
+	UserEventLimit int64 `json:"userEventLimit"` // events allowed for each user of the source per window, unlimited if zero
+	Window         int64 `json:"window"`         // window in seconds
+}
+
 type Credential struct {
 	Key      string `json:"key"`
 	Value    string `json:"value"`
@@ -216,3 +243,19 @@ type TrackingPlanT struct {
 	Id      string `json:"id"`
 	Version int    `json:"version"`
 }
+
+// SchemaEnforcementT holds the json schemas that the events of a source are validated against by the processor
+type SchemaEnforcementT struct {
+	ID      string         `json:"id"`
+	Version int            `json:"version"`
+	Mode    string         `json:"mode"` // one of warn, drop or quarantine
+	Schemas []EventSchemaT `json:"schemas"`
+}
+
+// EventSchemaT is a json schema applying to events of the given type and, optionally, name.
+// An empty event type matches all event types.
+type EventSchemaT struct {
+	EventType string          `json:"eventType"`
+	EventName string          `json:"eventName"`
+	Schema    json.RawMessage `json:"schema"`
+}
@@ -0,0 +1,71 @@
+package replayjob
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Admin exposes replay jobs over the admin rpc interface
+type Admin struct {
+	manager *Manager
+}
+
+// NewAdmin returns the admin handler of replay jobs
+func NewAdmin(manager *Manager) *Admin {
+	return &Admin{manager: manager}
+}
+
+// Create creates a new replay job
+func (a *Admin) Create(spec Spec, reply *string) error {
+	job, err := a.manager.Create(context.Background(), spec)
+	if err != nil {
+		return err
+	}
+	return formatReply(job, reply)
+}
+
+// List lists all replay jobs
+func (a *Admin) List(_ string, reply *string) error {
+	jobs, err := a.manager.List(context.Background())
+	if err != nil {
+		return err
+	}
+	return formatReply(jobs, reply)
+}
+
+// Get returns a replay job along with its progress
+func (a *Admin) Get(id int64, reply *string) error {
+	return a.call(a.manager.Get, id, reply)
+}
+
+// Pause pauses a running replay job
+func (a *Admin) Pause(id int64, reply *string) error {
+	return a.call(a.manager.Pause, id, reply)
+}
+
+// Resume resumes a paused replay job
+func (a *Admin) Resume(id int64, reply *string) error {
+	return a.call(a.manager.Resume, id, reply)
+}
+
+// Cancel cancels a replay job
+func (a *Admin) Cancel(id int64, reply *string) error {
+	return a.call(a.manager.Cancel, id, reply)
+}
+
+func (*Admin) call(fn func(ctx context.Context, id int64) (*Job, error), id int64, reply *string) error {
+	job, err := fn(context.Background(), id)
+	if err != nil {
+		return err
+	}
+	return formatReply(job, reply)
+}
+
+func formatReply(v any, reply *string) error {
+	formattedOutput, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	*reply = string(formattedOutput)
+	return nil
+}
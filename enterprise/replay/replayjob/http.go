@@ -0,0 +1,95 @@
+package replayjob
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// HttpHandler returns the http handler of replay jobs
+//
+//   - POST /jobs - creates a new replay job for the [Spec] provided in the request body
+//   - GET /jobs - lists replay jobs, optionally filtered by the status query parameter
+//   - GET /jobs/{id} - returns a replay job along with its progress
+//   - POST /jobs/{id}/pause, /jobs/{id}/resume & /jobs/{id}/cancel - control the execution of a replay job
+func (m *Manager) HttpHandler() http.Handler {
+	srvMux := chi.NewRouter()
+	srvMux.Post("/jobs", m.createJob)
+	srvMux.Get("/jobs", m.listJobs)
+	srvMux.Get("/jobs/{id}", m.jobHandler(m.Get))
+	srvMux.Post("/jobs/{id}/pause", m.jobHandler(m.Pause))
+	srvMux.Post("/jobs/{id}/resume", m.jobHandler(m.Resume))
+	srvMux.Post("/jobs/{id}/cancel", m.jobHandler(m.Cancel))
+	return srvMux
+}
+
+func (m *Manager) createJob(w http.ResponseWriter, r *http.Request) {
+	var spec Spec
+	if err := json.NewDecoder(r.Body).Decode(&spec); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := spec.validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	job, err := m.Create(r.Context(), spec)
+	if err != nil {
+		m.log.Errorw("creating replay job", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(job)
+}
+
+func (m *Manager) listJobs(w http.ResponseWriter, r *http.Request) {
+	var statuses []string
+	for _, status := range r.URL.Query()["status"] {
+		statuses = append(statuses, strings.Split(status, ",")...)
+	}
+	jobs, err := m.List(r.Context(), statuses...)
+	if err != nil {
+		m.log.Errorw("listing replay jobs", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if jobs == nil {
+		jobs = []Job{}
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(jobs)
+}
+
+// jobHandler returns a handler calling fn for the job of the id path parameter and responding with the resulting job
+func (m *Manager) jobHandler(fn func(ctx context.Context, id int64) (*Job, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			http.Error(w, "invalid id", http.StatusBadRequest)
+			return
+		}
+		job, err := fn(r.Context(), id)
+		if err != nil {
+			status := http.StatusInternalServerError
+			switch {
+			case errors.Is(err, ErrNotFound):
+				status = http.StatusNotFound
+			case errors.Is(err, ErrInvalidStatus):
+				status = http.StatusConflict
+			default:
+				m.log.Errorw("replay job request", "path", r.URL.Path, "error", err)
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		_ = json.NewEncoder(w).Encode(job)
+	}
+}
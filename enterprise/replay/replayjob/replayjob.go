@@ -0,0 +1,228 @@
+// Package replayjob implements on-demand replays of archived gateway events.
+//
+// A replay job replays the events of a source which were received within a time window, reading them from the
+// files uploaded by the archiver into the workspace's object storage and storing them back into the gateway jobsdb,
+// optionally targeting only specific destinations. Jobs can be paused, resumed and cancelled while their progress
+// is persisted in the replay_jobs table, so that they continue from where they left off after a restart.
+// A dry-run job only counts the events that would be replayed.
+package replayjob
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/rudderlabs/rudder-go-kit/config"
+	"github.com/rudderlabs/rudder-go-kit/logger"
+	"github.com/rudderlabs/rudder-server/jobsdb"
+	"github.com/rudderlabs/rudder-server/services/fileuploader"
+	migrator "github.com/rudderlabs/rudder-server/services/sql-migrator"
+	"github.com/rudderlabs/rudder-server/utils/misc"
+)
+
+// Replay job statuses
+const (
+	StatusRunning   = "running"
+	StatusPaused    = "paused"
+	StatusCancelled = "cancelled"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+)
+
+var (
+	// ErrNotFound is returned when a replay job doesn't exist
+	ErrNotFound = errors.New("replay job not found")
+	// ErrInvalidStatus is returned when a replay job cannot transition to the requested status
+	ErrInvalidStatus = errors.New("invalid replay job status transition")
+
+	columns = "id, workspace_id, source_id, destination_ids, start_time, end_time, dry_run, status, error, last_key, files_processed, events_matched, events_replayed, created_at, updated_at"
+)
+
+// Spec describes the events to be replayed
+type Spec struct {
+	WorkspaceID    string    `json:"workspaceId"`
+	SourceID       string    `json:"sourceId"`
+	DestinationIDs []string  `json:"destinationIds"` // events are replayed to all the source's destinations if empty
+	StartTime      time.Time `json:"startTime"`
+	EndTime        time.Time `json:"endTime"`
+	DryRun         bool      `json:"dryRun"` // only count the matching events, without replaying them
+}
+
+func (s *Spec) validate() error {
+	switch {
+	case s.WorkspaceID == "":
+		return errors.New("workspaceId is required")
+	case s.SourceID == "":
+		return errors.New("sourceId is required")
+	case s.StartTime.IsZero() || s.EndTime.IsZero():
+		return errors.New("startTime and endTime are required")
+	case !s.StartTime.Before(s.EndTime):
+		return errors.New("startTime must be before endTime")
+	}
+	return nil
+}
+
+// Job is a replay job along with its progress
+type Job struct {
+	ID int64 `json:"id"`
+	Spec
+	Status         string    `json:"status"`
+	Error          string    `json:"error,omitempty"`
+	LastKey        string    `json:"lastKey"`        // key of the last archive file that has been processed
+	FilesProcessed int64     `json:"filesProcessed"` // number of archive files processed
+	EventsMatched  int64     `json:"eventsMatched"`  // number of events received within the job's window
+	EventsReplayed int64     `json:"eventsReplayed"` // number of events stored into the gateway jobsdb, always zero for dry runs
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+// Manager manages replay jobs, executing running jobs one at a time once [Manager.Run] is started
+type Manager struct {
+	log     logger.Logger
+	conf    *config.Config
+	db      *sql.DB
+	gwDB    jobsdb.JobsDB
+	storage fileuploader.Provider
+}
+
+// New returns a new replay job manager after running its database migrations
+func New(conf *config.Config, log logger.Logger, gwDB jobsdb.JobsDB, storage fileuploader.Provider) (*Manager, error) {
+	db, err := setupDBConn(conf)
+	if err != nil {
+		return nil, fmt.Errorf("db setup: %w", err)
+	}
+	if err := migrate(conf, db); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("db migrations: %w", err)
+	}
+	return &Manager{
+		log:     log,
+		conf:    conf,
+		db:      db,
+		gwDB:    gwDB,
+		storage: storage,
+	}, nil
+}
+
+// Create creates a new running replay job
+func (m *Manager) Create(ctx context.Context, spec Spec) (*Job, error) {
+	if err := spec.validate(); err != nil {
+		return nil, err
+	}
+	if spec.DestinationIDs == nil {
+		spec.DestinationIDs = []string{}
+	}
+	row := m.db.QueryRowContext(ctx,
+		`INSERT INTO replay_jobs (workspace_id, source_id, destination_ids, start_time, end_time, dry_run, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING `+columns,
+		spec.WorkspaceID, spec.SourceID, pq.Array(spec.DestinationIDs), spec.StartTime, spec.EndTime, spec.DryRun, StatusRunning)
+	return scanJob(row)
+}
+
+// Get returns the replay job with the given id
+func (m *Manager) Get(ctx context.Context, id int64) (*Job, error) {
+	return scanJob(m.db.QueryRowContext(ctx, `SELECT `+columns+` FROM replay_jobs WHERE id = $1`, id))
+}
+
+// List returns the replay jobs having any of the given statuses, all jobs if no status is provided
+func (m *Manager) List(ctx context.Context, statuses ...string) ([]Job, error) {
+	query := `SELECT ` + columns + ` FROM replay_jobs`
+	var args []any
+	if len(statuses) > 0 {
+		query += ` WHERE status = ANY($1)`
+		args = append(args, pq.Array(statuses))
+	}
+	rows, err := m.db.QueryContext(ctx, query+` ORDER BY id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying replay jobs: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	var jobs []Job
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, *job)
+	}
+	return jobs, rows.Err()
+}
+
+// Pause pauses a running replay job, which will stop after the archive file currently being processed
+func (m *Manager) Pause(ctx context.Context, id int64) (*Job, error) {
+	return m.setStatus(ctx, id, StatusPaused, StatusRunning)
+}
+
+// Resume resumes a paused replay job
+func (m *Manager) Resume(ctx context.Context, id int64) (*Job, error) {
+	return m.setStatus(ctx, id, StatusRunning, StatusPaused)
+}
+
+// Cancel cancels a running or paused replay job
+func (m *Manager) Cancel(ctx context.Context, id int64) (*Job, error) {
+	return m.setStatus(ctx, id, StatusCancelled, StatusRunning, StatusPaused)
+}
+
+// setStatus updates the job's status, provided that its current status is one of the given ones
+func (m *Manager) setStatus(ctx context.Context, id int64, status string, from ...string) (*Job, error) {
+	job, err := scanJob(m.db.QueryRowContext(ctx,
+		`UPDATE replay_jobs SET status = $1, updated_at = NOW() WHERE id = $2 AND status = ANY($3) RETURNING `+columns,
+		status, id, pq.Array(from)))
+	if errors.Is(err, ErrNotFound) {
+		if _, err := m.Get(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: job %d cannot be %s", ErrInvalidStatus, id, status)
+	}
+	return job, err
+}
+
+// Stop closes the manager's database connection
+func (m *Manager) Stop() {
+	_ = m.db.Close()
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanJob(row scanner) (*Job, error) {
+	var job Job
+	err := row.Scan(
+		&job.ID, &job.WorkspaceID, &job.SourceID, pq.Array(&job.DestinationIDs), &job.StartTime, &job.EndTime, &job.DryRun,
+		&job.Status, &job.Error, &job.LastKey, &job.FilesProcessed, &job.EventsMatched, &job.EventsReplayed, &job.CreatedAt, &job.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("scanning replay job: %w", err)
+	}
+	job.StartTime, job.EndTime = job.StartTime.UTC(), job.EndTime.UTC()
+	return &job, nil
+}
+
+func migrate(conf *config.Config, db *sql.DB) error {
+	m := &migrator.Migrator{
+		Handle:                     db,
+		MigrationsTable:            "replay_jobs_migrations",
+		ShouldForceSetLowerVersion: conf.GetBool("SQLMigrator.forceSetLowerVersion", true),
+	}
+	return m.Migrate("replay_jobs")
+}
+
+// setupDBConn sets up the database connection. Replay jobs need to share the same database with the gateway jobsdb,
+// so that their progress can be updated within the same transaction that stores the replayed events.
+func setupDBConn(conf *config.Config) (*sql.DB, error) {
+	db, err := sql.Open("postgres", misc.GetConnectionString(conf, "replay-jobs"))
+	if err != nil {
+		return nil, fmt.Errorf("db open: %w", err)
+	}
+	db.SetMaxIdleConns(conf.GetInt("Replay.jobs.maxIdleConns", 1))
+	db.SetMaxOpenConns(conf.GetInt("Replay.jobs.maxOpenConns", 5))
+	return db, nil
+}
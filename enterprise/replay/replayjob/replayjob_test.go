@@ -0,0 +1,206 @@
+package replayjob_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/ory/dockertest/v3"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	"github.com/rudderlabs/rudder-go-kit/config"
+	"github.com/rudderlabs/rudder-go-kit/logger"
+	miniores "github.com/rudderlabs/rudder-go-kit/testhelper/docker/resource/minio"
+	"github.com/rudderlabs/rudder-go-kit/testhelper/docker/resource/postgres"
+
+	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
+	"github.com/rudderlabs/rudder-server/enterprise/replay/replayjob"
+	"github.com/rudderlabs/rudder-server/jobsdb"
+	"github.com/rudderlabs/rudder-server/services/fileuploader"
+)
+
+func TestReplayJobs(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv("RUDDER_TMPDIR", t.TempDir())
+	pool, err := dockertest.NewPool("")
+	require.NoError(t, err)
+	postgresResource, err := postgres.Setup(pool, t)
+	require.NoError(t, err)
+	minioResource, err := miniores.Setup(pool, t)
+	require.NoError(t, err)
+
+	conf := config.New()
+	conf.Set("DB.name", postgresResource.Database)
+	conf.Set("DB.host", postgresResource.Host)
+	conf.Set("DB.port", postgresResource.Port)
+	conf.Set("DB.user", postgresResource.User)
+	conf.Set("DB.password", postgresResource.Password)
+	conf.Set("Replay.jobs.pollInterval", "100ms")
+
+	gwDB := jobsdb.NewForReadWrite("gw", jobsdb.WithDBHandle(postgresResource.DB), jobsdb.WithConfig(conf))
+	require.NoError(t, gwDB.Start())
+	defer gwDB.TearDown()
+
+	// archive files, as uploaded by the archiver
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	uploadArchive := func(key string, createdAt ...time.Time) {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		for _, c := range createdAt {
+			line, err := json.Marshal(map[string]any{
+				"userId":    "user-1",
+				"payload":   json.RawMessage(`{"batch":[{"type":"track"},{"type":"identify"}],"writeKey":"key"}`),
+				"createdAt": c,
+				"messageId": "message-1",
+			})
+			require.NoError(t, err)
+			_, err = gz.Write(append(line, '\n'))
+			require.NoError(t, err)
+		}
+		require.NoError(t, gz.Close())
+		_, err := minioResource.Client.PutObject(ctx, minioResource.BucketName, key, &buf, int64(buf.Len()), minio.PutObjectOptions{})
+		require.NoError(t, err)
+	}
+	uploadArchive("source-1/gw/2024-01-01/10/1/1704103200_1704108600_workspace-1_uuid1.json.gz",
+		day.Add(10*time.Hour), day.Add(10*time.Hour+30*time.Minute), day.Add(11*time.Hour+30*time.Minute))
+	uploadArchive("source-1/gw/2024-01-02/10/1/1704189600_1704189600_workspace-1_uuid2.json.gz",
+		day.Add(34*time.Hour))
+	uploadArchive("source-2/gw/2024-01-01/10/1/1704103200_1704103200_workspace-1_uuid3.json.gz",
+		day.Add(10*time.Hour+30*time.Minute))
+
+	storage := fileuploader.NewStaticProvider(map[string]fileuploader.StorageSettings{
+		"workspace-1": {
+			Bucket: backendconfig.StorageBucket{
+				Type: "MINIO",
+				Config: map[string]interface{}{
+					"bucketName":      minioResource.BucketName,
+					"endPoint":        minioResource.Endpoint,
+					"accessKeyID":     minioResource.AccessKeyID,
+					"secretAccessKey": minioResource.AccessKeySecret,
+				},
+			},
+		},
+	})
+	m, err := replayjob.New(conf, logger.NOP, gwDB, storage)
+	require.NoError(t, err)
+	defer m.Stop()
+
+	spec := replayjob.Spec{
+		WorkspaceID: "workspace-1",
+		SourceID:    "source-1",
+		StartTime:   day.Add(10*time.Hour + 15*time.Minute),
+		EndTime:     day.Add(11 * time.Hour),
+	}
+
+	t.Run("invalid spec", func(t *testing.T) {
+		invalid := spec
+		invalid.EndTime = invalid.StartTime
+		_, err := m.Create(ctx, invalid)
+		require.Error(t, err)
+	})
+
+	t.Run("status transitions", func(t *testing.T) {
+		job, err := m.Create(ctx, spec)
+		require.NoError(t, err)
+		require.Equal(t, replayjob.StatusRunning, job.Status)
+
+		job, err = m.Pause(ctx, job.ID)
+		require.NoError(t, err)
+		require.Equal(t, replayjob.StatusPaused, job.Status)
+		_, err = m.Pause(ctx, job.ID)
+		require.ErrorIs(t, err, replayjob.ErrInvalidStatus)
+
+		job, err = m.Resume(ctx, job.ID)
+		require.NoError(t, err)
+		require.Equal(t, replayjob.StatusRunning, job.Status)
+
+		job, err = m.Cancel(ctx, job.ID)
+		require.NoError(t, err)
+		require.Equal(t, replayjob.StatusCancelled, job.Status)
+		_, err = m.Resume(ctx, job.ID)
+		require.ErrorIs(t, err, replayjob.ErrInvalidStatus)
+
+		_, err = m.Get(ctx, 1000)
+		require.ErrorIs(t, err, replayjob.ErrNotFound)
+	})
+
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = m.Run(runCtx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	waitForCompletion := func(t *testing.T, id int64) *replayjob.Job {
+		var job *replayjob.Job
+		require.Eventually(t, func() bool {
+			var err error
+			job, err = m.Get(ctx, id)
+			require.NoError(t, err)
+			return job.Status != replayjob.StatusRunning
+		}, 30*time.Second, 100*time.Millisecond)
+		require.Equal(t, replayjob.StatusCompleted, job.Status, job.Error)
+		return job
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		dryRun := spec
+		dryRun.DryRun = true
+		job, err := m.Create(ctx, dryRun)
+		require.NoError(t, err)
+		job = waitForCompletion(t, job.ID)
+		require.EqualValues(t, 1, job.FilesProcessed)
+		require.EqualValues(t, 2, job.EventsMatched)
+		require.EqualValues(t, 0, job.EventsReplayed)
+
+		unprocessed, err := gwDB.GetUnprocessed(ctx, jobsdb.GetQueryParams{JobsLimit: 10})
+		require.NoError(t, err)
+		require.Empty(t, unprocessed.Jobs)
+	})
+
+	t.Run("replay to destinations over http", func(t *testing.T) {
+		s := spec
+		s.DestinationIDs = []string{"destination-1", "destination-2"}
+		body, err := json.Marshal(s)
+		require.NoError(t, err)
+		srv := httptest.NewServer(m.HttpHandler())
+		defer srv.Close()
+		resp, err := http.Post(srv.URL+"/jobs", "application/json", bytes.NewReader(body))
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var job replayjob.Job
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&job))
+
+		completed := waitForCompletion(t, job.ID)
+		require.EqualValues(t, 2, completed.EventsMatched)
+		require.EqualValues(t, 4, completed.EventsReplayed)
+
+		unprocessed, err := gwDB.GetUnprocessed(ctx, jobsdb.GetQueryParams{JobsLimit: 10})
+		require.NoError(t, err)
+		require.Len(t, unprocessed.Jobs, 2)
+		var destinationIDs []string
+		for _, j := range unprocessed.Jobs {
+			require.Equal(t, "source-1", gjson.GetBytes(j.Parameters, "source_id").String())
+			require.Equal(t, "workspace-1", j.WorkspaceId)
+			require.Equal(t, 2, j.EventCount)
+			destinationIDs = append(destinationIDs, gjson.GetBytes(j.Parameters, "destination_id").String())
+		}
+		require.ElementsMatch(t, s.DestinationIDs, destinationIDs)
+
+		resp, err = http.Post(srv.URL+"/jobs/1000/pause", "application/json", nil)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
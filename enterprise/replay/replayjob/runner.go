@@ -0,0 +1,254 @@
+package replayjob
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tidwall/gjson"
+
+	"github.com/rudderlabs/rudder-go-kit/filemanager"
+	"github.com/rudderlabs/rudder-server/jobsdb"
+	"github.com/rudderlabs/rudder-server/utils/misc"
+)
+
+// errStopped is returned when a job is no longer running, i.e. it has been paused or cancelled while being processed
+var errStopped = errors.New("replay job stopped")
+
+// Run executes running replay jobs one at a time, until the context is cancelled
+func (m *Manager) Run(ctx context.Context) error {
+	pollInterval := m.conf.GetReloadableDurationVar(5, time.Second, "Replay.jobs.pollInterval")
+	for {
+		jobs, err := m.List(ctx, StatusRunning)
+		if err != nil && ctx.Err() == nil {
+			m.log.Errorw("listing running replay jobs", "error", err)
+		}
+		for i := range jobs {
+			if ctx.Err() != nil {
+				break
+			}
+			m.execute(ctx, &jobs[i])
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(pollInterval.Load()):
+		}
+	}
+}
+
+// execute processes the job, marking it as completed or failed unless it has been stopped in the meantime
+func (m *Manager) execute(ctx context.Context, job *Job) {
+	log := m.log.With("jobId", job.ID, "sourceId", job.SourceID, "dryRun", job.DryRun)
+	log.Infow("executing replay job", "startTime", job.StartTime, "endTime", job.EndTime, "lastKey", job.LastKey)
+	err := m.process(ctx, job)
+	if errors.Is(err, errStopped) || ctx.Err() != nil {
+		log.Infow("replay job stopped", "lastKey", job.LastKey)
+		return
+	}
+	status, errorMessage := StatusCompleted, ""
+	if err != nil {
+		log.Errorw("replay job failed", "error", err)
+		status, errorMessage = StatusFailed, err.Error()
+	}
+	if _, err := m.db.ExecContext(ctx,
+		`UPDATE replay_jobs SET status = $1, error = $2, updated_at = NOW() WHERE id = $3 AND status = $4`,
+		status, errorMessage, job.ID, StatusRunning); err != nil {
+		log.Errorw("updating replay job status", "status", status, "error", err)
+		return
+	}
+	log.Infow("replay job finished", "status", status, "eventsMatched", job.EventsMatched, "eventsReplayed", job.EventsReplayed)
+}
+
+// process replays the archive files of the job's source that overlap with its window.
+//
+// The archiver uploads files under <prefix>/<sourceId>/gw/<date>/<hour>/<instanceId>/ with the creation time of the
+// first job determining the date, so listing starts from the day before the window's start time.
+// Files are processed in key order, the key of the last processed file being persisted along with the job's progress.
+func (m *Manager) process(ctx context.Context, job *Job) error {
+	fm, err := m.storage.GetFileManager(ctx, job.WorkspaceID)
+	if err != nil {
+		return fmt.Errorf("getting file manager: %w", err)
+	}
+	maxItems := m.conf.GetInt64("Replay.jobs.listMaxItems", 1000)
+	startDay := job.StartTime.Truncate(24*time.Hour).AddDate(0, 0, -1)
+	for day := startDay; !day.After(job.EndTime); day = day.AddDate(0, 0, 1) {
+		dayPrefix := path.Join(fm.Prefix(), job.SourceID, "gw", day.Format("2006-01-02")) + "/"
+		var startAfter string
+		if job.LastKey != "" {
+			if strings.HasPrefix(job.LastKey, dayPrefix) {
+				startAfter = job.LastKey
+			} else if job.LastKey > dayPrefix {
+				continue // day already processed
+			}
+		}
+		iter := filemanager.IterateFilesWithPrefix(ctx, dayPrefix, startAfter, maxItems, fm)
+		for iter.Next() {
+			key := iter.Get().Key
+			if !archiveOverlaps(key, job.StartTime, job.EndTime) {
+				continue
+			}
+			if err := m.processFile(ctx, fm, job, key); err != nil {
+				return err
+			}
+		}
+		if err := iter.Err(); err != nil {
+			return fmt.Errorf("listing archive files with prefix %q: %w", dayPrefix, err)
+		}
+	}
+	return nil
+}
+
+// processFile replays the events of an archive file received within the job's window, updating the job's progress
+// in the same transaction that stores them. It returns [errStopped] if the job is no longer running.
+func (m *Manager) processFile(ctx context.Context, fm filemanager.FileManager, job *Job, key string) error {
+	jobs, eventsMatched, err := m.readArchive(ctx, fm, job, key)
+	if err != nil {
+		return fmt.Errorf("reading archive file %q: %w", key, err)
+	}
+	var eventsReplayed int64
+	if !job.DryRun {
+		for _, j := range jobs {
+			eventsReplayed += int64(j.EventCount)
+		}
+	}
+	return m.gwDB.WithStoreSafeTx(ctx, func(tx jobsdb.StoreSafeTx) error {
+		res, err := tx.SqlTx().ExecContext(ctx,
+			`UPDATE replay_jobs SET last_key = $1, files_processed = files_processed + 1,
+			events_matched = events_matched + $2, events_replayed = events_replayed + $3, updated_at = NOW()
+			WHERE id = $4 AND status = $5`,
+			key, eventsMatched, eventsReplayed, job.ID, StatusRunning)
+		if err != nil {
+			return fmt.Errorf("updating replay job progress: %w", err)
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return errStopped
+		}
+		if !job.DryRun && len(jobs) > 0 {
+			if err := m.gwDB.StoreInTx(ctx, tx, jobs); err != nil {
+				return fmt.Errorf("storing replayed jobs: %w", err)
+			}
+		}
+		job.LastKey = key
+		job.FilesProcessed++
+		job.EventsMatched += eventsMatched
+		job.EventsReplayed += eventsReplayed
+		return nil
+	})
+}
+
+// readArchive downloads the archive file and returns the gateway jobs to be replayed along with the number of matching events
+func (m *Manager) readArchive(ctx context.Context, fm filemanager.FileManager, job *Job, key string) ([]*jobsdb.JobT, int64, error) {
+	tmpDirPath, err := misc.CreateTMPDIR()
+	if err != nil {
+		return nil, 0, err
+	}
+	filePath := filepath.Join(tmpDirPath, "rudder-replay-jobs", strconv.FormatInt(job.ID, 10), path.Base(key))
+	if err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = os.Remove(filePath) }()
+	file, err := os.Create(filePath)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = file.Close() }()
+	if err := fm.Download(ctx, file, key); err != nil {
+		return nil, 0, fmt.Errorf("downloading: %w", err)
+	}
+	if _, err := file.Seek(0, 0); err != nil {
+		return nil, 0, err
+	}
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = reader.Close() }()
+
+	sc := bufio.NewScanner(reader)
+	maxCapacity := m.conf.GetInt("Replay.jobs.maxLineSizeInMB", 10) * 1024 * 1024
+	sc.Buffer(make([]byte, 0, 64*1024), maxCapacity)
+
+	var (
+		jobs          []*jobsdb.JobT
+		eventsMatched int64
+	)
+	for sc.Scan() {
+		var line struct {
+			UserID    string          `json:"userId"`
+			Payload   json.RawMessage `json:"payload"`
+			CreatedAt time.Time       `json:"createdAt"`
+		}
+		if err := json.Unmarshal(sc.Bytes(), &line); err != nil {
+			return nil, 0, fmt.Errorf("unmarshalling archived job: %w", err)
+		}
+		if line.CreatedAt.Before(job.StartTime) || !line.CreatedAt.Before(job.EndTime) {
+			continue
+		}
+		eventCount := len(gjson.GetBytes(line.Payload, "batch").Array())
+		eventsMatched += int64(eventCount)
+		if job.DryRun {
+			continue
+		}
+		destinationIDs := job.DestinationIDs
+		if len(destinationIDs) == 0 {
+			destinationIDs = []string{""}
+		}
+		for _, destinationID := range destinationIDs {
+			params := map[string]string{"source_id": job.SourceID}
+			if destinationID != "" {
+				params["destination_id"] = destinationID
+			}
+			marshalledParams, err := json.Marshal(params)
+			if err != nil {
+				return nil, 0, err
+			}
+			payload := make([]byte, len(line.Payload))
+			copy(payload, line.Payload)
+			jobs = append(jobs, &jobsdb.JobT{
+				UUID:         uuid.New(),
+				UserID:       line.UserID,
+				Parameters:   marshalledParams,
+				CustomVal:    "GW",
+				EventPayload: payload,
+				EventCount:   eventCount,
+				WorkspaceId:  job.WorkspaceID,
+			})
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, 0, err
+	}
+	return jobs, eventsMatched, nil
+}
+
+// archiveOverlaps returns true if the archive file may contain jobs created within [start, end).
+// Archive file names are formatted as <firstJobCreatedAt>_<lastJobCreatedAt>_<workspaceId>_<uuid>.json.gz,
+// using unix timestamps in seconds. Files not following this format are always considered.
+func archiveOverlaps(key string, start, end time.Time) bool {
+	tokens := strings.Split(path.Base(key), "_")
+	if len(tokens) < 2 {
+		return true
+	}
+	first, err := strconv.ParseInt(tokens[0], 10, 64)
+	if err != nil {
+		return true
+	}
+	last, err := strconv.ParseInt(tokens[1], 10, 64)
+	if err != nil {
+		return true
+	}
+	return last >= start.Unix() && first < end.Unix()+1
+}
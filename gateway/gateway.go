@@ -34,6 +34,7 @@ var (
 var (
 	errRequestDropped    = errors.New("request dropped")
 	errRequestSuppressed = errors.New("request suppressed")
+	errRequestBotDropped = errors.New("request dropped as bot traffic")
 	errEventSuppressed   = errors.New("event suppressed")
 )
 
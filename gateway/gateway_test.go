@@ -2,6 +2,7 @@ package gateway
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/base64"
 	"encoding/json"
@@ -13,6 +14,7 @@ import (
 	"net/url"
 	"reflect"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -25,6 +27,7 @@ import (
 	"go.uber.org/mock/gomock"
 
 	"github.com/google/uuid"
+	"github.com/klauspost/compress/zstd"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/stretchr/testify/require"
@@ -43,6 +46,7 @@ import (
 	gwstats "github.com/rudderlabs/rudder-server/gateway/internal/stats"
 	gwtypes "github.com/rudderlabs/rudder-server/gateway/internal/types"
 	"github.com/rudderlabs/rudder-server/gateway/response"
+	"github.com/rudderlabs/rudder-server/gateway/throttler"
 	webhookModel "github.com/rudderlabs/rudder-server/gateway/webhook/model"
 	"github.com/rudderlabs/rudder-server/jobsdb"
 	mocksApp "github.com/rudderlabs/rudder-server/mocks/app"
@@ -961,11 +965,15 @@ var _ = Describe("Gateway", func() {
 				},
 			).Should(BeTrue())
 
+			botTags := stats.Tags{"botRule": "bot"}
+			for k, v := range tags {
+				botTags[k] = v
+			}
 			Eventually(
 				func() bool {
 					stat := statsStore.Get(
 						"gateway.write_key_bot_events",
-						tags,
+						botTags,
 					)
 					return stat != nil && stat.LastValue() == float64(1)
 				},
@@ -973,6 +981,162 @@ var _ = Describe("Gateway", func() {
 		})
 	})
 
+	Context("Bulk", func() {
+		var (
+			err        error
+			gateway    *Handle
+			statsStore *memstats.Store
+		)
+
+		BeforeEach(func() {
+			c.initializeAppFeatures()
+			statsStore, err = memstats.New()
+			Expect(err).To(BeNil())
+
+			gateway = &Handle{}
+			conf.Set("Gateway.bulk.chunkSize", 2)
+			err := gateway.Setup(context.Background(), conf, logger.NOP, statsStore, c.mockApp, c.mockBackendConfig, c.mockJobsDB, c.mockErrJobsDB, nil, c.mockVersionHandler, rsources.NewNoOpService(), transformer.NewNoOpService(), sourcedebugger.NewNoOpService(), nil)
+			Expect(err).To(BeNil())
+			waitForBackendConfigInit(gateway)
+		})
+
+		AfterEach(func() {
+			err := gateway.Shutdown()
+			Expect(err).To(BeNil())
+		})
+
+		bulkPayload := []byte(strings.Join([]string{
+			`{"userId":"dummyId","type":"track","event":"event-1"}`,
+			``,
+			`{"userId":"dummyId","type":"track"`,
+			`{"type":"track"}`,
+			`["not","an","event"]`,
+			`{"anonymousId":"dummyId","type":"page"}`,
+			`{"userId":"dummyId","type":"alias"}`,
+		}, "\n"))
+
+		bulkRequest := func(contentEncoding string, body []byte) *http.Request {
+			req := authorizedRequest(WriteKeyEnabled, bytes.NewBuffer(body))
+			if contentEncoding != "" {
+				req.Header.Set("Content-Encoding", contentEncoding)
+			}
+			return req
+		}
+
+		// expectStore expects the bulk request to be stored in [chunks] transactions, returning the stored job batches
+		expectStore := func(chunks int, storeFn func(context.Context, jobsdb.StoreSafeTx, [][]*jobsdb.JobT) (map[uuid.UUID]string, error)) *[][]*jobsdb.JobT {
+			var stored [][]*jobsdb.JobT
+			c.mockJobsDB.EXPECT().WithStoreSafeTx(gomock.Any(), gomock.Any()).Times(chunks).DoAndReturn(func(ctx context.Context, f func(tx jobsdb.StoreSafeTx) error) error {
+				return f(jobsdb.EmptyStoreSafeTx())
+			})
+			c.mockJobsDB.EXPECT().StoreEachBatchRetryInTx(gomock.Any(), gomock.Any(), gomock.Any()).Times(chunks).DoAndReturn(func(ctx context.Context, tx jobsdb.StoreSafeTx, jobBatches [][]*jobsdb.JobT) (map[uuid.UUID]string, error) {
+				stored = append(stored, jobBatches...)
+				return storeFn(ctx, tx, jobBatches)
+			})
+			return &stored
+		}
+
+		serveBulk := func(req *http.Request) *httptest.ResponseRecorder {
+			rr := httptest.NewRecorder()
+			gateway.webBulkHandler().ServeHTTP(rr, req)
+			return rr
+		}
+
+		It("should store events of a newline-delimited json stream in chunks, reporting failed lines", func() {
+			stored := expectStore(2, jobsToEmptyErrors)
+
+			rr := serveBulk(bulkRequest("", bulkPayload))
+			Expect(rr.Code).To(Equal(http.StatusOK))
+			Expect(rr.Body.String()).To(MatchJSON(`{
+				"lines": 6,
+				"succeeded": 3,
+				"suppressed": 0,
+				"botDropped": 0,
+				"failed": 3,
+				"errors": [
+					{"line": 3, "error": "Invalid JSON"},
+					{"line": 4, "error": "Request neither has anonymousId nor userId"},
+					{"line": 5, "error": "Event is not a valid rudder event"}
+				]
+			}`))
+
+			Expect(*stored).To(HaveLen(3))
+			for i, eventType := range []string{"track", "page", "alias"} {
+				Expect((*stored)[i]).To(HaveLen(1))
+				job := (*stored)[i][0]
+				Expect(job.WorkspaceId).To(Equal(WorkspaceID))
+				Expect(gjson.GetBytes(job.EventPayload, "writeKey").String()).To(Equal(WriteKeyEnabled))
+				Expect(gjson.GetBytes(job.EventPayload, "batch.0.type").String()).To(Equal(eventType))
+				Expect(gjson.GetBytes(job.EventPayload, "batch.0.messageId").String()).NotTo(BeEmpty())
+				Expect(gjson.GetBytes(job.Parameters, "source_id").String()).To(Equal(SourceIDEnabled))
+			}
+
+			tags := map[string]string{
+				"source":      rCtxEnabled.SourceTag(),
+				"sourceID":    rCtxEnabled.SourceID,
+				"workspaceId": rCtxEnabled.WorkspaceID,
+				"writeKey":    rCtxEnabled.WriteKey,
+				"reqType":     "bulk",
+				"sourceType":  rCtxEnabled.SourceCategory,
+				"sdkVersion":  "",
+			}
+			Expect(statsStore.Get("gateway.write_key_successful_events", tags).LastValue()).To(Equal(float64(3)))
+			Expect(statsStore.Get("gateway.write_key_events", tags).LastValue()).To(Equal(float64(6)))
+		})
+
+		It("should decompress gzip and zstd streams", func() {
+			stored := expectStore(4, jobsToEmptyErrors)
+
+			var gzipped bytes.Buffer
+			gw := gzip.NewWriter(&gzipped)
+			_, err := gw.Write(bulkPayload)
+			Expect(err).To(BeNil())
+			Expect(gw.Close()).To(BeNil())
+			rr := serveBulk(bulkRequest("gzip", gzipped.Bytes()))
+			Expect(rr.Code).To(Equal(http.StatusOK))
+			Expect(gjson.Get(rr.Body.String(), "succeeded").Int()).To(Equal(int64(3)))
+
+			zw, err := zstd.NewWriter(nil)
+			Expect(err).To(BeNil())
+			rr = serveBulk(bulkRequest("zstd", zw.EncodeAll(bulkPayload, nil)))
+			Expect(rr.Code).To(Equal(http.StatusOK))
+			Expect(gjson.Get(rr.Body.String(), "succeeded").Int()).To(Equal(int64(3)))
+
+			Expect(*stored).To(HaveLen(6))
+		})
+
+		It("should report lines that couldn't be stored", func() {
+			expectStore(2, jobsToJobsdbErrors)
+
+			rr := serveBulk(bulkRequest("", bulkPayload))
+			Expect(rr.Code).To(Equal(http.StatusOK))
+			Expect(gjson.Get(rr.Body.String(), "succeeded").Int()).To(Equal(int64(0)))
+			Expect(gjson.Get(rr.Body.String(), "failed").Int()).To(Equal(int64(6)))
+			Expect(gjson.Get(rr.Body.String(), "errors.#(line==1).error").String()).To(Equal("tx error"))
+			Expect(gjson.Get(rr.Body.String(), "errors.#(line==7).error").String()).To(Equal("tx error"))
+		})
+
+		It("should report lines exceeding the max request size", func() {
+			conf.Set("Gateway.maxReqSizeInKB", 1)
+			expectStore(1, jobsToEmptyErrors)
+
+			payload := fmt.Sprintf(`{"userId":"dummyId","type":"track","properties":{"large":%q}}`+"\n"+`{"userId":"dummyId","type":"track"}`, strings.Repeat("a", 2048))
+			rr := serveBulk(bulkRequest("", []byte(payload)))
+			Expect(rr.Code).To(Equal(http.StatusOK))
+			Expect(rr.Body.String()).To(MatchJSON(`{"lines":2,"succeeded":1,"suppressed":0,"botDropped":0,"failed":1,"errors":[{"line":1,"error":"Request size exceeds max limit"}]}`))
+		})
+
+		It("should reject unsupported or invalid content encodings", func() {
+			rr := serveBulk(bulkRequest("br", bulkPayload))
+			Expect(rr.Code).To(Equal(http.StatusUnsupportedMediaType))
+			Expect(rr.Body.String()).To(Equal(response.UnsupportedContentEncoding + "\n"))
+
+			rr = serveBulk(bulkRequest("gzip", bulkPayload))
+			Expect(rr.Code).To(Equal(http.StatusBadRequest))
+			Expect(rr.Body.String()).To(Equal(response.InvalidCompressedBody + "\n"))
+		})
+	})
+
 	Context("Rate limits", func() {
 		var (
 			err        error
@@ -998,7 +1162,13 @@ var _ = Describe("Gateway", func() {
 		})
 
 		It("should store messages successfully if rate limit is not reached for workspace", func() {
-			c.mockRateLimiter.EXPECT().CheckLimitReached(gomock.Any(), gomock.Any(), gomock.Any()).Return(false, nil).Times(1)
+			c.mockRateLimiter.EXPECT().CheckLimitReached(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, req *throttler.Request) (bool, time.Duration, error) {
+				Expect(req.WorkspaceID).To(Equal(WorkspaceID))
+				Expect(req.Source.ID).To(Equal(SourceIDEnabled))
+				Expect(req.EventCount).To(Equal(int64(1)))
+				Expect(req.UserEvents).To(Equal(map[string]int64{"dummyId": 1}))
+				return false, 0, nil
+			}).Times(1)
 			c.mockJobsDB.EXPECT().WithStoreSafeTx(gomock.Any(), gomock.Any()).Times(1).Do(func(ctx context.Context, f func(tx jobsdb.StoreSafeTx) error) {
 				_ = f(jobsdb.EmptyStoreSafeTx())
 			}).Return(nil)
@@ -1039,7 +1209,7 @@ var _ = Describe("Gateway", func() {
 
 		It("should reject messages if rate limit is reached for workspace", func() {
 			conf.Set("Gateway.allowReqsWithoutUserIDAndAnonymousID", true)
-			c.mockRateLimiter.EXPECT().CheckLimitReached(gomock.Any(), gomock.Any(), gomock.Any()).Return(true, nil).Times(1)
+			c.mockRateLimiter.EXPECT().CheckLimitReached(gomock.Any(), gomock.Any()).Return(true, time.Duration(0), nil).Times(1)
 			expectHandlerResponse(
 				gateway.webAliasHandler(),
 				authorizedRequest(WriteKeyEnabled, bytes.NewBufferString(`{"data": "valid-json"}`)),
@@ -1066,6 +1236,14 @@ var _ = Describe("Gateway", func() {
 				1*time.Second,
 			).Should(BeTrue())
 		})
+
+		It("should set the Retry-After header if rate limit is reached", func() {
+			c.mockRateLimiter.EXPECT().CheckLimitReached(gomock.Any(), gomock.Any()).Return(true, 1500*time.Millisecond, nil).Times(1)
+			rr := httptest.NewRecorder()
+			gateway.webAliasHandler().ServeHTTP(rr, authorizedRequest(WriteKeyEnabled, bytes.NewBufferString(`{"userId":"dummyId"}`)))
+			Expect(rr.Code).To(Equal(http.StatusTooManyRequests))
+			Expect(rr.Header().Get("Retry-After")).To(Equal("2"))
+		})
 	})
 
 	Context("Invalid requests", func() {
@@ -1626,6 +1804,63 @@ var _ = Describe("Gateway", func() {
 			_, err := gateway.getJobDataFromRequest(req)
 			Expect(err).To(BeNil())
 		})
+
+		Context("bot policies", func() {
+			botRequest := func(policy string) *webRequestT {
+				botArctx := *rCtxEnabled
+				botArctx.Source.BotManagement = backendconfig.BotManagementT{Policy: policy}
+				return &webRequestT{
+					reqType:      "batch",
+					authContext:  &botArctx,
+					done:         make(chan<- string),
+					userIDHeader: userIDHeader,
+					requestPayload: []byte(fmt.Sprintf(`{"batch": [
+						{"type": "track", "userId": %[1]q, "context": {"userAgent": "Mozilla/5.0 (compatible; Googlebot/2.1)"}},
+						{"type": "track", "userId": %[1]q, "context": {"userAgent": "Mozilla/5.0 Chrome/91.0.4472.114"}}
+					]}`, NormalUserID)),
+				}
+			}
+
+			It("only counts bot events if the source has no policy", func() {
+				jobData, err := gateway.getJobDataFromRequest(botRequest(""))
+				Expect(err).To(BeNil())
+				Expect(jobData.botEvents).To(Equal(map[string]int{"bot": 1}))
+				Expect(jobData.jobs).To(HaveLen(2))
+				Expect(gjson.GetBytes(jobData.jobs[0].EventPayload, "batch.0.context.isBot").Exists()).To(BeFalse())
+				Expect(gjson.GetBytes(jobData.jobs[0].Parameters, "bot_rule").Exists()).To(BeFalse())
+			})
+
+			It("flags bot events", func() {
+				jobData, err := gateway.getJobDataFromRequest(botRequest(backendconfig.BotPolicyFlag))
+				Expect(err).To(BeNil())
+				Expect(jobData.jobs).To(HaveLen(2))
+				Expect(gjson.GetBytes(jobData.jobs[0].EventPayload, "batch.0.context.isBot").Bool()).To(BeTrue())
+				Expect(gjson.GetBytes(jobData.jobs[0].EventPayload, "batch.0.context.botRule").String()).To(Equal("bot"))
+				Expect(gjson.GetBytes(jobData.jobs[1].EventPayload, "batch.0.context.isBot").Exists()).To(BeFalse())
+			})
+
+			It("drops bot events", func() {
+				jobData, err := gateway.getJobDataFromRequest(botRequest(backendconfig.BotPolicyDrop))
+				Expect(err).To(BeNil())
+				Expect(jobData.botEvents).To(Equal(map[string]int{"bot": 1}))
+				Expect(jobData.jobs).To(HaveLen(1))
+				Expect(gjson.GetBytes(jobData.jobs[0].EventPayload, "batch.0.context.userAgent").String()).To(ContainSubstring("Chrome"))
+
+				req := botRequest(backendconfig.BotPolicyDrop)
+				req.requestPayload = []byte(fmt.Sprintf(`{"batch": [{"type": "track", "userId": %q, "context": {"userAgent": "some-spider"}}]}`, NormalUserID))
+				_, err = gateway.getJobDataFromRequest(req)
+				Expect(err).To(Equal(errRequestBotDropped))
+			})
+
+			It("marks bot events to be routed with their matched rule", func() {
+				jobData, err := gateway.getJobDataFromRequest(botRequest(backendconfig.BotPolicyRoute))
+				Expect(err).To(BeNil())
+				Expect(jobData.jobs).To(HaveLen(2))
+				Expect(gjson.GetBytes(jobData.jobs[0].Parameters, "bot_rule").String()).To(Equal("bot"))
+				Expect(gjson.GetBytes(jobData.jobs[0].Parameters, "source_id").String()).To(Equal(SourceIDEnabled))
+				Expect(gjson.GetBytes(jobData.jobs[1].Parameters, "bot_rule").Exists()).To(BeFalse())
+			})
+		})
 	})
 
 	Context("SaveWebhookFailures", func() {
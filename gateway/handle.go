@@ -8,6 +8,7 @@ import (
 	"io"
 	"math"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -33,13 +34,16 @@ import (
 	"github.com/rudderlabs/rudder-server/app"
 	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
 	"github.com/rudderlabs/rudder-server/gateway/internal/bot"
+	"github.com/rudderlabs/rudder-server/gateway/internal/deadletter"
 	gwstats "github.com/rudderlabs/rudder-server/gateway/internal/stats"
 	gwtypes "github.com/rudderlabs/rudder-server/gateway/internal/types"
+	"github.com/rudderlabs/rudder-server/gateway/kafkasource"
 	"github.com/rudderlabs/rudder-server/gateway/response"
 	"github.com/rudderlabs/rudder-server/gateway/throttler"
 	"github.com/rudderlabs/rudder-server/gateway/webhook"
 	"github.com/rudderlabs/rudder-server/jobsdb"
 	sourcedebugger "github.com/rudderlabs/rudder-server/services/debugger/source"
+	"github.com/rudderlabs/rudder-server/services/fileuploader"
 	"github.com/rudderlabs/rudder-server/services/rsources"
 	"github.com/rudderlabs/rudder-server/utils/misc"
 	"github.com/rudderlabs/rudder-server/utils/types"
@@ -63,6 +67,10 @@ type Handle struct {
 	rsourcesService rsources.JobService
 	sourcehandle    sourcedebugger.SourceDebugger
 
+	fileUploaderProvider fileuploader.Provider
+	rejectedRequests     *deadletter.Store    // nil unless uploading rejected requests is enabled
+	kafkaSources         *kafkasource.Manager // nil unless kafka sources are enabled
+
 	// statistic measurements initialised during Setup
 
 	batchSizeStat                                 stats.Measurement
@@ -89,6 +97,7 @@ type Handle struct {
 	backgroundWait               func() error
 	userWebRequestWorkers        []*userWebRequestWorkerT
 	backendConfigInitialisedChan chan struct{}
+	botRules                     *bot.Rules
 	now                          func() time.Time
 
 	// other state
@@ -120,6 +129,12 @@ type Handle struct {
 		IdleTimeout                          time.Duration
 		allowReqsWithoutUserIDAndAnonymousID config.ValueLoader[bool]
 		gwAllowPartialWriteWithErrors        config.ValueLoader[bool]
+		bulkChunkSize                        config.ValueLoader[int]
+		bulkTimeout                          config.ValueLoader[time.Duration]
+		grpcEnabled                          bool
+		grpcPort                             int
+		grpcMaxIngestInFlight                config.ValueLoader[int]
+		grpcShutdownTimeout                  config.ValueLoader[time.Duration]
 	}
 
 	// additional internal http handlers
@@ -200,14 +215,20 @@ func (gw *Handle) userWebRequestWorkerProcess(userWebRequestWorker *userWebReque
 			if err != nil {
 				switch {
 				case errors.Is(err, errRequestDropped):
+					req.retryAfter = jobData.retryAfter
 					req.done <- response.TooManyRequests
 					sourceStats[sourceTag].RequestDropped()
 				case errors.Is(err, errRequestSuppressed):
 					req.done <- "" // no error
 					sourceStats[sourceTag].RequestSuppressed()
+					gw.recordRejectedRequest(req, err.Error())
+				case errors.Is(err, errRequestBotDropped):
+					req.done <- "" // no error
+					sourceStats[sourceTag].RequestBotDropped()
 				default:
 					req.done <- err.Error()
 					sourceStats[sourceTag].RequestEventsFailed(jobData.numEvents, err.Error())
+					gw.recordRejectedRequest(req, err.Error())
 				}
 				continue
 			}
@@ -322,8 +343,9 @@ func (gw *Handle) getJobDataFromRequest(req *webRequestT) (jobData *jobFromReq,
 	jobData.numEvents = len(eventsBatch)
 
 	type jobObject struct {
-		userID string
-		events []map[string]interface{}
+		userID  string
+		events  []map[string]interface{}
+		botRule string // set if the events are bot events that need to be routed to the source's bot destinations
 	}
 
 	var (
@@ -333,7 +355,12 @@ func (gw *Handle) getJobDataFromRequest(req *webRequestT) (jobData *jobFromReq,
 		marshalledParams []byte
 
 		// facts about the batch populated as we iterate over events
-		containsAudienceList, suppressed bool
+		containsAudienceList, suppressed, botDropped bool
+
+		botPolicy = arctx.Source.BotManagement.Policy
+
+		// number of events per user, used for enforcing per user rate limits
+		userEvents = make(map[string]int64)
 	)
 
 	isUserSuppressed := gw.memoizedIsUserSuppressed()
@@ -355,7 +382,9 @@ func (gw *Handle) getJobDataFromRequest(req *webRequestT) (jobData *jobFromReq,
 			err = errors.New(response.NonIdentifiableRequest)
 			return
 		}
+		userEvents[lo.Ternary(userIDFromReq != "", userIDFromReq, anonIDFromReq)]++
 
+		var userAgent string
 		eventContext, ok := misc.MapLookup(toSet, "context").(map[string]interface{})
 		if ok {
 			if idx == 0 {
@@ -386,12 +415,35 @@ func (gw *Handle) getJobDataFromRequest(req *webRequestT) (jobData *jobFromReq,
 				}
 			}
 
-			userAgent, _ := misc.MapLookup(
+			userAgent, _ = misc.MapLookup(
 				eventContext,
 				"userAgent",
 			).(string)
-			if bot.IsBotUserAgent(userAgent) {
-				jobData.botEvents++
+		}
+
+		eventIP, _ := toSet["request_ip"].(string)
+		if eventIP == "" {
+			eventIP = ipAddr
+		}
+		botRule, isBot := gw.botRules.Match(userAgent, eventIP)
+		if isBot {
+			if jobData.botEvents == nil {
+				jobData.botEvents = make(map[string]int)
+			}
+			jobData.botEvents[botRule]++
+			switch botPolicy {
+			case backendconfig.BotPolicyDrop:
+				botDropped = true
+				continue
+			case backendconfig.BotPolicyFlag:
+				if eventContext == nil && toSet["context"] == nil {
+					eventContext = make(map[string]interface{})
+					toSet["context"] = eventContext
+				}
+				if eventContext != nil {
+					eventContext["isBot"] = true
+					eventContext["botRule"] = botRule
+				}
 			}
 		}
 
@@ -428,19 +480,26 @@ func (gw *Handle) getJobDataFromRequest(req *webRequestT) (jobData *jobFromReq,
 
 		userID := buildUserID(userIDHeader, anonIDFromReq, userIDFromReq)
 		out = append(out, jobObject{
-			userID: userID,
-			events: []map[string]interface{}{toSet},
+			userID:  userID,
+			events:  []map[string]interface{}{toSet},
+			botRule: lo.Ternary(isBot && botPolicy == backendconfig.BotPolicyRoute, botRule, ""),
 		})
 	}
 
 	if gw.conf.enableRateLimit.Load() && sourcesJobRunID == "" && sourcesTaskRunID == "" {
 		// In case of "batch" requests, if rate-limiter returns true for LimitReached, just drop the event batch and continue.
-		ok, errCheck := gw.rateLimiter.CheckLimitReached(context.TODO(), workspaceId, int64(len(eventsBatch)))
+		ok, retryAfter, errCheck := gw.rateLimiter.CheckLimitReached(context.TODO(), &throttler.Request{
+			WorkspaceID: workspaceId,
+			Source:      arctx.Source,
+			EventCount:  int64(len(eventsBatch)),
+			UserEvents:  userEvents,
+		})
 		if errCheck != nil {
 			gw.stats.NewTaggedStat("gateway.rate_limiter_error", stats.CountType, stats.Tags{"workspaceId": workspaceId}).Increment()
 			gw.logger.Errorf("Rate limiter error: %v Allowing the request", errCheck)
 		}
 		if ok {
+			jobData.retryAfter = retryAfter
 			return jobData, errRequestDropped
 		}
 	}
@@ -450,6 +509,11 @@ func (gw *Handle) getJobDataFromRequest(req *webRequestT) (jobData *jobFromReq,
 		return
 	}
 
+	if len(out) == 0 && botDropped {
+		err = errRequestBotDropped
+		return
+	}
+
 	if len(body) > gw.conf.maxReqSize.Load() && !containsAudienceList {
 		err = errors.New(response.RequestBodyTooLarge)
 		return
@@ -475,6 +539,14 @@ func (gw *Handle) getJobDataFromRequest(req *webRequestT) (jobData *jobFromReq,
 			`{"error": "rudder-server gateway failed to marshal params"}`,
 		)
 	}
+	// bot events to be routed are marked with the rule they matched, so that the processor sends them only to the source's bot destinations
+	botParams := func(botRule string) []byte {
+		p, err := sjson.SetBytes(marshalledParams, "bot_rule", botRule)
+		if err != nil {
+			return marshalledParams
+		}
+		return p
+	}
 	jobs := make([]*jobsdb.JobT, 0)
 	for _, userEvent := range out {
 		var (
@@ -505,10 +577,14 @@ func (gw *Handle) getJobDataFromRequest(req *webRequestT) (jobData *jobFromReq,
 			eventCount = len(userEvent.events)
 		}
 
+		jobParams := marshalledParams
+		if userEvent.botRule != "" {
+			jobParams = botParams(userEvent.botRule)
+		}
 		jobs = append(jobs, &jobsdb.JobT{
 			UUID:         uuid.New(),
 			UserID:       userEvent.userID,
-			Parameters:   marshalledParams,
+			Parameters:   jobParams,
 			CustomVal:    customVal,
 			EventPayload: payload,
 			EventCount:   eventCount,
@@ -615,32 +691,51 @@ func (gw *Handle) getPayloadFromRequest(r *http.Request) ([]byte, error) {
 addToWebRequestQ finds the worker for a particular userID and queues the webrequest with the worker(pushes the req into the webRequestQ channel of the worker).
 They are further batched together in userWebRequestBatcher
 */
-func (gw *Handle) addToWebRequestQ(_ *http.ResponseWriter, req *http.Request, done chan string, reqType string, requestPayload []byte, arctx *gwtypes.AuthRequestContext) {
-	userIDHeader := req.Header.Get("AnonymousId")
-	workerKey := userIDHeader
-	if userIDHeader == "" {
-		// If the request comes through proxy, proxy would already send this. So this shouldn't be happening in that case
-		workerKey = uuid.New().String()
-		gw.emptyAnonIdHeaderStat.Increment()
-	}
-	userWebRequestWorker := gw.findUserWebRequestWorker(workerKey)
-	ipAddr := kithttputil.GetRequestIP(req)
-
+func (gw *Handle) addToWebRequestQ(_ *http.ResponseWriter, req *http.Request, done chan string, reqType string, requestPayload []byte, arctx *gwtypes.AuthRequestContext) *webRequestT {
 	traceParent := stats.GetTraceParentFromContext(req.Context())
 	if traceParent == "" {
 		gw.logger.Debugw("traceParent not found in request")
 	}
 
-	webReq := webRequestT{
+	webReq := &webRequestT{
 		done:           done,
 		reqType:        reqType,
 		requestPayload: requestPayload,
 		authContext:    arctx,
 		traceParent:    traceParent,
-		ipAddr:         ipAddr,
-		userIDHeader:   userIDHeader,
+		ipAddr:         kithttputil.GetRequestIP(req),
+		userIDHeader:   req.Header.Get("AnonymousId"),
+	}
+	gw.enqueueWebRequest(webReq)
+	return webReq
+}
+
+// recordRejectedRequest stores the rejected request in object storage, if enabled
+func (gw *Handle) recordRejectedRequest(req *webRequestT, reason string) {
+	if gw.rejectedRequests == nil {
+		return
+	}
+	gw.rejectedRequests.Record(req.authContext.WorkspaceID, req.authContext.SourceID, req.reqType, reason, req.ipAddr, req.requestPayload)
+}
+
+// setRetryAfter sets the Retry-After header of a rate limited response, rounding the duration up to the next second
+func setRetryAfter(w *http.ResponseWriter, retryAfter time.Duration) {
+	if w == nil || retryAfter <= 0 {
+		return
+	}
+	(*w).Header().Set("Retry-After", strconv.FormatInt(int64(math.Ceil(retryAfter.Seconds())), 10))
+}
+
+// enqueueWebRequest pushes the webrequest into the webRequestQ of the worker responsible for its userIDHeader
+func (gw *Handle) enqueueWebRequest(webReq *webRequestT) {
+	workerKey := webReq.userIDHeader
+	if workerKey == "" {
+		// If the request comes through proxy, proxy would already send this. So this shouldn't be happening in that case
+		workerKey = uuid.New().String()
+		gw.emptyAnonIdHeaderStat.Increment()
 	}
-	userWebRequestWorker.webRequestQ <- &webReq
+	userWebRequestWorker := gw.findUserWebRequestWorker(workerKey)
+	userWebRequestWorker.webRequestQ <- webReq
 }
 
 func (gw *Handle) internalBatchHandlerFunc() http.HandlerFunc {
@@ -765,6 +860,11 @@ func (gw *Handle) extractJobsFromInternalBatchPayload(reqType string, body []byt
 	if err != nil {
 		stat.RequestFailed(response.InvalidJSON)
 		stat.Report(gw.stats)
+		// internal requests aren't authenticated, so their workspace and source can only be read out of the malformed payload
+		gw.recordRejectedRequest(&webRequestT{reqType: reqType, requestPayload: body, authContext: &gwtypes.AuthRequestContext{
+			WorkspaceID: gjson.GetBytes(body, "0.properties.workspaceID").String(),
+			SourceID:    gjson.GetBytes(body, "0.properties.sourceID").String(),
+		}}, response.InvalidJSON)
 		return nil, errors.New(response.InvalidJSON)
 	}
 	gw.requestSizeStat.Observe(float64(len(body)))
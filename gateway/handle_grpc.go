@@ -0,0 +1,343 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/rudderlabs/rudder-go-kit/stats"
+
+	"github.com/rudderlabs/rudder-server/gateway/internal/payload"
+	gwstats "github.com/rudderlabs/rudder-server/gateway/internal/stats"
+	gwtypes "github.com/rudderlabs/rudder-server/gateway/internal/types"
+	"github.com/rudderlabs/rudder-server/gateway/response"
+	proto "github.com/rudderlabs/rudder-server/proto/gateway"
+)
+
+// ingestRequestTypes are the request types accepted by the grpc Ingest rpc
+var ingestRequestTypes = map[string]struct{}{
+	"alias":    {},
+	"batch":    {},
+	"group":    {},
+	"identify": {},
+	"merge":    {},
+	"page":     {},
+	"screen":   {},
+	"track":    {},
+}
+
+/*
+StartGrpcHandler starts the gateway grpc server, listening on the gateway grpc port, if it is enabled.
+Messages received over grpc are handled by the same user web request workers as the http ones. This function will block.
+*/
+func (gw *Handle) StartGrpcHandler(ctx context.Context) error {
+	if !gw.conf.grpcEnabled {
+		return nil
+	}
+	gw.logger.Infof("GrpcHandler waiting for BackendConfig before starting on %d", gw.conf.grpcPort)
+	select {
+	case <-ctx.Done():
+		return nil
+	case <-gw.backendConfigInitialisedChan:
+	}
+	gw.logger.Infof("GrpcHandler Starting on %d", gw.conf.grpcPort)
+
+	listener, err := net.Listen("tcp", ":"+strconv.Itoa(gw.conf.grpcPort))
+	if err != nil {
+		return err
+	}
+	srv := grpc.NewServer(
+		grpc.MaxRecvMsgSize(gw.conf.maxReqSize.Load()),
+		grpc.ChainUnaryInterceptor(gw.grpcUnaryInFlightInterceptor),
+		grpc.ChainStreamInterceptor(gw.grpcStreamInFlightInterceptor),
+	)
+	proto.RegisterGatewayServer(srv, &grpcHandler{gw: gw})
+
+	go func() {
+		<-ctx.Done()
+		if timeout := gw.conf.grpcShutdownTimeout.Load(); !stopGrpcServer(srv, timeout) {
+			gw.logger.Warnf("GrpcHandler didn't stop gracefully within %s, stopped it forcefully", timeout)
+		}
+	}()
+	if err := srv.Serve(listener); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+	return nil
+}
+
+// stopGrpcServer stops the server gracefully, waiting for its in-flight calls to finish for up to timeout
+// before closing their connections. It returns false if the server had to be stopped forcefully.
+func stopGrpcServer(srv *grpc.Server, timeout time.Duration) bool {
+	stopped := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return true
+	case <-time.After(timeout):
+		srv.Stop()
+		<-stopped
+		return false
+	}
+}
+
+// grpcUnaryInFlightInterceptor keeps track of in-flight unary calls, so that they are waited for during shutdown
+func (gw *Handle) grpcUnaryInFlightInterceptor(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	gw.inFlightRequests.Add(1)
+	defer gw.inFlightRequests.Done()
+	return handler(ctx, req)
+}
+
+// grpcStreamInFlightInterceptor keeps track of in-flight streaming calls, so that they are waited for during shutdown
+func (gw *Handle) grpcStreamInFlightInterceptor(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	gw.inFlightRequests.Add(1)
+	defer gw.inFlightRequests.Done()
+	return handler(srv, ss)
+}
+
+// grpcHandler implements the gateway's grpc service
+type grpcHandler struct {
+	proto.UnimplementedGatewayServer
+	gw *Handle
+}
+
+// Track ingests a single track event
+func (h *grpcHandler) Track(ctx context.Context, req *proto.EventRequest) (*proto.Ack, error) {
+	return h.unary(ctx, "track", req.GetPayload())
+}
+
+// Identify ingests a single identify event
+func (h *grpcHandler) Identify(ctx context.Context, req *proto.EventRequest) (*proto.Ack, error) {
+	return h.unary(ctx, "identify", req.GetPayload())
+}
+
+// Batch ingests a batch of events
+func (h *grpcHandler) Batch(ctx context.Context, req *proto.BatchRequest) (*proto.Ack, error) {
+	return h.unary(ctx, "batch", payload.Batch(req.GetEvents()))
+}
+
+// Ingest enqueues every message of the stream as soon as it is received and, once the client closes the stream,
+// responds with an acknowledgement for each message, in the order they were received.
+func (h *grpcHandler) Ingest(stream proto.Gateway_IngestServer) error {
+	ctx := stream.Context()
+	rctx, err := h.gw.grpcRequestContext(ctx, "ingest")
+	if err != nil {
+		return err
+	}
+
+	type pendingAck struct {
+		sequence uint64
+		done     <-chan string
+	}
+	var (
+		pending []pendingAck
+		resp    = &proto.IngestResponse{}
+	)
+	ack := func(p pendingAck) {
+		select {
+		case errorMessage := <-p.done:
+			resp.Acks = append(resp.Acks, newAck(p.sequence, errorMessage))
+		case <-ctx.Done():
+			resp.Acks = append(resp.Acks, newAck(p.sequence, response.ContextDeadlineExceeded))
+		}
+	}
+	for {
+		msg, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		reqType := msg.GetType()
+		if reqType == "" {
+			reqType = "batch"
+			msg.Payload = payload.Batch([][]byte{msg.GetPayload()})
+		}
+		if _, ok := ingestRequestTypes[reqType]; !ok {
+			done := make(chan string, 1)
+			done <- response.InvalidRequestType
+			pending = append(pending, pendingAck{sequence: msg.GetSequence(), done: done})
+			continue
+		}
+		pending = append(pending, pendingAck{
+			sequence: msg.GetSequence(),
+			done:     h.gw.enqueueGrpcRequest(rctx, reqType, msg.GetPayload()),
+		})
+		// acknowledge the oldest messages if there are too many of them waiting
+		for len(pending) > h.gw.conf.grpcMaxIngestInFlight.Load() {
+			ack(pending[0])
+			pending = pending[1:]
+		}
+	}
+	for _, p := range pending {
+		ack(p)
+	}
+	for _, a := range resp.Acks {
+		h.gw.TrackRequestMetrics(a.GetError())
+	}
+	return stream.SendAndClose(resp)
+}
+
+// unary enqueues a single request and waits for its outcome
+func (h *grpcHandler) unary(ctx context.Context, reqType string, payload []byte) (*proto.Ack, error) {
+	rctx, err := h.gw.grpcRequestContext(ctx, reqType)
+	if err != nil {
+		return nil, err
+	}
+	ctx, span := h.gw.tracer.Start(ctx, "gw.grpcHandler", stats.SpanKindServer,
+		stats.SpanWithTags(stats.Tags{
+			"reqType":     reqType,
+			"workspaceId": rctx.authContext.WorkspaceID,
+			"sourceId":    rctx.authContext.SourceID,
+		}),
+	)
+	defer span.End()
+	rctx.traceParent = stats.GetTraceParentFromContext(ctx)
+
+	var errorMessage string
+	select {
+	case errorMessage = <-h.gw.enqueueGrpcRequest(rctx, reqType, payload):
+	case <-ctx.Done():
+		errorMessage = response.ContextDeadlineExceeded
+	}
+	h.gw.TrackRequestMetrics(errorMessage)
+	if errorMessage != "" {
+		span.SetStatus(stats.SpanStatusError, errorMessage)
+		return nil, status.Error(grpcCode(response.GetErrorStatusCode(errorMessage)), response.GetStatus(errorMessage))
+	}
+	return newAck(0, ""), nil
+}
+
+// grpcRequestContext holds the information of a grpc call that is needed for handing its messages to the user web request workers
+type grpcRequestContext struct {
+	authContext  *gwtypes.AuthRequestContext
+	userIDHeader string
+	ipAddr       string
+	traceParent  string
+}
+
+// grpcRequestContext authenticates the grpc call using the writeKey provided through basic authentication in the authorization metadata.
+// Failures are reported with the same stats as the http writeKey authentication middleware.
+func (gw *Handle) grpcRequestContext(ctx context.Context, reqType string) (*grpcRequestContext, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	mdValue := func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+
+	var (
+		errorMessage string
+		arctx        *gwtypes.AuthRequestContext
+	)
+	defer func() { gw.handleFailureStats(errorMessage, reqType, arctx) }()
+
+	writeKey, _, ok := (&http.Request{Header: http.Header{"Authorization": {mdValue("authorization")}}}).BasicAuth()
+	if !ok || writeKey == "" {
+		errorMessage = response.NoWriteKeyInBasicAuth
+		return nil, status.Error(codes.Unauthenticated, errorMessage)
+	}
+	arctx = gw.authRequestContextForWriteKey(writeKey)
+	if arctx == nil {
+		stat := gwstats.SourceStat{
+			Source:   "invalidWriteKey",
+			SourceID: "invalidWriteKey",
+			WriteKey: writeKey,
+			ReqType:  reqType,
+		}
+		stat.RequestFailed("invalidWriteKey")
+		stat.Report(gw.stats)
+		errorMessage = response.InvalidWriteKey
+		return nil, status.Error(codes.Unauthenticated, errorMessage)
+	}
+	if !arctx.SourceEnabled {
+		errorMessage = response.SourceDisabled
+		return nil, status.Error(codes.NotFound, errorMessage)
+	}
+	arctx.SourceJobRunID = mdValue("x-rudder-job-run-id")
+	arctx.SourceTaskRunID = mdValue("x-rudder-task-run-id")
+
+	rctx := &grpcRequestContext{
+		authContext:  arctx,
+		userIDHeader: mdValue("anonymousid"),
+		traceParent:  stats.GetTraceParentFromContext(ctx),
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		rctx.ipAddr = p.Addr.String()
+		if host, _, err := net.SplitHostPort(rctx.ipAddr); err == nil {
+			rctx.ipAddr = host
+		}
+	}
+	if forwardedFor := mdValue("x-forwarded-for"); forwardedFor != "" {
+		rctx.ipAddr = strings.TrimSpace(strings.Split(forwardedFor, ",")[0])
+	}
+	return rctx, nil
+}
+
+// enqueueGrpcRequest hands a grpc message to the user web request workers, returning the channel its outcome will be sent to
+func (gw *Handle) enqueueGrpcRequest(rctx *grpcRequestContext, reqType string, payload []byte) <-chan string {
+	done := make(chan string, 1)
+	if len(payload) == 0 {
+		done <- response.RequestBodyNil
+		return done
+	}
+	gw.enqueueWebRequest(&webRequestT{
+		done:           done,
+		reqType:        reqType,
+		requestPayload: payload,
+		authContext:    rctx.authContext,
+		traceParent:    rctx.traceParent,
+		ipAddr:         rctx.ipAddr,
+		userIDHeader:   rctx.userIDHeader,
+	})
+	return done
+}
+
+// newAck returns the acknowledgement of a message, given the gateway's error message for it
+func newAck(sequence uint64, errorMessage string) *proto.Ack {
+	if errorMessage == "" {
+		return &proto.Ack{Sequence: sequence, Status: http.StatusOK}
+	}
+	return &proto.Ack{
+		Sequence: sequence,
+		Status:   int32(response.GetErrorStatusCode(errorMessage)),
+		Error:    response.GetStatus(errorMessage),
+	}
+}
+
+// grpcCode maps a gateway http status code to its grpc equivalent
+func grpcCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusOK:
+		return codes.OK
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	default:
+		return codes.Internal
+	}
+}
@@ -0,0 +1,176 @@
+package gateway
+
+import (
+	"context"
+	"encoding/base64"
+	"net"
+	"time"
+
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/tidwall/gjson"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/rudderlabs/rudder-go-kit/config"
+	"github.com/rudderlabs/rudder-go-kit/logger"
+	"github.com/rudderlabs/rudder-go-kit/stats"
+
+	"github.com/rudderlabs/rudder-server/jobsdb"
+	proto "github.com/rudderlabs/rudder-server/proto/gateway"
+	sourcedebugger "github.com/rudderlabs/rudder-server/services/debugger/source"
+	"github.com/rudderlabs/rudder-server/services/rsources"
+	"github.com/rudderlabs/rudder-server/services/transformer"
+)
+
+var _ = Describe("Gateway gRPC", func() {
+	initGW()
+
+	var (
+		c       *testContext
+		gateway *Handle
+		srv     *grpc.Server
+		conn    *grpc.ClientConn
+		client  proto.GatewayClient
+	)
+
+	BeforeEach(func() {
+		c = &testContext{}
+		c.Setup()
+		c.initializeAppFeatures()
+
+		conf := config.New()
+		conf.Set("Gateway.enableRateLimit", false)
+		conf.Set("Gateway.enableEventSchemasFeature", false)
+		gateway = &Handle{}
+		err := gateway.Setup(context.Background(), conf, logger.NOP, stats.NOP, c.mockApp, c.mockBackendConfig, c.mockJobsDB, c.mockErrJobsDB, nil, c.mockVersionHandler, rsources.NewNoOpService(), transformer.NewNoOpService(), sourcedebugger.NewNoOpService(), nil)
+		Expect(err).To(BeNil())
+		waitForBackendConfigInit(gateway)
+
+		listener := bufconn.Listen(1024 * 1024)
+		srv = grpc.NewServer()
+		proto.RegisterGatewayServer(srv, &grpcHandler{gw: gateway})
+		go func() { _ = srv.Serve(listener) }()
+
+		conn, err = grpc.NewClient("passthrough:///bufnet",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		)
+		Expect(err).To(BeNil())
+		client = proto.NewGatewayClient(conn)
+	})
+
+	AfterEach(func() {
+		Expect(conn.Close()).To(BeNil())
+		srv.Stop()
+		Expect(gateway.Shutdown()).To(BeNil())
+		c.Finish()
+	})
+
+	authorizedContext := func(writeKey string) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(),
+			"authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(writeKey+":")),
+			"anonymousid", "094985f8-b4eb-43c3-bc8a-e8b75aae9c7c",
+		)
+	}
+
+	// expectStore expects jobs to be stored, sending the stored job batches to the returned channel
+	expectStore := func() <-chan [][]*jobsdb.JobT {
+		stored := make(chan [][]*jobsdb.JobT, 10)
+		c.mockJobsDB.EXPECT().WithStoreSafeTx(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(func(ctx context.Context, f func(tx jobsdb.StoreSafeTx) error) error {
+			return f(jobsdb.EmptyStoreSafeTx())
+		})
+		c.mockJobsDB.EXPECT().StoreEachBatchRetryInTx(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(func(ctx context.Context, tx jobsdb.StoreSafeTx, jobBatches [][]*jobsdb.JobT) (map[uuid.UUID]string, error) {
+			stored <- jobBatches
+			return jobsToEmptyErrors(ctx, tx, jobBatches)
+		})
+		return stored
+	}
+
+	It("should reject calls without a valid writeKey", func() {
+		_, err := client.Track(context.Background(), &proto.EventRequest{Payload: []byte(`{"userId":"dummyId"}`)})
+		Expect(status.Code(err)).To(Equal(codes.Unauthenticated))
+
+		_, err = client.Track(authorizedContext(WriteKeyInvalid), &proto.EventRequest{Payload: []byte(`{"userId":"dummyId"}`)})
+		Expect(status.Code(err)).To(Equal(codes.Unauthenticated))
+
+		_, err = client.Track(authorizedContext(WriteKeyDisabled), &proto.EventRequest{Payload: []byte(`{"userId":"dummyId"}`)})
+		Expect(status.Code(err)).To(Equal(codes.NotFound))
+	})
+
+	It("should ingest unary calls through the user web request workers", func() {
+		stored := expectStore()
+
+		ack, err := client.Track(authorizedContext(WriteKeyEnabled), &proto.EventRequest{Payload: []byte(`{"userId":"dummyId","event":"event-1"}`)})
+		Expect(err).To(BeNil())
+		Expect(ack.GetStatus()).To(BeEquivalentTo(200))
+		var jobBatches [][]*jobsdb.JobT
+		Eventually(stored).Should(Receive(&jobBatches))
+		Expect(gjson.GetBytes(jobBatches[0][0].EventPayload, "batch.0.type").String()).To(Equal("track"))
+		Expect(gjson.GetBytes(jobBatches[0][0].EventPayload, "writeKey").String()).To(Equal(WriteKeyEnabled))
+
+		ack, err = client.Batch(authorizedContext(WriteKeyEnabled), &proto.BatchRequest{Events: [][]byte{
+			[]byte(`{"userId":"dummyId","type":"identify"}`),
+			[]byte(`{"userId":"dummyId","type":"page"}`),
+		}})
+		Expect(err).To(BeNil())
+		Expect(ack.GetStatus()).To(BeEquivalentTo(200))
+
+		_, err = client.Identify(authorizedContext(WriteKeyEnabled), &proto.EventRequest{Payload: []byte(`{"userId":`)})
+		Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+		Expect(status.Convert(err).Message()).To(Equal("Invalid JSON"))
+	})
+
+	It("should stop forcefully once the shutdown timeout elapses", func() {
+		stream, err := client.Ingest(authorizedContext(WriteKeyEnabled))
+		Expect(err).To(BeNil())
+		Expect(stream.Send(&proto.IngestRequest{Sequence: 1, Type: "unknown", Payload: []byte(`{"userId":"dummyId"}`)})).To(BeNil())
+
+		Expect(stopGrpcServer(srv, 10*time.Millisecond)).To(BeFalse())
+		_, err = stream.CloseAndRecv()
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("should stop gracefully without in-flight calls", func() {
+		Expect(stopGrpcServer(srv, time.Second)).To(BeTrue())
+	})
+
+	It("should acknowledge every message of an Ingest stream", func() {
+		expectStore()
+
+		stream, err := client.Ingest(authorizedContext(WriteKeyEnabled))
+		Expect(err).To(BeNil())
+		for _, msg := range []*proto.IngestRequest{
+			{Sequence: 1, Type: "track", Payload: []byte(`{"userId":"dummyId","event":"event-1"}`)},
+			{Sequence: 2, Type: "identify", Payload: []byte(`{"userId":`)},
+			{Sequence: 3, Payload: []byte(`{"userId":"dummyId","type":"page"}`)},
+			{Sequence: 4, Type: "unknown", Payload: []byte(`{"userId":"dummyId"}`)},
+			{Sequence: 5, Type: "batch", Payload: []byte(`{"batch":[{"userId":"dummyId","type":"screen"}]}`)},
+		} {
+			Expect(stream.Send(msg)).To(BeNil())
+		}
+		resp, err := stream.CloseAndRecv()
+		Expect(err).To(BeNil())
+		Expect(resp.GetAcks()).To(HaveLen(5))
+		for i, expected := range []struct {
+			status int32
+			error  string
+		}{
+			{status: 200},
+			{status: 400, error: "Invalid JSON"},
+			{status: 200},
+			{status: 400, error: "Invalid request type"},
+			{status: 200},
+		} {
+			Expect(resp.GetAcks()[i].GetSequence()).To(BeEquivalentTo(i + 1))
+			Expect(resp.GetAcks()[i].GetStatus()).To(Equal(expected.status))
+			Expect(resp.GetAcks()[i].GetError()).To(Equal(expected.error))
+		}
+	})
+})
@@ -0,0 +1,316 @@
+package gateway
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/klauspost/compress/zstd"
+	"github.com/samber/lo"
+	"github.com/tidwall/gjson"
+
+	kithttputil "github.com/rudderlabs/rudder-go-kit/httputil"
+	"github.com/rudderlabs/rudder-go-kit/stats"
+
+	gwstats "github.com/rudderlabs/rudder-server/gateway/internal/stats"
+	gwtypes "github.com/rudderlabs/rudder-server/gateway/internal/types"
+	"github.com/rudderlabs/rudder-server/gateway/response"
+	"github.com/rudderlabs/rudder-server/jobsdb"
+	"github.com/rudderlabs/rudder-server/services/rsources"
+)
+
+// bulkResponse reports the outcome of a bulk request, line by line
+type bulkResponse struct {
+	Lines      int             `json:"lines"`      // number of non-empty lines read from the stream
+	Succeeded  int             `json:"succeeded"`  // number of lines stored successfully
+	Suppressed int             `json:"suppressed"` // number of lines belonging to suppressed users
+	BotDropped int             `json:"botDropped"` // number of lines dropped as bot traffic
+	Failed     int             `json:"failed"`     // number of lines that failed
+	Errors     []bulkLineError `json:"errors"`
+	Error      string          `json:"error,omitempty"` // set if the stream couldn't be read until its end
+}
+
+// bulkLineError is the error of a single line of a bulk request, [Line] being 1-based
+type bulkLineError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// webBulkHandler - handler for bulk requests, streaming newline-delimited json events
+func (gw *Handle) webBulkHandler() http.HandlerFunc {
+	return gw.callType("bulk", gw.writeKeyAuth(gw.bulkRequestHandler))
+}
+
+// bulkRequestHandler reads a stream of newline-delimited json events, optionally gzip or zstd compressed,
+// without buffering the whole request body in memory. Every line is handled as a batch of a single event,
+// going through the same validations, suppression and rate limiting as the rest of the web handlers.
+// Valid events are stored in chunks of [Gateway.bulk.chunkSize] lines and the response contains an error report for every line that failed.
+func (gw *Handle) bulkRequestHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqType := ctx.Value(gwtypes.CtxParamCallType).(string)
+	arctx := ctx.Value(gwtypes.CtxParamAuthRequestContext).(*gwtypes.AuthRequestContext)
+
+	ctx, span := gw.tracer.Start(ctx, "gw.bulkRequestHandler", stats.SpanKindServer,
+		stats.SpanWithTimestamp(time.Now()),
+		stats.SpanWithTags(stats.Tags{
+			"reqType":     reqType,
+			"path":        r.URL.Path,
+			"workspaceId": arctx.WorkspaceID,
+			"sourceId":    arctx.SourceID,
+		}),
+	)
+	defer span.End()
+	r = r.WithContext(ctx)
+	gw.logger.LogRequest(r)
+
+	stat := gwstats.SourceStat{
+		Source:      arctx.SourceTag(),
+		WriteKey:    arctx.WriteKey,
+		ReqType:     reqType,
+		SourceID:    arctx.SourceID,
+		WorkspaceID: arctx.WorkspaceID,
+		SourceType:  arctx.SourceCategory,
+	}
+	defer func() { stat.Report(gw.stats) }()
+
+	body, err := bulkRequestBody(r)
+	if err != nil {
+		stat.RequestFailed("requestBodyReadFailed")
+		span.SetStatus(stats.SpanStatusError, err.Error())
+		gw.handleHttpError(w, r, err.Error())
+		return
+	}
+	defer func() { _ = body.Close() }()
+
+	// bulk requests can take longer than the server's read & write timeouts
+	rc := http.NewResponseController(w)
+	deadline := time.Now().Add(gw.conf.bulkTimeout.Load())
+	_ = rc.SetReadDeadline(deadline)
+	_ = rc.SetWriteDeadline(deadline)
+
+	var (
+		resp         bulkResponse
+		reader       = bufio.NewReader(body)
+		lineNum      int
+		jobBatches   [][]*jobsdb.JobT
+		batchLines   = make(map[uuid.UUID]int)
+		userIDHeader = r.Header.Get("AnonymousId")
+		ipAddr       = kithttputil.GetRequestIP(r)
+		traceParent  = stats.GetTraceParentFromContext(ctx)
+		retryAfter   time.Duration // longest wait among rate limited lines
+	)
+	lineFailed := func(line int, errorMessage string) {
+		resp.Failed++
+		resp.Errors = append(resp.Errors, bulkLineError{Line: line, Error: errorMessage})
+		stat.RequestEventsFailed(1, errorMessage)
+	}
+	flush := func() {
+		if len(jobBatches) == 0 {
+			return
+		}
+		errorMessagesMap := gw.storeBulkJobBatches(ctx, jobBatches)
+		for _, batch := range jobBatches {
+			if errorMessage, found := errorMessagesMap[batch[0].UUID]; found {
+				lineFailed(batchLines[batch[0].UUID], errorMessage)
+				continue
+			}
+			resp.Succeeded++
+			stat.RequestEventsSucceeded(len(batch))
+			for _, job := range batch {
+				gw.sourcehandle.RecordEvent(arctx.WriteKey, job.EventPayload)
+			}
+		}
+		jobBatches = nil
+		batchLines = make(map[uuid.UUID]int)
+	}
+
+	for {
+		line, tooLarge, err := readBulkLine(reader, gw.conf.maxReqSize.Load())
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				gw.logger.Warnw("reading bulk request body", "line", lineNum+1, "error", err)
+				resp.Error = response.RequestBodyReadFailed
+			}
+			break
+		}
+		lineNum++
+		if !tooLarge && len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		resp.Lines++
+
+		var rejectReason string
+		switch {
+		case tooLarge:
+			rejectReason = response.RequestBodyTooLarge
+		case !gjson.ValidBytes(line):
+			rejectReason = response.InvalidJSON
+		case !gjson.ParseBytes(line).IsObject():
+			rejectReason = response.NotRudderEvent
+		}
+		if rejectReason != "" {
+			lineFailed(lineNum, rejectReason)
+			gw.recordRejectedRequest(&webRequestT{reqType: "batch", requestPayload: line, authContext: arctx, ipAddr: ipAddr}, rejectReason)
+			continue
+		}
+
+		payload := make([]byte, 0, len(line)+len(`{"batch":[]}`))
+		payload = append(payload, `{"batch":[`...)
+		payload = append(payload, line...)
+		payload = append(payload, `]}`...)
+		req := &webRequestT{
+			reqType:        "batch",
+			requestPayload: payload,
+			authContext:    arctx,
+			traceParent:    traceParent,
+			ipAddr:         ipAddr,
+			userIDHeader:   userIDHeader,
+		}
+		jobData, err := gw.getJobDataFromRequest(req)
+		stat.RequestEventsBot(jobData.botEvents)
+		if err != nil {
+			switch {
+			case errors.Is(err, errRequestDropped):
+				retryAfter = max(retryAfter, jobData.retryAfter)
+				lineFailed(lineNum, response.TooManyRequests)
+			case errors.Is(err, errRequestSuppressed):
+				resp.Suppressed++
+				stat.RequestSuppressed()
+				gw.recordRejectedRequest(req, err.Error())
+			case errors.Is(err, errRequestBotDropped):
+				resp.BotDropped++
+				stat.RequestBotDropped()
+			default:
+				lineFailed(lineNum, err.Error())
+				gw.recordRejectedRequest(req, err.Error())
+			}
+			continue
+		}
+		if len(jobData.jobs) == 0 {
+			lineFailed(lineNum, response.EmptyBatchPayload)
+			continue
+		}
+		jobBatches = append(jobBatches, jobData.jobs)
+		batchLines[jobData.jobs[0].UUID] = lineNum
+		if len(jobBatches) >= gw.conf.bulkChunkSize.Load() {
+			flush()
+		}
+	}
+	flush()
+
+	sort.Slice(resp.Errors, func(i, j int) bool { return resp.Errors[i].Line < resp.Errors[j].Line })
+	if resp.Errors == nil {
+		resp.Errors = []bulkLineError{}
+	}
+	status := http.StatusOK
+	if resp.Error != "" {
+		span.SetStatus(stats.SpanStatusError, resp.Error)
+		status = response.GetErrorStatusCode(resp.Error)
+	}
+	gw.TrackRequestMetrics(resp.Error)
+	gw.logger.Debugw("response",
+		"ip", ipAddr,
+		"path", r.URL.Path,
+		"status", status,
+		"lines", resp.Lines,
+		"failed", resp.Failed)
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	setRetryAfter(&w, retryAfter)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// storeBulkJobBatches stores the job batches of a bulk request in a single transaction,
+// returning the error messages of the batches that couldn't be stored, keyed by the uuid of each batch's first job.
+func (gw *Handle) storeBulkJobBatches(ctx context.Context, jobBatches [][]*jobsdb.JobT) map[uuid.UUID]string {
+	ctx, cancel := context.WithTimeout(ctx, gw.conf.WriteTimeout)
+	defer cancel()
+	defer gw.dbWritesStat.Count(1)
+	var errorMessagesMap map[uuid.UUID]string
+	err := gw.jobsDB.WithStoreSafeTx(ctx, func(tx jobsdb.StoreSafeTx) error {
+		var err error
+		errorMessagesMap, err = gw.jobsDB.StoreEachBatchRetryInTx(ctx, tx, jobBatches)
+		if err != nil {
+			return err
+		}
+		// rsources stats
+		rsourcesStats := rsources.NewStatsCollector(gw.rsourcesService, rsources.IgnoreDestinationID())
+		rsourcesStats.JobsStoredWithErrors(lo.Flatten(jobBatches), errorMessagesMap)
+		return rsourcesStats.Publish(ctx, tx.SqlTx())
+	})
+	if err != nil {
+		gw.logger.Errorw("storing bulk request jobs", "error", err)
+		errorMessage := err.Error()
+		if ctx.Err() != nil {
+			errorMessage = ctx.Err().Error()
+		}
+		errorMessagesMap = make(map[uuid.UUID]string, len(jobBatches))
+		for _, batch := range jobBatches {
+			errorMessagesMap[batch[0].UUID] = errorMessage
+		}
+	}
+	return errorMessagesMap
+}
+
+// bulkRequestBody returns a reader for the request's body, decompressing it according to its Content-Encoding header
+func bulkRequestBody(r *http.Request) (io.ReadCloser, error) {
+	if r.Body == nil {
+		return nil, errors.New(response.RequestBodyNil)
+	}
+	switch strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding"))) {
+	case "", "identity":
+		return r.Body, nil
+	case "gzip":
+		gr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return nil, errors.New(response.InvalidCompressedBody)
+		}
+		return gr, nil
+	case "zstd":
+		zr, err := zstd.NewReader(r.Body)
+		if err != nil {
+			return nil, errors.New(response.InvalidCompressedBody)
+		}
+		return zr.IOReadCloser(), nil
+	default:
+		return nil, errors.New(response.UnsupportedContentEncoding)
+	}
+}
+
+// readBulkLine reads the next line from the reader, without its line terminator.
+// Lines larger than maxSize are consumed but not returned, with tooLarge being set instead.
+// It returns [io.EOF] only when no more lines are available.
+func readBulkLine(reader *bufio.Reader, maxSize int) (line []byte, tooLarge bool, err error) {
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		if !tooLarge {
+			if len(line)+len(bytes.TrimRight(chunk, "\r\n")) > maxSize {
+				tooLarge = true
+				line = nil
+			} else {
+				line = append(line, chunk...)
+			}
+		}
+		switch {
+		case errors.Is(err, bufio.ErrBufferFull):
+			continue
+		case errors.Is(err, io.EOF):
+			if len(line) > 0 || tooLarge {
+				return bytes.TrimRight(line, "\r\n"), tooLarge, nil
+			}
+			return nil, false, io.EOF
+		case err != nil:
+			return nil, false, err
+		}
+		return bytes.TrimRight(line, "\r\n"), tooLarge, nil
+	}
+}
@@ -1,8 +1,107 @@
 package gateway
 
-import "net/http"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	obskit "github.com/rudderlabs/rudder-observability-kit/go/labels"
+
+	"github.com/rudderlabs/rudder-server/gateway/internal/deadletter"
+	gwtypes "github.com/rudderlabs/rudder-server/gateway/internal/types"
+)
 
 // webReplayHandler can handle replay requests
 func (gw *Handle) webReplayHandler() http.HandlerFunc {
 	return gw.callType("replay", gw.replaySourceIDAuth(gw.webHandler()))
 }
+
+// webReplayRejectedHandler can handle requests for replaying rejected requests, which have been uploaded to object storage
+func (gw *Handle) webReplayRejectedHandler() http.HandlerFunc {
+	return gw.callType("replay", gw.replaySourceIDAuth(gw.replayRejectedRequests))
+}
+
+// replayRejectedRequests downloads the rejected requests file with the requested object key from the storage of the replay source's workspace
+// and replays the rejected requests of the replay source's original source through the replay source.
+// Entries which cannot be replayed as is, e.g. truncated or malformed payloads, are skipped.
+func (gw *Handle) replayRejectedRequests(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	arctx := ctx.Value(gwtypes.CtxParamAuthRequestContext).(*gwtypes.AuthRequestContext)
+	if gw.fileUploaderProvider == nil {
+		http.Error(w, "rejected requests are not uploaded", http.StatusBadRequest)
+		return
+	}
+	var req struct {
+		ObjectKey string `json:"objectKey"`
+	}
+	payload, err := gw.getPayloadFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := json.Unmarshal(payload, &req); err != nil || req.ObjectKey == "" {
+		http.Error(w, "objectKey is required", http.StatusBadRequest)
+		return
+	}
+	gw.configSubscriberLock.RLock()
+	originalSourceID := gw.sourceIDSourceMap[arctx.SourceID].OriginalID
+	gw.configSubscriberLock.RUnlock()
+
+	entries, err := gw.downloadRejectedRequests(r, arctx.WorkspaceID, req.ObjectKey)
+	if err != nil {
+		gw.logger.Errorn("Downloading rejected requests", obskit.WorkspaceID(arctx.WorkspaceID), obskit.Error(err))
+		http.Error(w, "downloading rejected requests failed", http.StatusInternalServerError)
+		return
+	}
+
+	var res struct {
+		Replayed int `json:"replayed"`
+		Skipped  int `json:"skipped"`
+		Failed   int `json:"failed"`
+	}
+	for _, entry := range entries {
+		if entry.WorkspaceID != arctx.WorkspaceID || entry.SourceID != originalSourceID {
+			res.Skipped++
+			continue
+		}
+		payload, err := entry.ReplayPayload()
+		if err != nil {
+			res.Skipped++
+			continue
+		}
+		if errorMessage := gw.rrh.ProcessRequest(&w, r, "replay", payload, arctx); errorMessage != "" {
+			gw.logger.Warnn("Replaying rejected request", obskit.WorkspaceID(arctx.WorkspaceID), obskit.SourceID(arctx.SourceID), obskit.Error(errors.New(errorMessage)))
+			res.Failed++
+			continue
+		}
+		res.Replayed++
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+// downloadRejectedRequests downloads and reads the rejected requests file with the given object key from the workspace's storage
+func (gw *Handle) downloadRejectedRequests(r *http.Request, workspaceID, objectKey string) ([]deadletter.Entry, error) {
+	fm, err := gw.fileUploaderProvider.GetFileManager(r.Context(), workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("getting file manager: %w", err)
+	}
+	file, err := os.CreateTemp("", "rudder-gw-rejected-requests")
+	if err != nil {
+		return nil, fmt.Errorf("creating temp file: %w", err)
+	}
+	defer func() {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+	}()
+	if err := fm.Download(r.Context(), file, objectKey); err != nil {
+		return nil, fmt.Errorf("downloading %q: %w", objectKey, err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("seeking %q: %w", file.Name(), err)
+	}
+	return deadletter.ReadEntries(file)
+}
@@ -0,0 +1,29 @@
+package gateway
+
+import (
+	"context"
+	"time"
+
+	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
+	"github.com/rudderlabs/rudder-server/gateway/response"
+)
+
+// IngestBatch hands a batch payload consumed from a kafka source to the user web request workers and waits for its outcome,
+// so that kafka sources go through the same validation, throttling and storage as batch requests.
+func (gw *Handle) IngestBatch(ctx context.Context, source *backendconfig.SourceT, payload []byte) (string, time.Duration) {
+	done := make(chan string, 1)
+	req := &webRequestT{
+		done:           done,
+		reqType:        "batch",
+		requestPayload: payload,
+		authContext:    sourceToRequestContext(*source),
+	}
+	gw.enqueueWebRequest(req)
+	select {
+	case errorMessage := <-done:
+		gw.TrackRequestMetrics(errorMessage)
+		return errorMessage, req.retryAfter
+	case <-ctx.Done():
+		return response.ContextDeadlineExceeded, 0
+	}
+}
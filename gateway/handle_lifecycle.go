@@ -26,12 +26,16 @@ import (
 	"github.com/rudderlabs/rudder-go-kit/stats"
 	"github.com/rudderlabs/rudder-server/app"
 	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
+	"github.com/rudderlabs/rudder-server/gateway/internal/bot"
+	"github.com/rudderlabs/rudder-server/gateway/internal/deadletter"
+	"github.com/rudderlabs/rudder-server/gateway/kafkasource"
 	"github.com/rudderlabs/rudder-server/gateway/throttler"
 	"github.com/rudderlabs/rudder-server/gateway/webhook"
 	"github.com/rudderlabs/rudder-server/jobsdb"
 	"github.com/rudderlabs/rudder-server/middleware"
 	sourcedebugger "github.com/rudderlabs/rudder-server/services/debugger/source"
 	"github.com/rudderlabs/rudder-server/services/diagnostics"
+	"github.com/rudderlabs/rudder-server/services/fileuploader"
 	"github.com/rudderlabs/rudder-server/services/rsources"
 	rsources_http "github.com/rudderlabs/rudder-server/services/rsources/http"
 	"github.com/rudderlabs/rudder-server/services/transformer"
@@ -102,6 +106,28 @@ func (gw *Handle) Setup(
 	gw.conf.maxHeaderBytes = config.GetIntVar(524288, 1, "MaxHeaderBytes")
 	// if set to '0', it means disabled.
 	gw.conf.maxConcurrentRequests = config.GetIntVar(50000, 1, "Gateway.maxConcurrentRequests")
+	// Number of lines of a bulk request that are stored together
+	gw.conf.bulkChunkSize = config.GetReloadableIntVar(500, 1, "Gateway.bulk.chunkSize")
+	// Read & write timeout for bulk requests, overriding the server's timeouts
+	gw.conf.bulkTimeout = config.GetReloadableDurationVar(10, time.Minute, "Gateway.bulk.timeout")
+	// Enables the grpc ingestion api. false by default
+	gw.conf.grpcEnabled = config.GetBoolVar(false, "Gateway.grpc.enabled")
+	// Port where the grpc ingestion api is listening
+	gw.conf.grpcPort = config.GetIntVar(8083, 1, "Gateway.grpc.port")
+	// Number of messages of an Ingest stream that can be waiting for their acknowledgement
+	gw.conf.grpcMaxIngestInFlight = config.GetReloadableIntVar(10000, 1, "Gateway.grpc.maxIngestInFlight")
+	// Time to wait for in-flight grpc calls to finish during shutdown, before closing their connections
+	gw.conf.grpcShutdownTimeout = config.GetReloadableDurationVar(10, time.Second, "Gateway.grpc.shutdownTimeout")
+
+	// Rules for detecting bot traffic, matching user agents containing bot keywords if no rules file is provided
+	gw.botRules = bot.DefaultRules()
+	if rulesFile := config.GetStringVar("", "Gateway.bot.rulesFile"); rulesFile != "" {
+		botRules, err := bot.LoadRules(rulesFile)
+		if err != nil {
+			return fmt.Errorf("could not load bot rules: %w", err)
+		}
+		gw.botRules = botRules
+	}
 
 	// Registering stats
 	gw.batchSizeStat = gw.stats.NewStat("gateway.batch_size", stats.HistogramType)
@@ -170,11 +196,33 @@ func (gw *Handle) Setup(
 		gw.collectMetrics(ctx)
 		return nil
 	}))
+	// Whether to upload requests rejected due to validation errors to the object storage of their workspace
+	if config.GetBoolVar(false, "Gateway.rejectedRequests.enabled") {
+		if gw.fileUploaderProvider == nil {
+			gw.fileUploaderProvider = fileuploader.NewProvider(ctx, backendConfig)
+		}
+		gw.rejectedRequests = deadletter.New(config, gw.logger, gw.stats, gw.fileUploaderProvider)
+		g.Go(crash.Wrapper(func() error {
+			gw.rejectedRequests.Run(ctx)
+			return nil
+		}))
+	}
+	// Whether to consume events from kafka sources
+	if config.GetBoolVar(false, "Gateway.kafkaSource.enabled") {
+		gw.kafkaSources = kafkasource.New(config, gw.logger, gw.stats, gw)
+	}
 	return nil
 }
 
 type OptFunc func(*Handle)
 
+// WithFileUploaderProvider sets the provider used for uploading rejected requests to object storage
+func WithFileUploaderProvider(provider fileuploader.Provider) OptFunc {
+	return func(gw *Handle) {
+		gw.fileUploaderProvider = provider
+	}
+}
+
 func WithInternalHttpHandlers(handlers map[string]http.Handler) OptFunc {
 	return func(gw *Handle) {
 		gw.internalHttpHandlers = handlers
@@ -219,12 +267,14 @@ func (gw *Handle) backendConfigSubscriber(ctx context.Context) {
 		var (
 			writeKeysSourceMap = map[string]backendconfig.SourceT{}
 			sourceIDSourceMap  = map[string]backendconfig.SourceT{}
+			sources            []backendconfig.SourceT
 		)
 		configData := data.Data.(map[string]backendconfig.ConfigT)
 		for _, wsConfig := range configData {
 			for _, source := range wsConfig.Sources {
 				writeKeysSourceMap[source.WriteKey] = source
 				sourceIDSourceMap[source.ID] = source
+				sources = append(sources, source)
 				if source.Enabled && source.SourceDefinition.Category == "webhook" {
 					gw.webhook.Register(source.SourceDefinition.Name)
 				}
@@ -234,6 +284,9 @@ func (gw *Handle) backendConfigSubscriber(ctx context.Context) {
 		gw.writeKeysSourceMap = writeKeysSourceMap
 		gw.sourceIDSourceMap = sourceIDSourceMap
 		gw.configSubscriberLock.Unlock()
+		if gw.kafkaSources != nil {
+			gw.kafkaSources.Sync(sources)
+		}
 		closeConfigChan(len(gw.writeKeysSourceMap))
 	}
 }
@@ -408,6 +461,7 @@ func (gw *Handle) StartWebHandler(ctx context.Context) error {
 		r.Get("/v1/warehouse/fetch-tables", gw.whProxy.ServeHTTP)
 		r.Post("/v1/audiencelist", gw.webAudienceListHandler())
 		r.Post("/v1/replay", gw.webReplayHandler())
+		r.Post("/v1/replay/rejected", gw.webReplayRejectedHandler())
 		r.Post("/v1/batch", gw.internalBatchHandler())
 
 		// TODO: delete this handler once we are ready to remove support for the v1 api
@@ -434,6 +488,7 @@ func (gw *Handle) StartWebHandler(ctx context.Context) error {
 		r.Post("/track", gw.webTrackHandler())
 
 		r.Post("/import", gw.webImportHandler())
+		r.Post("/bulk", gw.webBulkHandler())
 		r.Post("/webhook", gw.webhookHandler())
 
 		r.Get("/webhook", gw.webhookHandler())
@@ -496,6 +551,9 @@ func (gw *Handle) Shutdown() error {
 	if err := gw.webhook.Shutdown(); err != nil {
 		return err
 	}
+	if gw.kafkaSources != nil {
+		gw.kafkaSources.Shutdown()
+	}
 
 	gw.inFlightRequests.Wait()
 
@@ -5,10 +5,12 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 
+	kithttputil "github.com/rudderlabs/rudder-go-kit/httputil"
 	kituuid "github.com/rudderlabs/rudder-go-kit/uuid"
 
 	gwtypes "github.com/rudderlabs/rudder-server/gateway/internal/types"
@@ -21,15 +23,17 @@ type ImportRequestHandler struct {
 }
 
 // ProcessRequest on ImportRequestHandler splits payload by user and throws them into the webrequestQ and waits for all their responses before returning
-func (irh *ImportRequestHandler) ProcessRequest(w *http.ResponseWriter, r *http.Request, _ string, payload []byte, arctx *gwtypes.AuthRequestContext) string {
+func (irh *ImportRequestHandler) ProcessRequest(w *http.ResponseWriter, r *http.Request, reqType string, payload []byte, arctx *gwtypes.AuthRequestContext) string {
 	usersPayload, payloadError := getUsersPayload(payload)
 	if payloadError != nil {
+		irh.recordRejectedRequest(&webRequestT{reqType: reqType, requestPayload: payload, authContext: arctx, ipAddr: kithttputil.GetRequestIP(r)}, payloadError.Error())
 		return payloadError.Error()
 	}
 	count := len(usersPayload)
 	done := make(chan string, count)
+	webReqs := make([]*webRequestT, 0, count)
 	for key := range usersPayload {
-		irh.addToWebRequestQ(w, r, done, "batch", usersPayload[key], arctx)
+		webReqs = append(webReqs, irh.addToWebRequestQ(w, r, done, "batch", usersPayload[key], arctx))
 	}
 
 	var interimMsgs []string
@@ -37,6 +41,11 @@ func (irh *ImportRequestHandler) ProcessRequest(w *http.ResponseWriter, r *http.
 		interimErrorMessage := <-done
 		interimMsgs = append(interimMsgs, interimErrorMessage)
 	}
+	var retryAfter time.Duration
+	for _, webReq := range webReqs {
+		retryAfter = max(retryAfter, webReq.retryAfter)
+	}
+	setRetryAfter(w, retryAfter)
 	return strings.Join(interimMsgs, "")
 }
 
@@ -1,6 +1,13 @@
 package bot
 
-import "strings"
+import (
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"os"
+	"regexp"
+	"strings"
+)
 
 var botKeyWords = []string{
 	"bot",
@@ -8,12 +15,151 @@ var botKeyWords = []string{
 	"spider",
 }
 
-func IsBotUserAgent(userAgent string) bool {
-	lowerUserAgent := strings.ToLower(userAgent)
+var defaultRules = func() *Rules {
+	r := &Rules{}
 	for _, keyword := range botKeyWords {
-		if strings.Contains(lowerUserAgent, keyword) {
-			return true
+		r.userAgents = append(r.userAgents, userAgentRule{name: keyword, pattern: regexp.MustCompile("(?i)" + regexp.QuoteMeta(keyword))})
+	}
+	return r
+}()
+
+// DefaultRules returns the rules used when no rules file is provided, matching user agents containing any of the bot keywords
+func DefaultRules() *Rules {
+	return defaultRules
+}
+
+// IsBotUserAgent returns true if the user agent matches any of the default rules
+func IsBotUserAgent(userAgent string) bool {
+	_, ok := defaultRules.Match(userAgent, "")
+	return ok
+}
+
+// RulesConfig is the format of a rules file.
+//
+// User agent rules follow the IAB spiders & bots list approach: a user agent matches a rule
+// if it matches its (case-insensitive) pattern and none of its exceptions.
+type RulesConfig struct {
+	UserAgents []UserAgentRuleConfig `json:"userAgents"`
+	IPRanges   []IPRangeRuleConfig   `json:"ipRanges"`
+}
+
+type UserAgentRuleConfig struct {
+	Name       string   `json:"name"`
+	Pattern    string   `json:"pattern"`
+	Exceptions []string `json:"exceptions"`
+}
+
+type IPRangeRuleConfig struct {
+	Name  string   `json:"name"`
+	CIDRs []string `json:"cidrs"`
+}
+
+// Rules detects bot traffic using user agent patterns and ip ranges
+type Rules struct {
+	userAgents []userAgentRule
+	ipRanges   []ipRangeRule
+}
+
+type userAgentRule struct {
+	name       string
+	pattern    *regexp.Regexp
+	exceptions []*regexp.Regexp
+}
+
+type ipRangeRule struct {
+	name     string
+	prefixes []netip.Prefix
+}
+
+// LoadRules loads the rules from the json rules file at the given path
+func LoadRules(path string) (*Rules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading bot rules file: %w", err)
+	}
+	var conf RulesConfig
+	if err := json.Unmarshal(data, &conf); err != nil {
+		return nil, fmt.Errorf("parsing bot rules file: %w", err)
+	}
+	return NewRules(conf)
+}
+
+// NewRules compiles the provided rules configuration
+func NewRules(conf RulesConfig) (*Rules, error) {
+	compile := func(pattern string) (*regexp.Regexp, error) {
+		return regexp.Compile("(?i)" + pattern)
+	}
+	r := &Rules{}
+	for _, uaConf := range conf.UserAgents {
+		if uaConf.Name == "" {
+			return nil, fmt.Errorf("user agent rule with pattern %q has no name", uaConf.Pattern)
+		}
+		pattern, err := compile(uaConf.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("user agent rule %q: %w", uaConf.Name, err)
+		}
+		rule := userAgentRule{name: uaConf.Name, pattern: pattern}
+		for _, exception := range uaConf.Exceptions {
+			e, err := compile(exception)
+			if err != nil {
+				return nil, fmt.Errorf("user agent rule %q exception: %w", uaConf.Name, err)
+			}
+			rule.exceptions = append(rule.exceptions, e)
+		}
+		r.userAgents = append(r.userAgents, rule)
+	}
+	for _, ipConf := range conf.IPRanges {
+		if ipConf.Name == "" {
+			return nil, fmt.Errorf("ip range rule with cidrs %v has no name", ipConf.CIDRs)
+		}
+		rule := ipRangeRule{name: ipConf.Name}
+		for _, cidr := range ipConf.CIDRs {
+			prefix, err := netip.ParsePrefix(strings.TrimSpace(cidr))
+			if err != nil {
+				return nil, fmt.Errorf("ip range rule %q: %w", ipConf.Name, err)
+			}
+			rule.prefixes = append(rule.prefixes, prefix.Masked())
+		}
+		r.ipRanges = append(r.ipRanges, rule)
+	}
+	return r, nil
+}
+
+// Match returns the name of the first rule matching either the user agent or the ip address.
+// User agent rules are evaluated before ip range rules, in the order they were configured.
+func (r *Rules) Match(userAgent, ip string) (rule string, ok bool) {
+	if userAgent != "" {
+		for _, uaRule := range r.userAgents {
+			if uaRule.matches(userAgent) {
+				return uaRule.name, true
+			}
+		}
+	}
+	if ip != "" && len(r.ipRanges) > 0 {
+		addr, err := netip.ParseAddr(ip)
+		if err != nil {
+			return "", false
+		}
+		addr = addr.Unmap()
+		for _, ipRule := range r.ipRanges {
+			for _, prefix := range ipRule.prefixes {
+				if prefix.Contains(addr) {
+					return ipRule.name, true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+func (r *userAgentRule) matches(userAgent string) bool {
+	if !r.pattern.MatchString(userAgent) {
+		return false
+	}
+	for _, exception := range r.exceptions {
+		if exception.MatchString(userAgent) {
+			return false
 		}
 	}
-	return false
+	return true
 }
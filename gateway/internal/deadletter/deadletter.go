@@ -0,0 +1,266 @@
+// Package deadletter persists requests rejected by the gateway in object storage.
+//
+// The raw bodies of rejected requests are stored along with their rejection reason as gzipped json lines
+// in the storage bucket of their workspace, so that they can be inspected for debugging SDK issues and
+// later replayed through the gateway's replay endpoint, see [ReadEntries] and [Entry.ReplayPayload].
+package deadletter
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+
+	"github.com/rudderlabs/rudder-go-kit/config"
+	"github.com/rudderlabs/rudder-go-kit/logger"
+	"github.com/rudderlabs/rudder-go-kit/stats"
+	obskit "github.com/rudderlabs/rudder-observability-kit/go/labels"
+
+	"github.com/rudderlabs/rudder-server/services/fileuploader"
+	"github.com/rudderlabs/rudder-server/utils/misc"
+)
+
+// Entry is a request rejected by the gateway
+type Entry struct {
+	WorkspaceID string    `json:"workspaceId"`
+	SourceID    string    `json:"sourceId"`
+	ReqType     string    `json:"reqType"`
+	Reason      string    `json:"reason"`
+	IPAddr      string    `json:"ipAddr"`
+	ReceivedAt  time.Time `json:"receivedAt"`
+	Payload     string    `json:"payload"`   // raw body of the request, which might not be valid json
+	Truncated   bool      `json:"truncated"` // true if the payload has been truncated to the maximum stored size
+}
+
+// ErrNotReplayable is returned for entries whose payload cannot be replayed as is
+var ErrNotReplayable = errors.New("rejected request is not replayable")
+
+// ReplayPayload returns the payload of the entry as a batch payload accepted by the gateway's replay endpoint.
+// Truncated payloads, payloads which aren't valid json and payloads of internal batch requests cannot be replayed.
+func (e Entry) ReplayPayload() ([]byte, error) {
+	if e.Truncated {
+		return nil, fmt.Errorf("%w: payload is truncated", ErrNotReplayable)
+	}
+	if !gjson.Valid(e.Payload) {
+		return nil, fmt.Errorf("%w: payload is not valid json", ErrNotReplayable)
+	}
+	switch e.ReqType {
+	case "batch", "import", "replay", "retl":
+		return []byte(e.Payload), nil
+	case "internalBatch":
+		return nil, fmt.Errorf("%w: unsupported request type %q", ErrNotReplayable, e.ReqType)
+	default: // single event requests
+		event, err := sjson.Set(e.Payload, "type", e.ReqType)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrNotReplayable, err)
+		}
+		payload, err := sjson.SetRaw(`{"batch":[]}`, "batch.0", event)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrNotReplayable, err)
+		}
+		return []byte(payload), nil
+	}
+}
+
+// ReadEntries reads the entries of a gzipped json lines file uploaded by [Store]
+func ReadEntries(r io.Reader) ([]Entry, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("opening gzip reader: %w", err)
+	}
+	defer func() { _ = gz.Close() }()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(gz)
+	// a line holds a payload of up to maxPayloadSizeInKB, escaped
+	scanner.Buffer(nil, 64*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("unmarshalling entry %d: %w", len(entries)+1, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading entries: %w", err)
+	}
+	return entries, nil
+}
+
+// Store uploads rejected requests to the object storage of their workspace.
+// Entries are buffered in memory and uploaded in batches, entries not fitting in the buffer being dropped.
+type Store struct {
+	uploader fileuploader.Provider
+	logger   logger.Logger
+	stats    stats.Stats
+	entries  chan Entry
+
+	conf struct {
+		maxPayloadSize  config.ValueLoader[int]
+		uploadFrequency config.ValueLoader[time.Duration]
+		maxBatchSize    config.ValueLoader[int]
+	}
+}
+
+// New creates a new store, entries getting uploaded once [Store.Run] is started
+func New(conf *config.Config, log logger.Logger, stat stats.Stats, uploader fileuploader.Provider) *Store {
+	s := &Store{
+		uploader: uploader,
+		logger:   log.Child("deadletter"),
+		stats:    stat,
+		entries:  make(chan Entry, conf.GetIntVar(10000, 1, "Gateway.rejectedRequests.bufferSize")),
+	}
+	// payloads larger than this are truncated
+	s.conf.maxPayloadSize = conf.GetReloadableIntVar(1024, 1024, "Gateway.rejectedRequests.maxPayloadSizeInKB")
+	s.conf.uploadFrequency = conf.GetReloadableDurationVar(30, time.Second, "Gateway.rejectedRequests.uploadFrequency")
+	s.conf.maxBatchSize = conf.GetReloadableIntVar(1000, 1, "Gateway.rejectedRequests.maxBatchSize")
+	return s
+}
+
+// Record queues a rejected request for upload without blocking, dropping it if the buffer is full
+func (s *Store) Record(workspaceID, sourceID, reqType, reason, ipAddr string, payload []byte) {
+	if workspaceID == "" {
+		return // no storage to upload to
+	}
+	entry := Entry{
+		WorkspaceID: workspaceID,
+		SourceID:    sourceID,
+		ReqType:     reqType,
+		Reason:      reason,
+		IPAddr:      ipAddr,
+		ReceivedAt:  time.Now(),
+	}
+	if maxSize := s.conf.maxPayloadSize.Load(); len(payload) > maxSize {
+		payload = payload[:maxSize]
+		entry.Truncated = true
+	}
+	entry.Payload = string(payload)
+	select {
+	case s.entries <- entry:
+	default:
+		s.stats.NewTaggedStat("gateway.rejected_requests_dropped", stats.CountType, stats.Tags{"workspaceId": workspaceID}).Increment()
+	}
+}
+
+// Run uploads the recorded entries periodically until the context is cancelled, uploading any remaining entries before returning
+func (s *Store) Run(ctx context.Context) {
+	var (
+		batch     = make(map[string][]Entry)
+		batchSize int
+	)
+	flush := func() {
+		if batchSize == 0 {
+			return
+		}
+		s.upload(context.WithoutCancel(ctx), batch)
+		batch = make(map[string][]Entry)
+		batchSize = 0
+	}
+	ticker := time.NewTicker(s.conf.uploadFrequency.Load())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			for {
+				select {
+				case entry := <-s.entries:
+					batch[entry.WorkspaceID] = append(batch[entry.WorkspaceID], entry)
+					batchSize++
+				default:
+					flush()
+					return
+				}
+			}
+		case entry := <-s.entries:
+			batch[entry.WorkspaceID] = append(batch[entry.WorkspaceID], entry)
+			batchSize++
+			if batchSize >= s.conf.maxBatchSize.Load() {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+			ticker.Reset(s.conf.uploadFrequency.Load())
+		}
+	}
+}
+
+func (s *Store) upload(ctx context.Context, batch map[string][]Entry) {
+	for workspaceID, entries := range batch {
+		tags := stats.Tags{"workspaceId": workspaceID}
+		location, err := s.uploadWorkspaceEntries(ctx, workspaceID, entries)
+		if err != nil {
+			if errors.Is(err, fileuploader.ErrNoStorageForWorkspace) {
+				s.logger.Debugn("Skipping rejected requests of workspace without storage", obskit.WorkspaceID(workspaceID))
+				continue
+			}
+			s.logger.Errorn("Uploading rejected requests", obskit.WorkspaceID(workspaceID), obskit.Error(err))
+			s.stats.NewTaggedStat("gateway.rejected_requests_upload_failed", stats.CountType, tags).Count(len(entries))
+			continue
+		}
+		s.logger.Debugn("Uploaded rejected requests", obskit.WorkspaceID(workspaceID), logger.NewStringField("location", location))
+		s.stats.NewTaggedStat("gateway.rejected_requests_uploaded", stats.CountType, tags).Count(len(entries))
+	}
+}
+
+func (s *Store) uploadWorkspaceEntries(ctx context.Context, workspaceID string, entries []Entry) (string, error) {
+	fm, err := s.uploader.GetFileManager(ctx, workspaceID)
+	if err != nil {
+		return "", err
+	}
+	tmpDirPath, err := misc.CreateTMPDIR()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(
+		tmpDirPath,
+		"rudder-gw-rejected-requests",
+		fmt.Sprintf("%v.%v.%v.%v.json.gz", time.Now().Unix(), config.GetString("INSTANCE_ID", "1"), uuid.New().String(), workspaceID),
+	)
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return "", fmt.Errorf("creating gz file %q: mkdir error: %w", path, err)
+	}
+	defer func() { _ = os.Remove(path) }()
+
+	writer, err := misc.CreateGZ(path)
+	if err != nil {
+		return "", err
+	}
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			_ = writer.Close()
+			return "", err
+		}
+		if _, err := writer.Write(append(line, '\n')); err != nil {
+			_ = writer.Close()
+			return "", err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = file.Close() }()
+	uploadOutput, err := fm.Upload(ctx, file, "rudder-gw-rejected-requests", time.Now().Format("01-02-2006"), workspaceID)
+	if err != nil {
+		return "", err
+	}
+	return uploadOutput.Location, nil
+}
@@ -0,0 +1,143 @@
+package deadletter_test
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/rudderlabs/rudder-go-kit/config"
+	"github.com/rudderlabs/rudder-go-kit/filemanager"
+	"github.com/rudderlabs/rudder-go-kit/filemanager/mock_filemanager"
+	"github.com/rudderlabs/rudder-go-kit/logger"
+	"github.com/rudderlabs/rudder-go-kit/stats"
+	"github.com/rudderlabs/rudder-go-kit/stats/memstats"
+
+	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
+	"github.com/rudderlabs/rudder-server/gateway/internal/deadletter"
+	"github.com/rudderlabs/rudder-server/services/fileuploader"
+)
+
+type provider struct {
+	fileManagers map[string]filemanager.FileManager
+}
+
+func (p *provider) GetFileManager(_ context.Context, workspaceID string) (filemanager.FileManager, error) {
+	fm, ok := p.fileManagers[workspaceID]
+	if !ok {
+		return nil, fileuploader.ErrNoStorageForWorkspace
+	}
+	return fm, nil
+}
+
+func (*provider) GetStoragePreferences(context.Context, string) (backendconfig.StoragePreferences, error) {
+	return backendconfig.StoragePreferences{}, nil
+}
+
+func TestStore(t *testing.T) {
+	t.Setenv("RUDDER_TMPDIR", t.TempDir())
+	ctrl := gomock.NewController(t)
+	fm := mock_filemanager.NewMockFileManager(ctrl)
+
+	var uploaded []deadletter.Entry
+	fm.EXPECT().Upload(gomock.Any(), gomock.Any(), "rudder-gw-rejected-requests", gomock.Any(), "workspace-1").
+		DoAndReturn(func(_ context.Context, f *os.File, _ ...string) (filemanager.UploadedFile, error) {
+			gz, err := gzip.NewReader(f)
+			require.NoError(t, err)
+			scanner := bufio.NewScanner(gz)
+			for scanner.Scan() {
+				var entry deadletter.Entry
+				require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+				uploaded = append(uploaded, entry)
+			}
+			require.NoError(t, scanner.Err())
+			return filemanager.UploadedFile{Location: "location"}, nil
+		}).Times(1)
+
+	c := config.New()
+	c.Set("Gateway.rejectedRequests.maxPayloadSizeInKB", 1)
+	c.Set("Gateway.rejectedRequests.uploadFrequency", "1h")
+	statsStore, err := memstats.New()
+	require.NoError(t, err)
+	store := deadletter.New(c, logger.NOP, statsStore, &provider{fileManagers: map[string]filemanager.FileManager{"workspace-1": fm}})
+
+	largePayload := make([]byte, 2048)
+	for i := range largePayload {
+		largePayload[i] = 'a'
+	}
+	store.Record("workspace-1", "source-1", "batch", "Invalid JSON", "1.1.1.1", []byte(`{"batch":`))
+	store.Record("workspace-1", "source-1", "track", "Request size too large", "1.1.1.1", largePayload)
+	store.Record("workspace-2", "source-2", "track", "Invalid JSON", "1.1.1.1", []byte(`{`))
+	store.Record("", "", "track", "Invalid JSON", "1.1.1.1", []byte(`{`))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		store.Run(ctx)
+	}()
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("store didn't stop")
+	}
+
+	require.Len(t, uploaded, 2)
+	require.Equal(t, "source-1", uploaded[0].SourceID)
+	require.Equal(t, "batch", uploaded[0].ReqType)
+	require.Equal(t, "Invalid JSON", uploaded[0].Reason)
+	require.Equal(t, `{"batch":`, uploaded[0].Payload)
+	require.False(t, uploaded[0].Truncated)
+	require.Len(t, uploaded[1].Payload, 1024)
+	require.True(t, uploaded[1].Truncated)
+
+	require.EqualValues(t, 2, statsStore.Get("gateway.rejected_requests_uploaded", stats.Tags{"workspaceId": "workspace-1"}).LastValue())
+	require.Nil(t, statsStore.Get("gateway.rejected_requests_upload_failed", stats.Tags{"workspaceId": "workspace-2"}), "workspaces without storage are skipped")
+}
+
+func TestReplay(t *testing.T) {
+	entries := []deadletter.Entry{
+		{WorkspaceID: "workspace-1", SourceID: "source-1", ReqType: "batch", Reason: "Invalid JSON", Payload: `{"batch":[{"type":"track","event":"e1"}]}`},
+		{WorkspaceID: "workspace-1", SourceID: "source-1", ReqType: "track", Reason: "Non Identifiable Request", Payload: `{"event":"e2"}`},
+		{WorkspaceID: "workspace-1", SourceID: "source-1", ReqType: "track", Reason: "Invalid JSON", Payload: `{"event":`},
+		{WorkspaceID: "workspace-1", SourceID: "source-1", ReqType: "track", Reason: "Request size too large", Payload: `{"event":"e3"}`, Truncated: true},
+		{WorkspaceID: "workspace-1", SourceID: "source-1", ReqType: "internalBatch", Reason: "Invalid JSON", Payload: `[]`},
+	}
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		require.NoError(t, err)
+		_, err = gz.Write(append(line, '\n'))
+		require.NoError(t, err)
+	}
+	require.NoError(t, gz.Close())
+
+	read, err := deadletter.ReadEntries(&buf)
+	require.NoError(t, err)
+	require.Equal(t, entries, read)
+
+	payload, err := read[0].ReplayPayload()
+	require.NoError(t, err)
+	require.JSONEq(t, `{"batch":[{"type":"track","event":"e1"}]}`, string(payload))
+
+	payload, err = read[1].ReplayPayload()
+	require.NoError(t, err)
+	require.JSONEq(t, `{"batch":[{"type":"track","event":"e2"}]}`, string(payload), "single event requests are wrapped in a batch")
+
+	for _, entry := range read[2:] {
+		_, err := entry.ReplayPayload()
+		require.ErrorIs(t, err, deadletter.ErrNotReplayable)
+	}
+
+	_, err = deadletter.ReadEntries(bytes.NewReader([]byte("not gzipped")))
+	require.Error(t, err)
+}
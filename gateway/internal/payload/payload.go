@@ -0,0 +1,14 @@
+// Package payload contains helpers for building the payloads of gateway requests
+package payload
+
+// Batch builds a batch payload out of json encoded events
+func Batch(events [][]byte) []byte {
+	payload := []byte(`{"batch":[`)
+	for i, event := range events {
+		if i > 0 {
+			payload = append(payload, ',')
+		}
+		payload = append(payload, event...)
+	}
+	return append(payload, ']', '}')
+}
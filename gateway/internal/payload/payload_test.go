@@ -0,0 +1,15 @@
+package payload_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rudderlabs/rudder-server/gateway/internal/payload"
+)
+
+func TestBatch(t *testing.T) {
+	require.JSONEq(t, `{"batch":[]}`, string(payload.Batch(nil)))
+	require.JSONEq(t, `{"batch":[{"type":"track"}]}`, string(payload.Batch([][]byte{[]byte(`{"type":"track"}`)})))
+	require.JSONEq(t, `{"batch":[{"type":"track"},{"type":"identify"}]}`, string(payload.Batch([][]byte{[]byte(`{"type":"track"}`), []byte(`{"type":"identify"}`)})))
+}
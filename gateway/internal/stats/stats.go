@@ -25,10 +25,11 @@ type SourceStat struct {
 		failed     int
 		dropped    int
 		suppressed int
+		botDropped int
 	}
 	events struct {
 		total int
-		bot   int
+		bot   map[string]int // bot events by matched rule
 
 		succeeded int
 		failed    int
@@ -77,8 +78,23 @@ func (ss *SourceStat) RequestEventsFailed(num int, reason string) {
 	ss.reason = reason
 }
 
-func (ss *SourceStat) RequestEventsBot(num int) {
-	ss.events.bot += num
+// RequestBotDropped increments the requests total & bot dropped counters by one
+func (ss *SourceStat) RequestBotDropped() {
+	ss.requests.total++
+	ss.requests.botDropped++
+}
+
+// RequestEventsBot increments the bot events counters by the number of events matching each bot rule
+func (ss *SourceStat) RequestEventsBot(botEvents map[string]int) {
+	if len(botEvents) == 0 {
+		return
+	}
+	if ss.events.bot == nil {
+		ss.events.bot = make(map[string]int)
+	}
+	for rule, num := range botEvents {
+		ss.events.bot[rule] += num
+	}
 }
 
 // Report captured stats
@@ -102,13 +118,16 @@ func (ss *SourceStat) Report(s stats.Stats) {
 	s.NewTaggedStat("gateway.write_key_failed_requests", stats.CountType, failedTags).Count(ss.requests.failed)
 	s.NewTaggedStat("gateway.write_key_dropped_requests", stats.CountType, tags).Count(ss.requests.dropped)
 	s.NewTaggedStat("gateway.write_key_suppressed_requests", stats.CountType, tags).Count(ss.requests.suppressed)
+	if ss.requests.botDropped > 0 {
+		s.NewTaggedStat("gateway.write_key_bot_dropped_requests", stats.CountType, tags).Count(ss.requests.botDropped)
+	}
 	if ss.events.total > 0 {
 		s.NewTaggedStat("gateway.write_key_events", stats.CountType, tags).Count(ss.events.total)
 		s.NewTaggedStat("gateway.write_key_successful_events", stats.CountType, tags).Count(ss.events.succeeded)
 		s.NewTaggedStat("gateway.write_key_failed_events", stats.CountType, failedTags).Count(ss.events.failed)
 
-		if ss.events.bot > 0 {
-			s.NewTaggedStat("gateway.write_key_bot_events", stats.CountType, tags).Count(ss.events.bot)
-		}
+	}
+	for rule, num := range ss.events.bot {
+		s.NewTaggedStat("gateway.write_key_bot_events", stats.CountType, lo.Assign(tags, stats.Tags{"botRule": rule})).Count(num)
 	}
 }
@@ -0,0 +1,138 @@
+package kafkasource
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+)
+
+// sourceConfig is the configuration of a kafka source
+type sourceConfig struct {
+	HostName string   `json:"hostname"` // comma separated list of broker hosts
+	Port     string   `json:"port"`
+	Topics   []string `json:"topics"`
+	// ConsumerGroupID is the consumer group whose offsets are committed, defaults to rudder-<sourceId>
+	ConsumerGroupID string `json:"consumerGroupId"`
+	// StartOffset is either first or last, deciding where consumption starts when the group has no committed offset
+	StartOffset string `json:"startOffset"`
+	// FieldMapping maps event fields to record fields, see [mapRecord]
+	FieldMapping map[string]string `json:"fieldMapping"`
+
+	SslEnabled    bool   `json:"sslEnabled"`
+	CACertificate string `json:"caCertificate"`
+	UseSASL       bool   `json:"useSASL"`
+	SaslType      string `json:"saslType"`
+	Username      string `json:"username"`
+	Password      string `json:"password"`
+}
+
+func parseSourceConfig(sourceID string, config map[string]interface{}) (*sourceConfig, error) {
+	jsonConfig, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling source configuration: %w", err)
+	}
+	var c sourceConfig
+	if err := json.Unmarshal(jsonConfig, &c); err != nil {
+		return nil, fmt.Errorf("unmarshalling source configuration: %w", err)
+	}
+	if c.ConsumerGroupID == "" {
+		c.ConsumerGroupID = "rudder-" + sourceID
+	}
+	return &c, c.validate()
+}
+
+func (c *sourceConfig) validate() error {
+	if c.HostName == "" {
+		return errors.New("hostname cannot be empty")
+	}
+	if c.Port == "" {
+		return errors.New("port cannot be empty")
+	}
+	if len(c.Topics) == 0 {
+		return errors.New("topics cannot be empty")
+	}
+	switch c.StartOffset {
+	case "", "first", "last":
+	default:
+		return fmt.Errorf("invalid start offset %q", c.StartOffset)
+	}
+	for field, recordField := range c.FieldMapping {
+		if err := validateRecordField(recordField); err != nil {
+			return fmt.Errorf("invalid mapping of %q: %w", field, err)
+		}
+	}
+	return nil
+}
+
+func (c *sourceConfig) brokers() []string {
+	hostNames := strings.Split(c.HostName, ",")
+	brokers := make([]string, len(hostNames))
+	for i, hostName := range hostNames {
+		brokers[i] = strings.TrimSpace(hostName) + ":" + c.Port
+	}
+	return brokers
+}
+
+func (c *sourceConfig) startOffset() int64 {
+	if c.StartOffset == "last" {
+		return kafka.LastOffset
+	}
+	return kafka.FirstOffset
+}
+
+// dialer returns the dialer for connecting to the brokers. SASL is enabled only with SSL, same as for the kafka destination.
+func (c *sourceConfig) dialer(timeout time.Duration) (*kafka.Dialer, error) {
+	dialer := &kafka.Dialer{
+		DualStack: true,
+		Timeout:   timeout,
+	}
+	if !c.SslEnabled {
+		return dialer, nil
+	}
+	dialer.TLS = &tls.Config{ // skipcq: GSC-G402
+		MinVersion: tls.VersionTLS11,
+	}
+	if c.CACertificate != "" {
+		caCertPool := x509.NewCertPool()
+		if !caCertPool.AppendCertsFromPEM([]byte(c.CACertificate)) {
+			return nil, errors.New("could not append CA certificate")
+		}
+		dialer.TLS.RootCAs = caCertPool
+	} else {
+		systemCertPool, err := x509.SystemCertPool()
+		if err != nil {
+			return nil, fmt.Errorf("loading system cert pool: %w", err)
+		}
+		dialer.TLS.RootCAs = systemCertPool
+	}
+	if c.UseSASL {
+		mechanism, err := c.saslMechanism()
+		if err != nil {
+			return nil, err
+		}
+		dialer.SASLMechanism = mechanism
+	}
+	return dialer, nil
+}
+
+func (c *sourceConfig) saslMechanism() (sasl.Mechanism, error) {
+	switch c.SaslType {
+	case "plain":
+		return plain.Mechanism{Username: c.Username, Password: c.Password}, nil
+	case "sha256":
+		return scram.Mechanism(scram.SHA256, c.Username, c.Password)
+	case "sha512":
+		return scram.Mechanism(scram.SHA512, c.Username, c.Password)
+	default:
+		return nil, fmt.Errorf("invalid SASL type %q", c.SaslType)
+	}
+}
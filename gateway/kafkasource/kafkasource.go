@@ -0,0 +1,354 @@
+// Package kafkasource implements kafka sources, consuming records from kafka topics and ingesting them as events
+// through the gateway.
+//
+// Each enabled kafka source gets a single consumer of all its topics, part of the source's consumer group. Records are mapped into
+// rudder events (see [mapRecord]) and ingested in batches, with their offsets being committed only after the gateway
+// has stored them in its jobsdb. Batches rate limited by the gateway are retried once the source's throttling window
+// allows it, applying back-pressure to consumption instead of dropping events.
+package kafkasource
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/rudderlabs/rudder-go-kit/config"
+	"github.com/rudderlabs/rudder-go-kit/logger"
+	"github.com/rudderlabs/rudder-go-kit/stats"
+
+	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
+	"github.com/rudderlabs/rudder-server/gateway/internal/payload"
+	"github.com/rudderlabs/rudder-server/gateway/response"
+	"github.com/rudderlabs/rudder-server/utils/crash"
+)
+
+// SourceDefinitionName is the name of the kafka source definition
+const SourceDefinitionName = "Kafka"
+
+// Gateway ingests events on behalf of kafka sources
+type Gateway interface {
+	// IngestBatch stores a batch payload of the source's events in the gateway jobsdb. It returns the error message
+	// of the gateway if the payload was rejected along with, if it was rate limited, how long to wait before retrying.
+	IngestBatch(ctx context.Context, source *backendconfig.SourceT, payload []byte) (errorMessage string, retryAfter time.Duration)
+}
+
+// Manager runs the consumers of kafka sources
+type Manager struct {
+	log   logger.Logger
+	stats stats.Stats
+	gw    Gateway
+
+	conf struct {
+		maxBatchSize  config.ValueLoader[int]
+		batchTimeout  config.ValueLoader[time.Duration]
+		retryInterval config.ValueLoader[time.Duration]
+		dialTimeout   time.Duration
+	}
+
+	mu      sync.Mutex
+	sources map[string]*sourceConsumer // by source id
+}
+
+// New returns a new manager of kafka sources
+func New(conf *config.Config, log logger.Logger, stat stats.Stats, gw Gateway) *Manager {
+	m := &Manager{
+		log:     log.Child("kafkasource"),
+		stats:   stat,
+		gw:      gw,
+		sources: make(map[string]*sourceConsumer),
+	}
+	// Maximum number of records ingested together
+	m.conf.maxBatchSize = conf.GetReloadableIntVar(100, 1, "Gateway.kafkaSource.maxBatchSize")
+	// Timeout after which a batch is ingested anyway with whatever records are available
+	m.conf.batchTimeout = conf.GetReloadableDurationVar(100, time.Millisecond, "Gateway.kafkaSource.batchTimeout")
+	// Interval between retries of batches that couldn't be stored, or rate limited batches without a retry time
+	m.conf.retryInterval = conf.GetReloadableDurationVar(1, time.Second, "Gateway.kafkaSource.retryInterval")
+	m.conf.dialTimeout = conf.GetDurationVar(10, time.Second, "Gateway.kafkaSource.dialTimeout")
+	return m
+}
+
+// Sync starts consuming from the enabled kafka sources among the given ones, restarting the consumers of sources
+// whose configuration has changed and stopping the consumers of sources which are no longer present
+func (m *Manager) Sync(sources []backendconfig.SourceT) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	present := make(map[string]struct{})
+	for i := range sources {
+		source := sources[i]
+		if !source.Enabled || source.SourceDefinition.Name != SourceDefinitionName {
+			continue
+		}
+		present[source.ID] = struct{}{}
+		checksum := configChecksum(&source)
+		if sc, ok := m.sources[source.ID]; ok {
+			if sc.checksum == checksum {
+				sc.setSource(&source)
+				continue
+			}
+			sc.stop()
+			delete(m.sources, source.ID)
+		}
+		sc, err := m.newSourceConsumer(&source, checksum)
+		if err != nil {
+			m.log.Errorw("invalid kafka source", "sourceId", source.ID, "workspaceId", source.WorkspaceID, "error", err)
+			continue
+		}
+		m.sources[source.ID] = sc
+	}
+	for sourceID, sc := range m.sources {
+		if _, ok := present[sourceID]; !ok {
+			sc.stop()
+			delete(m.sources, sourceID)
+		}
+	}
+}
+
+// Shutdown stops all consumers, waiting for the batches being ingested
+func (m *Manager) Shutdown() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for sourceID, sc := range m.sources {
+		sc.stop()
+		delete(m.sources, sourceID)
+	}
+}
+
+// configChecksum returns a checksum of the source's properties requiring its consumers to be restarted when changed
+func configChecksum(source *backendconfig.SourceT) string {
+	b, _ := json.Marshal(source.Config)
+	return fmt.Sprintf("%x", sha256.Sum256(append(b, source.WorkspaceID...)))
+}
+
+// sourceConsumer consumes the topics of a kafka source
+type sourceConsumer struct {
+	m        *Manager
+	log      logger.Logger
+	checksum string
+	config   *sourceConfig
+
+	sourceMu sync.RWMutex
+	source   *backendconfig.SourceT
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	ingestedStat      stats.Measurement
+	invalidStat       stats.Measurement
+	rateLimitedStat   stats.Measurement
+	commitFailedStat  stats.Measurement
+	ingestFailedStat  stats.Measurement
+	consumptionDelay  stats.Measurement
+	fetchFailedStat   stats.Measurement
+	batchSizeStat     stats.Measurement
+	ingestionTimeStat stats.Measurement
+}
+
+func (m *Manager) newSourceConsumer(source *backendconfig.SourceT, checksum string) (*sourceConsumer, error) {
+	conf, err := parseSourceConfig(source.ID, source.Config)
+	if err != nil {
+		return nil, err
+	}
+	dialer, err := conf.dialer(m.conf.dialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	tags := stats.Tags{"sourceId": source.ID, "workspaceId": source.WorkspaceID}
+	sc := &sourceConsumer{
+		m:                 m,
+		log:               m.log.With("sourceId", source.ID, "workspaceId", source.WorkspaceID),
+		checksum:          checksum,
+		config:            conf,
+		source:            source,
+		ingestedStat:      m.stats.NewTaggedStat("gateway.kafka_source_records_ingested", stats.CountType, tags),
+		invalidStat:       m.stats.NewTaggedStat("gateway.kafka_source_records_invalid", stats.CountType, tags),
+		rateLimitedStat:   m.stats.NewTaggedStat("gateway.kafka_source_rate_limited", stats.CountType, tags),
+		commitFailedStat:  m.stats.NewTaggedStat("gateway.kafka_source_commit_failed", stats.CountType, tags),
+		ingestFailedStat:  m.stats.NewTaggedStat("gateway.kafka_source_ingest_failed", stats.CountType, tags),
+		fetchFailedStat:   m.stats.NewTaggedStat("gateway.kafka_source_fetch_failed", stats.CountType, tags),
+		consumptionDelay:  m.stats.NewTaggedStat("gateway.kafka_source_consumption_delay", stats.TimerType, tags),
+		batchSizeStat:     m.stats.NewTaggedStat("gateway.kafka_source_batch_size", stats.HistogramType, tags),
+		ingestionTimeStat: m.stats.NewTaggedStat("gateway.kafka_source_ingestion_time", stats.TimerType, tags),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	sc.cancel = cancel
+	// a single reader consumes all topics of the source, so that the consumer group is joined by one member per
+	// gateway and partitions get balanced across all topics
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     conf.brokers(),
+		GroupID:     conf.ConsumerGroupID,
+		GroupTopics: conf.Topics,
+		Dialer:      dialer,
+		StartOffset: conf.startOffset(),
+		// offsets are committed synchronously, once the records have been stored
+		CommitInterval: 0,
+		ErrorLogger: kafka.LoggerFunc(func(msg string, args ...interface{}) {
+			sc.log.Warnf("kafka reader: "+msg, args...)
+		}),
+	})
+	sc.wg.Add(1)
+	go crash.Wrapper(func() error {
+		defer sc.wg.Done()
+		sc.consume(ctx, reader)
+		if err := reader.Close(); err != nil {
+			sc.log.Warnw("closing kafka reader", "error", err)
+		}
+		return nil
+	})()
+	sc.log.Infow("started consuming kafka source", "topics", conf.Topics, "consumerGroupId", conf.ConsumerGroupID)
+	return sc, nil
+}
+
+func (sc *sourceConsumer) setSource(source *backendconfig.SourceT) {
+	sc.sourceMu.Lock()
+	defer sc.sourceMu.Unlock()
+	sc.source = source
+}
+
+func (sc *sourceConsumer) getSource() *backendconfig.SourceT {
+	sc.sourceMu.RLock()
+	defer sc.sourceMu.RUnlock()
+	return sc.source
+}
+
+func (sc *sourceConsumer) stop() {
+	sc.cancel()
+	sc.wg.Wait()
+	sc.log.Infow("stopped consuming kafka source")
+}
+
+// consume ingests batches of records until the context is cancelled, committing their offsets once ingested
+func (sc *sourceConsumer) consume(ctx context.Context, reader *kafka.Reader) {
+	for {
+		records, err := sc.fetchBatch(ctx, reader)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			sc.fetchFailedStat.Increment()
+			sc.log.Errorw("fetching kafka records", "error", err)
+			if !sleep(ctx, sc.m.conf.retryInterval.Load()) {
+				return
+			}
+			continue
+		}
+		if err := sc.ingest(ctx, records); err != nil {
+			return // context cancelled, records will be consumed again
+		}
+		if err := sc.commit(ctx, reader, records); err != nil {
+			// records will be consumed again after a rebalance, ending up as events with the same messageIds
+			sc.commitFailedStat.Increment()
+			sc.log.Errorw("committing kafka offsets", "records", len(records), "error", err)
+		}
+	}
+}
+
+// commit commits the offsets of the stored records, even if the consumer is being stopped
+func (sc *sourceConsumer) commit(ctx context.Context, reader *kafka.Reader, records []kafka.Message) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), sc.m.conf.dialTimeout)
+	defer cancel()
+	return reader.CommitMessages(ctx, records...)
+}
+
+// fetchBatch waits for a record and then fetches records until the batch is full or the batch timeout expires
+func (sc *sourceConsumer) fetchBatch(ctx context.Context, reader *kafka.Reader) ([]kafka.Message, error) {
+	record, err := reader.FetchMessage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	records := []kafka.Message{record}
+	batchCtx, cancel := context.WithTimeout(ctx, sc.m.conf.batchTimeout.Load())
+	defer cancel()
+	for maxBatchSize := sc.m.conf.maxBatchSize.Load(); len(records) < maxBatchSize; {
+		record, err := reader.FetchMessage(batchCtx)
+		if err != nil {
+			if batchCtx.Err() != nil {
+				break
+			}
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// ingest maps the records into events and ingests them through the gateway, retrying until they are either stored
+// or rejected as invalid. Only a cancellation of the context stops it from doing so, returning the context's error.
+func (sc *sourceConsumer) ingest(ctx context.Context, records []kafka.Message) error {
+	defer sc.ingestionTimeStat.RecordDuration()()
+	source := sc.getSource()
+	events := make([][]byte, 0, len(records))
+	for i := range records {
+		sc.consumptionDelay.Since(records[i].Time)
+		event, err := mapRecord(source.ID, sc.config.FieldMapping, &records[i])
+		if err != nil {
+			sc.invalidStat.Increment()
+			sc.log.Warnw("invalid kafka record",
+				"topic", records[i].Topic, "partition", records[i].Partition, "offset", records[i].Offset, "error", err)
+			continue
+		}
+		events = append(events, event)
+	}
+	sc.batchSizeStat.Observe(float64(len(events)))
+	return sc.ingestEvents(ctx, events)
+}
+
+func (sc *sourceConsumer) ingestEvents(ctx context.Context, events [][]byte) error {
+	if len(events) == 0 {
+		return nil
+	}
+	batch := payload.Batch(events)
+	for {
+		errorMessage, retryAfter := sc.m.gw.IngestBatch(ctx, sc.getSource(), batch)
+		if errorMessage == "" {
+			sc.ingestedStat.Count(len(events))
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		switch statusCode := response.GetErrorStatusCode(errorMessage); {
+		case statusCode == http.StatusTooManyRequests:
+			sc.rateLimitedStat.Increment()
+			if retryAfter <= 0 {
+				retryAfter = sc.m.conf.retryInterval.Load()
+			}
+			if !sleep(ctx, retryAfter) {
+				return ctx.Err()
+			}
+		case statusCode >= http.StatusInternalServerError:
+			sc.ingestFailedStat.Increment()
+			sc.log.Warnw("ingesting kafka records", "events", len(events), "error", errorMessage)
+			if !sleep(ctx, sc.m.conf.retryInterval.Load()) {
+				return ctx.Err()
+			}
+		case len(events) > 1:
+			// the batch was rejected, ingest its events one by one so that only the invalid ones are skipped
+			for _, event := range events {
+				if err := sc.ingestEvents(ctx, [][]byte{event}); err != nil {
+					return err
+				}
+			}
+			return nil
+		default:
+			sc.invalidStat.Increment()
+			sc.log.Warnw("kafka record rejected by the gateway", "error", errorMessage)
+			return nil
+		}
+	}
+}
+
+// sleep waits for the given duration, returning false if the context got cancelled in the meantime
+func sleep(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
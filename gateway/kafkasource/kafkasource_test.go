@@ -0,0 +1,157 @@
+package kafkasource
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ory/dockertest/v3"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	"github.com/rudderlabs/rudder-go-kit/config"
+	"github.com/rudderlabs/rudder-go-kit/kafkaclient/testutil"
+	"github.com/rudderlabs/rudder-go-kit/logger"
+	"github.com/rudderlabs/rudder-go-kit/stats"
+	dockerKafka "github.com/rudderlabs/rudder-go-kit/testhelper/docker/resource/kafka"
+
+	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
+	"github.com/rudderlabs/rudder-server/gateway/response"
+)
+
+type mockGateway struct {
+	mu             sync.Mutex
+	userIDs        []string
+	rateLimited    int    // number of calls to be rate limited
+	failWith       string // error message returned to all calls, if set
+	invalidUserIDs map[string]struct{}
+}
+
+func (g *mockGateway) IngestBatch(_ context.Context, _ *backendconfig.SourceT, payload []byte) (string, time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.failWith != "" {
+		return g.failWith, 0
+	}
+	if g.rateLimited > 0 {
+		g.rateLimited--
+		return response.TooManyRequests, 10 * time.Millisecond
+	}
+	events := gjson.GetBytes(payload, "batch").Array()
+	for _, event := range events {
+		if _, ok := g.invalidUserIDs[event.Get("userId").String()]; ok {
+			return response.NonIdentifiableRequest, 0
+		}
+	}
+	for _, event := range events {
+		g.userIDs = append(g.userIDs, event.Get("userId").String())
+	}
+	return "", 0
+}
+
+func (g *mockGateway) ingested() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]string{}, g.userIDs...)
+}
+
+func TestKafkaSource(t *testing.T) {
+	pool, err := dockertest.NewPool("")
+	require.NoError(t, err)
+	kafkaContainer, err := dockerKafka.Setup(pool, t, dockerKafka.WithBrokers(1))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	topic := "orders"
+	tc := testutil.New("tcp", kafkaContainer.Brokers[0])
+	require.Eventually(t, func() bool {
+		return tc.CreateTopic(ctx, topic, 2, 1) == nil
+	}, 30*time.Second, 100*time.Millisecond)
+
+	writer := &kafka.Writer{Addr: kafka.TCP(kafkaContainer.Brokers...), Topic: topic, Balancer: &kafka.Hash{}}
+	t.Cleanup(func() { _ = writer.Close() })
+	produce := func(from, to int) {
+		var records []kafka.Message
+		for i := from; i < to; i++ {
+			records = append(records, kafka.Message{
+				Key:   []byte("key-" + strconv.Itoa(i)),
+				Value: []byte(`{"customerId":"user-` + strconv.Itoa(i) + `"}`),
+			})
+		}
+		require.NoError(t, writer.WriteMessages(ctx, records...))
+	}
+	userIDs := func(from, to int) []string {
+		var ids []string
+		for i := from; i < to; i++ {
+			ids = append(ids, "user-"+strconv.Itoa(i))
+		}
+		return ids
+	}
+
+	host, port, err := net.SplitHostPort(kafkaContainer.Brokers[0])
+	require.NoError(t, err)
+	source := backendconfig.SourceT{
+		ID:               "source-1",
+		WorkspaceID:      "workspace-1",
+		Enabled:          true,
+		SourceDefinition: backendconfig.SourceDefinitionT{Name: SourceDefinitionName},
+		Config: map[string]interface{}{
+			"hostname":     host,
+			"port":         port,
+			"topics":       []string{topic},
+			"fieldMapping": map[string]string{"userId": "value.customerId"},
+		},
+	}
+	conf := config.New()
+	conf.Set("Gateway.kafkaSource.maxBatchSize", 10)
+	conf.Set("Gateway.kafkaSource.retryInterval", "10ms")
+
+	t.Run("records are ingested with rate limited and invalid batches being retried", func(t *testing.T) {
+		produce(0, 20)
+		gw := &mockGateway{rateLimited: 3, invalidUserIDs: map[string]struct{}{"user-5": {}}}
+		m := New(conf, logger.NOP, stats.NOP, gw)
+		m.Sync([]backendconfig.SourceT{source})
+		defer m.Shutdown()
+
+		expected := userIDs(0, 20)
+		expected = append(expected[:5], expected[6:]...)
+		require.Eventually(t, func() bool {
+			return len(gw.ingested()) == len(expected)
+		}, 60*time.Second, 100*time.Millisecond)
+		require.ElementsMatch(t, expected, gw.ingested())
+	})
+
+	t.Run("offsets are committed only once records are stored", func(t *testing.T) {
+		produce(20, 30)
+		failing := &mockGateway{failWith: "store failed"}
+		m := New(conf, logger.NOP, stats.NOP, failing)
+		m.Sync([]backendconfig.SourceT{source})
+		time.Sleep(2 * time.Second)
+		m.Shutdown()
+		require.Empty(t, failing.ingested())
+
+		gw := &mockGateway{}
+		m = New(conf, logger.NOP, stats.NOP, gw)
+		m.Sync([]backendconfig.SourceT{source})
+		defer m.Shutdown()
+		require.Eventually(t, func() bool {
+			return len(gw.ingested()) == 10
+		}, 60*time.Second, 100*time.Millisecond)
+		require.ElementsMatch(t, userIDs(20, 30), gw.ingested(), "previously committed records shouldn't be consumed again")
+	})
+
+	t.Run("consumers stop when their source is removed", func(t *testing.T) {
+		gw := &mockGateway{}
+		m := New(conf, logger.NOP, stats.NOP, gw)
+		m.Sync([]backendconfig.SourceT{source})
+		m.Sync(nil)
+		require.Empty(t, m.sources)
+		produce(30, 31)
+		time.Sleep(time.Second)
+		require.Empty(t, gw.ingested())
+	})
+}
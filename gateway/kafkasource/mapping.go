@@ -0,0 +1,142 @@
+package kafkasource
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+
+	"github.com/rudderlabs/rudder-server/utils/misc"
+)
+
+// Record fields that event fields can be mapped to
+const (
+	recordKey       = "key"       // the record's key as a string
+	recordValue     = "value"     // the record's value, value.<path> selecting a field of a json value
+	recordHeader    = "header"    // header.<name> selecting the value of a header as a string
+	recordTopic     = "topic"     // the record's topic
+	recordPartition = "partition" // the record's partition
+	recordOffset    = "offset"    // the record's offset
+	recordTimestamp = "timestamp" // the record's timestamp
+)
+
+var errNotAnObject = errors.New("record value is not a json object")
+
+func validateRecordField(recordField string) error {
+	switch {
+	case recordField == recordKey, recordField == recordValue, recordField == recordTopic,
+		recordField == recordPartition, recordField == recordOffset, recordField == recordTimestamp:
+		return nil
+	case strings.HasPrefix(recordField, recordValue+".") && len(recordField) > len(recordValue)+1:
+		return nil
+	case strings.HasPrefix(recordField, recordHeader+".") && len(recordField) > len(recordHeader)+1:
+		return nil
+	}
+	return fmt.Errorf("unknown record field %q", recordField)
+}
+
+// mapRecord maps a kafka record into a rudder event.
+//
+// Without a field mapping the record's value is expected to be a rudder event. Otherwise the event is built by
+// setting each of the mapping's event fields (using sjson paths) to the value of the record field it is mapped to.
+// Events are then completed with:
+//   - type track, along with the topic as the event name, if missing
+//   - a messageId derived from the record's position, so that records consumed more than once end up with the same messageId
+//   - the record's key as the anonymousId, if neither a userId nor an anonymousId is present
+//   - the record's timestamp as the originalTimestamp, if missing
+func mapRecord(sourceID string, fieldMapping map[string]string, record *kafka.Message) ([]byte, error) {
+	var event []byte
+	if len(fieldMapping) == 0 {
+		if !gjson.ValidBytes(record.Value) || !gjson.ParseBytes(record.Value).IsObject() {
+			return nil, errNotAnObject
+		}
+		event = record.Value
+	} else {
+		event = []byte(`{}`)
+		for field, recordField := range fieldMapping {
+			var err error
+			event, err = setRecordField(event, field, recordField, record)
+			if err != nil {
+				return nil, fmt.Errorf("mapping %q: %w", field, err)
+			}
+		}
+	}
+
+	setDefault := func(field string, value any) (err error) {
+		if !gjson.GetBytes(event, field).Exists() {
+			event, err = sjson.SetBytes(event, field, value)
+		}
+		return err
+	}
+	if err := setDefault("type", "track"); err != nil {
+		return nil, err
+	}
+	if gjson.GetBytes(event, "type").String() == "track" {
+		if err := setDefault("event", record.Topic); err != nil {
+			return nil, err
+		}
+	}
+	if err := setDefault("messageId", messageID(sourceID, record)); err != nil {
+		return nil, err
+	}
+	if len(record.Key) > 0 && gjson.GetBytes(event, "userId").String() == "" {
+		if err := setDefault("anonymousId", string(record.Key)); err != nil {
+			return nil, err
+		}
+	}
+	if !record.Time.IsZero() {
+		if err := setDefault("originalTimestamp", record.Time.Format(misc.RFC3339Milli)); err != nil {
+			return nil, err
+		}
+	}
+	return event, nil
+}
+
+func setRecordField(event []byte, field, recordField string, record *kafka.Message) ([]byte, error) {
+	switch {
+	case recordField == recordKey:
+		return sjson.SetBytes(event, field, string(record.Key))
+	case recordField == recordValue:
+		if gjson.ValidBytes(record.Value) {
+			return sjson.SetRawBytes(event, field, record.Value)
+		}
+		return sjson.SetBytes(event, field, string(record.Value))
+	case recordField == recordTopic:
+		return sjson.SetBytes(event, field, record.Topic)
+	case recordField == recordPartition:
+		return sjson.SetBytes(event, field, record.Partition)
+	case recordField == recordOffset:
+		return sjson.SetBytes(event, field, record.Offset)
+	case recordField == recordTimestamp:
+		return sjson.SetBytes(event, field, record.Time.Format(misc.RFC3339Milli))
+	case strings.HasPrefix(recordField, recordValue+"."):
+		if !gjson.ValidBytes(record.Value) {
+			return nil, errors.New("record value is not valid json")
+		}
+		value := gjson.GetBytes(record.Value, strings.TrimPrefix(recordField, recordValue+"."))
+		if !value.Exists() {
+			return event, nil
+		}
+		return sjson.SetRawBytes(event, field, []byte(value.Raw))
+	case strings.HasPrefix(recordField, recordHeader+"."):
+		name := strings.TrimPrefix(recordField, recordHeader+".")
+		for _, header := range record.Headers {
+			if header.Key == name {
+				return sjson.SetBytes(event, field, string(header.Value))
+			}
+		}
+		return event, nil
+	}
+	return nil, fmt.Errorf("unknown record field %q", recordField)
+}
+
+// messageID returns a deterministic message id for the record
+func messageID(sourceID string, record *kafka.Message) string {
+	position := sourceID + "/" + record.Topic + "/" + strconv.Itoa(record.Partition) + "/" + strconv.FormatInt(record.Offset, 10)
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte(position)).String()
+}
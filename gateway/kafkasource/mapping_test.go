@@ -0,0 +1,129 @@
+package kafkasource
+
+import (
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestMapRecord(t *testing.T) {
+	record := kafka.Message{
+		Topic:     "orders",
+		Partition: 2,
+		Offset:    42,
+		Key:       []byte("key-1"),
+		Value:     []byte(`{"id":"order-1","customer":{"id":"customer-1"},"amount":10.5}`),
+		Headers:   []kafka.Header{{Key: "tenant", Value: []byte("tenant-1")}},
+		Time:      time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("without a field mapping", func(t *testing.T) {
+		r := record
+		r.Value = []byte(`{"type":"identify","userId":"user-1","traits":{"name":"John"}}`)
+		event, err := mapRecord("source-1", nil, &r)
+		require.NoError(t, err)
+		require.Equal(t, "identify", gjson.GetBytes(event, "type").String())
+		require.Equal(t, "user-1", gjson.GetBytes(event, "userId").String())
+		require.Equal(t, "John", gjson.GetBytes(event, "traits.name").String())
+		require.False(t, gjson.GetBytes(event, "event").Exists())
+		require.False(t, gjson.GetBytes(event, "anonymousId").Exists(), "record key is used only for events without a userId")
+		require.Equal(t, "2024-01-01T10:00:00.000Z", gjson.GetBytes(event, "originalTimestamp").String())
+		require.NotEmpty(t, gjson.GetBytes(event, "messageId").String())
+	})
+
+	t.Run("without a field mapping and a value that isn't an object", func(t *testing.T) {
+		r := record
+		r.Value = []byte(`plain text`)
+		_, err := mapRecord("source-1", nil, &r)
+		require.ErrorIs(t, err, errNotAnObject)
+	})
+
+	t.Run("with a field mapping", func(t *testing.T) {
+		event, err := mapRecord("source-1", map[string]string{
+			"userId":                    "value.customer.id",
+			"properties.orderId":        "value.id",
+			"properties.amount":         "value.amount",
+			"properties.missing":        "value.missing",
+			"properties.raw":            "value",
+			"context.tenant":            "header.tenant",
+			"context.kafka.topic":       "topic",
+			"context.kafka.partition":   "partition",
+			"context.kafka.offset":      "offset",
+			"context.kafka.key":         "key",
+			"context.kafka.publishedAt": "timestamp",
+		}, &record)
+		require.NoError(t, err)
+		require.Equal(t, "track", gjson.GetBytes(event, "type").String())
+		require.Equal(t, "orders", gjson.GetBytes(event, "event").String())
+		require.Equal(t, "customer-1", gjson.GetBytes(event, "userId").String())
+		require.Equal(t, "order-1", gjson.GetBytes(event, "properties.orderId").String())
+		require.Equal(t, 10.5, gjson.GetBytes(event, "properties.amount").Float())
+		require.False(t, gjson.GetBytes(event, "properties.missing").Exists())
+		require.JSONEq(t, string(record.Value), gjson.GetBytes(event, "properties.raw").Raw)
+		require.Equal(t, "tenant-1", gjson.GetBytes(event, "context.tenant").String())
+		require.Equal(t, "orders", gjson.GetBytes(event, "context.kafka.topic").String())
+		require.EqualValues(t, 2, gjson.GetBytes(event, "context.kafka.partition").Int())
+		require.EqualValues(t, 42, gjson.GetBytes(event, "context.kafka.offset").Int())
+		require.Equal(t, "key-1", gjson.GetBytes(event, "context.kafka.key").String())
+		require.Equal(t, "2024-01-01T10:00:00.000Z", gjson.GetBytes(event, "context.kafka.publishedAt").String())
+	})
+
+	t.Run("record key as anonymousId", func(t *testing.T) {
+		event, err := mapRecord("source-1", map[string]string{"properties.orderId": "value.id"}, &record)
+		require.NoError(t, err)
+		require.Equal(t, "key-1", gjson.GetBytes(event, "anonymousId").String())
+		require.False(t, gjson.GetBytes(event, "userId").Exists())
+	})
+
+	t.Run("deterministic messageId", func(t *testing.T) {
+		event1, err := mapRecord("source-1", nil, &record)
+		require.NoError(t, err)
+		event2, err := mapRecord("source-1", nil, &record)
+		require.NoError(t, err)
+		require.Equal(t, gjson.GetBytes(event1, "messageId").String(), gjson.GetBytes(event2, "messageId").String())
+
+		next := record
+		next.Offset++
+		event3, err := mapRecord("source-1", nil, &next)
+		require.NoError(t, err)
+		require.NotEqual(t, gjson.GetBytes(event1, "messageId").String(), gjson.GetBytes(event3, "messageId").String())
+	})
+}
+
+func TestParseSourceConfig(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		c, err := parseSourceConfig("source-1", map[string]interface{}{
+			"hostname":     "broker-1, broker-2",
+			"port":         "9092",
+			"topics":       []string{"orders"},
+			"fieldMapping": map[string]string{"userId": "key"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"broker-1:9092", "broker-2:9092"}, c.brokers())
+		require.Equal(t, "rudder-source-1", c.ConsumerGroupID)
+		require.Equal(t, kafka.FirstOffset, c.startOffset())
+	})
+
+	for name, config := range map[string]map[string]interface{}{
+		"missing hostname":     {"port": "9092", "topics": []string{"orders"}},
+		"missing port":         {"hostname": "broker", "topics": []string{"orders"}},
+		"missing topics":       {"hostname": "broker", "port": "9092"},
+		"invalid start offset": {"hostname": "broker", "port": "9092", "topics": []string{"orders"}, "startOffset": "middle"},
+		"invalid mapping":      {"hostname": "broker", "port": "9092", "topics": []string{"orders"}, "fieldMapping": map[string]string{"userId": "unknown"}},
+		"invalid sasl type": {
+			"hostname": "broker", "port": "9092", "topics": []string{"orders"},
+			"sslEnabled": true, "useSASL": true, "saslType": "unknown",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			c, err := parseSourceConfig("source-1", config)
+			if err == nil {
+				_, err = c.dialer(time.Second)
+			}
+			require.Error(t, err)
+		})
+	}
+}
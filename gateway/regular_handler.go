@@ -16,9 +16,10 @@ type RegularRequestHandler struct {
 func (rrh *RegularRequestHandler) ProcessRequest(w *http.ResponseWriter, r *http.Request, reqType string, payload []byte, arctx *gwtypes.AuthRequestContext) string {
 	done := make(chan string, 1)
 	start := time.Now()
-	rrh.addToWebRequestQ(w, r, done, reqType, payload, arctx)
+	webReq := rrh.addToWebRequestQ(w, r, done, reqType, payload, arctx)
 	rrh.addToWebRequestQWaitTime.SendTiming(time.Since(start))
 	defer rrh.processRequestTime.Since(start)
 	errorMessage := <-done
+	setRetryAfter(w, webReq.retryAfter)
 	return errorMessage
 }
@@ -72,6 +72,12 @@ const (
 	DestinationDisabled = "Destination is disabled"
 	// NoDestinationIDInHeader - Failed to read destination id from header
 	NoDestinationIDInHeader = "Failed to read destination id from header"
+	// UnsupportedContentEncoding - Content encoding of the request body is not supported
+	UnsupportedContentEncoding = "Unsupported content encoding"
+	// InvalidCompressedBody - Request body cannot be decompressed
+	InvalidCompressedBody = "Invalid compressed request body"
+	// InvalidRequestType - Request type is not supported
+	InvalidRequestType = "Invalid request type"
 
 	transPixelResponse = "\x47\x49\x46\x38\x39\x61\x01\x00\x01\x00\x80\x00\x00\x00\x00\x00\x00\x00\x00\x21\xF9\x04" +
 		"\x01\x00\x00\x00\x00\x2C\x00\x00\x00\x00\x01\x00\x01\x00\x00\x02\x02\x44\x01\x00\x3B"
@@ -100,6 +106,13 @@ var statusMap = map[string]status{
 	NoDestinationIDInHeader: {message: NoDestinationIDInHeader, code: http.StatusBadRequest},
 	InvalidStreamMessage:    {message: InvalidStreamMessage, code: http.StatusBadRequest},
 
+	// bulk specific status
+	UnsupportedContentEncoding: {message: UnsupportedContentEncoding, code: http.StatusUnsupportedMediaType},
+	InvalidCompressedBody:      {message: InvalidCompressedBody, code: http.StatusBadRequest},
+
+	// grpc specific status
+	InvalidRequestType: {message: InvalidRequestType, code: http.StatusBadRequest},
+
 	// webhook specific status
 	InvalidWebhookSource:                           {message: InvalidWebhookSource, code: http.StatusNotFound},
 	SourceTransformerFailed:                        {message: SourceTransformerFailed, code: http.StatusBadRequest},
@@ -8,22 +8,40 @@ import (
 	"sync"
 	"time"
 
+	"github.com/go-redis/redis/v8"
+
 	"github.com/rudderlabs/rudder-go-kit/config"
 	"github.com/rudderlabs/rudder-go-kit/stats"
 	"github.com/rudderlabs/rudder-go-kit/throttling"
+
+	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
 )
 
 const (
-	throttlingAlgoTypeGCRA = "gcra"
+	throttlingAlgoTypeGCRA      = "gcra"
+	throttlingAlgoTypeRedisGCRA = "redis-gcra"
+
+	// refundsEvictionInterval is how often expired refunds of keys which aren't requested anymore are evicted
+	refundsEvictionInterval = time.Minute
 )
 
 type Limiter interface {
-	// Allow returns true if the limit is not exceeded, false otherwise.
-	Allow(ctx context.Context, cost, rate, window int64, key string) (bool, func(context.Context) error, error)
+	// AllowAfter returns true if the limit is not exceeded, false otherwise, along with the duration until the next allowed request.
+	AllowAfter(ctx context.Context, cost, rate, window int64, key string) (bool, time.Duration, func(context.Context) error, error)
 }
 
 type Throttler interface {
-	CheckLimitReached(context context.Context, workspaceId string, eventCount int64) (bool, error)
+	// CheckLimitReached returns true if any of the limits applying to the request is reached,
+	// along with the duration after which the request can be retried.
+	CheckLimitReached(ctx context.Context, req *Request) (limited bool, retryAfter time.Duration, err error)
+}
+
+// Request holds the events of a gateway request that need to be checked against the rate limits
+type Request struct {
+	WorkspaceID string
+	Source      backendconfig.SourceT
+	EventCount  int64
+	UserEvents  map[string]int64 // number of events by user id
 }
 
 type Factory struct {
@@ -31,23 +49,87 @@ type Factory struct {
 	limiter      Limiter
 	throttlers   map[string]*throttler // map key is the workspaceId
 	throttlersMu sync.Mutex
+
+	source throttlingConfig // default limit per source, disabled if its limit is zero
+	user   throttlingConfig // default limit per user of a source, disabled if its limit is zero
+
+	refunds *refunds
 }
 
-// New constructs a new Throttler Factory
+// New constructs a new Throttler Factory.
+// Limits are enforced by the gateway replica itself, unless the redis-gcra algorithm is used,
+// in which case limits are shared across all the replicas using the same redis.
+// Refunds are always local to the replica though, see [refunds].
 func New(stats stats.Stats) (*Factory, error) {
 	f := Factory{
 		Stats:      stats,
 		throttlers: make(map[string]*throttler),
+		refunds:    &refunds{tokens: make(map[string]refund)},
 	}
 	if err := f.initThrottlerFactory(); err != nil {
 		return nil, err
 	}
+	f.source.readEntityThrottlingConfig("source")
+	f.user.readEntityThrottlingConfig("user")
 	return &f, nil
 }
 
-func (f *Factory) CheckLimitReached(context context.Context, workspaceId string, eventCount int64) (bool, error) {
-	t := f.get(workspaceId)
-	return t.checkLimitReached(context, workspaceId, eventCount)
+// CheckLimitReached checks the workspace, source and user limits of the request, one after the other.
+// If a limit is reached, the tokens already consumed by the preceding limits are refunded, so that rejected requests don't use up any quota.
+func (f *Factory) CheckLimitReached(ctx context.Context, req *Request) (limited bool, retryAfter time.Duration, err error) {
+	type consumption struct {
+		throttler *throttler
+		key       string
+		count     int64
+	}
+	var consumed []consumption
+	check := func(t *throttler, key string, count int64) bool {
+		limited, retryAfter, err = t.checkLimitReached(ctx, key, count)
+		if err != nil || limited {
+			for _, c := range consumed {
+				c.throttler.refund(c.key, c.count)
+			}
+			return false
+		}
+		consumed = append(consumed, consumption{throttler: t, key: key, count: count})
+		return true
+	}
+
+	if !check(f.get(req.WorkspaceID), req.WorkspaceID, req.EventCount) {
+		return limited, retryAfter, err
+	}
+	sourceConf, userConf := f.sourceThrottlingConfig(req.Source.RateLimit)
+	if sourceConf.enabled() {
+		st := &throttler{limiter: f.limiter, config: sourceConf, refunds: f.refunds}
+		if !check(st, "source:"+req.Source.ID, req.EventCount) {
+			return limited, retryAfter, err
+		}
+	}
+	if userConf.enabled() {
+		ut := &throttler{limiter: f.limiter, config: userConf, refunds: f.refunds}
+		for userID, count := range req.UserEvents {
+			if !check(ut, "user:"+req.Source.ID+":"+userID, count) {
+				return limited, retryAfter, err
+			}
+		}
+	}
+	return false, 0, nil
+}
+
+// sourceThrottlingConfig returns the source and user limits of a source, each limit set by the source's override taking precedence over the default one
+func (f *Factory) sourceThrottlingConfig(rl backendconfig.RateLimitT) (sourceConf, userConf throttlingConfig) {
+	sourceConf, userConf = f.source, f.user
+	if rl.EventLimit > 0 {
+		sourceConf.limit = rl.EventLimit
+	}
+	if rl.UserEventLimit > 0 {
+		userConf.limit = rl.UserEventLimit
+	}
+	if rl.Window > 0 {
+		sourceConf.window = time.Duration(rl.Window) * time.Second
+		userConf.window = sourceConf.window
+	}
+	return sourceConf, userConf
 }
 
 func (f *Factory) get(workspaceId string) *throttler {
@@ -62,6 +144,7 @@ func (f *Factory) get(workspaceId string) *throttler {
 	f.throttlers[workspaceId] = &throttler{
 		limiter: f.limiter,
 		config:  conf,
+		refunds: f.refunds,
 	}
 	return f.throttlers[workspaceId]
 }
@@ -80,6 +163,16 @@ func (f *Factory) initThrottlerFactory() error {
 	switch throttlingAlgorithm {
 	case throttlingAlgoTypeGCRA:
 		l, err = throttling.New(append(opts, throttling.WithInMemoryGCRA(0))...)
+	case throttlingAlgoTypeRedisGCRA:
+		if !config.IsSet("Gateway.throttler.redis.addr") {
+			return fmt.Errorf("redis address is required with algorithm %s", throttlingAlgorithm)
+		}
+		redisClient := redis.NewClient(&redis.Options{
+			Addr:     config.GetString("Gateway.throttler.redis.addr", "localhost:6379"),
+			Username: config.GetString("Gateway.throttler.redis.username", ""),
+			Password: config.GetString("Gateway.throttler.redis.password", ""),
+		})
+		l, err = throttling.New(append(opts, throttling.WithRedisGCRA(redisClient, 0))...)
 	default:
 		return fmt.Errorf("invalid throttling algorithm: %s", throttlingAlgorithm)
 	}
@@ -95,18 +188,96 @@ func (f *Factory) initThrottlerFactory() error {
 type throttler struct {
 	limiter Limiter
 	config  throttlingConfig
+	refunds *refunds // optional
 }
 
-// checkLimitReached returns true if we're not allowed to process the number of event
-func (t *throttler) checkLimitReached(ctx context.Context, key string, count int64) (limited bool, retErr error) {
-	allowed, _, err := t.limiter.Allow(ctx, count, t.config.limit, getWindowInSecs(t.config.window), key)
+// checkLimitReached returns true if we're not allowed to process the number of event.
+// Refunded tokens of the key are spent first, the limiter is only asked for the remaining ones.
+func (t *throttler) checkLimitReached(ctx context.Context, key string, count int64) (limited bool, retryAfter time.Duration, retErr error) {
+	var refunded int64
+	if t.refunds != nil {
+		refunded = t.refunds.take(key, count)
+	}
+	cost := count - refunded
+	if cost == 0 {
+		return false, 0, nil
+	}
+	allowed, retryAfter, _, err := t.limiter.AllowAfter(ctx, cost, t.config.limit, getWindowInSecs(t.config.window), key)
+	if err != nil || !allowed {
+		t.refund(key, refunded)
+	}
 	if err != nil {
-		return false, fmt.Errorf("could not limit: %w", err)
+		return false, 0, fmt.Errorf("could not limit: %w", err)
 	}
 	if !allowed {
-		return true, nil // no token to return when limited
+		return true, retryAfter, nil // no token to return when limited
 	}
-	return false, nil
+	return false, 0, nil
+}
+
+// refund gives back tokens consumed for the key by a request which got rejected afterwards
+func (t *throttler) refund(key string, count int64) {
+	if t.refunds == nil || count == 0 {
+		return
+	}
+	t.refunds.add(key, count, t.config.window)
+}
+
+// refunds holds the tokens which have been consumed by requests that got rejected by a subsequent limit.
+// The limiters cannot return tokens, so refunded tokens are spent by the following requests of the same key instead.
+// A refund expires after the limit's window, since by then the limiter has replenished the tokens anyway.
+//
+// Refunds are kept in memory, even when limits are shared through redis: tokens refunded by a replica
+// can only be spent by the requests it receives, while the other replicas keep seeing them as consumed until the window ends.
+type refunds struct {
+	mu        sync.Mutex
+	tokens    map[string]refund
+	nextEvict time.Time // expired refunds are evicted lazily by take, and by add at most once per refundsEvictionInterval
+}
+
+type refund struct {
+	count     int64
+	expiresAt time.Time
+}
+
+func (r *refunds) add(key string, count int64, window time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	if now.After(r.nextEvict) {
+		for k, v := range r.tokens {
+			if now.After(v.expiresAt) {
+				delete(r.tokens, k)
+			}
+		}
+		r.nextEvict = now.Add(refundsEvictionInterval)
+	}
+	existing := r.tokens[key]
+	if now.After(existing.expiresAt) {
+		existing.count = 0
+	}
+	r.tokens[key] = refund{count: existing.count + count, expiresAt: now.Add(window)}
+}
+
+// take spends up to count refunded tokens of the key, returning the number of tokens spent
+func (r *refunds) take(key string, count int64) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	v, ok := r.tokens[key]
+	if !ok {
+		return 0
+	}
+	if time.Now().After(v.expiresAt) {
+		delete(r.tokens, key)
+		return 0
+	}
+	taken := min(count, v.count)
+	if v.count -= taken; v.count == 0 {
+		delete(r.tokens, key)
+	} else {
+		r.tokens[key] = v
+	}
+	return taken
 }
 
 type throttlingConfig struct {
@@ -130,6 +301,16 @@ func (c *throttlingConfig) readThrottlingConfig(workspaceID string) {
 	}
 }
 
+// readEntityThrottlingConfig reads the default limit for the entity (source or user), which is disabled unless configured
+func (c *throttlingConfig) readEntityThrottlingConfig(entity string) {
+	c.limit = config.GetInt64(fmt.Sprintf("RateLimit.%s.eventLimit", entity), 0)
+	c.window = config.GetDuration(fmt.Sprintf("RateLimit.%s.rateLimitWindow", entity), 60, time.Second)
+}
+
+func (c *throttlingConfig) enabled() bool {
+	return c.limit > 0 && getWindowInSecs(c.window) > 0
+}
+
 func getWindowInSecs(d time.Duration) int64 {
 	return int64(d.Seconds())
 }
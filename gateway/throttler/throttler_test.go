@@ -2,14 +2,18 @@ package throttler
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/stretchr/testify/require"
 
 	"github.com/rudderlabs/rudder-go-kit/config"
 	"github.com/rudderlabs/rudder-go-kit/stats"
 	"github.com/rudderlabs/rudder-go-kit/throttling"
+
+	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
 )
 
 func TestGateway_Throttler(t *testing.T) {
@@ -34,14 +38,14 @@ func TestGateway_Throttler(t *testing.T) {
 	}
 
 	for i := 0; i < eventLimit; i++ {
-		_, err := testThrottler.checkLimitReached(context.TODO(), workspaceId, 1)
+		_, _, err := testThrottler.checkLimitReached(context.TODO(), workspaceId, 1)
 		require.NoError(t, err)
 	}
 
 	startTime := time.Now()
 	var passed int
 	for i := 0; i < 2*eventLimit; i++ {
-		allowed, err := testThrottler.checkLimitReached(context.TODO(), workspaceId, 1)
+		allowed, _, err := testThrottler.checkLimitReached(context.TODO(), workspaceId, 1)
 		require.NoError(t, err)
 		if allowed {
 			passed++
@@ -69,14 +73,14 @@ func TestGateway_Factory(t *testing.T) {
 	require.NotNil(t, rateLimiter)
 
 	for i := 0; i < eventLimit; i++ {
-		_, err := rateLimiter.CheckLimitReached(context.TODO(), workspaceId, 1)
+		_, _, err := rateLimiter.CheckLimitReached(context.TODO(), &Request{WorkspaceID: workspaceId, EventCount: 1})
 		require.NoError(t, err)
 	}
 
 	startTime := time.Now()
 	var passed int
 	for i := 0; i < 2*eventLimit; i++ {
-		allowed, err := rateLimiter.CheckLimitReached(context.TODO(), workspaceId, 1)
+		allowed, _, err := rateLimiter.CheckLimitReached(context.TODO(), &Request{WorkspaceID: workspaceId, EventCount: 1})
 		require.NoError(t, err)
 		if allowed {
 			passed++
@@ -89,6 +93,131 @@ func TestGateway_Factory(t *testing.T) {
 	)
 }
 
+func TestGateway_FactorySourceAndUserLimits(t *testing.T) {
+	config.Set("RateLimit.eventLimit", 1000)
+	config.Set("RateLimit.source.eventLimit", 10)
+	config.Set("RateLimit.user.eventLimit", 5)
+	defer config.Reset()
+	rateLimiter, err := New(stats.NOP)
+	require.NoError(t, err)
+
+	source := backendconfig.SourceT{ID: "source-1"}
+	request := func(source backendconfig.SourceT, userID string, count int64) *Request {
+		return &Request{WorkspaceID: "testID", Source: source, EventCount: count, UserEvents: map[string]int64{userID: count}}
+	}
+
+	t.Run("user limit", func(t *testing.T) {
+		limited, _, err := rateLimiter.CheckLimitReached(context.TODO(), request(source, "user-1", 5))
+		require.NoError(t, err)
+		require.False(t, limited)
+
+		limited, retryAfter, err := rateLimiter.CheckLimitReached(context.TODO(), request(source, "user-1", 5))
+		require.NoError(t, err)
+		require.True(t, limited, "user-1 reached its limit")
+		require.Positive(t, retryAfter)
+	})
+
+	t.Run("source limit", func(t *testing.T) {
+		source := backendconfig.SourceT{ID: "source-3"}
+		for _, userID := range []string{"user-1", "user-2"} {
+			limited, _, err := rateLimiter.CheckLimitReached(context.TODO(), request(source, userID, 5))
+			require.NoError(t, err)
+			require.False(t, limited, "users have their own limits")
+		}
+
+		// gcra lets a request through as long as the limit isn't exceeded by more than a single token
+		limited, retryAfter, err := rateLimiter.CheckLimitReached(context.TODO(), request(source, "user-3", 2))
+		require.NoError(t, err)
+		require.True(t, limited, "source-3 reached its limit")
+		require.Positive(t, retryAfter)
+	})
+
+	t.Run("source override", func(t *testing.T) {
+		source := backendconfig.SourceT{ID: "source-2", RateLimit: backendconfig.RateLimitT{EventLimit: 100, Window: 60}}
+		for i := 0; i < 10; i++ {
+			limited, _, err := rateLimiter.CheckLimitReached(context.TODO(), request(source, fmt.Sprintf("user-%d", i), 5))
+			require.NoError(t, err)
+			require.False(t, limited, "the source's override raises its limit")
+		}
+
+		limited, _, err := rateLimiter.CheckLimitReached(context.TODO(), request(source, "user-0", 5))
+		require.NoError(t, err)
+		require.True(t, limited, "the source's override keeps the default user limit")
+	})
+
+	t.Run("rejected requests don't use up quota", func(t *testing.T) {
+		source := backendconfig.SourceT{ID: "source-4"}
+		limited, _, err := rateLimiter.CheckLimitReached(context.TODO(), request(source, "user-1", 5))
+		require.NoError(t, err)
+		require.False(t, limited)
+
+		limited, _, err = rateLimiter.CheckLimitReached(context.TODO(), request(source, "user-1", 5))
+		require.NoError(t, err)
+		require.True(t, limited, "user-1 reached its limit")
+
+		limited, _, err = rateLimiter.CheckLimitReached(context.TODO(), request(source, "user-2", 5))
+		require.NoError(t, err)
+		require.False(t, limited, "the source's tokens consumed by the rejected request have been refunded")
+
+		limited, _, err = rateLimiter.CheckLimitReached(context.TODO(), request(source, "user-3", 2))
+		require.NoError(t, err)
+		require.True(t, limited, "source-4 reached its limit")
+	})
+}
+
+func TestRefunds(t *testing.T) {
+	r := &refunds{tokens: make(map[string]refund)}
+	r.add("a", 3, time.Millisecond)
+	r.add("b", 5, time.Minute)
+	require.EqualValues(t, 2, r.take("b", 2))
+	require.EqualValues(t, 3, r.take("b", 4))
+	require.Zero(t, r.take("b", 1))
+
+	time.Sleep(2 * time.Millisecond)
+	r.add("c", 1, time.Minute)
+	require.Contains(t, r.tokens, "a", "expired refunds are evicted at most once per eviction interval")
+	r.add("a", 1, time.Minute)
+	require.EqualValues(t, 1, r.take("a", 5), "expired tokens aren't refunded anymore")
+
+	r.add("d", 1, time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+	r.nextEvict = time.Time{}
+	r.add("c", 1, time.Minute)
+	require.NotContains(t, r.tokens, "d")
+	require.EqualValues(t, 2, r.take("c", 5))
+}
+
+func TestGateway_FactoryRedis(t *testing.T) {
+	mr := miniredis.RunT(t)
+	config.Set("Gateway.throttler.algorithm", throttlingAlgoTypeRedisGCRA)
+	config.Set("Gateway.throttler.redis.addr", mr.Addr())
+	config.Set("RateLimit.eventLimit", 10)
+	defer config.Reset()
+
+	// limiters of two gateway replicas sharing the same redis
+	replica1, err := New(stats.NOP)
+	require.NoError(t, err)
+	replica2, err := New(stats.NOP)
+	require.NoError(t, err)
+
+	req := &Request{WorkspaceID: "testID", EventCount: 10}
+	limited, _, err := replica1.CheckLimitReached(context.TODO(), req)
+	require.NoError(t, err)
+	require.False(t, limited)
+
+	limited, retryAfter, err := replica2.CheckLimitReached(context.TODO(), req)
+	require.NoError(t, err)
+	require.True(t, limited, "the workspace's limit is shared by the replicas")
+	require.Positive(t, retryAfter)
+}
+
+func TestGateway_FactoryRedisWithoutAddress(t *testing.T) {
+	config.Set("Gateway.throttler.algorithm", throttlingAlgoTypeRedisGCRA)
+	defer config.Reset()
+	_, err := New(stats.NOP)
+	require.Error(t, err)
+}
+
 func Test_readThrottlingConfig(t *testing.T) {
 	var (
 		workspaceId = "testID"
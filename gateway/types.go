@@ -2,6 +2,7 @@ package gateway
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -27,6 +28,7 @@ type webRequestT struct {
 	ipAddr         string
 	userIDHeader   string
 	errors         []string
+	retryAfter     time.Duration // set if the request was dropped due to rate limiting
 }
 
 type batchWebRequestT struct {
@@ -61,8 +63,9 @@ type userWebRequestWorkerT struct {
 }
 
 type jobFromReq struct {
-	jobs      []*jobsdb.JobT
-	numEvents int
-	botEvents int
-	version   string
+	jobs       []*jobsdb.JobT
+	numEvents  int
+	botEvents  map[string]int // number of bot events by matched rule
+	version    string
+	retryAfter time.Duration // set if the request was dropped due to rate limiting
 }
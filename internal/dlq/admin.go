@@ -0,0 +1,44 @@
+package dlq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Admin exposes the dead-letter queue over the admin rpc interface
+type Admin struct {
+	dlq *DLQ
+}
+
+// NewAdmin returns the admin handler of the dead-letter queue
+func NewAdmin(dlq *DLQ) *Admin {
+	return &Admin{dlq: dlq}
+}
+
+// List returns the entries matching the filter, without their event payloads
+func (a *Admin) List(filter Filter, reply *string) error {
+	entries, err := a.dlq.List(context.Background(), filter, false)
+	if err != nil {
+		return err
+	}
+	formattedOutput, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	*reply = string(formattedOutput)
+	return nil
+}
+
+// Replay re-enqueues the entries matching the filter back into their jobsdb
+func (a *Admin) Replay(filter Filter, reply *string) error {
+	if filter.isEmpty() {
+		return fmt.Errorf("at least one filter is required")
+	}
+	replayed, err := a.dlq.Replay(context.Background(), filter)
+	if err != nil {
+		return err
+	}
+	*reply = fmt.Sprintf("Replayed %d entries", replayed)
+	return nil
+}
@@ -0,0 +1,379 @@
+// Package dlq implements a dead-letter queue for jobs aborted by the router and the batch router.
+//
+// Aborted jobs are copied, along with their last error response and attempt count, into the router_dlq table
+// within the same transaction that marks them as aborted. Entries can later be listed, filtered and replayed,
+// i.e. re-enqueued into the jobsdb they were aborted from.
+package dlq
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+
+	"github.com/rudderlabs/rudder-go-kit/config"
+	"github.com/rudderlabs/rudder-go-kit/logger"
+	"github.com/rudderlabs/rudder-server/jobsdb"
+	migrator "github.com/rudderlabs/rudder-server/services/sql-migrator"
+	"github.com/rudderlabs/rudder-server/utils/misc"
+	. "github.com/rudderlabs/rudder-server/utils/tx" //nolint:staticcheck
+)
+
+const tableName = "router_dlq"
+
+var (
+	// ErrUnknownJobsDB is returned when replaying entries whose jobsdb is not known to the dead-letter queue
+	ErrUnknownJobsDB = errors.New("unknown jobsdb")
+
+	columns = []string{
+		"jobsdb", "job_id", "workspace_id", "source_id", "destination_id", "custom_val", "user_id",
+		"attempt_num", "error_code", "error_response", "parameters", "event_payload", "event_count", "job_created_at",
+	}
+)
+
+// Entry is a job that has been aborted and copied into the dead-letter queue
+type Entry struct {
+	ID            int64           `json:"id"`
+	JobsDB        string          `json:"jobsdb"`
+	JobID         int64           `json:"jobId"`
+	WorkspaceID   string          `json:"workspaceId"`
+	SourceID      string          `json:"sourceId"`
+	DestinationID string          `json:"destinationId"`
+	CustomVal     string          `json:"customVal"`
+	UserID        string          `json:"userId"`
+	AttemptNum    int             `json:"attemptNum"`
+	ErrorCode     string          `json:"errorCode"`
+	ErrorResponse json.RawMessage `json:"errorResponse"`
+	Parameters    json.RawMessage `json:"parameters"`
+	EventPayload  json.RawMessage `json:"eventPayload,omitempty"`
+	EventCount    int             `json:"eventCount"`
+	JobCreatedAt  time.Time       `json:"jobCreatedAt"`
+	AbortedAt     time.Time       `json:"abortedAt"`
+	ReplayedAt    *time.Time      `json:"replayedAt,omitempty"`
+}
+
+// NewEntry creates a new dead-letter queue entry for a job which has been aborted from the provided jobsdb (e.g. rt, batch_rt)
+func NewEntry(jobsDB string, job *jobsdb.JobT, status *jobsdb.JobStatusT) Entry {
+	errorResponse := status.ErrorResponse
+	if len(errorResponse) == 0 || !json.Valid(errorResponse) {
+		errorResponse = json.RawMessage(`{}`)
+	}
+	parameters := job.Parameters
+	if len(parameters) == 0 {
+		parameters = json.RawMessage(`{}`)
+	}
+	return Entry{
+		JobsDB:        jobsDB,
+		JobID:         job.JobID,
+		WorkspaceID:   job.WorkspaceId,
+		SourceID:      gjson.GetBytes(job.Parameters, "source_id").String(),
+		DestinationID: gjson.GetBytes(job.Parameters, "destination_id").String(),
+		CustomVal:     job.CustomVal,
+		UserID:        job.UserID,
+		AttemptNum:    status.AttemptNum,
+		ErrorCode:     status.ErrorCode,
+		ErrorResponse: errorResponse,
+		Parameters:    parameters,
+		EventPayload:  job.EventPayload,
+		EventCount:    job.EventCount,
+		JobCreatedAt:  job.CreatedAt,
+	}
+}
+
+// Filter is used for selecting entries from the dead-letter queue. Empty fields are ignored.
+type Filter struct {
+	JobsDB        string   `json:"jobsdb"`
+	WorkspaceID   string   `json:"workspaceId"`
+	SourceID      string   `json:"sourceId"`
+	DestinationID string   `json:"destinationId"`
+	ErrorCodes    []string `json:"errorCodes"`
+	// IDs limits the selection to specific entries
+	IDs []int64 `json:"ids"`
+	// IncludeReplayed includes entries that have already been replayed
+	IncludeReplayed bool `json:"includeReplayed"`
+	// AfterID returns entries with an id greater than the provided one, used for pagination
+	AfterID int64 `json:"afterId"`
+	// Limit is the maximum number of entries to return
+	Limit int `json:"limit"`
+}
+
+func (f Filter) isEmpty() bool {
+	return f.JobsDB == "" && f.WorkspaceID == "" && f.SourceID == "" && f.DestinationID == "" &&
+		len(f.ErrorCodes) == 0 && len(f.IDs) == 0 && f.AfterID == 0
+}
+
+func (f Filter) where() (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if f.JobsDB != "" {
+		add("jobsdb = $%d", f.JobsDB)
+	}
+	if f.WorkspaceID != "" {
+		add("workspace_id = $%d", f.WorkspaceID)
+	}
+	if f.SourceID != "" {
+		add("source_id = $%d", f.SourceID)
+	}
+	if f.DestinationID != "" {
+		add("destination_id = $%d", f.DestinationID)
+	}
+	if len(f.ErrorCodes) > 0 {
+		add("error_code = ANY($%d)", pq.Array(f.ErrorCodes))
+	}
+	if len(f.IDs) > 0 {
+		add("id = ANY($%d)", pq.Array(f.IDs))
+	}
+	if f.AfterID > 0 {
+		add("id > $%d", f.AfterID)
+	}
+	if !f.IncludeReplayed {
+		conditions = append(conditions, "replayed_at IS NULL")
+	}
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// Writer stores entries into the dead-letter queue
+type Writer interface {
+	// StoreInTx stores the entries using the provided transaction, which is expected to be the same one that marks the jobs as aborted
+	StoreInTx(ctx context.Context, tx *Tx, entries []Entry) error
+}
+
+// DLQ is the dead-letter queue for aborted router & batch router jobs
+type DLQ struct {
+	log  logger.Logger
+	conf *config.Config
+	db   *sql.DB
+
+	jobsDBsMu sync.RWMutex
+	jobsDBs   map[string]jobsdb.JobsDB
+
+	done *atomic.Bool
+	wg   sync.WaitGroup
+}
+
+// New returns a new dead-letter queue after running its database migrations
+func New(conf *config.Config, log logger.Logger) (*DLQ, error) {
+	db, err := setupDBConn(conf)
+	if err != nil {
+		return nil, fmt.Errorf("db setup: %w", err)
+	}
+	if err := migrate(conf, db); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("db migrations: %w", err)
+	}
+	return &DLQ{
+		log:     log,
+		conf:    conf,
+		db:      db,
+		jobsDBs: make(map[string]jobsdb.JobsDB),
+		done:    &atomic.Bool{},
+	}, nil
+}
+
+// RegisterJobsDB registers a jobsdb which can be used as a target when replaying entries
+func (d *DLQ) RegisterJobsDB(jobsDB jobsdb.JobsDB) {
+	d.jobsDBsMu.Lock()
+	defer d.jobsDBsMu.Unlock()
+	d.jobsDBs[jobsDB.Identifier()] = jobsDB
+}
+
+// StoreInTx stores the entries using the provided transaction
+func (d *DLQ) StoreInTx(ctx context.Context, tx *Tx, entries []Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn(tableName, columns...))
+	if err != nil {
+		return fmt.Errorf("preparing statement: %w", err)
+	}
+	defer func() { _ = stmt.Close() }()
+	for _, e := range entries {
+		if _, err := stmt.ExecContext(ctx,
+			e.JobsDB, e.JobID, e.WorkspaceID, e.SourceID, e.DestinationID, e.CustomVal, e.UserID,
+			e.AttemptNum, e.ErrorCode, string(e.ErrorResponse), string(e.Parameters), string(e.EventPayload), e.EventCount, e.JobCreatedAt,
+		); err != nil {
+			return fmt.Errorf("copying entry for job %d: %w", e.JobID, err)
+		}
+	}
+	if _, err = stmt.ExecContext(ctx); err != nil {
+		return fmt.Errorf("executing copy: %w", err)
+	}
+	return nil
+}
+
+// List returns the entries matching the filter, ordered by id. Event payloads are included only if withPayload is true.
+func (d *DLQ) List(ctx context.Context, filter Filter, withPayload bool) ([]Entry, error) {
+	limit := filter.Limit
+	if maxLimit := d.conf.GetInt("DLQ.maxListLimit", 1000); limit <= 0 || limit > maxLimit {
+		limit = maxLimit
+	}
+	payloadColumn := "NULL"
+	if withPayload {
+		payloadColumn = "event_payload"
+	}
+	where, args := filter.where()
+	rows, err := d.db.QueryContext(ctx,
+		`SELECT id, jobsdb, job_id, workspace_id, source_id, destination_id, custom_val, user_id, attempt_num, error_code,
+		error_response, parameters, `+payloadColumn+`, event_count, job_created_at, aborted_at, replayed_at
+		FROM `+tableName+where+` ORDER BY id ASC LIMIT `+strconv.Itoa(limit), args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying entries: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	var entries []Entry
+	for rows.Next() {
+		var e Entry
+		var errorResponse, parameters, payload []byte
+		var replayedAt sql.NullTime
+		if err := rows.Scan(&e.ID, &e.JobsDB, &e.JobID, &e.WorkspaceID, &e.SourceID, &e.DestinationID, &e.CustomVal, &e.UserID,
+			&e.AttemptNum, &e.ErrorCode, &errorResponse, &parameters, &payload, &e.EventCount, &e.JobCreatedAt, &e.AbortedAt, &replayedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scanning entry: %w", err)
+		}
+		e.ErrorResponse = errorResponse
+		e.Parameters = parameters
+		e.EventPayload = payload
+		if replayedAt.Valid {
+			e.ReplayedAt = &replayedAt.Time
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating entries: %w", err)
+	}
+	return entries, nil
+}
+
+// Replay re-enqueues the entries matching the filter back into the jobsdb they were aborted from and marks them as replayed.
+// Entries which have already been replayed are always skipped. It returns the number of replayed entries.
+func (d *DLQ) Replay(ctx context.Context, filter Filter) (int, error) {
+	filter.IncludeReplayed = false
+	entries, err := d.List(ctx, filter, true)
+	if err != nil {
+		return 0, err
+	}
+	entriesByJobsDB := make(map[string][]Entry)
+	for _, e := range entries {
+		entriesByJobsDB[e.JobsDB] = append(entriesByJobsDB[e.JobsDB], e)
+	}
+
+	var replayed int
+	for name, entries := range entriesByJobsDB {
+		d.jobsDBsMu.RLock()
+		jobsDB, ok := d.jobsDBs[name]
+		d.jobsDBsMu.RUnlock()
+		if !ok {
+			return replayed, fmt.Errorf("replaying entries of %q: %w", name, ErrUnknownJobsDB)
+		}
+		jobs := make([]*jobsdb.JobT, 0, len(entries))
+		ids := make([]int64, 0, len(entries))
+		for _, e := range entries {
+			jobs = append(jobs, e.job())
+			ids = append(ids, e.ID)
+		}
+		if err := jobsDB.WithStoreSafeTx(ctx, func(tx jobsdb.StoreSafeTx) error {
+			if err := jobsDB.StoreInTx(ctx, tx, jobs); err != nil {
+				return fmt.Errorf("storing jobs: %w", err)
+			}
+			if _, err := tx.SqlTx().ExecContext(ctx, `UPDATE `+tableName+` SET replayed_at = NOW() WHERE id = ANY($1)`, pq.Array(ids)); err != nil {
+				return fmt.Errorf("marking entries as replayed: %w", err)
+			}
+			return nil
+		}); err != nil {
+			return replayed, fmt.Errorf("replaying entries of %q: %w", name, err)
+		}
+		replayed += len(entries)
+	}
+	return replayed, nil
+}
+
+// job creates a new job out of the entry, ready to be stored again into its jobsdb
+func (e *Entry) job() *jobsdb.JobT {
+	parameters := []byte(e.Parameters)
+	// remove any abort-related information added to the parameters
+	for _, key := range []string{"stage", "reason"} {
+		if p, err := sjson.DeleteBytes(parameters, key); err == nil {
+			parameters = p
+		}
+	}
+	return &jobsdb.JobT{
+		UUID:         uuid.New(),
+		UserID:       e.UserID,
+		CustomVal:    e.CustomVal,
+		EventCount:   e.EventCount,
+		EventPayload: e.EventPayload,
+		Parameters:   parameters,
+		WorkspaceId:  e.WorkspaceID,
+	}
+}
+
+// CleanupRoutine periodically deletes entries older than the configured retention period
+func (d *DLQ) CleanupRoutine(ctx context.Context) error {
+	d.wg.Add(1)
+	defer d.wg.Done()
+	for {
+		if d.done.Load() {
+			return nil
+		}
+		if _, err := d.db.ExecContext(
+			ctx,
+			`DELETE FROM `+tableName+` WHERE aborted_at < $1`,
+			time.Now().Add(-d.conf.GetDuration("DLQ.retention", 14*24, time.Hour)),
+		); err != nil && ctx.Err() == nil {
+			d.log.Errorw("db cleanup", "error", err)
+			return fmt.Errorf("db cleanup: %w", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(d.conf.GetDuration("DLQ.cleanupFrequency", 1, time.Hour)):
+		}
+	}
+}
+
+// Stop stops the dead-letter queue and closes its database connection
+func (d *DLQ) Stop() {
+	d.done.Store(true)
+	d.wg.Wait()
+	_ = d.db.Close()
+}
+
+func migrate(conf *config.Config, db *sql.DB) error {
+	m := &migrator.Migrator{
+		Handle:                     db,
+		MigrationsTable:            "router_dlq_migrations",
+		ShouldForceSetLowerVersion: conf.GetBool("SQLMigrator.forceSetLowerVersion", true),
+	}
+	return m.Migrate("router_dlq")
+}
+
+// setupDBConn sets up the database connection. The dead-letter queue needs to share the same database with jobsdb,
+// so that entries can be stored within the same transaction that aborts the jobs.
+func setupDBConn(conf *config.Config) (*sql.DB, error) {
+	db, err := sql.Open("postgres", misc.GetConnectionString(conf, "router-dlq"))
+	if err != nil {
+		return nil, fmt.Errorf("db open: %w", err)
+	}
+	db.SetMaxIdleConns(conf.GetInt("DLQ.maxIdleConns", 1))
+	db.SetMaxOpenConns(conf.GetInt("DLQ.maxOpenConns", 5))
+	return db, nil
+}
@@ -0,0 +1,169 @@
+package dlq_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ory/dockertest/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rudderlabs/rudder-go-kit/config"
+	"github.com/rudderlabs/rudder-go-kit/logger"
+	"github.com/rudderlabs/rudder-go-kit/testhelper/docker/resource/postgres"
+	"github.com/rudderlabs/rudder-server/internal/dlq"
+	"github.com/rudderlabs/rudder-server/jobsdb"
+)
+
+func TestDLQ(t *testing.T) {
+	ctx := context.Background()
+	pool, err := dockertest.NewPool("")
+	require.NoError(t, err)
+	postgresResource, err := postgres.Setup(pool, t)
+	require.NoError(t, err)
+
+	conf := config.New()
+	conf.Set("DB.name", postgresResource.Database)
+	conf.Set("DB.host", postgresResource.Host)
+	conf.Set("DB.port", postgresResource.Port)
+	conf.Set("DB.user", postgresResource.User)
+	conf.Set("DB.password", postgresResource.Password)
+
+	rtDB := jobsdb.NewForReadWrite("rt", jobsdb.WithDBHandle(postgresResource.DB), jobsdb.WithConfig(conf))
+	require.NoError(t, rtDB.Start())
+	defer rtDB.TearDown()
+
+	d, err := dlq.New(conf, logger.NOP)
+	require.NoError(t, err)
+	defer d.Stop()
+	d.RegisterJobsDB(rtDB)
+
+	newJob := func(destinationID string) *jobsdb.JobT {
+		return &jobsdb.JobT{
+			UUID:         uuid.New(),
+			UserID:       "user",
+			CustomVal:    "WEBHOOK",
+			EventCount:   1,
+			EventPayload: []byte(`{"key":"value"}`),
+			Parameters:   []byte(`{"source_id":"source","destination_id":"` + destinationID + `"}`),
+			WorkspaceId:  "workspace",
+		}
+	}
+	require.NoError(t, rtDB.Store(ctx, []*jobsdb.JobT{newJob("dest-1"), newJob("dest-1"), newJob("dest-2")}))
+	unprocessed, err := rtDB.GetUnprocessed(ctx, jobsdb.GetQueryParams{JobsLimit: 10})
+	require.NoError(t, err)
+	require.Len(t, unprocessed.Jobs, 3)
+
+	// abort all jobs, storing them into the dead-letter queue within the same transaction
+	var statusList []*jobsdb.JobStatusT
+	var entries []dlq.Entry
+	for i, job := range unprocessed.Jobs {
+		status := &jobsdb.JobStatusT{
+			JobID:         job.JobID,
+			JobState:      jobsdb.Aborted.State,
+			AttemptNum:    3,
+			ExecTime:      time.Now(),
+			RetryTime:     time.Now(),
+			ErrorCode:     []string{"400", "500", "400"}[i],
+			ErrorResponse: []byte(`{"response":"error"}`),
+			Parameters:    []byte(`{}`),
+			JobParameters: job.Parameters,
+			WorkspaceId:   job.WorkspaceId,
+		}
+		statusList = append(statusList, status)
+		entries = append(entries, dlq.NewEntry(rtDB.Identifier(), job, status))
+	}
+	require.NoError(t, rtDB.WithUpdateSafeTx(ctx, func(tx jobsdb.UpdateSafeTx) error {
+		if err := rtDB.UpdateJobStatusInTx(ctx, tx, statusList, nil, nil); err != nil {
+			return err
+		}
+		return d.StoreInTx(ctx, tx.Tx(), entries)
+	}))
+
+	t.Run("list", func(t *testing.T) {
+		all, err := d.List(ctx, dlq.Filter{}, false)
+		require.NoError(t, err)
+		require.Len(t, all, 3)
+		require.Equal(t, "rt", all[0].JobsDB)
+		require.Equal(t, unprocessed.Jobs[0].JobID, all[0].JobID)
+		require.Equal(t, 3, all[0].AttemptNum)
+		require.JSONEq(t, `{"response":"error"}`, string(all[0].ErrorResponse))
+		require.Empty(t, all[0].EventPayload)
+
+		filtered, err := d.List(ctx, dlq.Filter{DestinationID: "dest-1", ErrorCodes: []string{"400"}}, true)
+		require.NoError(t, err)
+		require.Len(t, filtered, 1)
+		require.JSONEq(t, `{"key":"value"}`, string(filtered[0].EventPayload))
+
+		paginated, err := d.List(ctx, dlq.Filter{AfterID: all[0].ID, Limit: 1}, false)
+		require.NoError(t, err)
+		require.Len(t, paginated, 1)
+		require.Equal(t, all[1].ID, paginated[0].ID)
+	})
+
+	t.Run("http list", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/entries?destinationId=dest-1&errorCode=400,500", http.NoBody)
+		resp := httptest.NewRecorder()
+		d.HttpHandler().ServeHTTP(resp, req)
+		require.Equal(t, http.StatusOK, resp.Code)
+		var entries []dlq.Entry
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &entries))
+		require.Len(t, entries, 2)
+
+		req = httptest.NewRequest(http.MethodGet, "/entries?limit=invalid", http.NoBody)
+		resp = httptest.NewRecorder()
+		d.HttpHandler().ServeHTTP(resp, req)
+		require.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("http replay", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/replay", bytes.NewBufferString(`{}`))
+		resp := httptest.NewRecorder()
+		d.HttpHandler().ServeHTTP(resp, req)
+		require.Equal(t, http.StatusBadRequest, resp.Code, "a filter is required")
+
+		req = httptest.NewRequest(http.MethodPost, "/replay", bytes.NewBufferString(`{"destinationId":"dest-1"}`))
+		resp = httptest.NewRecorder()
+		d.HttpHandler().ServeHTTP(resp, req)
+		require.Equal(t, http.StatusOK, resp.Code)
+		require.JSONEq(t, `{"replayed":2}`, resp.Body.String())
+
+		replayedJobs, err := rtDB.GetUnprocessed(ctx, jobsdb.GetQueryParams{JobsLimit: 10})
+		require.NoError(t, err)
+		require.Len(t, replayedJobs.Jobs, 2)
+		for _, job := range replayedJobs.Jobs {
+			require.Equal(t, "WEBHOOK", job.CustomVal)
+			require.JSONEq(t, `{"key":"value"}`, string(job.EventPayload))
+			require.JSONEq(t, `{"source_id":"source","destination_id":"dest-1"}`, string(job.Parameters))
+		}
+
+		// replayed entries are not replayed again
+		replayed, err := d.Replay(ctx, dlq.Filter{DestinationID: "dest-1"})
+		require.NoError(t, err)
+		require.Zero(t, replayed)
+
+		remaining, err := d.List(ctx, dlq.Filter{}, false)
+		require.NoError(t, err)
+		require.Len(t, remaining, 1)
+		require.Equal(t, "dest-2", remaining[0].DestinationID)
+
+		withReplayed, err := d.List(ctx, dlq.Filter{IncludeReplayed: true}, false)
+		require.NoError(t, err)
+		require.Len(t, withReplayed, 3)
+	})
+
+	t.Run("replay into unknown jobsdb", func(t *testing.T) {
+		unknown := newJob("dest-3")
+		unknown.JobID = 1000
+		require.NoError(t, rtDB.WithUpdateSafeTx(ctx, func(tx jobsdb.UpdateSafeTx) error {
+			return d.StoreInTx(ctx, tx.Tx(), []dlq.Entry{dlq.NewEntry("batch_rt", unknown, &jobsdb.JobStatusT{AttemptNum: 1})})
+		}))
+		_, err := d.Replay(ctx, dlq.Filter{DestinationID: "dest-3"})
+		require.ErrorIs(t, err, dlq.ErrUnknownJobsDB)
+	})
+}
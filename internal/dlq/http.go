@@ -0,0 +1,86 @@
+package dlq
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// HttpHandler returns the http handler of the dead-letter queue
+//
+//   - GET /entries - lists entries, filtered by the jobsdb, workspaceId, sourceId, destinationId, errorCode, afterId, limit, includeReplayed & withPayload query parameters
+//   - POST /replay - re-enqueues the entries matching the [Filter] provided in the request body
+func (d *DLQ) HttpHandler() http.Handler {
+	srvMux := chi.NewRouter()
+	srvMux.Get("/entries", d.listEntries)
+	srvMux.Post("/replay", d.replay)
+	return srvMux
+}
+
+func (d *DLQ) listEntries(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := Filter{
+		JobsDB:        query.Get("jobsdb"),
+		WorkspaceID:   query.Get("workspaceId"),
+		SourceID:      query.Get("sourceId"),
+		DestinationID: query.Get("destinationId"),
+	}
+	for _, errorCode := range query["errorCode"] {
+		filter.ErrorCodes = append(filter.ErrorCodes, strings.Split(errorCode, ",")...)
+	}
+	var err error
+	if v := query.Get("afterId"); v != "" {
+		if filter.AfterID, err = strconv.ParseInt(v, 10, 64); err != nil {
+			http.Error(w, "invalid afterId", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := query.Get("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+	filter.IncludeReplayed, _ = strconv.ParseBool(query.Get("includeReplayed"))
+	withPayload, _ := strconv.ParseBool(query.Get("withPayload"))
+
+	entries, err := d.List(r.Context(), filter, withPayload)
+	if err != nil {
+		d.log.Errorw("listing dlq entries", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if entries == nil {
+		entries = []Entry{}
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(entries)
+}
+
+func (d *DLQ) replay(w http.ResponseWriter, r *http.Request) {
+	var filter Filter
+	if err := json.NewDecoder(r.Body).Decode(&filter); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if filter.isEmpty() {
+		http.Error(w, "at least one filter is required", http.StatusBadRequest)
+		return
+	}
+	replayed, err := d.Replay(r.Context(), filter)
+	if err != nil {
+		d.log.Errorw("replaying dlq entries", "error", err)
+		status := http.StatusInternalServerError
+		if errors.Is(err, ErrUnknownJobsDB) {
+			status = http.StatusBadRequest
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(map[string]int{"replayed": replayed})
+}
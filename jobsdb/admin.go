@@ -2,11 +2,16 @@ package jobsdb
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
 	"time"
 
+	"github.com/lib/pq"
 	"github.com/samber/lo"
 
+	"github.com/rudderlabs/rudder-server/jobsdb/internal/dsindex"
 	"github.com/rudderlabs/rudder-server/utils/crash"
 	"github.com/rudderlabs/rudder-server/utils/misc"
 )
@@ -54,7 +59,7 @@ func (jd *Handle) deleteJobStatus() {
 				return err
 			}
 			tx.Tx().AddSuccessListener(func() {
-				jd.noResultsCache.InvalidateDataset(ds.Index)
+				jd.invalidateNoResultsCache(ds.Index, "", nil, nil, nil)
 			})
 		}
 
@@ -115,7 +120,7 @@ func (jd *Handle) failExecuting() {
 				return err
 			}
 			tx.Tx().AddSuccessListener(func() {
-				jd.noResultsCache.InvalidateDataset(ds.Index)
+				jd.invalidateNoResultsCache(ds.Index, "", nil, nil, nil)
 			})
 		}
 		return nil
@@ -240,3 +245,274 @@ func (jd *Handle) doCleanup(ctx context.Context, batchSize int) error {
 
 	return nil
 }
+
+// DatasetStats describes the size of a dataset
+type DatasetStats struct {
+	Index             string `json:"index"`
+	JobTable          string `json:"jobTable"`
+	JobStatusTable    string `json:"jobStatusTable"`
+	PayloadColumnType string `json:"payloadColumnType"`
+	Jobs              int64  `json:"jobs"`                // number of jobs
+	PendingJobs       int64  `json:"pendingJobs"`         // number of jobs without a terminal status
+	JobStatuses       int64  `json:"jobStatuses"`         // number of job statuses
+	JobsSize          int64  `json:"jobsSize"`            // size of the jobs table in bytes, including its indexes and toast
+	JobStatusesSize   int64  `json:"jobStatusesSize"`     // size of the job status table in bytes, including its indexes
+	Cold              bool   `json:"cold,omitempty"`      // whether the dataset's pending jobs have been offloaded to object storage
+	ObjectKey         string `json:"objectKey,omitempty"` // object storage key of a cold dataset's jobs
+}
+
+// GetDatasetStats returns the row counts and sizes of all datasets, including cold ones, ordered by their index
+func (jd *Handle) GetDatasetStats(ctx context.Context) ([]DatasetStats, error) {
+	if !jd.dsMigrationLock.RTryLockWithCtx(ctx) {
+		return nil, fmt.Errorf("could not acquire a migration read lock: %w", ctx.Err())
+	}
+	defer jd.dsMigrationLock.RUnlock()
+	if !jd.dsListLock.RTryLockWithCtx(ctx) {
+		return nil, fmt.Errorf("could not acquire a dslist read lock: %w", ctx.Err())
+	}
+	dsList := jd.getDSList()
+	jd.dsListLock.RUnlock()
+
+	datasets := make([]DatasetStats, 0, len(dsList))
+	for _, ds := range dsList {
+		dsStats := DatasetStats{
+			Index:          ds.Index,
+			JobTable:       ds.JobTable,
+			JobStatusTable: ds.JobStatusTable,
+		}
+		payloadType, err := jd.payloadColumnTypeOf(ctx, jd.dbHandle, ds)
+		if err != nil {
+			return nil, err
+		}
+		dsStats.PayloadColumnType = string(payloadType)
+		var terminalJobs int64
+		if err := jd.dbHandle.QueryRowContext(ctx, fmt.Sprintf(
+			`SELECT
+				(SELECT COUNT(*) FROM %[1]q),
+				(SELECT COUNT(DISTINCT(job_id)) FROM %[2]q WHERE job_state = ANY($1)),
+				(SELECT COUNT(*) FROM %[2]q),
+				pg_total_relation_size($2::regclass),
+				pg_total_relation_size($3::regclass)`,
+			ds.JobTable, ds.JobStatusTable),
+			pq.Array(validTerminalStates), pq.QuoteIdentifier(ds.JobTable), pq.QuoteIdentifier(ds.JobStatusTable),
+		).Scan(&dsStats.Jobs, &terminalJobs, &dsStats.JobStatuses, &dsStats.JobsSize, &dsStats.JobStatusesSize); err != nil {
+			return nil, fmt.Errorf("getting stats of dataset %s: %w", ds.Index, err)
+		}
+		dsStats.PendingJobs = dsStats.Jobs - terminalJobs
+		datasets = append(datasets, dsStats)
+	}
+	for _, cds := range jd.getColdDSList() {
+		datasets = append(datasets, DatasetStats{
+			Index:       cds.Index,
+			Jobs:        int64(cds.Jobs),
+			PendingJobs: int64(cds.Jobs), // only pending jobs are offloaded
+			Cold:        true,
+			ObjectKey:   cds.ObjectKey,
+		})
+	}
+	slices.SortFunc(datasets, func(a, b DatasetStats) int {
+		if dsindex.MustParse(a.Index).Less(dsindex.MustParse(b.Index)) {
+			return -1
+		}
+		return 1
+	})
+	return datasets, nil
+}
+
+// JobsFilter selects the jobs that an admin operation is applied to
+type JobsFilter struct {
+	WorkspaceID   string   `json:"workspaceId"`
+	CustomVal     string   `json:"customVal"`
+	SourceID      string   `json:"sourceId"`
+	DestinationID string   `json:"destinationId"`
+	States        []string `json:"states"` // states of the jobs' last status, defaults to all states the operation can be applied to
+	Limit         int      `json:"limit"`  // maximum number of jobs to update, no limit if zero
+	DryRun        bool     `json:"dryRun"` // only count the matching jobs, without updating them
+}
+
+// errInvalidJobsFilter is returned for filters that admin operations cannot be applied with
+var errInvalidJobsFilter = errors.New("invalid jobs filter")
+
+func (f JobsFilter) validate(allowedStates []string) error {
+	if f.WorkspaceID == "" && f.CustomVal == "" && f.SourceID == "" && f.DestinationID == "" {
+		return fmt.Errorf("%w: at least one of workspaceId, customVal, sourceId or destinationId is required", errInvalidJobsFilter)
+	}
+	for _, state := range f.States {
+		if !slices.Contains(allowedStates, state) {
+			return fmt.Errorf("%w: state %q is not one of %v", errInvalidJobsFilter, state, allowedStates)
+		}
+	}
+	if f.Limit < 0 {
+		return fmt.Errorf("%w: limit cannot be negative", errInvalidJobsFilter)
+	}
+	return nil
+}
+
+var (
+	// states of jobs that can be aborted, executing jobs are excluded since they are being processed
+	abortableStates = []string{Unprocessed.State, Failed.State, Waiting.State}
+	// states of jobs that can be reset to failed
+	failableStates = []string{Failed.State, Waiting.State, Aborted.State}
+)
+
+// AbortHook is called within the transaction updating the statuses of the jobs aborted by [Handle.AbortJobs],
+// so that their owner can handle them the same way it handles the jobs it aborts, e.g. report them.
+type AbortHook func(ctx context.Context, tx UpdateSafeTx, jobs []*JobT, statuses []*JobStatusT) error
+
+// AbortJobs aborts the jobs matching the filter, calling the hook (if not nil) for every batch of aborted jobs.
+// It returns the number of jobs aborted.
+func (jd *Handle) AbortJobs(ctx context.Context, filter JobsFilter, reason string, hook AbortHook) (int, error) {
+	if err := filter.validate(abortableStates); err != nil {
+		return 0, err
+	}
+	return jd.updateJobsByFilter(ctx, filter, abortableStates, func(job *JobT) *JobStatusT {
+		return &JobStatusT{
+			JobID:         job.JobID,
+			JobState:      Aborted.State,
+			AttemptNum:    job.LastJobStatus.AttemptNum,
+			ExecTime:      time.Now(),
+			RetryTime:     time.Now(),
+			ErrorCode:     "0",
+			ErrorResponse: adminErrorResponse(reason),
+			Parameters:    []byte(`{}`),
+			JobParameters: job.Parameters,
+			WorkspaceId:   job.WorkspaceId,
+		}
+	}, hook)
+}
+
+// FailJobs resets the jobs matching the filter to failed, along with their attempts, so that they are retried immediately.
+// It returns the number of jobs reset.
+func (jd *Handle) FailJobs(ctx context.Context, filter JobsFilter, reason string) (int, error) {
+	if err := filter.validate(failableStates); err != nil {
+		return 0, err
+	}
+	return jd.updateJobsByFilter(ctx, filter, failableStates, func(job *JobT) *JobStatusT {
+		return &JobStatusT{
+			JobID:         job.JobID,
+			JobState:      Failed.State,
+			AttemptNum:    0,
+			ExecTime:      time.Now(),
+			RetryTime:     time.Now(),
+			ErrorCode:     "0",
+			ErrorResponse: adminErrorResponse(reason),
+			Parameters:    []byte(`{}`),
+			JobParameters: job.Parameters,
+			WorkspaceId:   job.WorkspaceId,
+		}
+	}, nil)
+}
+
+// updateJobsByFilter reads the jobs matching the filter in batches and updates their status through [Handle.UpdateJobStatusInTx],
+// which also takes care of invalidating the no results cache. The hook, if not nil, is called for every batch within the same transaction.
+// Jobs whose status has changed since they were read, e.g. by a router picking them up in the meantime, are skipped.
+func (jd *Handle) updateJobsByFilter(ctx context.Context, filter JobsFilter, defaultStates []string, newStatus func(job *JobT) *JobStatusT, hook AbortHook) (int, error) {
+	states := filter.States
+	if len(states) == 0 {
+		states = defaultStates
+	}
+	params := GetQueryParams{WorkspaceID: filter.WorkspaceID}
+	if filter.CustomVal != "" {
+		params.CustomValFilters = []string{filter.CustomVal}
+	} else {
+		params.IgnoreCustomValFiltersInQuery = true
+	}
+	if filter.SourceID != "" {
+		params.ParameterFilters = append(params.ParameterFilters, ParameterFilterT{Name: "source_id", Value: filter.SourceID})
+	}
+	if filter.DestinationID != "" {
+		params.ParameterFilters = append(params.ParameterFilters, ParameterFilterT{Name: "destination_id", Value: filter.DestinationID})
+	}
+
+	batchSize := jd.config.GetInt("JobsDB.admin.batchSize", 1000)
+	var updated int
+	for filter.Limit == 0 || updated < filter.Limit {
+		params.JobsLimit = batchSize
+		if filter.Limit > 0 {
+			params.JobsLimit = min(batchSize, filter.Limit-updated)
+		}
+		res, err := jd.GetJobs(ctx, states, params)
+		if err != nil {
+			return updated, fmt.Errorf("getting jobs: %w", err)
+		}
+		if len(res.Jobs) == 0 {
+			break
+		}
+		batchUpdated := len(res.Jobs)
+		if !filter.DryRun {
+			if err := jd.WithUpdateSafeTx(ctx, func(tx UpdateSafeTx) error {
+				jobs, err := jd.unchangedJobsInTx(ctx, tx, res.Jobs)
+				if err != nil {
+					return fmt.Errorf("checking job statuses: %w", err)
+				}
+				if batchUpdated = len(jobs); batchUpdated == 0 {
+					return nil
+				}
+				statusList := lo.Map(jobs, func(job *JobT, _ int) *JobStatusT { return newStatus(job) })
+				if err := jd.UpdateJobStatusInTx(ctx, tx, statusList, params.CustomValFilters, params.ParameterFilters); err != nil {
+					return fmt.Errorf("updating job statuses: %w", err)
+				}
+				if hook != nil {
+					return hook(ctx, tx, jobs, statusList)
+				}
+				return nil
+			}); err != nil {
+				return updated, err
+			}
+		}
+		updated += batchUpdated
+		params.afterJobID = &res.Jobs[len(res.Jobs)-1].JobID
+		if len(res.Jobs) < params.JobsLimit {
+			break
+		}
+	}
+	if !filter.DryRun && updated > 0 {
+		jd.logger.Infow("jobs updated by admin", "filter", filter, "count", updated)
+	}
+	return updated, nil
+}
+
+// unchangedJobsInTx returns the jobs whose latest status is still the one they were read with.
+// Jobs which aren't found in the datasets of the transaction, e.g. the ones of the cold tier, are returned as well.
+func (jd *Handle) unchangedJobsInTx(ctx context.Context, tx UpdateSafeTx, jobs []*JobT) ([]*JobT, error) {
+	type latestStatus struct {
+		state   string
+		attempt int
+	}
+	jobIDs := lo.Map(jobs, func(job *JobT, _ int) int64 { return job.JobID })
+	latest := make(map[int64]latestStatus, len(jobs))
+	for _, ds := range tx.getDSList() {
+		rows, err := tx.SqlTx().QueryContext(ctx, fmt.Sprintf(
+			`SELECT jobs.job_id, COALESCE(job_latest_state.job_state, ''), COALESCE(job_latest_state.attempt, 0)
+				FROM %[1]q AS jobs
+				LEFT JOIN "v_last_%[2]s" job_latest_state ON jobs.job_id = job_latest_state.job_id
+				WHERE jobs.job_id = ANY($1)`,
+			ds.JobTable, ds.JobStatusTable,
+		), pq.Array(jobIDs))
+		if err != nil {
+			return nil, fmt.Errorf("querying latest statuses of %s: %w", ds.JobTable, err)
+		}
+		for rows.Next() {
+			var jobID int64
+			var status latestStatus
+			if err := rows.Scan(&jobID, &status.state, &status.attempt); err != nil {
+				_ = rows.Close()
+				return nil, fmt.Errorf("scanning latest status: %w", err)
+			}
+			latest[jobID] = status
+		}
+		_ = rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("iterating latest statuses of %s: %w", ds.JobTable, err)
+		}
+	}
+	return lo.Filter(jobs, func(job *JobT, _ int) bool {
+		status, ok := latest[job.JobID]
+		return !ok || (status.state == job.LastJobStatus.JobState && status.attempt == job.LastJobStatus.AttemptNum)
+	}), nil
+}
+
+func adminErrorResponse(reason string) json.RawMessage {
+	errorResponse, _ := json.Marshal(map[string]string{"reason": reason})
+	return errorResponse
+}
@@ -0,0 +1,162 @@
+package jobsdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/rudderlabs/rudder-go-kit/logger"
+)
+
+// ErrUnknownJobsDB is returned by admin operations targeting a jobsdb that isn't registered
+var ErrUnknownJobsDB = errors.New("unknown jobsdb")
+
+// Admin exposes inspection and surgery operations of jobsdbs over the admin rpc interface and http.
+// Jobsdbs are identified by their table prefix, e.g. gw, rt & batch_rt.
+type Admin struct {
+	log        logger.Logger
+	dbs        map[string]*Handle
+	abortHooks map[string]AbortHook
+}
+
+// NewAdmin returns the admin handler of the provided jobsdbs
+func NewAdmin(log logger.Logger, dbs ...*Handle) *Admin {
+	a := &Admin{log: log, dbs: make(map[string]*Handle, len(dbs)), abortHooks: make(map[string]AbortHook)}
+	for _, db := range dbs {
+		a.dbs[db.Identifier()] = db
+	}
+	return a
+}
+
+// SetAbortHook sets the hook called for the jobs of a jobsdb aborted through the admin
+func (a *Admin) SetAbortHook(jobsDB string, hook AbortHook) {
+	a.abortHooks[jobsDB] = hook
+}
+
+// PileUpRequest selects the pile-up counts of a jobsdb, optionally filtered by workspace, custom value and destination
+type PileUpRequest struct {
+	JobsDB        string `json:"jobsdb"`
+	WorkspaceID   string `json:"workspaceId"`
+	CustomVal     string `json:"customVal"`
+	DestinationID string `json:"destinationId"`
+}
+
+// JobsRequest selects the jobs of a jobsdb that an admin operation is applied to
+type JobsRequest struct {
+	JobsDB string `json:"jobsdb"`
+	JobsFilter
+	Reason string `json:"reason"`
+}
+
+// Datasets returns the row counts and sizes of all datasets of a jobsdb
+func (a *Admin) Datasets(jobsDB string, reply *string) error {
+	datasets, err := a.datasets(context.Background(), jobsDB)
+	if err != nil {
+		return err
+	}
+	return formatReply(datasets, reply)
+}
+
+// PileUp returns the incomplete jobs of a jobsdb grouped by workspace, custom value, destination and state
+func (a *Admin) PileUp(req PileUpRequest, reply *string) error {
+	pileUp, err := a.pileUp(context.Background(), req)
+	if err != nil {
+		return err
+	}
+	return formatReply(pileUp, reply)
+}
+
+// AbortJobs aborts the jobs matching the request's filter
+func (a *Admin) AbortJobs(req JobsRequest, reply *string) error {
+	aborted, err := a.abortJobs(context.Background(), req)
+	if err != nil {
+		return err
+	}
+	*reply = fmt.Sprintf("Aborted %d jobs", aborted)
+	if req.DryRun {
+		*reply = fmt.Sprintf("%d jobs would be aborted", aborted)
+	}
+	return nil
+}
+
+// FailJobs resets the jobs matching the request's filter to failed
+func (a *Admin) FailJobs(req JobsRequest, reply *string) error {
+	failed, err := a.failJobs(context.Background(), req)
+	if err != nil {
+		return err
+	}
+	*reply = fmt.Sprintf("Reset %d jobs to failed", failed)
+	if req.DryRun {
+		*reply = fmt.Sprintf("%d jobs would be reset to failed", failed)
+	}
+	return nil
+}
+
+func (a *Admin) datasets(ctx context.Context, jobsDB string) ([]DatasetStats, error) {
+	db, err := a.db(jobsDB)
+	if err != nil {
+		return nil, err
+	}
+	return db.GetDatasetStats(ctx)
+}
+
+func (a *Admin) pileUp(ctx context.Context, req PileUpRequest) ([]PileUpCount, error) {
+	db, err := a.db(req.JobsDB)
+	if err != nil {
+		return nil, err
+	}
+	pileUp, err := db.GetPileUp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	filtered := make([]PileUpCount, 0, len(pileUp))
+	for _, c := range pileUp {
+		if (req.WorkspaceID == "" || c.WorkspaceID == req.WorkspaceID) &&
+			(req.CustomVal == "" || c.CustomVal == req.CustomVal) &&
+			(req.DestinationID == "" || c.DestinationID == req.DestinationID) {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered, nil
+}
+
+func (a *Admin) abortJobs(ctx context.Context, req JobsRequest) (int, error) {
+	db, err := a.db(req.JobsDB)
+	if err != nil {
+		return 0, err
+	}
+	return db.AbortJobs(ctx, req.JobsFilter, req.reason(), a.abortHooks[req.JobsDB])
+}
+
+func (a *Admin) failJobs(ctx context.Context, req JobsRequest) (int, error) {
+	db, err := a.db(req.JobsDB)
+	if err != nil {
+		return 0, err
+	}
+	return db.FailJobs(ctx, req.JobsFilter, req.reason())
+}
+
+func (a *Admin) db(jobsDB string) (*Handle, error) {
+	db, ok := a.dbs[jobsDB]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownJobsDB, jobsDB)
+	}
+	return db, nil
+}
+
+func (r JobsRequest) reason() string {
+	if r.Reason == "" {
+		return "updated by admin"
+	}
+	return r.Reason
+}
+
+func formatReply(v any, reply *string) error {
+	formattedOutput, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	*reply = string(formattedOutput)
+	return nil
+}
@@ -0,0 +1,172 @@
+package jobsdb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rudderlabs/rudder-go-kit/config"
+	"github.com/rudderlabs/rudder-go-kit/logger"
+	"github.com/rudderlabs/rudder-go-kit/testhelper/rand"
+)
+
+func TestAdmin(t *testing.T) {
+	_ = startPostgres(t)
+
+	jobDB := Handle{config: config.New()}
+	tablePrefix := strings.ToLower(rand.String(5))
+	require.NoError(t, jobDB.Setup(ReadWrite, true, tablePrefix))
+	defer jobDB.TearDown()
+
+	ctx := context.Background()
+	customVal := "WEBHOOK"
+	jobs := genJobs(defaultWorkspaceID, customVal, 10, 1)
+	for i, job := range jobs {
+		destinationID := "destination-1"
+		if i >= 6 {
+			destinationID = "destination-2"
+		}
+		job.Parameters = []byte(`{"source_id":"source-1","destination_id":"` + destinationID + `"}`)
+	}
+	require.NoError(t, jobDB.Store(ctx, jobs))
+	// destination-1: 2 succeeded, 2 failed & 2 unprocessed jobs, destination-2: 4 unprocessed jobs
+	require.NoError(t, jobDB.UpdateJobStatus(ctx, genJobStatuses(jobs[:2], Succeeded.State), []string{customVal}, nil))
+	require.NoError(t, jobDB.UpdateJobStatus(ctx, genJobStatuses(jobs[2:4], Failed.State), []string{customVal}, nil))
+
+	admin := NewAdmin(logger.NOP, &jobDB)
+	var hookedJobs []int64
+	admin.SetAbortHook(tablePrefix, func(_ context.Context, tx UpdateSafeTx, jobs []*JobT, statuses []*JobStatusT) error {
+		require.NotNil(t, tx.Tx())
+		require.Len(t, statuses, len(jobs))
+		for i := range jobs {
+			require.Equal(t, jobs[i].JobID, statuses[i].JobID)
+			require.Equal(t, Aborted.State, statuses[i].JobState)
+			hookedJobs = append(hookedJobs, jobs[i].JobID)
+		}
+		return nil
+	})
+	srv := httptest.NewServer(admin.HttpHandler())
+	defer srv.Close()
+	get := func(t *testing.T, path string, v any) int {
+		resp, err := http.Get(srv.URL + path)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		if resp.StatusCode == http.StatusOK {
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(v))
+		}
+		return resp.StatusCode
+	}
+	post := func(t *testing.T, path string, req JobsRequest) (int, int) {
+		body, err := json.Marshal(req)
+		require.NoError(t, err)
+		resp, err := http.Post(srv.URL+path, "application/json", bytes.NewReader(body))
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		var res struct {
+			Count int `json:"count"`
+		}
+		if resp.StatusCode == http.StatusOK {
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+		}
+		return resp.StatusCode, res.Count
+	}
+	pendingJobs := func(t *testing.T, states ...string) int {
+		res, err := jobDB.GetJobs(ctx, states, GetQueryParams{CustomValFilters: []string{customVal}, JobsLimit: 100})
+		require.NoError(t, err)
+		return len(res.Jobs)
+	}
+
+	t.Run("datasets", func(t *testing.T) {
+		var datasets []DatasetStats
+		require.Equal(t, http.StatusOK, get(t, "/"+tablePrefix+"/datasets", &datasets))
+		require.Len(t, datasets, 1)
+		require.Equal(t, "1", datasets[0].Index)
+		require.Equal(t, "jsonb", datasets[0].PayloadColumnType)
+		require.EqualValues(t, 10, datasets[0].Jobs)
+		require.EqualValues(t, 8, datasets[0].PendingJobs)
+		require.EqualValues(t, 4, datasets[0].JobStatuses)
+		require.Positive(t, datasets[0].JobsSize)
+		require.Positive(t, datasets[0].JobStatusesSize)
+	})
+
+	t.Run("pile-up", func(t *testing.T) {
+		var pileUp []PileUpCount
+		require.Equal(t, http.StatusOK, get(t, "/"+tablePrefix+"/pileup", &pileUp))
+		require.Equal(t, []PileUpCount{
+			{WorkspaceID: defaultWorkspaceID, CustomVal: customVal, DestinationID: "destination-1", State: Failed.State, Count: 2},
+			{WorkspaceID: defaultWorkspaceID, CustomVal: customVal, DestinationID: "destination-1", State: Unprocessed.State, Count: 2},
+			{WorkspaceID: defaultWorkspaceID, CustomVal: customVal, DestinationID: "destination-2", State: Unprocessed.State, Count: 4},
+		}, pileUp)
+
+		require.Equal(t, http.StatusOK, get(t, "/"+tablePrefix+"/pileup?destinationId=destination-2", &pileUp))
+		require.Len(t, pileUp, 1)
+		require.EqualValues(t, 4, pileUp[0].Count)
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		status, _ := post(t, "/unknown/abort", JobsRequest{JobsFilter: JobsFilter{DestinationID: "destination-1"}})
+		require.Equal(t, http.StatusNotFound, status)
+		status, _ = post(t, "/"+tablePrefix+"/abort", JobsRequest{})
+		require.Equal(t, http.StatusBadRequest, status, "a filter is required")
+		status, _ = post(t, "/"+tablePrefix+"/abort", JobsRequest{JobsFilter: JobsFilter{DestinationID: "destination-1", States: []string{Executing.State}}})
+		require.Equal(t, http.StatusBadRequest, status, "executing jobs cannot be aborted")
+	})
+
+	t.Run("abort", func(t *testing.T) {
+		status, count := post(t, "/"+tablePrefix+"/abort", JobsRequest{JobsFilter: JobsFilter{DestinationID: "destination-2", DryRun: true}})
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, 4, count)
+		require.Equal(t, 6, pendingJobs(t, Unprocessed.State), "dry runs don't update jobs")
+		require.Empty(t, hookedJobs, "dry runs don't call the abort hook")
+
+		status, count = post(t, "/"+tablePrefix+"/abort", JobsRequest{JobsFilter: JobsFilter{DestinationID: "destination-2", Limit: 3}, Reason: "destination removed"})
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, 3, count)
+		require.Equal(t, 3, pendingJobs(t, Unprocessed.State))
+
+		status, count = post(t, "/"+tablePrefix+"/abort", JobsRequest{JobsFilter: JobsFilter{DestinationID: "destination-1", States: []string{Failed.State}}})
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, 2, count)
+		require.Equal(t, 0, pendingJobs(t, Failed.State))
+
+		res, err := jobDB.GetJobs(ctx, []string{Aborted.State}, GetQueryParams{CustomValFilters: []string{customVal}, JobsLimit: 100})
+		require.NoError(t, err)
+		require.Len(t, res.Jobs, 5)
+		require.ElementsMatch(t, lo.Map(res.Jobs, func(job *JobT, _ int) int64 { return job.JobID }), hookedJobs, "the abort hook is called for all aborted jobs")
+		require.JSONEq(t, `{"reason":"destination removed"}`, string(res.Jobs[2].LastJobStatus.ErrorResponse))
+	})
+
+	t.Run("reset to failed", func(t *testing.T) {
+		var reply string
+		require.NoError(t, NewAdmin(logger.NOP, &jobDB).FailJobs(JobsRequest{JobsDB: tablePrefix, JobsFilter: JobsFilter{SourceID: "source-1", States: []string{Aborted.State}}}, &reply))
+		require.Equal(t, "Reset 5 jobs to failed", reply)
+		require.Equal(t, 5, pendingJobs(t, Failed.State))
+		res, err := jobDB.GetJobs(ctx, []string{Failed.State}, GetQueryParams{CustomValFilters: []string{customVal}, JobsLimit: 100})
+		require.NoError(t, err)
+		for _, job := range res.Jobs {
+			require.Zero(t, job.LastJobStatus.AttemptNum)
+		}
+	})
+
+	t.Run("skipping jobs updated after being read", func(t *testing.T) {
+		res, err := jobDB.GetJobs(ctx, []string{Failed.State}, GetQueryParams{CustomValFilters: []string{customVal}, JobsLimit: 100})
+		require.NoError(t, err)
+		require.Len(t, res.Jobs, 5)
+		require.NoError(t, jobDB.UpdateJobStatus(ctx, genJobStatuses(res.Jobs[:2], Executing.State), []string{customVal}, nil))
+
+		jobIDs := func(jobs []*JobT) []int64 { return lo.Map(jobs, func(job *JobT, _ int) int64 { return job.JobID }) }
+		require.NoError(t, jobDB.WithUpdateSafeTx(ctx, func(tx UpdateSafeTx) error {
+			unchanged, err := jobDB.unchangedJobsInTx(ctx, tx, res.Jobs)
+			require.NoError(t, err)
+			require.Equal(t, jobIDs(res.Jobs[2:]), jobIDs(unchanged))
+			return nil
+		}))
+	})
+}
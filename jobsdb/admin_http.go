@@ -0,0 +1,81 @@
+package jobsdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// HttpHandler returns the http handler of the jobsdb admin operations
+//
+//   - GET /{jobsdb}/datasets - lists the datasets of a jobsdb along with their row counts and sizes
+//   - GET /{jobsdb}/pileup - returns the incomplete jobs grouped by workspace, custom value, destination and state, filtered by the workspaceId, customVal & destinationId query parameters
+//   - POST /{jobsdb}/abort - aborts the jobs matching the [JobsFilter] provided in the request body, along with an optional reason
+//   - POST /{jobsdb}/fail - resets the jobs matching the [JobsFilter] provided in the request body to failed, along with an optional reason
+func (a *Admin) HttpHandler() http.Handler {
+	srvMux := chi.NewRouter()
+	srvMux.Get("/{jobsdb}/datasets", a.getDatasets)
+	srvMux.Get("/{jobsdb}/pileup", a.getPileUp)
+	srvMux.Post("/{jobsdb}/abort", a.jobsHandler(a.abortJobs))
+	srvMux.Post("/{jobsdb}/fail", a.jobsHandler(a.failJobs))
+	return srvMux
+}
+
+func (a *Admin) getDatasets(w http.ResponseWriter, r *http.Request) {
+	datasets, err := a.datasets(r.Context(), chi.URLParam(r, "jobsdb"))
+	if err != nil {
+		a.httpError(w, "listing datasets", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(datasets)
+}
+
+func (a *Admin) getPileUp(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	pileUp, err := a.pileUp(r.Context(), PileUpRequest{
+		JobsDB:        chi.URLParam(r, "jobsdb"),
+		WorkspaceID:   query.Get("workspaceId"),
+		CustomVal:     query.Get("customVal"),
+		DestinationID: query.Get("destinationId"),
+	})
+	if err != nil {
+		a.httpError(w, "getting pile-up", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(pileUp)
+}
+
+func (a *Admin) jobsHandler(f func(ctx context.Context, req JobsRequest) (int, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req JobsRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		req.JobsDB = chi.URLParam(r, "jobsdb")
+		count, err := f(r.Context(), req)
+		if err != nil {
+			a.httpError(w, "updating jobs", err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		_ = json.NewEncoder(w).Encode(map[string]any{"count": count, "dryRun": req.DryRun})
+	}
+}
+
+func (a *Admin) httpError(w http.ResponseWriter, action string, err error) {
+	switch {
+	case errors.Is(err, ErrUnknownJobsDB):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, errInvalidJobsFilter):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		a.log.Errorw(action, "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
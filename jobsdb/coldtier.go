@@ -0,0 +1,807 @@
+package jobsdb
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/lib/pq"
+	"github.com/samber/lo"
+	"github.com/tidwall/gjson"
+
+	"github.com/rudderlabs/rudder-go-kit/filemanager"
+	"github.com/rudderlabs/rudder-go-kit/stats"
+	"github.com/rudderlabs/rudder-server/jobsdb/internal/dsindex"
+	"github.com/rudderlabs/rudder-server/jobsdb/internal/lock"
+	"github.com/rudderlabs/rudder-server/utils/crash"
+	"github.com/rudderlabs/rudder-server/utils/misc"
+	. "github.com/rudderlabs/rudder-server/utils/tx" //nolint:staticcheck
+)
+
+// coldDataSetT is a dataset whose pending jobs have been offloaded to object storage.
+//
+// The tables of a cold dataset are dropped, but its index row is kept in the cold datasets table, so that the dataset
+// retains its position in the dataset list. Readers cannot get past a cold dataset which may contain jobs matching their query,
+// instead they request for it to be paged back in, thus jobs are always returned in order.
+type coldDataSetT struct {
+	Index          string
+	ObjectKey      string
+	Jobs           int
+	MinJobID       int64
+	MaxJobID       int64
+	CustomVals     []string
+	WorkspaceIDs   []string
+	SourceIDs      []string
+	DestinationIDs []string
+	PileUp         []PileUpCount // incomplete jobs of the dataset at the time it was offloaded
+	CreatedAt      time.Time
+}
+
+// mayContain returns true if the cold dataset may contain jobs matching the query parameters
+func (cds coldDataSetT) mayContain(params GetQueryParams) bool {
+	if params.afterJobID != nil && *params.afterJobID >= cds.MaxJobID {
+		return false
+	}
+	if params.WorkspaceID != "" && !slices.Contains(cds.WorkspaceIDs, params.WorkspaceID) {
+		return false
+	}
+	if len(params.CustomValFilters) > 0 && !params.IgnoreCustomValFiltersInQuery && !lo.Some(cds.CustomVals, params.CustomValFilters) {
+		return false
+	}
+	for _, filter := range params.ParameterFilters {
+		switch filter.Name {
+		case "source_id":
+			if !slices.Contains(cds.SourceIDs, filter.Value) {
+				return false
+			}
+		case "destination_id":
+			if !slices.Contains(cds.DestinationIDs, filter.Value) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// coldJob is the representation of a job, along with its last status, inside the object storage file of a cold dataset
+type coldJob struct {
+	JobID         int64           `json:"jobId"`
+	WorkspaceID   string          `json:"workspaceId"`
+	UUID          string          `json:"uuid"`
+	UserID        string          `json:"userId"`
+	CustomVal     string          `json:"customVal"`
+	Parameters    json.RawMessage `json:"parameters"`
+	EventPayload  []byte          `json:"eventPayload"`
+	EventCount    int             `json:"eventCount"`
+	CreatedAt     time.Time       `json:"createdAt"`
+	ExpireAt      time.Time       `json:"expireAt"`
+	PartitionID   int             `json:"partitionId"`
+	LastJobStatus *coldJobStatus  `json:"lastJobStatus,omitempty"`
+}
+
+type coldJobStatus struct {
+	JobState      string          `json:"jobState"`
+	AttemptNum    int             `json:"attemptNum"`
+	ExecTime      time.Time       `json:"execTime"`
+	RetryTime     time.Time       `json:"retryTime"`
+	ErrorCode     string          `json:"errorCode"`
+	ErrorResponse json.RawMessage `json:"errorResponse"`
+	Parameters    json.RawMessage `json:"parameters"`
+}
+
+func (jd *Handle) coldDatasetsTable() string {
+	return jd.tablePrefix + "_cold_datasets"
+}
+
+func (jd *Handle) coldJobStatusesTable() string {
+	return jd.tablePrefix + "_cold_job_statuses"
+}
+
+func (jd *Handle) dropColdDatasets() {
+	_, err := jd.dbHandle.Exec(fmt.Sprintf(`DROP TABLE IF EXISTS %q`, jd.coldDatasetsTable()))
+	jd.assertError(err)
+	_, err = jd.dbHandle.Exec(fmt.Sprintf(`DROP TABLE IF EXISTS %q`, jd.coldJobStatusesTable()))
+	jd.assertError(err)
+}
+
+// getColdDSList returns the ordered list of cold datasets
+func (jd *Handle) getColdDSList() []coldDataSetT {
+	jd.coldTier.mu.RLock()
+	defer jd.coldTier.mu.RUnlock()
+	return jd.coldTier.datasets
+}
+
+// doRefreshColdDSList refreshes the cold dataset list from the database
+func (jd *Handle) doRefreshColdDSList(ctx context.Context) error {
+	if !jd.conf.coldTier.enabled {
+		return nil
+	}
+	rows, err := jd.dbHandle.QueryContext(ctx, fmt.Sprintf(
+		`SELECT ds_index, object_key, jobs_count, min_job_id, max_job_id, custom_vals, workspace_ids, source_ids, destination_ids, pile_up, created_at FROM %q`,
+		jd.coldDatasetsTable(),
+	))
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "42P01" {
+			// the table is created by the schema migration, which may not have run yet
+			return nil
+		}
+		return fmt.Errorf("querying cold datasets: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	var coldList []coldDataSetT
+	for rows.Next() {
+		var cds coldDataSetT
+		var pileUp []byte
+		if err := rows.Scan(&cds.Index, &cds.ObjectKey, &cds.Jobs, &cds.MinJobID, &cds.MaxJobID,
+			pq.Array(&cds.CustomVals), pq.Array(&cds.WorkspaceIDs), pq.Array(&cds.SourceIDs), pq.Array(&cds.DestinationIDs), &pileUp, &cds.CreatedAt,
+		); err != nil {
+			return fmt.Errorf("scanning cold dataset: %w", err)
+		}
+		if err := json.Unmarshal(pileUp, &cds.PileUp); err != nil {
+			return fmt.Errorf("unmarshalling pile up of cold dataset %s: %w", cds.Index, err)
+		}
+		coldList = append(coldList, cds)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterating cold datasets: %w", err)
+	}
+	slices.SortFunc(coldList, func(a, b coldDataSetT) int {
+		if dsindex.MustParse(a.Index).Less(dsindex.MustParse(b.Index)) {
+			return -1
+		}
+		return 1
+	})
+	stats.Default.NewTaggedStat("jobsdb_cold_datasets", stats.GaugeType, stats.Tags{"customVal": jd.tablePrefix}).Gauge(len(coldList))
+
+	jd.coldTier.mu.Lock()
+	defer jd.coldTier.mu.Unlock()
+	jd.coldTier.datasets = coldList
+	return nil
+}
+
+// coldDatasetBefore returns the first cold dataset preceding the given dataset which may contain jobs matching the query parameters
+func coldDatasetBefore(coldList []coldDataSetT, ds dataSetT, params GetQueryParams) (coldDataSetT, bool) {
+	if len(coldList) == 0 {
+		return coldDataSetT{}, false
+	}
+	dsIdx := dsindex.MustParse(ds.Index)
+	for _, cds := range coldList {
+		if !dsindex.MustParse(cds.Index).Less(dsIdx) {
+			break
+		}
+		if cds.mayContain(params) {
+			return cds, true
+		}
+	}
+	return coldDataSetT{}, false
+}
+
+// leadingHotDatasets returns the datasets preceding the first cold dataset
+func leadingHotDatasets(dsList []dataSetT, coldList []coldDataSetT) []dataSetT {
+	if len(coldList) == 0 {
+		return dsList
+	}
+	firstColdIdx := dsindex.MustParse(coldList[0].Index)
+	for i, ds := range dsList {
+		if firstColdIdx.Less(dsindex.MustParse(ds.Index)) {
+			return dsList[:i]
+		}
+	}
+	return dsList
+}
+
+// splitColdJobStatuses separates the statuses of jobs belonging to cold datasets, e.g. statuses of jobs which were picked up by a reader right before their dataset was offloaded,
+// from the ones of jobs belonging to hot datasets. Cold statuses are grouped by the index of their cold dataset.
+func (jd *Handle) splitColdJobStatuses(statusList []*JobStatusT) (hot []*JobStatusT, cold map[string][]*JobStatusT) {
+	coldList := jd.getColdDSList()
+	if len(coldList) == 0 {
+		return statusList, nil
+	}
+	hot = make([]*JobStatusT, 0, len(statusList))
+	for _, status := range statusList {
+		cds, ok := lo.Find(coldList, func(cds coldDataSetT) bool {
+			return status.JobID >= cds.MinJobID && status.JobID <= cds.MaxJobID
+		})
+		if !ok {
+			hot = append(hot, status)
+			continue
+		}
+		if cold == nil {
+			cold = make(map[string][]*JobStatusT)
+		}
+		cold[cds.Index] = append(cold[cds.Index], status)
+	}
+	return hot, cold
+}
+
+// storeColdJobStatusesInTx keeps the statuses of jobs belonging to cold datasets aside, until their dataset gets paged in
+func (jd *Handle) storeColdJobStatusesInTx(ctx context.Context, tx *Tx, cold map[string][]*JobStatusT) error {
+	if len(cold) == 0 {
+		return nil
+	}
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn(jd.coldJobStatusesTable(), "ds_index", "job_id", "job_state", "attempt", "exec_time",
+		"retry_time", "error_code", "error_response", "parameters"))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = stmt.Close() }()
+	var count int
+	for dsIndex, statusList := range cold {
+		for _, status := range statusList {
+			if !utf8.ValidString(string(status.ErrorResponse)) {
+				status.ErrorResponse = []byte(`{}`)
+			}
+			if err := status.sanitizeJson(); err != nil {
+				return fmt.Errorf("sanitizing status of job %d: %w", status.JobID, err)
+			}
+			if _, err := stmt.ExecContext(ctx, dsIndex, status.JobID, status.JobState, status.AttemptNum, status.ExecTime,
+				status.RetryTime, status.ErrorCode, string(status.ErrorResponse), string(status.Parameters)); err != nil {
+				return err
+			}
+			count++
+		}
+	}
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		return err
+	}
+	jd.logger.Infow("Stored statuses of jobs belonging to cold datasets", "count", count)
+	return nil
+}
+
+// requestPageIn requests for the cold dataset to be paged back in by the cold tier loop
+func (jd *Handle) requestPageIn(cds coldDataSetT) {
+	jd.coldTier.pageInMu.Lock()
+	jd.coldTier.pageIn[cds.Index] = struct{}{}
+	jd.coldTier.pageInMu.Unlock()
+	select {
+	case jd.coldTier.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// wasPagedIn returns true if the dataset has been paged in by this jobsdb, so that it doesn't get offloaded again
+func (jd *Handle) wasPagedIn(ds dataSetT) bool {
+	jd.coldTier.pageInMu.Lock()
+	defer jd.coldTier.pageInMu.Unlock()
+	_, ok := jd.coldTier.pagedIn[ds.Index]
+	return ok
+}
+
+func (jd *Handle) startColdTierLoop(ctx context.Context) {
+	if !jd.conf.coldTier.enabled {
+		return
+	}
+	jd.backgroundGroup.Go(crash.Wrapper(func() error {
+		jd.coldTierLoop(ctx)
+		return nil
+	}))
+}
+
+// coldTierLoop pages in the cold datasets which readers have reached and offloads datasets with old jobs to object storage
+func (jd *Handle) coldTierLoop(ctx context.Context) {
+	for {
+		select {
+		case <-jd.TriggerColdTier():
+		case <-jd.coldTier.trigger:
+		case <-ctx.Done():
+			return
+		}
+		start := time.Now()
+		timeoutCtx, cancel := context.WithTimeout(ctx, jd.conf.coldTier.timeout.Load())
+		err := jd.doColdTier(timeoutCtx)
+		cancel()
+		stats.Default.NewTaggedStat("jobsdb_cold_tier_loop", stats.TimerType, stats.Tags{"customVal": jd.tablePrefix, "error": fmt.Sprint(err != nil)}).Since(start)
+		if err != nil && ctx.Err() == nil {
+			// object storage errors shouldn't take the server down, datasets will remain hot until the storage becomes available again
+			jd.logger.Errorw("Cold tier maintenance failed", "error", err)
+		}
+	}
+}
+
+func (jd *Handle) doColdTier(ctx context.Context) error {
+	fm, err := jd.coldTier.storage.GetFileManager(ctx, "")
+	if err != nil {
+		return fmt.Errorf("getting file manager: %w", err)
+	}
+	if err := jd.pageInColdDatasets(ctx, fm); err != nil {
+		return fmt.Errorf("paging in cold datasets: %w", err)
+	}
+	if err := jd.offloadDatasets(ctx, fm); err != nil {
+		return fmt.Errorf("offloading datasets: %w", err)
+	}
+	return nil
+}
+
+// pageInColdDatasets pages in the cold datasets that have been requested by readers, oldest first
+func (jd *Handle) pageInColdDatasets(ctx context.Context, fm filemanager.FileManager) error {
+	jd.coldTier.pageInMu.Lock()
+	requested := jd.coldTier.pageIn
+	jd.coldTier.pageIn = make(map[string]struct{})
+	jd.coldTier.pageInMu.Unlock()
+
+	for _, cds := range jd.getColdDSList() {
+		if _, ok := requested[cds.Index]; !ok {
+			continue
+		}
+		if err := jd.pageInDS(ctx, fm, cds); err != nil {
+			// request it again on the next run
+			jd.coldTier.pageInMu.Lock()
+			jd.coldTier.pageIn[cds.Index] = struct{}{}
+			jd.coldTier.pageInMu.Unlock()
+			return fmt.Errorf("paging in %s: %w", cds.Index, err)
+		}
+	}
+	return nil
+}
+
+// offloadDatasets offloads the datasets whose jobs are older than the configured threshold to object storage.
+// The first few datasets are never offloaded, since these are the ones readers are working on, nor the last two ones, which are being written to.
+// Datasets located behind a cold dataset and having no pending jobs are dropped, since the migration loop cannot reach them.
+func (jd *Handle) offloadDatasets(ctx context.Context, fm filemanager.FileManager) error {
+	if !jd.dsListLock.RTryLockWithCtx(ctx) {
+		return fmt.Errorf("could not acquire a dslist read lock: %w", ctx.Err())
+	}
+	dsList := jd.getDSList()
+	jd.dsListLock.RUnlock()
+
+	hotDatasets := jd.conf.coldTier.hotDatasets.Load()
+	if len(dsList) <= hotDatasets+2 {
+		return nil
+	}
+	threshold := time.Now().Add(-jd.conf.coldTier.threshold.Load())
+	var offloaded int
+	for _, ds := range dsList[hotDatasets : len(dsList)-2] {
+		if offloaded >= jd.conf.coldTier.maxOffloadOnce.Load() {
+			return nil
+		}
+		var newestJobAt sql.NullTime
+		if err := jd.dbHandle.QueryRowContext(ctx, fmt.Sprintf(`SELECT created_at FROM %q ORDER BY job_id DESC LIMIT 1`, ds.JobTable)).Scan(&newestJobAt); err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("getting newest job of %s: %w", ds.JobTable, err)
+		}
+		if jd.wasPagedIn(ds) {
+			// readers have reached it, it will be migrated sooner or later
+			continue
+		}
+		_, behindCold := coldDatasetBefore(jd.getColdDSList(), ds, GetQueryParams{})
+		if newestJobAt.Valid && newestJobAt.Time.After(threshold) && !behindCold {
+			// datasets are ordered, following datasets won't be eligible either
+			return nil
+		}
+		ok, err := jd.offloadDS(ctx, fm, ds, newestJobAt.Valid && newestJobAt.Time.Before(threshold))
+		if err != nil {
+			return fmt.Errorf("offloading %s: %w", ds.Index, err)
+		}
+		if ok {
+			offloaded++
+		}
+	}
+	return nil
+}
+
+// offloadDS uploads the pending jobs of a dataset to object storage, records the cold dataset and drops the dataset's tables.
+// If [withPendingJobs] is false the dataset is only dropped if it doesn't have any pending jobs.
+// Datasets having executing jobs are not offloaded, since their statuses are about to be updated.
+//
+// Jobs are dumped and uploaded without holding the migration lock, so that migrations and readers are not blocked while uploading.
+// The migration lock is only acquired for swapping the dataset with its cold counterpart, which is abandoned if the dataset
+// has been dropped or received new statuses in the meantime.
+func (jd *Handle) offloadDS(ctx context.Context, fm filemanager.FileManager, ds dataSetT, withPendingJobs bool) (bool, error) {
+	tmpDir, err := misc.CreateTMPDIR()
+	if err != nil {
+		return false, fmt.Errorf("creating tmp dir: %w", err)
+	}
+	file, err := os.CreateTemp(tmpDir, fmt.Sprintf("%s.*.json.gz", ds.JobTable))
+	if err != nil {
+		return false, fmt.Errorf("creating file: %w", err)
+	}
+	defer func() {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+	}()
+
+	cds, maxStatusID, ok, err := jd.dumpDS(ctx, ds, file)
+	if err != nil || !ok {
+		return false, err
+	}
+	if cds.Jobs > 0 {
+		if !withPendingJobs {
+			return false, nil
+		}
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return false, fmt.Errorf("seeking file: %w", err)
+		}
+		uploaded, err := fm.Upload(ctx, file, jd.conf.coldTier.prefix, jd.tablePrefix)
+		if err != nil {
+			return false, fmt.Errorf("uploading jobs: %w", err)
+		}
+		cds.ObjectKey = uploaded.ObjectName
+	}
+
+	var offloaded bool
+	var l lock.LockToken
+	var lockChan chan<- lock.LockToken
+	err = jd.WithTx(func(tx *Tx) error {
+		return jd.withDistributedSharedLock(ctx, tx, "schema_migrate", func() error { // cannot run while schema migration is running
+			if !jd.dsMigrationLock.TryLockWithCtx(ctx) {
+				return fmt.Errorf("failed to acquire lock: %w", ctx.Err())
+			}
+			defer jd.dsMigrationLock.Unlock()
+
+			var exists bool
+			if err := tx.QueryRowContext(ctx, `SELECT to_regclass($1) IS NOT NULL`, pq.QuoteIdentifier(ds.JobStatusTable)).Scan(&exists); err != nil {
+				return fmt.Errorf("checking if dataset exists: %w", err)
+			}
+			if !exists {
+				jd.logger.Infow("Skipping offloading of dataset which no longer exists", "dataset", ds.Index)
+				return nil
+			}
+			// no more statuses can be added to the dataset until the transaction ends
+			if _, err := tx.ExecContext(ctx, fmt.Sprintf(`LOCK TABLE %q IN EXCLUSIVE MODE`, ds.JobStatusTable)); err != nil {
+				return fmt.Errorf("locking job status table: %w", err)
+			}
+			var currentMaxStatusID int64
+			if err := tx.QueryRowContext(ctx, fmt.Sprintf(`SELECT COALESCE(MAX(id), 0) FROM %q`, ds.JobStatusTable)).Scan(&currentMaxStatusID); err != nil {
+				return fmt.Errorf("getting max job status id: %w", err)
+			}
+			if currentMaxStatusID != maxStatusID {
+				jd.logger.Infow("Skipping offloading of dataset which received new statuses while uploading", "dataset", ds.Index)
+				return nil
+			}
+			if cds.Jobs > 0 {
+				pileUp, err := json.Marshal(cds.PileUp)
+				if err != nil {
+					return fmt.Errorf("marshalling pile up: %w", err)
+				}
+				if _, err := tx.ExecContext(ctx, fmt.Sprintf(
+					`INSERT INTO %q (ds_index, object_key, jobs_count, min_job_id, max_job_id, custom_vals, workspace_ids, source_ids, destination_ids, pile_up) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+					jd.coldDatasetsTable()),
+					cds.Index, cds.ObjectKey, cds.Jobs, cds.MinJobID, cds.MaxJobID,
+					pq.Array(cds.CustomVals), pq.Array(cds.WorkspaceIDs), pq.Array(cds.SourceIDs), pq.Array(cds.DestinationIDs), string(pileUp),
+				); err != nil {
+					return fmt.Errorf("recording cold dataset: %w", err)
+				}
+			}
+			// acquire an async lock, as this needs to be released after the transaction commits
+			l, lockChan, err = jd.dsListLock.AsyncLockWithCtx(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to acquire lock: %w", err)
+			}
+			if err := jd.dropDSInTx(tx, ds); err != nil {
+				return fmt.Errorf("dropping dataset: %w", err)
+			}
+			jd.logger.Infow("Offloaded dataset", "dataset", ds.Index, "jobs", cds.Jobs, "objectKey", cds.ObjectKey)
+			stats.Default.NewTaggedStat("jobsdb_cold_tier_offloaded_jobs", stats.CountType, stats.Tags{"customVal": jd.tablePrefix}).Count(cds.Jobs)
+			offloaded = true
+			return nil
+		})
+	})
+	if l != nil {
+		defer func() { lockChan <- l }()
+		if err == nil {
+			if err = jd.doRefreshDSRangeList(ctx, l); err != nil {
+				return false, fmt.Errorf("refreshing ds range list: %w", err)
+			}
+		}
+	}
+	if !offloaded && cds.ObjectKey != "" {
+		if deleteErr := fm.Delete(ctx, []string{cds.ObjectKey}); deleteErr != nil {
+			jd.logger.Warnw("Failed to delete object of dataset which was not offloaded", "dataset", ds.Index, "objectKey", cds.ObjectKey, "error", deleteErr)
+		}
+	}
+	return offloaded, err
+}
+
+// dumpDS dumps the pending jobs of a dataset into the file within a read-only snapshot and returns the cold dataset describing them,
+// along with the id of the dataset's latest job status at the time of the snapshot.
+// It returns false if the dataset has executing jobs or no longer exists.
+func (jd *Handle) dumpDS(ctx context.Context, ds dataSetT, file *os.File) (cds coldDataSetT, maxStatusID int64, ok bool, err error) {
+	// prevent the dataset from being migrated while dumping it, readers are not blocked
+	if !jd.dsMigrationLock.RTryLockWithCtx(ctx) {
+		return cds, 0, false, fmt.Errorf("could not acquire a migration read lock: %w", ctx.Err())
+	}
+	defer jd.dsMigrationLock.RUnlock()
+
+	sqlTx, err := jd.dbHandle.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return cds, 0, false, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() { _ = sqlTx.Rollback() }()
+	tx := &Tx{Tx: sqlTx}
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx, `SELECT to_regclass($1) IS NOT NULL`, pq.QuoteIdentifier(ds.JobStatusTable)).Scan(&exists); err != nil {
+		return cds, 0, false, fmt.Errorf("checking if dataset exists: %w", err)
+	}
+	if !exists {
+		return cds, 0, false, nil
+	}
+	var executing int
+	if err := tx.QueryRowContext(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM "v_last_%s" WHERE job_state = $1`, ds.JobStatusTable), Executing.State).Scan(&executing); err != nil {
+		return cds, 0, false, fmt.Errorf("counting executing jobs: %w", err)
+	}
+	if executing > 0 {
+		jd.logger.Infow("Skipping offloading of dataset with executing jobs", "dataset", ds.Index, "executing", executing)
+		return cds, 0, false, nil
+	}
+	if err := tx.QueryRowContext(ctx, fmt.Sprintf(`SELECT COALESCE(MAX(id), 0) FROM %q`, ds.JobStatusTable)).Scan(&maxStatusID); err != nil {
+		return cds, 0, false, fmt.Errorf("getting max job status id: %w", err)
+	}
+	if cds, err = jd.dumpDSInTx(ctx, tx, ds, file); err != nil {
+		return cds, 0, false, fmt.Errorf("dumping jobs: %w", err)
+	}
+	return cds, maxStatusID, true, nil
+}
+
+// dumpDSInTx writes the pending jobs of a dataset, along with their last status, into the file as gzipped json lines
+// and returns the cold dataset describing them
+func (jd *Handle) dumpDSInTx(ctx context.Context, tx *Tx, ds dataSetT, file *os.File) (coldDataSetT, error) {
+	cds := coldDataSetT{Index: ds.Index}
+	payloadType, err := jd.payloadColumnTypeOf(ctx, tx, ds)
+	if err != nil {
+		return cds, err
+	}
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(
+		`SELECT j.job_id, j.workspace_id, j.uuid, j.user_id, j.custom_val, j.parameters, j.event_payload, j.event_count, j.created_at, j.expire_at, j.partition_id,
+			js.job_state, js.attempt, js.exec_time, js.retry_time, js.error_code, js.error_response, js.parameters
+		FROM %[1]q j LEFT JOIN "v_last_%[2]s" js ON js.job_id = j.job_id
+		WHERE js.job_id IS NULL OR js.job_state = ANY('{%[3]s}')
+		ORDER BY j.job_id`,
+		ds.JobTable, ds.JobStatusTable, strings.Join(validNonTerminalStates, ","),
+	))
+	if err != nil {
+		return cds, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	gzWriter := gzip.NewWriter(file)
+	bufWriter := bufio.NewWriter(gzWriter)
+	encoder := json.NewEncoder(bufWriter)
+	customVals, workspaceIDs, sourceIDs, destinationIDs := map[string]struct{}{}, map[string]struct{}{}, map[string]struct{}{}, map[string]struct{}{}
+	pileUp := make(map[PileUpCount]int64)
+	for rows.Next() {
+		var job coldJob
+		var payload []byte
+		var jsState, jsErrorCode sql.NullString
+		var jsAttemptNum sql.NullInt64
+		var jsExecTime, jsRetryTime sql.NullTime
+		var jsErrorResponse, jsParameters []byte
+		if err := rows.Scan(&job.JobID, &job.WorkspaceID, &job.UUID, &job.UserID, &job.CustomVal, &job.Parameters, &payload, &job.EventCount, &job.CreatedAt, &job.ExpireAt, &job.PartitionID,
+			&jsState, &jsAttemptNum, &jsExecTime, &jsRetryTime, &jsErrorCode, &jsErrorResponse, &jsParameters,
+		); err != nil {
+			return cds, err
+		}
+		if job.EventPayload, err = decodePayload(payloadType, payload); err != nil {
+			return cds, fmt.Errorf("decoding payload of job %d: %w", job.JobID, err)
+		}
+		if jsState.Valid {
+			job.LastJobStatus = &coldJobStatus{
+				JobState:      jsState.String,
+				AttemptNum:    int(jsAttemptNum.Int64),
+				ExecTime:      jsExecTime.Time,
+				RetryTime:     jsRetryTime.Time,
+				ErrorCode:     jsErrorCode.String,
+				ErrorResponse: jsErrorResponse,
+				Parameters:    jsParameters,
+			}
+		}
+		if err := encoder.Encode(job); err != nil {
+			return cds, fmt.Errorf("writing job %d: %w", job.JobID, err)
+		}
+		if cds.Jobs == 0 {
+			cds.MinJobID = job.JobID
+		}
+		cds.MaxJobID = job.JobID
+		cds.Jobs++
+		customVals[job.CustomVal] = struct{}{}
+		workspaceIDs[job.WorkspaceID] = struct{}{}
+		sourceIDs[gjson.GetBytes(job.Parameters, "source_id").String()] = struct{}{}
+		destinationIDs[gjson.GetBytes(job.Parameters, "destination_id").String()] = struct{}{}
+		state := Unprocessed.State
+		if job.LastJobStatus != nil {
+			state = job.LastJobStatus.JobState
+		}
+		pileUp[PileUpCount{
+			WorkspaceID:   job.WorkspaceID,
+			CustomVal:     job.CustomVal,
+			DestinationID: gjson.GetBytes(job.Parameters, "destination_id").String(),
+			State:         state,
+		}]++
+	}
+	if err := rows.Err(); err != nil {
+		return cds, err
+	}
+	if err := bufWriter.Flush(); err != nil {
+		return cds, err
+	}
+	if err := gzWriter.Close(); err != nil {
+		return cds, err
+	}
+	cds.CustomVals, cds.WorkspaceIDs, cds.SourceIDs, cds.DestinationIDs = lo.Keys(customVals), lo.Keys(workspaceIDs), lo.Keys(sourceIDs), lo.Keys(destinationIDs)
+	for key, count := range pileUp {
+		key.Count = count
+		cds.PileUp = append(cds.PileUp, key)
+	}
+	return cds, nil
+}
+
+// pageInDS downloads the jobs of a cold dataset from object storage and recreates the dataset's tables at its original position
+func (jd *Handle) pageInDS(ctx context.Context, fm filemanager.FileManager, cds coldDataSetT) error {
+	tmpDir, err := misc.CreateTMPDIR()
+	if err != nil {
+		return fmt.Errorf("creating tmp dir: %w", err)
+	}
+	file, err := os.CreateTemp(tmpDir, fmt.Sprintf("%s_cold_%s.*.json.gz", jd.tablePrefix, cds.Index))
+	if err != nil {
+		return fmt.Errorf("creating file: %w", err)
+	}
+	defer func() {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+	}()
+	if err := fm.Download(ctx, file, cds.ObjectKey); err != nil {
+		return fmt.Errorf("downloading %s: %w", cds.ObjectKey, err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("seeking file: %w", err)
+	}
+
+	ds := newDataSet(jd.tablePrefix, cds.Index)
+	var l lock.LockToken
+	var lockChan chan<- lock.LockToken
+	err = jd.WithTx(func(tx *Tx) error {
+		return jd.withDistributedSharedLock(ctx, tx, "schema_migrate", func() error { // cannot run while schema migration is running
+			if !jd.dsMigrationLock.TryLockWithCtx(ctx) {
+				return fmt.Errorf("failed to acquire lock: %w", ctx.Err())
+			}
+			defer jd.dsMigrationLock.Unlock()
+
+			if err := jd.createDSTablesInTx(ctx, tx, ds); err != nil {
+				return fmt.Errorf("creating dataset tables: %w", err)
+			}
+			jobs, err := jd.restoreJobsInTx(ctx, tx, ds, file)
+			if err != nil {
+				return fmt.Errorf("restoring jobs: %w", err)
+			}
+			statuses, err := jd.restoreColdJobStatusesInTx(ctx, tx, ds)
+			if err != nil {
+				return fmt.Errorf("restoring cold job statuses: %w", err)
+			}
+			if err := jd.createDSIndicesInTx(ctx, tx, ds); err != nil {
+				return fmt.Errorf("creating dataset indices: %w", err)
+			}
+			if _, err := tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %q WHERE ds_index = $1`, jd.coldDatasetsTable()), cds.Index); err != nil {
+				return fmt.Errorf("deleting cold dataset: %w", err)
+			}
+			// acquire an async lock, as this needs to be released after the transaction commits
+			l, lockChan, err = jd.dsListLock.AsyncLockWithCtx(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to acquire lock: %w", err)
+			}
+			jd.logger.Infow("Paged in cold dataset", "dataset", cds.Index, "jobs", jobs, "statuses", statuses)
+			stats.Default.NewTaggedStat("jobsdb_cold_tier_paged_in_jobs", stats.CountType, stats.Tags{"customVal": jd.tablePrefix}).Count(jobs)
+			return nil
+		})
+	})
+	if l != nil {
+		defer func() { lockChan <- l }()
+		if err == nil {
+			if err = jd.doRefreshDSRangeList(ctx, l); err != nil {
+				return fmt.Errorf("refreshing ds range list: %w", err)
+			}
+		}
+	}
+	if err != nil {
+		return err
+	}
+	jd.invalidateNoResultsCache(ds.Index, "", nil, nil, nil)
+	jd.coldTier.pageInMu.Lock()
+	jd.coldTier.pagedIn[ds.Index] = struct{}{}
+	jd.coldTier.pageInMu.Unlock()
+	if err := fm.Delete(ctx, []string{cds.ObjectKey}); err != nil {
+		jd.logger.Warnw("Failed to delete object of paged in dataset", "dataset", cds.Index, "objectKey", cds.ObjectKey, "error", err)
+	}
+	return nil
+}
+
+// restoreJobsInTx inserts the jobs read from a cold dataset's file, along with their last status, into the dataset's tables
+func (jd *Handle) restoreJobsInTx(ctx context.Context, tx *Tx, ds dataSetT, file *os.File) (int, error) {
+	payloadType, err := jd.payloadColumnTypeOf(ctx, tx, ds)
+	if err != nil {
+		return 0, err
+	}
+	gzReader, err := gzip.NewReader(file)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = gzReader.Close() }()
+
+	jobsStmt, err := tx.PrepareContext(ctx, pq.CopyIn(ds.JobTable, "job_id", "workspace_id", "uuid", "user_id", "custom_val", "parameters", "event_payload", "event_count", "created_at", "expire_at", "partition_id"))
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = jobsStmt.Close() }()
+	var statuses []coldJob
+	var jobs int
+	decoder := json.NewDecoder(gzReader)
+	for {
+		var job coldJob
+		if err := decoder.Decode(&job); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return 0, fmt.Errorf("reading job: %w", err)
+		}
+		payload, err := encodePayload(payloadType, jd.conf.payloadCompression, job.EventPayload)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := jobsStmt.ExecContext(ctx, job.JobID, job.WorkspaceID, job.UUID, job.UserID, job.CustomVal, string(job.Parameters), payload, job.EventCount, job.CreatedAt, job.ExpireAt, job.PartitionID); err != nil {
+			return 0, err
+		}
+		if job.LastJobStatus != nil {
+			statuses = append(statuses, coldJob{JobID: job.JobID, LastJobStatus: job.LastJobStatus})
+		}
+		jobs++
+	}
+	if _, err := jobsStmt.ExecContext(ctx); err != nil {
+		return 0, err
+	}
+
+	statusStmt, err := tx.PrepareContext(ctx, pq.CopyIn(ds.JobStatusTable, "job_id", "job_state", "attempt", "exec_time", "retry_time", "error_code", "error_response", "parameters"))
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = statusStmt.Close() }()
+	for _, job := range statuses {
+		status := job.LastJobStatus
+		if _, err := statusStmt.ExecContext(ctx, job.JobID, status.JobState, status.AttemptNum, status.ExecTime, status.RetryTime, status.ErrorCode,
+			jsonOrEmpty(status.ErrorResponse), jsonOrEmpty(status.Parameters),
+		); err != nil {
+			return 0, err
+		}
+	}
+	if _, err := statusStmt.ExecContext(ctx); err != nil {
+		return 0, err
+	}
+	return jobs, nil
+}
+
+// restoreColdJobStatusesInTx moves the statuses which were kept aside while the dataset was cold into the dataset's job status table, in the order they were stored
+func (jd *Handle) restoreColdJobStatusesInTx(ctx context.Context, tx *Tx, ds dataSetT) (int64, error) {
+	res, err := tx.ExecContext(ctx, fmt.Sprintf(
+		`INSERT INTO %[1]q (job_id, job_state, attempt, exec_time, retry_time, error_code, error_response, parameters)
+		SELECT s.job_id, s.job_state, s.attempt, s.exec_time, s.retry_time, s.error_code, s.error_response, s.parameters
+		FROM %[2]q s WHERE s.ds_index = $1 AND EXISTS (SELECT 1 FROM %[3]q j WHERE j.job_id = s.job_id)
+		ORDER BY s.id`,
+		ds.JobStatusTable, jd.coldJobStatusesTable(), ds.JobTable,
+	), ds.Index)
+	if err != nil {
+		return 0, err
+	}
+	statuses, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %q WHERE ds_index = $1`, jd.coldJobStatusesTable()), ds.Index); err != nil {
+		return 0, err
+	}
+	return statuses, nil
+}
+
+func jsonOrEmpty(v json.RawMessage) string {
+	if len(v) == 0 || string(v) == "null" {
+		return "{}"
+	}
+	return string(v)
+}
@@ -0,0 +1,165 @@
+package jobsdb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ory/dockertest/v3"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rudderlabs/rudder-go-kit/config"
+	"github.com/rudderlabs/rudder-go-kit/testhelper/docker/resource/minio"
+	"github.com/rudderlabs/rudder-go-kit/testhelper/rand"
+	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
+	"github.com/rudderlabs/rudder-server/services/fileuploader"
+)
+
+func TestColdTier(t *testing.T) {
+	_ = startPostgres(t)
+	pool, err := dockertest.NewPool("")
+	require.NoError(t, err)
+	minioResource, err := minio.Setup(pool, t)
+	require.NoError(t, err)
+
+	c := config.New()
+	c.Set("JobsDB.maxDSSize", 1)
+	c.Set("JobsDB.coldTier.enabled", true)
+	c.Set("JobsDB.coldTier.threshold", "1ns")
+	c.Set("JobsDB.coldTier.hotDatasets", 1)
+	c.Set("JobsDB.coldTier.maxOffloadOnce", 10)
+	triggerAddNewDS := make(chan time.Time)
+	triggerColdTier := make(chan time.Time)
+	jobDB := Handle{
+		config:           c,
+		TriggerAddNewDS:  func() <-chan time.Time { return triggerAddNewDS },
+		TriggerMigrateDS: func() <-chan time.Time { return make(chan time.Time) },
+		TriggerColdTier:  func() <-chan time.Time { return triggerColdTier },
+	}
+	WithColdTierStorage(fileuploader.NewStaticProvider(map[string]fileuploader.StorageSettings{
+		"": {
+			Bucket: backendconfig.StorageBucket{
+				Type: "MINIO",
+				Config: map[string]interface{}{
+					"bucketName":      minioResource.BucketName,
+					"endPoint":        minioResource.Endpoint,
+					"accessKeyID":     minioResource.AccessKeyID,
+					"secretAccessKey": minioResource.AccessKeySecret,
+				},
+			},
+		},
+	}))(&jobDB)
+	tablePrefix := strings.ToLower(rand.String(5))
+	require.NoError(t, jobDB.Setup(ReadWrite, true, tablePrefix))
+	defer jobDB.TearDown()
+
+	ctx := context.Background()
+	customVal := "WEBHOOK"
+	// 4 datasets with 3 jobs each, one destination per dataset, followed by an empty dataset
+	for i := 1; i <= 4; i++ {
+		jobs := genJobs(defaultWorkspaceID, customVal, 3, 1)
+		for _, job := range jobs {
+			job.Parameters = []byte(fmt.Sprintf(`{"source_id":"source-1","destination_id":"destination-%d"}`, i))
+		}
+		require.NoError(t, jobDB.Store(ctx, jobs))
+		triggerAddNewDS <- time.Now()
+		require.Eventually(t, func() bool { return len(jobDB.getDSList()) == i+1 }, 5*time.Second, 10*time.Millisecond)
+	}
+
+	getJobs := func(t *testing.T, states []string, params GetQueryParams) []*JobT {
+		params.CustomValFilters = []string{customVal}
+		params.JobsLimit = 100
+		res, err := jobDB.GetJobs(ctx, states, params)
+		require.NoError(t, err)
+		return res.Jobs
+	}
+	destinationsOf := func(jobs []*JobT) []string {
+		return lo.Uniq(lo.Map(jobs, func(job *JobT, _ int) string { return string(job.Parameters) }))
+	}
+	pending := []string{Unprocessed.State, Failed.State}
+
+	// one of the jobs of destination-2 has failed before being offloaded
+	failed := getJobs(t, []string{Unprocessed.State}, GetQueryParams{ParameterFilters: []ParameterFilterT{{Name: "destination_id", Value: "destination-2"}}})[:1]
+	require.NoError(t, jobDB.UpdateJobStatus(ctx, genJobStatuses(failed, Failed.State), []string{customVal}, nil))
+
+	t.Run("datasets with old jobs are offloaded", func(t *testing.T) {
+		triggerColdTier <- time.Now()
+		require.Eventually(t, func() bool { return len(jobDB.getColdDSList()) == 2 }, 10*time.Second, 10*time.Millisecond)
+
+		coldList := jobDB.getColdDSList()
+		require.Equal(t, []string{"2", "3"}, lo.Map(coldList, func(cds coldDataSetT, _ int) string { return cds.Index }))
+		require.Equal(t, 3, coldList[0].Jobs)
+		require.Equal(t, []string{"destination-2"}, coldList[0].DestinationIDs)
+		require.Equal(t, []string{"1", "4", "5"}, lo.Map(jobDB.getDSList(), func(ds dataSetT, _ int) string { return ds.Index }))
+
+		destinations, err := jobDB.GetDistinctParameterValues(ctx, "destination_id")
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{"destination-1", "destination-2", "destination-3", "destination-4"}, destinations)
+
+		pileUp, err := jobDB.GetPileUp(ctx)
+		require.NoError(t, err)
+		require.Equal(t, []PileUpCount{
+			{WorkspaceID: defaultWorkspaceID, CustomVal: customVal, DestinationID: "destination-1", State: Unprocessed.State, Count: 3},
+			{WorkspaceID: defaultWorkspaceID, CustomVal: customVal, DestinationID: "destination-2", State: Failed.State, Count: 1},
+			{WorkspaceID: defaultWorkspaceID, CustomVal: customVal, DestinationID: "destination-2", State: Unprocessed.State, Count: 2},
+			{WorkspaceID: defaultWorkspaceID, CustomVal: customVal, DestinationID: "destination-3", State: Unprocessed.State, Count: 3},
+			{WorkspaceID: defaultWorkspaceID, CustomVal: customVal, DestinationID: "destination-4", State: Unprocessed.State, Count: 3},
+		}, pileUp, "cold datasets are included in the pile up")
+
+		datasets, err := jobDB.GetDatasetStats(ctx)
+		require.NoError(t, err)
+		require.Equal(t, []string{"1", "2", "3", "4", "5"}, lo.Map(datasets, func(ds DatasetStats, _ int) string { return ds.Index }))
+		require.Equal(t, []bool{false, true, true, false, false}, lo.Map(datasets, func(ds DatasetStats, _ int) bool { return ds.Cold }))
+		require.EqualValues(t, 3, datasets[1].PendingJobs)
+		require.Equal(t, coldList[0].ObjectKey, datasets[1].ObjectKey)
+	})
+
+	t.Run("statuses of jobs in cold datasets are kept until their dataset is paged in", func(t *testing.T) {
+		coldList := jobDB.getColdDSList()
+		require.NoError(t, jobDB.UpdateJobStatus(ctx, []*JobStatusT{{
+			JobID:         coldList[1].MinJobID,
+			JobState:      Failed.State,
+			AttemptNum:    1,
+			ExecTime:      time.Now(),
+			RetryTime:     time.Now(),
+			ErrorCode:     "500",
+			ErrorResponse: []byte(`{}`),
+			Parameters:    []byte(`{}`),
+			WorkspaceId:   defaultWorkspaceID,
+		}}, []string{customVal}, nil))
+		var count int
+		require.NoError(t, jobDB.dbHandle.QueryRow(fmt.Sprintf(`SELECT COUNT(*) FROM %q WHERE ds_index = $1`, jobDB.coldJobStatusesTable()), coldList[1].Index).Scan(&count))
+		require.Equal(t, 1, count)
+	})
+
+	t.Run("readers don't get past cold datasets which may contain their jobs", func(t *testing.T) {
+		jobs := getJobs(t, pending, GetQueryParams{ParameterFilters: []ParameterFilterT{{Name: "destination_id", Value: "destination-4"}}})
+		require.Len(t, jobs, 3, "cold datasets without jobs of destination-4 are skipped")
+		require.Len(t, jobDB.getColdDSList(), 2, "page-ins are only requested for cold datasets which may contain jobs")
+
+		jobs = getJobs(t, pending, GetQueryParams{}) // requests the page-in of the first cold dataset
+		require.Len(t, jobs, 3)
+		require.Equal(t, []string{`{"source_id": "source-1", "destination_id": "destination-1"}`}, destinationsOf(jobs))
+	})
+
+	t.Run("cold datasets are paged in when reached", func(t *testing.T) {
+		destination1 := getJobs(t, pending, GetQueryParams{ParameterFilters: []ParameterFilterT{{Name: "destination_id", Value: "destination-1"}}})
+		require.NoError(t, jobDB.UpdateJobStatus(ctx, genJobStatuses(destination1, Succeeded.State), []string{customVal}, nil))
+		require.Eventually(t, func() bool { return len(jobDB.getColdDSList()) == 1 }, 10*time.Second, 10*time.Millisecond)
+
+		jobs := getJobs(t, pending, GetQueryParams{}) // requests the page-in of the second cold dataset
+		require.Len(t, jobs, 3)
+		require.Equal(t, []string{`{"source_id": "source-1", "destination_id": "destination-2"}`}, destinationsOf(jobs))
+		failedJobs := getJobs(t, []string{Failed.State}, GetQueryParams{})
+		require.Len(t, failedJobs, 1, "last job statuses are restored")
+		require.Equal(t, failed[0].UUID, failedJobs[0].UUID)
+		require.Equal(t, 1, failedJobs[0].LastJobStatus.AttemptNum)
+
+		require.Eventually(t, func() bool { return len(jobDB.getColdDSList()) == 0 }, 10*time.Second, 10*time.Millisecond)
+		require.Len(t, getJobs(t, pending, GetQueryParams{}), 9)
+		require.Len(t, getJobs(t, []string{Failed.State}, GetQueryParams{}), 2, "statuses kept aside while cold are restored")
+	})
+}
@@ -21,6 +21,7 @@ package jobsdb
 
 import (
 	"bytes"
+	"cmp"
 	"context"
 	"crypto/sha256"
 	"database/sql"
@@ -53,6 +54,7 @@ import (
 
 	"github.com/rudderlabs/rudder-go-kit/config"
 	"github.com/rudderlabs/rudder-go-kit/stats"
+	"github.com/rudderlabs/rudder-server/services/fileuploader"
 	"github.com/rudderlabs/rudder-server/services/rmetrics"
 	"github.com/rudderlabs/rudder-server/utils/misc"
 
@@ -89,6 +91,7 @@ type GetQueryParams struct {
 	WorkspaceID                   string
 	CustomValFilters              []string
 	ParameterFilters              []ParameterFilterT
+	PartitionRange                *PartitionRange // if set, only jobs belonging to the partitions of the range are returned
 	stateFilters                  []string
 	afterJobID                    *int64
 
@@ -300,6 +303,9 @@ type JobsDB interface {
 	// GetDistinctParameterValues returns the list of distinct parameter values inside the jobs tables
 	GetDistinctParameterValues(ctx context.Context, parameterName string) (values []string, err error)
 
+	// PartitionRanges returns the ranges of partitions that the jobs of this jobsdb are split into, so that each range can be processed independently
+	PartitionRanges() []PartitionRange
+
 	/* Admin */
 
 	Ping() error
@@ -407,6 +413,7 @@ type JobT struct {
 	LastJobStatus JobStatusT      `json:"LastJobStatus"`
 	Parameters    json.RawMessage `json:"Parameters"`
 	WorkspaceId   string          `json:"WorkspaceId"`
+	PartitionID   int             `json:"PartitionID"`
 }
 
 func (job *JobT) String() string {
@@ -455,6 +462,12 @@ type Handle struct {
 	dsMigrationLock  *lock.Locker
 	noResultsCache   *cache.NoResultsCache[ParameterFilterT]
 
+	// payload column types of the datasets' jobs tables, keyed by table name
+	payloadColumnTypes struct {
+		mu      sync.RWMutex
+		byTable map[string]payloadColumnType
+	}
+
 	// table count stats
 	statTableCount        stats.Measurement
 	statPreDropTableCount stats.Measurement
@@ -481,6 +494,18 @@ type Handle struct {
 	TriggerRefreshDS func() <-chan time.Time
 
 	TriggerJobCleanUp func() <-chan time.Time
+	TriggerColdTier   func() <-chan time.Time
+
+	// coldTier holds the state of datasets offloaded to object storage, see coldtier.go
+	coldTier struct {
+		storage  fileuploader.Provider
+		mu       sync.RWMutex
+		datasets []coldDataSetT // cold datasets ordered by index, guarded by mu
+		pageInMu sync.Mutex
+		pageIn   map[string]struct{} // indices of cold datasets that readers have requested to be paged in, guarded by pageInMu
+		pagedIn  map[string]struct{} // indices of datasets that have been paged in, guarded by pageInMu
+		trigger  chan struct{}
+	}
 
 	lifecycle struct {
 		mu      sync.Mutex
@@ -510,6 +535,11 @@ type Handle struct {
 		maxOpenConnections             int
 		analyzeThreshold               config.ValueLoader[int]
 		MaxDSSize                      config.ValueLoader[int]
+		payloadColumnType              payloadColumnType
+		payloadCompression             payloadCompression
+		partitionKey                   string
+		partitionCount                 int
+		partitionRanges                int
 		migration                      struct {
 			maxMigrateOnce, maxMigrateDSProbe          config.ValueLoader[int]
 			vacuumFullStatusTableThreshold             func() int64
@@ -518,10 +548,18 @@ type Handle struct {
 			jobMinRowsMigrateThres                     func() float64
 			migrateDSLoopSleepDuration                 config.ValueLoader[time.Duration]
 			migrateDSTimeout                           config.ValueLoader[time.Duration]
+			payloadConversionBatchSize                 config.ValueLoader[int]
 		}
 		backup struct {
 			masterBackupEnabled config.ValueLoader[bool]
 		}
+		coldTier struct {
+			enabled                     bool
+			prefix                      string
+			threshold                   config.ValueLoader[time.Duration]
+			hotDatasets, maxOffloadOnce config.ValueLoader[int]
+			loopSleepDuration, timeout  config.ValueLoader[time.Duration]
+		}
 	}
 }
 
@@ -685,6 +723,13 @@ func WithJobMaxAge(maxAgeFunc func() time.Duration) OptsFunc {
 	}
 }
 
+// WithColdTierStorage sets the object storage where datasets with old jobs are offloaded to, if JobsDB.coldTier.enabled is set
+func WithColdTierStorage(provider fileuploader.Provider) OptsFunc {
+	return func(jd *Handle) {
+		jd.coldTier.storage = provider
+	}
+}
+
 func NewForRead(tablePrefix string, opts ...OptsFunc) *Handle {
 	return newOwnerType(Read, tablePrefix, opts...)
 }
@@ -731,6 +776,9 @@ func (jd *Handle) Setup(
 func (jd *Handle) init() {
 	jd.dsListLock = lock.NewLocker()
 	jd.dsMigrationLock = lock.NewLocker()
+	jd.coldTier.pageIn = make(map[string]struct{})
+	jd.coldTier.pagedIn = make(map[string]struct{})
+	jd.coldTier.trigger = make(chan struct{}, 1)
 	if jd.logger == nil {
 		jd.logger = logger.NewLogger().Child("jobsdb").Child(jd.tablePrefix)
 	}
@@ -783,7 +831,7 @@ func (jd *Handle) init() {
 			jd.dsListLock.WithLock(func(l lock.LockToken) {
 				writer := jd.ownerType == Write || jd.ownerType == ReadWrite
 				if writer && jd.conf.clearAll {
-					jd.dropDatabaseTables(l)
+					jd.dropDatabaseTables(context.Background(), l)
 				}
 				templateData := func() map[string]interface{} {
 					// Important: if jobsdb type is acting as a writer then refreshDSList
@@ -818,7 +866,7 @@ func (jd *Handle) init() {
 				jd.runAlwaysChangesets(templateData)
 
 				// finally refresh the dataset list to make sure [datasetList] field is populated
-				err := jd.doRefreshDSRangeList(l)
+				err := jd.doRefreshDSRangeList(context.Background(), l)
 				jd.assertError(err)
 			})
 			return nil
@@ -871,6 +919,34 @@ func (jd *Handle) loadConfig() {
 	maxDSRetentionPeriodKeys := []string{"JobsDB." + jd.tablePrefix + "." + "maxDSRetention", "JobsDB." + "maxDSRetention"}
 	jd.conf.maxDSRetentionPeriod = jd.config.GetReloadableDurationVar(90, time.Minute, maxDSRetentionPeriodKeys...)
 	jd.conf.refreshDSTimeout = jd.config.GetReloadableDurationVar(10, time.Minute, "JobsDB.refreshDS.timeout")
+	// payloadColumnType: The type of the event_payload column of new datasets (jsonb, text or bytea)
+	payloadColumnTypeKeys := []string{"JobsDB." + jd.tablePrefix + "." + "payloadColumnType", "JobsDB." + "payloadColumnType"}
+	jd.conf.payloadColumnType = payloadColumnType(jd.config.GetStringVar(string(payloadColumnTypeJSONB), payloadColumnTypeKeys...))
+	if !jd.conf.payloadColumnType.valid() {
+		panic(fmt.Errorf("[[ %s ]]: invalid payload column type: %q", jd.tablePrefix, jd.conf.payloadColumnType))
+	}
+	// payloadCompression: The compression of payloads written into bytea payload columns (zstd, snappy or none)
+	payloadCompressionKeys := []string{"JobsDB." + jd.tablePrefix + "." + "payloadCompression", "JobsDB." + "payloadCompression"}
+	jd.conf.payloadCompression = payloadCompression(jd.config.GetStringVar(string(payloadCompressionZstd), payloadCompressionKeys...))
+	if _, ok := payloadCompressionHeaders[jd.conf.payloadCompression]; !ok {
+		panic(fmt.Errorf("[[ %s ]]: invalid payload compression: %q", jd.tablePrefix, jd.conf.payloadCompression))
+	}
+	// partitionKey: The key that jobs are partitioned by (user_id, workspace_id or the name of a job parameter, e.g. destination_id)
+	partitionKeyKeys := []string{"JobsDB." + jd.tablePrefix + "." + "partitionKey", "JobsDB." + "partitionKey"}
+	jd.conf.partitionKey = jd.config.GetStringVar("user_id", partitionKeyKeys...)
+	// partitionCount: The number of partitions that jobs are hashed into
+	partitionCountKeys := []string{"JobsDB." + jd.tablePrefix + "." + "partitionCount", "JobsDB." + "partitionCount"}
+	jd.conf.partitionCount = jd.config.GetIntVar(64, 1, partitionCountKeys...)
+	// partitionRanges: The number of ranges that partitions are split into by PartitionRanges, e.g. for partition isolation
+	partitionRangesKeys := []string{"JobsDB." + jd.tablePrefix + "." + "partitionRanges", "JobsDB." + "partitionRanges"}
+	jd.conf.partitionRanges = jd.config.GetIntVar(8, 1, partitionRangesKeys...)
+	if jd.conf.partitionCount <= 0 || jd.conf.partitionRanges <= 0 {
+		panic(fmt.Errorf("[[ %s ]]: invalid partitioning: %d partitions, %d ranges", jd.tablePrefix, jd.conf.partitionCount, jd.conf.partitionRanges))
+	}
+	// gateway jobs aren't assigned to destinations yet, so all of them would end up in the same partition
+	if jd.tablePrefix == "gw" && jd.conf.partitionKey == "destination_id" {
+		panic(fmt.Errorf("[[ %s ]]: invalid partition key: %s", jd.tablePrefix, jd.conf.partitionKey))
+	}
 
 	// migrationConfig
 
@@ -885,6 +961,10 @@ func (jd *Handle) loadConfig() {
 	jd.conf.migration.migrateDSTimeout = jd.config.GetReloadableDurationVar(
 		10, time.Minute, "JobsDB.migrateDS.timeout",
 	)
+	// payloadConversionBatchSize: Number of jobs read at once while migrating jobs between datasets whose payloads need to be converted
+	jd.conf.migration.payloadConversionBatchSize = jd.config.GetReloadableIntVar(
+		10000, 1, "JobsDB.migrateDS.payloadConversionBatchSize",
+	)
 	// jobDoneMigrateThres: A DS is migrated when this fraction of the jobs have been processed
 	jd.conf.migration.jobDoneMigrateThres = func() float64 { return jd.config.GetFloat64("JobsDB.jobDoneMigrateThreshold", 0.7) }
 	// jobStatusMigrateThres: A DS is migrated if the job_status exceeds this (* no_of_jobs)
@@ -908,6 +988,20 @@ func (jd *Handle) loadConfig() {
 		return jd.config.GetInt64("JobsDB.vacuumAnalyzeStatusTableThreshold", 30000)
 	}
 
+	// coldTier: Datasets whose jobs are older than coldTier.threshold are offloaded to object storage and paged back in when readers reach them
+	coldTierEnabledKeys := []string{"JobsDB." + jd.tablePrefix + "." + "coldTier.enabled", "JobsDB." + "coldTier.enabled"}
+	jd.conf.coldTier.enabled = jd.config.GetBoolVar(false, coldTierEnabledKeys...) && jd.coldTier.storage != nil
+	jd.conf.coldTier.prefix = jd.config.GetStringVar("jobsdb-cold-tier", "JobsDB.coldTier.prefix")
+	coldTierThresholdKeys := []string{"JobsDB." + jd.tablePrefix + "." + "coldTier.threshold", "JobsDB." + "coldTier.threshold"}
+	jd.conf.coldTier.threshold = jd.config.GetReloadableDurationVar(24, time.Hour, coldTierThresholdKeys...)
+	// hotDatasets: Number of leading datasets which are never offloaded
+	coldTierHotDatasetsKeys := []string{"JobsDB." + jd.tablePrefix + "." + "coldTier.hotDatasets", "JobsDB." + "coldTier.hotDatasets"}
+	jd.conf.coldTier.hotDatasets = jd.config.GetReloadableIntVar(2, 1, coldTierHotDatasetsKeys...)
+	// maxOffloadOnce: Maximum number of datasets offloaded in a single run of the cold tier loop
+	jd.conf.coldTier.maxOffloadOnce = jd.config.GetReloadableIntVar(1, 1, "JobsDB.coldTier.maxOffloadOnce")
+	jd.conf.coldTier.loopSleepDuration = jd.config.GetReloadableDurationVar(1, time.Minute, "JobsDB.coldTier.loopSleepDuration")
+	jd.conf.coldTier.timeout = jd.config.GetReloadableDurationVar(10, time.Minute, "JobsDB.coldTier.timeout")
+
 	// masterBackupEnabled = true => all the jobsdb are eligible for backup
 	jd.conf.backup.masterBackupEnabled = jd.config.GetReloadableBoolVar(
 		true, "JobsDB.backup.enabled",
@@ -942,6 +1036,12 @@ func (jd *Handle) loadConfig() {
 		}
 	}
 
+	if jd.TriggerColdTier == nil {
+		jd.TriggerColdTier = func() <-chan time.Time {
+			return time.After(jd.conf.coldTier.loopSleepDuration.Load())
+		}
+	}
+
 	if jd.conf.jobMaxAge == nil {
 		jd.conf.jobMaxAge = func() time.Duration {
 			return jd.config.GetDuration("JobsDB.jobMaxAge", 720, time.Hour)
@@ -1045,7 +1145,7 @@ func (jd *Handle) readerSetup(ctx context.Context, l lock.LockToken) {
 	// This is a thread-safe operation.
 	// Even if two different services (gateway and processor) perform this operation, there should not be any problem.
 	jd.recoverFromJournal(ReadWrite)
-	jd.assertError(jd.doRefreshDSRangeList(l))
+	jd.assertError(jd.doRefreshDSRangeList(ctx, l))
 
 	g := jd.backgroundGroup
 	g.Go(crash.Wrapper(func() error {
@@ -1055,6 +1155,7 @@ func (jd *Handle) readerSetup(ctx context.Context, l lock.LockToken) {
 
 	jd.startMigrateDSLoop(ctx)
 	jd.startCleanupLoop(ctx)
+	jd.startColdTierLoop(ctx)
 }
 
 func (jd *Handle) writerSetup(ctx context.Context, l lock.LockToken) {
@@ -1062,11 +1163,11 @@ func (jd *Handle) writerSetup(ctx context.Context, l lock.LockToken) {
 	// This is a thread-safe operation.
 	// Even if two different services (gateway and processor) perform this operation, there should not be any problem.
 	jd.recoverFromJournal(ReadWrite)
-	jd.assertError(jd.doRefreshDSRangeList(l))
+	jd.assertError(jd.doRefreshDSRangeList(ctx, l))
 
 	// If no DS present, add one
 	if len(jd.getDSList()) == 0 {
-		jd.addNewDS(l, newDataSet(jd.tablePrefix, jd.computeNewIdxForAppend(l)))
+		jd.addNewDS(ctx, l, newDataSet(jd.tablePrefix, jd.computeNewIdxForAppend(ctx, l)))
 	}
 
 	jd.backgroundGroup.Go(crash.Wrapper(func() error {
@@ -1082,6 +1183,7 @@ func (jd *Handle) readerWriterSetup(ctx context.Context, l lock.LockToken) {
 
 	jd.startMigrateDSLoop(ctx)
 	jd.startCleanupLoop(ctx)
+	jd.startColdTierLoop(ctx)
 }
 
 // Stop stops the background goroutines and waits until they finish.
@@ -1122,7 +1224,7 @@ func (jd *Handle) getDSList() []dataSetT {
 }
 
 // doRefreshDSList refreshes the ds list from the database
-func (jd *Handle) doRefreshDSList(l lock.LockToken) ([]dataSetT, error) {
+func (jd *Handle) doRefreshDSList(ctx context.Context, l lock.LockToken) ([]dataSetT, error) {
 	if l == nil {
 		return nil, fmt.Errorf("cannot refresh DS list without a valid lock token")
 	}
@@ -1133,6 +1235,9 @@ func (jd *Handle) doRefreshDSList(l lock.LockToken) ([]dataSetT, error) {
 	}
 	// report table count metrics before shrinking the datasetList
 	jd.statTableCount.Gauge(len(jd.datasetList))
+	if err := jd.doRefreshColdDSList(ctx); err != nil {
+		return nil, fmt.Errorf("refreshColdDSList %w", err)
+	}
 
 	// if the owner of this jobsdb is a writer, then shrinking datasetList to have only last two datasets
 	// this shrank datasetList is used to compute DSRangeList
@@ -1151,11 +1256,11 @@ func (jd *Handle) getDSRangeList() []dataSetRangeT {
 }
 
 // doRefreshDSRangeList first refreshes the DS list and then calculate the DS range list
-func (jd *Handle) doRefreshDSRangeList(l lock.LockToken) error {
+func (jd *Handle) doRefreshDSRangeList(ctx context.Context, l lock.LockToken) error {
 	var prevMax int64
 
 	// At this point we must have write-locked dsListLock
-	dsList, err := jd.doRefreshDSList(l)
+	dsList, err := jd.doRefreshDSList(ctx, l)
 	if err != nil {
 		return fmt.Errorf("refreshDSList %w", err)
 	}
@@ -1312,14 +1417,14 @@ func newDataSet(tablePrefix, dsIdx string) dataSetT {
 	}
 }
 
-func (jd *Handle) addNewDS(l lock.LockToken, ds dataSetT) {
+func (jd *Handle) addNewDS(ctx context.Context, l lock.LockToken, ds dataSetT) {
 	err := jd.WithTx(func(tx *Tx) error {
-		dsList, err := jd.doRefreshDSList(l)
+		dsList, err := jd.doRefreshDSList(ctx, l)
 		jd.assertError(err)
 		return jd.addNewDSInTx(tx, l, dsList, ds)
 	})
 	jd.assertError(err)
-	jd.assertError(jd.doRefreshDSRangeList(l))
+	jd.assertError(jd.doRefreshDSRangeList(ctx, l))
 }
 
 // NOTE: If addNewDSInTx is directly called, make sure to explicitly call refreshDSRangeList(l) to update the DS list in cache, once transaction has completed.
@@ -1350,8 +1455,8 @@ func (jd *Handle) addNewDSInTx(tx *Tx, l lock.LockToken, dsList []dataSetT, ds d
 	return nil
 }
 
-func (jd *Handle) computeNewIdxForAppend(l lock.LockToken) string {
-	dList, err := jd.doRefreshDSList(l)
+func (jd *Handle) computeNewIdxForAppend(ctx context.Context, l lock.LockToken) string {
+	dList, err := jd.doRefreshDSList(ctx, l)
 	jd.assertError(err)
 	return jd.doComputeNewIdxForAppend(dList)
 }
@@ -1409,17 +1514,18 @@ func (jd *Handle) createDSInTx(tx *Tx, newDS dataSetT) error {
 }
 
 func (jd *Handle) createDSTablesInTx(ctx context.Context, tx *Tx, newDS dataSetT) error {
-	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE %q (
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE %[1]q (
 		job_id BIGSERIAL PRIMARY KEY,
 		workspace_id TEXT NOT NULL DEFAULT '',
 		uuid UUID NOT NULL,
 		user_id TEXT NOT NULL,
 		parameters JSONB NOT NULL,
 		custom_val VARCHAR(64) NOT NULL,
-		event_payload JSONB NOT NULL,
+		event_payload %[2]s NOT NULL,
 		event_count INTEGER NOT NULL DEFAULT 1,
 		created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
-		expire_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW());`, newDS.JobTable)); err != nil {
+		expire_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+		partition_id INTEGER NOT NULL DEFAULT -1);`, newDS.JobTable, jd.conf.payloadColumnType)); err != nil {
 		return fmt.Errorf("creating %s: %w", newDS.JobTable, err)
 	}
 	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE %q (
@@ -1445,6 +1551,9 @@ func (jd *Handle) createDSIndicesInTx(ctx context.Context, tx *Tx, newDS dataSet
 	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`CREATE INDEX "idx_%[1]s_cv" ON %[1]q (custom_val)`, newDS.JobTable)); err != nil {
 		return fmt.Errorf("creating custom_val index: %w", err)
 	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`CREATE INDEX "idx_%[1]s_partition_id" ON %[1]q (partition_id)`, newDS.JobTable)); err != nil {
+		return fmt.Errorf("creating partition_id index: %w", err)
+	}
 	for _, param := range cacheParameterFilters {
 		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`CREATE INDEX "idx_%[1]s_%[2]s" ON %[1]q USING BTREE ((parameters->>'%[2]s'))`, newDS.JobTable, param)); err != nil {
 			return fmt.Errorf("creating %s index: %w", param, err)
@@ -1571,6 +1680,7 @@ func (jd *Handle) dropDSInTx(tx *Tx, ds dataSetT) error {
 		return err
 	}
 	jd.postDropDs(ds)
+	jd.removePayloadColumnType(ds)
 	return nil
 }
 
@@ -1595,7 +1705,7 @@ func (jd *Handle) dropDSForRecovery(ds dataSetT) {
 }
 
 func (jd *Handle) postDropDs(ds dataSetT) {
-	jd.noResultsCache.InvalidateDataset(ds.Index)
+	jd.invalidateNoResultsCache(ds.Index, "", nil, nil, nil)
 
 	// Tracking time interval between drop ds operations. Hence calling end before start
 	if jd.isStatDropDSPeriodInitialized {
@@ -1605,9 +1715,9 @@ func (jd *Handle) postDropDs(ds dataSetT) {
 	jd.isStatDropDSPeriodInitialized = true
 }
 
-func (jd *Handle) dropAllDS(l lock.LockToken) error {
+func (jd *Handle) dropAllDS(ctx context.Context, l lock.LockToken) error {
 	var err error
-	dList, err := jd.doRefreshDSList(l)
+	dList, err := jd.doRefreshDSList(ctx, l)
 	if err != nil {
 		return fmt.Errorf("refreshDSList: %w", err)
 	}
@@ -1618,7 +1728,7 @@ func (jd *Handle) dropAllDS(l lock.LockToken) error {
 	}
 
 	// Update the lists
-	if err = jd.doRefreshDSRangeList(l); err != nil {
+	if err = jd.doRefreshDSRangeList(ctx, l); err != nil {
 		return fmt.Errorf("refreshDSRangeList: %w", err)
 	}
 	return nil
@@ -1663,7 +1773,7 @@ func (jd *Handle) inStoreSafeCtx(ctx context.Context, f func() error) error {
 		if err != nil && errors.Is(err, errStaleDsList) {
 			jd.logger.Errorf("[JobsDB] :: Store failed: %v. Retrying after refreshing DS cache", errStaleDsList)
 			if err := jd.dsListLock.WithLockInCtx(ctx, func(l lock.LockToken) error {
-				err = jd.doRefreshDSRangeList(l)
+				err = jd.doRefreshDSRangeList(ctx, l)
 				if err != nil {
 					return fmt.Errorf("refreshing ds list: %w", err)
 				}
@@ -1749,7 +1859,7 @@ func (jd *Handle) invalidateCacheForJobs(ds dataSetT, jobList []*JobT) {
 		paramsKey := strings.Join(params, "#")
 		if _, ok := cacheKeys[workspace][customVal][paramsKey]; !ok {
 			cacheKeys[workspace][customVal][paramsKey] = struct{}{}
-			jd.noResultsCache.Invalidate(ds.Index, workspace, []string{customVal}, []string{Unprocessed.State}, parameterFilters)
+			jd.invalidateNoResultsCache(ds.Index, workspace, []string{customVal}, []string{Unprocessed.State}, parameterFilters)
 		}
 	}
 }
@@ -1781,12 +1891,39 @@ func (jd *Handle) GetToProcess(ctx context.Context, params GetQueryParams, more
 var cacheParameterFilters = []string{"source_id", "destination_id"}
 
 func (jd *Handle) GetPileUpCounts(ctx context.Context) error {
+	pileUp, err := jd.GetPileUp(ctx)
+	if err != nil {
+		return err
+	}
+	for _, c := range pileUp {
+		rmetrics.IncreasePendingEvents(
+			jd.tablePrefix,
+			c.WorkspaceID,
+			c.CustomVal,
+			float64(c.Count),
+		)
+	}
+	return nil
+}
+
+// PileUpCount is the number of incomplete jobs of a workspace, custom value and destination in a specific state
+type PileUpCount struct {
+	WorkspaceID   string `json:"workspaceId"`
+	CustomVal     string `json:"customVal"`
+	DestinationID string `json:"destinationId"`
+	State         string `json:"state"`
+	Count         int64  `json:"count"`
+}
+
+// GetPileUp returns the number of incomplete jobs across all datasets, including cold ones,
+// grouped by workspace, custom value, destination and the state of their last status.
+func (jd *Handle) GetPileUp(ctx context.Context) ([]PileUpCount, error) {
 	if !jd.dsMigrationLock.RTryLockWithCtx(ctx) {
-		return fmt.Errorf("could not acquire a migration read lock: %w", ctx.Err())
+		return nil, fmt.Errorf("could not acquire a migration read lock: %w", ctx.Err())
 	}
 	defer jd.dsMigrationLock.RUnlock()
 	if !jd.dsListLock.RTryLockWithCtx(ctx) {
-		return fmt.Errorf("could not acquire a dslist read lock: %w", ctx.Err())
+		return nil, fmt.Errorf("could not acquire a dslist read lock: %w", ctx.Err())
 	}
 	dsList := jd.getDSList()
 	jd.dsListLock.RUnlock()
@@ -1794,7 +1931,9 @@ func (jd *Handle) GetPileUpCounts(ctx context.Context) error {
 	queryString := `with joined as (
 		select
 		  j.custom_val as customVal,
-		  j.workspace_id as workspace
+		  j.workspace_id as workspace,
+		  coalesce(j.parameters->>'destination_id', '') as destination,
+		  coalesce(s.job_state, '` + Unprocessed.State + `') as state
 		from
 		  %[1]q j
 		  left join "v_last_%[2]s" s on j.job_id = s.job_id
@@ -1806,12 +1945,16 @@ func (jd *Handle) GetPileUpCounts(ctx context.Context) error {
 	  select
 		count(*),
 		customVal,
-		workspace
+		workspace,
+		destination,
+		state
 	  from
 		joined
 	  group by
 		customVal,
-		workspace;`
+		workspace,
+		destination,
+		state;`
 
 	g, ctx := errgroup.WithContext(ctx)
 	defaultConcurrency := 10
@@ -1825,6 +1968,8 @@ func (jd *Handle) GetPileUpCounts(ctx context.Context) error {
 		conc = defaultConcurrency
 	}
 	g.SetLimit(conc)
+	var mu sync.Mutex
+	counts := make(map[PileUpCount]int64)
 	for _, ds := range dsList {
 		ds := ds
 		g.Go(func() error {
@@ -1840,19 +1985,15 @@ func (jd *Handle) GetPileUpCounts(ctx context.Context) error {
 			}()
 			for rows.Next() {
 				var count sql.NullInt64
-				var customVal string
-				var workspace string
-				err := rows.Scan(&count, &customVal, &workspace)
+				var key PileUpCount
+				err := rows.Scan(&count, &key.CustomVal, &key.WorkspaceID, &key.DestinationID, &key.State)
 				if err != nil {
 					return fmt.Errorf("rows.Scan(...) on %s: %w", ds.JobTable, err)
 				}
 				if count.Valid {
-					rmetrics.IncreasePendingEvents(
-						jd.tablePrefix,
-						workspace,
-						customVal,
-						float64(count.Int64),
-					)
+					mu.Lock()
+					counts[key] += count.Int64
+					mu.Unlock()
 				}
 			}
 			if err = rows.Err(); err != nil {
@@ -1861,7 +2002,30 @@ func (jd *Handle) GetPileUpCounts(ctx context.Context) error {
 			return nil
 		})
 	}
-	return g.Wait()
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	for _, cds := range jd.getColdDSList() {
+		for _, c := range cds.PileUp {
+			count := c.Count
+			c.Count = 0
+			counts[c] += count
+		}
+	}
+	pileUp := make([]PileUpCount, 0, len(counts))
+	for key, count := range counts {
+		key.Count = count
+		pileUp = append(pileUp, key)
+	}
+	slices.SortFunc(pileUp, func(a, b PileUpCount) int {
+		return cmp.Or(
+			cmp.Compare(a.WorkspaceID, b.WorkspaceID),
+			cmp.Compare(a.CustomVal, b.CustomVal),
+			cmp.Compare(a.DestinationID, b.DestinationID),
+			cmp.Compare(a.State, b.State),
+		)
+	})
+	return pileUp, nil
 }
 
 func (jd *Handle) GetActiveWorkspaces(ctx context.Context, customVal string) ([]string, error) {
@@ -1916,7 +2080,12 @@ func (jd *Handle) GetActiveWorkspaces(ctx context.Context, customVal string) ([]
 	if err = rows.Err(); err != nil {
 		return nil, err
 	}
-	return workspaceIds, nil
+	for _, cds := range jd.getColdDSList() {
+		if customVal == "" || slices.Contains(cds.CustomVals, customVal) {
+			workspaceIds = append(workspaceIds, cds.WorkspaceIDs...)
+		}
+	}
+	return lo.Uniq(workspaceIds), nil
 }
 
 func (jd *Handle) GetDistinctParameterValues(ctx context.Context, parameterName string) ([]string, error) {
@@ -1960,15 +2129,37 @@ func (jd *Handle) GetDistinctParameterValues(ctx context.Context, parameterName
 	if err = rows.Err(); err != nil {
 		return nil, err
 	}
-	return values, nil
+	for _, cds := range jd.getColdDSList() {
+		switch parameterName {
+		case "source_id":
+			values = append(values, cds.SourceIDs...)
+		case "destination_id":
+			values = append(values, cds.DestinationIDs...)
+		}
+	}
+	return lo.Uniq(values), nil
 }
 
 func (jd *Handle) doStoreJobsInTx(ctx context.Context, tx *Tx, ds dataSetT, jobList []*JobT) error {
+	payloadType, err := jd.payloadColumnTypeOf(ctx, tx, ds)
+	if err != nil {
+		return err
+	}
+	if payloadType != payloadColumnTypeJSONB {
+		// postgres doesn't validate payloads which aren't stored as jsonb
+		for i := range jobList {
+			if needsSanitizing(jobList[i].EventPayload) {
+				if err := jobList[i].sanitizeJSON(); err != nil {
+					return fmt.Errorf("sanitizeJSON: %w", err)
+				}
+			}
+		}
+	}
 	store := func() error {
 		var stmt *sql.Stmt
 		var err error
 
-		stmt, err = tx.PrepareContext(ctx, pq.CopyIn(ds.JobTable, "uuid", "user_id", "custom_val", "parameters", "event_payload", "event_count", "workspace_id"))
+		stmt, err = tx.PrepareContext(ctx, pq.CopyIn(ds.JobTable, "uuid", "user_id", "custom_val", "parameters", "event_payload", "event_count", "workspace_id", "partition_id"))
 		if err != nil {
 			return err
 		}
@@ -1980,7 +2171,11 @@ func (jd *Handle) doStoreJobsInTx(ctx context.Context, tx *Tx, ds dataSetT, jobL
 				eventCount = job.EventCount
 			}
 
-			if _, err = stmt.ExecContext(ctx, job.UUID, job.UserID, job.CustomVal, string(job.Parameters), string(job.EventPayload), eventCount, job.WorkspaceId); err != nil {
+			payload, err := encodePayload(payloadType, jd.conf.payloadCompression, job.EventPayload)
+			if err != nil {
+				return err
+			}
+			if _, err = stmt.ExecContext(ctx, job.UUID, job.UserID, job.CustomVal, string(job.Parameters), payload, eventCount, job.WorkspaceId, jd.partitionIDOf(job)); err != nil {
 				return err
 			}
 		}
@@ -2000,7 +2195,7 @@ func (jd *Handle) doStoreJobsInTx(ctx context.Context, tx *Tx, ds dataSetT, jobL
 	if _, err := tx.ExecContext(ctx, savepointSql); err != nil {
 		return err
 	}
-	err := store()
+	err = store()
 
 	var e *pq.Error
 	if err != nil && errors.As(err, &e) {
@@ -2042,7 +2237,13 @@ func (jd *Handle) getJobsDS(ctx context.Context, ds dataSetT, lastDS bool, param
 	workspaceID := params.WorkspaceID
 	checkValidJobState(jd, stateFilters)
 
-	if jd.noResultsCache.Get(ds.Index, workspaceID, customValFilters, stateFilters, parameterFilters) {
+	// results of partition range queries are cached separately, as long as the range is one of the partition ranges of the jobsdb
+	cacheDataset, cacheable := ds.Index, true
+	if params.PartitionRange != nil {
+		cacheDataset, cacheable = jd.partitionRangeCacheDataset(ds.Index, *params.PartitionRange)
+	}
+
+	if jd.noResultsCache.Get(cacheDataset, workspaceID, customValFilters, stateFilters, parameterFilters) {
 		jd.logger.Debugf("[getJobsDS] Empty cache hit for ds: %v, stateFilters: %v, customValFilters: %v, parameterFilters: %v", ds, stateFilters, customValFilters, parameterFilters)
 		return JobsResult{}, false, nil
 	}
@@ -2055,13 +2256,13 @@ func (jd *Handle) getJobsDS(ctx context.Context, ds dataSetT, lastDS bool, param
 	}
 
 	stateFilters = lo.Filter(stateFilters, func(state string, _ int) bool { // exclude states for which we already know that there are no jobs
-		return !jd.noResultsCache.Get(ds.Index, workspaceID, customValFilters, []string{state}, parameterFilters)
+		return !jd.noResultsCache.Get(cacheDataset, workspaceID, customValFilters, []string{state}, parameterFilters)
 	})
 
 	defer jd.getTimerStat("jobsdb_get_jobs_ds_time", &tags).RecordDuration()()
 
 	containsUnprocessed := lo.Contains(stateFilters, Unprocessed.State)
-	skipCacheResult := params.afterJobID != nil
+	skipCacheResult := params.afterJobID != nil || !cacheable
 	cacheTx := map[string]*cache.NoResultTx[ParameterFilterT]{}
 	if !skipCacheResult {
 		for _, state := range stateFilters {
@@ -2072,7 +2273,7 @@ func (jd *Handle) getJobsDS(ctx context.Context, ds dataSetT, lastDS bool, param
 			if state == Unprocessed.State && jd.ownerType == Read && lastDS {
 				continue
 			}
-			cacheTx[state] = jd.noResultsCache.StartNoResultTx(ds.Index, workspaceID, customValFilters, []string{state}, parameterFilters)
+			cacheTx[state] = jd.noResultsCache.StartNoResultTx(cacheDataset, workspaceID, customValFilters, []string{state}, parameterFilters)
 		}
 	}
 
@@ -2101,6 +2302,10 @@ func (jd *Handle) getJobsDS(ctx context.Context, ds dataSetT, lastDS bool, param
 		filterConditions = append(filterConditions, fmt.Sprintf("jobs.workspace_id = '%s'", workspaceID))
 	}
 
+	if params.PartitionRange != nil {
+		filterConditions = append(filterConditions, jd.partitionRangeConditions("jobs", *params.PartitionRange)...)
+	}
+
 	var filterQuery string
 	if len(filterConditions) > 0 {
 		filterQuery = "WHERE " + strings.Join(filterConditions, " AND ")
@@ -2124,7 +2329,7 @@ func (jd *Handle) getJobsDS(ctx context.Context, ds dataSetT, lastDS bool, param
 	var rows *sql.Rows
 	sqlStatement := fmt.Sprintf(`SELECT
 									jobs.job_id, jobs.uuid, jobs.user_id, jobs.parameters, jobs.custom_val, jobs.event_payload, jobs.event_count,
-									jobs.created_at, jobs.expire_at, jobs.workspace_id,
+									jobs.created_at, jobs.expire_at, jobs.workspace_id, jobs.partition_id,
 									pg_column_size(jobs.event_payload) as payload_size,
 									sum(jobs.event_count) over (order by jobs.job_id asc) as running_event_counts,
 									sum(pg_column_size(jobs.event_payload)) over (order by jobs.job_id) as running_payload_size,
@@ -2160,6 +2365,10 @@ func (jd *Handle) getJobsDS(ctx context.Context, ds dataSetT, lastDS bool, param
 		sqlStatement = `SELECT * FROM (` + sqlStatement + `) t WHERE ` + strings.Join(wrapQuery, " AND ")
 	}
 
+	payloadType, err := jd.payloadColumnTypeOf(ctx, jd.dbHandle, ds)
+	if err != nil {
+		return JobsResult{}, false, err
+	}
 	stmt, err := jd.dbHandle.PrepareContext(ctx, sqlStatement)
 	if err != nil {
 		return JobsResult{}, false, err
@@ -2189,13 +2398,16 @@ func (jd *Handle) getJobsDS(ctx context.Context, ds dataSetT, lastDS bool, param
 		var jsErrorResponse []byte
 		var jsParameters []byte
 		err := rows.Scan(&job.JobID, &job.UUID, &job.UserID, &job.Parameters, &job.CustomVal,
-			&job.EventPayload, &job.EventCount, &job.CreatedAt, &job.ExpireAt, &job.WorkspaceId, &job.PayloadSize, &runningEventCount, &runningPayloadSize,
+			&job.EventPayload, &job.EventCount, &job.CreatedAt, &job.ExpireAt, &job.WorkspaceId, &job.PartitionID, &job.PayloadSize, &runningEventCount, &runningPayloadSize,
 			&jsState, &jsAttemptNum,
 			&jsExecTime, &jsRetryTime,
 			&jsErrorCode, &jsErrorResponse, &jsParameters)
 		if err != nil {
 			return JobsResult{}, false, err
 		}
+		if job.EventPayload, err = decodePayload(payloadType, job.EventPayload); err != nil {
+			return JobsResult{}, false, fmt.Errorf("decoding payload of job %d: %w", job.JobID, err)
+		}
 		if jsState.Valid {
 			resultsetStates[jsState.String] = struct{}{}
 			job.LastJobStatus.JobState = jsState.String
@@ -2429,7 +2641,7 @@ func (jd *Handle) addNewDSLoop(ctx context.Context) {
 			}
 			// to get the updated DS list in the cache after createDS transaction has been committed.
 			if dsListLock != nil {
-				if err = jd.doRefreshDSRangeList(dsListLock); err != nil {
+				if err = jd.doRefreshDSRangeList(ctx, dsListLock); err != nil {
 					return fmt.Errorf("refreshDSRangeList: %w", err)
 				}
 			}
@@ -2505,7 +2717,7 @@ func (jd *Handle) refreshDSList(ctx context.Context) error {
 	}
 	defer stats.Default.NewTaggedStat("refresh_ds_loop_lock", stats.TimerType, stats.Tags{"customVal": jd.tablePrefix}).RecordDuration()()
 	err = jd.dsListLock.WithLockInCtx(ctx, func(l lock.LockToken) error {
-		return jd.doRefreshDSRangeList(l)
+		return jd.doRefreshDSRangeList(ctx, l)
 	})
 	if err != nil {
 		return fmt.Errorf("refreshDSRangeList: %w", err)
@@ -2746,19 +2958,19 @@ func (jd *Handle) internalUpdateJobStatusInTx(ctx context.Context, tx *Tx, dsLis
 		// clear cache
 		for ds, dsKeys := range updatedStatesByDS {
 			if len(dsKeys) == 0 { // if no keys, we need to invalidate all keys
-				jd.noResultsCache.Invalidate(ds.Index, "", nil, nil, nil)
+				jd.invalidateNoResultsCache(ds.Index, "", nil, nil, nil)
 			}
 			for workspace, wsKeys := range dsKeys {
 				if len(wsKeys) == 0 { // if no keys, we need to invalidate all keys
-					jd.noResultsCache.Invalidate(ds.Index, workspace, nil, nil, nil)
+					jd.invalidateNoResultsCache(ds.Index, workspace, nil, nil, nil)
 				}
 				for state, parametersMap := range wsKeys {
 					stateList := []string{state}
 					if len(parametersMap) == 0 { // if no keys, we need to invalidate all keys
-						jd.noResultsCache.Invalidate(ds.Index, workspace, customValFilters, stateList, nil)
+						jd.invalidateNoResultsCache(ds.Index, workspace, customValFilters, stateList, nil)
 					}
 					parameterFilters := lo.Keys(parametersMap)
-					jd.noResultsCache.Invalidate(ds.Index, workspace, customValFilters, stateList, parameterFilters)
+					jd.invalidateNoResultsCache(ds.Index, workspace, customValFilters, stateList, parameterFilters)
 				}
 			}
 		}
@@ -2773,6 +2985,11 @@ customValFilters[] is passed, so we can efficiently mark empty cache
 Later we can move this to query
 */
 func (jd *Handle) doUpdateJobStatusInTx(ctx context.Context, tx *Tx, dsList []dataSetT, dsRangeList []dataSetRangeT, statusList []*JobStatusT, tags statTags) (updatedStatesByDS map[dataSetT]map[string]map[string]map[ParameterFilterT]struct{}, err error) {
+	statusList, coldStatuses := jd.splitColdJobStatuses(statusList)
+	if err = jd.storeColdJobStatusesInTx(ctx, tx, coldStatuses); err != nil {
+		err = fmt.Errorf("storing cold job statuses: %w", err)
+		return
+	}
 	if len(statusList) == 0 {
 		return
 	}
@@ -3082,6 +3299,7 @@ func (jd *Handle) getJobs(ctx context.Context, params GetQueryParams, more MoreT
 	dsRangeList := jd.getDSRangeList()
 	dsList := jd.getDSList()
 	jd.dsListLock.RUnlock()
+	coldList := jd.getColdDSList()
 
 	limitByEventCount := false
 	if params.EventsLimit > 0 {
@@ -3114,6 +3332,11 @@ func (jd *Handle) getJobs(ctx context.Context, params GetQueryParams, more MoreT
 		if dsLimit > 0 && dsQueryCount >= dsLimit {
 			break
 		}
+		if cds, ok := coldDatasetBefore(coldList, ds, params); ok {
+			// jobs of a cold dataset need to be paged in before any following jobs can be returned
+			jd.requestPageIn(cds)
+			break
+		}
 		jobs, dsHit, err := jd.getJobsDS(ctx, ds, len(dsList)-1 == idx, params)
 		if err != nil {
 			return nil, err
@@ -3236,6 +3459,12 @@ func (jd *Handle) GetLastJob(ctx context.Context) *JobT {
 	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		jd.assertError(err)
 	}
+	if err == nil {
+		payloadType, err := jd.payloadColumnTypeOf(ctx, jd.dbHandle, dsList[len(dsList)-1])
+		jd.assertError(err)
+		job.EventPayload, err = decodePayload(payloadType, job.EventPayload)
+		jd.assertError(err)
+	}
 	return &job
 }
 
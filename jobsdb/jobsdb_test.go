@@ -369,7 +369,7 @@ func TestRefreshDSList(t *testing.T) {
 	}))
 	require.Equal(t, 1, len(jobsDB.getDSList()), "addDS should not refresh the ds list")
 	jobsDB.dsListLock.WithLock(func(l lock.LockToken) {
-		dsList, err := jobsDB.doRefreshDSList(l)
+		dsList, err := jobsDB.doRefreshDSList(context.Background(), l)
 		require.NoError(t, err)
 		require.Equal(t, 2, len(dsList), "after refreshing the ds list jobsDB should have a ds list size of 2")
 	})
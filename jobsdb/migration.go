@@ -69,6 +69,8 @@ func (jd *Handle) doMigrateDS(ctx context.Context) error {
 		return err
 	}
 
+	// datasets behind a cold dataset cannot be migrated, since migrated jobs would end up ahead of the cold dataset's jobs
+	dsList = leadingHotDatasets(dsList, jd.getColdDSList())
 	migrateFrom, pendingJobsCount, insertBeforeDS, err := jd.getMigrationList(dsList)
 	if err != nil {
 		return fmt.Errorf("could not get migration list: %w", err)
@@ -100,7 +102,7 @@ func (jd *Handle) doMigrateDS(ctx context.Context) error {
 			if pendingJobsCount > 0 { // migrate incomplete jobs
 				var destination dataSetT
 				if err := jd.dsListLock.WithLockInCtx(ctx, func(l lock.LockToken) error {
-					dsIdx, err := jd.computeNewIdxForIntraNodeMigration(l, insertBeforeDS)
+					dsIdx, err := jd.computeNewIdxForIntraNodeMigration(ctx, l, insertBeforeDS)
 					if err != nil {
 						return fmt.Errorf("computing new index for intra-node migration: %w", err)
 					}
@@ -173,7 +175,7 @@ func (jd *Handle) doMigrateDS(ctx context.Context) error {
 		defer stats.Default.NewTaggedStat("migration_loop_lock", stats.TimerType, stats.Tags{"customVal": jd.tablePrefix}).Since(lockStart)
 		defer func() { lockChan <- l }()
 		if err == nil {
-			if err = jd.doRefreshDSRangeList(l); err != nil {
+			if err = jd.doRefreshDSRangeList(ctx, l); err != nil {
 				return fmt.Errorf("failed to refresh ds range list: %w", err)
 			}
 		}
@@ -431,12 +433,44 @@ func (jd *Handle) migrateJobsInTx(ctx context.Context, tx *Tx, srcDS, destDS dat
 		&statTags{CustomValFilters: []string{jd.tablePrefix}},
 	).RecordDuration()()
 
+	srcPayloadType, err := jd.payloadColumnTypeOf(ctx, tx, srcDS)
+	if err != nil {
+		return 0, err
+	}
+	destPayloadType, err := jd.payloadColumnTypeOf(ctx, tx, destDS)
+	if err != nil {
+		return 0, err
+	}
+
+	var numJobsMigrated int
+	if srcPayloadType != destPayloadType && (srcPayloadType == payloadColumnTypeBytea || destPayloadType == payloadColumnTypeBytea) {
+		// compressed payloads can only be converted by us, not by postgres
+		numJobsMigrated, err = jd.migrateJobsWithPayloadConversionInTx(ctx, tx, srcDS, destDS, srcPayloadType, destPayloadType)
+	} else {
+		numJobsMigrated, err = jd.copyJobsInTx(ctx, tx, srcDS, destDS, srcPayloadType, destPayloadType)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(fmt.Sprintf(`ANALYZE %q, %q`, destDS.JobTable, destDS.JobStatusTable)); err != nil {
+		return 0, err
+	}
+	return numJobsMigrated, nil
+}
+
+// copyJobsInTx copies all non-terminal jobs of the source dataset, along with their last status, into the destination dataset
+// using a single statement, casting payloads between jsonb and text if the datasets' payload column types differ.
+func (jd *Handle) copyJobsInTx(ctx context.Context, tx *Tx, srcDS, destDS dataSetT, srcPayloadType, destPayloadType payloadColumnType) (int, error) {
+	payloadColumn := "j.event_payload"
+	if srcPayloadType != destPayloadType {
+		payloadColumn = fmt.Sprintf("j.event_payload::%s", destPayloadType)
+	}
 	compactDSQuery := fmt.Sprintf(
 		`with last_status as (select * from "v_last_%[1]s"),
 		inserted_jobs as
 		(
-			insert into %[3]q (job_id,   workspace_id,   uuid,   user_id,   custom_val,   parameters,   event_payload,   event_count,   created_at,   expire_at)
-			           (select j.job_id, j.workspace_id, j.uuid, j.user_id, j.custom_val, j.parameters, j.event_payload, j.event_count, j.created_at, j.expire_at from %[2]q j left join last_status js on js.job_id = j.job_id
+			insert into %[3]q (job_id,   workspace_id,   uuid,   user_id,   custom_val,   parameters,   event_payload,   event_count,   created_at,   expire_at,   partition_id)
+			           (select j.job_id, j.workspace_id, j.uuid, j.user_id, j.custom_val, j.parameters, %[6]s, j.event_count, j.created_at, j.expire_at, j.partition_id from %[2]q j left join last_status js on js.job_id = j.job_id
 				where js.job_id is null or js.job_state = ANY('{%[5]s}') order by j.job_id) returning job_id
 		),
 		insertedStatuses as
@@ -450,6 +484,7 @@ func (jd *Handle) migrateJobsInTx(ctx context.Context, tx *Tx, srcDS, destDS dat
 		destDS.JobTable,
 		destDS.JobStatusTable,
 		strings.Join(validNonTerminalStates, ","),
+		payloadColumn,
 	)
 
 	var numJobsMigrated int64
@@ -459,15 +494,96 @@ func (jd *Handle) migrateJobsInTx(ctx context.Context, tx *Tx, srcDS, destDS dat
 	).Scan(&numJobsMigrated); err != nil {
 		return 0, err
 	}
-	if _, err := tx.Exec(fmt.Sprintf(`ANALYZE %q, %q`, destDS.JobTable, destDS.JobStatusTable)); err != nil {
-		return 0, err
-	}
 	return int(numJobsMigrated), nil
 }
 
-func (jd *Handle) computeNewIdxForIntraNodeMigration(l lock.LockToken, insertBeforeDS dataSetT) (string, error) { // Within the node
+// migrateJobsWithPayloadConversionInTx migrates all non-terminal jobs of the source dataset, along with their last status,
+// into the destination dataset by reading them in batches, decoding their payloads and encoding them again for the destination dataset.
+func (jd *Handle) migrateJobsWithPayloadConversionInTx(ctx context.Context, tx *Tx, srcDS, destDS dataSetT, srcPayloadType, destPayloadType payloadColumnType) (int, error) {
+	batchSize := jd.conf.migration.payloadConversionBatchSize.Load()
+	selectJobsQuery := fmt.Sprintf(
+		`select j.job_id, j.workspace_id, j.uuid, j.user_id, j.custom_val, j.parameters, j.event_payload, j.event_count, j.created_at, j.expire_at, j.partition_id
+		from %[1]q j left join "v_last_%[2]s" js on js.job_id = j.job_id
+		where (js.job_id is null or js.job_state = ANY('{%[3]s}')) and j.job_id > $1
+		order by j.job_id limit $2`,
+		srcDS.JobTable,
+		srcDS.JobStatusTable,
+		strings.Join(validNonTerminalStates, ","),
+	)
+	selectJobs := func(afterJobID int64) ([]*JobT, error) {
+		rows, err := tx.QueryContext(ctx, selectJobsQuery, afterJobID, batchSize)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = rows.Close() }()
+		var jobs []*JobT
+		for rows.Next() {
+			var job JobT
+			if err := rows.Scan(&job.JobID, &job.WorkspaceId, &job.UUID, &job.UserID, &job.CustomVal, &job.Parameters,
+				&job.EventPayload, &job.EventCount, &job.CreatedAt, &job.ExpireAt, &job.PartitionID); err != nil {
+				return nil, err
+			}
+			if job.EventPayload, err = decodePayload(srcPayloadType, job.EventPayload); err != nil {
+				return nil, fmt.Errorf("decoding payload of job %d: %w", job.JobID, err)
+			}
+			jobs = append(jobs, &job)
+		}
+		return jobs, rows.Err()
+	}
+	insertJobs := func(jobs []*JobT) error {
+		stmt, err := tx.PrepareContext(ctx, pq.CopyIn(destDS.JobTable, "job_id", "workspace_id", "uuid", "user_id", "custom_val", "parameters", "event_payload", "event_count", "created_at", "expire_at", "partition_id"))
+		if err != nil {
+			return err
+		}
+		defer func() { _ = stmt.Close() }()
+		for _, job := range jobs {
+			payload, err := encodePayload(destPayloadType, jd.conf.payloadCompression, job.EventPayload)
+			if err != nil {
+				return err
+			}
+			if _, err := stmt.ExecContext(ctx, job.JobID, job.WorkspaceId, job.UUID, job.UserID, job.CustomVal, string(job.Parameters), payload, job.EventCount, job.CreatedAt, job.ExpireAt, job.PartitionID); err != nil {
+				return err
+			}
+		}
+		_, err = stmt.ExecContext(ctx)
+		return err
+	}
+
+	var numJobsMigrated int
+	var lastJobID int64
+	for {
+		jobs, err := selectJobs(lastJobID)
+		if err != nil {
+			return 0, fmt.Errorf("selecting jobs from %q: %w", srcDS.JobTable, err)
+		}
+		if len(jobs) == 0 {
+			break
+		}
+		if err := insertJobs(jobs); err != nil {
+			return 0, fmt.Errorf("inserting jobs into %q: %w", destDS.JobTable, err)
+		}
+		numJobsMigrated += len(jobs)
+		lastJobID = jobs[len(jobs)-1].JobID
+		if len(jobs) < batchSize {
+			break
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(
+		`insert into %[2]q (job_id, job_state, attempt, exec_time, retry_time, error_code, error_response, parameters)
+		(select job_id, job_state, attempt, exec_time, retry_time, error_code, error_response, parameters from "v_last_%[1]s" where job_state = ANY('{%[3]s}'))`,
+		srcDS.JobStatusTable,
+		destDS.JobStatusTable,
+		strings.Join(validNonTerminalStates, ","),
+	)); err != nil {
+		return 0, fmt.Errorf("inserting job statuses into %q: %w", destDS.JobStatusTable, err)
+	}
+	return numJobsMigrated, nil
+}
+
+func (jd *Handle) computeNewIdxForIntraNodeMigration(ctx context.Context, l lock.LockToken, insertBeforeDS dataSetT) (string, error) { // Within the node
 	jd.logger.Debugf("computeNewIdxForIntraNodeMigration, insertBeforeDS : %v", insertBeforeDS)
-	dList, err := jd.doRefreshDSList(l)
+	dList, err := jd.doRefreshDSList(ctx, l)
 	if err != nil {
 		return "", fmt.Errorf("refreshDSList: %w", err)
 	}
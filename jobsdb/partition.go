@@ -0,0 +1,109 @@
+package jobsdb
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spaolacci/murmur3"
+	"github.com/tidwall/gjson"
+)
+
+// PartitionRange is a range of partition ids, from Start (inclusive) to End (exclusive).
+//
+// Every job is assigned to a partition when it is stored, by hashing its partition key (see JobsDB.partitionKey) into one of JobsDB.partitionCount partitions.
+// Jobs stored before partitioning was introduced belong to partition -1, whereas jobs stored while a higher partition count was configured
+// can belong to partitions greater than the current count. Both of them are returned by the first and last range respectively.
+type PartitionRange struct {
+	Start int
+	End   int
+}
+
+// String returns the range in the form of start-end, e.g. 0-8
+func (r PartitionRange) String() string {
+	return strconv.Itoa(r.Start) + "-" + strconv.Itoa(r.End)
+}
+
+// ParsePartitionRange parses a partition range in the form of start-end, e.g. 0-8
+func ParsePartitionRange(s string) (PartitionRange, error) {
+	start, end, ok := strings.Cut(s, "-")
+	if !ok {
+		return PartitionRange{}, fmt.Errorf("invalid partition range: %q", s)
+	}
+	var r PartitionRange
+	var err error
+	if r.Start, err = strconv.Atoi(start); err != nil {
+		return PartitionRange{}, fmt.Errorf("invalid partition range start: %q: %w", s, err)
+	}
+	if r.End, err = strconv.Atoi(end); err != nil {
+		return PartitionRange{}, fmt.Errorf("invalid partition range end: %q: %w", s, err)
+	}
+	if r.Start < 0 || r.Start >= r.End {
+		return PartitionRange{}, fmt.Errorf("invalid partition range: %q", s)
+	}
+	return r, nil
+}
+
+// PartitionRanges splits the partitions of the jobsdb into JobsDB.partitionRanges contiguous ranges of (almost) equal size
+func (jd *Handle) PartitionRanges() []PartitionRange {
+	return partitionRanges(jd.conf.partitionCount, jd.conf.partitionRanges)
+}
+
+func partitionRanges(partitionCount, rangeCount int) []PartitionRange {
+	rangeCount = min(rangeCount, partitionCount)
+	ranges := make([]PartitionRange, 0, rangeCount)
+	var start int
+	for i := 0; i < rangeCount; i++ {
+		end := start + partitionCount/rangeCount
+		if i < partitionCount%rangeCount {
+			end++
+		}
+		ranges = append(ranges, PartitionRange{Start: start, End: end})
+		start = end
+	}
+	return ranges
+}
+
+// partitionIDOf returns the partition that the job belongs to, according to the configured partition key
+func (jd *Handle) partitionIDOf(job *JobT) int {
+	var key string
+	switch jd.conf.partitionKey {
+	case "user_id":
+		key = job.UserID
+	case "workspace_id":
+		key = job.WorkspaceId
+	default:
+		key = gjson.GetBytes(job.Parameters, jd.conf.partitionKey).String()
+	}
+	return int(murmur3.Sum32([]byte(key)) % uint32(jd.conf.partitionCount))
+}
+
+// partitionRangeConditions returns the sql conditions for selecting the jobs of a partition range.
+// The lower bound of the first range and the upper bound of the last range are left open,
+// so that jobs without a partition or belonging to partitions outside the configured count are not left behind.
+func (jd *Handle) partitionRangeConditions(table string, r PartitionRange) []string {
+	var conditions []string
+	if r.Start > 0 {
+		conditions = append(conditions, fmt.Sprintf("%s.partition_id >= %d", table, r.Start))
+	}
+	if r.End < jd.conf.partitionCount {
+		conditions = append(conditions, fmt.Sprintf("%s.partition_id < %d", table, r.End))
+	}
+	return conditions
+}
+
+// partitionRangeCacheDataset returns the key of the dataset in the no results cache for queries of the partition range,
+// along with false if the range isn't one of the partition ranges of the jobsdb, in which case its results cannot be cached.
+func (jd *Handle) partitionRangeCacheDataset(dsIndex string, r PartitionRange) (string, bool) {
+	return dsIndex + "#" + r.String(), slices.Contains(jd.PartitionRanges(), r)
+}
+
+// invalidateNoResultsCache invalidates the no results cache entries of the dataset, including the ones of its partition ranges
+func (jd *Handle) invalidateNoResultsCache(dsIndex, workspace string, customVals, states []string, parameters []ParameterFilterT) {
+	jd.noResultsCache.Invalidate(dsIndex, workspace, customVals, states, parameters)
+	for _, r := range jd.PartitionRanges() {
+		cacheDataset, _ := jd.partitionRangeCacheDataset(dsIndex, r)
+		jd.noResultsCache.Invalidate(cacheDataset, workspace, customVals, states, parameters)
+	}
+}
@@ -0,0 +1,122 @@
+package jobsdb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rudderlabs/rudder-go-kit/config"
+	"github.com/rudderlabs/rudder-go-kit/testhelper/rand"
+
+	"github.com/rudderlabs/rudder-server/jobsdb/internal/cache"
+)
+
+func TestPartitionRanges(t *testing.T) {
+	require.Equal(t, []PartitionRange{{0, 3}, {3, 6}, {6, 8}, {8, 10}}, partitionRanges(10, 4))
+	require.Equal(t, []PartitionRange{{0, 1}, {1, 2}}, partitionRanges(2, 4), "ranges cannot outnumber partitions")
+	require.Equal(t, []PartitionRange{{0, 64}}, partitionRanges(64, 1))
+
+	for _, r := range partitionRanges(10, 4) {
+		parsed, err := ParsePartitionRange(r.String())
+		require.NoError(t, err)
+		require.Equal(t, r, parsed)
+	}
+	for _, invalid := range []string{"", "1", "a-2", "1-b", "2-1", "1-1", "-1-2"} {
+		_, err := ParsePartitionRange(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestPartitionKeyValidation(t *testing.T) {
+	c := config.New()
+	c.Set("JobsDB.partitionKey", "destination_id")
+	require.NotPanics(t, (&Handle{config: c, tablePrefix: "rt"}).loadConfig)
+	require.Panics(t, (&Handle{config: c, tablePrefix: "gw"}).loadConfig, "gateway jobs cannot be partitioned by destination")
+
+	c.Set("JobsDB.gw.partitionKey", "user_id")
+	require.NotPanics(t, (&Handle{config: c, tablePrefix: "gw"}).loadConfig)
+}
+
+func TestPartitionRangeNoResultsCache(t *testing.T) {
+	jd := &Handle{}
+	jd.conf.partitionCount, jd.conf.partitionRanges = 4, 2
+	jd.noResultsCache = cache.NewNoResultsCache[ParameterFilterT](cacheParameterFilters, func() time.Duration { return time.Hour })
+
+	r := PartitionRange{Start: 2, End: 4}
+	cacheDataset, ok := jd.partitionRangeCacheDataset("1", r)
+	require.True(t, ok)
+	_, ok = jd.partitionRangeCacheDataset("1", PartitionRange{Start: 1, End: 3})
+	require.False(t, ok, "ranges other than the partition ranges of the jobsdb cannot be cached")
+
+	states := []string{Unprocessed.State}
+	jd.noResultsCache.StartNoResultTx(cacheDataset, "", nil, states, nil).Commit()
+	require.True(t, jd.noResultsCache.Get(cacheDataset, "", nil, states, nil))
+	require.False(t, jd.noResultsCache.Get("1", "", nil, states, nil), "results of a range don't apply to the whole dataset")
+
+	jd.invalidateNoResultsCache("1", defaultWorkspaceID, []string{"WEBHOOK"}, states, nil)
+	require.False(t, jd.noResultsCache.Get(cacheDataset, "", nil, states, nil))
+}
+
+func TestPartitionedJobs(t *testing.T) {
+	_ = startPostgres(t)
+
+	c := config.New()
+	c.Set("JobsDB.partitionKey", "destination_id")
+	c.Set("JobsDB.partitionCount", 4)
+	c.Set("JobsDB.partitionRanges", 2)
+	jobDB := Handle{config: c}
+	tablePrefix := strings.ToLower(rand.String(5))
+	require.NoError(t, jobDB.Setup(ReadWrite, true, tablePrefix))
+	defer jobDB.TearDown()
+
+	ctx := context.Background()
+	customVal := "WEBHOOK"
+	jobs := genJobs(defaultWorkspaceID, customVal, 20, 1)
+	for i, job := range jobs {
+		job.Parameters = []byte(fmt.Sprintf(`{"destination_id":"destination-%d"}`, i%5))
+	}
+	require.NoError(t, jobDB.Store(ctx, jobs))
+
+	ranges := jobDB.PartitionRanges()
+	require.Equal(t, []PartitionRange{{0, 2}, {2, 4}}, ranges)
+
+	getJobs := func(t *testing.T, r PartitionRange) []*JobT {
+		res, err := jobDB.GetUnprocessed(ctx, GetQueryParams{CustomValFilters: []string{customVal}, PartitionRange: &r, JobsLimit: 100})
+		require.NoError(t, err)
+		return res.Jobs
+	}
+
+	t.Run("jobs of the same key belong to the same partition", func(t *testing.T) {
+		var all []*JobT
+		for _, r := range ranges {
+			rangeJobs := getJobs(t, r)
+			for _, job := range rangeJobs {
+				require.GreaterOrEqual(t, job.PartitionID, r.Start)
+				require.Less(t, job.PartitionID, r.End)
+			}
+			all = append(all, rangeJobs...)
+		}
+		require.Len(t, all, len(jobs))
+		partitions := lo.GroupBy(all, func(job *JobT) string { return string(job.Parameters) })
+		for _, destinationJobs := range partitions {
+			require.Len(t, lo.Uniq(lo.Map(destinationJobs, func(job *JobT, _ int) int { return job.PartitionID })), 1)
+		}
+	})
+
+	t.Run("jobs outside of the partition count are returned by the first and last range", func(t *testing.T) {
+		ds := jobDB.getDSList()[0]
+		_, err := jobDB.dbHandle.Exec(fmt.Sprintf(`UPDATE %q SET partition_id = -1 WHERE uuid = $1`, ds.JobTable), jobs[0].UUID)
+		require.NoError(t, err)
+		_, err = jobDB.dbHandle.Exec(fmt.Sprintf(`UPDATE %q SET partition_id = 10 WHERE uuid = $1`, ds.JobTable), jobs[1].UUID)
+		require.NoError(t, err)
+
+		require.Contains(t, lo.Map(getJobs(t, ranges[0]), func(job *JobT, _ int) uuid.UUID { return job.UUID }), jobs[0].UUID)
+		require.Contains(t, lo.Map(getJobs(t, ranges[1]), func(job *JobT, _ int) uuid.UUID { return job.UUID }), jobs[1].UUID)
+	})
+}
@@ -0,0 +1,152 @@
+package jobsdb
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/lib/pq"
+)
+
+// payloadColumnType is the type of the event_payload column of a jobs table.
+//
+// The type is selected when a dataset is created, thus datasets of the same jobsdb can have different types
+// if the configuration changes over time.
+type payloadColumnType string
+
+const (
+	payloadColumnTypeJSONB payloadColumnType = "jsonb" // payloads are stored as jsonb (default)
+	payloadColumnTypeText  payloadColumnType = "text"  // payloads are stored as text, avoiding jsonb's parsing and storage overhead
+	payloadColumnTypeBytea payloadColumnType = "bytea" // payloads are stored as compressed bytes
+)
+
+func (t payloadColumnType) valid() bool {
+	switch t {
+	case payloadColumnTypeJSONB, payloadColumnTypeText, payloadColumnTypeBytea:
+		return true
+	}
+	return false
+}
+
+// payloadCompression is the compression algorithm used for payloads stored in bytea columns
+type payloadCompression string
+
+const (
+	payloadCompressionNone   payloadCompression = "none"
+	payloadCompressionZstd   payloadCompression = "zstd"
+	payloadCompressionSnappy payloadCompression = "snappy"
+)
+
+// Each bytea payload starts with a byte identifying the compression algorithm that was used for it,
+// so that payloads can always be decoded, no matter the compression that is configured when reading them.
+var payloadCompressionHeaders = map[payloadCompression]byte{
+	payloadCompressionNone:   0,
+	payloadCompressionZstd:   1,
+	payloadCompressionSnappy: 2,
+}
+
+var (
+	zstdEncoder = sync.OnceValue(func() *zstd.Encoder {
+		encoder, _ := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedFastest))
+		return encoder
+	})
+	zstdDecoder = sync.OnceValue(func() *zstd.Decoder {
+		decoder, _ := zstd.NewReader(nil)
+		return decoder
+	})
+)
+
+// encodePayload encodes a payload for writing it into an event_payload column of the given type
+func encodePayload(columnType payloadColumnType, compression payloadCompression, payload []byte) (any, error) {
+	if columnType != payloadColumnTypeBytea {
+		return string(payload), nil
+	}
+	header, ok := payloadCompressionHeaders[compression]
+	if !ok {
+		return nil, fmt.Errorf("unsupported payload compression: %q", compression)
+	}
+	encoded := make([]byte, 1, len(payload)/2+1)
+	encoded[0] = header
+	switch compression {
+	case payloadCompressionZstd:
+		return zstdEncoder().EncodeAll(payload, encoded), nil
+	case payloadCompressionSnappy:
+		return append(encoded, snappy.Encode(nil, payload)...), nil
+	default:
+		return append(encoded, payload...), nil
+	}
+}
+
+// decodePayload decodes a payload read from an event_payload column of the given type
+func decodePayload(columnType payloadColumnType, value []byte) ([]byte, error) {
+	if columnType != payloadColumnTypeBytea {
+		return value, nil
+	}
+	if len(value) == 0 {
+		return nil, fmt.Errorf("empty bytea payload")
+	}
+	switch value[0] {
+	case payloadCompressionHeaders[payloadCompressionNone]:
+		return value[1:], nil
+	case payloadCompressionHeaders[payloadCompressionZstd]:
+		return zstdDecoder().DecodeAll(value[1:], nil)
+	case payloadCompressionHeaders[payloadCompressionSnappy]:
+		return snappy.Decode(nil, value[1:])
+	default:
+		return nil, fmt.Errorf("unknown payload compression header: %d", value[0])
+	}
+}
+
+// needsSanitizing returns true if the payload wouldn't be accepted by a jsonb column.
+// Payloads stored in text and bytea columns are not validated by postgres, but they still need to be valid,
+// so that they can be migrated into datasets having a jsonb payload column.
+func needsSanitizing(payload []byte) bool {
+	return !json.Valid(payload) || bytes.Contains(payload, []byte(`\u0000`))
+}
+
+type queryRowerContext interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// payloadColumnTypeOf returns the type of the event_payload column of the dataset's jobs table
+func (jd *Handle) payloadColumnTypeOf(ctx context.Context, db queryRowerContext, ds dataSetT) (payloadColumnType, error) {
+	jd.payloadColumnTypes.mu.RLock()
+	columnType, ok := jd.payloadColumnTypes.byTable[ds.JobTable]
+	jd.payloadColumnTypes.mu.RUnlock()
+	if ok {
+		return columnType, nil
+	}
+	var typ string
+	if err := db.QueryRowContext(ctx,
+		`SELECT format_type(atttypid, atttypmod) FROM pg_attribute WHERE attrelid = $1::regclass AND attname = 'event_payload'`,
+		pq.QuoteIdentifier(ds.JobTable),
+	).Scan(&typ); err != nil {
+		return "", fmt.Errorf("getting payload column type of %q: %w", ds.JobTable, err)
+	}
+	columnType = payloadColumnType(typ)
+	if !columnType.valid() {
+		return "", fmt.Errorf("unsupported payload column type of %q: %q", ds.JobTable, typ)
+	}
+	jd.setPayloadColumnType(ds, columnType)
+	return columnType, nil
+}
+
+func (jd *Handle) setPayloadColumnType(ds dataSetT, columnType payloadColumnType) {
+	jd.payloadColumnTypes.mu.Lock()
+	defer jd.payloadColumnTypes.mu.Unlock()
+	if jd.payloadColumnTypes.byTable == nil {
+		jd.payloadColumnTypes.byTable = make(map[string]payloadColumnType)
+	}
+	jd.payloadColumnTypes.byTable[ds.JobTable] = columnType
+}
+
+func (jd *Handle) removePayloadColumnType(ds dataSetT) {
+	jd.payloadColumnTypes.mu.Lock()
+	defer jd.payloadColumnTypes.mu.Unlock()
+	delete(jd.payloadColumnTypes.byTable, ds.JobTable)
+}
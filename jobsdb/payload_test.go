@@ -0,0 +1,133 @@
+package jobsdb
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rudderlabs/rudder-go-kit/config"
+	"github.com/rudderlabs/rudder-go-kit/testhelper/rand"
+)
+
+func TestPayloadEncoding(t *testing.T) {
+	payload := []byte(`{"batch":[{"type":"track","event":"Demo Track","properties":{"value":5}}]}`)
+
+	for _, columnType := range []payloadColumnType{payloadColumnTypeJSONB, payloadColumnTypeText, payloadColumnTypeBytea} {
+		for compression := range payloadCompressionHeaders {
+			t.Run(string(columnType)+" "+string(compression), func(t *testing.T) {
+				encoded, err := encodePayload(columnType, compression, payload)
+				require.NoError(t, err)
+
+				var value []byte
+				switch v := encoded.(type) {
+				case string:
+					require.NotEqual(t, payloadColumnTypeBytea, columnType)
+					value = []byte(v)
+				case []byte:
+					require.Equal(t, payloadColumnTypeBytea, columnType)
+					require.Equal(t, payloadCompressionHeaders[compression], v[0])
+					value = v
+				}
+				decoded, err := decodePayload(columnType, value)
+				require.NoError(t, err)
+				require.Equal(t, payload, decoded)
+			})
+		}
+	}
+
+	t.Run("unsupported compression", func(t *testing.T) {
+		_, err := encodePayload(payloadColumnTypeBytea, "lz4", payload)
+		require.Error(t, err)
+	})
+
+	t.Run("unknown compression header", func(t *testing.T) {
+		_, err := decodePayload(payloadColumnTypeBytea, append([]byte{99}, payload...))
+		require.Error(t, err)
+	})
+}
+
+func TestPayloadColumnTypes(t *testing.T) {
+	_ = startPostgres(t)
+
+	for _, tc := range []struct {
+		from, to    payloadColumnType
+		compression payloadCompression
+	}{
+		{from: payloadColumnTypeJSONB, to: payloadColumnTypeBytea, compression: payloadCompressionZstd},
+		{from: payloadColumnTypeBytea, to: payloadColumnTypeJSONB, compression: payloadCompressionZstd},
+		{from: payloadColumnTypeText, to: payloadColumnTypeBytea, compression: payloadCompressionSnappy},
+		{from: payloadColumnTypeBytea, to: payloadColumnTypeText, compression: payloadCompressionSnappy},
+		{from: payloadColumnTypeJSONB, to: payloadColumnTypeText},
+		{from: payloadColumnTypeText, to: payloadColumnTypeJSONB},
+	} {
+		t.Run(string(tc.from)+" to "+string(tc.to), func(t *testing.T) {
+			c := config.New()
+			c.Set("JobsDB.maxDSSize", 1)
+			c.Set("JobsDB.payloadColumnType", string(tc.from))
+			if tc.compression != "" {
+				c.Set("JobsDB.payloadCompression", string(tc.compression))
+			}
+
+			triggerAddNewDS := make(chan time.Time)
+			triggerMigrateDS := make(chan time.Time)
+			jobDB := Handle{
+				TriggerAddNewDS: func() <-chan time.Time {
+					return triggerAddNewDS
+				},
+				TriggerMigrateDS: func() <-chan time.Time {
+					return triggerMigrateDS
+				},
+				config: c,
+			}
+			tablePrefix := strings.ToLower(rand.String(5))
+			require.NoError(t, jobDB.Setup(ReadWrite, true, tablePrefix))
+			defer jobDB.TearDown()
+			c.Set("JobsDB."+tablePrefix+"."+"maxDSRetention", "1ms")
+
+			ctx := context.Background()
+			customVal := rand.String(5)
+			jobs := genJobs(defaultWorkspaceID, customVal, 10, 1)
+			jobs[0].EventPayload = []byte(`{"invalid":"\u0000"}`)
+			require.NoError(t, jobDB.Store(ctx, jobs))
+
+			columnType, err := jobDB.payloadColumnTypeOf(ctx, jobDB.dbHandle, jobDB.getDSList()[0])
+			require.NoError(t, err)
+			require.Equal(t, tc.from, columnType)
+			res, err := jobDB.GetUnprocessed(ctx, GetQueryParams{CustomValFilters: []string{customVal}, JobsLimit: 1})
+			require.NoError(t, err)
+			require.Len(t, res.Jobs, 1)
+			require.JSONEq(t, `{"invalid":""}`, string(res.Jobs[0].EventPayload), "payloads are sanitized regardless of the column type")
+
+			// 8 jobs succeed, 1 fails and 1 remains unprocessed
+			require.NoError(t, jobDB.UpdateJobStatus(ctx, genJobStatuses(jobs[:8], Succeeded.State), []string{customVal}, nil))
+			require.NoError(t, jobDB.UpdateJobStatus(ctx, genJobStatuses(jobs[8:9], Failed.State), []string{customVal}, nil))
+
+			triggerAddNewDS <- time.Now() // trigger addNewDSLoop to run
+			triggerAddNewDS <- time.Now() // Second time, waits for the first loop to finish
+			require.EqualValues(t, 2, jobDB.GetMaxDSIndex())
+
+			jobDB.conf.payloadColumnType = tc.to
+			triggerMigrateDS <- time.Now() // trigger migrateDSLoop to run
+			triggerMigrateDS <- time.Now() // waits for last loop to finish
+
+			dsList := jobDB.getDSList()
+			require.Equal(t, "1_1", dsList[0].Index)
+			columnType, err = jobDB.payloadColumnTypeOf(ctx, jobDB.dbHandle, dsList[0])
+			require.NoError(t, err)
+			require.Equal(t, tc.to, columnType)
+
+			res, err = jobDB.GetJobs(ctx, []string{Unprocessed.State, Failed.State}, GetQueryParams{CustomValFilters: []string{customVal}, JobsLimit: 10})
+			require.NoError(t, err)
+			require.Len(t, res.Jobs, 2)
+			for i, job := range res.Jobs {
+				require.Equal(t, jobs[8+i].UUID, job.UUID)
+				require.JSONEq(t, string(jobs[8+i].EventPayload), string(job.EventPayload))
+			}
+			require.Equal(t, Failed.State, res.Jobs[0].LastJobStatus.JobState)
+			require.Equal(t, "", res.Jobs[1].LastJobStatus.JobState)
+		})
+	}
+}
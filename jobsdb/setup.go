@@ -1,6 +1,7 @@
 package jobsdb
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/rudderlabs/rudder-server/jobsdb/internal/lock"
@@ -43,11 +44,12 @@ func (jd *Handle) runAlwaysChangesets(templateData map[string]interface{}) {
 	}
 }
 
-func (jd *Handle) dropDatabaseTables(l lock.LockToken) {
+func (jd *Handle) dropDatabaseTables(ctx context.Context, l lock.LockToken) {
 	jd.logger.Infof("[JobsDB:%v] Dropping all database tables", jd.tablePrefix)
 	jd.dropSchemaMigrationTables()
-	jd.assertError(jd.dropAllDS(l))
+	jd.assertError(jd.dropAllDS(ctx, l))
 	jd.dropJournal()
+	jd.dropColdDatasets()
 }
 
 func (jd *Handle) dropSchemaMigrationTables() {
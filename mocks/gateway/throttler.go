@@ -12,7 +12,9 @@ package mocks_gateway
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
+	throttler "github.com/rudderlabs/rudder-server/gateway/throttler"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -40,16 +42,17 @@ func (m *MockThrottler) EXPECT() *MockThrottlerMockRecorder {
 }
 
 // CheckLimitReached mocks base method.
-func (m *MockThrottler) CheckLimitReached(arg0 context.Context, arg1 string, arg2 int64) (bool, error) {
+func (m *MockThrottler) CheckLimitReached(arg0 context.Context, arg1 *throttler.Request) (bool, time.Duration, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CheckLimitReached", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "CheckLimitReached", arg0, arg1)
 	ret0, _ := ret[0].(bool)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret1, _ := ret[1].(time.Duration)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // CheckLimitReached indicates an expected call of CheckLimitReached.
-func (mr *MockThrottlerMockRecorder) CheckLimitReached(arg0, arg1, arg2 any) *gomock.Call {
+func (mr *MockThrottlerMockRecorder) CheckLimitReached(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckLimitReached", reflect.TypeOf((*MockThrottler)(nil).CheckLimitReached), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckLimitReached", reflect.TypeOf((*MockThrottler)(nil).CheckLimitReached), arg0, arg1)
 }
@@ -314,6 +314,20 @@ func (mr *MockJobsDBMockRecorder) JournalMarkStart(arg0, arg1 any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JournalMarkStart", reflect.TypeOf((*MockJobsDB)(nil).JournalMarkStart), arg0, arg1)
 }
 
+// PartitionRanges mocks base method.
+func (m *MockJobsDB) PartitionRanges() []jobsdb.PartitionRange {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PartitionRanges")
+	ret0, _ := ret[0].([]jobsdb.PartitionRange)
+	return ret0
+}
+
+// PartitionRanges indicates an expected call of PartitionRanges.
+func (mr *MockJobsDBMockRecorder) PartitionRanges() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PartitionRanges", reflect.TypeOf((*MockJobsDB)(nil).PartitionRanges))
+}
+
 // Ping mocks base method.
 func (m *MockJobsDB) Ping() error {
 	m.ctrl.T.Helper()
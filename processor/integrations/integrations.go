@@ -34,6 +34,8 @@ type PostParametersT struct {
 	Type          string `json:"type"`
 	URL           string `json:"endpoint"`
 	RequestMethod string `json:"method"`
+	// GRPCMethod is the full name of the gRPC method to invoke for payloads of type GRPC, e.g. package.Service/Method
+	GRPCMethod string `json:"grpcMethod"`
 	// Invalid tag used in struct. skipcq: SCC-SA5008
 	UserID      string                 `json:"userId,,optional"` //nolint:staticcheck
 	Headers     map[string]interface{} `json:"headers"`
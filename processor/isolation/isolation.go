@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 
+	"github.com/samber/lo"
+
 	"github.com/rudderlabs/rudder-server/jobsdb"
 )
 
@@ -13,6 +15,7 @@ const (
 	ModeNone      Mode = "none"
 	ModeWorkspace Mode = "workspace"
 	ModeSource    Mode = "source"
+	ModePartition Mode = "partition"
 )
 
 // GetStrategy returns the strategy for the given isolation mode. An error is returned if the mode is invalid
@@ -24,6 +27,8 @@ func GetStrategy(mode Mode) (Strategy, error) {
 		return workspaceStrategy{}, nil
 	case ModeSource:
 		return sourceStrategy{}, nil
+	case ModePartition:
+		return partitionStrategy{}, nil
 	default:
 		return noneStrategy{}, errors.New("unsupported isolation mode")
 	}
@@ -33,8 +38,9 @@ func GetStrategy(mode Mode) (Strategy, error) {
 type Strategy interface {
 	// ActivePartitions returns the list of partitions that are active for the given strategy
 	ActivePartitions(ctx context.Context, db jobsdb.JobsDB) ([]string, error)
-	// AugmentQueryParams augments the given GetQueryParamsT with the strategy specific parameters
-	AugmentQueryParams(partition string, params *jobsdb.GetQueryParams)
+	// AugmentQueryParams augments the given GetQueryParamsT with the strategy specific parameters,
+	// returning an error if the partition isn't valid for the strategy
+	AugmentQueryParams(partition string, params *jobsdb.GetQueryParams) error
 }
 
 // noneStrategy implements isolation at no level
@@ -44,8 +50,8 @@ func (noneStrategy) ActivePartitions(_ context.Context, _ jobsdb.JobsDB) ([]stri
 	return []string{""}, nil
 }
 
-func (noneStrategy) AugmentQueryParams(_ string, _ *jobsdb.GetQueryParams) {
-	// no-op
+func (noneStrategy) AugmentQueryParams(_ string, _ *jobsdb.GetQueryParams) error {
+	return nil // no-op
 }
 
 // workspaceStrategy implements isolation at workspace level
@@ -56,8 +62,9 @@ func (workspaceStrategy) ActivePartitions(ctx context.Context, db jobsdb.JobsDB)
 	return db.GetActiveWorkspaces(ctx, "")
 }
 
-func (workspaceStrategy) AugmentQueryParams(partition string, params *jobsdb.GetQueryParams) {
+func (workspaceStrategy) AugmentQueryParams(partition string, params *jobsdb.GetQueryParams) error {
 	params.WorkspaceID = partition
+	return nil
 }
 
 // sourceStrategy implements isolation at source level
@@ -69,6 +76,27 @@ func (sourceStrategy) ActivePartitions(ctx context.Context, db jobsdb.JobsDB) ([
 }
 
 // AugmentQueryParams augments the given GetQueryParamsT by adding the partition as sourceID parameter filter
-func (sourceStrategy) AugmentQueryParams(partition string, params *jobsdb.GetQueryParams) {
+func (sourceStrategy) AugmentQueryParams(partition string, params *jobsdb.GetQueryParams) error {
 	params.ParameterFilters = append(params.ParameterFilters, jobsdb.ParameterFilterT{Name: "source_id", Value: partition})
+	return nil
+}
+
+// partitionStrategy implements isolation at partition range level
+type partitionStrategy struct{}
+
+// ActivePartitions returns the partition ranges of jobsdb
+func (partitionStrategy) ActivePartitions(_ context.Context, db jobsdb.JobsDB) ([]string, error) {
+	return lo.Map(db.PartitionRanges(), func(r jobsdb.PartitionRange, _ int) string {
+		return r.String()
+	}), nil
+}
+
+// AugmentQueryParams augments the given GetQueryParamsT by adding the partition as partition range
+func (partitionStrategy) AugmentQueryParams(partition string, params *jobsdb.GetQueryParams) error {
+	r, err := jobsdb.ParsePartitionRange(partition)
+	if err != nil {
+		return err
+	}
+	params.PartitionRange = &r
+	return nil
 }
@@ -37,6 +37,7 @@ import (
 	"github.com/rudderlabs/rudder-server/processor/eventfilter"
 	"github.com/rudderlabs/rudder-server/processor/integrations"
 	"github.com/rudderlabs/rudder-server/processor/isolation"
+	"github.com/rudderlabs/rudder-server/processor/schemaenforcement"
 	"github.com/rudderlabs/rudder-server/processor/stash"
 	"github.com/rudderlabs/rudder-server/processor/transformer"
 	"github.com/rudderlabs/rudder-server/router/batchrouter"
@@ -48,6 +49,7 @@ import (
 	"github.com/rudderlabs/rudder-server/services/fileuploader"
 	"github.com/rudderlabs/rudder-server/services/rmetrics"
 	"github.com/rudderlabs/rudder-server/services/rsources"
+	"github.com/rudderlabs/rudder-server/services/secrets"
 	transformerFeaturesService "github.com/rudderlabs/rudder-server/services/transformer"
 	"github.com/rudderlabs/rudder-server/services/transientsource"
 	"github.com/rudderlabs/rudder-server/utils/crash"
@@ -156,6 +158,8 @@ type Handle struct {
 		eventAuditEnabled               map[string]bool
 		credentialsMap                  map[string][]transformer.Credential
 		nonEventStreamSources           map[string]bool
+		schemaEnforcers                 map[string]*schemaenforcement.Enforcer
+		enableSchemaEnforcement         config.ValueLoader[bool]
 	}
 
 	drainConfig struct {
@@ -391,7 +395,7 @@ func (proc *Handle) Setup(
 		proc.conf = config.Default
 	}
 	proc.setupReloadableVars()
-	proc.logger = logger.NewLogger().Child("processor")
+	proc.logger = secrets.NewRedactingLogger(logger.NewLogger().Child("processor"))
 	proc.backendConfig = backendConfig
 
 	proc.gatewayDB = gatewayDB
@@ -815,6 +819,8 @@ func (proc *Handle) loadReloadableConfig(defaultPayloadLimit int64, defaultMaxEv
 	proc.config.archivalEnabled = config.GetReloadableBoolVar(true, "archival.Enabled")
 	// Capture event name as a tag in event level stats
 	proc.config.captureEventNameStats = config.GetReloadableBoolVar(false, "Processor.Stats.captureEventName")
+	// Enforce the json schemas attached to sources, if any
+	proc.config.enableSchemaEnforcement = config.GetReloadableBoolVar(true, "Processor.SchemaEnforcement.enabled")
 }
 
 type connection struct {
@@ -874,6 +880,7 @@ func (proc *Handle) backendConfigSubscriber(ctx context.Context) {
 				}
 			})
 		}
+		schemaEnforcers := proc.buildSchemaEnforcers(sourceIdSourceMap)
 		proc.config.configSubscriberLock.Lock()
 		proc.config.connectionConfigMap = connectionConfigMap
 		proc.config.oneTrustConsentCategoriesMap = oneTrustConsentCategoriesMap
@@ -885,6 +892,7 @@ func (proc *Handle) backendConfigSubscriber(ctx context.Context) {
 		proc.config.eventAuditEnabled = eventAuditEnabled
 		proc.config.credentialsMap = credentialsMap
 		proc.config.nonEventStreamSources = nonEventStreamSources
+		proc.config.schemaEnforcers = schemaEnforcers
 		proc.config.configSubscriberLock.Unlock()
 		if !initDone {
 			initDone = true
@@ -1133,7 +1141,7 @@ func (proc *Handle) getTransformerEvents(
 
 		for _, message := range messages {
 			proc.updateMetricMaps(successCountMetadataMap, successCountMap, connectionDetailsMap, statusDetailsMap, userTransformedEvent, jobsdb.Succeeded.State, pu, func() json.RawMessage {
-				if pu != types.TRACKINGPLAN_VALIDATOR {
+				if pu != types.TRACKINGPLAN_VALIDATOR && pu != types.SCHEMA_ENFORCER {
 					return []byte(`{}`)
 				}
 				if proc.transientSources.Apply(commonMetaData.SourceID) {
@@ -1276,7 +1284,7 @@ func (proc *Handle) updateMetricMaps(
 	// create status details for each validation error
 	// single event can have multiple validation errors of same type
 	veCount := len(event.ValidationErrors)
-	if stage == types.TRACKINGPLAN_VALIDATOR && status == jobsdb.Succeeded.State {
+	if (stage == types.TRACKINGPLAN_VALIDATOR || stage == types.SCHEMA_ENFORCER) && status == jobsdb.Succeeded.State {
 		if veCount > 0 {
 			status = types.SUCCEEDED_WITH_VIOLATIONS
 		} else {
@@ -1636,6 +1644,7 @@ func (proc *Handle) processJobsForDest(partition string, subJobs subJob) *transf
 	destFilterStatusDetailMap := make(map[string]map[string]*types.StatusDetail)
 	// map of jobID to destinationID: for messages that needs to be delivered to a specific destinations only
 	jobIDToSpecificDestMapOnly := make(map[int64]string)
+	botJobIDs := make(map[int64]struct{})
 
 	spans := make([]stats.TraceSpan, 0, len(jobList))
 	defer func() {
@@ -1657,6 +1666,9 @@ func (proc *Handle) processJobsForDest(partition string, subJobs subJob) *transf
 		if destinationID != "" {
 			jobIDToSpecificDestMapOnly[batchEvent.JobID] = destinationID
 		}
+		if eventParams.BotRule != "" {
+			botJobIDs[batchEvent.JobID] = struct{}{}
+		}
 
 		var span stats.TraceSpan
 		if traceParent == "" {
@@ -1978,11 +1990,18 @@ func (proc *Handle) processJobsForDest(partition string, subJobs subJob) *transf
 		}
 	}
 
+	// SCHEMA ENFORCEMENT - START
+	// Same as tracking plan validation, enforcing schemas before events are duplicated by destId
+	enforcedEventsBySourceId, enforcedReportMetrics, enforcedErrorJobs := proc.enforceSchemas(groupedEventsBySourceId, eventsByMessageID)
+	procErrorJobs = append(procErrorJobs, enforcedErrorJobs...)
+	reportMetrics = append(reportMetrics, enforcedReportMetrics...)
+	// SCHEMA ENFORCEMENT - END
+
 	// TRACKING PLAN - START
 	// Placing the trackingPlan validation filters here.
 	// Else further down events are duplicated by destId, so multiple validation takes places for same event
 	validateEventsStart := time.Now()
-	validatedEventsBySourceId, validatedReportMetrics, validatedErrorJobs, trackingPlanEnabledMap := proc.validateEvents(groupedEventsBySourceId, eventsByMessageID)
+	validatedEventsBySourceId, validatedReportMetrics, validatedErrorJobs, trackingPlanEnabledMap := proc.validateEvents(enforcedEventsBySourceId, eventsByMessageID)
 	validateEventsTime := time.Since(validateEventsStart)
 	defer proc.stats.validateEventsTime(partition).SendTiming(validateEventsTime)
 
@@ -2015,6 +2034,11 @@ func (proc *Handle) processJobsForDest(partition string, subJobs subJob) *transf
 						if destId != "" {
 							return destId == item.ID
 						}
+						if source.BotManagement.Policy == backendconfig.BotPolicyRoute {
+							// bot events are sent only to the source's bot destinations, which don't receive any other events
+							_, isBotEvent := botJobIDs[event.Metadata.JobID]
+							return isBotEvent == slices.Contains(source.BotManagement.DestinationIDs, item.ID)
+						}
 						return destId == ""
 					}),
 				)
@@ -3059,7 +3083,10 @@ func (proc *Handle) getJobs(partition string) jobsdb.JobsResult {
 		EventsLimit:      eventCount,
 		PayloadSizeLimit: proc.adaptiveLimit(proc.payloadLimit.Load()),
 	}
-	proc.isolationStrategy.AugmentQueryParams(partition, &queryParams)
+	if err := proc.isolationStrategy.AugmentQueryParams(partition, &queryParams); err != nil {
+		proc.logger.Errorf("Skipping the jobs of partition %q: %v", partition, err)
+		return jobsdb.JobsResult{}
+	}
 
 	unprocessedList, err := misc.QueryWithRetriesAndNotify(context.Background(), proc.jobdDBQueryRequestTimeout.Load(), proc.jobdDBMaxRetries.Load(), func(ctx context.Context) (jobsdb.JobsResult, error) {
 		return proc.gatewayDB.GetUnprocessed(ctx, queryParams)
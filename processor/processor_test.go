@@ -39,6 +39,7 @@ import (
 	mock_features "github.com/rudderlabs/rudder-server/mocks/services/transformer"
 	mockReportingTypes "github.com/rudderlabs/rudder-server/mocks/utils/types"
 	"github.com/rudderlabs/rudder-server/processor/isolation"
+	"github.com/rudderlabs/rudder-server/processor/schemaenforcement"
 	"github.com/rudderlabs/rudder-server/processor/transformer"
 	destinationdebugger "github.com/rudderlabs/rudder-server/services/debugger/destination"
 	transformationdebugger "github.com/rudderlabs/rudder-server/services/debugger/transformation"
@@ -1304,6 +1305,129 @@ var _ = Describe("Processor with event schemas v2", Ordered, func() {
 	})
 })
 
+var _ = Describe("Processor with schema enforcement", Ordered, func() {
+	initProcessor()
+
+	var c *testContext
+
+	BeforeEach(func() {
+		c = &testContext{}
+		c.Setup()
+		// crash recovery check
+		c.mockGatewayJobsDB.EXPECT().DeleteExecuting().Times(1)
+		c.mockArchivalDB.EXPECT().WithStoreSafeTx(gomock.Any(), gomock.Any()).AnyTimes()
+		c.mockArchivalDB.EXPECT().StoreInTx(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	})
+
+	AfterEach(func() {
+		c.Finish()
+	})
+
+	// message-1 conforms to the source's schema, message-2 violates it
+	processJobs := func(mode string) *transformationMessage {
+		messages := []mockEventData{
+			{
+				id:                        "1",
+				jobid:                     1010,
+				originalTimestamp:         "2000-01-02T01:23:45",
+				expectedOriginalTimestamp: "2000-01-02T01:23:45.000Z",
+				sentAt:                    "2000-01-02 01:23",
+				expectedSentAt:            "2000-01-02T01:23:00.000Z",
+				expectedReceivedAt:        "2001-01-02T02:23:45.000Z",
+				integrations:              map[string]bool{"All": true},
+			},
+			{
+				id:                        "2",
+				jobid:                     1010,
+				originalTimestamp:         "2000-01-02T01:23:45",
+				expectedOriginalTimestamp: "2000-01-02T01:23:45.000Z",
+				sentAt:                    "2000-01-02 01:23",
+				expectedSentAt:            "2000-01-02T01:23:00.000Z",
+				expectedReceivedAt:        "2001-01-02T02:23:45.000Z",
+				integrations:              map[string]bool{"All": true},
+			},
+		}
+		unprocessedJobsList := []*jobsdb.JobT{
+			{
+				UUID:          uuid.New(),
+				JobID:         1010,
+				CreatedAt:     time.Date(2020, 0o4, 28, 23, 26, 0o0, 0o0, time.UTC),
+				ExpireAt:      time.Date(2020, 0o4, 28, 23, 26, 0o0, 0o0, time.UTC),
+				CustomVal:     gatewayCustomVal[0],
+				EventPayload:  createBatchPayload(WriteKeyEnabledNoUT, "2001-01-02T02:23:45.000Z", messages, createMessagePayload),
+				EventCount:    2,
+				LastJobStatus: jobsdb.JobStatusT{},
+				Parameters:    createBatchParameters(SourceIDEnabledNoUT),
+				WorkspaceId:   sampleWorkspaceID,
+			},
+		}
+
+		processor := NewHandle(config.Default, mocksTransformer.NewMockTransformer(c.mockCtrl))
+		isolationStrategy, err := isolation.GetStrategy(isolation.ModeNone)
+		Expect(err).To(BeNil())
+		processor.isolationStrategy = isolationStrategy
+		Setup(processor, c, false, false)
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		Expect(processor.config.asyncInit.WaitContext(ctx)).To(BeNil())
+
+		enforcer, err := schemaenforcement.New(backendconfig.SchemaEnforcementT{
+			ID:      "schema-1",
+			Version: 1,
+			Mode:    mode,
+			Schemas: []backendconfig.EventSchemaT{
+				{Schema: json.RawMessage(`{"type":"object","properties":{"some-property":{"const":"property-1"}}}`)},
+			},
+		})
+		Expect(err).To(BeNil())
+		processor.config.configSubscriberLock.Lock()
+		processor.config.schemaEnforcers = map[string]*schemaenforcement.Enforcer{SourceIDEnabledNoUT: enforcer}
+		processor.config.configSubscriberLock.Unlock()
+
+		return processor.processJobsForDest("", subJob{subJobs: unprocessedJobsList})
+	}
+
+	messageIDsOf := func(groupedEvents map[string][]transformer.TransformerEvent) []string {
+		var messageIDs []string
+		for _, events := range groupedEvents {
+			for _, event := range events {
+				messageIDs = append(messageIDs, event.Metadata.MessageID)
+			}
+		}
+		return lo.Uniq(messageIDs)
+	}
+
+	Context("schema enforcement", func() {
+		It("should drop events violating their source's schema", func() {
+			message := processJobs(schemaenforcement.ModeDrop)
+			Expect(messageIDsOf(message.groupedEvents)).To(Equal([]string{"message-1"}))
+			Expect(message.procErrorJobs).To(BeEmpty())
+		})
+
+		It("should quarantine events violating their source's schema", func() {
+			message := processJobs(schemaenforcement.ModeQuarantine)
+			Expect(messageIDsOf(message.groupedEvents)).To(Equal([]string{"message-1"}))
+			Expect(message.procErrorJobs).To(HaveLen(1))
+			Expect(gjson.GetBytes(message.procErrorJobs[0].EventPayload, "0.messageId").String()).To(Equal("message-2"))
+		})
+
+		It("should flag events violating their source's schema in warn mode", func() {
+			message := processJobs(schemaenforcement.ModeWarn)
+			Expect(messageIDsOf(message.groupedEvents)).To(ConsistOf("message-1", "message-2"))
+			for _, events := range message.groupedEvents {
+				for _, event := range events {
+					violations := event.Message["context"].(map[string]interface{})["violationErrors"]
+					if event.Metadata.MessageID == "message-2" {
+						Expect(violations).NotTo(BeNil())
+					} else {
+						Expect(violations).To(BeNil())
+					}
+				}
+			}
+		})
+	})
+})
+
 var _ = Describe("Processor with ArchivalV2 enabled", Ordered, func() {
 	initProcessor()
 
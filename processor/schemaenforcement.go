@@ -0,0 +1,179 @@
+package processor
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/rudderlabs/rudder-go-kit/stats"
+	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
+	"github.com/rudderlabs/rudder-server/jobsdb"
+	"github.com/rudderlabs/rudder-server/processor/schemaenforcement"
+	"github.com/rudderlabs/rudder-server/processor/transformer"
+	"github.com/rudderlabs/rudder-server/utils/types"
+)
+
+// buildSchemaEnforcers compiles the schema enforcement configuration of every source having one,
+// reusing the enforcers of the previous configuration which haven't changed.
+func (proc *Handle) buildSchemaEnforcers(sources map[string]backendconfig.SourceT) map[string]*schemaenforcement.Enforcer {
+	proc.config.configSubscriberLock.RLock()
+	previous := proc.config.schemaEnforcers
+	proc.config.configSubscriberLock.RUnlock()
+
+	enforcers := make(map[string]*schemaenforcement.Enforcer)
+	for sourceID, source := range sources {
+		conf := source.SchemaEnforcement
+		if len(conf.Schemas) == 0 {
+			continue
+		}
+		if e, ok := previous[sourceID]; ok && e.Matches(conf) {
+			enforcers[sourceID] = e
+			continue
+		}
+		e, err := schemaenforcement.New(conf)
+		if err != nil {
+			proc.logger.Errorw("invalid schema enforcement configuration, schemas will not be enforced for the source",
+				"sourceId", sourceID,
+				"schemaId", conf.ID,
+				"schemaVersion", conf.Version,
+				"error", err)
+			continue
+		}
+		enforcers[sourceID] = e
+	}
+	return enforcers
+}
+
+func (proc *Handle) getSchemaEnforcer(sourceID string) *schemaenforcement.Enforcer {
+	proc.config.configSubscriberLock.RLock()
+	defer proc.config.configSubscriberLock.RUnlock()
+	return proc.config.schemaEnforcers[sourceID]
+}
+
+// enforceSchemas validates events in-process against the json schemas attached to their source, if any.
+// Depending on the source's enforcement mode, events violating their schemas are either:
+// 1. passed on, having their violations added to their context (warn)
+// 2. filtered out (drop)
+// 3. aborted, getting added to enforcedErrorJobs (quarantine)
+func (proc *Handle) enforceSchemas(groupedEventsBySourceId map[SourceIDT][]transformer.TransformerEvent, eventsByMessageID map[string]types.SingularEventWithReceivedAt) (map[SourceIDT][]transformer.TransformerEvent, []*types.PUReportedMetric, []*jobsdb.JobT) {
+	if !proc.config.enableSchemaEnforcement.Load() {
+		return groupedEventsBySourceId, nil, nil
+	}
+	enforcedEventsBySourceId := make(map[SourceIDT][]transformer.TransformerEvent)
+	enforcedReportMetrics := make([]*types.PUReportedMetric, 0)
+	enforcedErrorJobs := make([]*jobsdb.JobT, 0)
+
+	for sourceId, eventList := range groupedEventsBySourceId {
+		enforcer := proc.getSchemaEnforcer(string(sourceId))
+		if enforcer == nil {
+			enforcedEventsBySourceId[sourceId] = eventList
+			continue
+		}
+		enforcementStat := proc.newSchemaEnforcementStat(&eventList[0].Metadata, enforcer)
+		enforcementStat.numEvents.Count(len(eventList))
+		start := time.Now()
+
+		var (
+			response      transformer.Response
+			passedEvents  []transformer.TransformerEvent
+			numViolations int
+		)
+		for i := range eventList {
+			event := &eventList[i]
+			violations := enforcer.Validate(event.Message)
+			validatedEvent := transformer.TransformerResponse{
+				Output:           event.Message,
+				Metadata:         event.Metadata,
+				StatusCode:       http.StatusOK,
+				ValidationErrors: violations,
+			}
+			if len(violations) == 0 {
+				response.Events = append(response.Events, validatedEvent)
+				passedEvents = append(passedEvents, *event)
+				continue
+			}
+			numViolations++
+			reportSchemaViolations(event.Message, enforcer, violations)
+			switch enforcer.Mode {
+			case schemaenforcement.ModeDrop:
+				validatedEvent.StatusCode = types.FilterEventCode
+				validatedEvent.Error = "event violates its schema"
+				response.FailedEvents = append(response.FailedEvents, validatedEvent)
+			case schemaenforcement.ModeQuarantine:
+				validatedEvent.StatusCode = http.StatusBadRequest
+				validatedEvent.Error = "event violates its schema"
+				response.FailedEvents = append(response.FailedEvents, validatedEvent)
+			default:
+				response.Events = append(response.Events, validatedEvent)
+				passedEvents = append(passedEvents, *event)
+			}
+		}
+		enforcementStat.enforcementTime.Since(start)
+
+		transformerEvent := eventList[0]
+		commonMetaData := makeCommonMetadataFromTransformerEvent(&transformerEvent)
+		// events are passed on as they are, so that the metadata needed by the next stages is retained,
+		// getTransformerEvents is only used for reporting.
+		_, successMetrics, _, _ := proc.getTransformerEvents(response, commonMetaData, eventsByMessageID, &transformerEvent.Destination, backendconfig.Connection{}, types.DESTINATION_FILTER, types.SCHEMA_ENFORCER)
+		nonSuccessMetrics := proc.getNonSuccessfulMetrics(response, commonMetaData, eventsByMessageID, types.DESTINATION_FILTER, types.SCHEMA_ENFORCER)
+
+		enforcementStat.numViolatingEvents.Count(numViolations)
+		enforcementStat.numFailedEvents.Count(len(nonSuccessMetrics.failedJobs))
+		enforcementStat.numFilteredEvents.Count(len(nonSuccessMetrics.filteredJobs))
+
+		enforcedErrorJobs = append(enforcedErrorJobs, nonSuccessMetrics.failedJobs...)
+
+		// REPORTING - START
+		if proc.isReportingEnabled() {
+			enforcedReportMetrics = append(enforcedReportMetrics, successMetrics...)
+			enforcedReportMetrics = append(enforcedReportMetrics, nonSuccessMetrics.failedMetrics...)
+			enforcedReportMetrics = append(enforcedReportMetrics, nonSuccessMetrics.filteredMetrics...)
+		}
+		// REPORTING - END
+
+		if len(passedEvents) == 0 {
+			continue
+		}
+		enforcedEventsBySourceId[sourceId] = passedEvents
+	}
+	return enforcedEventsBySourceId, enforcedReportMetrics, enforcedErrorJobs
+}
+
+// reportSchemaViolations adds the schema's id, version and the event's violations in the event's context
+func reportSchemaViolations(event types.SingularEventT, enforcer *schemaenforcement.Enforcer, violations []transformer.ValidationError) {
+	eventContext, ok := event["context"].(map[string]interface{})
+	if !ok {
+		if event["context"] != nil {
+			return
+		}
+		eventContext = make(map[string]interface{})
+		event["context"] = eventContext
+	}
+	eventContext["schemaId"] = enforcer.ID
+	eventContext["schemaVersion"] = enforcer.Version
+	eventContext["violationErrors"] = violations
+}
+
+type schemaEnforcementStat struct {
+	numEvents          stats.Measurement
+	numViolatingEvents stats.Measurement
+	numFailedEvents    stats.Measurement
+	numFilteredEvents  stats.Measurement
+	enforcementTime    stats.Measurement
+}
+
+// newSchemaEnforcementStat creates a new schemaEnforcementStat instance
+func (proc *Handle) newSchemaEnforcementStat(metadata *transformer.Metadata, enforcer *schemaenforcement.Enforcer) *schemaEnforcementStat {
+	tags := map[string]string{
+		"source":      metadata.SourceID,
+		"workspaceId": metadata.WorkspaceID,
+		"schemaId":    enforcer.ID,
+		"mode":        enforcer.Mode,
+	}
+	return &schemaEnforcementStat{
+		numEvents:          proc.statsFactory.NewTaggedStat("proc_num_schema_enforcement_input_events", stats.CountType, tags),
+		numViolatingEvents: proc.statsFactory.NewTaggedStat("proc_num_schema_enforcement_violating_events", stats.CountType, tags),
+		numFailedEvents:    proc.statsFactory.NewTaggedStat("proc_num_schema_enforcement_failed_events", stats.CountType, tags),
+		numFilteredEvents:  proc.statsFactory.NewTaggedStat("proc_num_schema_enforcement_filtered_events", stats.CountType, tags),
+		enforcementTime:    proc.statsFactory.NewTaggedStat("proc_schema_enforcement", stats.TimerType, tags),
+	}
+}
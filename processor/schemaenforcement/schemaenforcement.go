@@ -0,0 +1,117 @@
+package schemaenforcement
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/xeipuuv/gojsonschema"
+
+	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
+	"github.com/rudderlabs/rudder-server/processor/transformer"
+)
+
+// Schema enforcement modes, deciding what happens to events violating their schemas
+const (
+	// ModeWarn lets events through, adding their violations to the event's context
+	ModeWarn = "warn"
+	// ModeDrop filters events out
+	ModeDrop = "drop"
+	// ModeQuarantine aborts events, storing them in the processor's error db
+	ModeQuarantine = "quarantine"
+)
+
+// Enforcer validates events against the json schemas of a source's schema enforcement configuration
+type Enforcer struct {
+	ID      string
+	Version int
+	Mode    string
+	schemas []eventSchema
+	hash    string // hash of the configuration the enforcer was compiled from
+}
+
+type eventSchema struct {
+	eventType string
+	eventName string
+	schema    *gojsonschema.Schema
+}
+
+// New compiles the json schemas of the provided configuration, returning an error if its mode is unknown or any of its schemas is invalid.
+// An empty mode defaults to [ModeWarn].
+func New(conf backendconfig.SchemaEnforcementT) (*Enforcer, error) {
+	e := &Enforcer{
+		ID:      conf.ID,
+		Version: conf.Version,
+		Mode:    conf.Mode,
+		hash:    configHash(conf),
+	}
+	switch e.Mode {
+	case "":
+		e.Mode = ModeWarn
+	case ModeWarn, ModeDrop, ModeQuarantine:
+	default:
+		return nil, fmt.Errorf("unknown schema enforcement mode %q", conf.Mode)
+	}
+	for i, s := range conf.Schemas {
+		schema, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(s.Schema))
+		if err != nil {
+			return nil, fmt.Errorf("compiling schema %d for event type %q and event name %q: %w", i, s.EventType, s.EventName, err)
+		}
+		e.schemas = append(e.schemas, eventSchema{
+			eventType: s.EventType,
+			eventName: s.EventName,
+			schema:    schema,
+		})
+	}
+	return e, nil
+}
+
+// Matches returns true if the enforcer was compiled from the provided configuration,
+// comparing the whole configuration, since its mode or schemas may change without a version bump.
+func (e *Enforcer) Matches(conf backendconfig.SchemaEnforcementT) bool {
+	return e.hash != "" && e.hash == configHash(conf)
+}
+
+func configHash(conf backendconfig.SchemaEnforcementT) string {
+	b, err := json.Marshal(conf)
+	if err != nil { // never matches, so that the configuration gets compiled again
+		return ""
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// Validate validates the event against all schemas matching its type and name, returning its violations.
+// Events not matching any schema have no violations.
+func (e *Enforcer) Validate(event map[string]interface{}) []transformer.ValidationError {
+	eventType, _ := event["type"].(string)
+	eventName, _ := event["event"].(string)
+
+	var violations []transformer.ValidationError
+	for _, s := range e.schemas {
+		if (s.eventType != "" && s.eventType != eventType) || (s.eventName != "" && s.eventName != eventName) {
+			continue
+		}
+		result, err := s.schema.Validate(gojsonschema.NewGoLoader(event))
+		if err != nil {
+			violations = append(violations, transformer.ValidationError{
+				Type:    "invalid_event",
+				Message: err.Error(),
+				Meta:    map[string]string{"schemaId": e.ID},
+			})
+			continue
+		}
+		for _, re := range result.Errors() {
+			violations = append(violations, transformer.ValidationError{
+				Type:    re.Type(),
+				Message: re.String(),
+				Meta: map[string]string{
+					"schemaId": e.ID,
+					"field":    re.Field(),
+				},
+			})
+		}
+	}
+	return violations
+}
@@ -0,0 +1,99 @@
+package schemaenforcement_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
+	"github.com/rudderlabs/rudder-server/processor/schemaenforcement"
+)
+
+func TestEnforcer(t *testing.T) {
+	conf := backendconfig.SchemaEnforcementT{
+		ID:      "schema-1",
+		Version: 2,
+		Schemas: []backendconfig.EventSchemaT{
+			{
+				EventType: "track",
+				EventName: "Order Completed",
+				Schema:    json.RawMessage(`{"type":"object","required":["properties"],"properties":{"properties":{"type":"object","required":["total"],"properties":{"total":{"type":"number"}}}}}`),
+			},
+			{
+				EventType: "identify",
+				Schema:    json.RawMessage(`{"type":"object","required":["userId"]}`),
+			},
+		},
+	}
+
+	t.Run("defaults to warn mode", func(t *testing.T) {
+		e, err := schemaenforcement.New(conf)
+		require.NoError(t, err)
+		require.Equal(t, schemaenforcement.ModeWarn, e.Mode)
+		require.Equal(t, "schema-1", e.ID)
+		require.Equal(t, 2, e.Version)
+	})
+
+	t.Run("unknown mode", func(t *testing.T) {
+		c := conf
+		c.Mode = "unknown"
+		_, err := schemaenforcement.New(c)
+		require.Error(t, err)
+	})
+
+	t.Run("invalid schema", func(t *testing.T) {
+		c := conf
+		c.Schemas = []backendconfig.EventSchemaT{{EventType: "track", Schema: json.RawMessage(`{"type":"invalid"}`)}}
+		_, err := schemaenforcement.New(c)
+		require.Error(t, err)
+	})
+
+	t.Run("matches", func(t *testing.T) {
+		e, err := schemaenforcement.New(conf)
+		require.NoError(t, err)
+		require.True(t, e.Matches(conf))
+
+		c := conf
+		c.Mode = schemaenforcement.ModeDrop
+		require.False(t, e.Matches(c), "mode changed without a version bump")
+
+		c = conf
+		c.Schemas = []backendconfig.EventSchemaT{{EventType: "identify", Schema: json.RawMessage(`{"type":"object","required":["traits"]}`)}}
+		require.False(t, e.Matches(c), "schemas changed without a version bump")
+	})
+
+	t.Run("validate", func(t *testing.T) {
+		c := conf
+		c.Mode = schemaenforcement.ModeDrop
+		e, err := schemaenforcement.New(c)
+		require.NoError(t, err)
+
+		require.Empty(t, e.Validate(map[string]interface{}{
+			"type":       "track",
+			"event":      "Order Completed",
+			"properties": map[string]interface{}{"total": 10.5},
+		}))
+		require.Empty(t, e.Validate(map[string]interface{}{
+			"type":  "track",
+			"event": "Product Viewed",
+		}), "events not matching any schema have no violations")
+
+		violations := e.Validate(map[string]interface{}{
+			"type":       "track",
+			"event":      "Order Completed",
+			"properties": map[string]interface{}{"total": "10.5"},
+		})
+		require.Len(t, violations, 1)
+		require.Equal(t, "invalid_type", violations[0].Type)
+		require.Equal(t, "properties.total", violations[0].Meta["field"])
+		require.Equal(t, "schema-1", violations[0].Meta["schemaId"])
+
+		violations = e.Validate(map[string]interface{}{
+			"type":        "identify",
+			"anonymousId": "anon",
+		})
+		require.Len(t, violations, 1)
+		require.Equal(t, "required", violations[0].Type)
+	})
+}
@@ -33,6 +33,8 @@ const (
 	Warehouse_RetrieveFailedBatches_FullMethodName                                = "/proto.Warehouse/RetrieveFailedBatches"
 	Warehouse_RetryFailedBatches_FullMethodName                                   = "/proto.Warehouse/RetryFailedBatches"
 	Warehouse_GetFirstAbortedUploadInContinuousAbortsByDestination_FullMethodName = "/proto.Warehouse/GetFirstAbortedUploadInContinuousAbortsByDestination"
+	Warehouse_GetWHUploadSchemaChanges_FullMethodName                             = "/proto.Warehouse/GetWHUploadSchemaChanges"
+	Warehouse_ReviewWHUploadSchemaChanges_FullMethodName                          = "/proto.Warehouse/ReviewWHUploadSchemaChanges"
 )
 
 // WarehouseClient is the client API for Warehouse service.
@@ -51,6 +53,8 @@ type WarehouseClient interface {
 	RetrieveFailedBatches(ctx context.Context, in *RetrieveFailedBatchesRequest, opts ...grpc.CallOption) (*RetrieveFailedBatchesResponse, error)
 	RetryFailedBatches(ctx context.Context, in *RetryFailedBatchesRequest, opts ...grpc.CallOption) (*RetryFailedBatchesResponse, error)
 	GetFirstAbortedUploadInContinuousAbortsByDestination(ctx context.Context, in *FirstAbortedUploadInContinuousAbortsByDestinationRequest, opts ...grpc.CallOption) (*FirstAbortedUploadInContinuousAbortsByDestinationResponse, error)
+	GetWHUploadSchemaChanges(ctx context.Context, in *WHUploadRequest, opts ...grpc.CallOption) (*WHUploadSchemaChangesResponse, error)
+	ReviewWHUploadSchemaChanges(ctx context.Context, in *ReviewWHUploadSchemaChangesRequest, opts ...grpc.CallOption) (*WHUploadSchemaChangesResponse, error)
 }
 
 type warehouseClient struct {
@@ -169,6 +173,24 @@ func (c *warehouseClient) GetFirstAbortedUploadInContinuousAbortsByDestination(c
 	return out, nil
 }
 
+func (c *warehouseClient) GetWHUploadSchemaChanges(ctx context.Context, in *WHUploadRequest, opts ...grpc.CallOption) (*WHUploadSchemaChangesResponse, error) {
+	out := new(WHUploadSchemaChangesResponse)
+	err := c.cc.Invoke(ctx, Warehouse_GetWHUploadSchemaChanges_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *warehouseClient) ReviewWHUploadSchemaChanges(ctx context.Context, in *ReviewWHUploadSchemaChangesRequest, opts ...grpc.CallOption) (*WHUploadSchemaChangesResponse, error) {
+	out := new(WHUploadSchemaChangesResponse)
+	err := c.cc.Invoke(ctx, Warehouse_ReviewWHUploadSchemaChanges_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // WarehouseServer is the server API for Warehouse service.
 // All implementations must embed UnimplementedWarehouseServer
 // for forward compatibility
@@ -185,6 +207,8 @@ type WarehouseServer interface {
 	RetrieveFailedBatches(context.Context, *RetrieveFailedBatchesRequest) (*RetrieveFailedBatchesResponse, error)
 	RetryFailedBatches(context.Context, *RetryFailedBatchesRequest) (*RetryFailedBatchesResponse, error)
 	GetFirstAbortedUploadInContinuousAbortsByDestination(context.Context, *FirstAbortedUploadInContinuousAbortsByDestinationRequest) (*FirstAbortedUploadInContinuousAbortsByDestinationResponse, error)
+	GetWHUploadSchemaChanges(context.Context, *WHUploadRequest) (*WHUploadSchemaChangesResponse, error)
+	ReviewWHUploadSchemaChanges(context.Context, *ReviewWHUploadSchemaChangesRequest) (*WHUploadSchemaChangesResponse, error)
 	mustEmbedUnimplementedWarehouseServer()
 }
 
@@ -228,6 +252,12 @@ func (UnimplementedWarehouseServer) RetryFailedBatches(context.Context, *RetryFa
 func (UnimplementedWarehouseServer) GetFirstAbortedUploadInContinuousAbortsByDestination(context.Context, *FirstAbortedUploadInContinuousAbortsByDestinationRequest) (*FirstAbortedUploadInContinuousAbortsByDestinationResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetFirstAbortedUploadInContinuousAbortsByDestination not implemented")
 }
+func (UnimplementedWarehouseServer) GetWHUploadSchemaChanges(context.Context, *WHUploadRequest) (*WHUploadSchemaChangesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWHUploadSchemaChanges not implemented")
+}
+func (UnimplementedWarehouseServer) ReviewWHUploadSchemaChanges(context.Context, *ReviewWHUploadSchemaChangesRequest) (*WHUploadSchemaChangesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReviewWHUploadSchemaChanges not implemented")
+}
 func (UnimplementedWarehouseServer) mustEmbedUnimplementedWarehouseServer() {}
 
 // UnsafeWarehouseServer may be embedded to opt out of forward compatibility for this service.
@@ -457,6 +487,42 @@ func _Warehouse_GetFirstAbortedUploadInContinuousAbortsByDestination_Handler(srv
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Warehouse_GetWHUploadSchemaChanges_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WHUploadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WarehouseServer).GetWHUploadSchemaChanges(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Warehouse_GetWHUploadSchemaChanges_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WarehouseServer).GetWHUploadSchemaChanges(ctx, req.(*WHUploadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Warehouse_ReviewWHUploadSchemaChanges_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReviewWHUploadSchemaChangesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WarehouseServer).ReviewWHUploadSchemaChanges(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Warehouse_ReviewWHUploadSchemaChanges_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WarehouseServer).ReviewWHUploadSchemaChanges(ctx, req.(*ReviewWHUploadSchemaChangesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Warehouse_ServiceDesc is the grpc.ServiceDesc for Warehouse service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -512,6 +578,14 @@ var Warehouse_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetFirstAbortedUploadInContinuousAbortsByDestination",
 			Handler:    _Warehouse_GetFirstAbortedUploadInContinuousAbortsByDestination_Handler,
 		},
+		{
+			MethodName: "GetWHUploadSchemaChanges",
+			Handler:    _Warehouse_GetWHUploadSchemaChanges_Handler,
+		},
+		{
+			MethodName: "ReviewWHUploadSchemaChanges",
+			Handler:    _Warehouse_ReviewWHUploadSchemaChanges_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/warehouse/warehouse.proto",
@@ -0,0 +1,85 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/rudderlabs/rudder-server/internal/dlq"
+	"github.com/rudderlabs/rudder-server/jobsdb"
+	routerutils "github.com/rudderlabs/rudder-server/router/utils"
+	utilTypes "github.com/rudderlabs/rudder-server/utils/types"
+)
+
+// AdminAbortHook returns the hook handling the jobs of a router's jobsdb which are aborted through the jobsdb admin,
+// the same way the router (or batch router, depending on pu) handles the jobs it aborts:
+// aborted jobs are stored into the error db, reported and copied into the dead-letter queue, if any.
+func AdminAbortHook(pu string, jobsDB, errorDB jobsdb.JobsDB, reporting utilTypes.Reporting, deadLetterQueue dlq.Writer) jobsdb.AbortHook {
+	return func(ctx context.Context, tx jobsdb.UpdateSafeTx, jobs []*jobsdb.JobT, statuses []*jobsdb.JobStatusT) error {
+		if len(jobs) == 0 {
+			return nil
+		}
+		if err := errorDB.Store(ctx, jobs); err != nil {
+			return fmt.Errorf("storing aborted jobs into error db: %w", err)
+		}
+
+		connectionDetailsMap := make(map[string]*utilTypes.ConnectionDetails)
+		statusDetailsMap := make(map[string]*utilTypes.StatusDetail)
+		transformedAtMap := make(map[string]string)
+		var dlqEntries []dlq.Entry
+		for i, job := range jobs {
+			status := statuses[i]
+			sourceID := gjson.GetBytes(job.Parameters, "source_id").String()
+			destinationID := gjson.GetBytes(job.Parameters, "destination_id").String()
+			sourceJobRunID := gjson.GetBytes(job.Parameters, "source_job_run_id").String()
+			eventName := gjson.GetBytes(job.Parameters, "event_name").String()
+			eventType := gjson.GetBytes(job.Parameters, "event_type").String()
+			key := fmt.Sprintf("%s:%s:%s:%s:%s:%s:%s", sourceID, destinationID, sourceJobRunID, status.JobState, status.ErrorCode, eventName, eventType)
+			if _, ok := connectionDetailsMap[key]; !ok {
+				connectionDetailsMap[key] = utilTypes.CreateConnectionDetail(sourceID, destinationID,
+					gjson.GetBytes(job.Parameters, "source_task_run_id").String(),
+					gjson.GetBytes(job.Parameters, "source_job_id").String(),
+					sourceJobRunID,
+					gjson.GetBytes(job.Parameters, "source_definition_id").String(),
+					gjson.GetBytes(job.Parameters, "destination_definition_id").String(),
+					gjson.GetBytes(job.Parameters, "source_category").String(),
+					"", "", "", 0)
+				transformedAtMap[key] = gjson.GetBytes(job.Parameters, "transform_at").String()
+			}
+			sd, ok := statusDetailsMap[key]
+			if !ok {
+				errorCode, _ := strconv.Atoi(status.ErrorCode)
+				sd = utilTypes.CreateStatusDetail(status.JobState, 0, 0, errorCode, string(status.ErrorResponse), routerutils.EmptyPayload, eventName, eventType, "")
+				statusDetailsMap[key] = sd
+			}
+			sd.Count++
+			if deadLetterQueue != nil {
+				dlqEntries = append(dlqEntries, dlq.NewEntry(jobsDB.Identifier(), job, status))
+			}
+		}
+
+		reportMetrics := make([]*utilTypes.PUReportedMetric, 0, len(connectionDetailsMap))
+		for k, cd := range connectionDetailsMap {
+			inPu := utilTypes.EVENT_FILTER
+			if transformedAtMap[k] == "processor" {
+				inPu = utilTypes.DEST_TRANSFORMER
+			}
+			reportMetrics = append(reportMetrics, &utilTypes.PUReportedMetric{
+				ConnectionDetails: *cd,
+				PUDetails:         *utilTypes.CreatePUDetails(inPu, pu, true, false),
+				StatusDetail:      statusDetailsMap[k],
+			})
+		}
+		if err := reporting.Report(ctx, reportMetrics, tx.Tx()); err != nil {
+			return fmt.Errorf("reporting metrics: %w", err)
+		}
+		if deadLetterQueue != nil {
+			if err := deadLetterQueue.StoreInTx(ctx, tx.Tx(), dlqEntries); err != nil {
+				return fmt.Errorf("storing aborted jobs into dead-letter queue: %w", err)
+			}
+		}
+		return nil
+	}
+}
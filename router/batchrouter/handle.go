@@ -29,6 +29,7 @@ import (
 	kitsync "github.com/rudderlabs/rudder-go-kit/sync"
 	obskit "github.com/rudderlabs/rudder-observability-kit/go/labels"
 	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
+	"github.com/rudderlabs/rudder-server/internal/dlq"
 	"github.com/rudderlabs/rudder-server/jobsdb"
 	asynccommon "github.com/rudderlabs/rudder-server/router/batchrouter/asyncdestinationmanager/common"
 	"github.com/rudderlabs/rudder-server/router/batchrouter/isolation"
@@ -66,6 +67,7 @@ type Handle struct {
 	debugger           destinationdebugger.DestinationDebugger
 	Diagnostics        diagnostics.DiagnosticsI
 	adaptiveLimit      func(int64) int64
+	deadLetterQueue    dlq.Writer // optional, aborted jobs are copied into the dead-letter queue if set
 	isolationStrategy  isolation.Strategy
 	now                func() time.Time
 
@@ -801,7 +803,7 @@ func (brt *Handle) updateJobStatus(batchJobs *BatchedJobs, isWarehouse bool, err
 					return fmt.Errorf("reporting metrics: %w", err)
 				}
 			}
-			return nil
+			return brt.storeDeadLetterEntriesInTx(ctx, tx, batchJobs.Jobs, statusList)
 		})
 	}, brt.sendRetryUpdateStats)
 	if err != nil {
@@ -811,6 +813,30 @@ func (brt *Handle) updateJobStatus(batchJobs *BatchedJobs, isWarehouse bool, err
 	sendDestStatusStats(batchJobs.Connection, jobStateCounts, brt.destType, isWarehouse)
 }
 
+// storeDeadLetterEntriesInTx copies the aborted jobs of the status list into the dead-letter queue, if one is configured.
+// Drained and stale jobs are not eligible for replaying, thus they are not copied.
+func (brt *Handle) storeDeadLetterEntriesInTx(ctx context.Context, tx jobsdb.UpdateSafeTx, jobs []*jobsdb.JobT, statusList []*jobsdb.JobStatusT) error {
+	if brt.deadLetterQueue == nil {
+		return nil
+	}
+	jobsByID := lo.SliceToMap(jobs, func(job *jobsdb.JobT) (int64, *jobsdb.JobT) {
+		return job.JobID, job
+	})
+	var entries []dlq.Entry
+	for _, status := range statusList {
+		if status.JobState != jobsdb.Aborted.State || status.ErrorCode == routerutils.DRAIN_ERROR_CODE || status.ErrorCode == routerutils.STALE_EVENT_ERROR_CODE {
+			continue
+		}
+		if job, ok := jobsByID[status.JobID]; ok {
+			entries = append(entries, dlq.NewEntry(brt.jobsDB.Identifier(), job, status))
+		}
+	}
+	if err := brt.deadLetterQueue.StoreInTx(ctx, tx.Tx(), entries); err != nil {
+		return fmt.Errorf("storing aborted jobs into dead-letter queue: %w", err)
+	}
+	return nil
+}
+
 // uploadInterval calculates the upload interval for the destination
 func (brt *Handle) uploadInterval(destinationConfig map[string]interface{}) time.Duration {
 	uploadInterval, ok := destinationConfig["uploadInterval"]
@@ -64,6 +64,9 @@ func (brt *Handle) updateJobStatuses(ctx context.Context, destinationID string,
 					return fmt.Errorf("reporting metrics: %w", err)
 				}
 			}
+			if err = brt.storeDeadLetterEntriesInTx(ctx, tx, allJobs, statusList); err != nil {
+				return err
+			}
 			tx.Tx().AddSuccessListener(func() {
 				for _, job := range completedJobs {
 					rmetrics.DecreasePendingEvents(
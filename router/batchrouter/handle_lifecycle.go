@@ -34,6 +34,7 @@ import (
 	destinationdebugger "github.com/rudderlabs/rudder-server/services/debugger/destination"
 	"github.com/rudderlabs/rudder-server/services/diagnostics"
 	"github.com/rudderlabs/rudder-server/services/rsources"
+	"github.com/rudderlabs/rudder-server/services/secrets"
 	"github.com/rudderlabs/rudder-server/services/transientsource"
 	"github.com/rudderlabs/rudder-server/utils/crash"
 	"github.com/rudderlabs/rudder-server/utils/misc"
@@ -55,7 +56,7 @@ func (brt *Handle) Setup(
 ) {
 	brt.destType = destType
 	brt.backendConfig = backendConfig
-	brt.logger = logger.NewLogger().Child("batchrouter").Child(destType)
+	brt.logger = secrets.NewRedactingLogger(logger.NewLogger().Child("batchrouter").Child(destType))
 
 	brt.netHandle = &http.Client{
 		Transport: &http.Transport{},
@@ -0,0 +1,82 @@
+package circuitbreaker
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Admin exposes the router's circuit breakers over the admin rpc interface
+type Admin struct {
+	registry *Registry
+}
+
+// NewAdmin returns the admin handler of the circuit breakers
+func NewAdmin(registry *Registry) *Admin {
+	return &Admin{registry: registry}
+}
+
+// Status returns the status of the destination's circuit breaker, or of all circuit breakers if no destination id is provided
+func (a *Admin) Status(destinationID string, reply *string) error {
+	var v any = a.registry.Statuses()
+	if destinationID != "" {
+		status, ok := a.registry.Status(destinationID)
+		if !ok {
+			return fmt.Errorf("no circuit breaker for destination %q", destinationID)
+		}
+		v = status
+	}
+	formattedOutput, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	*reply = string(formattedOutput)
+	return nil
+}
+
+// Reset closes the destination's circuit breaker
+func (a *Admin) Reset(destinationID string, reply *string) error {
+	if !a.registry.Reset(destinationID) {
+		return fmt.Errorf("no circuit breaker for destination %q", destinationID)
+	}
+	*reply = fmt.Sprintf("Circuit breaker of destination %q was reset", destinationID)
+	return nil
+}
+
+// HttpHandler returns the http handler of the circuit breakers
+//
+//   - GET / - lists the status of all circuit breakers
+//   - GET /{destinationId} - returns the status of the destination's circuit breaker
+//   - POST /{destinationId}/reset - closes the destination's circuit breaker
+func (a *Admin) HttpHandler() http.Handler {
+	srvMux := chi.NewRouter()
+	srvMux.Get("/", a.listStatuses)
+	srvMux.Get("/{destinationId}", a.getStatus)
+	srvMux.Post("/{destinationId}/reset", a.reset)
+	return srvMux
+}
+
+func (a *Admin) listStatuses(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(a.registry.Statuses())
+}
+
+func (a *Admin) getStatus(w http.ResponseWriter, r *http.Request) {
+	status, ok := a.registry.Status(chi.URLParam(r, "destinationId"))
+	if !ok {
+		http.Error(w, "circuit breaker not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(status)
+}
+
+func (a *Admin) reset(w http.ResponseWriter, r *http.Request) {
+	if !a.registry.Reset(chi.URLParam(r, "destinationId")) {
+		http.Error(w, "circuit breaker not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,226 @@
+// Package circuitbreaker provides per-destination circuit breakers for the router.
+//
+// A destination's breaker opens when its failure ratio over a rolling window exceeds the configured threshold.
+// While open, the router parks the destination and doesn't pick up any of its jobs. After the open timeout the breaker
+// becomes half-open and lets a limited number of probe requests through: the breaker closes if all of them succeed, otherwise it opens again.
+// Requests are gated by [Breaker.Allow] before being sent, so that no request gets through while the breaker is open, or exceeds the probes while half-open.
+package circuitbreaker
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sony/gobreaker"
+
+	"github.com/rudderlabs/rudder-go-kit/config"
+	"github.com/rudderlabs/rudder-go-kit/logger"
+	"github.com/rudderlabs/rudder-go-kit/stats"
+)
+
+// NewRegistry returns a new registry of circuit breakers, configured through the Router[.<destType>].circuitBreaker.* config keys
+func NewRegistry(conf *config.Config, log logger.Logger, stat stats.Stats) *Registry {
+	return &Registry{
+		conf:     conf,
+		log:      log.Child("circuitbreaker"),
+		stats:    stat,
+		enabled:  make(map[string]config.ValueLoader[bool]),
+		breakers: make(map[string]*Breaker),
+	}
+}
+
+// Registry keeps track of the circuit breakers of all destinations.
+// A nil registry is valid and behaves as if circuit breakers were disabled.
+type Registry struct {
+	conf  *config.Config
+	log   logger.Logger
+	stats stats.Stats
+
+	mu       sync.Mutex
+	enabled  map[string]config.ValueLoader[bool] // destType -> whether circuit breakers are enabled
+	breakers map[string]*Breaker                 // destinationID -> breaker
+}
+
+// Get returns the circuit breaker of the destination, creating it if needed.
+// It returns nil if circuit breakers are disabled for the destination type, which is a valid, always closed breaker.
+func (r *Registry) Get(destType, destinationID string) *Breaker {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	enabled, ok := r.enabled[destType]
+	if !ok {
+		enabled = r.conf.GetReloadableBoolVar(false, "Router."+destType+".circuitBreaker.enabled", "Router.circuitBreaker.enabled")
+		r.enabled[destType] = enabled
+	}
+	if !enabled.Load() {
+		return nil
+	}
+	if b, ok := r.breakers[destinationID]; ok {
+		return b
+	}
+	b := r.newBreaker(destType, destinationID)
+	r.breakers[destinationID] = b
+	return b
+}
+
+// Reset resets the circuit breaker of the destination back to its closed state.
+// It returns false if there is no circuit breaker for the destination.
+func (r *Registry) Reset(destinationID string) bool {
+	if r == nil {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b, ok := r.breakers[destinationID]
+	if !ok {
+		return false
+	}
+	r.log.Infon("Resetting circuit breaker", logger.NewStringField("destinationId", destinationID))
+	r.breakers[destinationID] = r.newBreaker(b.destType, destinationID)
+	return true
+}
+
+// Statuses returns the status of all circuit breakers, sorted by destination id
+func (r *Registry) Statuses() []Status {
+	if r == nil {
+		return []Status{}
+	}
+	r.mu.Lock()
+	breakers := make([]*Breaker, 0, len(r.breakers))
+	for _, b := range r.breakers {
+		breakers = append(breakers, b)
+	}
+	r.mu.Unlock()
+	statuses := make([]Status, 0, len(breakers))
+	for _, b := range breakers {
+		statuses = append(statuses, b.Status())
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].DestinationID < statuses[j].DestinationID })
+	return statuses
+}
+
+// Status returns the status of the destination's circuit breaker and false if there is no circuit breaker for it
+func (r *Registry) Status(destinationID string) (Status, bool) {
+	if r == nil {
+		return Status{}, false
+	}
+	r.mu.Lock()
+	b, ok := r.breakers[destinationID]
+	r.mu.Unlock()
+	if !ok {
+		return Status{}, false
+	}
+	return b.Status(), true
+}
+
+func (r *Registry) newBreaker(destType, destinationID string) *Breaker {
+	keys := func(key string) []string {
+		return []string{"Router." + destType + ".circuitBreaker." + key, "Router.circuitBreaker." + key}
+	}
+	failureRatio := r.conf.GetFloat64Var(0.5, keys("failureRatio")...)
+	minRequests := r.conf.GetIntVar(20, 1, keys("minRequests")...)
+	b := &Breaker{
+		destType:      destType,
+		destinationID: destinationID,
+		probes:        r.conf.GetIntVar(5, 1, keys("halfOpenProbes")...),
+	}
+	tags := stats.Tags{"destType": destType, "destinationId": destinationID}
+	stateStat := r.stats.NewTaggedStat("router_circuit_breaker_state", stats.GaugeType, tags)
+	b.cb = gobreaker.NewTwoStepCircuitBreaker(gobreaker.Settings{
+		Name:        destinationID,
+		MaxRequests: uint32(b.probes),
+		Interval:    r.conf.GetDurationVar(1, time.Minute, keys("interval")...),
+		Timeout:     r.conf.GetDurationVar(1, time.Minute, keys("openTimeout")...),
+		ReadyToTrip: func(counts gobreaker.Counts) bool {
+			return counts.Requests >= uint32(minRequests) && float64(counts.TotalFailures)/float64(counts.Requests) >= failureRatio
+		},
+		OnStateChange: func(_ string, from, to gobreaker.State) {
+			r.log.Infon("Circuit breaker state changed",
+				logger.NewStringField("destType", destType),
+				logger.NewStringField("destinationId", destinationID),
+				logger.NewStringField("from", from.String()),
+				logger.NewStringField("to", to.String()),
+			)
+			stateStat.Gauge(int(to))
+			r.stats.NewTaggedStat("router_circuit_breaker_state_changes", stats.CountType, stats.Tags{
+				"destType":      destType,
+				"destinationId": destinationID,
+				"from":          from.String(),
+				"to":            to.String(),
+			}).Increment()
+		},
+	})
+	stateStat.Gauge(int(gobreaker.StateClosed))
+	return b
+}
+
+// Breaker is the circuit breaker of a single destination.
+// A nil breaker is valid and always closed.
+type Breaker struct {
+	destType      string
+	destinationID string
+	probes        int
+	cb            *gobreaker.TwoStepCircuitBreaker
+}
+
+// Open returns true if the breaker is open, i.e. no requests should be sent to the destination
+func (b *Breaker) Open() bool {
+	return b != nil && b.cb.State() == gobreaker.StateOpen
+}
+
+// HalfOpen returns true if the breaker is half-open, i.e. only a limited number of probe requests should be sent to the destination
+func (b *Breaker) HalfOpen() bool {
+	return b != nil && b.cb.State() == gobreaker.StateHalfOpen
+}
+
+// Probes returns the number of probe requests allowed while the breaker is half-open
+func (b *Breaker) Probes() int {
+	if b == nil {
+		return 0
+	}
+	return b.probes
+}
+
+// Allow checks whether a request can be sent to the destination, returning an error if the breaker is open,
+// or if it is half-open and all of its probes are already in flight.
+// Otherwise, the outcome of the request needs to be recorded through the returned done function once the request completes.
+func (b *Breaker) Allow() (done func(success bool), err error) {
+	if b == nil {
+		return func(bool) {}, nil
+	}
+	done, err = b.cb.Allow()
+	if err != nil {
+		return nil, fmt.Errorf("circuit breaker of destination %s: %w", b.destinationID, err)
+	}
+	return done, nil
+}
+
+// Status returns the current status of the breaker
+func (b *Breaker) Status() Status {
+	counts := b.cb.Counts()
+	return Status{
+		DestinationID:        b.destinationID,
+		DestType:             b.destType,
+		State:                b.cb.State().String(),
+		Requests:             counts.Requests,
+		TotalSuccesses:       counts.TotalSuccesses,
+		TotalFailures:        counts.TotalFailures,
+		ConsecutiveSuccesses: counts.ConsecutiveSuccesses,
+		ConsecutiveFailures:  counts.ConsecutiveFailures,
+	}
+}
+
+// Status is the status of a destination's circuit breaker, along with its request counts in the current window
+type Status struct {
+	DestinationID        string `json:"destinationId"`
+	DestType             string `json:"destType"`
+	State                string `json:"state"`
+	Requests             uint32 `json:"requests"`
+	TotalSuccesses       uint32 `json:"totalSuccesses"`
+	TotalFailures        uint32 `json:"totalFailures"`
+	ConsecutiveSuccesses uint32 `json:"consecutiveSuccesses"`
+	ConsecutiveFailures  uint32 `json:"consecutiveFailures"`
+}
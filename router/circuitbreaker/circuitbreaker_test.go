@@ -0,0 +1,107 @@
+package circuitbreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sony/gobreaker"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rudderlabs/rudder-go-kit/config"
+	"github.com/rudderlabs/rudder-go-kit/logger"
+	"github.com/rudderlabs/rudder-go-kit/stats"
+	"github.com/rudderlabs/rudder-go-kit/stats/memstats"
+)
+
+func TestRegistry(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		var nilRegistry *Registry
+		require.Nil(t, nilRegistry.Get("WEBHOOK", "dest-1"))
+		require.Empty(t, nilRegistry.Statuses())
+
+		r := NewRegistry(config.New(), logger.NOP, stats.NOP)
+		b := r.Get("WEBHOOK", "dest-1")
+		require.Nil(t, b)
+		done, err := b.Allow()
+		require.NoError(t, err)
+		done(false)
+		require.False(t, b.Open())
+		require.False(t, b.HalfOpen())
+	})
+
+	t.Run("toggled at runtime", func(t *testing.T) {
+		c := config.New()
+		r := NewRegistry(c, logger.NOP, stats.NOP)
+		require.Nil(t, r.Get("WEBHOOK", "dest-1"))
+
+		c.Set("Router.circuitBreaker.enabled", true)
+		require.NotNil(t, r.Get("WEBHOOK", "dest-1"))
+
+		c.Set("Router.WEBHOOK.circuitBreaker.enabled", false)
+		require.Nil(t, r.Get("WEBHOOK", "dest-1"))
+		require.NotNil(t, r.Get("AM", "dest-2"))
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		c := config.New()
+		c.Set("Router.WEBHOOK.circuitBreaker.enabled", true)
+		c.Set("Router.circuitBreaker.minRequests", 4)
+		c.Set("Router.circuitBreaker.failureRatio", 0.5)
+		c.Set("Router.circuitBreaker.openTimeout", "100ms")
+		c.Set("Router.circuitBreaker.halfOpenProbes", 2)
+		statsStore, err := memstats.New()
+		require.NoError(t, err)
+		r := NewRegistry(c, logger.NOP, statsStore)
+		require.Nil(t, r.Get("AM", "dest-2"), "circuit breakers are only enabled for WEBHOOK")
+
+		b := r.Get("WEBHOOK", "dest-1")
+		require.NotNil(t, b)
+		require.Same(t, b, r.Get("WEBHOOK", "dest-1"))
+		stateTags := stats.Tags{"destType": "WEBHOOK", "destinationId": "dest-1"}
+
+		record := func(success bool) {
+			t.Helper()
+			done, err := b.Allow()
+			require.NoError(t, err)
+			done(success)
+		}
+
+		record(true)
+		record(true)
+		record(false)
+		require.False(t, b.Open(), "the breaker doesn't open before the minimum number of requests")
+		record(false)
+		require.True(t, b.Open(), "the breaker opens once the failure ratio is reached")
+		_, err = b.Allow()
+		require.ErrorIs(t, err, gobreaker.ErrOpenState, "no requests are allowed while the breaker is open")
+		require.EqualValues(t, 2, statsStore.Get("router_circuit_breaker_state", stateTags).LastValue())
+
+		require.Eventually(t, b.HalfOpen, time.Second, 10*time.Millisecond, "the breaker becomes half-open after the open timeout")
+		require.Equal(t, 2, b.Probes())
+		probe1, err := b.Allow()
+		require.NoError(t, err)
+		probe2, err := b.Allow()
+		require.NoError(t, err)
+		_, err = b.Allow()
+		require.ErrorIs(t, err, gobreaker.ErrTooManyRequests, "no more requests than the probes are allowed while the breaker is half-open")
+		probe1(true)
+		require.True(t, b.HalfOpen())
+		probe2(true)
+		require.False(t, b.Open())
+		require.False(t, b.HalfOpen(), "the breaker closes once all probes succeed")
+		require.EqualValues(t, 0, statsStore.Get("router_circuit_breaker_state", stateTags).LastValue())
+
+		for i := 0; i < 4; i++ {
+			record(false)
+		}
+		require.True(t, b.Open())
+		status, ok := r.Status("dest-1")
+		require.True(t, ok)
+		require.Equal(t, "open", status.State)
+		require.Len(t, r.Statuses(), 1)
+
+		require.True(t, r.Reset("dest-1"))
+		require.False(t, r.Get("WEBHOOK", "dest-1").Open(), "the breaker is closed after a reset")
+		require.False(t, r.Reset("dest-3"))
+	})
+}
@@ -6,7 +6,9 @@ import (
 	"github.com/rudderlabs/rudder-go-kit/config"
 	"github.com/rudderlabs/rudder-go-kit/logger"
 	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
+	"github.com/rudderlabs/rudder-server/internal/dlq"
 	"github.com/rudderlabs/rudder-server/jobsdb"
+	"github.com/rudderlabs/rudder-server/router/circuitbreaker"
 	"github.com/rudderlabs/rudder-server/router/throttler"
 	destinationdebugger "github.com/rudderlabs/rudder-server/services/debugger/destination"
 	"github.com/rudderlabs/rudder-server/services/rsources"
@@ -28,12 +30,16 @@ type Factory struct {
 	ThrottlerFactory           throttler.Factory
 	Debugger                   destinationdebugger.DestinationDebugger
 	AdaptiveLimit              func(int64) int64
+	DeadLetterQueue            dlq.Writer
+	CircuitBreakers            *circuitbreaker.Registry
 }
 
 func (f *Factory) New(destination *backendconfig.DestinationT) *Handle {
 	r := &Handle{
-		Reporting:     f.Reporting,
-		adaptiveLimit: f.AdaptiveLimit,
+		Reporting:       f.Reporting,
+		adaptiveLimit:   f.AdaptiveLimit,
+		deadLetterQueue: f.DeadLetterQueue,
+		circuitBreakers: f.CircuitBreakers,
 	}
 	r.Setup(
 		destination.DestinationDefinition,
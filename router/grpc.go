@@ -0,0 +1,317 @@
+package router
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/rudderlabs/rudder-go-kit/logger"
+	obskit "github.com/rudderlabs/rudder-observability-kit/go/labels"
+	"github.com/rudderlabs/rudder-server/processor/integrations"
+	"github.com/rudderlabs/rudder-server/router/utils"
+)
+
+// grpcStatusCodes maps gRPC status codes to the status codes driving the router's retry & abort semantics:
+// errors which won't go away by retrying are mapped to 4xx status codes, whereas transient ones are mapped to 5xx or 429.
+var grpcStatusCodes = map[codes.Code]int{
+	codes.OK:                 http.StatusOK,
+	codes.Canceled:           http.StatusGatewayTimeout,
+	codes.Unknown:            http.StatusInternalServerError,
+	codes.InvalidArgument:    http.StatusBadRequest,
+	codes.DeadlineExceeded:   http.StatusGatewayTimeout,
+	codes.NotFound:           http.StatusNotFound,
+	codes.AlreadyExists:      http.StatusConflict,
+	codes.PermissionDenied:   http.StatusForbidden,
+	codes.ResourceExhausted:  http.StatusTooManyRequests,
+	codes.FailedPrecondition: http.StatusBadRequest,
+	codes.Aborted:            http.StatusInternalServerError,
+	codes.OutOfRange:         http.StatusBadRequest,
+	codes.Unimplemented:      http.StatusBadRequest,
+	codes.Internal:           http.StatusInternalServerError,
+	codes.Unavailable:        http.StatusServiceUnavailable,
+	codes.DataLoss:           http.StatusInternalServerError,
+	codes.Unauthenticated:    http.StatusUnauthorized,
+}
+
+// grpcStatusCode returns the status code of a gRPC error, as per [grpcStatusCodes]
+func grpcStatusCode(err error) int {
+	if statusCode, ok := grpcStatusCodes[status.Code(err)]; ok {
+		return statusCode
+	}
+	return http.StatusInternalServerError
+}
+
+// grpcClient delivers transformed payloads of type GRPC by invoking unary gRPC methods with dynamic messages.
+//
+// The endpoint of the payload is the target of the gRPC server, with a grpcs:// scheme for TLS connections, and its grpcMethod
+// is the full name of the gRPC method, e.g. package.Service/Method. The JSON body of the payload is the request message,
+// encoded with the canonical protobuf JSON mapping, whereas headers are sent as metadata. Method descriptors are resolved
+// from the descriptor set configured through Router[.<destType>].grpc.descriptorSet, if any, otherwise they are resolved
+// through the server reflection service of the gRPC server.
+type grpcClient struct {
+	logger      logger.Logger
+	descriptors *protoregistry.Files // descriptors of the configured descriptor set, nil if none is configured
+
+	connsMu sync.Mutex
+	conns   map[string]*grpc.ClientConn // target -> connection
+
+	methodsMu sync.RWMutex
+	methods   map[string]protoreflect.MethodDescriptor // target + method -> descriptor resolved through server reflection
+}
+
+func newGRPCClient(log logger.Logger, descriptorSetPath string) (*grpcClient, error) {
+	c := &grpcClient{
+		logger:  log,
+		conns:   make(map[string]*grpc.ClientConn),
+		methods: make(map[string]protoreflect.MethodDescriptor),
+	}
+	if descriptorSetPath != "" {
+		data, err := os.ReadFile(descriptorSetPath)
+		if err != nil {
+			return nil, fmt.Errorf("reading descriptor set: %w", err)
+		}
+		var fds descriptorpb.FileDescriptorSet
+		if err := proto.Unmarshal(data, &fds); err != nil {
+			return nil, fmt.Errorf("unmarshalling descriptor set: %w", err)
+		}
+		if c.descriptors, err = protodesc.NewFiles(&fds); err != nil {
+			return nil, fmt.Errorf("building descriptor set files: %w", err)
+		}
+	}
+	return c, nil
+}
+
+// send invokes the gRPC method of the transformed payload and returns its response, encoded as JSON
+func (c *grpcClient) send(ctx context.Context, postInfo integrations.PostParametersT) *utils.SendPostResponse {
+	badRequest := func(err error) *utils.SendPostResponse {
+		return &utils.SendPostResponse{
+			StatusCode:   http.StatusBadRequest,
+			ResponseBody: []byte(fmt.Sprintf("400 Unable to construct grpc request: %v. Unexpected transformer response", err)),
+		}
+	}
+	service, method, ok := strings.Cut(strings.TrimPrefix(postInfo.GRPCMethod, "/"), "/")
+	if !ok || service == "" || method == "" {
+		return badRequest(fmt.Errorf("invalid method %q, expected package.Service/Method", postInfo.GRPCMethod))
+	}
+	bodyValue, _ := postInfo.Body["JSON"].(map[string]interface{})
+	if bodyValue == nil {
+		bodyValue = map[string]interface{}{}
+	}
+	body, err := json.Marshal(bodyValue)
+	if err != nil {
+		return badRequest(err)
+	}
+
+	conn, err := c.conn(postInfo.URL)
+	if err != nil {
+		return badRequest(err)
+	}
+	if len(postInfo.Headers) > 0 {
+		md := metadata.MD{}
+		for key, val := range postInfo.Headers {
+			md.Append(strings.ToLower(key), fmt.Sprint(val))
+		}
+		ctx = metadata.NewOutgoingContext(ctx, md)
+	}
+
+	md, err := c.methodDescriptor(ctx, conn, service, method)
+	if err != nil {
+		return &utils.SendPostResponse{
+			StatusCode:   grpcStatusCode(err),
+			ResponseBody: []byte(fmt.Sprintf("Unable to resolve grpc method %q of %q: %v", postInfo.GRPCMethod, postInfo.URL, err)),
+		}
+	}
+	req := dynamicpb.NewMessage(md.Input())
+	if err := protojson.Unmarshal(body, req); err != nil {
+		return badRequest(fmt.Errorf("request message of %q: %w", postInfo.GRPCMethod, err))
+	}
+	resp := dynamicpb.NewMessage(md.Output())
+	if err := conn.Invoke(ctx, "/"+service+"/"+method, req, resp); err != nil {
+		statusCode := grpcStatusCode(err)
+		if status.Code(err) == codes.Unimplemented {
+			c.forgetMethodDescriptor(conn, service, method) // the method might have been removed from the server
+		}
+		return &utils.SendPostResponse{
+			StatusCode:   statusCode,
+			ResponseBody: []byte(fmt.Sprintf("%d grpc method %q of %q failed: %v", statusCode, postInfo.GRPCMethod, postInfo.URL, err)),
+		}
+	}
+	respBody, err := protojson.Marshal(resp)
+	if err != nil {
+		respBody = []byte(fmt.Sprintf("Failed to marshal response of grpc method %q: %v", postInfo.GRPCMethod, err))
+	}
+	return &utils.SendPostResponse{
+		StatusCode:          http.StatusOK,
+		ResponseBody:        respBody,
+		ResponseContentType: "application/json",
+	}
+}
+
+// close closes the connections to all targets
+func (c *grpcClient) close() {
+	c.connsMu.Lock()
+	defer c.connsMu.Unlock()
+	for target, conn := range c.conns {
+		if err := conn.Close(); err != nil {
+			c.logger.Warnn("Closing grpc client", logger.NewStringField("target", target), obskit.Error(err))
+		}
+		delete(c.conns, target)
+	}
+}
+
+// conn returns the connection to the target, creating it if needed
+func (c *grpcClient) conn(target string) (*grpc.ClientConn, error) {
+	c.connsMu.Lock()
+	defer c.connsMu.Unlock()
+	if conn, ok := c.conns[target]; ok {
+		return conn, nil
+	}
+	creds := insecure.NewCredentials()
+	address := strings.TrimPrefix(target, "grpc://")
+	if strings.HasPrefix(target, "grpcs://") {
+		creds = credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+		address = strings.TrimPrefix(target, "grpcs://")
+	}
+	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("creating grpc client for %q: %w", target, err)
+	}
+	c.logger.Infon("Created grpc client", logger.NewStringField("target", target))
+	c.conns[target] = conn
+	return conn, nil
+}
+
+// methodDescriptor returns the descriptor of the method, either from the configured descriptor set or through server reflection
+func (c *grpcClient) methodDescriptor(ctx context.Context, conn *grpc.ClientConn, service, method string) (protoreflect.MethodDescriptor, error) {
+	if c.descriptors != nil {
+		return findMethodDescriptor(c.descriptors, service, method)
+	}
+	key := conn.Target() + "/" + service + "/" + method
+	c.methodsMu.RLock()
+	md, ok := c.methods[key]
+	c.methodsMu.RUnlock()
+	if ok {
+		return md, nil
+	}
+	files, err := reflectServiceFiles(ctx, conn, service)
+	if err != nil {
+		return nil, err
+	}
+	if md, err = findMethodDescriptor(files, service, method); err != nil {
+		return nil, err
+	}
+	c.methodsMu.Lock()
+	c.methods[key] = md
+	c.methodsMu.Unlock()
+	return md, nil
+}
+
+func (c *grpcClient) forgetMethodDescriptor(conn *grpc.ClientConn, service, method string) {
+	c.methodsMu.Lock()
+	defer c.methodsMu.Unlock()
+	delete(c.methods, conn.Target()+"/"+service+"/"+method)
+}
+
+// findMethodDescriptor looks up the descriptor of the service's method in the files
+func findMethodDescriptor(files *protoregistry.Files, service, method string) (protoreflect.MethodDescriptor, error) {
+	d, err := files.FindDescriptorByName(protoreflect.FullName(service))
+	if err != nil {
+		return nil, status.Errorf(codes.Unimplemented, "service %q: %v", service, err)
+	}
+	sd, ok := d.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, status.Errorf(codes.Unimplemented, "%q is not a service", service)
+	}
+	md := sd.Methods().ByName(protoreflect.Name(method))
+	if md == nil {
+		return nil, status.Errorf(codes.Unimplemented, "service %q has no method %q", service, method)
+	}
+	if md.IsStreamingClient() || md.IsStreamingServer() {
+		return nil, status.Errorf(codes.Unimplemented, "streaming method %q of service %q is not supported", method, service)
+	}
+	return md, nil
+}
+
+// reflectServiceFiles resolves the file descriptors of the service, along with their dependencies, through server reflection.
+// Dependencies that the server doesn't return are looked up in the descriptors linked into the binary, e.g. well-known types.
+func reflectServiceFiles(ctx context.Context, conn *grpc.ClientConn, service string) (*protoregistry.Files, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: service},
+	}); err != nil {
+		return nil, err
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	_ = stream.CloseSend()
+	if errResp := resp.GetErrorResponse(); errResp != nil {
+		return nil, status.Error(codes.Code(errResp.GetErrorCode()), errResp.GetErrorMessage())
+	}
+	fdps := make(map[string]*descriptorpb.FileDescriptorProto)
+	for _, data := range resp.GetFileDescriptorResponse().GetFileDescriptorProto() {
+		var fdp descriptorpb.FileDescriptorProto
+		if err := proto.Unmarshal(data, &fdp); err != nil {
+			return nil, status.Errorf(codes.Internal, "unmarshalling file descriptor: %v", err)
+		}
+		fdps[fdp.GetName()] = &fdp
+	}
+
+	files := new(protoregistry.Files)
+	var register func(name string) error
+	register = func(name string) error {
+		if _, err := files.FindFileByPath(name); err == nil {
+			return nil
+		}
+		fdp, ok := fdps[name]
+		if !ok {
+			fd, err := protoregistry.GlobalFiles.FindFileByPath(name)
+			if err != nil {
+				return fmt.Errorf("file %q: %w", name, err)
+			}
+			return files.RegisterFile(fd)
+		}
+		for _, dependency := range fdp.GetDependency() {
+			if err := register(dependency); err != nil {
+				return err
+			}
+		}
+		fd, err := protodesc.NewFile(fdp, files)
+		if err != nil {
+			return fmt.Errorf("file %q: %w", name, err)
+		}
+		return files.RegisterFile(fd)
+	}
+	for name := range fdps {
+		if err := register(name); err != nil {
+			return nil, status.Errorf(codes.Internal, "resolving file descriptors: %v", err)
+		}
+	}
+	return files, nil
+}
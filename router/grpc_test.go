@@ -0,0 +1,140 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/rudderlabs/rudder-go-kit/config"
+	"github.com/rudderlabs/rudder-go-kit/logger"
+	"github.com/rudderlabs/rudder-server/processor/integrations"
+)
+
+func TestSendPostWithGRPC(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("serving", healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+	reflection.Register(server)
+	go func() { _ = server.Serve(lis) }()
+	defer server.Stop()
+
+	grpcClient, err := newGRPCClient(logger.NOP, "")
+	require.NoError(t, err)
+	network := &netHandle{logger: logger.NOP, grpcClient: grpcClient}
+	postInfo := func(method string, body map[string]interface{}) integrations.PostParametersT {
+		return integrations.PostParametersT{
+			Type:       "GRPC",
+			URL:        "grpc://" + lis.Addr().String(),
+			GRPCMethod: method,
+			Headers:    map[string]interface{}{"X-Request-Id": "1"},
+			Body:       map[string]interface{}{"JSON": body},
+		}
+	}
+
+	t.Run("should invoke the method resolved through server reflection", func(t *testing.T) {
+		resp := network.SendPost(context.Background(), postInfo("grpc.health.v1.Health/Check", map[string]interface{}{"service": "serving"}))
+		require.Equal(t, http.StatusOK, resp.StatusCode, string(resp.ResponseBody))
+		require.Equal(t, "application/json", resp.ResponseContentType)
+		var body map[string]string
+		require.NoError(t, json.Unmarshal(resp.ResponseBody, &body))
+		require.Equal(t, "SERVING", body["status"])
+	})
+
+	t.Run("should map grpc status codes", func(t *testing.T) {
+		resp := network.SendPost(context.Background(), postInfo("/grpc.health.v1.Health/Check", map[string]interface{}{"service": "unknown"}))
+		require.Equal(t, http.StatusNotFound, resp.StatusCode, string(resp.ResponseBody))
+	})
+
+	t.Run("should fail with invalid requests", func(t *testing.T) {
+		for name, pi := range map[string]integrations.PostParametersT{
+			"invalid method":   postInfo("Check", nil),
+			"unknown method":   postInfo("grpc.health.v1.Health/Unknown", nil),
+			"unknown field":    postInfo("grpc.health.v1.Health/Check", map[string]interface{}{"unknown": "field"}),
+			"streaming method": postInfo("grpc.health.v1.Health/Watch", nil),
+		} {
+			t.Run(name, func(t *testing.T) {
+				resp := network.SendPost(context.Background(), pi)
+				require.Equal(t, http.StatusBadRequest, resp.StatusCode, string(resp.ResponseBody))
+			})
+		}
+	})
+
+	t.Run("should invoke the method resolved from the descriptor set", func(t *testing.T) {
+		fds := &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{
+			protodesc.ToFileDescriptorProto(healthpb.File_grpc_health_v1_health_proto),
+		}}
+		data, err := proto.Marshal(fds)
+		require.NoError(t, err)
+		descriptorSet := filepath.Join(t.TempDir(), "health.pb")
+		require.NoError(t, os.WriteFile(descriptorSet, data, 0o600))
+		grpcClient, err := newGRPCClient(logger.NOP, descriptorSet)
+		require.NoError(t, err)
+		network := &netHandle{logger: logger.NOP, grpcClient: grpcClient}
+
+		resp := network.SendPost(context.Background(), postInfo("grpc.health.v1.Health/Check", map[string]interface{}{"service": "serving"}))
+		require.Equal(t, http.StatusOK, resp.StatusCode, string(resp.ResponseBody))
+		resp = network.SendPost(context.Background(), postInfo("grpc.reflection.v1.ServerReflection/ServerReflectionInfo", nil))
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode, "only methods of the descriptor set can be invoked")
+	})
+
+	t.Run("should close connections", func(t *testing.T) {
+		grpcClient, err := newGRPCClient(logger.NOP, "")
+		require.NoError(t, err)
+		network := &netHandle{logger: logger.NOP, grpcClient: grpcClient}
+
+		resp := network.SendPost(context.Background(), postInfo("grpc.health.v1.Health/Check", map[string]interface{}{"service": "serving"}))
+		require.Equal(t, http.StatusOK, resp.StatusCode, string(resp.ResponseBody))
+		require.Len(t, grpcClient.conns, 1)
+
+		network.Close()
+		require.Empty(t, grpcClient.conns)
+	})
+
+	t.Run("should retry when the server is unavailable", func(t *testing.T) {
+		pi := postInfo("grpc.health.v1.Health/Check", nil)
+		pi.URL = "127.0.0.1:1"
+		resp := network.SendPost(context.Background(), pi)
+		require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode, string(resp.ResponseBody))
+	})
+}
+
+func TestGRPCStatusCode(t *testing.T) {
+	require.Equal(t, http.StatusTooManyRequests, grpcStatusCode(status.Error(codes.ResourceExhausted, "")))
+	require.Equal(t, http.StatusServiceUnavailable, grpcStatusCode(status.Error(codes.Unavailable, "")))
+	require.Equal(t, http.StatusBadRequest, grpcStatusCode(status.Error(codes.InvalidArgument, "")))
+	require.Equal(t, http.StatusInternalServerError, grpcStatusCode(context.Canceled), "non-status errors are retried")
+}
+
+func TestNetHandleSetupWithInvalidDescriptorSet(t *testing.T) {
+	descriptorSet := filepath.Join(t.TempDir(), "invalid.pb")
+	require.NoError(t, os.WriteFile(descriptorSet, []byte("invalid"), 0o600))
+	config.Reset()
+	defer config.Reset()
+	config.Set("Router.grpc.descriptorSet", descriptorSet)
+
+	network := &netHandle{logger: logger.NOP}
+	require.Error(t, network.Setup("DEST_TYPE", time.Second))
+	require.NotNil(t, network.httpClient, "http delivery should still be set up")
+
+	resp := network.SendPost(context.Background(), integrations.PostParametersT{Type: "GRPC", GRPCMethod: "grpc.health.v1.Health/Check"})
+	require.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+}
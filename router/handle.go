@@ -24,7 +24,9 @@ import (
 	"github.com/rudderlabs/rudder-go-kit/stats"
 	kitsync "github.com/rudderlabs/rudder-go-kit/sync"
 	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
+	"github.com/rudderlabs/rudder-server/internal/dlq"
 	"github.com/rudderlabs/rudder-server/jobsdb"
+	"github.com/rudderlabs/rudder-server/router/circuitbreaker"
 	customDestinationManager "github.com/rudderlabs/rudder-server/router/customdestinationmanager"
 	"github.com/rudderlabs/rudder-server/router/internal/eventorder"
 	"github.com/rudderlabs/rudder-server/router/internal/jobiterator"
@@ -60,6 +62,8 @@ type Handle struct {
 	transformerFeaturesService transformerFeaturesService.FeaturesService
 	debugger                   destinationdebugger.DestinationDebugger
 	adaptiveLimit              func(int64) int64
+	deadLetterQueue            dlq.Writer               // optional, aborted jobs are copied into the dead-letter queue if set
+	circuitBreakers            *circuitbreaker.Registry // optional, destinations are parked while their circuit breaker is open if set
 
 	// configuration
 	reloadableConfig                   *reloadableConfig
@@ -104,6 +108,7 @@ type Handle struct {
 	routerResponseTransformStat    stats.Measurement
 	throttlingErrorStat            stats.Measurement
 	throttledStat                  stats.Measurement
+	isolationMode                  isolation.Mode
 	isolationStrategy              isolation.Strategy
 	backgroundGroup                *errgroup.Group
 	backgroundCtx                  context.Context
@@ -114,6 +119,7 @@ type Handle struct {
 
 	eventOrderingDisabledForWorkspace   func(workspaceID string) bool
 	eventOrderingDisabledForDestination func(destinationID string) bool
+	maxEventAgeForDestination           func(destinationID string) time.Duration // events older than this are aborted instead of being delivered, 0 means no limit
 
 	limiter struct {
 		pickup    kitsync.Limiter
@@ -146,6 +152,10 @@ func (rt *Handle) activePartitions(ctx context.Context) []string {
 // pickup picks up jobs from the jobsDB for the provided partition and returns the number of jobs picked up and whether the limits were reached or not
 // picked up jobs are distributed to the workers
 func (rt *Handle) pickup(ctx context.Context, partition string, workers []*worker) (pickupCount int, limitsReached bool) {
+	if rt.skipFetchingJobs(partition) {
+		return 0, false
+	}
+
 	// pickup limiter with dynamic priority
 	start := time.Now()
 	var discardedCount int
@@ -175,8 +185,19 @@ func (rt *Handle) pickup(ctx context.Context, partition string, workers []*worke
 		"Router."+rt.destType+".jobIterator.discardedPercentageTolerance",
 		"Router.jobIterator.discardedPercentageTolerance")
 
+	pickupLimit := rt.reloadableConfig.jobQueryBatchSize.Load()
+	if rt.isolationMode == isolation.ModeDestination { // partitions are destinations, otherwise the probes are limited per destination by findWorkerSlot
+		if breaker := rt.circuitBreakers.Get(rt.destType, partition); breaker.HalfOpen() {
+			pickupLimit = min(pickupLimit, breaker.Probes()) // only pick up as many jobs as the probes allowed by the half-open breaker
+		}
+	}
+	queryParams, err := rt.getQueryParams(partition, pickupLimit)
+	if err != nil {
+		rt.logger.Errorf("[%v Router] :: Skipping the pickup of partition %q: %v", rt.destType, partition, err)
+		return 0, false
+	}
 	iterator := jobiterator.New(
-		rt.getQueryParams(partition, rt.reloadableConfig.jobQueryBatchSize.Load()),
+		queryParams,
 		rt.getJobsFn(ctx),
 		jobiterator.WithDiscardedPercentageTolerance(jobIteratorDiscardedPercentageTolerance),
 		jobiterator.WithMaxQueries(jobIteratorMaxQueries),
@@ -199,6 +220,7 @@ func (rt *Handle) pickup(ctx context.Context, partition string, workers []*worke
 	var statusList []*jobsdb.JobStatusT
 	var reservedJobs []reservedJob
 	blockedOrderKeys := make(map[eventorder.BarrierKey]struct{})
+	halfOpenProbes := make(map[string]int) // destinationID -> jobs picked up as probes of its half-open circuit breaker
 
 	flushTime := time.Now()
 	shouldFlush := func() bool {
@@ -244,7 +266,7 @@ func (rt *Handle) pickup(ctx context.Context, partition string, workers []*worke
 			firstJob = job
 		}
 		lastJob = job
-		workerJobSlot, err := rt.findWorkerSlot(ctx, workers, job, blockedOrderKeys)
+		workerJobSlot, err := rt.findWorkerSlot(ctx, workers, job, blockedOrderKeys, halfOpenProbes)
 		if err == nil {
 			traceParent := gjson.GetBytes(job.Parameters, "traceparent").String()
 			if traceParent != "" {
@@ -332,6 +354,7 @@ func (rt *Handle) commitStatusList(workerJobStatuses *[]workerJobStatus) {
 	var completedJobsList []*jobsdb.JobT
 	var statusList []*jobsdb.JobStatusT
 	var routerAbortedJobs []*jobsdb.JobT
+	var dlqEntries []dlq.Entry
 	jobIDConnectionDetailsMap := make(map[int64]jobsdb.ConnectionDetails)
 	for _, workerJobStatus := range *workerJobStatuses {
 		var parameters routerutils.JobParameters
@@ -384,6 +407,9 @@ func (rt *Handle) commitStatusList(workerJobStatuses *[]workerJobStatus) {
 			sd.FailedMessages = append(sd.FailedMessages, &utilTypes.FailedMessage{MessageID: parameters.MessageID, ReceivedAt: parameters.ParseReceivedAtTime()})
 			routerAbortedJobs = append(routerAbortedJobs, workerJobStatus.job)
 			completedJobsList = append(completedJobsList, workerJobStatus.job)
+			if rt.deadLetterQueue != nil && workerJobStatus.status.ErrorCode != routerutils.DRAIN_ERROR_CODE && workerJobStatus.status.ErrorCode != routerutils.STALE_EVENT_ERROR_CODE { // drained and stale jobs are not eligible for replaying
+				dlqEntries = append(dlqEntries, dlq.NewEntry(rt.jobsDB.Identifier(), workerJobStatus.job, workerJobStatus.status))
+			}
 		}
 
 		// REPORTING - ROUTER - END
@@ -461,6 +487,11 @@ func (rt *Handle) commitStatusList(workerJobStatuses *[]workerJobStatus) {
 				if err = rt.Reporting.Report(ctx, reportMetrics, tx.Tx()); err != nil {
 					return fmt.Errorf("reporting metrics: %w", err)
 				}
+				if rt.deadLetterQueue != nil {
+					if err = rt.deadLetterQueue.StoreInTx(ctx, tx.Tx(), dlqEntries); err != nil {
+						return fmt.Errorf("storing aborted jobs into dead-letter queue: %w", err)
+					}
+				}
 				return nil
 			})
 		}, rt.sendRetryStoreStats)
@@ -522,14 +553,25 @@ func (rt *Handle) getJobsFn(parentContext context.Context) func(context.Context,
 	}
 }
 
-func (rt *Handle) getQueryParams(partition string, pickUpCount int) jobsdb.GetQueryParams {
+// skipFetchingJobs returns true if the partition is a destination whose circuit breaker is open.
+// In the other isolation modes, partitions aren't destinations, so jobs of destinations with an open circuit breaker are skipped by findWorkerSlot instead.
+func (rt *Handle) skipFetchingJobs(partition string) bool {
+	if rt.isolationMode != isolation.ModeDestination {
+		return false
+	}
+	return rt.circuitBreakers.Get(rt.destType, partition).Open()
+}
+
+func (rt *Handle) getQueryParams(partition string, pickUpCount int) (jobsdb.GetQueryParams, error) {
 	params := jobsdb.GetQueryParams{
 		CustomValFilters: []string{rt.destType},
 		PayloadSizeLimit: rt.adaptiveLimit(rt.reloadableConfig.payloadLimit.Load()),
 		JobsLimit:        pickUpCount,
 	}
-	rt.isolationStrategy.AugmentQueryParams(partition, &params)
-	return params
+	if err := rt.isolationStrategy.AugmentQueryParams(partition, &params); err != nil {
+		return jobsdb.GetQueryParams{}, fmt.Errorf("augmenting query params: %w", err)
+	}
+	return params, nil
 }
 
 type workerJobSlot struct {
@@ -537,7 +579,21 @@ type workerJobSlot struct {
 	drainReason string
 }
 
-func (rt *Handle) findWorkerSlot(ctx context.Context, workers []*worker, job *jobsdb.JobT, blockedOrderKeys map[eventorder.BarrierKey]struct{}) (*workerJobSlot, error) {
+// circuitOpen returns true if no jobs of the destination can be picked up, because its circuit breaker is open,
+// or it is half-open and as many jobs as its probes have already been picked up
+func (rt *Handle) circuitOpen(destinationID string, halfOpenProbes map[string]int) bool {
+	breaker := rt.circuitBreakers.Get(rt.destType, destinationID)
+	return breaker.Open() || (breaker.HalfOpen() && halfOpenProbes[destinationID] >= breaker.Probes())
+}
+
+// pickedUpProbe keeps track of a job picked up while the circuit breaker of its destination is half-open
+func (rt *Handle) pickedUpProbe(destinationID string, halfOpenProbes map[string]int) {
+	if rt.circuitBreakers.Get(rt.destType, destinationID).HalfOpen() {
+		halfOpenProbes[destinationID]++
+	}
+}
+
+func (rt *Handle) findWorkerSlot(ctx context.Context, workers []*worker, job *jobsdb.JobT, blockedOrderKeys map[eventorder.BarrierKey]struct{}, halfOpenProbes map[string]int) (*workerJobSlot, error) {
 	if rt.backgroundCtx.Err() != nil {
 		return nil, types.ErrContextCancelled
 	}
@@ -582,11 +638,15 @@ func (rt *Handle) findWorkerSlot(ctx context.Context, workers []*worker, job *jo
 			slot.Release()
 			return nil, types.ErrJobBackoff
 		}
+		if rt.circuitOpen(parameters.DestinationID, halfOpenProbes) {
+			slot.Release()
+			return nil, types.ErrDestinationCircuitOpen
+		}
 		if rt.shouldThrottle(ctx, job, parameters) {
 			slot.Release()
 			return nil, types.ErrDestinationThrottled
 		}
-
+		rt.pickedUpProbe(parameters.DestinationID, halfOpenProbes)
 		return &workerJobSlot{slot: slot}, nil
 	}
 
@@ -602,6 +662,10 @@ func (rt *Handle) findWorkerSlot(ctx context.Context, workers []*worker, job *jo
 		blockedOrderKeys[orderKey] = struct{}{}
 		return nil, types.ErrJobBackoff
 	}
+	if !abortedJob && rt.circuitOpen(parameters.DestinationID, halfOpenProbes) {
+		blockedOrderKeys[orderKey] = struct{}{}
+		return nil, types.ErrDestinationCircuitOpen
+	}
 	worker := workers[getWorkerPartition(orderKey, len(workers))]
 	slot := worker.ReserveSlot()
 	if slot == nil {
@@ -626,6 +690,9 @@ func (rt *Handle) findWorkerSlot(ctx context.Context, workers []*worker, job *jo
 		slot.Release()
 		return nil, types.ErrDestinationThrottled
 	}
+	if !abortedJob {
+		rt.pickedUpProbe(parameters.DestinationID, halfOpenProbes)
+	}
 	return &workerJobSlot{slot: slot, drainReason: abortReason}, nil
 	//#EndJobOrder
 }
@@ -646,7 +713,7 @@ func (rt *Handle) drainOrRetryLimitReached(job *jobsdb.JobT) (bool, string) {
 func (rt *Handle) retryLimitReached(status *jobsdb.JobStatusT) bool {
 	respStatusCode, _ := strconv.Atoi(status.ErrorCode)
 	switch respStatusCode {
-	case types.RouterUnMarshalErrorCode: // 5xx errors
+	case types.RouterUnMarshalErrorCode, types.RouterCircuitOpenErrorCode: // 5xx errors which don't involve the destination
 		return false
 	}
 
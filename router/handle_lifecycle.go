@@ -18,6 +18,7 @@ import (
 	"github.com/rudderlabs/rudder-go-kit/logger"
 	"github.com/rudderlabs/rudder-go-kit/stats"
 	kitsync "github.com/rudderlabs/rudder-go-kit/sync"
+	obskit "github.com/rudderlabs/rudder-observability-kit/go/labels"
 	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
 	"github.com/rudderlabs/rudder-server/jobsdb"
 	customDestinationManager "github.com/rudderlabs/rudder-server/router/customdestinationmanager"
@@ -83,7 +84,9 @@ func (rt *Handle) Setup(
 	if rt.netHandle == nil {
 		netHandle := &netHandle{disableEgress: config.GetBool("disableEgress", false)}
 		netHandle.logger = rt.logger.Child("network")
-		netHandle.Setup(destType, rt.netClientTimeout)
+		if err := netHandle.Setup(destType, rt.netClientTimeout); err != nil {
+			rt.logger.Errorn("Setting up network handler, payloads of type GRPC won't be delivered", obskit.Error(err))
+		}
 		rt.netHandle = netHandle
 	}
 
@@ -131,6 +134,7 @@ func (rt *Handle) Setup(
 	rt.backendConfigInitialized = make(chan bool)
 
 	isolationMode := isolationMode(destType, config)
+	rt.isolationMode = isolationMode
 	var err error
 	if rt.isolationStrategy, err = isolation.GetStrategy(isolationMode, rt.destType, func(destinationID string) bool {
 		rt.destinationsMapMu.RLock()
@@ -147,6 +151,18 @@ func (rt *Handle) Setup(
 	rt.eventOrderingDisabledForDestination = func(destinationID string) bool {
 		return slices.Contains(config.GetStringSlice("Router.orderingDisabledDestinationIDs", nil), destinationID)
 	}
+	var maxEventAgesMu sync.Mutex
+	maxEventAges := make(map[string]interface{ Load() time.Duration }) // reloadable max event age per destination
+	rt.maxEventAgeForDestination = func(destinationID string) time.Duration {
+		maxEventAgesMu.Lock()
+		maxEventAge, ok := maxEventAges[destinationID]
+		if !ok {
+			maxEventAge = config.GetReloadableDurationVar(0, time.Second, "Router."+destinationID+".maxEventAge", "Router."+rt.destType+".maxEventAge", "Router.maxEventAge")
+			maxEventAges[destinationID] = maxEventAge
+		}
+		maxEventAgesMu.Unlock()
+		return maxEventAge.Load()
+	}
 	rt.barrier = eventorder.NewBarrier(eventorder.WithMetadata(map[string]string{
 		"destType":         rt.destType,
 		"batching":         strconv.FormatBool(rt.enableBatching),
@@ -382,6 +398,9 @@ func (rt *Handle) Shutdown() {
 
 	<-rt.startEnded // wait for all workers to stop first
 	rt.throttlerFactory.Shutdown()
+	if netHandle, ok := rt.netHandle.(*netHandle); ok {
+		netHandle.Close()
+	}
 	close(rt.responseQ) // now it is safe to close the response channel
 	_ = rt.backgroundWait()
 }
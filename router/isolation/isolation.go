@@ -16,6 +16,7 @@ const (
 	ModeNone        Mode = "none"
 	ModeWorkspace   Mode = "workspace"
 	ModeDestination Mode = "destination"
+	ModePartition   Mode = "partition"
 )
 
 // GetStrategy returns the strategy for the given isolation mode. An error is returned if the mode is invalid
@@ -27,6 +28,8 @@ func GetStrategy(mode Mode, customVal string, destinationFilter func(destination
 		return workspaceStrategy{customVal: customVal}, nil
 	case ModeDestination:
 		return destinationStrategy{destinationFilter: destinationFilter}, nil
+	case ModePartition:
+		return partitionStrategy{}, nil
 	default:
 		return noneStrategy{}, errors.New("unsupported isolation mode")
 	}
@@ -36,8 +39,9 @@ func GetStrategy(mode Mode, customVal string, destinationFilter func(destination
 type Strategy interface {
 	// ActivePartitions returns the list of partitions that are active for the given strategy
 	ActivePartitions(ctx context.Context, db jobsdb.JobsDB) ([]string, error)
-	// AugmentQueryParams augments the given GetQueryParamsT with the strategy specific parameters
-	AugmentQueryParams(partition string, params *jobsdb.GetQueryParams)
+	// AugmentQueryParams augments the given GetQueryParamsT with the strategy specific parameters,
+	// returning an error if the partition isn't valid for the strategy
+	AugmentQueryParams(partition string, params *jobsdb.GetQueryParams) error
 	// StopIteration returns true if the iteration should be stopped for the given error
 	StopIteration(err error) bool
 }
@@ -49,8 +53,8 @@ func (noneStrategy) ActivePartitions(_ context.Context, _ jobsdb.JobsDB) ([]stri
 	return []string{""}, nil
 }
 
-func (noneStrategy) AugmentQueryParams(_ string, _ *jobsdb.GetQueryParams) {
-	// no-op
+func (noneStrategy) AugmentQueryParams(_ string, _ *jobsdb.GetQueryParams) error {
+	return nil // no-op
 }
 
 func (noneStrategy) StopIteration(_ error) bool {
@@ -67,8 +71,9 @@ func (ws workspaceStrategy) ActivePartitions(ctx context.Context, db jobsdb.Jobs
 	return db.GetActiveWorkspaces(ctx, ws.customVal)
 }
 
-func (workspaceStrategy) AugmentQueryParams(partition string, params *jobsdb.GetQueryParams) {
+func (workspaceStrategy) AugmentQueryParams(partition string, params *jobsdb.GetQueryParams) error {
 	params.WorkspaceID = partition
+	return nil
 }
 
 func (workspaceStrategy) StopIteration(_ error) bool {
@@ -92,11 +97,36 @@ func (ds destinationStrategy) ActivePartitions(ctx context.Context, db jobsdb.Jo
 }
 
 // AugmentQueryParams augments the given GetQueryParamsT by adding the partition as sourceID parameter filter
-func (destinationStrategy) AugmentQueryParams(partition string, params *jobsdb.GetQueryParams) {
+func (destinationStrategy) AugmentQueryParams(partition string, params *jobsdb.GetQueryParams) error {
 	params.ParameterFilters = append(params.ParameterFilters, jobsdb.ParameterFilterT{Name: "destination_id", Value: partition})
+	return nil
 }
 
-// StopIteration returns true if the error is ErrDestinationThrottled
+// StopIteration returns true if the error is ErrDestinationThrottled or ErrDestinationCircuitOpen
 func (destinationStrategy) StopIteration(err error) bool {
-	return errors.Is(err, types.ErrDestinationThrottled)
+	return errors.Is(err, types.ErrDestinationThrottled) || errors.Is(err, types.ErrDestinationCircuitOpen)
+}
+
+// partitionStrategy implements isolation at partition range level
+type partitionStrategy struct{}
+
+// ActivePartitions returns the partition ranges of jobsdb
+func (partitionStrategy) ActivePartitions(_ context.Context, db jobsdb.JobsDB) ([]string, error) {
+	return lo.Map(db.PartitionRanges(), func(r jobsdb.PartitionRange, _ int) string {
+		return r.String()
+	}), nil
+}
+
+// AugmentQueryParams augments the given GetQueryParamsT by adding the partition as partition range
+func (partitionStrategy) AugmentQueryParams(partition string, params *jobsdb.GetQueryParams) error {
+	r, err := jobsdb.ParsePartitionRange(partition)
+	if err != nil {
+		return err
+	}
+	params.PartitionRange = &r
+	return nil
+}
+
+func (partitionStrategy) StopIteration(_ error) bool {
+	return false
 }
@@ -5,8 +5,10 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
 
 	"github.com/rudderlabs/rudder-server/jobsdb"
+	mocksJobsDB "github.com/rudderlabs/rudder-server/mocks/jobsdb"
 	"github.com/rudderlabs/rudder-server/router/isolation"
 	"github.com/rudderlabs/rudder-server/router/types"
 )
@@ -24,7 +26,7 @@ func TestIsolationStrategy(t *testing.T) {
 		t.Run("augment query params", func(t *testing.T) {
 			var params jobsdb.GetQueryParams
 			toAugment := params
-			strategy.AugmentQueryParams("partition", &toAugment)
+			require.NoError(t, strategy.AugmentQueryParams("partition", &toAugment))
 			require.Equal(t, params, toAugment)
 		})
 		t.Run("stop iteration", func(t *testing.T) {
@@ -38,7 +40,7 @@ func TestIsolationStrategy(t *testing.T) {
 
 		t.Run("augment query params", func(t *testing.T) {
 			var params jobsdb.GetQueryParams
-			strategy.AugmentQueryParams("partition", &params)
+			require.NoError(t, strategy.AugmentQueryParams("partition", &params))
 			var expected jobsdb.GetQueryParams
 			expected.WorkspaceID = "partition"
 			require.Equal(t, expected, params)
@@ -55,6 +57,35 @@ func TestIsolationStrategy(t *testing.T) {
 		t.Run("stop iteration", func(t *testing.T) {
 			require.False(t, strategy.StopIteration(types.ErrBarrierExists))
 			require.True(t, strategy.StopIteration(types.ErrDestinationThrottled))
+			require.True(t, strategy.StopIteration(types.ErrDestinationCircuitOpen))
+		})
+	})
+	t.Run("partition", func(r *testing.T) {
+		strategy, err := isolation.GetStrategy(isolation.ModePartition, "", func(_ string) bool { return true })
+		require.NoError(t, err)
+
+		t.Run("active partitions", func(t *testing.T) {
+			db := mocksJobsDB.NewMockJobsDB(gomock.NewController(t))
+			db.EXPECT().PartitionRanges().Return([]jobsdb.PartitionRange{{Start: 0, End: 32}, {Start: 32, End: 64}}).Times(1)
+			partitions, err := strategy.ActivePartitions(context.Background(), db)
+			require.NoError(t, err)
+			require.Equal(t, []string{"0-32", "32-64"}, partitions)
+		})
+		t.Run("augment query params", func(t *testing.T) {
+			var params jobsdb.GetQueryParams
+			require.NoError(t, strategy.AugmentQueryParams("32-64", &params))
+			var expected jobsdb.GetQueryParams
+			expected.PartitionRange = &jobsdb.PartitionRange{Start: 32, End: 64}
+			require.Equal(t, expected, params)
+		})
+		t.Run("augment query params with an invalid partition", func(t *testing.T) {
+			var params jobsdb.GetQueryParams
+			require.Error(t, strategy.AugmentQueryParams("partition", &params))
+			require.Nil(t, params.PartitionRange)
+		})
+		t.Run("stop iteration", func(t *testing.T) {
+			require.False(t, strategy.StopIteration(types.ErrBarrierExists))
+			require.False(t, strategy.StopIteration(types.ErrDestinationThrottled))
 		})
 	})
 }
@@ -7,16 +7,24 @@ import (
 	"compress/gzip"
 	"context"
 	"crypto/tls"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"mime"
+	"mime/multipart"
 	"net/http"
+	"net/textproto"
 	"net/url"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/samber/lo"
+	"github.com/tidwall/gjson"
+
+	"github.com/rudderlabs/rudder-go-kit/config"
 	"github.com/rudderlabs/rudder-go-kit/logger"
 	"github.com/rudderlabs/rudder-server/processor/integrations"
 	"github.com/rudderlabs/rudder-server/router/utils"
@@ -31,6 +39,7 @@ var contentTypeRegex = regexp.MustCompile(`^(text/[a-z0-9.-]+)|(application/([a-
 type netHandle struct {
 	disableEgress bool
 	httpClient    sysUtils.HTTPClientI
+	grpcClient    *grpcClient
 	logger        logger.Logger
 }
 
@@ -59,6 +68,10 @@ func handleQueryParam(param interface{}) string {
 
 // SendPost takes the EventPayload of a transformed job, gets the necessary values from the payload and makes a call to destination to push the event to it
 // this returns the statusCode, status and response body from the response of the destination call
+//
+// Transformed payloads of type REST are sent with the body format provided by the transformer, or as multipart/form-data
+// requests if they contain files. Payloads of type GRAPHQL are sent as GraphQL operations, with errors reported in the
+// response being mapped to retryable or terminal statuses. Payloads of type GRPC are sent as unary gRPC calls, see [grpcClient].
 func (network *netHandle) SendPost(ctx context.Context, structData integrations.PostParametersT) *utils.SendPostResponse {
 	if network.disableEgress {
 		return &utils.SendPostResponse{
@@ -66,182 +79,372 @@ func (network *netHandle) SendPost(ctx context.Context, structData integrations.
 			ResponseBody: []byte("200: outgoing disabled"),
 		}
 	}
+	if structData.Type == "GRPC" {
+		if network.grpcClient == nil {
+			return &utils.SendPostResponse{
+				StatusCode:   http.StatusInternalServerError,
+				ResponseBody: []byte("500 grpc delivery is not set up"),
+			}
+		}
+		return network.grpcClient.send(ctx, structData)
+	}
 	client := network.httpClient
 	postInfo := structData
 	isRest := postInfo.Type == "REST"
+	isGraphQL := postInfo.Type == "GRAPHQL"
 
 	isMultipart := len(postInfo.Files) > 0
 
-	// going forward we may want to support GraphQL and multipart requests
-	// the files key in the response is specifically to handle the multipart use case
-	// for type GraphQL may need to support more keys like expected response format etc.
-	// in future it's expected that we will build on top of this response type
-	// so, code addition should be done here instead of version bumping of response.
-	if isRest && !isMultipart {
-		requestMethod := postInfo.RequestMethod
-		requestBody := postInfo.Body
-		requestQueryParams := postInfo.QueryParams
-		var bodyFormat string
-		var bodyValue map[string]interface{}
-		for k, v := range requestBody {
-			if len(v.(map[string]interface{})) > 0 {
-				bodyFormat = k
-				bodyValue = v.(map[string]interface{})
-				break
-			}
+	requestMethod := postInfo.RequestMethod
+	requestQueryParams := postInfo.QueryParams
+	var (
+		payload io.Reader
+		headers map[string]string
+		errResp *utils.SendPostResponse
+	)
+	switch {
+	case isRest && !isMultipart:
+		payload, headers, errResp = restPayload(postInfo.Body)
+	case isRest && isMultipart:
+		payload, headers, errResp = multipartPayload(postInfo.Body, postInfo.Files)
+	case isGraphQL:
+		if requestMethod == "" {
+			requestMethod = http.MethodPost
+		}
+		var graphQLParams map[string]interface{}
+		payload, headers, graphQLParams, errResp = graphQLPayload(requestMethod, postInfo.Body)
+		if len(graphQLParams) > 0 {
+			requestQueryParams = lo.Assign(requestQueryParams, graphQLParams)
 		}
+	default:
+		// returning 200 with a message in case of unsupported processing
+		// so that we don't process again. can change this code to anything
+		// to be not picked up by router again
+		return &utils.SendPostResponse{
+			StatusCode:   200,
+			ResponseBody: []byte{},
+		}
+	}
+	if errResp != nil {
+		return errResp
+	}
 
-		var payload io.Reader
-		headers := map[string]string{"User-Agent": "RudderLabs"}
-		// support for JSON and FORM body type
-		if len(bodyValue) > 0 {
-			switch bodyFormat {
-			case "JSON":
-				jsonValue, err := json.Marshal(bodyValue)
-				if err != nil {
-					panic(err)
-				}
-				payload = strings.NewReader(string(jsonValue))
-			case "JSON_ARRAY":
-				// support for JSON ARRAY
-				jsonListStr, ok := bodyValue["batch"].(string)
-				if !ok {
-					return &utils.SendPostResponse{
-						StatusCode:   400,
-						ResponseBody: []byte("400 Unable to parse json list. Unexpected transformer response"),
-					}
-				}
-				payload = strings.NewReader(jsonListStr)
-			case "XML":
-				strValue, ok := bodyValue["payload"].(string)
-				if !ok {
-					return &utils.SendPostResponse{
-						StatusCode:   400,
-						ResponseBody: []byte("400 Unable to construct xml payload. Unexpected transformer response"),
-					}
-				}
-				payload = strings.NewReader(strValue)
-			case "FORM":
-				formValues := url.Values{}
-				for key, val := range bodyValue {
-					formValues.Set(key, fmt.Sprint(val)) // transformer ensures top level string values, still val.(string) would be restrictive
-				}
-				payload = strings.NewReader(formValues.Encode())
-			case "GZIP":
-				strValue, ok := bodyValue["payload"].(string)
-				if !ok {
-					return &utils.SendPostResponse{
-						StatusCode:   400,
-						ResponseBody: []byte("400 Unable to parse json list. Unexpected transformer response"),
-					}
-				}
-				var buf bytes.Buffer
-				zw := gzip.NewWriter(&buf)
-				defer func() { _ = zw.Close() }()
-
-				if _, err := zw.Write([]byte(strValue)); err != nil {
-					return &utils.SendPostResponse{
-						StatusCode:   400,
-						ResponseBody: []byte("400 Unable to compress data. Unexpected response"),
-					}
-				}
+	req, err := http.NewRequestWithContext(ctx, requestMethod, postInfo.URL, payload)
+	if err != nil {
+		network.logger.Error(fmt.Sprintf(`400 Unable to construct %q request for URL : %q`, requestMethod, postInfo.URL))
+		return &utils.SendPostResponse{
+			StatusCode:   400,
+			ResponseBody: []byte(fmt.Sprintf(`400 Unable to construct %q request for URL : %q`, requestMethod, postInfo.URL)),
+		}
+	}
 
-				if err := zw.Close(); err != nil {
-					return &utils.SendPostResponse{
-						StatusCode:   400,
-						ResponseBody: []byte("400 Unable to flush compressed data. Unexpected response"),
-					}
-				}
+	// add query params to the url
+	// support of array type in params is handled if the
+	// response from transformers are "," separated
+	queryParams := req.URL.Query()
+	for key, val := range requestQueryParams {
+		formattedVal := handleQueryParam(val)
+		queryParams.Add(key, formattedVal)
+	}
 
-				headers["Content-Encoding"] = "gzip"
-				payload = &buf
-			default:
-				panic(fmt.Errorf("bodyFormat: %s is not supported", bodyFormat))
-			}
-		}
+	req.URL.RawQuery = queryParams.Encode()
+	headerKV := postInfo.Headers
+	for key, val := range headerKV {
+		req.Header.Add(key, val.(string))
+	}
 
-		req, err := http.NewRequestWithContext(ctx, requestMethod, postInfo.URL, payload)
-		if err != nil {
-			network.logger.Error(fmt.Sprintf(`400 Unable to construct %q request for URL : %q`, requestMethod, postInfo.URL))
-			return &utils.SendPostResponse{
-				StatusCode:   400,
-				ResponseBody: []byte(fmt.Sprintf(`400 Unable to construct %q request for URL : %q`, requestMethod, postInfo.URL)),
-			}
+	for key, val := range headers {
+		switch {
+		case key != "Content-Type":
+			req.Header.Add(key, val)
+		case isMultipart || req.Header.Get(key) == "":
+			// the multipart content type carries the boundary of the body, so it always overrides the transformer's one
+			req.Header.Set(key, val)
 		}
+	}
 
-		// add query params to the url
-		// support of array type in params is handled if the
-		// response from transformers are "," separated
-		queryParams := req.URL.Query()
-		for key, val := range requestQueryParams {
-			formattedVal := handleQueryParam(val)
-			queryParams.Add(key, formattedVal)
+	resp, err := client.Do(req)
+	if err != nil {
+		return &utils.SendPostResponse{
+			StatusCode:   http.StatusGatewayTimeout,
+			ResponseBody: []byte(fmt.Sprintf(`504 Unable to make %q request for URL : %q. Error: %v`, requestMethod, postInfo.URL, err)),
 		}
+	}
 
-		req.URL.RawQuery = queryParams.Encode()
-		headerKV := postInfo.Headers
-		for key, val := range headerKV {
-			req.Header.Add(key, val.(string))
+	defer func() { httputil.CloseResponse(resp) }()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return &utils.SendPostResponse{
+			StatusCode:   resp.StatusCode,
+			ResponseBody: []byte(fmt.Sprintf(`Failed to read response body for request for URL : %q. Error: %v`, postInfo.URL, err)),
 		}
+	}
+	network.logger.Debug(postInfo.URL, " : ", req.Proto, " : ", resp.Proto, resp.ProtoMajor, resp.ProtoMinor, resp.ProtoAtLeast)
 
-		for key, val := range headers {
-			req.Header.Add(key, val)
+	var contentTypeHeader string
+	if resp.Header != nil {
+		contentTypeHeader = resp.Header.Get("Content-Type")
+	}
+	if contentTypeHeader == "" {
+		// Detecting content type of the respBody
+		contentTypeHeader = http.DetectContentType(respBody)
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentTypeHeader)
+
+	statusCode := resp.StatusCode
+	if isGraphQL {
+		statusCode = graphQLStatusCode(statusCode, respBody)
+	}
+
+	// If media type is not in some human-readable format (text,json,xml), override the response with an empty string
+	// https://www.iana.org/assignments/media-types/media-types.xhtml
+	isHumanReadable := contentTypeRegex.MatchString(mediaType)
+	if !isHumanReadable {
+		respBody = []byte("redacted due to unsupported content-type")
+	}
+
+	return &utils.SendPostResponse{
+		StatusCode:          statusCode,
+		ResponseBody:        respBody,
+		ResponseContentType: contentTypeHeader,
+	}
+}
+
+// restPayload builds the body of a REST request out of the first non-empty body format of the transformed payload
+func restPayload(requestBody map[string]interface{}) (io.Reader, map[string]string, *utils.SendPostResponse) {
+	var bodyFormat string
+	var bodyValue map[string]interface{}
+	for k, v := range requestBody {
+		if len(v.(map[string]interface{})) > 0 {
+			bodyFormat = k
+			bodyValue = v.(map[string]interface{})
+			break
 		}
+	}
 
-		resp, err := client.Do(req)
-		if err != nil {
-			return &utils.SendPostResponse{
-				StatusCode:   http.StatusGatewayTimeout,
-				ResponseBody: []byte(fmt.Sprintf(`504 Unable to make %q request for URL : %q. Error: %v`, requestMethod, postInfo.URL, err)),
+	var payload io.Reader
+	headers := map[string]string{"User-Agent": "RudderLabs"}
+	// support for JSON and FORM body type
+	if len(bodyValue) > 0 {
+		switch bodyFormat {
+		case "JSON":
+			jsonValue, err := json.Marshal(bodyValue)
+			if err != nil {
+				panic(err)
 			}
-		}
+			payload = strings.NewReader(string(jsonValue))
+		case "JSON_ARRAY":
+			// support for JSON ARRAY
+			jsonListStr, ok := bodyValue["batch"].(string)
+			if !ok {
+				return nil, nil, &utils.SendPostResponse{
+					StatusCode:   400,
+					ResponseBody: []byte("400 Unable to parse json list. Unexpected transformer response"),
+				}
+			}
+			payload = strings.NewReader(jsonListStr)
+		case "XML":
+			strValue, ok := bodyValue["payload"].(string)
+			if !ok {
+				return nil, nil, &utils.SendPostResponse{
+					StatusCode:   400,
+					ResponseBody: []byte("400 Unable to construct xml payload. Unexpected transformer response"),
+				}
+			}
+			payload = strings.NewReader(strValue)
+		case "FORM":
+			formValues := url.Values{}
+			for key, val := range bodyValue {
+				formValues.Set(key, fmt.Sprint(val)) // transformer ensures top level string values, still val.(string) would be restrictive
+			}
+			payload = strings.NewReader(formValues.Encode())
+		case "GZIP":
+			strValue, ok := bodyValue["payload"].(string)
+			if !ok {
+				return nil, nil, &utils.SendPostResponse{
+					StatusCode:   400,
+					ResponseBody: []byte("400 Unable to parse json list. Unexpected transformer response"),
+				}
+			}
+			var buf bytes.Buffer
+			zw := gzip.NewWriter(&buf)
+			defer func() { _ = zw.Close() }()
 
-		defer func() { httputil.CloseResponse(resp) }()
+			if _, err := zw.Write([]byte(strValue)); err != nil {
+				return nil, nil, &utils.SendPostResponse{
+					StatusCode:   400,
+					ResponseBody: []byte("400 Unable to compress data. Unexpected response"),
+				}
+			}
 
-		respBody, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return &utils.SendPostResponse{
-				StatusCode:   resp.StatusCode,
-				ResponseBody: []byte(fmt.Sprintf(`Failed to read response body for request for URL : %q. Error: %v`, postInfo.URL, err)),
+			if err := zw.Close(); err != nil {
+				return nil, nil, &utils.SendPostResponse{
+					StatusCode:   400,
+					ResponseBody: []byte("400 Unable to flush compressed data. Unexpected response"),
+				}
 			}
+
+			headers["Content-Encoding"] = "gzip"
+			payload = &buf
+		default:
+			panic(fmt.Errorf("bodyFormat: %s is not supported", bodyFormat))
 		}
-		network.logger.Debug(postInfo.URL, " : ", req.Proto, " : ", resp.Proto, resp.ProtoMajor, resp.ProtoMinor, resp.ProtoAtLeast)
+	}
+	return payload, headers, nil
+}
 
-		var contentTypeHeader string
-		if resp.Header != nil {
-			contentTypeHeader = resp.Header.Get("Content-Type")
+// multipartPayload builds a multipart/form-data body out of the files of the transformed payload, along with the values
+// of its FORM or JSON body as fields. Each file is either
+//   - a string, used as the content of a file named after its key, or
+//   - an object with the file's content, along with optional filename, contentType and encoding (base64) properties
+func multipartPayload(requestBody, files map[string]interface{}) (io.Reader, map[string]string, *utils.SendPostResponse) {
+	badRequest := func(err error) *utils.SendPostResponse {
+		return &utils.SendPostResponse{
+			StatusCode:   400,
+			ResponseBody: []byte(fmt.Sprintf("400 Unable to construct multipart payload: %v. Unexpected transformer response", err)),
 		}
-		if contentTypeHeader == "" {
-			// Detecting content type of the respBody
-			contentTypeHeader = http.DetectContentType(respBody)
+	}
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	for _, bodyFormat := range []string{"FORM", "JSON"} {
+		bodyValue, _ := requestBody[bodyFormat].(map[string]interface{})
+		for _, key := range sortedKeys(bodyValue) {
+			value, ok := bodyValue[key].(string)
+			if !ok {
+				jsonValue, err := json.Marshal(bodyValue[key])
+				if err != nil {
+					return nil, nil, badRequest(fmt.Errorf("field %q: %w", key, err))
+				}
+				value = string(jsonValue)
+			}
+			if err := mw.WriteField(key, value); err != nil {
+				return nil, nil, badRequest(err)
+			}
 		}
-		mediaType, _, _ := mime.ParseMediaType(contentTypeHeader)
-
-		// If media type is not in some human-readable format (text,json,xml), override the response with an empty string
-		// https://www.iana.org/assignments/media-types/media-types.xhtml
-		isHumanReadable := contentTypeRegex.MatchString(mediaType)
-		if !isHumanReadable {
-			respBody = []byte("redacted due to unsupported content-type")
+	}
+	for _, key := range sortedKeys(files) {
+		filename, contentType, content := key, "application/octet-stream", ""
+		switch file := files[key].(type) {
+		case string:
+			content = file
+		case map[string]interface{}:
+			content, _ = file["content"].(string)
+			if v, _ := file["filename"].(string); v != "" {
+				filename = v
+			}
+			if v, _ := file["contentType"].(string); v != "" {
+				contentType = v
+			}
+			if encoding, _ := file["encoding"].(string); encoding == "base64" {
+				decoded, err := base64.StdEncoding.DecodeString(content)
+				if err != nil {
+					return nil, nil, badRequest(fmt.Errorf("file %q: %w", key, err))
+				}
+				content = string(decoded)
+			}
+		default:
+			return nil, nil, badRequest(fmt.Errorf("file %q: unsupported type %T", key, file))
 		}
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name=%q; filename=%q`, key, filename))
+		h.Set("Content-Type", contentType)
+		part, err := mw.CreatePart(h)
+		if err != nil {
+			return nil, nil, badRequest(err)
+		}
+		if _, err := io.WriteString(part, content); err != nil {
+			return nil, nil, badRequest(err)
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, nil, badRequest(err)
+	}
+	return &buf, map[string]string{"User-Agent": "RudderLabs", "Content-Type": mw.FormDataContentType()}, nil
+}
 
-		return &utils.SendPostResponse{
-			StatusCode:          resp.StatusCode,
-			ResponseBody:        respBody,
-			ResponseContentType: contentTypeHeader,
+// sortedKeys returns the keys of the map in order, so that multipart bodies are built deterministically
+func sortedKeys(m map[string]interface{}) []string {
+	keys := lo.Keys(m)
+	slices.Sort(keys)
+	return keys
+}
+
+// graphQLPayload builds a GraphQL operation out of the query, variables and operationName of the JSON body of the
+// transformed payload. Operations are sent as JSON bodies, or as query parameters (returned separately) for GET requests.
+func graphQLPayload(requestMethod string, requestBody map[string]interface{}) (io.Reader, map[string]string, map[string]interface{}, *utils.SendPostResponse) {
+	bodyValue, _ := requestBody["JSON"].(map[string]interface{})
+	query, _ := bodyValue["query"].(string)
+	if query == "" {
+		return nil, nil, nil, &utils.SendPostResponse{
+			StatusCode:   400,
+			ResponseBody: []byte("400 Unable to construct graphql operation without a query. Unexpected transformer response"),
+		}
+	}
+	operation := map[string]interface{}{"query": query}
+	for _, key := range []string{"variables", "operationName"} {
+		if v, ok := bodyValue[key]; ok && v != nil {
+			operation[key] = v
+		}
+	}
+	headers := map[string]string{"User-Agent": "RudderLabs", "Accept": "application/graphql-response+json, application/json"}
+	if requestMethod == http.MethodGet {
+		return nil, headers, operation, nil
+	}
+	jsonValue, err := json.Marshal(operation)
+	if err != nil {
+		return nil, nil, nil, &utils.SendPostResponse{
+			StatusCode:   400,
+			ResponseBody: []byte(fmt.Sprintf("400 Unable to marshal graphql operation: %v. Unexpected transformer response", err)),
 		}
 	}
+	headers["Content-Type"] = "application/json"
+	return bytes.NewReader(jsonValue), headers, nil, nil
+}
 
-	// returning 200 with a message in case of unsupported processing
-	// so that we don't process again. can change this code to anything
-	// to be not picked up by router again
-	return &utils.SendPostResponse{
-		StatusCode:   200,
-		ResponseBody: []byte{},
+// graphQLErrorCodes maps the codes of errors reported by GraphQL servers in their extensions to the status codes of
+// retryable errors. Errors with any other code, or without one, are terminal.
+var graphQLErrorCodes = map[string]int{
+	"INTERNAL_SERVER_ERROR": http.StatusInternalServerError,
+	"INTERNAL_ERROR":        http.StatusInternalServerError,
+	"SERVICE_UNAVAILABLE":   http.StatusServiceUnavailable,
+	"UNAVAILABLE":           http.StatusServiceUnavailable,
+	"TIMEOUT":               http.StatusGatewayTimeout,
+	"TIMED_OUT":             http.StatusGatewayTimeout,
+	"RATE_LIMITED":          http.StatusTooManyRequests,
+	"THROTTLED":             http.StatusTooManyRequests,
+	"TOO_MANY_REQUESTS":     http.StatusTooManyRequests,
+}
+
+// graphQLStatusCode returns the status code of a GraphQL response. GraphQL servers usually report errors with a
+// successful status code, so successful responses with errors are mapped to
+//   - 400 if any of the errors is terminal, since retrying the operation won't succeed
+//   - 429 if any of the errors is due to rate limiting
+//   - the status code of the retryable errors otherwise
+func graphQLStatusCode(statusCode int, respBody []byte) int {
+	if !isSuccessStatus(statusCode) {
+		return statusCode
 	}
+	errs := gjson.GetBytes(respBody, "errors")
+	if !errs.IsArray() || len(errs.Array()) == 0 {
+		return statusCode
+	}
+	var retryableStatusCode int
+	for _, e := range errs.Array() {
+		code, ok := graphQLErrorCodes[strings.ToUpper(e.Get("extensions.code").String())]
+		if !ok {
+			return http.StatusBadRequest
+		}
+		if retryableStatusCode != http.StatusTooManyRequests {
+			retryableStatusCode = code
+		}
+	}
+	return retryableStatusCode
 }
 
-// Setup initializes the module
-func (network *netHandle) Setup(destID string, netClientTimeout time.Duration) {
+// Setup initializes the module. If gRPC delivery cannot be set up, e.g. due to an invalid descriptor set,
+// an error is returned, with the handler still being able to send payloads of any other type.
+func (network *netHandle) Setup(destID string, netClientTimeout time.Duration) error {
 	network.logger.Info("Network Handler Startup")
 	// Reference http://tleyden.github.io/blog/2016/11/21/tuning-the-go-http-client-library-for-load-testing
 	defaultRoundTripper := http.DefaultTransport
@@ -272,4 +475,19 @@ func (network *netHandle) Setup(destID string, netClientTimeout time.Duration) {
 	network.logger.Info("defaultTransportCopy.MaxIdleConnsPerHost: ", defaultTransportCopy.MaxIdleConnsPerHost)
 	network.logger.Info("netClientTimeout: ", netClientTimeout)
 	network.httpClient = &http.Client{Transport: &defaultTransportCopy, Timeout: netClientTimeout}
+
+	descriptorSet := config.GetStringVar("", "Router."+destID+".grpc.descriptorSet", "Router.grpc.descriptorSet")
+	grpcClient, err := newGRPCClient(network.logger.Child("grpc"), descriptorSet)
+	if err != nil {
+		return fmt.Errorf("setting up grpc delivery with descriptor set %q: %w", descriptorSet, err)
+	}
+	network.grpcClient = grpcClient
+	return nil
+}
+
+// Close releases the connections of the module
+func (network *netHandle) Close() {
+	if network.grpcClient != nil {
+		network.grpcClient.close()
+	}
 }
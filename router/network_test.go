@@ -94,6 +94,180 @@ func TestSendPostWithGzipData(t *testing.T) {
 	})
 }
 
+func TestSendPostWithGraphQL(t *testing.T) {
+	newNetwork := func() *netHandle {
+		network := &netHandle{}
+		network.logger = logger.NOP
+		network.httpClient = http.DefaultClient
+		return network
+	}
+	operation := map[string]interface{}{
+		"query":         "mutation AddUser($name: String!) { addUser(name: $name) { id } }",
+		"variables":     map[string]interface{}{"name": "John"},
+		"operationName": "AddUser",
+	}
+
+	t.Run("should send the operation as a json body", func(t *testing.T) {
+		testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, http.MethodPost, r.Method)
+			require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			require.JSONEq(t, `{
+				"query": "mutation AddUser($name: String!) { addUser(name: $name) { id } }",
+				"variables": {"name": "John"},
+				"operationName": "AddUser"
+			}`, string(body))
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"data":{"addUser":{"id":"1"}}}`))
+		}))
+		defer testServer.Close()
+
+		resp := newNetwork().SendPost(context.Background(), integrations.PostParametersT{
+			Type:    "GRAPHQL",
+			URL:     testServer.URL,
+			Headers: map[string]interface{}{"Authorization": "Bearer token"},
+			Body:    map[string]interface{}{"JSON": operation},
+		})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.JSONEq(t, `{"data":{"addUser":{"id":"1"}}}`, string(resp.ResponseBody))
+	})
+
+	t.Run("should send the operation as query parameters for GET requests", func(t *testing.T) {
+		testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, http.MethodGet, r.Method)
+			require.Equal(t, "{ users { id } }", r.URL.Query().Get("query"))
+			require.JSONEq(t, `{"limit":10}`, r.URL.Query().Get("variables"))
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"data":{"users":[]}}`))
+		}))
+		defer testServer.Close()
+
+		resp := newNetwork().SendPost(context.Background(), integrations.PostParametersT{
+			Type:          "GRAPHQL",
+			URL:           testServer.URL,
+			RequestMethod: http.MethodGet,
+			Body: map[string]interface{}{"JSON": map[string]interface{}{
+				"query":     "{ users { id } }",
+				"variables": map[string]interface{}{"limit": 10},
+			}},
+		})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("should fail without a query", func(t *testing.T) {
+		resp := newNetwork().SendPost(context.Background(), integrations.PostParametersT{
+			Type: "GRAPHQL",
+			URL:  "http://localhost",
+			Body: map[string]interface{}{"JSON": map[string]interface{}{}},
+		})
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	for name, tc := range map[string]struct {
+		statusCode         int
+		response           string
+		expectedStatusCode int
+	}{
+		"terminal error":               {http.StatusOK, `{"errors":[{"message":"invalid name","extensions":{"code":"BAD_USER_INPUT"}}]}`, http.StatusBadRequest},
+		"error without a code":         {http.StatusOK, `{"errors":[{"message":"something went wrong"}],"data":null}`, http.StatusBadRequest},
+		"retryable error":              {http.StatusOK, `{"errors":[{"message":"oops","extensions":{"code":"INTERNAL_SERVER_ERROR"}}]}`, http.StatusInternalServerError},
+		"rate limited error":           {http.StatusOK, `{"errors":[{"message":"slow down","extensions":{"code":"THROTTLED"}},{"message":"oops","extensions":{"code":"TIMEOUT"}}]}`, http.StatusTooManyRequests},
+		"terminal and retryable error": {http.StatusOK, `{"errors":[{"message":"oops","extensions":{"code":"TIMEOUT"}},{"message":"invalid","extensions":{"code":"FORBIDDEN"}}]}`, http.StatusBadRequest},
+		"empty errors":                 {http.StatusOK, `{"errors":[],"data":{"addUser":{"id":"1"}}}`, http.StatusOK},
+		"http error":                   {http.StatusServiceUnavailable, `{"errors":[{"message":"invalid","extensions":{"code":"BAD_USER_INPUT"}}]}`, http.StatusServiceUnavailable},
+	} {
+		t.Run("should map the status of a response with "+name, func(t *testing.T) {
+			testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tc.statusCode)
+				_, _ = w.Write([]byte(tc.response))
+			}))
+			defer testServer.Close()
+
+			resp := newNetwork().SendPost(context.Background(), integrations.PostParametersT{
+				Type: "GRAPHQL",
+				URL:  testServer.URL,
+				Body: map[string]interface{}{"JSON": operation},
+			})
+			require.Equal(t, tc.expectedStatusCode, resp.StatusCode)
+			require.Equal(t, tc.response, string(resp.ResponseBody))
+		})
+	}
+}
+
+func TestSendPostWithMultipart(t *testing.T) {
+	network := &netHandle{}
+	network.logger = logger.NOP
+	network.httpClient = http.DefaultClient
+
+	t.Run("should send files and body values as multipart form data", func(t *testing.T) {
+		testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, http.MethodPost, r.Method)
+			require.NoError(t, r.ParseMultipartForm(1<<20))
+			require.Equal(t, "import", r.FormValue("action"))
+			require.JSONEq(t, `{"header":true}`, r.FormValue("options"))
+
+			file, header, err := r.FormFile("users")
+			require.NoError(t, err)
+			require.Equal(t, "users.csv", header.Filename)
+			require.Equal(t, "text/csv", header.Header.Get("Content-Type"))
+			content, err := io.ReadAll(file)
+			require.NoError(t, err)
+			require.Equal(t, "id,name\n1,John\n", string(content))
+
+			file, header, err = r.FormFile("notes")
+			require.NoError(t, err)
+			require.Equal(t, "notes", header.Filename)
+			content, err = io.ReadAll(file)
+			require.NoError(t, err)
+			require.Equal(t, "plain notes", string(content))
+			_, _ = w.Write([]byte("OK"))
+		}))
+		defer testServer.Close()
+
+		resp := network.SendPost(context.Background(), integrations.PostParametersT{
+			Type:          "REST",
+			URL:           testServer.URL,
+			RequestMethod: http.MethodPost,
+			Headers:       map[string]interface{}{"Content-Type": "multipart/form-data"},
+			Body: map[string]interface{}{
+				"FORM": map[string]interface{}{"action": "import"},
+				"JSON": map[string]interface{}{"options": map[string]interface{}{"header": true}},
+			},
+			Files: map[string]interface{}{
+				"users": map[string]interface{}{
+					"filename":    "users.csv",
+					"contentType": "text/csv",
+					"content":     "aWQsbmFtZQoxLEpvaG4K",
+					"encoding":    "base64",
+				},
+				"notes": "plain notes",
+			},
+		})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "OK", string(resp.ResponseBody))
+	})
+
+	t.Run("should fail with invalid files", func(t *testing.T) {
+		for name, file := range map[string]interface{}{
+			"invalid base64": map[string]interface{}{"content": "%%%", "encoding": "base64"},
+			"invalid type":   10,
+		} {
+			t.Run(name, func(t *testing.T) {
+				resp := network.SendPost(context.Background(), integrations.PostParametersT{
+					Type:          "REST",
+					URL:           "http://localhost",
+					RequestMethod: http.MethodPost,
+					Files:         map[string]interface{}{"file": file},
+				})
+				require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			})
+		}
+	})
+}
+
 var _ = Describe("Network", func() {
 	var c *networkContext
 
@@ -27,6 +27,7 @@ import (
 
 	"github.com/rudderlabs/rudder-go-kit/config"
 	"github.com/rudderlabs/rudder-go-kit/logger"
+	"github.com/rudderlabs/rudder-go-kit/stats"
 	"github.com/rudderlabs/rudder-server/admin"
 	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
 	"github.com/rudderlabs/rudder-server/jobsdb"
@@ -35,6 +36,7 @@ import (
 	mocksRouter "github.com/rudderlabs/rudder-server/mocks/router"
 	mocksTransformer "github.com/rudderlabs/rudder-server/mocks/router/transformer"
 	mockutils "github.com/rudderlabs/rudder-server/mocks/utils/types"
+	"github.com/rudderlabs/rudder-server/router/circuitbreaker"
 	"github.com/rudderlabs/rudder-server/router/internal/eventorder"
 	"github.com/rudderlabs/rudder-server/router/throttler"
 	"github.com/rudderlabs/rudder-server/router/transformer"
@@ -279,27 +281,27 @@ func TestBackoff(t *testing.T) {
 			r.guaranteeUserEventOrder = false
 			workers[0].inputReservations = 0
 
-			slot, err := r.findWorkerSlot(context.Background(), workers, backoffJob, map[eventorder.BarrierKey]struct{}{})
+			slot, err := r.findWorkerSlot(context.Background(), workers, backoffJob, map[eventorder.BarrierKey]struct{}{}, map[string]int{})
 			require.Nil(t, slot)
 			require.ErrorIs(t, err, types.ErrJobBackoff)
 
-			slot, err = r.findWorkerSlot(context.Background(), workers, noBackoffJob1, map[eventorder.BarrierKey]struct{}{})
+			slot, err = r.findWorkerSlot(context.Background(), workers, noBackoffJob1, map[eventorder.BarrierKey]struct{}{}, map[string]int{})
 			require.NotNil(t, slot)
 			require.NoError(t, err)
 			require.Equal(t, int64(1), r.throttlerFactory.(*mockThrottlerFactory).count.Load())
 
-			slot, err = r.findWorkerSlot(context.Background(), workers, noBackoffJob2, map[eventorder.BarrierKey]struct{}{})
+			slot, err = r.findWorkerSlot(context.Background(), workers, noBackoffJob2, map[eventorder.BarrierKey]struct{}{}, map[string]int{})
 			require.NotNil(t, slot)
 			require.NoError(t, err)
 			require.Equal(t, int64(2), r.throttlerFactory.(*mockThrottlerFactory).count.Load())
 
-			slot, err = r.findWorkerSlot(context.Background(), workers, noBackoffJob3, map[eventorder.BarrierKey]struct{}{})
+			slot, err = r.findWorkerSlot(context.Background(), workers, noBackoffJob3, map[eventorder.BarrierKey]struct{}{}, map[string]int{})
 			require.NotNil(t, slot)
 			require.NoError(t, err)
 			require.NotNil(t, slot)
 			require.Equal(t, int64(3), r.throttlerFactory.(*mockThrottlerFactory).count.Load())
 
-			slot, err = r.findWorkerSlot(context.Background(), workers, noBackoffJob4, map[eventorder.BarrierKey]struct{}{})
+			slot, err = r.findWorkerSlot(context.Background(), workers, noBackoffJob4, map[eventorder.BarrierKey]struct{}{}, map[string]int{})
 			require.Nil(t, slot)
 			require.ErrorIs(t, err, types.ErrWorkerNoSlot)
 			require.Equal(t, int64(3), r.throttlerFactory.(*mockThrottlerFactory).count.Load())
@@ -312,29 +314,29 @@ func TestBackoff(t *testing.T) {
 			r.guaranteeUserEventOrder = true
 			workers[0].inputReservations = 0
 
-			slot, err := r.findWorkerSlot(context.Background(), workers, backoffJob, map[eventorder.BarrierKey]struct{}{})
+			slot, err := r.findWorkerSlot(context.Background(), workers, backoffJob, map[eventorder.BarrierKey]struct{}{}, map[string]int{})
 			require.Nil(t, slot)
 			require.ErrorIs(t, err, types.ErrJobBackoff)
 			require.Equal(t, int64(0), r.throttlerFactory.(*mockThrottlerFactory).count.Load())
 
-			slot, err = r.findWorkerSlot(context.Background(), workers, noBackoffJob1, map[eventorder.BarrierKey]struct{}{})
+			slot, err = r.findWorkerSlot(context.Background(), workers, noBackoffJob1, map[eventorder.BarrierKey]struct{}{}, map[string]int{})
 			require.NotNil(t, slot)
 			require.NoError(t, err)
 			require.Equal(t, int64(1), r.throttlerFactory.(*mockThrottlerFactory).count.Load())
 
-			slot, err = r.findWorkerSlot(context.Background(), workers, noBackoffJob2, map[eventorder.BarrierKey]struct{}{})
+			slot, err = r.findWorkerSlot(context.Background(), workers, noBackoffJob2, map[eventorder.BarrierKey]struct{}{}, map[string]int{})
 			require.NotNil(t, slot)
 			require.NoError(t, err)
 			require.Equal(t, int64(2), r.throttlerFactory.(*mockThrottlerFactory).count.Load())
 
-			slot, err = r.findWorkerSlot(context.Background(), workers, noBackoffJob3, map[eventorder.BarrierKey]struct{}{})
+			slot, err = r.findWorkerSlot(context.Background(), workers, noBackoffJob3, map[eventorder.BarrierKey]struct{}{}, map[string]int{})
 			require.NotNil(t, slot)
 			require.NoError(t, err)
 			require.Equal(t, int64(3), r.throttlerFactory.(*mockThrottlerFactory).count.Load())
 			slotToRelease := slot
 			defer func() { slotToRelease.slot.Release() }()
 
-			slot, err = r.findWorkerSlot(context.Background(), workers, noBackoffJob4, map[eventorder.BarrierKey]struct{}{})
+			slot, err = r.findWorkerSlot(context.Background(), workers, noBackoffJob4, map[eventorder.BarrierKey]struct{}{}, map[string]int{})
 			require.Nil(t, slot)
 			require.ErrorIs(t, err, types.ErrWorkerNoSlot)
 			require.Equal(t, int64(3), r.throttlerFactory.(*mockThrottlerFactory).count.Load())
@@ -348,7 +350,7 @@ func TestBackoff(t *testing.T) {
 			r.guaranteeUserEventOrder = true
 			workers[0].inputReservations = 0
 
-			slot, err := r.findWorkerSlot(context.Background(), workers, backoffJob, map[eventorder.BarrierKey]struct{}{})
+			slot, err := r.findWorkerSlot(context.Background(), workers, backoffJob, map[eventorder.BarrierKey]struct{}{}, map[string]int{})
 			require.NotNil(t, slot)
 			require.NoError(t, err, "drain job should be accepted even if it's to be backed off")
 			require.Equal(
@@ -358,7 +360,7 @@ func TestBackoff(t *testing.T) {
 				"throttle check shouldn't even happen for drain job",
 			)
 
-			slot, err = r.findWorkerSlot(context.Background(), workers, noBackoffJob1, map[eventorder.BarrierKey]struct{}{})
+			slot, err = r.findWorkerSlot(context.Background(), workers, noBackoffJob1, map[eventorder.BarrierKey]struct{}{}, map[string]int{})
 			require.NotNil(t, slot)
 			require.NoError(t, err)
 			require.Equal(
@@ -368,7 +370,7 @@ func TestBackoff(t *testing.T) {
 				"throttle check shouldn't even happen for drain job",
 			)
 
-			slot, err = r.findWorkerSlot(context.Background(), workers, noBackoffJob1, map[eventorder.BarrierKey]struct{}{})
+			slot, err = r.findWorkerSlot(context.Background(), workers, noBackoffJob1, map[eventorder.BarrierKey]struct{}{}, map[string]int{})
 			require.NotNil(t, slot)
 			require.NoError(t, err)
 			require.Equal(
@@ -378,7 +380,7 @@ func TestBackoff(t *testing.T) {
 				"throttle check shouldn't even happen for drain job",
 			)
 
-			slot, err = r.findWorkerSlot(context.Background(), workers, noBackoffJob1, map[eventorder.BarrierKey]struct{}{})
+			slot, err = r.findWorkerSlot(context.Background(), workers, noBackoffJob1, map[eventorder.BarrierKey]struct{}{}, map[string]int{})
 			require.Nil(t, slot)
 			require.ErrorIs(t, err, types.ErrWorkerNoSlot)
 			require.Equal(
@@ -393,7 +395,7 @@ func TestBackoff(t *testing.T) {
 			defer func() { r.backgroundCtx = context.Background() }()
 			r.backgroundCtx, r.backgroundCancel = context.WithCancel(context.Background())
 			r.backgroundCancel()
-			slot, err := r.findWorkerSlot(context.Background(), workers, backoffJob, map[eventorder.BarrierKey]struct{}{})
+			slot, err := r.findWorkerSlot(context.Background(), workers, backoffJob, map[eventorder.BarrierKey]struct{}{}, map[string]int{})
 			require.Nil(t, slot)
 			require.ErrorIs(t, err, types.ErrContextCancelled)
 		})
@@ -408,7 +410,7 @@ func TestBackoff(t *testing.T) {
 					RetryTime:  time.Now().Add(1 * time.Hour),
 				},
 			}
-			slot, err := r.findWorkerSlot(context.Background(), workers, invalidJob, map[eventorder.BarrierKey]struct{}{})
+			slot, err := r.findWorkerSlot(context.Background(), workers, invalidJob, map[eventorder.BarrierKey]struct{}{}, map[string]int{})
 			require.Nil(t, slot)
 			require.ErrorIs(t, err, types.ErrParamsUnmarshal)
 		})
@@ -423,7 +425,7 @@ func TestBackoff(t *testing.T) {
 					RetryTime:  time.Now().Add(1 * time.Hour),
 				},
 			}
-			slot, err := r.findWorkerSlot(context.Background(), workers, backoffJob, map[eventorder.BarrierKey]struct{}{{UserID: job.UserID, DestinationID: "destination"}: {}})
+			slot, err := r.findWorkerSlot(context.Background(), workers, backoffJob, map[eventorder.BarrierKey]struct{}{{UserID: job.UserID, DestinationID: "destination"}: {}}, map[string]int{})
 			require.Nil(t, slot)
 			require.ErrorIs(t, err, types.ErrJobOrderBlocked)
 		})
@@ -447,6 +449,7 @@ func TestBackoff(t *testing.T) {
 				workers,
 				job,
 				map[eventorder.BarrierKey]struct{}{{UserID: job.UserID, DestinationID: "destination", WorkspaceID: job.WorkspaceId}: {}},
+				map[string]int{},
 			)
 			require.NoError(t, err)
 			require.NotNil(t, slot)
@@ -456,7 +459,7 @@ func TestBackoff(t *testing.T) {
 				context.Background(),
 				workers,
 				job,
-				map[eventorder.BarrierKey]struct{}{{UserID: job.UserID, DestinationID: "destination", WorkspaceID: job.WorkspaceId}: {}})
+				map[eventorder.BarrierKey]struct{}{{UserID: job.UserID, DestinationID: "destination", WorkspaceID: job.WorkspaceId}: {}}, map[string]int{})
 			require.Nil(t, slot)
 			require.ErrorIs(t, err, types.ErrJobOrderBlocked)
 		})
@@ -480,6 +483,7 @@ func TestBackoff(t *testing.T) {
 				workers,
 				job,
 				map[eventorder.BarrierKey]struct{}{{UserID: job.UserID, DestinationID: "destination", WorkspaceID: job.WorkspaceId}: {}},
+				map[string]int{},
 			)
 			require.NoError(t, err)
 			require.NotNil(t, slot)
@@ -490,10 +494,42 @@ func TestBackoff(t *testing.T) {
 				workers,
 				job,
 				map[eventorder.BarrierKey]struct{}{{UserID: job.UserID, DestinationID: "destination", WorkspaceID: job.WorkspaceId}: {}},
+				map[string]int{},
 			)
 			require.Nil(t, slot)
 			require.ErrorIs(t, err, types.ErrJobOrderBlocked)
 		})
+
+		t.Run("half-open circuit breaker", func(t *testing.T) {
+			defer func(d routerutils.Drainer) { r.drainer = d }(r.drainer)
+			r.drainer = &drainer{}
+			r.guaranteeUserEventOrder = false
+			workers[0].inputReservations = 0
+			c := config.New()
+			c.Set("Router.circuitBreaker.enabled", true)
+			c.Set("Router.circuitBreaker.minRequests", 1)
+			c.Set("Router.circuitBreaker.openTimeout", "10ms")
+			c.Set("Router.circuitBreaker.halfOpenProbes", 1)
+			r.circuitBreakers = circuitbreaker.NewRegistry(c, logger.NOP, stats.NOP)
+			defer func() { r.circuitBreakers = nil }()
+			breaker := r.circuitBreakers.Get(r.destType, "destination")
+			done, err := breaker.Allow()
+			require.NoError(t, err)
+			done(false)
+			require.True(t, breaker.Open())
+			slot, err := r.findWorkerSlot(context.Background(), workers, noBackoffJob1, map[eventorder.BarrierKey]struct{}{}, map[string]int{})
+			require.Nil(t, slot)
+			require.ErrorIs(t, err, types.ErrDestinationCircuitOpen)
+
+			require.Eventually(t, breaker.HalfOpen, time.Second, 5*time.Millisecond)
+			halfOpenProbes := map[string]int{}
+			slot, err = r.findWorkerSlot(context.Background(), workers, noBackoffJob1, map[eventorder.BarrierKey]struct{}{}, halfOpenProbes)
+			require.NoError(t, err)
+			require.NotNil(t, slot)
+			slot, err = r.findWorkerSlot(context.Background(), workers, noBackoffJob2, map[eventorder.BarrierKey]struct{}{}, halfOpenProbes)
+			require.Nil(t, slot, "no more jobs than the probes of the half-open breaker are picked up")
+			require.ErrorIs(t, err, types.ErrDestinationCircuitOpen)
+		})
 	})
 }
 
@@ -788,6 +824,67 @@ var _ = Describe("router", func() {
 			Eventually(func() bool { return routerAborted && procErrorStored }, 5*time.Second, 100*time.Millisecond).Should(Equal(true))
 		})
 
+		It("aborts events that are older than the max event age of their destination", func() {
+			conf.Set("Router."+gaDestinationID+".maxEventAge", "1h")
+			router := &Handle{
+				Reporting: &reporting.NOOP{},
+			}
+			c.mockBackendConfig.EXPECT().AccessToken().AnyTimes()
+
+			router.Setup(gaDestinationDefinition, logger.NOP, conf, c.mockBackendConfig, c.mockRouterJobsDB, c.mockProcErrorsDB, transientsource.NewEmptyService(), rsources.NewNoOpService(), transformerFeaturesService.NewNoOpService(), destinationdebugger.NewNoOpService(), throttler.NewNoOpThrottlerFactory())
+			mockNetHandle := mocksRouter.NewMockNetHandle(c.mockCtrl)
+			router.netHandle = mockNetHandle
+
+			gaPayload := `{"body": {"XML": {}, "FORM": {}, "JSON": {}}, "type": "REST", "files": {}, "method": "POST", "params": {"t": "event", "v": "1", "an": "RudderAndroidClient", "av": "1.0", "ds": "android-sdk", "ea": "Demo Track", "ec": "Demo Category", "el": "Demo Label", "ni": 0, "qt": 59268380964, "ul": "en-US", "cid": "anon_id", "tid": "UA-185645846-1", "uip": "[::1]", "aiid": "com.rudderlabs.android.sdk"}, "userId": "anon_id", "headers": {}, "version": "1", "endpoint": "https://www.google-analytics.com/collect"}`
+			receivedAt := time.Now().Add(-2 * time.Hour).Format(misc.RFC3339Milli)
+			parameters := fmt.Sprintf(`{"source_id": "%s", "destination_id": "%s", "message_id": "2f548e6d-60f6-44af-a1f4-62b3272445c3", "received_at": "%s", "transform_at": "processor"}`, sourceIDEnabled, gaDestinationID, receivedAt)
+
+			unprocessedJobsList := []*jobsdb.JobT{
+				{
+					UUID:         uuid.New(),
+					UserID:       "u1",
+					JobID:        2010,
+					CreatedAt:    time.Now(),
+					ExpireAt:     time.Now(),
+					CustomVal:    customVal["GA"],
+					EventPayload: []byte(gaPayload),
+					LastJobStatus: jobsdb.JobStatusT{
+						AttemptNum: 0,
+					},
+					Parameters:  []byte(parameters),
+					WorkspaceId: workspaceID,
+				},
+			}
+
+			payloadLimit := router.reloadableConfig.payloadLimit
+			c.mockRouterJobsDB.EXPECT().GetToProcess(gomock.Any(), jobsdb.GetQueryParams{
+				CustomValFilters: []string{customVal["GA"]},
+				ParameterFilters: []jobsdb.ParameterFilterT{{Name: "destination_id", Value: gaDestinationID}},
+				PayloadSizeLimit: payloadLimit.Load(),
+				JobsLimit:        10000,
+			}, nil).Times(1).Return(&jobsdb.MoreJobsResult{JobsResult: jobsdb.JobsResult{Jobs: unprocessedJobsList}}, nil)
+
+			var routerAborted bool
+			c.mockRouterJobsDB.EXPECT().UpdateJobStatus(gomock.Any(), gomock.Any(), []string{customVal["GA"]}, nil).Times(1)
+			c.mockProcErrorsDB.EXPECT().Store(gomock.Any(), gomock.Any()).Times(1)
+			c.mockRouterJobsDB.EXPECT().WithUpdateSafeTx(gomock.Any(), gomock.Any()).Do(func(ctx context.Context, f func(tx jobsdb.UpdateSafeTx) error) {
+				_ = f(jobsdb.EmptyUpdateSafeTx())
+			}).Return(nil).Times(1)
+			c.mockRouterJobsDB.EXPECT().UpdateJobStatusInTx(gomock.Any(), gomock.Any(), gomock.Any(), []string{customVal["GA"]}, nil).Times(1).
+				Do(func(ctx context.Context, tx jobsdb.UpdateSafeTx, drainList []*jobsdb.JobStatusT, _, _ interface{}) {
+					Expect(drainList).To(HaveLen(1))
+					assertJobStatus(unprocessedJobsList[0], drainList[0], jobsdb.Aborted.State, routerutils.STALE_EVENT_ERROR_CODE, `{"reason": "event exceeded the max event age of the destination"}`, 0)
+					routerAborted = true
+				})
+
+			<-router.backendConfigInitialized
+			worker := newPartitionWorker(context.Background(), router, gaDestinationID)
+			defer worker.Stop()
+			Expect(worker.Work()).To(BeTrue())
+			Expect(worker.pickupCount).To(Equal(len(unprocessedJobsList)))
+			Eventually(func() bool { return routerAborted }, 5*time.Second, 100*time.Millisecond).Should(Equal(true))
+		})
+
 		It("aborts jobs that bear a abort configured jobRunId", func() {
 			conf.Set("drain.jobRunIDs", "someJobRunId")
 			router := &Handle{
@@ -11,7 +11,11 @@ import (
 	"github.com/rudderlabs/rudder-server/jobsdb"
 )
 
-const RouterUnMarshalErrorCode = 599
+const (
+	RouterUnMarshalErrorCode = 599
+	// RouterCircuitOpenErrorCode is the status code of jobs which weren't sent, because the circuit breaker of their destination didn't allow it
+	RouterCircuitOpenErrorCode = 598
+)
 
 // RouterJobT holds the router job and its related metadata
 type RouterJobT struct {
@@ -152,6 +156,8 @@ var (
 	ErrJobBackoff = errors.New("backoff")
 	// ErrDestinationThrottled is returned when the destination is being throttled
 	ErrDestinationThrottled = errors.New("throttled")
+	// ErrDestinationCircuitOpen is returned when the circuit breaker of the destination is open
+	ErrDestinationCircuitOpen = errors.New("circuit open")
 	// ErrBarrierExists is returned when a job ordering barrier exists for the job's ordering key
 	ErrBarrierExists = errors.New("barrier")
 )
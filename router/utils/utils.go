@@ -19,6 +19,9 @@ var EmptyPayload = []byte(`{}`)
 
 const (
 	DRAIN_ERROR_CODE = "410"
+	// STALE_EVENT_ERROR_CODE is the error code of jobs aborted for exceeding the max event age of their destination.
+	// It is outside the range of HTTP status codes, so that it cannot be mistaken for a response of the destination.
+	STALE_EVENT_ERROR_CODE = "1410"
 	// transformation(router or batch)
 	ERROR_AT_TF = "transformation"
 	// event delivery
@@ -31,6 +34,7 @@ const (
 	DrainReasonDestAbort         = "destination configured to abort"
 	DrainReasonJobRunIDCancelled = "cancelled jobRunID"
 	DrainReasonJobExpired        = "job expired"
+	DrainReasonStaleEvent        = "event exceeded the max event age of the destination"
 )
 
 type DestinationWithSources struct {
@@ -29,6 +29,7 @@ import (
 	destinationdebugger "github.com/rudderlabs/rudder-server/services/debugger/destination"
 	"github.com/rudderlabs/rudder-server/services/oauth"
 	oauthv2 "github.com/rudderlabs/rudder-server/services/oauth/v2"
+	"github.com/rudderlabs/rudder-server/services/secrets"
 	"github.com/rudderlabs/rudder-server/utils/misc"
 	utilTypes "github.com/rudderlabs/rudder-server/utils/types"
 )
@@ -92,7 +93,10 @@ func (w *worker) workLoop() {
 			if err := json.Unmarshal(job.Parameters, &parameters); err != nil {
 				panic(fmt.Errorf("unmarshalling of job parameters failed for job %d (%s): %w", job.JobID, string(job.Parameters), err))
 			}
-			abortReason := message.drainReason
+			abortReason, abortCode := message.drainReason, routerutils.DRAIN_ERROR_CODE
+			if abortReason == "" && w.isStale(job, parameters) {
+				abortReason, abortCode = routerutils.DrainReasonStaleEvent, routerutils.STALE_EVENT_ERROR_CODE
+			}
 			abort := abortReason != ""
 			abortTag := abortReason
 			errResponse := routerutils.EnhanceJSON(job.LastJobStatus.ErrorResponse, "reason", abortReason)
@@ -103,7 +107,7 @@ func (w *worker) workLoop() {
 					JobState:      jobsdb.Aborted.State,
 					ExecTime:      time.Now(),
 					RetryTime:     time.Now(),
-					ErrorCode:     routerutils.DRAIN_ERROR_CODE,
+					ErrorCode:     abortCode,
 					Parameters:    routerutils.EmptyPayload,
 					JobParameters: job.Parameters,
 					ErrorResponse: errResponse,
@@ -484,6 +488,7 @@ func (w *worker) processDestinationJobs() {
 				// TODO: remove trackStuckDelivery once we verify it is not needed,
 				//			router_delivery_exceeded_timeout -> goes to zero
 				ch := w.trackStuckDelivery()
+				var recordDelivery func(success bool) // records the delivery outcome to the destination's circuit breaker, once allowed by it
 
 				if w.rt.customDestinationManager != nil {
 					for _, destinationJobMetadata := range destinationJob.JobMetadataArray {
@@ -506,6 +511,10 @@ func (w *worker) processDestinationJobs() {
 						truncatedMessage := misc.TruncateStr(string(destinationJob.Message), int(10*bytesize.KB))
 						w.logger.Errorw("transformer response unmarshal error", "message", truncatedMessage, "jobIDs", jobIDs)
 						respStatusCodes, respBodys = w.prepareResponsesForJobs(&destinationJob, respStatusCode, respBody)
+					} else if recordDelivery, err = w.rt.circuitBreakers.Get(w.rt.destType, destinationID).Allow(); err != nil {
+						// the destination's circuit breaker opened, or all of its half-open probes are in flight, since the jobs were picked up
+						errorAt = routerutils.ERROR_AT_DEL
+						respStatusCodes, respBodys = w.prepareResponsesForJobs(&destinationJob, types.RouterCircuitOpenErrorCode, err.Error())
 					} else {
 						var respStatusCode int
 						var respBodyTemp string
@@ -604,6 +613,12 @@ func (w *worker) processDestinationJobs() {
 				// END: request to destination endpoint
 
 				w.updateReqMetrics(respStatusCodes, &diagnosisStartTime)
+				if recordDelivery != nil {
+					// requests which failed before reaching the destination don't count as failures
+					recordDelivery(errorAt != routerutils.ERROR_AT_DEL || !lo.SomeBy(lo.Values(respStatusCodes), func(statusCode int) bool {
+						return statusCode >= http.StatusInternalServerError
+					}))
+				}
 			} else {
 				respStatusCode := http.StatusInternalServerError
 				var respBody string
@@ -683,7 +698,7 @@ func (w *worker) processDestinationJobs() {
 			jobOrderKeyToJobIDMap[orderKey] = destinationJobMetadata.JobID
 		}
 
-		trimmedResponse := string(lo.Slice([]byte(routerJobResponse.respBody), 0, int(10*bytesize.KB)))
+		trimmedResponse := string(lo.Slice([]byte(secrets.Redact(routerJobResponse.respBody)), 0, int(10*bytesize.KB))) // destinations might echo resolved secrets of their config
 		status.AttemptNum++
 		status.ErrorResponse = routerutils.EnhanceJSON(routerutils.EmptyPayload, "response", trimmedResponse)
 		status.ErrorCode = strconv.Itoa(respStatusCode)
@@ -1095,6 +1110,23 @@ func (w *worker) sendRouterResponseCountStat(status *jobsdb.JobStatusT, destinat
 	routerResponseStat.Count(1)
 }
 
+// isStale returns true if the event is older than the max event age of its destination, in which case it is not worth delivering anymore.
+func (w *worker) isStale(job *jobsdb.JobT, parameters routerutils.JobParameters) bool {
+	receivedAt := parameters.ParseReceivedAtTime()
+	if receivedAt.IsZero() {
+		return false
+	}
+	if maxEventAge := w.rt.maxEventAgeForDestination(parameters.DestinationID); maxEventAge > 0 && time.Since(receivedAt) > maxEventAge {
+		stats.Default.NewTaggedStat("router_stale_events", stats.CountType, stats.Tags{
+			"destType":    w.rt.destType,
+			"destId":      parameters.DestinationID,
+			"workspaceId": job.WorkspaceId,
+		}).Increment()
+		return true
+	}
+	return false
+}
+
 func (w *worker) sendEventDeliveryStat(destinationJobMetadata *types.JobMetadataT, status *jobsdb.JobStatusT, destination *backendconfig.DestinationT) {
 	destinationTag := misc.GetTagName(destination.ID, destination.Name)
 	if status.JobState == jobsdb.Succeeded.State {
@@ -13,6 +13,7 @@ import (
 	"github.com/rudderlabs/rudder-server/rruntime"
 	"github.com/rudderlabs/rudder-server/services/debugger"
 	"github.com/rudderlabs/rudder-server/services/debugger/cache"
+	"github.com/rudderlabs/rudder-server/services/secrets"
 )
 
 // DeliveryStatusT is a structure to hold everything related to event delivery
@@ -116,6 +117,9 @@ func (h *Handle) RecordEventDeliveryStatus(destinationID string, deliveryStatus
 		return false
 	}
 	<-h.initialized
+	// payloads and destination responses might contain resolved secrets of the destination's config
+	deliveryStatus.Payload = redact(deliveryStatus.Payload)
+	deliveryStatus.ErrorResponse = redact(deliveryStatus.ErrorResponse)
 	// Check if destinationID part of enabled destinations, if not then push the job in cache to keep track
 	if !h.HasUploadEnabled(destinationID) {
 		err := h.eventsDeliveryCache.Update(destinationID, deliveryStatus)
@@ -129,6 +133,13 @@ func (h *Handle) RecordEventDeliveryStatus(destinationID string, deliveryStatus
 	return true
 }
 
+func redact(raw json.RawMessage) json.RawMessage {
+	if len(raw) == 0 {
+		return raw
+	}
+	return json.RawMessage(secrets.Redact(string(raw)))
+}
+
 func (h *Handle) HasUploadEnabled(destID string) bool {
 	<-h.initialized
 	h.uploadEnabledDestinationIDsMu.RLock()
@@ -8,6 +8,7 @@ import (
 	"github.com/rudderlabs/rudder-server/services/dedup/badger"
 	"github.com/rudderlabs/rudder-server/services/dedup/mirrorBadger"
 	"github.com/rudderlabs/rudder-server/services/dedup/mirrorScylla"
+	"github.com/rudderlabs/rudder-server/services/dedup/redis"
 	"github.com/rudderlabs/rudder-server/services/dedup/scylla"
 	"github.com/rudderlabs/rudder-server/services/dedup/types"
 )
@@ -19,6 +20,7 @@ const (
 	Scylla       Mode = "Scylla"
 	MirrorScylla Mode = "MirrorScylla"
 	MirrorBadger Mode = "MirrorBadger"
+	Redis        Mode = "Redis"
 )
 
 // New creates a new deduplication service. The service needs to be closed after use.
@@ -41,6 +43,12 @@ func New(conf *config.Config, stats stats.Stats) (Dedup, error) {
 		// Writes happen to both
 		// Read only from Badger
 		return mirrorBadger.NewMirrorBadger(conf, stats)
+	case Redis:
+		redis, err := redis.New(conf, stats)
+		if err != nil {
+			return nil, err
+		}
+		return redis, nil
 	default:
 		return badger.NewBadgerDB(conf, stats, badger.DefaultPath()), nil
 	}
@@ -0,0 +1,208 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/samber/lo"
+
+	"github.com/rudderlabs/rudder-go-kit/config"
+	"github.com/rudderlabs/rudder-go-kit/logger"
+	"github.com/rudderlabs/rudder-go-kit/stats"
+
+	"github.com/rudderlabs/rudder-server/rruntime"
+	"github.com/rudderlabs/rudder-server/services/dedup/types"
+)
+
+// Dedup is a deduplication service backed by Redis (or any Redis compatible server, e.g. Valkey).
+// Keys are namespaced per workspace and expire after the configured ttl.
+type Dedup struct {
+	client goredis.UniversalClient
+	stats  stats.Stats
+	logger logger.Logger
+	// Time to live for an entry in the DB
+	ttl config.ValueLoader[time.Duration]
+	// Maximum number of commands to send in a single pipeline
+	batchSize int
+	prefix    string
+	timeout   time.Duration
+
+	cacheMu sync.Mutex
+	cache   map[string]types.KeyValue
+
+	statsInterval time.Duration
+	wg            sync.WaitGroup
+	bgCtx         context.Context
+	cancel        context.CancelFunc
+}
+
+// New creates a new redis deduplication service. The service needs to be closed after use.
+func New(conf *config.Config, stats stats.Stats) (*Dedup, error) {
+	addrs := lo.Map(conf.GetStringSlice("Dedup.Redis.Addresses", []string{"localhost:6379"}), func(addr string, _ int) string {
+		return strings.TrimSpace(addr)
+	})
+	client := goredis.NewUniversalClient(&goredis.UniversalOptions{
+		Addrs:        addrs,
+		Username:     conf.GetString("Dedup.Redis.Username", ""),
+		Password:     conf.GetString("Dedup.Redis.Password", ""),
+		DB:           conf.GetInt("Dedup.Redis.DB", 0),
+		MaxRetries:   conf.GetInt("Dedup.Redis.MaxRetries", 3),
+		DialTimeout:  conf.GetDuration("Dedup.Redis.DialTimeout", 5, time.Second),
+		ReadTimeout:  conf.GetDuration("Dedup.Redis.ReadTimeout", 3, time.Second),
+		WriteTimeout: conf.GetDuration("Dedup.Redis.WriteTimeout", 3, time.Second),
+		PoolSize:     conf.GetInt("Dedup.Redis.PoolSize", 0),
+	})
+	timeout := conf.GetDuration("Dedup.Redis.Timeout", 10, time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("pinging redis: %w", err)
+	}
+
+	bgCtx, bgCancel := context.WithCancel(context.Background())
+	d := &Dedup{
+		client:        client,
+		stats:         stats,
+		logger:        logger.NewLogger().Child("Dedup").Child("redis"),
+		ttl:           conf.GetReloadableDurationVar(3600, time.Second, "Dedup.Redis.TTL", "Dedup.dedupWindow", "Dedup.dedupWindowInS"),
+		batchSize:     conf.GetInt("Dedup.Redis.BatchSize", 100),
+		prefix:        conf.GetString("Dedup.Redis.KeyPrefix", "dedup"),
+		timeout:       timeout,
+		cache:         make(map[string]types.KeyValue),
+		statsInterval: conf.GetDuration("Dedup.Redis.StatsInterval", 5, time.Minute),
+		bgCtx:         bgCtx,
+		cancel:        bgCancel,
+	}
+	d.wg.Add(1)
+	rruntime.Go(func() {
+		defer d.wg.Done()
+		d.statsLoop()
+	})
+	return d, nil
+}
+
+// Get returns [true] if it was the first time the key was encountered, otherwise it returns [false] along with the previous value
+func (d *Dedup) Get(kv types.KeyValue) (bool, int64, error) {
+	d.cacheMu.Lock()
+	defer d.cacheMu.Unlock()
+
+	// Check if the key exists in the cache first.
+	// This is essential if we get the same key multiple times in the same batch
+	// Since we are not committing the keys immediately, we need to keep track of the keys in the cache
+	if previous, found := d.cache[kv.Key]; found {
+		return false, previous.Value, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
+	defer cancel()
+	start := time.Now()
+	value, err := d.client.Get(ctx, d.redisKey(kv)).Int64()
+	d.stats.NewTaggedStat("dedup_redis_get_duration_seconds", stats.TimerType, stats.Tags{"name": "dedup"}).Since(start)
+	switch {
+	case errors.Is(err, goredis.Nil):
+		d.cache[kv.Key] = kv
+		return true, kv.Value, nil
+	case err != nil:
+		d.stats.NewTaggedStat("dedup_redis_get_error", stats.CountType, stats.Tags{"name": "dedup"}).Increment()
+		return false, 0, fmt.Errorf("getting key %s: %w", kv.Key, err)
+	default:
+		return false, value, nil
+	}
+}
+
+// Commit commits a list of previously set keys to the DB
+func (d *Dedup) Commit(keys []string) error {
+	d.cacheMu.Lock()
+	kvs := make([]types.KeyValue, len(keys))
+	for i, key := range keys {
+		value, ok := d.cache[key]
+		if !ok {
+			d.cacheMu.Unlock()
+			return fmt.Errorf("key %v has not been previously set", key)
+		}
+		kvs[i] = value
+	}
+	d.cacheMu.Unlock()
+
+	ttl := d.ttl.Load()
+	for _, batch := range lo.Chunk(kvs, d.batchSize) {
+		ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
+		start := time.Now()
+		pipe := d.client.Pipeline()
+		for _, kv := range batch {
+			pipe.Set(ctx, d.redisKey(kv), strconv.FormatInt(kv.Value, 10), ttl)
+		}
+		_, err := pipe.Exec(ctx)
+		cancel()
+		d.stats.NewTaggedStat("dedup_redis_commit_duration_seconds", stats.TimerType, stats.Tags{"name": "dedup"}).Since(start)
+		if err != nil {
+			d.stats.NewTaggedStat("dedup_redis_commit_error", stats.CountType, stats.Tags{"name": "dedup"}).Increment()
+			return fmt.Errorf("committing keys: %w", err)
+		}
+		d.cacheMu.Lock()
+		for _, kv := range batch {
+			delete(d.cache, kv.Key)
+		}
+		d.cacheMu.Unlock()
+	}
+	return nil
+}
+
+// Close closes the deduplication service
+func (d *Dedup) Close() {
+	d.cancel()
+	d.wg.Wait()
+	_ = d.client.Close()
+}
+
+func (d *Dedup) redisKey(kv types.KeyValue) string {
+	return d.prefix + ":" + kv.WorkspaceID + ":" + kv.Key
+}
+
+// statsLoop periodically reports the size of the redis database, using the same stat types as the badger implementation where applicable
+func (d *Dedup) statsLoop() {
+	for {
+		select {
+		case <-d.bgCtx.Done():
+			return
+		case <-time.After(d.statsInterval):
+		}
+		ctx, cancel := context.WithTimeout(d.bgCtx, d.timeout)
+		keys, err := d.client.DBSize(ctx).Result()
+		if err != nil {
+			cancel()
+			d.logger.Errorf("Error while getting redis db size: %v", err)
+			continue
+		}
+		memory, err := d.usedMemory(ctx)
+		cancel()
+		if err != nil {
+			d.logger.Errorf("Error while getting redis memory usage: %v", err)
+			continue
+		}
+		statName := "dedup"
+		d.stats.NewTaggedStat("redis_db_size", stats.GaugeType, stats.Tags{"name": statName, "type": "total"}).Gauge(memory)
+		d.stats.NewTaggedStat("redis_db_keys", stats.GaugeType, stats.Tags{"name": statName}).Gauge(keys)
+	}
+}
+
+// usedMemory parses the used_memory field out of the INFO memory section
+func (d *Dedup) usedMemory(ctx context.Context) (int64, error) {
+	info, err := d.client.Info(ctx, "memory").Result()
+	if err != nil {
+		return 0, err
+	}
+	for _, line := range strings.Split(info, "\n") {
+		if v, ok := strings.CutPrefix(strings.TrimSpace(line), "used_memory:"); ok {
+			return strconv.ParseInt(v, 10, 64)
+		}
+	}
+	return 0, fmt.Errorf("used_memory not found in redis info")
+}
@@ -0,0 +1,105 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rudderlabs/rudder-go-kit/config"
+	"github.com/rudderlabs/rudder-go-kit/stats"
+	"github.com/rudderlabs/rudder-server/services/dedup/types"
+)
+
+func Test_Redis(t *testing.T) {
+	mr := miniredis.RunT(t)
+	conf := config.New()
+	conf.Set("Dedup.Redis.Addresses", mr.Addr())
+	conf.Set("Dedup.Redis.TTL", "1h")
+	conf.Set("Dedup.Redis.BatchSize", 2)
+	redis, err := New(conf, stats.NOP)
+	require.NoError(t, err)
+	require.NotNil(t, redis)
+	defer redis.Close()
+
+	t.Run("Same messageID should not be deduped for different workspace", func(t *testing.T) {
+		key1 := types.KeyValue{Key: "a", Value: 1, WorkspaceID: "test1"}
+		key2 := types.KeyValue{Key: "a", Value: 1, WorkspaceID: "test2"}
+		found, _, err := redis.Get(key1)
+		require.NoError(t, err)
+		require.True(t, found)
+		err = redis.Commit([]string{key1.Key})
+		require.NoError(t, err)
+		found, _, err = redis.Get(key2)
+		require.NoError(t, err)
+		require.True(t, found)
+		err = redis.Commit([]string{key2.Key})
+		require.NoError(t, err)
+	})
+	t.Run("Same messageID should be deduped for same workspace", func(t *testing.T) {
+		key1 := types.KeyValue{Key: "b", Value: 1, WorkspaceID: "test"}
+		key2 := types.KeyValue{Key: "b", Value: 2, WorkspaceID: "test"}
+		found, _, err := redis.Get(key1)
+		require.NoError(t, err)
+		require.True(t, found)
+		err = redis.Commit([]string{key1.Key})
+		require.NoError(t, err)
+		found, previous, err := redis.Get(key2)
+		require.NoError(t, err)
+		require.False(t, found)
+		require.EqualValues(t, 1, previous)
+	})
+	t.Run("Same messageID should be deduped for same workspace from cache", func(t *testing.T) {
+		key1 := types.KeyValue{Key: "c", Value: 1, WorkspaceID: "test"}
+		key2 := types.KeyValue{Key: "c", Value: 1, WorkspaceID: "test"}
+		found, _, err := redis.Get(key1)
+		require.NoError(t, err)
+		require.True(t, found)
+		found, _, err = redis.Get(key2)
+		require.NoError(t, err)
+		require.False(t, found)
+	})
+	t.Run("committing in batches", func(t *testing.T) {
+		for i, key := range []string{"d", "e", "f"} {
+			found, _, err := redis.Get(types.KeyValue{Key: key, Value: int64(i), WorkspaceID: "test"})
+			require.NoError(t, err)
+			require.True(t, found)
+		}
+
+		require.NoError(t, redis.Commit([]string{"d", "e", "f"}))
+		for _, key := range []string{"d", "e", "f"} {
+			require.True(t, mr.Exists("dedup:test:"+key))
+		}
+	})
+	t.Run("committing a key not previously set", func(t *testing.T) {
+		require.Error(t, redis.Commit([]string{"unknown"}))
+	})
+	t.Run("keys expire after the ttl", func(t *testing.T) {
+		key := types.KeyValue{Key: "g", Value: 1, WorkspaceID: "test"}
+		found, _, err := redis.Get(key)
+		require.NoError(t, err)
+		require.True(t, found)
+		require.NoError(t, redis.Commit([]string{key.Key}))
+
+		found, _, err = redis.Get(key)
+		require.NoError(t, err)
+		require.False(t, found)
+
+		mr.FastForward(time.Hour + time.Second)
+		found, _, err = redis.Get(key)
+		require.NoError(t, err)
+		require.True(t, found)
+	})
+}
+
+func Test_RedisUnavailable(t *testing.T) {
+	mr := miniredis.RunT(t)
+	addr := mr.Addr()
+	mr.Close()
+	conf := config.New()
+	conf.Set("Dedup.Redis.Addresses", addr)
+	conf.Set("Dedup.Redis.MaxRetries", 0)
+	_, err := New(conf, stats.NOP)
+	require.Error(t, err)
+}
@@ -0,0 +1,173 @@
+package secrets
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/rudderlabs/rudder-go-kit/logger"
+)
+
+// NewRedactingLogger returns a logger which redacts resolved secret values out of everything it logs through the provided logger,
+// for components that come across destination configs, e.g. while logging requests or responses of destinations.
+// Arguments are only formatted for redaction if any secret has been resolved.
+func NewRedactingLogger(log logger.Logger) logger.Logger {
+	return &redactingLogger{Logger: log}
+}
+
+type redactingLogger struct {
+	logger.Logger
+}
+
+// redacting returns true if there are resolved secret values to redact
+func redacting() bool {
+	return redactor.Load() != nil
+}
+
+func redactArgs(args []any) []any {
+	if !redacting() {
+		return args
+	}
+	return []any{Redact(fmt.Sprint(args...))}
+}
+
+func redactf(format string, args []any) (string, []any) {
+	if !redacting() {
+		return format, args
+	}
+	return "%s", []any{Redact(fmt.Sprintf(format, args...))}
+}
+
+func redactw(msg string, keysAndValues []any) (string, []any) {
+	if !redacting() {
+		return msg, keysAndValues
+	}
+	redacted := make([]any, len(keysAndValues))
+	for i, v := range keysAndValues {
+		redacted[i] = redactValue(v)
+	}
+	return Redact(msg), redacted
+}
+
+func redactn(msg string, fields []logger.Field) (string, []logger.Field) {
+	if !redacting() {
+		return msg, fields
+	}
+	redacted := make([]logger.Field, len(fields))
+	for i, f := range fields {
+		redacted[i] = f
+		if s := fmt.Sprint(f.Value()); Redact(s) != s {
+			redacted[i] = logger.NewStringField(f.Name(), Redact(s))
+		}
+	}
+	return Redact(msg), redacted
+}
+
+// redactValue returns the value as is, unless its string representation contains secret values, in which case it is returned redacted
+func redactValue(v any) any {
+	s, ok := v.(string)
+	if !ok {
+		s = fmt.Sprint(v)
+	}
+	if redactedValue := Redact(s); redactedValue != s {
+		return redactedValue
+	}
+	return v
+}
+
+func (l *redactingLogger) Debug(args ...any) { l.Logger.Debug(redactArgs(args)...) }
+func (l *redactingLogger) Info(args ...any)  { l.Logger.Info(redactArgs(args)...) }
+func (l *redactingLogger) Warn(args ...any)  { l.Logger.Warn(redactArgs(args)...) }
+func (l *redactingLogger) Error(args ...any) { l.Logger.Error(redactArgs(args)...) }
+func (l *redactingLogger) Fatal(args ...any) { l.Logger.Fatal(redactArgs(args)...) }
+
+func (l *redactingLogger) Debugf(format string, args ...any) {
+	format, args = redactf(format, args)
+	l.Logger.Debugf(format, args...)
+}
+
+func (l *redactingLogger) Infof(format string, args ...any) {
+	format, args = redactf(format, args)
+	l.Logger.Infof(format, args...)
+}
+
+func (l *redactingLogger) Warnf(format string, args ...any) {
+	format, args = redactf(format, args)
+	l.Logger.Warnf(format, args...)
+}
+
+func (l *redactingLogger) Errorf(format string, args ...any) {
+	format, args = redactf(format, args)
+	l.Logger.Errorf(format, args...)
+}
+
+func (l *redactingLogger) Fatalf(format string, args ...any) {
+	format, args = redactf(format, args)
+	l.Logger.Fatalf(format, args...)
+}
+
+func (l *redactingLogger) Debugw(msg string, keysAndValues ...any) {
+	msg, keysAndValues = redactw(msg, keysAndValues)
+	l.Logger.Debugw(msg, keysAndValues...)
+}
+
+func (l *redactingLogger) Infow(msg string, keysAndValues ...any) {
+	msg, keysAndValues = redactw(msg, keysAndValues)
+	l.Logger.Infow(msg, keysAndValues...)
+}
+
+func (l *redactingLogger) Warnw(msg string, keysAndValues ...any) {
+	msg, keysAndValues = redactw(msg, keysAndValues)
+	l.Logger.Warnw(msg, keysAndValues...)
+}
+
+func (l *redactingLogger) Errorw(msg string, keysAndValues ...any) {
+	msg, keysAndValues = redactw(msg, keysAndValues)
+	l.Logger.Errorw(msg, keysAndValues...)
+}
+
+func (l *redactingLogger) Fatalw(msg string, keysAndValues ...any) {
+	msg, keysAndValues = redactw(msg, keysAndValues)
+	l.Logger.Fatalw(msg, keysAndValues...)
+}
+
+func (l *redactingLogger) Debugn(msg string, fields ...logger.Field) {
+	msg, fields = redactn(msg, fields)
+	l.Logger.Debugn(msg, fields...)
+}
+
+func (l *redactingLogger) Infon(msg string, fields ...logger.Field) {
+	msg, fields = redactn(msg, fields)
+	l.Logger.Infon(msg, fields...)
+}
+
+func (l *redactingLogger) Warnn(msg string, fields ...logger.Field) {
+	msg, fields = redactn(msg, fields)
+	l.Logger.Warnn(msg, fields...)
+}
+
+func (l *redactingLogger) Errorn(msg string, fields ...logger.Field) {
+	msg, fields = redactn(msg, fields)
+	l.Logger.Errorn(msg, fields...)
+}
+
+func (l *redactingLogger) Fataln(msg string, fields ...logger.Field) {
+	msg, fields = redactn(msg, fields)
+	l.Logger.Fataln(msg, fields...)
+}
+
+// LogRequest logs the request as is, since headers and bodies of incoming requests don't contain resolved secrets
+func (l *redactingLogger) LogRequest(req *http.Request) { l.Logger.LogRequest(req) }
+
+func (l *redactingLogger) Child(s string) logger.Logger {
+	return &redactingLogger{Logger: l.Logger.Child(s)}
+}
+
+func (l *redactingLogger) With(args ...any) logger.Logger {
+	_, args = redactw("", args)
+	return &redactingLogger{Logger: l.Logger.With(args...)}
+}
+
+func (l *redactingLogger) Withn(args ...logger.Field) logger.Logger {
+	_, args = redactn("", args)
+	return &redactingLogger{Logger: l.Logger.Withn(args...)}
+}
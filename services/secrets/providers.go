@@ -0,0 +1,186 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+
+	"github.com/rudderlabs/rudder-go-kit/awsutil"
+)
+
+// NewFileProvider returns a provider reading secrets from JSON files under the root directory.
+// The secret at path a/b is read from the file <root>/a/b.json, containing an object with the values of the secret.
+func NewFileProvider(root string) (Provider, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	if info, err := os.Stat(root); err != nil {
+		return nil, err
+	} else if !info.IsDir() {
+		return nil, fmt.Errorf("%q is not a directory", root)
+	}
+	return &fileProvider{root: root}, nil
+}
+
+type fileProvider struct {
+	root string
+}
+
+func (p *fileProvider) Get(_ context.Context, path string) (map[string]string, error) {
+	file := filepath.Join(p.root, filepath.FromSlash(path)+".json")
+	if !strings.HasPrefix(file, p.root+string(filepath.Separator)) {
+		return nil, fmt.Errorf("invalid secret path %q", path)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return parseValues(data)
+}
+
+// VaultConfig is the configuration of a HashiCorp Vault provider
+type VaultConfig struct {
+	Address string
+	Token   string
+	Mount   string // mount path of the KV v2 secrets engine
+	Timeout time.Duration
+}
+
+// NewVaultProvider returns a provider reading secrets from the KV v2 secrets engine of a HashiCorp Vault server
+func NewVaultProvider(conf VaultConfig) (Provider, error) {
+	address, err := url.Parse(conf.Address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid vault address: %w", err)
+	}
+	if conf.Token == "" {
+		return nil, errors.New("vault token is required")
+	}
+	return &vaultProvider{
+		address: address,
+		token:   conf.Token,
+		mount:   strings.Trim(conf.Mount, "/"),
+		client:  &http.Client{Timeout: conf.Timeout},
+	}, nil
+}
+
+type vaultProvider struct {
+	address *url.URL
+	token   string
+	mount   string
+	client  *http.Client
+}
+
+func (p *vaultProvider) Get(ctx context.Context, path string) (map[string]string, error) {
+	u := p.address.JoinPath("v1", p.mount, "data", strings.Trim(path, "/"))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", p.token)
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, fmt.Errorf("vault responded with %d", resp.StatusCode) // the body is left out, since it might echo the request
+	}
+	var secret struct {
+		Data struct {
+			Data json.RawMessage `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return nil, fmt.Errorf("unmarshalling vault response: %w", err)
+	}
+	return parseValues(secret.Data.Data)
+}
+
+// AWSConfig is the configuration of an AWS Secrets Manager provider.
+// Credentials are resolved through the default credentials chain if no access key is provided.
+type AWSConfig struct {
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	Endpoint        string // optional, for using compatible services
+}
+
+// NewAWSProvider returns a provider reading secrets from AWS Secrets Manager.
+// Secrets whose string value is a JSON object are returned with its values, otherwise their string value is returned as a plain value.
+func NewAWSProvider(conf AWSConfig) (Provider, error) {
+	sessionConfig := &awsutil.SessionConfig{
+		Region:      conf.Region,
+		AccessKeyID: conf.AccessKeyID,
+		AccessKey:   conf.SecretAccessKey,
+		Service:     secretsmanager.ServiceName,
+	}
+	if conf.Endpoint != "" {
+		sessionConfig.Endpoint = aws.String(conf.Endpoint)
+	}
+	sess, err := awsutil.CreateSession(sessionConfig)
+	if err != nil {
+		return nil, fmt.Errorf("creating aws session: %w", err)
+	}
+	return &awsProvider{client: secretsmanager.New(sess)}, nil
+}
+
+type awsProvider struct {
+	client *secretsmanager.SecretsManager
+}
+
+func (p *awsProvider) Get(ctx context.Context, path string) (map[string]string, error) {
+	out, err := p.client.GetSecretValueWithContext(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(path)})
+	if err != nil {
+		var awsErr awserr.Error
+		if errors.As(err, &awsErr) && awsErr.Code() == secretsmanager.ErrCodeResourceNotFoundException {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	value := aws.StringValue(out.SecretString)
+	if values, err := parseValues([]byte(value)); err == nil {
+		return values, nil
+	}
+	return map[string]string{"": value}, nil
+}
+
+// parseValues parses the values of a secret out of a JSON object. Values which aren't strings are kept in their JSON form.
+func parseValues(data []byte) (map[string]string, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, errors.New("secret is not a JSON object") // the error is not wrapped, since it might contain parts of the secret
+	}
+	values := make(map[string]string, len(raw))
+	for key, value := range raw {
+		var s string
+		if err := json.Unmarshal(value, &s); err != nil {
+			s = string(value)
+		}
+		values[key] = s
+	}
+	return values, nil
+}
@@ -0,0 +1,223 @@
+// Package secrets resolves secret references in destination configs through a pluggable secrets provider.
+//
+// A reference has the form ${secret:path#key}, where path identifies the secret in the provider and key is the
+// key of the value within the secret. The key can be omitted for secrets which consist of a single plain value.
+// References can either make up a whole config value or be embedded in it, e.g. "Bearer ${secret:api/tokens#bearer}".
+//
+// Resolved secrets are cached and refreshed periodically, so that rotated secrets are picked up without restarts.
+// Since resolved values end up in the in-memory config only, they can be redacted out of any text through [Redact].
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/samber/lo"
+
+	"github.com/rudderlabs/rudder-go-kit/config"
+	"github.com/rudderlabs/rudder-go-kit/logger"
+)
+
+const redacted = "***"
+
+var (
+	referenceRegex = regexp.MustCompile(`\$\{secret:([^#}]+)(?:#([^}]+))?\}`)
+
+	// ErrNotFound is returned by providers when a secret doesn't exist
+	ErrNotFound = errors.New("secret not found")
+
+	// redactor replaces all values resolved by any resolver of this process
+	redactor atomic.Pointer[strings.Replacer]
+)
+
+// Provider fetches secrets from a secrets store
+type Provider interface {
+	// Get returns the values of the secret at the given path, keyed by their key.
+	// Secrets consisting of a single plain value are returned under the empty key.
+	Get(ctx context.Context, path string) (map[string]string, error)
+}
+
+// New returns a resolver using the provider configured through BackendConfig.secrets.provider, i.e. one of
+//   - file: secrets are JSON files under BackendConfig.secrets.file.path, named after their path
+//   - vault: secrets are read from the KV v2 engine of a HashiCorp Vault server
+//   - aws: secrets are read from AWS Secrets Manager
+//
+// It returns a nil resolver if no provider is configured, which leaves references untouched.
+func New(conf *config.Config, log logger.Logger) (*Resolver, error) {
+	var provider Provider
+	var err error
+	switch providerType := conf.GetString("BackendConfig.secrets.provider", ""); providerType {
+	case "":
+		return nil, nil
+	case "file":
+		provider, err = NewFileProvider(conf.GetString("BackendConfig.secrets.file.path", "/etc/rudderstack/secrets"))
+	case "vault":
+		provider, err = NewVaultProvider(VaultConfig{
+			Address: conf.GetString("BackendConfig.secrets.vault.address", "http://localhost:8200"),
+			Token:   conf.GetStringVar("", "BackendConfig.secrets.vault.token", "VAULT_TOKEN"),
+			Mount:   conf.GetString("BackendConfig.secrets.vault.mount", "secret"),
+			Timeout: conf.GetDuration("BackendConfig.secrets.vault.timeout", 10, time.Second),
+		})
+	case "aws":
+		provider, err = NewAWSProvider(AWSConfig{
+			Region:          conf.GetString("BackendConfig.secrets.aws.region", ""),
+			AccessKeyID:     conf.GetString("BackendConfig.secrets.aws.accessKeyID", ""),
+			SecretAccessKey: conf.GetString("BackendConfig.secrets.aws.secretAccessKey", ""),
+			Endpoint:        conf.GetString("BackendConfig.secrets.aws.endpoint", ""),
+		})
+	default:
+		return nil, fmt.Errorf("unsupported secrets provider %q", providerType)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("setting up %q secrets provider: %w", conf.GetString("BackendConfig.secrets.provider", ""), err)
+	}
+	return NewResolver(provider, conf.GetDuration("BackendConfig.secrets.refreshInterval", 5, time.Minute), log), nil
+}
+
+// NewResolver returns a resolver fetching secrets through the provider, which are refreshed once they get older than refreshInterval
+func NewResolver(provider Provider, refreshInterval time.Duration, log logger.Logger) *Resolver {
+	return &Resolver{
+		provider:        provider,
+		refreshInterval: refreshInterval,
+		log:             log.Child("secrets"),
+		now:             time.Now,
+		secrets:         make(map[string]cachedSecret),
+	}
+}
+
+// Resolver resolves secret references through a [Provider]
+type Resolver struct {
+	provider        Provider
+	refreshInterval time.Duration
+	log             logger.Logger
+	now             func() time.Time
+
+	mu      sync.Mutex
+	secrets map[string]cachedSecret // path -> secret
+}
+
+type cachedSecret struct {
+	values    map[string]string
+	fetchedAt time.Time
+}
+
+// HasReferences returns true if the value, or any value nested in it, contains secret references
+func HasReferences(value interface{}) bool {
+	switch v := value.(type) {
+	case string:
+		return referenceRegex.MatchString(v)
+	case map[string]interface{}:
+		return lo.SomeBy(lo.Values(v), HasReferences)
+	case []interface{}:
+		return lo.SomeBy(v, HasReferences)
+	default:
+		return false
+	}
+}
+
+// Resolve returns a copy of the value with all secret references resolved. Values without references are returned as is.
+// References which cannot be resolved are left in place and reported through the returned error, which never contains secret values.
+// A nil resolver returns the value untouched.
+func (r *Resolver) Resolve(ctx context.Context, value interface{}) (interface{}, error) {
+	if r == nil || !HasReferences(value) {
+		return value, nil
+	}
+	switch v := value.(type) {
+	case string:
+		return r.resolveString(ctx, v)
+	case map[string]interface{}:
+		resolved := make(map[string]interface{}, len(v))
+		var errs []error
+		for key, nested := range v {
+			var err error
+			if resolved[key], err = r.Resolve(ctx, nested); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return resolved, errors.Join(errs...)
+	case []interface{}:
+		resolved := make([]interface{}, len(v))
+		var errs []error
+		for i, nested := range v {
+			var err error
+			if resolved[i], err = r.Resolve(ctx, nested); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return resolved, errors.Join(errs...)
+	default:
+		return value, nil
+	}
+}
+
+func (r *Resolver) resolveString(ctx context.Context, s string) (string, error) {
+	var errs []error
+	resolved := referenceRegex.ReplaceAllStringFunc(s, func(reference string) string {
+		match := referenceRegex.FindStringSubmatch(reference)
+		path, key := match[1], match[2]
+		values, err := r.secret(ctx, path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("resolving secret %q: %w", path, err))
+			return reference
+		}
+		value, ok := values[key]
+		if !ok {
+			errs = append(errs, fmt.Errorf("resolving secret %q: key %q not found", path, key))
+			return reference
+		}
+		return value
+	})
+	return resolved, errors.Join(errs...)
+}
+
+// secret returns the values of the secret at the path, fetching it if it isn't cached or needs to be refreshed.
+// If refreshing fails, the previously fetched values are returned.
+func (r *Resolver) secret(ctx context.Context, path string) (map[string]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cached, ok := r.secrets[path]
+	if ok && r.now().Sub(cached.fetchedAt) < r.refreshInterval {
+		return cached.values, nil
+	}
+	values, err := r.provider.Get(ctx, path)
+	if err != nil {
+		if ok {
+			r.log.Warnn("Failed to refresh secret, using previously fetched values",
+				logger.NewStringField("path", path),
+				logger.NewErrorField(err),
+			)
+			return cached.values, nil
+		}
+		return nil, err
+	}
+	r.secrets[path] = cachedSecret{values: values, fetchedAt: r.now()}
+	r.updateRedactor()
+	return values, nil
+}
+
+// updateRedactor rebuilds the redactor out of all values of the cached secrets
+func (r *Resolver) updateRedactor() {
+	var oldnew []string
+	for _, secret := range r.secrets {
+		for _, value := range secret.values {
+			if len(value) >= 4 { // redacting shorter values would mangle unrelated text
+				oldnew = append(oldnew, value, redacted)
+			}
+		}
+	}
+	redactor.Store(strings.NewReplacer(oldnew...))
+}
+
+// Redact replaces all resolved secret values in the text with ***
+func Redact(s string) string {
+	if r := redactor.Load(); r != nil {
+		return r.Replace(s)
+	}
+	return s
+}
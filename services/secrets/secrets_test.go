@@ -0,0 +1,219 @@
+package secrets_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rudderlabs/rudder-go-kit/logger"
+	"github.com/rudderlabs/rudder-server/services/secrets"
+)
+
+type providerFunc func(ctx context.Context, path string) (map[string]string, error)
+
+func (f providerFunc) Get(ctx context.Context, path string) (map[string]string, error) {
+	return f(ctx, path)
+}
+
+// capturingLogger captures the messages along with the string values logged through Infof, Errorw and Warnn
+type capturingLogger struct {
+	logger.Logger
+	logs []string
+}
+
+func (l *capturingLogger) Child(string) logger.Logger { return l }
+
+func (l *capturingLogger) Infof(format string, args ...any) {
+	l.logs = append(l.logs, fmt.Sprintf(format, args...))
+}
+
+func (l *capturingLogger) Errorw(msg string, keysAndValues ...any) {
+	l.logs = append(l.logs, fmt.Sprintf("%s %v", msg, keysAndValues))
+}
+
+func (l *capturingLogger) Warnn(msg string, fields ...logger.Field) {
+	var values []any
+	for _, f := range fields {
+		if s, ok := f.Value().(string); ok {
+			values = append(values, f.Name(), s)
+		}
+	}
+	l.logs = append(l.logs, fmt.Sprintf("%s %v", msg, values))
+}
+
+func TestResolver(t *testing.T) {
+	ctx := context.Background()
+	var fetches int
+	var providerErr error
+	values := map[string]map[string]string{
+		"api/tokens": {"bearer": "bearer-token-1", "basic": "basic-token"},
+		"plain":      {"": "plain-value"},
+	}
+	provider := providerFunc(func(_ context.Context, path string) (map[string]string, error) {
+		fetches++
+		if providerErr != nil {
+			return nil, providerErr
+		}
+		v, ok := values[path]
+		if !ok {
+			return nil, secrets.ErrNotFound
+		}
+		return v, nil
+	})
+	r := secrets.NewResolver(provider, 50*time.Millisecond, logger.NOP)
+
+	t.Run("references are resolved in nested values", func(t *testing.T) {
+		config := map[string]interface{}{
+			"apiKey":  "${secret:plain}",
+			"headers": []interface{}{map[string]interface{}{"to": "Authorization", "from": "Bearer ${secret:api/tokens#bearer}"}},
+			"enabled": true,
+		}
+		resolved, err := r.Resolve(ctx, config)
+		require.NoError(t, err)
+		require.Equal(t, map[string]interface{}{
+			"apiKey":  "plain-value",
+			"headers": []interface{}{map[string]interface{}{"to": "Authorization", "from": "Bearer bearer-token-1"}},
+			"enabled": true,
+		}, resolved)
+		require.Equal(t, "${secret:plain}", config["apiKey"], "the original config is left untouched")
+		require.Equal(t, 2, fetches)
+		require.Equal(t, "token: ***", secrets.Redact("token: bearer-token-1"))
+	})
+
+	t.Run("resolved values are redacted in logs", func(t *testing.T) {
+		captured := &capturingLogger{Logger: logger.NOP}
+		log := secrets.NewRedactingLogger(captured).Child("destination")
+		log.Infof("sending with %s", "Bearer bearer-token-1")
+		log.Errorw("request failed", "response", map[string]string{"echo": "plain-value"}, "attempt", 1)
+		log.Warnn("request failed", logger.NewStringField("response", "basic-token"), logger.NewIntField("attempt", 1))
+		require.Equal(t, []string{
+			"sending with Bearer ***",
+			"request failed [response map[echo:***] attempt 1]",
+			"request failed [response ***]",
+		}, captured.logs)
+	})
+
+	t.Run("secrets are cached", func(t *testing.T) {
+		_, err := r.Resolve(ctx, "${secret:api/tokens#basic}")
+		require.NoError(t, err)
+		require.Equal(t, 2, fetches)
+	})
+
+	t.Run("secrets are refreshed", func(t *testing.T) {
+		time.Sleep(50 * time.Millisecond)
+		values["api/tokens"] = map[string]string{"bearer": "bearer-token-2"}
+		resolved, err := r.Resolve(ctx, "${secret:api/tokens#bearer}")
+		require.NoError(t, err)
+		require.Equal(t, "bearer-token-2", resolved)
+		require.Equal(t, "***", secrets.Redact("bearer-token-2"))
+	})
+
+	t.Run("stale secrets are used if refreshing fails", func(t *testing.T) {
+		time.Sleep(50 * time.Millisecond)
+		providerErr = errors.New("unavailable")
+		defer func() { providerErr = nil }()
+		resolved, err := r.Resolve(ctx, "${secret:api/tokens#bearer}")
+		require.NoError(t, err)
+		require.Equal(t, "bearer-token-2", resolved)
+	})
+
+	t.Run("unresolvable references are left in place", func(t *testing.T) {
+		resolved, err := r.Resolve(ctx, map[string]interface{}{"a": "${secret:missing#key}", "b": "${secret:api/tokens#missing}"})
+		require.ErrorIs(t, err, secrets.ErrNotFound)
+		require.ErrorContains(t, err, `key "missing" not found`)
+		require.Equal(t, map[string]interface{}{"a": "${secret:missing#key}", "b": "${secret:api/tokens#missing}"}, resolved)
+	})
+
+	t.Run("nil resolver", func(t *testing.T) {
+		var nilResolver *secrets.Resolver
+		resolved, err := nilResolver.Resolve(ctx, "${secret:plain}")
+		require.NoError(t, err)
+		require.Equal(t, "${secret:plain}", resolved)
+	})
+}
+
+func TestProviders(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("file", func(t *testing.T) {
+		root := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(root, "api"), 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join(root, "api", "tokens.json"), []byte(`{"bearer":"token","port":5432}`), 0o600))
+		p, err := secrets.NewFileProvider(root)
+		require.NoError(t, err)
+
+		values, err := p.Get(ctx, "api/tokens")
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"bearer": "token", "port": "5432"}, values)
+		_, err = p.Get(ctx, "api/missing")
+		require.ErrorIs(t, err, secrets.ErrNotFound)
+		_, err = p.Get(ctx, "../outside")
+		require.Error(t, err)
+	})
+
+	t.Run("vault", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-Vault-Token") != "root" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			if r.URL.Path != "/v1/kv/data/api/tokens" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write([]byte(`{"data":{"data":{"bearer":"token"},"metadata":{"version":1}}}`))
+		}))
+		defer srv.Close()
+		p, err := secrets.NewVaultProvider(secrets.VaultConfig{Address: srv.URL, Token: "root", Mount: "kv", Timeout: time.Second})
+		require.NoError(t, err)
+
+		values, err := p.Get(ctx, "api/tokens")
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"bearer": "token"}, values)
+		_, err = p.Get(ctx, "api/missing")
+		require.ErrorIs(t, err, secrets.ErrNotFound)
+
+		p, err = secrets.NewVaultProvider(secrets.VaultConfig{Address: srv.URL, Token: "invalid", Mount: "kv", Timeout: time.Second})
+		require.NoError(t, err)
+		_, err = p.Get(ctx, "api/tokens")
+		require.ErrorContains(t, err, "403")
+	})
+
+	t.Run("aws", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var input struct{ SecretId string }
+			_ = json.NewDecoder(r.Body).Decode(&input)
+			w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+			switch input.SecretId {
+			case "api/tokens":
+				_, _ = w.Write([]byte(`{"Name":"api/tokens","SecretString":"{\"bearer\":\"token\"}"}`))
+			case "plain":
+				_, _ = w.Write([]byte(`{"Name":"plain","SecretString":"plain-value"}`))
+			default:
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"__type":"ResourceNotFoundException","message":"not found"}`))
+			}
+		}))
+		defer srv.Close()
+		p, err := secrets.NewAWSProvider(secrets.AWSConfig{Region: "us-east-1", AccessKeyID: "id", SecretAccessKey: "secret", Endpoint: srv.URL})
+		require.NoError(t, err)
+
+		values, err := p.Get(ctx, "api/tokens")
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"bearer": "token"}, values)
+		values, err = p.Get(ctx, "plain")
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"": "plain-value"}, values)
+		_, err = p.Get(ctx, "missing")
+		require.ErrorIs(t, err, secrets.ErrNotFound)
+	})
+}
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS replay_jobs (
+    id BIGSERIAL PRIMARY KEY,
+    workspace_id TEXT NOT NULL,
+    source_id TEXT NOT NULL,
+    destination_ids TEXT[] NOT NULL DEFAULT '{}',
+    start_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    end_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    dry_run BOOLEAN NOT NULL DEFAULT FALSE,
+    status TEXT NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    last_key TEXT NOT NULL DEFAULT '',
+    files_processed BIGINT NOT NULL DEFAULT 0,
+    events_matched BIGINT NOT NULL DEFAULT 0,
+    events_replayed BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS replay_jobs_status_idx ON replay_jobs (status);
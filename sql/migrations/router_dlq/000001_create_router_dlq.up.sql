@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS router_dlq (
+    id BIGSERIAL PRIMARY KEY,
+    jobsdb TEXT NOT NULL,
+    job_id BIGINT NOT NULL,
+    workspace_id TEXT NOT NULL DEFAULT '',
+    source_id TEXT NOT NULL DEFAULT '',
+    destination_id TEXT NOT NULL DEFAULT '',
+    custom_val TEXT NOT NULL,
+    user_id TEXT NOT NULL DEFAULT '',
+    attempt_num INT NOT NULL,
+    error_code TEXT NOT NULL DEFAULT '',
+    error_response JSONB NOT NULL DEFAULT '{}'::JSONB,
+    parameters JSONB NOT NULL DEFAULT '{}'::JSONB,
+    event_payload JSONB NOT NULL,
+    event_count INT NOT NULL DEFAULT 1,
+    job_created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    aborted_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    replayed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS router_dlq_destination_id_error_code_idx ON router_dlq (destination_id, error_code);
+CREATE INDEX IF NOT EXISTS router_dlq_workspace_id_idx ON router_dlq (workspace_id);
+CREATE INDEX IF NOT EXISTS router_dlq_aborted_at_idx ON router_dlq (aborted_at);
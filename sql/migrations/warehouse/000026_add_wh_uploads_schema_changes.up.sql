@@ -0,0 +1,5 @@
+--
+-- wh_uploads
+--
+
+ALTER TABLE wh_uploads ADD COLUMN IF NOT EXISTS schema_changes JSONB;
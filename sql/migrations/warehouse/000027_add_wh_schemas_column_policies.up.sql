@@ -0,0 +1,5 @@
+--
+-- wh_schemas
+--
+
+ALTER TABLE wh_schemas ADD COLUMN IF NOT EXISTS column_policies JSONB;
@@ -0,0 +1,13 @@
+--
+-- wh_rejected_schema_changes
+--
+
+CREATE TABLE IF NOT EXISTS wh_rejected_schema_changes (
+    destination_id VARCHAR(64) NOT NULL,
+    namespace TEXT NOT NULL,
+    table_name TEXT NOT NULL,
+    changes JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (destination_id, namespace, table_name)
+);
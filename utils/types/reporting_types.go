@@ -30,6 +30,7 @@ const (
 	GATEWAY                = "gateway"
 	DESTINATION_FILTER     = "destination_filter"
 	TRACKINGPLAN_VALIDATOR = "tracking_plan_validator"
+	SCHEMA_ENFORCER        = "schema_enforcer"
 	USER_TRANSFORMER       = "user_transformer"
 	EVENT_FILTER           = "event_filter"
 	DEST_TRANSFORMER       = "dest_transformer"
@@ -37,6 +37,7 @@ type EventParams struct {
 	SourceTaskRunId string `json:"source_task_run_id"`
 	TraceParent     string `json:"traceparent"`
 	DestinationID   string `json:"destination_id"`
+	BotRule         string `json:"bot_rule"`
 }
 
 // UserSuppression is interface to access Suppress user feature
@@ -1032,3 +1032,109 @@ func (g *GRPC) GetFirstAbortedUploadInContinuousAbortsByDestination(
 
 	return &proto.FirstAbortedUploadInContinuousAbortsByDestinationResponse{Uploads: uploads}, nil
 }
+
+func (g *GRPC) GetWHUploadSchemaChanges(ctx context.Context, request *proto.WHUploadRequest) (*proto.WHUploadSchemaChangesResponse, error) {
+	g.logger.Infow("Getting warehouse upload schema changes",
+		lf.WorkspaceID, request.WorkspaceId,
+		lf.UploadJobID, request.UploadId,
+	)
+
+	upload, err := g.workspaceUpload(ctx, request.WorkspaceId, request.UploadId)
+	if err != nil {
+		return &proto.WHUploadSchemaChangesResponse{}, err
+	}
+	return toSchemaChangesResponse(upload), nil
+}
+
+func (g *GRPC) ReviewWHUploadSchemaChanges(ctx context.Context, request *proto.ReviewWHUploadSchemaChangesRequest) (*proto.WHUploadSchemaChangesResponse, error) {
+	schemaChangeStatus := model.SchemaChangeRejected
+	if request.Approve {
+		schemaChangeStatus = model.SchemaChangeApproved
+	}
+
+	log := g.logger.With(
+		lf.WorkspaceID, request.WorkspaceId,
+		lf.UploadJobID, request.UploadId,
+		lf.Status, schemaChangeStatus,
+	)
+	log.Infow("Reviewing warehouse upload schema changes")
+
+	if _, err := g.workspaceUpload(ctx, request.WorkspaceId, request.UploadId); err != nil {
+		return &proto.WHUploadSchemaChangesResponse{}, err
+	}
+
+	err := g.uploadRepo.ReviewSchemaChanges(ctx, request.UploadId, schemaChangeStatus)
+	if errors.Is(err, model.ErrNoPendingSchemaChanges) {
+		return &proto.WHUploadSchemaChangesResponse{},
+			status.Errorf(codes.Code(code.Code_FAILED_PRECONDITION), "no pending schema changes for sync id %d", request.UploadId)
+	}
+	if err != nil {
+		log.Warnw("unable to review schema changes", lf.Error, err.Error())
+
+		return &proto.WHUploadSchemaChangesResponse{},
+			status.Errorf(codes.Code(code.Code_INTERNAL), "unable to review schema changes for sync id %d: %v", request.UploadId, err)
+	}
+
+	upload, err := g.uploadRepo.Get(ctx, request.UploadId)
+	if err != nil {
+		return &proto.WHUploadSchemaChangesResponse{},
+			status.Errorf(codes.Code(code.Code_INTERNAL), "unable to get sync id %d: %v", request.UploadId, err)
+	}
+	return toSchemaChangesResponse(upload), nil
+}
+
+// workspaceUpload returns the upload, provided that it belongs to one of the sources of the workspace
+func (g *GRPC) workspaceUpload(ctx context.Context, workspaceID string, uploadID int64) (model.Upload, error) {
+	if uploadID < 1 {
+		return model.Upload{},
+			status.Errorf(codes.Code(code.Code_INVALID_ARGUMENT), "upload_id should be greater than 0")
+	}
+
+	sourceIDs := g.bcManager.SourceIDsByWorkspace()[workspaceID]
+	if len(sourceIDs) == 0 {
+		return model.Upload{},
+			status.Errorf(codes.Code(code.Code_UNAUTHENTICATED), "no sources found for workspace: %v", workspaceID)
+	}
+
+	upload, err := g.uploadRepo.Get(ctx, uploadID)
+	if errors.Is(err, model.ErrUploadNotFound) {
+		return model.Upload{},
+			status.Errorf(codes.Code(code.Code_NOT_FOUND), "no sync found for id %d", uploadID)
+	}
+	if err != nil {
+		return model.Upload{},
+			status.Errorf(codes.Code(code.Code_INTERNAL), "unable to get sync id %d: %v", uploadID, err)
+	}
+
+	if !slices.Contains(sourceIDs, upload.SourceID) {
+		return model.Upload{},
+			status.Error(codes.Code(code.Code_UNAUTHENTICATED), "unauthorized request")
+	}
+	return upload, nil
+}
+
+func toSchemaChangesResponse(upload model.Upload) *proto.WHUploadSchemaChangesResponse {
+	tableNames := lo.Keys(upload.SchemaChanges.Tables)
+	slices.Sort(tableNames)
+
+	tables := lo.Map(tableNames, func(tableName string, index int) *proto.WHTableSchemaChange {
+		change := upload.SchemaChanges.Tables[tableName]
+		return &proto.WHTableSchemaChange{
+			TableName:        tableName,
+			TableToBeCreated: change.TableToBeCreated,
+			AddedColumns:     change.AddedColumns,
+			AlteredColumns:   change.AlteredColumns,
+		}
+	})
+
+	res := &proto.WHUploadSchemaChangesResponse{
+		UploadId:     upload.ID,
+		UploadStatus: upload.Status,
+		Status:       upload.SchemaChanges.Status,
+		Tables:       tables,
+	}
+	if !upload.SchemaChanges.ReviewedAt.IsZero() {
+		res.ReviewedAt = timestamppb.New(upload.SchemaChanges.ReviewedAt)
+	}
+	return res
+}
@@ -892,6 +892,160 @@ func TestGRPC(t *testing.T) {
 			})
 		})
 
+		t.Run("Schema changes", func(t *testing.T) {
+			repoUpload := repo.NewUploads(db)
+			repoStaging := repo.NewStagingFiles(db)
+
+			stagingFileID, err := repoStaging.Insert(ctx, &model.StagingFileWithSchema{})
+			require.NoError(t, err)
+
+			uploadID, err := repoUpload.CreateWithStagingFiles(ctx, model.Upload{
+				SourceID:        sourceID,
+				DestinationID:   destinationID,
+				DestinationType: destinationType,
+				WorkspaceID:     workspaceID,
+				Status:          model.Waiting,
+			}, []*model.StagingFile{
+				{
+					ID:            stagingFileID,
+					SourceID:      sourceID,
+					DestinationID: destinationID,
+					WorkspaceID:   workspaceID,
+				},
+			})
+			require.NoError(t, err)
+
+			schemaChanges, err := json.Marshal(model.SchemaChanges{
+				Status: model.SchemaChangePending,
+				Tables: model.TableSchemaChanges{
+					"tracks": {
+						AddedColumns:   model.TableSchema{"context_ip": "string"},
+						AlteredColumns: model.TableSchema{"event_text": "text"},
+					},
+					"product_viewed": {
+						TableToBeCreated: true,
+						AddedColumns:     model.TableSchema{"id": "string"},
+					},
+				},
+			})
+			require.NoError(t, err)
+			require.NoError(t, repoUpload.Update(ctx, uploadID, []repo.UpdateKeyValue{
+				repo.UploadFieldStatus(model.PendingSchemaApproval),
+				repo.UploadFieldSchemaChanges(schemaChanges),
+			}))
+
+			t.Run("GetWHUploadSchemaChanges", func(t *testing.T) {
+				t.Run("invalid id", func(t *testing.T) {
+					res, err := grpcClient.GetWHUploadSchemaChanges(ctx, &proto.WHUploadRequest{
+						UploadId:    -1,
+						WorkspaceId: workspaceID,
+					})
+					require.Error(t, err)
+					require.Empty(t, res)
+
+					statusError, ok := status.FromError(err)
+					require.True(t, ok)
+					require.Equal(t, codes.InvalidArgument, statusError.Code())
+				})
+
+				t.Run("unknown id", func(t *testing.T) {
+					res, err := grpcClient.GetWHUploadSchemaChanges(ctx, &proto.WHUploadRequest{
+						UploadId:    100001,
+						WorkspaceId: workspaceID,
+					})
+					require.Error(t, err)
+					require.Empty(t, res)
+
+					statusError, ok := status.FromError(err)
+					require.True(t, ok)
+					require.Equal(t, codes.NotFound, statusError.Code())
+					require.Equal(t, "no sync found for id 100001", statusError.Message())
+				})
+
+				t.Run("unauthorized", func(t *testing.T) {
+					res, err := grpcClient.GetWHUploadSchemaChanges(ctx, &proto.WHUploadRequest{
+						UploadId:    uploadID,
+						WorkspaceId: unusedWorkspaceID,
+					})
+					require.Error(t, err)
+					require.Empty(t, res)
+
+					statusError, ok := status.FromError(err)
+					require.True(t, ok)
+					require.Equal(t, codes.Unauthenticated, statusError.Code())
+					require.Equal(t, "unauthorized request", statusError.Message())
+				})
+
+				t.Run("success", func(t *testing.T) {
+					res, err := grpcClient.GetWHUploadSchemaChanges(ctx, &proto.WHUploadRequest{
+						UploadId:    uploadID,
+						WorkspaceId: workspaceID,
+					})
+					require.NoError(t, err)
+					require.EqualValues(t, uploadID, res.GetUploadId())
+					require.EqualValues(t, model.PendingSchemaApproval, res.GetUploadStatus())
+					require.EqualValues(t, model.SchemaChangePending, res.GetStatus())
+					require.Nil(t, res.GetReviewedAt())
+					require.Len(t, res.GetTables(), 2)
+					require.Equal(t, "product_viewed", res.GetTables()[0].GetTableName())
+					require.True(t, res.GetTables()[0].GetTableToBeCreated())
+					require.Equal(t, map[string]string{"id": "string"}, res.GetTables()[0].GetAddedColumns())
+					require.Equal(t, "tracks", res.GetTables()[1].GetTableName())
+					require.False(t, res.GetTables()[1].GetTableToBeCreated())
+					require.Equal(t, map[string]string{"context_ip": "string"}, res.GetTables()[1].GetAddedColumns())
+					require.Equal(t, map[string]string{"event_text": "text"}, res.GetTables()[1].GetAlteredColumns())
+				})
+			})
+
+			t.Run("ReviewWHUploadSchemaChanges", func(t *testing.T) {
+				t.Run("unauthorized", func(t *testing.T) {
+					res, err := grpcClient.ReviewWHUploadSchemaChanges(ctx, &proto.ReviewWHUploadSchemaChangesRequest{
+						UploadId:    uploadID,
+						WorkspaceId: unusedWorkspaceID,
+						Approve:     true,
+					})
+					require.Error(t, err)
+					require.Empty(t, res)
+
+					statusError, ok := status.FromError(err)
+					require.True(t, ok)
+					require.Equal(t, codes.Unauthenticated, statusError.Code())
+				})
+
+				t.Run("success", func(t *testing.T) {
+					res, err := grpcClient.ReviewWHUploadSchemaChanges(ctx, &proto.ReviewWHUploadSchemaChangesRequest{
+						UploadId:    uploadID,
+						WorkspaceId: workspaceID,
+						Approve:     true,
+					})
+					require.NoError(t, err)
+					require.EqualValues(t, model.Waiting, res.GetUploadStatus())
+					require.EqualValues(t, model.SchemaChangeApproved, res.GetStatus())
+					require.NotNil(t, res.GetReviewedAt())
+					require.Len(t, res.GetTables(), 2)
+
+					upload, err := repoUpload.Get(ctx, uploadID)
+					require.NoError(t, err)
+					require.Equal(t, model.Waiting, upload.Status)
+					require.Equal(t, model.SchemaChangeApproved, upload.SchemaChanges.Status)
+				})
+
+				t.Run("not pending", func(t *testing.T) {
+					res, err := grpcClient.ReviewWHUploadSchemaChanges(ctx, &proto.ReviewWHUploadSchemaChangesRequest{
+						UploadId:    uploadID,
+						WorkspaceId: workspaceID,
+					})
+					require.Error(t, err)
+					require.Empty(t, res)
+
+					statusError, ok := status.FromError(err)
+					require.True(t, ok)
+					require.Equal(t, codes.FailedPrecondition, statusError.Code())
+					require.Equal(t, fmt.Sprintf("no pending schema changes for sync id %d", uploadID), statusError.Message())
+				})
+			})
+		})
+
 		t.Run("Validate", func(t *testing.T) {
 			t.Run("warehouse destination", func(t *testing.T) {
 				t.Run("invalid payload", func(t *testing.T) {
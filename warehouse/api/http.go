@@ -66,6 +66,14 @@ type triggerUploadRequest struct {
 	DestinationID string `json:"destination_id"`
 }
 
+type schemaChangesResponse struct {
+	UploadID     int64                    `json:"upload_id"`
+	UploadStatus string                   `json:"upload_status"`
+	Status       string                   `json:"status"`
+	Tables       model.TableSchemaChanges `json:"tables"`
+	ReviewedAt   *time.Time               `json:"reviewed_at,omitempty"`
+}
+
 type Api struct {
 	mode          string
 	logger        logger.Logger
@@ -170,6 +178,10 @@ func (a *Api) addMasterEndpoints(ctx context.Context, r chi.Router) {
 			r.Post("/pending-events", a.logMiddleware(a.pendingEventsHandler))
 			r.Post("/trigger-upload", a.logMiddleware(a.triggerUploadHandler))
 
+			r.Get("/uploads/{id}/schema-changes", a.logMiddleware(a.schemaChangesHandler))
+			r.Post("/uploads/{id}/schema-changes/approve", a.logMiddleware(a.reviewSchemaChangesHandler(model.SchemaChangeApproved)))
+			r.Post("/uploads/{id}/schema-changes/reject", a.logMiddleware(a.reviewSchemaChangesHandler(model.SchemaChangeRejected)))
+
 			r.Post("/jobs", a.logMiddleware(a.sourceManager.InsertJobHandler))       // TODO: add degraded mode
 			r.Get("/jobs/status", a.logMiddleware(a.sourceManager.StatusJobHandler)) // TODO: add degraded mode
 
@@ -403,6 +415,80 @@ func (a *Api) triggerUploadHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+// schemaChangesHandler returns the schema changes introduced by an upload along with their review status
+func (a *Api) schemaChangesHandler(w http.ResponseWriter, r *http.Request) {
+	uploadID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || uploadID < 1 {
+		http.Error(w, ierrors.ErrInvalidUploadID.Error(), http.StatusBadRequest)
+		return
+	}
+	a.writeSchemaChanges(w, r, uploadID)
+}
+
+// reviewSchemaChangesHandler approves or rejects the schema changes of an upload pending schema approval, resuming the upload
+func (a *Api) reviewSchemaChangesHandler(schemaChangeStatus model.SchemaChangeStatus) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		uploadID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil || uploadID < 1 {
+			http.Error(w, ierrors.ErrInvalidUploadID.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := a.uploadRepo.ReviewSchemaChanges(r.Context(), uploadID, schemaChangeStatus); err != nil {
+			if errors.Is(err, model.ErrNoPendingSchemaChanges) {
+				http.Error(w, err.Error(), http.StatusConflict)
+				return
+			}
+			if errors.Is(r.Context().Err(), context.Canceled) {
+				http.Error(w, ierrors.ErrRequestCancelled.Error(), http.StatusBadRequest)
+				return
+			}
+			a.logger.Errorw("reviewing schema changes", lf.UploadJobID, uploadID, lf.Status, schemaChangeStatus, lf.Error, err.Error())
+			http.Error(w, "can't review schema changes", http.StatusInternalServerError)
+			return
+		}
+		a.logger.Infow("reviewed schema changes", lf.UploadJobID, uploadID, lf.Status, schemaChangeStatus)
+
+		a.writeSchemaChanges(w, r, uploadID)
+	}
+}
+
+func (a *Api) writeSchemaChanges(w http.ResponseWriter, r *http.Request, uploadID int64) {
+	upload, err := a.uploadRepo.Get(r.Context(), uploadID)
+	if err != nil {
+		if errors.Is(err, model.ErrUploadNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if errors.Is(r.Context().Err(), context.Canceled) {
+			http.Error(w, ierrors.ErrRequestCancelled.Error(), http.StatusBadRequest)
+			return
+		}
+		a.logger.Errorw("fetching upload for schema changes", lf.UploadJobID, uploadID, lf.Error, err.Error())
+		http.Error(w, "can't fetch schema changes", http.StatusInternalServerError)
+		return
+	}
+
+	response := schemaChangesResponse{
+		UploadID:     upload.ID,
+		UploadStatus: upload.Status,
+		Status:       upload.SchemaChanges.Status,
+		Tables:       upload.SchemaChanges.Tables,
+	}
+	if !upload.SchemaChanges.ReviewedAt.IsZero() {
+		response.ReviewedAt = &upload.SchemaChanges.ReviewedAt
+	}
+
+	resBody, err := json.Marshal(response)
+	if err != nil {
+		a.logger.Errorw("marshalling response for schema changes", lf.Error, err.Error())
+		http.Error(w, ierrors.ErrMarshallResponse.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	_, _ = w.Write(resBody)
+}
+
 func (a *Api) fetchTablesHandler(w http.ResponseWriter, r *http.Request) {
 	defer func() { _ = r.Body.Close() }()
 
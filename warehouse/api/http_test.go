@@ -9,10 +9,12 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strconv"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/ory/dockertest/v3"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
@@ -743,6 +745,114 @@ func TestHTTPApi(t *testing.T) {
 		})
 	})
 
+	t.Run("schema changes handlers", func(t *testing.T) {
+		schemaChangesRequest := func(method, uploadID string) *http.Request {
+			req := httptest.NewRequest(method, "/v1/warehouse/uploads/"+uploadID+"/schema-changes", nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", uploadID)
+			return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+		}
+
+		tables := model.TableSchemaChanges{
+			"tracks": {
+				AddedColumns:   model.TableSchema{"context_ip": "string"},
+				AlteredColumns: model.TableSchema{"event_text": "text"},
+			},
+		}
+		schemaChanges, err := json.Marshal(model.SchemaChanges{
+			Status: model.SchemaChangePending,
+			Tables: tables,
+		})
+		require.NoError(t, err)
+
+		schemaChangesStagingID, err := stagingRepo.Insert(ctx, &stagingFile)
+		require.NoError(t, err)
+		schemaChangesUploadID, err := uploadsRepo.CreateWithStagingFiles(ctx, model.Upload{
+			WorkspaceID:     workspaceID,
+			Namespace:       namespace,
+			SourceID:        "schema_changes_test_source_id",
+			DestinationID:   destinationID,
+			DestinationType: destinationType,
+			Status:          model.Waiting,
+		}, []*model.StagingFile{{
+			ID:            schemaChangesStagingID,
+			SourceID:      "schema_changes_test_source_id",
+			DestinationID: destinationID,
+		}})
+		require.NoError(t, err)
+		require.NoError(t, uploadsRepo.Update(ctx, schemaChangesUploadID, []repo.UpdateKeyValue{
+			repo.UploadFieldStatus(model.PendingSchemaApproval),
+			repo.UploadFieldSchemaChanges(schemaChanges),
+		}))
+		id := strconv.FormatInt(schemaChangesUploadID, 10)
+
+		a := NewApi(config.MasterMode, config.New(), logger.NOP, stats.NOP, mockBackendConfig, db, n, tenantManager, bcManager, sourcesManager, triggerStore)
+
+		t.Run("invalid upload id", func(t *testing.T) {
+			for _, handler := range []http.HandlerFunc{
+				a.schemaChangesHandler,
+				a.reviewSchemaChangesHandler(model.SchemaChangeApproved),
+			} {
+				resp := httptest.NewRecorder()
+				handler(resp, schemaChangesRequest(http.MethodGet, "invalid"))
+				require.Equal(t, http.StatusBadRequest, resp.Code)
+
+				b, err := io.ReadAll(resp.Body)
+				require.NoError(t, err)
+				require.Equal(t, "invalid upload id\n", string(b))
+			}
+		})
+
+		t.Run("unknown upload", func(t *testing.T) {
+			resp := httptest.NewRecorder()
+			a.schemaChangesHandler(resp, schemaChangesRequest(http.MethodGet, "1000"))
+			require.Equal(t, http.StatusNotFound, resp.Code)
+
+			resp = httptest.NewRecorder()
+			a.reviewSchemaChangesHandler(model.SchemaChangeApproved)(resp, schemaChangesRequest(http.MethodPost, "1000"))
+			require.Equal(t, http.StatusConflict, resp.Code)
+		})
+
+		t.Run("pending schema changes", func(t *testing.T) {
+			resp := httptest.NewRecorder()
+			a.schemaChangesHandler(resp, schemaChangesRequest(http.MethodGet, id))
+			require.Equal(t, http.StatusOK, resp.Code)
+
+			var schemaChangesRes schemaChangesResponse
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&schemaChangesRes))
+			require.Equal(t, schemaChangesResponse{
+				UploadID:     schemaChangesUploadID,
+				UploadStatus: model.PendingSchemaApproval,
+				Status:       model.SchemaChangePending,
+				Tables:       tables,
+			}, schemaChangesRes)
+		})
+
+		t.Run("approve", func(t *testing.T) {
+			resp := httptest.NewRecorder()
+			a.reviewSchemaChangesHandler(model.SchemaChangeApproved)(resp, schemaChangesRequest(http.MethodPost, id))
+			require.Equal(t, http.StatusOK, resp.Code)
+
+			var schemaChangesRes schemaChangesResponse
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&schemaChangesRes))
+			require.Equal(t, model.Waiting, schemaChangesRes.UploadStatus)
+			require.Equal(t, model.SchemaChangeApproved, schemaChangesRes.Status)
+			require.Equal(t, tables, schemaChangesRes.Tables)
+			require.NotNil(t, schemaChangesRes.ReviewedAt)
+			require.Equal(t, now, schemaChangesRes.ReviewedAt.UTC())
+		})
+
+		t.Run("already reviewed", func(t *testing.T) {
+			resp := httptest.NewRecorder()
+			a.reviewSchemaChangesHandler(model.SchemaChangeRejected)(resp, schemaChangesRequest(http.MethodPost, id))
+			require.Equal(t, http.StatusConflict, resp.Code)
+
+			b, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.Equal(t, "no pending schema changes\n", string(b))
+		})
+	})
+
 	t.Run("endpoints", func(t *testing.T) {
 		t.Run("normal mode", func(t *testing.T) {
 			webPort, err := kithelper.GetFreePort()
@@ -851,6 +961,20 @@ func TestHTTPApi(t *testing.T) {
 				}()
 			})
 
+			t.Run("schema changes", func(t *testing.T) {
+				schemaChangesURL := fmt.Sprintf("%s/v1/warehouse/uploads/%d/schema-changes", serverURL, uploadID)
+				req, err := http.NewRequest(http.MethodGet, schemaChangesURL, nil)
+				require.NoError(t, err)
+
+				resp, err := (&http.Client{}).Do(req)
+				require.NoError(t, err)
+				require.Equal(t, http.StatusOK, resp.StatusCode)
+
+				defer func() {
+					httputil.CloseResponse(resp)
+				}()
+			})
+
 			t.Run("fetch tables", func(t *testing.T) {
 				for _, u := range []string{
 					fmt.Sprintf("%s/v1/warehouse/fetch-tables", serverURL),
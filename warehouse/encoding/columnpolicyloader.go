@@ -0,0 +1,101 @@
+package encoding
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/rudderlabs/rudder-server/warehouse/internal/model"
+)
+
+const tokenPrefix = "tok_"
+
+// columnPolicyLoader applies the column policies to the values before adding them to the underlying EventLoader.
+// Since it wraps the EventLoader, the policies are enforced the same way regardless of the load file type.
+type columnPolicyLoader struct {
+	EventLoader
+	policies model.TableColumnPolicies
+	salt     string
+}
+
+// WithColumnPolicies returns an EventLoader applying the column policies of a table to the values added to the loader.
+// The salt is used for hashing and tokenizing the values.
+func WithColumnPolicies(loader EventLoader, policies model.TableColumnPolicies, salt string) EventLoader {
+	if len(policies) == 0 {
+		return loader
+	}
+	return &columnPolicyLoader{
+		EventLoader: loader,
+		policies:    policies,
+		salt:        salt,
+	}
+}
+
+func (loader *columnPolicyLoader) AddColumn(columnName, columnType string, val interface{}) {
+	policy, ok := loader.policies.Column(columnName)
+	if !ok {
+		loader.EventLoader.AddColumn(columnName, columnType, val)
+		return
+	}
+
+	val = applyColumnPolicy(policy, columnType, val, loader.salt)
+	if val == nil {
+		loader.EventLoader.AddEmptyColumn(columnName)
+		return
+	}
+	loader.EventLoader.AddColumn(columnName, columnType, val)
+}
+
+func (loader *columnPolicyLoader) AddRow(columnNames, row []string) {
+	policyRow := make([]string, len(row))
+	for i, columnName := range columnNames {
+		policy, ok := loader.policies.Column(columnName)
+		if !ok {
+			policyRow[i] = row[i]
+			continue
+		}
+
+		if val, ok := applyColumnPolicy(policy, model.StringDataType, row[i], loader.salt).(string); ok {
+			policyRow[i] = val
+		}
+	}
+	loader.EventLoader.AddRow(columnNames, policyRow)
+}
+
+// applyColumnPolicy returns the value to be written in the load file for a column with a policy.
+// Hashing, tokenizing and truncating produce strings, so for columns of any other type the value is nulled out instead,
+// though such policies are already rejected by [model.ParseColumnPolicies] for columns of the upload schema.
+func applyColumnPolicy(policy model.ColumnPolicy, columnType string, val interface{}, salt string) interface{} {
+	if val == nil || policy.Type == model.NullColumnPolicy {
+		return nil
+	}
+	if columnType != model.StringDataType && columnType != model.TextDataType {
+		return nil
+	}
+
+	valString := fmt.Sprintf("%v", val)
+
+	switch policy.Type {
+	case model.HashColumnPolicy:
+		return hex.EncodeToString(keyedHash(salt, valString))
+	case model.TokenizeColumnPolicy:
+		return tokenPrefix + base64.RawURLEncoding.EncodeToString(keyedHash(salt, valString)[:16])
+	case model.TruncateColumnPolicy:
+		if runes := []rune(valString); len(runes) > policy.Length {
+			return string(runes[:policy.Length])
+		}
+		return valString
+	default:
+		return nil
+	}
+}
+
+// keyedHash returns the HMAC-SHA256 of the value keyed with the salt, both hashing and tokenizing rely on it.
+// Hashing keeps the full hex encoded MAC, whereas tokenizing keeps a shorter, prefixed url-safe encoding of it.
+func keyedHash(salt, val string) []byte {
+	mac := hmac.New(sha256.New, []byte(salt))
+	mac.Write([]byte(val))
+	return mac.Sum(nil)
+}
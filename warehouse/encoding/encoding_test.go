@@ -2,6 +2,10 @@ package encoding_test
 
 import (
 	"compress/gzip"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"io"
 	"os"
 	"strings"
@@ -380,3 +384,163 @@ func TestReaderLoader(t *testing.T) {
 		})
 	})
 }
+
+func TestColumnPolicies(t *testing.T) {
+	misc.Init()
+
+	tmpDir, err := misc.CreateTMPDIR()
+	require.NoError(t, err)
+
+	const salt = "salt"
+
+	var (
+		policies = model.TableColumnPolicies{
+			"email":   {Type: model.HashColumnPolicy},
+			"phone":   {Type: model.TruncateColumnPolicy, Length: 4},
+			"address": {Type: model.NullColumnPolicy},
+			"userid":  {Type: model.TokenizeColumnPolicy},
+			"age":     {Type: model.HashColumnPolicy},
+		}
+		columns = []string{"address", "age", "email", "event", "phone", "userid"}
+	)
+
+	hash := func(val string) string {
+		mac := hmac.New(sha256.New, []byte(salt))
+		mac.Write([]byte(val))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+	tokenize := func(val string) string {
+		mac := hmac.New(sha256.New, []byte(salt))
+		mac.Write([]byte(val))
+		return "tok_" + base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:16])
+	}
+
+	addColumns := func(c encoding.EventLoader) {
+		c.AddColumn("address", "string", "221B Baker Street")
+		c.AddColumn("age", "int", 42)
+		c.AddColumn("email", "string", "john@example.com")
+		c.AddColumn("event", "string", "signed_up")
+		c.AddColumn("phone", "string", "+1234567890")
+		c.AddColumn("userid", "text", "user-1")
+	}
+
+	testCases := []struct {
+		name            string
+		loadFileType    string
+		destinationType string
+	}{
+		{name: "CSV", loadFileType: warehouseutils.LoadFileTypeCsv, destinationType: warehouseutils.RS},
+		{name: "JSON", loadFileType: warehouseutils.LoadFileTypeJson, destinationType: warehouseutils.BQ},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			outputFilePath := tmpDir + "/" + uuid.New().String() + ".gz"
+
+			ef := encoding.NewFactory(config.New())
+
+			writer, err := ef.NewLoadFileWriter(tc.loadFileType, outputFilePath, nil, tc.destinationType)
+			require.NoError(t, err)
+			t.Cleanup(func() {
+				require.NoError(t, os.Remove(writer.GetLoadFile().Name()))
+			})
+
+			c := encoding.WithColumnPolicies(ef.NewEventLoader(writer, tc.loadFileType, tc.destinationType), policies, salt)
+			addColumns(c)
+			require.NoError(t, c.Write())
+
+			c = encoding.WithColumnPolicies(ef.NewEventLoader(writer, tc.loadFileType, tc.destinationType), policies, salt)
+			c.AddRow(columns, []string{"221B Baker Street", "42", "john@example.com", "signed_up", "+1234567890", "user-1"})
+			require.NoError(t, c.Write())
+			require.NoError(t, writer.Close())
+
+			f, err := os.Open(outputFilePath)
+			require.NoError(t, err)
+
+			gzipReader, err := gzip.NewReader(f)
+			require.NoError(t, err)
+
+			t.Cleanup(func() {
+				require.NoError(t, gzipReader.Close())
+			})
+
+			r := ef.NewEventReader(gzipReader, tc.destinationType)
+
+			output, err := r.Read(columns)
+			require.NoError(t, err)
+			require.Equal(t, []string{"", "", hash("john@example.com"), "signed_up", "+123", tokenize("user-1")}, output)
+
+			// values added as rows are strings, so every policy applies
+			output, err = r.Read(columns)
+			require.NoError(t, err)
+			require.Equal(t, []string{"", hash("42"), hash("john@example.com"), "signed_up", "+123", tokenize("user-1")}, output)
+		})
+	}
+
+	t.Run("Parquet", func(t *testing.T) {
+		var (
+			outputFilePath  = tmpDir + "/" + uuid.New().String() + ".parquet"
+			loadFileType    = warehouseutils.LoadFileTypeParquet
+			destinationType = warehouseutils.S3Datalake
+			schema          = model.TableSchema{
+				"address": "string",
+				"age":     "int",
+				"email":   "string",
+				"event":   "string",
+				"phone":   "string",
+				"userid":  "text",
+			}
+		)
+
+		ef := encoding.NewFactory(config.New())
+
+		writer, err := ef.NewLoadFileWriter(loadFileType, outputFilePath, schema, destinationType)
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			require.NoError(t, os.Remove(writer.GetLoadFile().Name()))
+		})
+
+		c := encoding.WithColumnPolicies(ef.NewEventLoader(writer, loadFileType, destinationType), policies, salt)
+		addColumns(c)
+		require.NoError(t, c.Write())
+		require.NoError(t, writer.Close())
+
+		f, err := local.NewLocalFileReader(outputFilePath)
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			require.NoError(t, f.Close())
+		})
+
+		type parquetData struct {
+			Address *string
+			Age     *int64
+			Email   *string
+			Event   *string
+			Phone   *string
+			Userid  *string
+		}
+
+		pr, err := reader.NewParquetReader(f, nil, 1)
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			pr.ReadStop()
+		})
+
+		data := make([]*parquetData, 1)
+		require.NoError(t, pr.Read(&data))
+		require.Len(t, data, 1)
+
+		require.Nil(t, data[0].Address)
+		require.Nil(t, data[0].Age)
+		require.Equal(t, hash("john@example.com"), *data[0].Email)
+		require.Equal(t, "signed_up", *data[0].Event)
+		require.Equal(t, "+123", *data[0].Phone)
+		require.Equal(t, tokenize("user-1"), *data[0].Userid)
+	})
+
+	t.Run("no policies", func(t *testing.T) {
+		ef := encoding.NewFactory(config.New())
+		loader := ef.NewEventLoader(nil, warehouseutils.LoadFileTypeCsv, warehouseutils.RS)
+		require.Same(t, loader, encoding.WithColumnPolicies(loader, nil, salt))
+	})
+}
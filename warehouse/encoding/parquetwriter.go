@@ -65,6 +65,13 @@ var rudderDataTypeToParquetDataType = map[string]map[string]string{
 		"string":   parquetString,
 		"datetime": parquetTimestampMicros,
 	},
+	warehouseutils.DUCKDB: {
+		"int":      parquetInt64,
+		"boolean":  parquetBoolean,
+		"float":    parquetDouble,
+		"string":   parquetString,
+		"datetime": parquetTimestampMicros,
+	},
 }
 
 type parquetWriter struct {
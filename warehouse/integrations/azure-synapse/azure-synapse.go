@@ -229,6 +229,16 @@ func (as *AzureSynapse) loadTable(
 	)
 	log.Infow("started loading")
 
+	mergeMode, err := warehouseutils.MergeMode(as.Warehouse, tableName)
+	if err != nil {
+		return nil, "", fmt.Errorf("getting merge mode: %w", err)
+	}
+	if mergeMode.Enabled {
+		if err := mergeMode.Validate(tableName, tableSchemaInUpload); err != nil {
+			return nil, "", fmt.Errorf("validating merge mode: %w", err)
+		}
+	}
+
 	fileNames, err := as.LoadFileDownLoader.Download(ctx, tableName)
 	if err != nil {
 		return nil, "", fmt.Errorf("downloading load files: %w", err)
@@ -320,7 +330,7 @@ func (as *AzureSynapse) loadTable(
 	log.Infow("deleting from load table")
 	rowsDeleted, err := as.deleteFromLoadTable(
 		ctx, txn, tableName,
-		stagingTableName,
+		stagingTableName, mergeMode,
 	)
 	if err != nil {
 		return nil, "", fmt.Errorf("delete from load table: %w", err)
@@ -330,6 +340,7 @@ func (as *AzureSynapse) loadTable(
 	rowsInserted, err := as.insertIntoLoadTable(
 		ctx, txn, tableName,
 		stagingTableName, sortedColumnKeys,
+		mergeMode,
 	)
 	if err != nil {
 		return nil, "", fmt.Errorf("insert into load table: %w", err)
@@ -489,11 +500,15 @@ func (as *AzureSynapse) deleteFromLoadTable(
 	txn *sqlmw.Tx,
 	tableName string,
 	stagingTableName string,
+	mergeMode model.MergeMode,
 ) (int64, error) {
 	primaryKey := "id"
 	if column, ok := primaryKeyMap[tableName]; ok {
 		primaryKey = column
 	}
+	if mergeMode.Enabled {
+		primaryKey = mergeMode.PrimaryKey
+	}
 
 	var additionalJoinClause string
 	if tableName == warehouseutils.DiscardsTable {
@@ -534,11 +549,15 @@ func (as *AzureSynapse) insertIntoLoadTable(
 	tableName string,
 	stagingTableName string,
 	sortedColumnKeys []string,
+	mergeMode model.MergeMode,
 ) (int64, error) {
 	partitionKey := "id"
 	if column, ok := partitionKeyMap[tableName]; ok {
 		partitionKey = column
 	}
+	if mergeMode.Enabled {
+		partitionKey = mergeMode.PrimaryKey
+	}
 
 	quotedColumnNames := warehouseutils.DoubleQuoteAndJoinByComma(
 		sortedColumnKeys,
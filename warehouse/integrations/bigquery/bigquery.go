@@ -331,6 +331,12 @@ func (bq *BigQuery) DeleteBy(ctx context.Context, tableNames []string, params wa
 	return nil
 }
 
+// customPartitionsEnabled returns true if the tables might be partitioned by a custom field, in which case loading
+// data must not target an ingestion-time partition.
+func (bq *BigQuery) customPartitionsEnabled() bool {
+	return bq.config.customPartitionsEnabled || slices.Contains(bq.config.customPartitionsEnabledWorkspaceIDs, bq.warehouse.WorkspaceID)
+}
+
 func partitionedTable(tableName, partitionDate string) string {
 	return fmt.Sprintf(`%s$%v`, tableName, strings.ReplaceAll(partitionDate, "-", ""))
 }
@@ -338,6 +344,14 @@ func partitionedTable(tableName, partitionDate string) string {
 func (bq *BigQuery) loadTable(ctx context.Context, tableName string) (
 	*types.LoadTableStats, *loadTableResponse, error,
 ) {
+	// Merging is only supported with merge mode due to its cost limitations in BigQuery.
+	// The identifies table is always appended, since loading the users table relies on its ingestion-time partition.
+	mergeMode, err := warehouseutils.MergeMode(bq.warehouse, tableName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("getting merge mode: %w", err)
+	}
+	shouldMerge := mergeMode.Enabled && tableName != warehouseutils.IdentifiesTable
+
 	log := bq.logger.With(
 		logfield.SourceID, bq.warehouse.Source.ID,
 		logfield.SourceType, bq.warehouse.Source.SourceDefinition.Name,
@@ -346,10 +360,16 @@ func (bq *BigQuery) loadTable(ctx context.Context, tableName string) (
 		logfield.WorkspaceID, bq.warehouse.WorkspaceID,
 		logfield.Namespace, bq.namespace,
 		logfield.TableName, tableName,
-		logfield.ShouldMerge, false, // we don't support merging in BigQuery due to its cost limitations
+		logfield.ShouldMerge, shouldMerge,
 	)
 	log.Infow("started loading")
 
+	if shouldMerge {
+		if err := mergeMode.Validate(tableName, bq.uploader.GetTableSchemaInUpload(tableName)); err != nil {
+			return nil, nil, fmt.Errorf("validating merge mode: %w", err)
+		}
+	}
+
 	loadFileLocations, err := bq.loadFileLocations(ctx, tableName)
 	if err != nil {
 		return nil, nil, fmt.Errorf("getting load file locations: %w", err)
@@ -363,6 +383,9 @@ func (bq *BigQuery) loadTable(ctx context.Context, tableName string) (
 	gcsRef.MaxBadRecords = 0
 	gcsRef.IgnoreUnknownValues = false
 
+	if shouldMerge {
+		return bq.loadTableByMerge(ctx, tableName, mergeMode.PrimaryKey, gcsRef, log)
+	}
 	return bq.loadTableByAppend(ctx, tableName, gcsRef, log)
 }
 
@@ -410,7 +433,7 @@ func (bq *BigQuery) loadTableByAppend(
 		tableName,
 		partitionDate,
 	)
-	if bq.config.customPartitionsEnabled || slices.Contains(bq.config.customPartitionsEnabledWorkspaceIDs, bq.warehouse.WorkspaceID) {
+	if bq.customPartitionsEnabled() {
 		outputTable = tableName
 	}
 
@@ -447,6 +470,127 @@ func (bq *BigQuery) loadTableByAppend(
 	return tableStats, response, nil
 }
 
+// loadTableByMerge loads data into a staging table and merges it into the main table using the primary key
+//
+// Rows of the staging table are deduplicated by the primary key, keeping the latest received one.
+// Existing rows of the main table with the same primary key are updated in place, keeping their partition, and the
+// rest are inserted. Similar to loadTableByAppend, inserted rows target the ingestion-time partition of the load date,
+// unless custom partitions are enabled, in which case BigQuery assigns them to partitions.
+func (bq *BigQuery) loadTableByMerge(
+	ctx context.Context,
+	tableName string,
+	primaryKey string,
+	gcsRef *bigquery.GCSReference,
+	log logger.Logger,
+) (*types.LoadTableStats, *loadTableResponse, error) {
+	tableSchemaInWarehouse := bq.uploader.GetTableSchemaInWarehouse(tableName)
+
+	stagingTableName := warehouseutils.StagingTableName(provider, tableName, tableNameLimit)
+	log = log.With(logfield.StagingTableName, stagingTableName)
+
+	log.Debugw("creating staging table")
+	err := bq.db.Dataset(bq.namespace).Table(stagingTableName).Create(ctx, &bigquery.TableMetadata{
+		Schema: getTableSchema(tableSchemaInWarehouse),
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("creating staging table: %w", err)
+	}
+	defer func() {
+		if err := bq.DeleteTable(ctx, stagingTableName); err != nil {
+			log.Warnw("dropping staging table", logfield.Error, err.Error())
+		}
+	}()
+
+	log.Infow("loading data into staging table")
+	job, err := bq.db.Dataset(bq.namespace).Table(stagingTableName).LoaderFrom(gcsRef).Run(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading data into staging table: %w", err)
+	}
+
+	log.Debugw("waiting for staging job to complete", "jobID", job.ID())
+	status, err := job.Wait(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("waiting for staging job: %w", err)
+	}
+	if err := status.Err(); err != nil {
+		return nil, nil, fmt.Errorf("status for staging job: %w", err)
+	}
+
+	columnNames := lo.Keys(tableSchemaInWarehouse)
+	slices.Sort(columnNames)
+
+	quotedColumnNames := lo.Map(columnNames, func(columnName string, _ int) string {
+		return fmt.Sprintf("`%s`", columnName)
+	})
+	stagingColumnNames := lo.Map(columnNames, func(columnName string, _ int) string {
+		return fmt.Sprintf("staging.`%s`", columnName)
+	})
+	updateSet := lo.Map(columnNames, func(columnName string, _ int) string {
+		return fmt.Sprintf("original.`%[1]s` = staging.`%[1]s`", columnName)
+	})
+
+	partitionDate := timeutil.Now().Format("2006-01-02")
+	if !bq.customPartitionsEnabled() {
+		quotedColumnNames = append([]string{"_PARTITIONTIME"}, quotedColumnNames...)
+		stagingColumnNames = append([]string{fmt.Sprintf("TIMESTAMP('%s')", partitionDate)}, stagingColumnNames...)
+	}
+
+	orderByColumn := primaryKey
+	if _, ok := tableSchemaInWarehouse["received_at"]; ok {
+		orderByColumn = "received_at"
+	}
+
+	sqlStatement := fmt.Sprintf("MERGE INTO `%[1]s`.`%[2]s` AS original USING ("+
+		"SELECT * EXCEPT (_rudder_row_number) FROM ("+
+		"SELECT *, ROW_NUMBER() OVER (PARTITION BY `%[4]s` ORDER BY `%[5]s` DESC) AS _rudder_row_number FROM `%[1]s`.`%[3]s`"+
+		") WHERE _rudder_row_number = 1"+
+		") AS staging ON original.`%[4]s` = staging.`%[4]s` "+
+		"WHEN MATCHED THEN UPDATE SET %[6]s "+
+		"WHEN NOT MATCHED THEN INSERT (%[7]s) VALUES (%[8]s);",
+		bq.namespace,                           // 1
+		tableName,                              // 2
+		stagingTableName,                       // 3
+		primaryKey,                             // 4
+		orderByColumn,                          // 5
+		strings.Join(updateSet, ", "),          // 6
+		strings.Join(quotedColumnNames, ", "),  // 7
+		strings.Join(stagingColumnNames, ", "), // 8
+	)
+
+	log.Infow("merging data into main table")
+	job, err = bq.getMiddleware().Run(ctx, bq.db.Query(sqlStatement))
+	if err != nil {
+		return nil, nil, fmt.Errorf("merging data into main table: %w", err)
+	}
+
+	log.Debugw("waiting for merge job to complete", "jobID", job.ID())
+	status, err = job.Wait(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("waiting for merge job: %w", err)
+	}
+	if err := status.Err(); err != nil {
+		return nil, nil, fmt.Errorf("status for merge job: %w", err)
+	}
+
+	log.Debugw("job statistics")
+	statistics, err := bq.jobStatistics(ctx, job)
+	if err != nil {
+		return nil, nil, fmt.Errorf("merge job statistics: %w", err)
+	}
+
+	log.Infow("completed loading")
+
+	tableStats := &types.LoadTableStats{}
+	if statistics.Query != nil && statistics.Query.DmlStats != nil {
+		tableStats.RowsInserted = statistics.Query.DmlStats.InsertedRowCount
+		tableStats.RowsUpdated = statistics.Query.DmlStats.UpdatedRowCount
+	}
+	response := &loadTableResponse{
+		partitionDate: partitionDate,
+	}
+	return tableStats, response, nil
+}
+
 // jobStatistics returns statistics for a job
 // In case of rate limit error, it returns empty statistics
 func (bq *BigQuery) jobStatistics(
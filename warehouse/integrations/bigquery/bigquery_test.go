@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"cloud.google.com/go/bigquery"
+	"github.com/samber/lo"
 	"go.uber.org/mock/gomock"
 	"google.golang.org/api/option"
 
@@ -27,6 +28,7 @@ import (
 
 	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
 	"github.com/rudderlabs/rudder-server/runner"
+	th "github.com/rudderlabs/rudder-server/testhelper"
 	"github.com/rudderlabs/rudder-server/testhelper/health"
 	"github.com/rudderlabs/rudder-server/testhelper/workspaceConfig"
 	"github.com/rudderlabs/rudder-server/utils/misc"
@@ -552,6 +554,145 @@ func TestIntegration(t *testing.T) {
 			)
 			require.Equal(t, records, whth.AppendTestRecords())
 		})
+		t.Run("merge mode", func(t *testing.T) {
+			tableName := "merge_mode_test_table"
+
+			mergeModeWarehouse := th.Clone(t, warehouse)
+			mergeModeWarehouse.Destination.Config[model.MergeModeSetting.String()] = map[string]any{
+				"enabled": true,
+			}
+
+			uploadOutput := whth.UploadLoadFile(t, fm, "../testdata/load.json.gz", tableName)
+
+			loadFiles := []warehouseutils.LoadFile{{Location: uploadOutput.Location}}
+			mockUploader := newMockUploader(t, loadFiles, tableName, schemaInUpload, schemaInWarehouse)
+
+			bq := whbigquery.New(config.New(), logger.NOP)
+			err := bq.Setup(ctx, mergeModeWarehouse, mockUploader)
+			require.NoError(t, err)
+
+			err = bq.CreateSchema(ctx)
+			require.NoError(t, err)
+
+			err = bq.CreateTable(ctx, tableName, schemaInWarehouse)
+			require.NoError(t, err)
+
+			loadTableStat, err := bq.LoadTable(ctx, tableName)
+			require.NoError(t, err)
+			require.Equal(t, loadTableStat.RowsInserted, int64(14))
+			require.Equal(t, loadTableStat.RowsUpdated, int64(0))
+
+			uploadOutput = whth.UploadLoadFile(t, fm, "../testdata/dedup.json.gz", tableName)
+
+			loadFiles = []warehouseutils.LoadFile{{Location: uploadOutput.Location}}
+			mockUploader = newMockUploader(t, loadFiles, tableName, schemaInUpload, schemaInWarehouse)
+
+			bq = whbigquery.New(config.New(), logger.NOP)
+			err = bq.Setup(ctx, mergeModeWarehouse, mockUploader)
+			require.NoError(t, err)
+
+			loadTableStat, err = bq.LoadTable(ctx, tableName)
+			require.NoError(t, err)
+			require.Equal(t, loadTableStat.RowsInserted, int64(0))
+			require.Equal(t, loadTableStat.RowsUpdated, int64(14))
+
+			records := bqHelper.RetrieveRecordsFromWarehouse(t, db,
+				fmt.Sprintf(`
+					SELECT
+					  id,
+					  received_at,
+					  test_bool,
+					  test_datetime,
+					  test_float,
+					  test_int,
+					  test_string
+					FROM %s.%s
+					WHERE _PARTITIONTIME BETWEEN TIMESTAMP('%s') AND TIMESTAMP('%s')
+					ORDER BY id;`,
+					namespace,
+					tableName,
+					time.Now().Add(-24*time.Hour).Format("2006-01-02"),
+					time.Now().Add(+24*time.Hour).Format("2006-01-02"),
+				),
+			)
+			require.Equal(t, records, whth.DedupTestRecords())
+		})
+		t.Run("merge mode with custom partition", func(t *testing.T) {
+			tableName := "merge_mode_partition_test_table"
+
+			mergeModeWarehouse := th.Clone(t, warehouse)
+			mergeModeWarehouse.Destination.Config[model.MergeModeSetting.String()] = map[string]any{
+				"enabled": true,
+			}
+
+			c := config.New()
+			c.Set("Warehouse.bigquery.customPartitionsEnabledWorkspaceIDs", []string{workspaceID})
+
+			uploadOutput := whth.UploadLoadFile(t, fm, "../testdata/load.json.gz", tableName)
+
+			loadFiles := []warehouseutils.LoadFile{{Location: uploadOutput.Location}}
+			mockUploader := newMockUploader(t, loadFiles, tableName, schemaInUpload, schemaInWarehouse)
+
+			bq := whbigquery.New(c, logger.NOP)
+			err := bq.Setup(ctx, mergeModeWarehouse, mockUploader)
+			require.NoError(t, err)
+
+			err = bq.CreateSchema(ctx)
+			require.NoError(t, err)
+
+			err = db.Dataset(namespace).Table(tableName).Create(ctx, &bigquery.TableMetadata{
+				Schema: []*bigquery.FieldSchema{{
+					Name: "received_at",
+					Type: bigquery.TimestampFieldType,
+				}},
+				TimePartitioning: &bigquery.TimePartitioning{
+					Field: "received_at",
+				},
+			})
+			require.NoError(t, err)
+
+			err = bq.AddColumns(ctx, tableName, lo.MapToSlice(lo.OmitByKeys(schemaInWarehouse, []string{"received_at"}), func(name, dataType string) warehouseutils.ColumnInfo {
+				return warehouseutils.ColumnInfo{Name: name, Type: dataType}
+			}))
+			require.NoError(t, err)
+
+			loadTableStat, err := bq.LoadTable(ctx, tableName)
+			require.NoError(t, err)
+			require.Equal(t, loadTableStat.RowsInserted, int64(14))
+			require.Equal(t, loadTableStat.RowsUpdated, int64(0))
+
+			uploadOutput = whth.UploadLoadFile(t, fm, "../testdata/dedup.json.gz", tableName)
+
+			loadFiles = []warehouseutils.LoadFile{{Location: uploadOutput.Location}}
+			mockUploader = newMockUploader(t, loadFiles, tableName, schemaInUpload, schemaInWarehouse)
+
+			bq = whbigquery.New(c, logger.NOP)
+			err = bq.Setup(ctx, mergeModeWarehouse, mockUploader)
+			require.NoError(t, err)
+
+			loadTableStat, err = bq.LoadTable(ctx, tableName)
+			require.NoError(t, err)
+			require.Equal(t, loadTableStat.RowsInserted, int64(0))
+			require.Equal(t, loadTableStat.RowsUpdated, int64(14))
+
+			records := bqHelper.RetrieveRecordsFromWarehouse(t, db,
+				fmt.Sprintf(`
+					SELECT
+					  id,
+					  received_at,
+					  test_bool,
+					  test_datetime,
+					  test_float,
+					  test_int,
+					  test_string
+					FROM %s.%s
+					ORDER BY id;`,
+					namespace,
+					tableName,
+				),
+			)
+			require.Equal(t, records, whth.DedupTestRecords())
+		})
 		t.Run("load file does not exists", func(t *testing.T) {
 			tableName := "load_file_not_exists_test_table"
 
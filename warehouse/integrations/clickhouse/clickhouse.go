@@ -822,6 +822,8 @@ func getSortKeyTuple(sortKeyFields []string) string {
 
 // CreateTable creates table with engine ReplacingMergeTree(), this is used for dedupe event data and replace it will the latest data if duplicate data found. This logic is handled by clickhouse
 // The engine differs from MergeTree in that it removes duplicate entries with the same sorting key value.
+// With merge mode enabled, the table is sorted by the primary key only and received_at, when present, is used as the version column, so that the latest row is kept for each primary key.
+// Since the sorting key of a table can't be changed afterwards, merge mode only applies to tables created while it is enabled, see validateMergeModeTable.
 func (ch *Clickhouse) CreateTable(ctx context.Context, tableName string, columns model.TableSchema) (err error) {
 	sortKeyFields := []string{"received_at", "id"}
 	if tableName == warehouseutils.DiscardsTable {
@@ -834,26 +836,53 @@ func (ch *Clickhouse) CreateTable(ctx context.Context, tableName string, columns
 	if tableName == warehouseutils.UsersTable {
 		return ch.createUsersTable(ctx, tableName, columns)
 	}
+
+	mergeMode, err := warehouseutils.MergeMode(ch.Warehouse, tableName)
+	if err != nil {
+		return fmt.Errorf("getting merge mode: %w", err)
+	}
+
+	notNullableColumns := sortKeyFields
+	var versionColumn string
+	if mergeMode.Enabled && !strings.HasPrefix(tableName, warehouseutils.CTStagingTablePrefix) {
+		if err := mergeMode.Validate(tableName, columns); err != nil {
+			return fmt.Errorf("validating merge mode: %w", err)
+		}
+		sortKeyFields = []string{mergeMode.PrimaryKey}
+		notNullableColumns = sortKeyFields
+		if _, ok := columns[partitionField]; ok {
+			versionColumn = partitionField
+			notNullableColumns = append([]string{versionColumn}, sortKeyFields...)
+		}
+	}
+
 	clusterClause := ""
 	engine := "ReplacingMergeTree"
-	engineOptions := ""
+	var engineOptions []string
 	cluster := ch.Warehouse.GetStringDestinationConfig(ch.conf, model.ClusterSetting)
 	if len(strings.TrimSpace(cluster)) > 0 {
 		clusterClause = fmt.Sprintf(`ON CLUSTER %q`, cluster)
 		engine = fmt.Sprintf(`%s%s`, "Replicated", engine)
-		engineOptions = fmt.Sprintf(`'/clickhouse/{cluster}/tables/%s/{database}/{table}', '{replica}'`, uuid.New().String())
+		engineOptions = append(engineOptions, fmt.Sprintf(`'/clickhouse/{cluster}/tables/%s/{database}/{table}'`, uuid.New().String()), `'{replica}'`)
+	}
+	if versionColumn != "" {
+		engineOptions = append(engineOptions, fmt.Sprintf(`%q`, versionColumn))
 	}
 	var orderByClause string
 	if len(sortKeyFields) > 0 {
 		orderByClause = fmt.Sprintf(`ORDER BY %s`, getSortKeyTuple(sortKeyFields))
 	}
 
+	// Rows are only replaced within the same partition, so tables versioned by the partition field are not partitioned.
 	var partitionByClause string
 	if _, ok := columns[partitionField]; ok {
 		partitionByClause = fmt.Sprintf(`PARTITION BY toDate(%s)`, partitionField)
 	}
+	if versionColumn != "" {
+		partitionByClause = ""
+	}
 
-	sqlStatement = fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %q.%q %s ( %v ) ENGINE = %s(%s) %s %s`, ch.Namespace, tableName, clusterClause, ch.ColumnsWithDataTypes(tableName, columns, sortKeyFields), engine, engineOptions, orderByClause, partitionByClause)
+	sqlStatement = fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %q.%q %s ( %v ) ENGINE = %s(%s) %s %s`, ch.Namespace, tableName, clusterClause, ch.ColumnsWithDataTypes(tableName, columns, notNullableColumns), engine, strings.Join(engineOptions, ", "), orderByClause, partitionByClause)
 
 	ch.logger.Infof("CH: Creating table in clickhouse for ch:%s : %v", ch.Warehouse.Destination.ID, sqlStatement)
 	_, err = ch.DB.ExecContext(ctx, sqlStatement)
@@ -1044,6 +1073,16 @@ func (ch *Clickhouse) LoadUserTables(ctx context.Context) (errorMap map[string]e
 }
 
 func (ch *Clickhouse) LoadTable(ctx context.Context, tableName string) (*types.LoadTableStats, error) {
+	mergeMode, err := warehouseutils.MergeMode(ch.Warehouse, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("getting merge mode: %w", err)
+	}
+	if mergeMode.Enabled {
+		if err := ch.validateMergeModeTable(ctx, tableName, mergeMode); err != nil {
+			return nil, fmt.Errorf("validating merge mode: %w", err)
+		}
+	}
+
 	preLoadTableCount, err := ch.totalCountIntable(ctx, tableName)
 	if err != nil {
 		return nil, fmt.Errorf("pre load table count: %w", err)
@@ -1086,6 +1125,25 @@ func (*Clickhouse) IsEmpty(context.Context, model.Warehouse) (empty bool, err er
 	return
 }
 
+// validateMergeModeTable returns an error if the table isn't sorted by the merge mode primary key.
+// This is the case for tables created before enabling merge mode, where loading would keep duplicates having different received_at.
+func (ch *Clickhouse) validateMergeModeTable(ctx context.Context, tableName string, mergeMode model.MergeMode) error {
+	var sortingKey string
+	err := ch.DB.QueryRowContext(ctx, `
+		SELECT sorting_key FROM system.tables WHERE database = ? AND name = ?;
+	`,
+		ch.Namespace,
+		tableName,
+	).Scan(&sortingKey)
+	if err != nil {
+		return fmt.Errorf("getting sorting key: %w", err)
+	}
+	if strings.Trim(sortingKey, "`") != mergeMode.PrimaryKey {
+		return fmt.Errorf("table %s is sorted by %s instead of the merge mode primary key %s, it must be recreated for merge mode to apply", tableName, sortingKey, mergeMode.PrimaryKey)
+	}
+	return nil
+}
+
 func (ch *Clickhouse) totalCountIntable(ctx context.Context, tableName string) (int64, error) {
 	var (
 		total        int64
@@ -30,6 +30,7 @@ import (
 	kithelper "github.com/rudderlabs/rudder-go-kit/testhelper"
 
 	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
+	th "github.com/rudderlabs/rudder-server/testhelper"
 	"github.com/rudderlabs/rudder-server/testhelper/backendconfigtest"
 	"github.com/rudderlabs/rudder-server/utils/misc"
 	"github.com/rudderlabs/rudder-server/warehouse/client"
@@ -565,6 +566,7 @@ func TestIntegration(t *testing.T) {
 			fileName                    string
 			S3EngineEnabledWorkspaceIDs []string
 			disableNullable             bool
+			mergeMode                   bool
 		}{
 			{
 				name:     "normal loading using downloading of load files",
@@ -586,6 +588,11 @@ func TestIntegration(t *testing.T) {
 				fileName:                    "testdata/load-copy.csv.gz",
 				disableNullable:             true,
 			},
+			{
+				name:      "normal loading using downloading of load files with merge mode",
+				fileName:  "testdata/load.csv.gz",
+				mergeMode: true,
+			},
 		}
 
 		for i, tc := range testCases {
@@ -615,6 +622,12 @@ func TestIntegration(t *testing.T) {
 					},
 				}
 
+				if tc.mergeMode {
+					warehouse.Destination.Config[model.MergeModeSetting.String()] = map[string]any{
+						"enabled": true,
+					}
+				}
+
 				t.Log("Preparing load files metadata")
 				f, err := os.Open(tc.fileName)
 				require.NoError(t, err)
@@ -746,6 +759,46 @@ func TestIntegration(t *testing.T) {
 				_, err = ch.LoadTable(ctx, table)
 				require.NoError(t, err)
 
+				if tc.mergeMode {
+					t.Log("Verifying merge mode")
+					var sortingKey, engineFull string
+					err = ch.DB.QueryRowContext(ctx, fmt.Sprintf(`select sorting_key, engine_full from system.tables where database = '%s' and name = '%s'`, warehouse.Namespace, table)).Scan(&sortingKey, &engineFull)
+					require.NoError(t, err)
+					require.Equal(t, "id", sortingKey)
+					require.Contains(t, engineFull, "ReplacingMergeTree(received_at)")
+
+					t.Log("Loading data into table again")
+					_, err = ch.LoadTable(ctx, table)
+					require.NoError(t, err)
+
+					var count, uniqueIDs int64
+					err = ch.DB.QueryRowContext(ctx, fmt.Sprintf(`select count(*), uniqExact(id) from %q.%q final`, warehouse.Namespace, table)).Scan(&count, &uniqueIDs)
+					require.NoError(t, err)
+					require.Equal(t, uniqueIDs, count)
+
+					t.Log("Loading data into table created without merge mode")
+					existingTable := "existing_test_table"
+
+					withoutMergeMode := th.Clone(t, warehouse)
+					delete(withoutMergeMode.Destination.Config, model.MergeModeSetting.String())
+
+					existingCH := clickhouse.New(conf, logger.NOP, stats.NOP)
+					err = existingCH.Setup(ctx, withoutMergeMode, mockUploader)
+					require.NoError(t, err)
+
+					err = existingCH.CreateTable(ctx, existingTable, model.TableSchema{
+						"id":          "string",
+						"received_at": "datetime",
+					})
+					require.NoError(t, err)
+
+					_, err = ch.LoadTable(ctx, existingTable)
+					require.ErrorContains(t, err, "table existing_test_table is sorted by received_at, id instead of the merge mode primary key id")
+
+					err = ch.DropTable(ctx, existingTable)
+					require.NoError(t, err)
+				}
+
 				t.Log("Drop table")
 				err = ch.DropTable(ctx, table)
 				require.NoError(t, err)
@@ -17,6 +17,7 @@ func MaxParallelLoadsMap(conf *config.Config) map[string]int {
 		whutils.S3Datalake:    conf.GetInt("Warehouse.s3_datalake.maxParallelLoads", 8),
 		whutils.GCSDatalake:   conf.GetInt("Warehouse.gcs_datalake.maxParallelLoads", 8),
 		whutils.AzureDatalake: conf.GetInt("Warehouse.azure_datalake.maxParallelLoads", 8),
+		whutils.DUCKDB:        conf.GetInt("Warehouse.duckdb.maxParallelLoads", 1),
 	}
 }
 
@@ -6,12 +6,16 @@ import (
 	"regexp"
 	"time"
 
+	"github.com/tidwall/gjson"
+
 	"github.com/rudderlabs/rudder-go-kit/config"
+	"github.com/rudderlabs/rudder-go-kit/filemanager"
 
 	"github.com/rudderlabs/rudder-server/warehouse/integrations/types"
 
 	"github.com/rudderlabs/rudder-server/warehouse/internal/model"
 
+	"github.com/rudderlabs/rudder-server/warehouse/integrations/datalake/iceberg"
 	schemarepository "github.com/rudderlabs/rudder-server/warehouse/integrations/datalake/schema-repository"
 
 	"github.com/rudderlabs/rudder-go-kit/logger"
@@ -38,6 +42,9 @@ type Datalake struct {
 	Uploader         warehouseutils.Uploader
 	conf             *config.Config
 	logger           logger.Logger
+
+	icebergCatalog *iceberg.Catalog // nil unless the iceberg table format is used
+	icebergStorage *iceberg.ObjectStorage
 }
 
 func New(conf *config.Config, log logger.Logger) *Datalake {
@@ -54,8 +61,72 @@ func (d *Datalake) Setup(_ context.Context, warehouse model.Warehouse, uploader
 	d.Uploader = uploader
 
 	d.SchemaRepository, err = schemarepository.NewSchemaRepository(d.conf, d.logger, d.Warehouse, d.Uploader)
+	if err != nil {
+		return err
+	}
 
-	return err
+	if d.Warehouse.GetStringDestinationConfig(d.conf, model.TableFormatSetting) == model.IcebergTableFormat {
+		if err = d.setupIceberg(); err != nil {
+			return fmt.Errorf("setting up iceberg: %w", err)
+		}
+	}
+	return nil
+}
+
+func (d *Datalake) setupIceberg() error {
+	provider := warehouseutils.ObjectStorageType(d.Warehouse.Destination.DestinationDefinition.Name, d.Warehouse.Destination.Config, d.Uploader.UseRudderStorage())
+	storageConfig := misc.GetObjectStorageConfig(misc.ObjectStorageOptsT{
+		Provider:         provider,
+		Config:           d.Warehouse.Destination.Config,
+		UseRudderStorage: d.Uploader.UseRudderStorage(),
+		WorkspaceID:      d.Warehouse.Destination.WorkspaceID,
+	})
+	fm, err := filemanager.New(&filemanager.Settings{
+		Provider: provider,
+		Config:   storageConfig,
+		Conf:     d.conf,
+	})
+	if err != nil {
+		return fmt.Errorf("creating file manager: %w", err)
+	}
+
+	// metadata files are created conditionally, so that concurrent commits cannot overwrite each other
+	var (
+		baseLocation string
+		create       iceberg.ObjectCreator
+	)
+	switch provider {
+	case warehouseutils.S3:
+		baseLocation = fmt.Sprintf("s3://%s", storageConfig["bucketName"])
+		s3Manager, ok := fm.(*filemanager.S3Manager)
+		if !ok {
+			return fmt.Errorf("unexpected file manager %T for %s", fm, provider)
+		}
+		create = iceberg.NewS3ObjectCreator(s3Manager)
+	case warehouseutils.GCS:
+		baseLocation = fmt.Sprintf("gs://%s", storageConfig["bucketName"])
+		conditionalFM, err := filemanager.New(&filemanager.Settings{
+			Provider:            provider,
+			Config:              storageConfig,
+			Conf:                d.conf,
+			GCSUploadIfNotExist: true,
+		})
+		if err != nil {
+			return fmt.Errorf("creating conditional file manager: %w", err)
+		}
+		create = iceberg.NewGCSObjectCreator(conditionalFM)
+	case warehouseutils.AzureBlob:
+		baseLocation = fmt.Sprintf("abfss://%s@%s.dfs.core.windows.net", storageConfig["containerName"], storageConfig["accountName"])
+		if create, err = iceberg.NewAzureObjectCreator(storageConfig, fm.Prefix()); err != nil {
+			return fmt.Errorf("creating azure object creator: %w", err)
+		}
+	default:
+		return fmt.Errorf("unsupported object storage %s", provider)
+	}
+
+	d.icebergStorage = iceberg.NewObjectStorage(fm, create, baseLocation)
+	d.icebergCatalog = iceberg.NewCatalog(d.icebergStorage, d.logger)
+	return nil
 }
 
 func (*Datalake) CrashRecover(context.Context) error {
@@ -72,7 +143,13 @@ func (d *Datalake) CreateSchema(ctx context.Context) (err error) {
 }
 
 func (d *Datalake) CreateTable(ctx context.Context, tableName string, columnMap model.TableSchema) (err error) {
-	return d.SchemaRepository.CreateTable(ctx, tableName, columnMap)
+	if err = d.SchemaRepository.CreateTable(ctx, tableName, columnMap); err != nil {
+		return err
+	}
+	if d.icebergCatalog != nil {
+		return d.icebergCatalog.EnsureTable(ctx, d.icebergTablePath(tableName), columnMap)
+	}
+	return nil
 }
 
 func (*Datalake) DropTable(context.Context, string) (err error) {
@@ -80,23 +157,90 @@ func (*Datalake) DropTable(context.Context, string) (err error) {
 }
 
 func (d *Datalake) AddColumns(ctx context.Context, tableName string, columnsInfo []warehouseutils.ColumnInfo) (err error) {
-	return d.SchemaRepository.AddColumns(ctx, tableName, columnsInfo)
+	if err = d.SchemaRepository.AddColumns(ctx, tableName, columnsInfo); err != nil {
+		return err
+	}
+	if d.icebergCatalog != nil {
+		columnMap := make(model.TableSchema, len(columnsInfo))
+		for _, columnInfo := range columnsInfo {
+			columnMap[columnInfo.Name] = columnInfo.Type
+		}
+		return d.icebergCatalog.EnsureTable(ctx, d.icebergTablePath(tableName), columnMap)
+	}
+	return nil
 }
 
 func (d *Datalake) AlterColumn(ctx context.Context, tableName, columnName, columnType string) (model.AlterTableResponse, error) {
-	return d.SchemaRepository.AlterColumn(ctx, tableName, columnName, columnType)
+	res, err := d.SchemaRepository.AlterColumn(ctx, tableName, columnName, columnType)
+	if err != nil {
+		return res, err
+	}
+	if d.icebergCatalog != nil {
+		return res, d.icebergCatalog.AlterColumn(ctx, d.icebergTablePath(tableName), columnName, columnType)
+	}
+	return res, nil
+}
+
+func (d *Datalake) LoadTable(ctx context.Context, tableName string) (*types.LoadTableStats, error) {
+	if d.icebergCatalog == nil {
+		d.logger.Infof("Skipping load for table %s : %s is a datalake destination", tableName, d.Warehouse.Destination.ID)
+		return &types.LoadTableStats{}, nil
+	}
+
+	rowsInserted, err := d.appendToIcebergTable(ctx, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("appending to iceberg table %s: %w", tableName, err)
+	}
+	return &types.LoadTableStats{RowsInserted: rowsInserted}, nil
 }
 
-func (d *Datalake) LoadTable(_ context.Context, tableName string) (*types.LoadTableStats, error) {
-	d.logger.Infof("Skipping load for table %s : %s is a datalake destination", tableName, d.Warehouse.Destination.ID)
-	return &types.LoadTableStats{}, nil
+// appendToIcebergTable commits the load files of the table as a new snapshot of its iceberg table.
+// The iceberg table is created or evolved beforehand, since it might predate the use of the iceberg table format.
+func (d *Datalake) appendToIcebergTable(ctx context.Context, tableName string) (int64, error) {
+	tablePath := d.icebergTablePath(tableName)
+	if err := d.icebergCatalog.EnsureTable(ctx, tablePath, d.Uploader.GetTableSchemaInUpload(tableName)); err != nil {
+		return 0, fmt.Errorf("ensuring table: %w", err)
+	}
+
+	loadFiles, err := d.Uploader.GetLoadFilesMetadata(ctx, warehouseutils.GetLoadFilesOptions{Table: tableName})
+	if err != nil {
+		return 0, fmt.Errorf("getting load files metadata: %w", err)
+	}
+	dataFiles := make([]iceberg.DataFile, 0, len(loadFiles))
+	for _, loadFile := range loadFiles {
+		location, err := d.icebergStorage.ObjectLocation(loadFile.Location)
+		if err != nil {
+			return 0, err
+		}
+		dataFiles = append(dataFiles, iceberg.DataFile{
+			Path:            location,
+			RecordCount:     gjson.GetBytes(loadFile.Metadata, "total_rows").Int(),
+			FileSizeInBytes: gjson.GetBytes(loadFile.Metadata, "content_length").Int(),
+		})
+	}
+	return d.icebergCatalog.Append(ctx, tablePath, dataFiles)
+}
+
+func (d *Datalake) icebergTablePath(tableName string) string {
+	return warehouseutils.GetTablePathInObjectStorage(d.Warehouse.Namespace, tableName)
 }
 
 func (*Datalake) DeleteBy(context.Context, []string, warehouseutils.DeleteByParams) (err error) {
 	return fmt.Errorf(warehouseutils.NotImplementedErrorCode)
 }
 
-func (d *Datalake) LoadUserTables(context.Context) map[string]error {
+func (d *Datalake) LoadUserTables(ctx context.Context) map[string]error {
+	if d.icebergCatalog != nil {
+		errorMap := map[string]error{}
+		for _, tableName := range []string{warehouseutils.IdentifiesTable, warehouseutils.UsersTable} {
+			if len(d.Uploader.GetTableSchemaInUpload(tableName)) == 0 {
+				continue
+			}
+			_, errorMap[tableName] = d.appendToIcebergTable(ctx, tableName)
+		}
+		return errorMap
+	}
+
 	d.logger.Infof("Skipping load for user tables : %s is a datalake destination", d.Warehouse.Destination.ID)
 	// return map with nil error entries for identifies and users(if any) tables
 	// this is so that they are marked as succeeded
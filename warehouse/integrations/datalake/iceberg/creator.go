@@ -0,0 +1,109 @@
+package iceberg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/rudderlabs/rudder-go-kit/filemanager"
+)
+
+// ObjectCreator creates the object with the given key, relative to the prefix of the storage, only if it doesn't exist.
+// It returns ErrAlreadyExists if the object exists, relying on the preconditions of the object storage for atomicity.
+type ObjectCreator func(ctx context.Context, key string, data []byte) error
+
+// NewS3ObjectCreator returns a creator putting objects in the bucket of the file manager with an If-None-Match precondition
+func NewS3ObjectCreator(fm *filemanager.S3Manager) ObjectCreator {
+	return func(ctx context.Context, key string, data []byte) error {
+		sess, err := fm.GetSession(ctx)
+		if err != nil {
+			return fmt.Errorf("starting s3 session: %w", err)
+		}
+		objectName := path.Join(fm.Prefix(), key)
+		_, err = s3.New(sess).PutObjectWithContext(ctx, &s3.PutObjectInput{
+			ACL:    aws.String("bucket-owner-full-control"),
+			Bucket: aws.String(fm.Bucket()),
+			Key:    aws.String(objectName),
+			Body:   bytes.NewReader(data),
+		}, request.WithSetRequestHeaders(map[string]string{"If-None-Match": "*"}))
+		var reqErr awserr.RequestFailure
+		// a conflict is returned while a concurrent conditional write of the same object is in progress
+		if errors.As(err, &reqErr) && (reqErr.StatusCode() == http.StatusPreconditionFailed || reqErr.StatusCode() == http.StatusConflict) {
+			return ErrAlreadyExists
+		}
+		if err != nil {
+			return fmt.Errorf("putting %s: %w", objectName, err)
+		}
+		return nil
+	}
+}
+
+// NewGCSObjectCreator returns a creator uploading objects through a GCS file manager which has been created with
+// GCSUploadIfNotExist, so that objects are uploaded with a DoesNotExist precondition
+func NewGCSObjectCreator(fm filemanager.FileManager) ObjectCreator {
+	return func(ctx context.Context, key string, data []byte) error {
+		err := uploadObject(ctx, fm, key, data)
+		if errors.Is(err, filemanager.ErrPreConditionFailed) {
+			return ErrAlreadyExists
+		}
+		return err
+	}
+}
+
+// NewAzureObjectCreator returns a creator uploading blobs to the container of the storage config with an If-None-Match precondition
+func NewAzureObjectCreator(storageConfig map[string]interface{}, prefix string) (ObjectCreator, error) {
+	containerName, _ := storageConfig["containerName"].(string)
+	accountName, _ := storageConfig["accountName"].(string)
+	if containerName == "" || accountName == "" {
+		return nil, errors.New("containerName and accountName are required")
+	}
+
+	endpoint := "blob.core.windows.net"
+	if configuredEndpoint, _ := storageConfig["endPoint"].(string); configuredEndpoint != "" {
+		endpoint = configuredEndpoint
+	}
+	baseURL := url.URL{Scheme: "https", Host: accountName + "." + endpoint}
+
+	var credential azblob.Credential
+	if useSASTokens, _ := storageConfig["useSASTokens"].(bool); useSASTokens {
+		sasToken, _ := storageConfig["sasToken"].(string)
+		baseURL.RawQuery = strings.TrimPrefix(sasToken, "?")
+		credential = azblob.NewAnonymousCredential()
+	} else {
+		accountKey, _ := storageConfig["accountKey"].(string)
+		sharedKeyCredential, err := azblob.NewSharedKeyCredential(accountName, accountKey)
+		if err != nil {
+			return nil, fmt.Errorf("creating shared key credential: %w", err)
+		}
+		credential = sharedKeyCredential
+	}
+	containerURL := azblob.NewServiceURL(baseURL, azblob.NewPipeline(credential, azblob.PipelineOptions{})).NewContainerURL(containerName)
+
+	return func(ctx context.Context, key string, data []byte) error {
+		objectName := path.Join(prefix, key)
+		_, err := azblob.UploadBufferToBlockBlob(ctx, data, containerURL.NewBlockBlobURL(objectName), azblob.UploadToBlockBlobOptions{
+			AccessConditions: azblob.BlobAccessConditions{
+				ModifiedAccessConditions: azblob.ModifiedAccessConditions{IfNoneMatch: azblob.ETagAny},
+			},
+		})
+		var storageErr azblob.StorageError
+		if errors.As(err, &storageErr) && (storageErr.ServiceCode() == azblob.ServiceCodeBlobAlreadyExists || storageErr.Response().StatusCode == http.StatusConflict || storageErr.Response().StatusCode == http.StatusPreconditionFailed) {
+			return ErrAlreadyExists
+		}
+		if err != nil {
+			return fmt.Errorf("uploading %s: %w", objectName, err)
+		}
+		return nil
+	}, nil
+}
@@ -0,0 +1,541 @@
+// Package iceberg maintains Apache Iceberg tables (format version 2) on top of the parquet files of the datalake destinations.
+//
+// Tables are kept following the layout of the Hadoop catalog, i.e. the metadata files of a table live under <table>/metadata
+// next to its data files, with version-hint.text pointing to the current metadata file. Query engines can read them by
+// registering the current metadata file with their catalog, or by using a Hadoop catalog on the same bucket.
+//
+// Tables are unpartitioned, and since the parquet files written by the datalake destinations lack field ids, columns are
+// mapped to their files through the default name mapping of the table. Commits create the next metadata file of a table
+// conditionally, so that concurrent writers cannot overwrite each other's commits, and conflicting commits are retried.
+package iceberg
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+
+	"github.com/rudderlabs/rudder-go-kit/logger"
+
+	"github.com/rudderlabs/rudder-server/warehouse/internal/model"
+)
+
+const (
+	formatVersion   = 2
+	mainBranch      = "main"
+	versionHintFile = "version-hint.text"
+
+	nameMappingProperty = "schema.name-mapping.default"
+	// appendIDProperty is the snapshot summary property identifying the data files appended by the snapshot
+	appendIDProperty = "rudder.append-id"
+)
+
+// dataTypesMap maps rudder data types to iceberg types
+var dataTypesMap = map[string]string{
+	model.BooleanDataType:  "boolean",
+	model.IntDataType:      "long",
+	model.BigIntDataType:   "long",
+	model.FloatDataType:    "double",
+	model.StringDataType:   "string",
+	model.TextDataType:     "string",
+	model.JSONDataType:     "string",
+	model.DateTimeDataType: "timestamptz",
+}
+
+// typePromotions are the type changes allowed by iceberg's schema evolution
+var typePromotions = map[string][]string{
+	"int":   {"long"},
+	"float": {"double"},
+}
+
+var (
+	// ErrIncompatibleTypeChange is returned when a column cannot be altered to the requested type
+	ErrIncompatibleTypeChange = errors.New("incompatible type change")
+	// ErrCommitConflict is returned when another writer has committed the next version of a table first
+	ErrCommitConflict = errors.New("commit conflict")
+)
+
+// TableMetadata is the metadata file of a table
+type TableMetadata struct {
+	FormatVersion      int                    `json:"format-version"`
+	TableUUID          string                 `json:"table-uuid"`
+	Location           string                 `json:"location"`
+	LastSequenceNumber int64                  `json:"last-sequence-number"`
+	LastUpdatedMS      int64                  `json:"last-updated-ms"`
+	LastColumnID       int                    `json:"last-column-id"`
+	CurrentSchemaID    int                    `json:"current-schema-id"`
+	Schemas            []Schema               `json:"schemas"`
+	DefaultSpecID      int                    `json:"default-spec-id"`
+	PartitionSpecs     []json.RawMessage      `json:"partition-specs"`
+	LastPartitionID    int                    `json:"last-partition-id"`
+	DefaultSortOrderID int                    `json:"default-sort-order-id"`
+	SortOrders         []json.RawMessage      `json:"sort-orders"`
+	Properties         map[string]string      `json:"properties"`
+	CurrentSnapshotID  *int64                 `json:"current-snapshot-id,omitempty"`
+	Refs               map[string]SnapshotRef `json:"refs"`
+	Snapshots          []Snapshot             `json:"snapshots"`
+	SnapshotLog        []SnapshotLogEntry     `json:"snapshot-log"`
+	MetadataLog        []MetadataLogEntry     `json:"metadata-log"`
+}
+
+// Schema is a schema of a table
+type Schema struct {
+	Type     string  `json:"type"`
+	SchemaID int     `json:"schema-id"`
+	Fields   []Field `json:"fields"`
+}
+
+// Field is a column of a schema
+type Field struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Required bool   `json:"required"`
+	Type     string `json:"type"`
+}
+
+// Snapshot is the state of a table at some point in time
+type Snapshot struct {
+	SnapshotID       int64             `json:"snapshot-id"`
+	ParentSnapshotID *int64            `json:"parent-snapshot-id,omitempty"`
+	SequenceNumber   int64             `json:"sequence-number"`
+	TimestampMS      int64             `json:"timestamp-ms"`
+	ManifestList     string            `json:"manifest-list"`
+	Summary          map[string]string `json:"summary"`
+	SchemaID         int               `json:"schema-id"`
+}
+
+// SnapshotRef is a named reference to a snapshot
+type SnapshotRef struct {
+	SnapshotID int64  `json:"snapshot-id"`
+	Type       string `json:"type"`
+}
+
+type SnapshotLogEntry struct {
+	TimestampMS int64 `json:"timestamp-ms"`
+	SnapshotID  int64 `json:"snapshot-id"`
+}
+
+type MetadataLogEntry struct {
+	TimestampMS  int64  `json:"timestamp-ms"`
+	MetadataFile string `json:"metadata-file"`
+}
+
+// CurrentSchema returns the current schema of the table
+func (m *TableMetadata) CurrentSchema() Schema {
+	for _, schema := range m.Schemas {
+		if schema.SchemaID == m.CurrentSchemaID {
+			return schema
+		}
+	}
+	return Schema{Type: "struct"}
+}
+
+// CurrentSnapshot returns the current snapshot of the table, if any
+func (m *TableMetadata) CurrentSnapshot() (Snapshot, bool) {
+	if m.CurrentSnapshotID == nil {
+		return Snapshot{}, false
+	}
+	return lo.Find(m.Snapshots, func(s Snapshot) bool { return s.SnapshotID == *m.CurrentSnapshotID })
+}
+
+type Catalog struct {
+	storage Storage
+	logger  logger.Logger
+	now     func() time.Time
+
+	maxCommitAttempts  int
+	commitRetryBackoff time.Duration
+}
+
+// NewCatalog returns a catalog keeping its tables in the storage
+func NewCatalog(storage Storage, log logger.Logger) *Catalog {
+	return &Catalog{
+		storage:            storage,
+		logger:             log.Child("iceberg"),
+		now:                time.Now,
+		maxCommitAttempts:  5,
+		commitRetryBackoff: time.Second,
+	}
+}
+
+// LoadTable returns the current metadata of the table at tablePath, or ErrNotFound if the table doesn't exist
+func (c *Catalog) LoadTable(ctx context.Context, tablePath string) (*TableMetadata, error) {
+	metadata, _, err := c.loadTable(ctx, tablePath)
+	return metadata, err
+}
+
+func (c *Catalog) loadTable(ctx context.Context, tablePath string) (*TableMetadata, int, error) {
+	hint, err := c.storage.Read(ctx, metadataKey(tablePath, versionHintFile))
+	if err != nil {
+		return nil, 0, err
+	}
+	version, err := strconv.Atoi(strings.TrimSpace(string(hint)))
+	if err != nil {
+		return nil, 0, fmt.Errorf("parsing version hint of %s: %w", tablePath, err)
+	}
+	data, err := c.storage.Read(ctx, metadataKey(tablePath, metadataFile(version)))
+	if err != nil {
+		return nil, 0, fmt.Errorf("reading metadata of %s: %w", tablePath, err)
+	}
+	// the version hint is written after the metadata file, so it might lag behind a concurrent commit
+	for {
+		next, err := c.storage.Read(ctx, metadataKey(tablePath, metadataFile(version+1)))
+		if errors.Is(err, ErrNotFound) {
+			break
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("reading metadata of %s: %w", tablePath, err)
+		}
+		data = next
+		version++
+	}
+	var metadata TableMetadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, 0, fmt.Errorf("unmarshalling metadata of %s: %w", tablePath, err)
+	}
+	return &metadata, version, nil
+}
+
+// EnsureTable creates the table at tablePath with the columns of the table schema if it doesn't exist,
+// otherwise it evolves the schema of the table by adding the columns it lacks
+func (c *Catalog) EnsureTable(ctx context.Context, tablePath string, tableSchema model.TableSchema) error {
+	return c.withCommitRetries(ctx, tablePath, func() error {
+		return c.ensureTable(ctx, tablePath, tableSchema)
+	})
+}
+
+func (c *Catalog) ensureTable(ctx context.Context, tablePath string, tableSchema model.TableSchema) error {
+	metadata, version, err := c.loadTable(ctx, tablePath)
+	if errors.Is(err, ErrNotFound) {
+		return c.createTable(ctx, tablePath, tableSchema)
+	}
+	if err != nil {
+		return err
+	}
+
+	schema := metadata.CurrentSchema()
+	var newFields []Field
+	for _, name := range sortedColumns(tableSchema) {
+		if slices.ContainsFunc(schema.Fields, func(f Field) bool { return f.Name == name }) {
+			continue
+		}
+		dataType, err := icebergType(tableSchema[name])
+		if err != nil {
+			return err
+		}
+		metadata.LastColumnID++
+		newFields = append(newFields, Field{ID: metadata.LastColumnID, Name: name, Type: dataType})
+	}
+	if len(newFields) == 0 {
+		return nil
+	}
+
+	c.logger.Infof("Adding %d columns to iceberg table %s", len(newFields), tablePath)
+	metadata.addSchema(append(slices.Clone(schema.Fields), newFields...))
+	return c.commit(ctx, tablePath, metadata, version)
+}
+
+// AlterColumn changes the type of a column of the table at tablePath, as long as iceberg allows it
+func (c *Catalog) AlterColumn(ctx context.Context, tablePath, columnName, columnType string) error {
+	return c.withCommitRetries(ctx, tablePath, func() error {
+		return c.alterColumn(ctx, tablePath, columnName, columnType)
+	})
+}
+
+func (c *Catalog) alterColumn(ctx context.Context, tablePath, columnName, columnType string) error {
+	metadata, version, err := c.loadTable(ctx, tablePath)
+	if err != nil {
+		return err
+	}
+	dataType, err := icebergType(columnType)
+	if err != nil {
+		return err
+	}
+
+	fields := slices.Clone(metadata.CurrentSchema().Fields)
+	i := slices.IndexFunc(fields, func(f Field) bool { return f.Name == columnName })
+	if i < 0 {
+		return fmt.Errorf("column %s does not exist in table %s", columnName, tablePath)
+	}
+	if fields[i].Type == dataType {
+		return nil
+	}
+	if !slices.Contains(typePromotions[fields[i].Type], dataType) {
+		return fmt.Errorf("altering column %s from %s to %s: %w", columnName, fields[i].Type, dataType, ErrIncompatibleTypeChange)
+	}
+
+	fields[i].Type = dataType
+	metadata.addSchema(fields)
+	return c.commit(ctx, tablePath, metadata, version)
+}
+
+// Append commits the data files to the table at tablePath as a new snapshot, returning the number of records added.
+// Appending files which have already been appended together is skipped, so that retrying an append doesn't duplicate data.
+func (c *Catalog) Append(ctx context.Context, tablePath string, files []DataFile) (int64, error) {
+	var addedRecords int64
+	err := c.withCommitRetries(ctx, tablePath, func() (err error) {
+		addedRecords, err = c.append(ctx, tablePath, files)
+		return err
+	})
+	return addedRecords, err
+}
+
+func (c *Catalog) append(ctx context.Context, tablePath string, files []DataFile) (int64, error) {
+	metadata, version, err := c.loadTable(ctx, tablePath)
+	if err != nil {
+		return 0, err
+	}
+
+	files = lo.UniqBy(files, func(file DataFile) string { return file.Path })
+	if len(files) == 0 {
+		return 0, nil
+	}
+	id := appendID(files)
+	if slices.ContainsFunc(metadata.Snapshots, func(s Snapshot) bool { return s.Summary[appendIDProperty] == id }) {
+		c.logger.Infof("Skipping append to iceberg table %s: the files are already part of it", tablePath)
+		return 0, nil
+	}
+
+	var manifests []manifestFile
+	parent, hasParent := metadata.CurrentSnapshot()
+	if hasParent {
+		if manifests, err = c.readManifestList(ctx, tablePath, metadata, parent.ManifestList); err != nil {
+			return 0, err
+		}
+	}
+
+	now := c.now()
+	snapshot := Snapshot{
+		SnapshotID:     rand.Int63(), // nolint:gosec // snapshot ids don't need to be cryptographically secure
+		SequenceNumber: metadata.LastSequenceNumber + 1,
+		TimestampMS:    now.UnixMilli(),
+		SchemaID:       metadata.CurrentSchemaID,
+		Summary:        map[string]string{"operation": "append", appendIDProperty: id},
+	}
+	var previousTotals map[string]string
+	if hasParent {
+		snapshot.ParentSnapshotID = &parent.SnapshotID
+		previousTotals = parent.Summary
+	}
+
+	var addedRecords, addedSize int64
+	for _, file := range files {
+		addedRecords += file.RecordCount
+		addedSize += file.FileSizeInBytes
+	}
+	for key, added := range map[string]int64{
+		"data-files": int64(len(files)),
+		"records":    addedRecords,
+		"files-size": addedSize,
+	} {
+		total, _ := strconv.ParseInt(previousTotals["total-"+key], 10, 64)
+		snapshot.Summary["added-"+key] = strconv.FormatInt(added, 10)
+		snapshot.Summary["total-"+key] = strconv.FormatInt(total+added, 10)
+	}
+
+	manifest, err := writeManifest(metadata.CurrentSchema(), snapshot.SnapshotID, files)
+	if err != nil {
+		return 0, fmt.Errorf("writing manifest: %w", err)
+	}
+	manifestKey := metadataKey(tablePath, fmt.Sprintf("%s-m0.avro", uuid.New()))
+	if err := c.storage.Write(ctx, manifestKey, manifest); err != nil {
+		return 0, fmt.Errorf("storing manifest: %w", err)
+	}
+
+	manifests = append(manifests, manifestFile{
+		"manifest_path":        c.storage.Location(manifestKey),
+		"manifest_length":      int64(len(manifest)),
+		"partition_spec_id":    int32(0),
+		"content":              int32(manifestContentData),
+		"sequence_number":      snapshot.SequenceNumber,
+		"min_sequence_number":  snapshot.SequenceNumber,
+		"added_snapshot_id":    snapshot.SnapshotID,
+		"added_files_count":    int32(len(files)),
+		"existing_files_count": int32(0),
+		"deleted_files_count":  int32(0),
+		"added_rows_count":     addedRecords,
+		"existing_rows_count":  int64(0),
+		"deleted_rows_count":   int64(0),
+	})
+	manifestList, err := writeManifestList(snapshot, manifests)
+	if err != nil {
+		return 0, fmt.Errorf("writing manifest list: %w", err)
+	}
+	manifestListKey := metadataKey(tablePath, fmt.Sprintf("snap-%d-1-%s.avro", snapshot.SnapshotID, uuid.New()))
+	if err := c.storage.Write(ctx, manifestListKey, manifestList); err != nil {
+		return 0, fmt.Errorf("storing manifest list: %w", err)
+	}
+	snapshot.ManifestList = c.storage.Location(manifestListKey)
+
+	metadata.LastSequenceNumber = snapshot.SequenceNumber
+	metadata.CurrentSnapshotID = &snapshot.SnapshotID
+	metadata.Refs[mainBranch] = SnapshotRef{SnapshotID: snapshot.SnapshotID, Type: "branch"}
+	metadata.Snapshots = append(metadata.Snapshots, snapshot)
+	metadata.SnapshotLog = append(metadata.SnapshotLog, SnapshotLogEntry{TimestampMS: snapshot.TimestampMS, SnapshotID: snapshot.SnapshotID})
+	if err := c.commit(ctx, tablePath, metadata, version); err != nil {
+		return 0, err
+	}
+	c.logger.Infof("Appended %d files with %d records to iceberg table %s in snapshot %d", len(files), addedRecords, tablePath, snapshot.SnapshotID)
+	return addedRecords, nil
+}
+
+func (c *Catalog) createTable(ctx context.Context, tablePath string, tableSchema model.TableSchema) error {
+	metadata := &TableMetadata{
+		FormatVersion:   formatVersion,
+		TableUUID:       uuid.New().String(),
+		Location:        c.storage.Location(tablePath),
+		CurrentSchemaID: -1,
+		PartitionSpecs:  []json.RawMessage{json.RawMessage(`{"spec-id":0,"fields":[]}`)},
+		LastPartitionID: 999, // partition field ids start at 1000
+		SortOrders:      []json.RawMessage{json.RawMessage(`{"order-id":0,"fields":[]}`)},
+		Properties:      map[string]string{"write.format.default": "parquet"},
+		Refs:            map[string]SnapshotRef{},
+		Snapshots:       []Snapshot{},
+		SnapshotLog:     []SnapshotLogEntry{},
+		MetadataLog:     []MetadataLogEntry{},
+	}
+	fields := make([]Field, 0, len(tableSchema))
+	for _, name := range sortedColumns(tableSchema) {
+		dataType, err := icebergType(tableSchema[name])
+		if err != nil {
+			return err
+		}
+		metadata.LastColumnID++
+		fields = append(fields, Field{ID: metadata.LastColumnID, Name: name, Type: dataType})
+	}
+	metadata.addSchema(fields)
+
+	c.logger.Infof("Creating iceberg table %s", tablePath)
+	return c.commit(ctx, tablePath, metadata, 0)
+}
+
+// addSchema adds a schema with the fields and makes it the current schema of the table, updating the name mapping accordingly
+func (m *TableMetadata) addSchema(fields []Field) {
+	schemaID := 0
+	for _, schema := range m.Schemas {
+		schemaID = max(schemaID, schema.SchemaID+1)
+	}
+	m.Schemas = append(m.Schemas, Schema{Type: "struct", SchemaID: schemaID, Fields: fields})
+	m.CurrentSchemaID = schemaID
+
+	type mappedField struct {
+		FieldID int      `json:"field-id"`
+		Names   []string `json:"names"`
+	}
+	nameMapping, _ := json.Marshal(lo.Map(fields, func(f Field, _ int) mappedField {
+		return mappedField{FieldID: f.ID, Names: []string{f.Name}}
+	}))
+	m.Properties[nameMappingProperty] = string(nameMapping)
+}
+
+// withCommitRetries runs the operation, which loads the table and commits a new version of it,
+// again as long as its commit conflicts with the commit of another writer
+func (c *Catalog) withCommitRetries(ctx context.Context, tablePath string, operation func() error) error {
+	var err error
+	for attempt := 1; attempt <= c.maxCommitAttempts; attempt++ {
+		if err = operation(); !errors.Is(err, ErrCommitConflict) {
+			return err
+		}
+		c.logger.Warnf("Retrying conflicting commit to iceberg table %s after attempt %d", tablePath, attempt)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt) * c.commitRetryBackoff):
+		}
+	}
+	return err
+}
+
+// commit creates the next version of the metadata of the table, returning ErrCommitConflict if another writer has already created it
+func (c *Catalog) commit(ctx context.Context, tablePath string, metadata *TableMetadata, version int) error {
+	nextVersion := version + 1
+	nextKey := metadataKey(tablePath, metadataFile(nextVersion))
+
+	now := c.now().UnixMilli()
+	if version > 0 {
+		metadata.MetadataLog = append(metadata.MetadataLog, MetadataLogEntry{
+			TimestampMS:  metadata.LastUpdatedMS,
+			MetadataFile: c.storage.Location(metadataKey(tablePath, metadataFile(version))),
+		})
+	}
+	metadata.LastUpdatedMS = now
+
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("marshalling metadata of %s: %w", tablePath, err)
+	}
+	if err := c.storage.Create(ctx, nextKey, data); errors.Is(err, ErrAlreadyExists) {
+		return fmt.Errorf("committing version %d of %s: %w", nextVersion, tablePath, ErrCommitConflict)
+	} else if err != nil {
+		return fmt.Errorf("storing metadata of %s: %w", tablePath, err)
+	}
+	if err := c.storage.Write(ctx, metadataKey(tablePath, versionHintFile), []byte(strconv.Itoa(nextVersion))); err != nil {
+		return fmt.Errorf("storing version hint of %s: %w", tablePath, err)
+	}
+	return nil
+}
+
+func (c *Catalog) readManifestList(ctx context.Context, tablePath string, metadata *TableMetadata, location string) ([]manifestFile, error) {
+	data, err := c.readMetadataFile(ctx, tablePath, metadata, location)
+	if err != nil {
+		return nil, err
+	}
+	manifests, err := readManifestList(data)
+	if err != nil {
+		return nil, fmt.Errorf("reading manifest list %s: %w", location, err)
+	}
+	return manifests, nil
+}
+
+// readMetadataFile reads a file of the table given its location, which has to be under the location of the table
+func (c *Catalog) readMetadataFile(ctx context.Context, tablePath string, metadata *TableMetadata, location string) ([]byte, error) {
+	relative, ok := strings.CutPrefix(location, metadata.Location)
+	if !ok {
+		return nil, fmt.Errorf("file %s is outside of the location of table %s", location, tablePath)
+	}
+	data, err := c.storage.Read(ctx, tablePath+relative)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", location, err)
+	}
+	return data, nil
+}
+
+// appendID identifies the data files of an append, regardless of their order
+func appendID(files []DataFile) string {
+	paths := lo.Map(files, func(file DataFile, _ int) string { return file.Path })
+	slices.Sort(paths)
+	digest := sha256.Sum256([]byte(strings.Join(paths, "\n")))
+	return hex.EncodeToString(digest[:])
+}
+
+func icebergType(columnType string) (string, error) {
+	dataType, ok := dataTypesMap[columnType]
+	if !ok {
+		return "", fmt.Errorf("unsupported data type %s", columnType)
+	}
+	return dataType, nil
+}
+
+func sortedColumns(tableSchema model.TableSchema) []string {
+	columns := lo.Keys(tableSchema)
+	slices.Sort(columns)
+	return columns
+}
+
+func metadataKey(tablePath, file string) string {
+	return tablePath + "/metadata/" + file
+}
+
+func metadataFile(version int) string {
+	return fmt.Sprintf("v%d.metadata.json", version)
+}
@@ -0,0 +1,187 @@
+package iceberg
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rudderlabs/rudder-go-kit/logger"
+
+	"github.com/rudderlabs/rudder-server/warehouse/internal/model"
+)
+
+func TestCatalog(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	storage := NewFileSystemStorage(root)
+	catalog := NewCatalog(storage, logger.NOP)
+	tablePath := "rudder-datalake/namespace/tracks"
+	dataFile := func(name string, records int64) DataFile {
+		return DataFile{Path: storage.Location(tablePath + "/" + name), RecordCount: records, FileSizeInBytes: records * 10}
+	}
+	liveFiles := func(t *testing.T, metadata *TableMetadata) []string {
+		snapshot, ok := metadata.CurrentSnapshot()
+		require.True(t, ok)
+		manifests, err := catalog.readManifestList(ctx, tablePath, metadata, snapshot.ManifestList)
+		require.NoError(t, err)
+		var files []string
+		for _, manifest := range manifests {
+			require.LessOrEqual(t, manifest["sequence_number"], snapshot.SequenceNumber)
+			data, err := catalog.readMetadataFile(ctx, tablePath, metadata, manifest.path())
+			require.NoError(t, err)
+			paths, err := readManifest(data)
+			require.NoError(t, err)
+			files = append(files, paths...)
+		}
+		return files
+	}
+
+	t.Run("loading a missing table", func(t *testing.T) {
+		_, err := catalog.LoadTable(ctx, tablePath)
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("creating a table", func(t *testing.T) {
+		require.NoError(t, catalog.EnsureTable(ctx, tablePath, model.TableSchema{"id": "string", "received_at": "datetime", "count": "int"}))
+
+		metadata, err := catalog.LoadTable(ctx, tablePath)
+		require.NoError(t, err)
+		require.Equal(t, 2, metadata.FormatVersion)
+		require.Equal(t, "file://"+filepath.ToSlash(filepath.Join(root, tablePath)), metadata.Location)
+		require.Equal(t, 3, metadata.LastColumnID)
+		require.Equal(t, []Field{
+			{ID: 1, Name: "count", Type: "long"},
+			{ID: 2, Name: "id", Type: "string"},
+			{ID: 3, Name: "received_at", Type: "timestamptz"},
+		}, metadata.CurrentSchema().Fields)
+		require.JSONEq(t, `[{"field-id":1,"names":["count"]},{"field-id":2,"names":["id"]},{"field-id":3,"names":["received_at"]}]`, metadata.Properties[nameMappingProperty])
+		_, ok := metadata.CurrentSnapshot()
+		require.False(t, ok)
+	})
+
+	t.Run("appending files", func(t *testing.T) {
+		added, err := catalog.Append(ctx, tablePath, []DataFile{dataFile("a.parquet", 10), dataFile("b.parquet", 5)})
+		require.NoError(t, err)
+		require.EqualValues(t, 15, added)
+
+		metadata, err := catalog.LoadTable(ctx, tablePath)
+		require.NoError(t, err)
+		snapshot, ok := metadata.CurrentSnapshot()
+		require.True(t, ok)
+		require.Nil(t, snapshot.ParentSnapshotID)
+		require.EqualValues(t, 1, snapshot.SequenceNumber)
+		require.Equal(t, "append", snapshot.Summary["operation"])
+		require.Equal(t, "15", snapshot.Summary["total-records"])
+		require.Equal(t, snapshot.SnapshotID, metadata.Refs[mainBranch].SnapshotID)
+		require.ElementsMatch(t, []string{dataFile("a.parquet", 0).Path, dataFile("b.parquet", 0).Path}, liveFiles(t, metadata))
+		require.Len(t, metadata.MetadataLog, 1)
+	})
+
+	t.Run("appending files which are already part of the table", func(t *testing.T) {
+		added, err := catalog.Append(ctx, tablePath, []DataFile{dataFile("b.parquet", 5), dataFile("a.parquet", 10)})
+		require.NoError(t, err)
+		require.Zero(t, added)
+		metadata, err := catalog.LoadTable(ctx, tablePath)
+		require.NoError(t, err)
+		require.Len(t, metadata.Snapshots, 1, "no snapshot is committed for files which have already been appended")
+
+		added, err = catalog.Append(ctx, tablePath, []DataFile{dataFile("c.parquet", 7), dataFile("c.parquet", 7)})
+		require.NoError(t, err)
+		require.EqualValues(t, 7, added)
+
+		metadata, err = catalog.LoadTable(ctx, tablePath)
+		require.NoError(t, err)
+		require.Len(t, metadata.Snapshots, 2)
+		snapshot, _ := metadata.CurrentSnapshot()
+		require.Equal(t, metadata.Snapshots[0].SnapshotID, *snapshot.ParentSnapshotID)
+		require.NotEqual(t, metadata.Snapshots[0].Summary[appendIDProperty], snapshot.Summary[appendIDProperty])
+		require.EqualValues(t, 2, snapshot.SequenceNumber)
+		require.Equal(t, "1", snapshot.Summary["added-data-files"])
+		require.Equal(t, "3", snapshot.Summary["total-data-files"])
+		require.Equal(t, "22", snapshot.Summary["total-records"])
+		require.ElementsMatch(t, []string{dataFile("a.parquet", 0).Path, dataFile("b.parquet", 0).Path, dataFile("c.parquet", 0).Path}, liveFiles(t, metadata))
+
+		added, err = catalog.Append(ctx, tablePath, []DataFile{dataFile("c.parquet", 7)})
+		require.NoError(t, err)
+		require.Zero(t, added)
+		metadata, err = catalog.LoadTable(ctx, tablePath)
+		require.NoError(t, err)
+		require.Len(t, metadata.Snapshots, 2)
+	})
+
+	t.Run("evolving the schema", func(t *testing.T) {
+		require.NoError(t, catalog.EnsureTable(ctx, tablePath, model.TableSchema{"id": "string", "price": "float", "context_ip": "string"}))
+
+		metadata, err := catalog.LoadTable(ctx, tablePath)
+		require.NoError(t, err)
+		require.Equal(t, 1, metadata.CurrentSchemaID)
+		require.Len(t, metadata.Schemas, 2)
+		require.Equal(t, []Field{
+			{ID: 1, Name: "count", Type: "long"},
+			{ID: 2, Name: "id", Type: "string"},
+			{ID: 3, Name: "received_at", Type: "timestamptz"},
+			{ID: 4, Name: "context_ip", Type: "string"},
+			{ID: 5, Name: "price", Type: "double"},
+		}, metadata.CurrentSchema().Fields)
+		var nameMapping []map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(metadata.Properties[nameMappingProperty]), &nameMapping))
+		require.Len(t, nameMapping, 5)
+
+		require.NoError(t, catalog.EnsureTable(ctx, tablePath, model.TableSchema{"id": "string"}))
+		metadata, err = catalog.LoadTable(ctx, tablePath)
+		require.NoError(t, err)
+		require.Len(t, metadata.Schemas, 2, "no schema is added without new columns")
+	})
+
+	t.Run("altering columns", func(t *testing.T) {
+		require.NoError(t, catalog.AlterColumn(ctx, tablePath, "id", "text"))
+		require.ErrorIs(t, catalog.AlterColumn(ctx, tablePath, "count", "string"), ErrIncompatibleTypeChange)
+		require.Error(t, catalog.AlterColumn(ctx, tablePath, "missing", "string"))
+
+		metadata, err := catalog.LoadTable(ctx, tablePath)
+		require.NoError(t, err)
+		require.Len(t, metadata.Schemas, 2)
+	})
+
+	t.Run("retrying conflicting commits", func(t *testing.T) {
+		other := NewCatalog(storage, logger.NOP)
+		conflicting := &conflictingStorage{Storage: storage, beforeCreate: func() {
+			require.NoError(t, other.EnsureTable(ctx, tablePath, model.TableSchema{"concurrent": "string"}))
+		}}
+		retrying := NewCatalog(conflicting, logger.NOP)
+		retrying.commitRetryBackoff = time.Millisecond
+		require.NoError(t, retrying.EnsureTable(ctx, tablePath, model.TableSchema{"retried": "string"}))
+
+		metadata, err := catalog.LoadTable(ctx, tablePath)
+		require.NoError(t, err)
+		require.Len(t, metadata.Schemas, 4)
+		fields := metadata.CurrentSchema().Fields
+		require.Equal(t, Field{ID: 6, Name: "concurrent", Type: "string"}, fields[len(fields)-2])
+		require.Equal(t, Field{ID: 7, Name: "retried", Type: "string"}, fields[len(fields)-1])
+	})
+
+	t.Run("committing concurrently", func(t *testing.T) {
+		metadata, version, err := catalog.loadTable(ctx, tablePath)
+		require.NoError(t, err)
+		require.NoError(t, storage.Write(ctx, metadataKey(tablePath, metadataFile(version+1)), []byte(`{}`)))
+		require.ErrorIs(t, catalog.commit(ctx, tablePath, metadata, version), ErrCommitConflict)
+	})
+}
+
+// conflictingStorage runs beforeCreate ahead of the first object it creates, e.g. for committing concurrently
+type conflictingStorage struct {
+	Storage
+	beforeCreate func()
+}
+
+func (s *conflictingStorage) Create(ctx context.Context, key string, data []byte) error {
+	if beforeCreate := s.beforeCreate; beforeCreate != nil {
+		s.beforeCreate = nil
+		beforeCreate()
+	}
+	return s.Storage.Create(ctx, key, data)
+}
@@ -0,0 +1,223 @@
+package iceberg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/linkedin/goavro/v2"
+)
+
+// manifest entry statuses
+const (
+	manifestEntryAdded   = 1
+	manifestEntryDeleted = 2
+)
+
+const (
+	manifestContentData = 0
+	dataFileContentData = 0
+	dataFileFormat      = "PARQUET"
+)
+
+// manifestEntrySchema is the avro schema of the entries of manifest files, limited to the fields written for unpartitioned tables
+const manifestEntrySchema = `{
+	"type": "record",
+	"name": "manifest_entry",
+	"fields": [
+		{"name": "status", "type": "int", "field-id": 0},
+		{"name": "snapshot_id", "type": ["null", "long"], "default": null, "field-id": 1},
+		{"name": "sequence_number", "type": ["null", "long"], "default": null, "field-id": 3},
+		{"name": "file_sequence_number", "type": ["null", "long"], "default": null, "field-id": 4},
+		{"name": "data_file", "field-id": 2, "type": {
+			"type": "record",
+			"name": "r2",
+			"fields": [
+				{"name": "content", "type": "int", "field-id": 134},
+				{"name": "file_path", "type": "string", "field-id": 100},
+				{"name": "file_format", "type": "string", "field-id": 101},
+				{"name": "partition", "field-id": 102, "type": {"type": "record", "name": "r102", "fields": []}},
+				{"name": "record_count", "type": "long", "field-id": 103},
+				{"name": "file_size_in_bytes", "type": "long", "field-id": 104}
+			]
+		}}
+	]
+}`
+
+// manifestFileSchema is the avro schema of the entries of manifest lists
+const manifestFileSchema = `{
+	"type": "record",
+	"name": "manifest_file",
+	"fields": [
+		{"name": "manifest_path", "type": "string", "field-id": 500},
+		{"name": "manifest_length", "type": "long", "field-id": 501},
+		{"name": "partition_spec_id", "type": "int", "field-id": 502},
+		{"name": "content", "type": "int", "field-id": 517},
+		{"name": "sequence_number", "type": "long", "field-id": 515},
+		{"name": "min_sequence_number", "type": "long", "field-id": 516},
+		{"name": "added_snapshot_id", "type": "long", "field-id": 503},
+		{"name": "added_files_count", "type": "int", "field-id": 504},
+		{"name": "existing_files_count", "type": "int", "field-id": 505},
+		{"name": "deleted_files_count", "type": "int", "field-id": 506},
+		{"name": "added_rows_count", "type": "long", "field-id": 512},
+		{"name": "existing_rows_count", "type": "long", "field-id": 513},
+		{"name": "deleted_rows_count", "type": "long", "field-id": 514}
+	]
+}`
+
+var manifestEntryCodec, manifestFileCodec *goavro.Codec
+
+func init() {
+	var err error
+	if manifestEntryCodec, err = goavro.NewCodec(manifestEntrySchema); err != nil {
+		panic(fmt.Errorf("manifest entry codec: %w", err))
+	}
+	if manifestFileCodec, err = goavro.NewCodec(manifestFileSchema); err != nil {
+		panic(fmt.Errorf("manifest file codec: %w", err))
+	}
+}
+
+// DataFile is a parquet file to be added to a table
+type DataFile struct {
+	Path            string // URI of the file
+	RecordCount     int64
+	FileSizeInBytes int64
+}
+
+// manifestFile is an entry of a manifest list
+type manifestFile map[string]interface{}
+
+func (m manifestFile) path() string {
+	p, _ := m["manifest_path"].(string)
+	return p
+}
+
+// writeManifest encodes a manifest file adding the data files in the snapshot.
+// Sequence numbers are left empty, so that they are inherited from the manifest list.
+func writeManifest(schema Schema, snapshotID int64, files []DataFile) ([]byte, error) {
+	schemaJSON, err := json.Marshal(schema)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling schema: %w", err)
+	}
+	entries := make([]interface{}, 0, len(files))
+	for _, file := range files {
+		entries = append(entries, map[string]interface{}{
+			"status":               manifestEntryAdded,
+			"snapshot_id":          goavro.Union("long", snapshotID),
+			"sequence_number":      nil,
+			"file_sequence_number": nil,
+			"data_file": map[string]interface{}{
+				"content":            dataFileContentData,
+				"file_path":          file.Path,
+				"file_format":        dataFileFormat,
+				"partition":          map[string]interface{}{},
+				"record_count":       file.RecordCount,
+				"file_size_in_bytes": file.FileSizeInBytes,
+			},
+		})
+	}
+	return writeOCF(manifestEntryCodec, map[string][]byte{
+		"schema":            schemaJSON,
+		"schema-id":         []byte(strconv.Itoa(schema.SchemaID)),
+		"partition-spec":    []byte("[]"),
+		"partition-spec-id": []byte("0"),
+		"format-version":    []byte("2"),
+		"content":           []byte("data"),
+	}, entries)
+}
+
+// readManifest returns the paths of the live data files of a manifest file
+func readManifest(data []byte) ([]string, error) {
+	records, err := readOCF(data)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0, len(records))
+	for _, record := range records {
+		entry, ok := record.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unexpected manifest entry %T", record)
+		}
+		if status, _ := entry["status"].(int32); status == manifestEntryDeleted {
+			continue
+		}
+		dataFile, _ := entry["data_file"].(map[string]interface{})
+		path, ok := dataFile["file_path"].(string)
+		if !ok {
+			return nil, fmt.Errorf("manifest entry without file path")
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// writeManifestList encodes the manifest list of a snapshot
+func writeManifestList(snapshot Snapshot, manifests []manifestFile) ([]byte, error) {
+	parentSnapshotID := "null"
+	if snapshot.ParentSnapshotID != nil {
+		parentSnapshotID = strconv.FormatInt(*snapshot.ParentSnapshotID, 10)
+	}
+	records := make([]interface{}, 0, len(manifests))
+	for _, manifest := range manifests {
+		records = append(records, map[string]interface{}(manifest))
+	}
+	return writeOCF(manifestFileCodec, map[string][]byte{
+		"snapshot-id":        []byte(strconv.FormatInt(snapshot.SnapshotID, 10)),
+		"parent-snapshot-id": []byte(parentSnapshotID),
+		"sequence-number":    []byte(strconv.FormatInt(snapshot.SequenceNumber, 10)),
+		"format-version":     []byte("2"),
+	}, records)
+}
+
+// readManifestList returns the entries of a manifest list
+func readManifestList(data []byte) ([]manifestFile, error) {
+	records, err := readOCF(data)
+	if err != nil {
+		return nil, err
+	}
+	manifests := make([]manifestFile, 0, len(records))
+	for _, record := range records {
+		manifest, ok := record.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unexpected manifest list entry %T", record)
+		}
+		manifests = append(manifests, manifest)
+	}
+	return manifests, nil
+}
+
+func writeOCF(codec *goavro.Codec, metadata map[string][]byte, records []interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := goavro.NewOCFWriter(goavro.OCFConfig{
+		W:        &buf,
+		Codec:    codec,
+		MetaData: metadata,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating avro writer: %w", err)
+	}
+	if err := w.Append(records); err != nil {
+		return nil, fmt.Errorf("writing avro records: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func readOCF(data []byte) ([]interface{}, error) {
+	r, err := goavro.NewOCFReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("creating avro reader: %w", err)
+	}
+	var records []interface{}
+	for r.Scan() {
+		record, err := r.Read()
+		if err != nil {
+			return nil, fmt.Errorf("reading avro record: %w", err)
+		}
+		records = append(records, record)
+	}
+	if err := r.Err(); err != nil {
+		return nil, fmt.Errorf("reading avro records: %w", err)
+	}
+	return records, nil
+}
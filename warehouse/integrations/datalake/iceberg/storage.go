@@ -0,0 +1,171 @@
+package iceberg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/rudderlabs/rudder-go-kit/filemanager"
+)
+
+var (
+	// ErrNotFound is returned by storages when an object doesn't exist
+	ErrNotFound = errors.New("object not found")
+	// ErrAlreadyExists is returned by storages when creating an object which already exists
+	ErrAlreadyExists = errors.New("object already exists")
+)
+
+// Storage stores the metadata files of the tables
+type Storage interface {
+	// Read returns the contents of the object with the given key, or ErrNotFound if it doesn't exist
+	Read(ctx context.Context, key string) ([]byte, error)
+	// Write creates or overwrites the object with the given key
+	Write(ctx context.Context, key string, data []byte) error
+	// Create creates the object with the given key, or returns ErrAlreadyExists if it exists.
+	// Checking for the object and creating it is atomic, so that concurrent writers cannot overwrite each other's objects.
+	Create(ctx context.Context, key string, data []byte) error
+	// Location returns the URI through which query engines can access the object with the given key
+	Location(key string) string
+}
+
+// NewObjectStorage returns a storage backed by a file manager, where keys are relative to the prefix of the file manager.
+// Objects are created through the creator, since file managers cannot upload objects conditionally.
+// baseLocation is the URI of the bucket as seen by query engines, e.g. s3://bucket
+func NewObjectStorage(fm filemanager.FileManager, create ObjectCreator, baseLocation string) *ObjectStorage {
+	return &ObjectStorage{
+		fm:           fm,
+		create:       create,
+		baseLocation: strings.TrimSuffix(baseLocation, "/"),
+	}
+}
+
+// ObjectStorage is a [Storage] backed by a file manager
+type ObjectStorage struct {
+	fm           filemanager.FileManager
+	create       ObjectCreator
+	baseLocation string
+}
+
+func (s *ObjectStorage) Read(ctx context.Context, key string) ([]byte, error) {
+	objectName := path.Join(s.fm.Prefix(), key)
+	// downloading errors are provider specific, so existence is checked by listing the object beforehand
+	files, err := s.fm.ListFilesWithPrefix(ctx, "", objectName, 1).Next()
+	if err != nil {
+		return nil, fmt.Errorf("listing %s: %w", objectName, err)
+	}
+	if len(files) == 0 || files[0].Key != objectName {
+		return nil, ErrNotFound
+	}
+
+	file, err := os.CreateTemp("", "iceberg-*")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+	}()
+	if err := s.fm.Download(ctx, file, objectName); err != nil {
+		return nil, fmt.Errorf("downloading %s: %w", objectName, err)
+	}
+	return os.ReadFile(file.Name())
+}
+
+func (s *ObjectStorage) Write(ctx context.Context, key string, data []byte) error {
+	return uploadObject(ctx, s.fm, key, data)
+}
+
+// uploadObject uploads the data through the file manager as the object with the given key, relative to the prefix of the file manager
+func uploadObject(ctx context.Context, fm filemanager.FileManager, key string, data []byte) error {
+	// the name of the uploaded object is made up of the name of the uploaded file
+	dir, err := os.MkdirTemp("", "iceberg-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	localPath := filepath.Join(dir, path.Base(key))
+	if err := os.WriteFile(localPath, data, 0o600); err != nil {
+		return err
+	}
+	file, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+	if _, err := fm.Upload(ctx, file, path.Dir(key)); err != nil {
+		return fmt.Errorf("uploading %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *ObjectStorage) Create(ctx context.Context, key string, data []byte) error {
+	return s.create(ctx, key, data)
+}
+
+func (s *ObjectStorage) Location(key string) string {
+	return s.baseLocation + "/" + path.Join(s.fm.Prefix(), key)
+}
+
+// ObjectLocation returns the URI of an object uploaded through the file manager, given its location as returned by the file manager
+func (s *ObjectStorage) ObjectLocation(location string) (string, error) {
+	objectName, err := s.fm.GetObjectNameFromLocation(location)
+	if err != nil {
+		return "", fmt.Errorf("object name from location %s: %w", location, err)
+	}
+	return s.baseLocation + "/" + strings.TrimPrefix(objectName, "/"), nil
+}
+
+// NewFileSystemStorage returns a storage keeping objects as files under the root directory
+func NewFileSystemStorage(root string) *FileSystemStorage {
+	return &FileSystemStorage{root: root}
+}
+
+// FileSystemStorage is a [Storage] backed by the local file system
+type FileSystemStorage struct {
+	root string
+}
+
+func (s *FileSystemStorage) Read(_ context.Context, key string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(s.root, filepath.FromSlash(key)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+func (s *FileSystemStorage) Write(_ context.Context, key string, data []byte) error {
+	file := filepath.Join(s.root, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(file, data, 0o600)
+}
+
+func (s *FileSystemStorage) Create(_ context.Context, key string, data []byte) error {
+	file := filepath.Join(s.root, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if errors.Is(err, fs.ErrExist) {
+		return ErrAlreadyExists
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (s *FileSystemStorage) Location(key string) string {
+	return "file://" + filepath.ToSlash(filepath.Join(s.root, filepath.FromSlash(key)))
+}
@@ -0,0 +1,10 @@
+//go:build cgo
+
+package duckdb
+
+import (
+	_ "github.com/marcboeker/go-duckdb"
+)
+
+// driverAvailable is true since the duckdb driver is registered with database/sql in cgo enabled builds.
+const driverAvailable = true
@@ -0,0 +1,7 @@
+//go:build !cgo
+
+package duckdb
+
+// driverAvailable is false since the duckdb driver relies on cgo and can't be linked in builds with CGO_ENABLED=0,
+// such as the release image. Connecting to a DuckDB destination fails with errDriverUnavailable in such builds.
+const driverAvailable = false
@@ -0,0 +1,671 @@
+package duckdb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rudderlabs/rudder-go-kit/config"
+	"github.com/rudderlabs/rudder-go-kit/filemanager"
+	"github.com/rudderlabs/rudder-go-kit/logger"
+	"github.com/rudderlabs/rudder-go-kit/stats"
+
+	"github.com/rudderlabs/rudder-server/utils/misc"
+	"github.com/rudderlabs/rudder-server/warehouse/client"
+	sqlmiddleware "github.com/rudderlabs/rudder-server/warehouse/integrations/middleware/sqlquerywrapper"
+	"github.com/rudderlabs/rudder-server/warehouse/internal/model"
+	"github.com/rudderlabs/rudder-server/warehouse/internal/service/loadfiles/downloader"
+	"github.com/rudderlabs/rudder-server/warehouse/logfield"
+	warehouseutils "github.com/rudderlabs/rudder-server/warehouse/utils"
+)
+
+const (
+	provider       = warehouseutils.DUCKDB
+	tableNameLimit = 127
+)
+
+var errDriverUnavailable = errors.New("duckdb is not supported by this build, it requires CGO_ENABLED=1")
+
+var errorsMappings = []model.JobError{
+	{
+		Type:   model.ResourceNotFoundError,
+		Format: regexp.MustCompile(`Catalog Error: Table with name .* does not exist`),
+	},
+	{
+		Type:   model.ResourceNotFoundError,
+		Format: regexp.MustCompile(`Catalog Error: Schema with name .* does not exist`),
+	},
+	{
+		Type:   model.ConcurrentQueriesError,
+		Format: regexp.MustCompile(`IO Error: Could not set lock on file`),
+	},
+	{
+		Type:   model.PermissionError,
+		Format: regexp.MustCompile(`IO Error: Cannot open file .*: Permission denied`),
+	},
+	{
+		Type:   model.InsufficientResourceError,
+		Format: regexp.MustCompile(`Out of Memory Error`),
+	},
+}
+
+var rudderDataTypesMapToDuckDB = map[string]string{
+	"int":      "BIGINT",
+	"float":    "DOUBLE",
+	"string":   "VARCHAR",
+	"datetime": "TIMESTAMPTZ",
+	"boolean":  "BOOLEAN",
+	"json":     "JSON",
+}
+
+var duckDBDataTypesMapToRudder = map[string]string{
+	"TINYINT":                  "int",
+	"SMALLINT":                 "int",
+	"INTEGER":                  "int",
+	"BIGINT":                   "int",
+	"HUGEINT":                  "int",
+	"FLOAT":                    "float",
+	"DOUBLE":                   "float",
+	"VARCHAR":                  "string",
+	"TIMESTAMP WITH TIME ZONE": "datetime",
+	"TIMESTAMP":                "datetime",
+	"BOOLEAN":                  "boolean",
+	"JSON":                     "json",
+}
+
+var primaryKeyMap = map[string]string{
+	warehouseutils.UsersTable:      "id",
+	warehouseutils.IdentifiesTable: "id",
+	warehouseutils.DiscardsTable:   "row_id",
+}
+
+var partitionKeyMap = map[string]string{
+	warehouseutils.UsersTable:      "id",
+	warehouseutils.IdentifiesTable: "id",
+	warehouseutils.DiscardsTable:   "row_id, column_name, table_name",
+}
+
+// DuckDB loads into a DuckDB database file, which is either kept on the local file system under Warehouse.duckdb.baseDir
+// or in the bucket of the destination, in which case a local copy of it is used during the upload.
+// Uploads of destinations keeping the database file in their bucket are serialized, see [lockDestination].
+type DuckDB struct {
+	DB                 *sqlmiddleware.DB
+	Namespace          string
+	ObjectStorage      string
+	Warehouse          model.Warehouse
+	Uploader           warehouseutils.Uploader
+	LoadFileDownloader downloader.Downloader
+	connectTimeout     time.Duration
+	conf               *config.Config
+	logger             logger.Logger
+	stats              stats.Stats
+
+	// databaseFM is set when the database file is hosted in object storage
+	databaseFM   filemanager.FileManager
+	localDir     string
+	databasePath string
+	syncMu       sync.Mutex
+	// unlockDestination releases the lock serializing the uploads of the destination, if the database file is stored in a bucket
+	unlockDestination func()
+
+	config struct {
+		allowMerge                                bool
+		enableDeleteByJobs                        bool
+		numWorkersDownloadLoadFiles               int
+		slowQueryThreshold                        time.Duration
+		skipDedupDestinationIDs                   []string
+		skipComputingUserLatestTraits             bool
+		skipComputingUserLatestTraitsWorkspaceIDs []string
+		memoryLimit                               string
+		threads                                   int
+		baseDir                                   string
+	}
+}
+
+// destinationLocks serialize the uploads of destinations storing their database file in a bucket.
+// Every upload downloads the database file, loads into its local copy and uploads it back,
+// thus concurrent uploads of the same destination, e.g. for different namespaces, would drop each other's loads.
+var destinationLocks = struct {
+	sync.Mutex
+	locks map[string]chan struct{}
+}{locks: make(map[string]chan struct{})}
+
+// lockDestination waits until no other upload of the destination is in progress, returning the function releasing the lock
+func lockDestination(ctx context.Context, destinationID string) (func(), error) {
+	destinationLocks.Lock()
+	lock, ok := destinationLocks.locks[destinationID]
+	if !ok {
+		lock = make(chan struct{}, 1)
+		destinationLocks.locks[destinationID] = lock
+	}
+	destinationLocks.Unlock()
+
+	select {
+	case lock <- struct{}{}:
+		return func() { <-lock }, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("waiting for other uploads of the destination: %w", ctx.Err())
+	}
+}
+
+func New(conf *config.Config, log logger.Logger, stat stats.Stats) *DuckDB {
+	d := &DuckDB{}
+
+	d.conf = conf
+	d.logger = log.Child("integrations").Child("duckdb")
+	d.stats = stat
+
+	d.config.allowMerge = conf.GetBool("Warehouse.duckdb.allowMerge", true)
+	d.config.enableDeleteByJobs = conf.GetBool("Warehouse.duckdb.enableDeleteByJobs", false)
+	d.config.numWorkersDownloadLoadFiles = conf.GetInt("Warehouse.duckdb.numWorkersDownloadLoadFiles", 1)
+	d.config.slowQueryThreshold = conf.GetDuration("Warehouse.duckdb.slowQueryThreshold", 5, time.Minute)
+	d.config.skipDedupDestinationIDs = conf.GetStringSlice("Warehouse.duckdb.skipDedupDestinationIDs", nil)
+	d.config.skipComputingUserLatestTraits = conf.GetBool("Warehouse.duckdb.skipComputingUserLatestTraits", false)
+	d.config.skipComputingUserLatestTraitsWorkspaceIDs = conf.GetStringSlice("Warehouse.duckdb.skipComputingUserLatestTraitsWorkspaceIDs", nil)
+	d.config.memoryLimit = conf.GetString("Warehouse.duckdb.memoryLimit", "")
+	d.config.threads = conf.GetInt("Warehouse.duckdb.threads", 0)
+	d.config.baseDir = conf.GetString("Warehouse.duckdb.baseDir", "")
+
+	return d
+}
+
+func (d *DuckDB) getNewMiddleWare(db *sql.DB) *sqlmiddleware.DB {
+	middleware := sqlmiddleware.New(
+		db,
+		sqlmiddleware.WithStats(d.stats),
+		sqlmiddleware.WithLogger(d.logger),
+		sqlmiddleware.WithKeyAndValues(
+			logfield.SourceID, d.Warehouse.Source.ID,
+			logfield.SourceType, d.Warehouse.Source.SourceDefinition.Name,
+			logfield.DestinationID, d.Warehouse.Destination.ID,
+			logfield.DestinationType, d.Warehouse.Destination.DestinationDefinition.Name,
+			logfield.WorkspaceID, d.Warehouse.WorkspaceID,
+			logfield.Schema, d.Namespace,
+		),
+		sqlmiddleware.WithSlowQueryThreshold(d.config.slowQueryThreshold),
+		sqlmiddleware.WithQueryTimeout(d.connectTimeout),
+	)
+	return middleware
+}
+
+// connect opens the database file at the given path, creating it if it doesn't exist
+func (d *DuckDB) connect(databasePath string) (*sqlmiddleware.DB, error) {
+	if err := os.MkdirAll(filepath.Dir(databasePath), os.ModePerm); err != nil {
+		return nil, fmt.Errorf("creating database directory: %w", err)
+	}
+
+	var settings []string
+	if d.config.memoryLimit != "" {
+		settings = append(settings, "memory_limit="+d.config.memoryLimit)
+	}
+	if d.config.threads > 0 {
+		settings = append(settings, fmt.Sprintf("threads=%d", d.config.threads))
+	}
+	dsn := databasePath
+	if len(settings) > 0 {
+		dsn += "?" + strings.Join(settings, "&")
+	}
+
+	if !driverAvailable {
+		return nil, errDriverUnavailable
+	}
+
+	db, err := sql.Open("duckdb", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("opening connection to duckdb: %w", err)
+	}
+	return d.getNewMiddleWare(db), nil
+}
+
+// validateDatabasePath makes sure that the database path of a destination is relative and doesn't contain any .. segments,
+// so that destinations cannot access any files outside of the base directory, or the bucket prefix, their database files are kept in.
+func validateDatabasePath(databasePath string) error {
+	if databasePath == "" {
+		return errors.New("database path is not configured")
+	}
+	if path.IsAbs(databasePath) || filepath.IsAbs(databasePath) || strings.HasPrefix(databasePath, `\`) {
+		return fmt.Errorf("database path %q must be relative", databasePath)
+	}
+	for _, segment := range strings.FieldsFunc(databasePath, func(r rune) bool { return r == '/' || r == '\\' }) {
+		if segment == ".." {
+			return fmt.Errorf("database path %q must not contain .. segments", databasePath)
+		}
+	}
+	return nil
+}
+
+// localDatabasePath returns the path of a database file kept on the local file system, under Warehouse.duckdb.baseDir
+func (d *DuckDB) localDatabasePath(databasePath string) (string, error) {
+	if d.config.baseDir == "" {
+		return "", errors.New("base directory of database files is not configured, see Warehouse.duckdb.baseDir")
+	}
+	return filepath.Join(d.config.baseDir, filepath.FromSlash(databasePath)), nil
+}
+
+// databaseFileManager returns a file manager for the bucket of the destination,
+// or nil if the database file is kept on the local file system
+func (d *DuckDB) databaseFileManager() (filemanager.FileManager, error) {
+	if !d.Warehouse.GetBoolDestinationConfig(model.DatabaseInBucketSetting) {
+		return nil, nil
+	}
+	storageProvider := warehouseutils.ObjectStorageType(provider, d.Warehouse.Destination.Config, false)
+	fm, err := filemanager.New(&filemanager.Settings{
+		Provider: storageProvider,
+		Config: misc.GetObjectStorageConfig(misc.ObjectStorageOptsT{
+			Provider:    storageProvider,
+			Config:      d.Warehouse.Destination.Config,
+			WorkspaceID: d.Warehouse.Destination.WorkspaceID,
+		}),
+		Conf: d.conf,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating file manager: %w", err)
+	}
+	return fm, nil
+}
+
+// databaseObjectName returns the name of the database file in the bucket of the destination
+func (d *DuckDB) databaseObjectName() string {
+	return path.Join(d.databaseFM.Prefix(), d.databasePath)
+}
+
+// downloadDatabase copies the database file from object storage into the local directory.
+// Nothing is downloaded if the database file doesn't exist yet, so that a new database gets created.
+func (d *DuckDB) downloadDatabase(ctx context.Context, localPath string) error {
+	objectName := d.databaseObjectName()
+	files, err := d.databaseFM.ListFilesWithPrefix(ctx, "", objectName, 1).Next()
+	if err != nil {
+		return fmt.Errorf("listing %s: %w", objectName, err)
+	}
+	if len(files) == 0 || files[0].Key != objectName {
+		d.logger.Infow("database file not found in object storage, creating a new one",
+			logfield.DestinationID, d.Warehouse.Destination.ID,
+			"objectName", objectName,
+		)
+		return nil
+	}
+
+	file, err := os.Create(localPath)
+	if err != nil {
+		return fmt.Errorf("creating local database file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	if err := d.databaseFM.Download(ctx, file, objectName); err != nil {
+		return fmt.Errorf("downloading %s: %w", objectName, err)
+	}
+	return nil
+}
+
+// sync persists the local copy of the database file into object storage, if the database is hosted there.
+// Pending changes are checkpointed first, so that the uploaded file doesn't depend on the write-ahead log.
+func (d *DuckDB) sync(ctx context.Context) error {
+	if d.databaseFM == nil {
+		return nil
+	}
+
+	d.syncMu.Lock()
+	defer d.syncMu.Unlock()
+
+	if _, err := d.DB.ExecContext(ctx, `CHECKPOINT;`); err != nil {
+		return fmt.Errorf("checkpointing database: %w", err)
+	}
+
+	file, err := os.Open(filepath.Join(d.localDir, path.Base(d.databasePath)))
+	if err != nil {
+		return fmt.Errorf("opening local database file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	// the name of the uploaded object is made up of the name of the uploaded file
+	dir := path.Dir(d.databasePath)
+	if _, err := d.databaseFM.Upload(ctx, file, dir); err != nil {
+		return fmt.Errorf("uploading database file: %w", err)
+	}
+	return nil
+}
+
+func ColumnsWithDataTypes(columns model.TableSchema, prefix string) string {
+	var arr []string
+	for name, dataType := range columns {
+		arr = append(arr, fmt.Sprintf(`"%s%s" %s`, prefix, name, rudderDataTypesMapToDuckDB[dataType]))
+	}
+	return strings.Join(arr, ",")
+}
+
+func (*DuckDB) IsEmpty(context.Context, model.Warehouse) (empty bool, err error) {
+	return
+}
+
+func (d *DuckDB) DeleteBy(ctx context.Context, tableNames []string, params warehouseutils.DeleteByParams) error {
+	if !d.config.enableDeleteByJobs {
+		return nil
+	}
+
+	for _, tableName := range tableNames {
+		query := fmt.Sprintf(`
+			DELETE FROM %q.%q
+			WHERE
+			  context_sources_job_run_id <> $1
+			  AND context_sources_task_run_id <> $2
+			  AND context_source_id = $3
+			  AND received_at < $4;`,
+			d.Namespace,
+			tableName,
+		)
+
+		d.logger.Infow("deleting rows",
+			logfield.SourceID, d.Warehouse.Source.ID,
+			logfield.DestinationID, d.Warehouse.Destination.ID,
+			logfield.WorkspaceID, d.Warehouse.WorkspaceID,
+			logfield.Namespace, d.Namespace,
+			logfield.TableName, tableName,
+			logfield.Query, query,
+		)
+		if _, err := d.DB.ExecContext(ctx, query,
+			params.JobRunId,
+			params.TaskRunId,
+			params.SourceId,
+			params.StartTime,
+		); err != nil {
+			return fmt.Errorf("deleting rows from %s: %w", tableName, err)
+		}
+	}
+	return d.sync(ctx)
+}
+
+func (d *DuckDB) CreateSchema(ctx context.Context) error {
+	query := fmt.Sprintf(`CREATE SCHEMA IF NOT EXISTS %q;`, d.Namespace)
+
+	d.logger.Infow("creating schema",
+		logfield.DestinationID, d.Warehouse.Destination.ID,
+		logfield.Namespace, d.Namespace,
+		logfield.Query, query,
+	)
+	if _, err := d.DB.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("creating schema: %w", err)
+	}
+	return nil
+}
+
+func (d *DuckDB) CreateTable(ctx context.Context, tableName string, columnMap model.TableSchema) error {
+	query := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %q.%q ( %v );`,
+		d.Namespace,
+		tableName,
+		ColumnsWithDataTypes(columnMap, ""),
+	)
+
+	d.logger.Infow("creating table",
+		logfield.DestinationID, d.Warehouse.Destination.ID,
+		logfield.Namespace, d.Namespace,
+		logfield.TableName, tableName,
+		logfield.Query, query,
+	)
+	if _, err := d.DB.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("creating table: %w", err)
+	}
+	return nil
+}
+
+func (d *DuckDB) DropTable(ctx context.Context, tableName string) error {
+	query := fmt.Sprintf(`DROP TABLE IF EXISTS %q.%q;`, d.Namespace, tableName)
+
+	d.logger.Infow("dropping table",
+		logfield.DestinationID, d.Warehouse.Destination.ID,
+		logfield.Namespace, d.Namespace,
+		logfield.TableName, tableName,
+		logfield.Query, query,
+	)
+	if _, err := d.DB.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("dropping table: %w", err)
+	}
+	return nil
+}
+
+// AddColumns adds the columns one at a time, since DuckDB doesn't support adding several columns in a single statement
+func (d *DuckDB) AddColumns(ctx context.Context, tableName string, columnsInfo []warehouseutils.ColumnInfo) error {
+	return d.DB.WithTx(ctx, func(tx *sqlmiddleware.Tx) error {
+		for _, columnInfo := range columnsInfo {
+			query := fmt.Sprintf(`ALTER TABLE %q.%q ADD COLUMN IF NOT EXISTS %q %s;`,
+				d.Namespace,
+				tableName,
+				columnInfo.Name,
+				rudderDataTypesMapToDuckDB[columnInfo.Type],
+			)
+
+			d.logger.Infow("adding column",
+				logfield.DestinationID, d.Warehouse.Destination.ID,
+				logfield.Namespace, d.Namespace,
+				logfield.TableName, tableName,
+				logfield.ColumnName, columnInfo.Name,
+				logfield.Query, query,
+			)
+			if _, err := tx.ExecContext(ctx, query); err != nil {
+				return fmt.Errorf("adding column %s: %w", columnInfo.Name, err)
+			}
+		}
+		return nil
+	})
+}
+
+func (*DuckDB) AlterColumn(context.Context, string, string, string) (model.AlterTableResponse, error) {
+	return model.AlterTableResponse{}, nil
+}
+
+func (d *DuckDB) TestConnection(ctx context.Context, _ model.Warehouse) error {
+	err := d.DB.PingContext(ctx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("connection timeout: %w", err)
+	}
+	if err != nil {
+		return fmt.Errorf("pinging: %w", err)
+	}
+
+	return nil
+}
+
+func (d *DuckDB) Setup(ctx context.Context, warehouse model.Warehouse, uploader warehouseutils.Uploader) (err error) {
+	d.Warehouse = warehouse
+	d.Namespace = warehouse.Namespace
+	d.Uploader = uploader
+	d.ObjectStorage = warehouseutils.ObjectStorageType(provider, warehouse.Destination.Config, d.Uploader.UseRudderStorage())
+	d.LoadFileDownloader = downloader.NewDownloader(&warehouse, uploader, d.config.numWorkersDownloadLoadFiles)
+
+	d.databasePath = warehouse.GetStringDestinationConfig(d.conf, model.DatabasePathSetting)
+	if err = validateDatabasePath(d.databasePath); err != nil {
+		return err
+	}
+
+	if d.databaseFM, err = d.databaseFileManager(); err != nil {
+		return err
+	}
+	if d.databaseFM == nil {
+		localPath, err := d.localDatabasePath(d.databasePath)
+		if err != nil {
+			return err
+		}
+		d.DB, err = d.connect(localPath)
+		return err
+	}
+
+	if d.unlockDestination, err = lockDestination(ctx, warehouse.Destination.ID); err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			d.unlock()
+		}
+	}()
+
+	tmpDirPath, err := misc.CreateTMPDIR()
+	if err != nil {
+		return fmt.Errorf("creating tmp dir: %w", err)
+	}
+	if err = os.MkdirAll(filepath.Join(tmpDirPath, "rudder-warehouse-duckdb"), os.ModePerm); err != nil {
+		return fmt.Errorf("creating duckdb dir: %w", err)
+	}
+	if d.localDir, err = os.MkdirTemp(filepath.Join(tmpDirPath, "rudder-warehouse-duckdb"), warehouse.Destination.ID+"-*"); err != nil {
+		return fmt.Errorf("creating local database dir: %w", err)
+	}
+
+	localPath := filepath.Join(d.localDir, path.Base(d.databasePath))
+	if err = d.downloadDatabase(ctx, localPath); err != nil {
+		return fmt.Errorf("downloading database: %w", err)
+	}
+
+	d.DB, err = d.connect(localPath)
+	return err
+}
+
+func (*DuckDB) CrashRecover(context.Context) error {
+	return nil
+}
+
+// FetchSchema queries duckdb and returns the schema associated with provided namespace
+func (d *DuckDB) FetchSchema(ctx context.Context) (model.Schema, model.Schema, error) {
+	schema := make(model.Schema)
+	unrecognizedSchema := make(model.Schema)
+
+	sqlStatement := `
+		SELECT
+		  table_name,
+		  column_name,
+		  data_type
+		FROM
+		  INFORMATION_SCHEMA.COLUMNS
+		WHERE
+		  table_catalog = current_database()
+		  AND table_schema = $1
+		  AND table_name NOT LIKE $2;
+	`
+	rows, err := d.DB.QueryContext(
+		ctx,
+		sqlStatement,
+		d.Namespace,
+		fmt.Sprintf(`%s%%`, warehouseutils.StagingTablePrefix(provider)),
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return schema, unrecognizedSchema, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("fetching schema: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var tableName, columnName, columnType string
+
+		if err := rows.Scan(&tableName, &columnName, &columnType); err != nil {
+			return nil, nil, fmt.Errorf("scanning schema: %w", err)
+		}
+
+		if _, ok := schema[tableName]; !ok {
+			schema[tableName] = make(model.TableSchema)
+		}
+		if datatype, ok := duckDBDataTypesMapToRudder[columnType]; ok {
+			schema[tableName][columnName] = datatype
+		} else {
+			if _, ok := unrecognizedSchema[tableName]; !ok {
+				unrecognizedSchema[tableName] = make(model.TableSchema)
+			}
+			unrecognizedSchema[tableName][columnName] = warehouseutils.MissingDatatype
+
+			warehouseutils.WHCounterStat(d.stats, warehouseutils.RudderMissingDatatype, &d.Warehouse, warehouseutils.Tag{Name: "datatype", Value: columnType}).Count(1)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("fetching schema: %w", err)
+	}
+
+	return schema, unrecognizedSchema, nil
+}
+
+func (d *DuckDB) Cleanup(context.Context) {
+	if d.DB != nil {
+		_ = d.DB.Close()
+	}
+	if d.localDir != "" {
+		_ = os.RemoveAll(d.localDir)
+	}
+	d.unlock()
+}
+
+// unlock releases the lock serializing the uploads of the destination, if held
+func (d *DuckDB) unlock() {
+	if d.unlockDestination != nil {
+		d.unlockDestination()
+		d.unlockDestination = nil
+	}
+}
+
+func (*DuckDB) LoadIdentityMergeRulesTable(context.Context) (err error) {
+	return
+}
+
+func (*DuckDB) LoadIdentityMappingsTable(context.Context) (err error) {
+	return
+}
+
+func (*DuckDB) DownloadIdentityRules(context.Context, *misc.GZipWriter) (err error) {
+	return
+}
+
+// Connect opens the database for querying it through the warehouse client.
+// Databases hosted in object storage can't be queried, since only the copies used during uploads are local.
+func (d *DuckDB) Connect(_ context.Context, warehouse model.Warehouse) (client.Client, error) {
+	d.Warehouse = warehouse
+	d.Namespace = warehouse.Namespace
+	d.ObjectStorage = warehouseutils.ObjectStorageType(
+		provider,
+		warehouse.Destination.Config,
+		misc.IsConfiguredToUseRudderObjectStorage(d.Warehouse.Destination.Config),
+	)
+
+	if warehouse.GetBoolDestinationConfig(model.DatabaseInBucketSetting) {
+		return client.Client{}, errors.New("querying databases stored in object storage is not supported")
+	}
+	databasePath := warehouse.GetStringDestinationConfig(d.conf, model.DatabasePathSetting)
+	if err := validateDatabasePath(databasePath); err != nil {
+		return client.Client{}, err
+	}
+	localPath, err := d.localDatabasePath(databasePath)
+	if err != nil {
+		return client.Client{}, err
+	}
+
+	db, err := d.connect(localPath)
+	if err != nil {
+		return client.Client{}, err
+	}
+
+	return client.Client{Type: client.SQLClient, SQL: db.DB}, err
+}
+
+func (d *DuckDB) LoadTestTable(ctx context.Context, _, tableName string, payloadMap map[string]interface{}, _ string) error {
+	query := fmt.Sprintf(`INSERT INTO %q.%q (%q, %q) VALUES ($1, $2);`,
+		d.Namespace,
+		tableName,
+		"id",
+		"val",
+	)
+	if _, err := d.DB.ExecContext(ctx, query, payloadMap["id"], payloadMap["val"]); err != nil {
+		return fmt.Errorf("inserting into test table: %w", err)
+	}
+	return nil
+}
+
+func (d *DuckDB) SetConnectionTimeout(timeout time.Duration) {
+	d.connectTimeout = timeout
+}
+
+func (*DuckDB) ErrorMappings() []model.JobError {
+	return errorsMappings
+}
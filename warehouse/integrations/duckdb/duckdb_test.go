@@ -0,0 +1,475 @@
+//go:build cgo
+
+package duckdb_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/rudderlabs/rudder-go-kit/config"
+	"github.com/rudderlabs/rudder-go-kit/logger"
+	"github.com/rudderlabs/rudder-go-kit/stats"
+
+	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
+	"github.com/rudderlabs/rudder-server/warehouse/integrations/duckdb"
+	whth "github.com/rudderlabs/rudder-server/warehouse/integrations/testhelper"
+	mockuploader "github.com/rudderlabs/rudder-server/warehouse/internal/mocks/utils"
+	"github.com/rudderlabs/rudder-server/warehouse/internal/model"
+	whutils "github.com/rudderlabs/rudder-server/warehouse/utils"
+)
+
+func TestIntegration(t *testing.T) {
+	destType := whutils.DUCKDB
+	namespace := whth.RandSchema(destType)
+
+	schemaInUpload := model.TableSchema{
+		"test_bool":     "boolean",
+		"test_datetime": "datetime",
+		"test_float":    "float",
+		"test_int":      "int",
+		"test_string":   "string",
+		"id":            "string",
+		"received_at":   "datetime",
+	}
+	schemaInWarehouse := model.TableSchema{
+		"test_bool":           "boolean",
+		"test_datetime":       "datetime",
+		"test_float":          "float",
+		"test_int":            "int",
+		"test_string":         "string",
+		"id":                  "string",
+		"received_at":         "datetime",
+		"extra_test_bool":     "boolean",
+		"extra_test_datetime": "datetime",
+		"extra_test_float":    "float",
+		"extra_test_int":      "int",
+		"extra_test_string":   "string",
+	}
+
+	baseDir := t.TempDir()
+	newConfig := func() *config.Config {
+		c := config.New()
+		c.Set("Warehouse.duckdb.baseDir", baseDir)
+		return c
+	}
+	var databases atomic.Int64
+	newWarehouse := func(t *testing.T) model.Warehouse {
+		return model.Warehouse{
+			Source: backendconfig.SourceT{
+				ID: "test_source_id",
+			},
+			Destination: backendconfig.DestinationT{
+				ID: "test_destination_id",
+				DestinationDefinition: backendconfig.DestinationDefinitionT{
+					Name: destType,
+				},
+				Config: map[string]any{
+					"databasePath":  fmt.Sprintf("%d/rudder.duckdb", databases.Add(1)),
+					"syncFrequency": "30",
+				},
+			},
+			WorkspaceID: "test_workspace_id",
+			Namespace:   namespace,
+		}
+	}
+	setup := func(t *testing.T, c *config.Config, warehouse model.Warehouse, uploader whutils.Uploader, loadFiles map[string][]string) *duckdb.DuckDB {
+		t.Helper()
+
+		d := duckdb.New(c, logger.NOP, stats.NOP)
+		require.NoError(t, d.Setup(context.Background(), warehouse, uploader))
+		t.Cleanup(func() { d.Cleanup(context.Background()) })
+		d.LoadFileDownloader = &localDownloader{t: t, loadFiles: loadFiles}
+		return d
+	}
+	selectRecords := func(t *testing.T, d *duckdb.DuckDB, tableName string) [][]string {
+		t.Helper()
+
+		return whth.RetrieveRecordsFromWarehouse(t, d.DB.DB,
+			fmt.Sprintf(`
+				SELECT
+				  id,
+				  received_at,
+				  test_bool,
+				  test_datetime,
+				  test_float,
+				  test_int,
+				  test_string
+				FROM
+				  %q.%q
+				ORDER BY
+				  id;
+				`,
+				namespace,
+				tableName,
+			),
+		)
+	}
+
+	t.Run("Schema", func(t *testing.T) {
+		ctx := context.Background()
+		tableName := "schema_test_table"
+
+		d := setup(t, newConfig(), newWarehouse(t), newMockUploader(t, tableName, schemaInUpload, schemaInWarehouse, whutils.LoadFileTypeCsv), nil)
+
+		schema, unrecognizedSchema, err := d.FetchSchema(ctx)
+		require.NoError(t, err)
+		require.Empty(t, schema)
+		require.Empty(t, unrecognizedSchema)
+
+		require.NoError(t, d.CreateSchema(ctx))
+		require.NoError(t, d.CreateSchema(ctx))
+		require.NoError(t, d.CreateTable(ctx, tableName, schemaInUpload))
+		require.NoError(t, d.AddColumns(ctx, tableName, []whutils.ColumnInfo{
+			{Name: "extra_test_bool", Type: "boolean"},
+			{Name: "extra_test_datetime", Type: "datetime"},
+			{Name: "extra_test_float", Type: "float"},
+			{Name: "extra_test_int", Type: "int"},
+			{Name: "extra_test_string", Type: "string"},
+		}))
+		require.NoError(t, d.AddColumns(ctx, tableName, []whutils.ColumnInfo{
+			{Name: "extra_test_string", Type: "string"},
+		}))
+
+		_, err = d.DB.ExecContext(ctx, fmt.Sprintf(`ALTER TABLE %q.%q ADD COLUMN test_uuid UUID;`, namespace, tableName))
+		require.NoError(t, err)
+
+		schema, unrecognizedSchema, err = d.FetchSchema(ctx)
+		require.NoError(t, err)
+		require.Equal(t, model.Schema{tableName: schemaInWarehouse}, schema)
+		require.Equal(t, model.Schema{tableName: {"test_uuid": whutils.MissingDatatype}}, unrecognizedSchema)
+
+		require.NoError(t, d.DropTable(ctx, tableName))
+		schema, _, err = d.FetchSchema(ctx)
+		require.NoError(t, err)
+		require.Empty(t, schema)
+	})
+
+	t.Run("Load Table", func(t *testing.T) {
+		t.Run("schema does not exists", func(t *testing.T) {
+			ctx := context.Background()
+			tableName := "schema_not_exists_test_table"
+
+			d := setup(t, newConfig(), newWarehouse(t), newMockUploader(t, tableName, schemaInUpload, schemaInWarehouse, whutils.LoadFileTypeCsv), map[string][]string{
+				tableName: {"../testdata/load.csv.gz"},
+			})
+
+			loadTableStat, err := d.LoadTable(ctx, tableName)
+			require.Error(t, err)
+			require.Nil(t, loadTableStat)
+		})
+		t.Run("table does not exists", func(t *testing.T) {
+			ctx := context.Background()
+			tableName := "table_not_exists_test_table"
+
+			d := setup(t, newConfig(), newWarehouse(t), newMockUploader(t, tableName, schemaInUpload, schemaInWarehouse, whutils.LoadFileTypeCsv), map[string][]string{
+				tableName: {"../testdata/load.csv.gz"},
+			})
+			require.NoError(t, d.CreateSchema(ctx))
+
+			loadTableStat, err := d.LoadTable(ctx, tableName)
+			require.Error(t, err)
+			require.Nil(t, loadTableStat)
+		})
+		t.Run("merge", func(t *testing.T) {
+			t.Run("without dedup", func(t *testing.T) {
+				ctx := context.Background()
+				tableName := "merge_without_dedup_test_table"
+
+				appendWarehouse := newWarehouse(t)
+				appendWarehouse.Destination.Config[model.PreferAppendSetting.String()] = true
+
+				d := setup(t, newConfig(), appendWarehouse, newMockUploader(t, tableName, schemaInUpload, schemaInWarehouse, whutils.LoadFileTypeCsv), map[string][]string{
+					tableName: {"../testdata/load.csv.gz"},
+				})
+				require.NoError(t, d.CreateSchema(ctx))
+				require.NoError(t, d.CreateTable(ctx, tableName, schemaInWarehouse))
+
+				loadTableStat, err := d.LoadTable(ctx, tableName)
+				require.NoError(t, err)
+				require.Equal(t, loadTableStat.RowsInserted, int64(14))
+				require.Equal(t, loadTableStat.RowsUpdated, int64(0))
+
+				loadTableStat, err = d.LoadTable(ctx, tableName)
+				require.NoError(t, err)
+				require.Equal(t, loadTableStat.RowsInserted, int64(14))
+				require.Equal(t, loadTableStat.RowsUpdated, int64(0))
+
+				require.Equal(t, selectRecords(t, d, tableName), whth.AppendTestRecords())
+			})
+			t.Run("with dedup", func(t *testing.T) {
+				ctx := context.Background()
+				tableName := "merge_with_dedup_test_table"
+
+				d := setup(t, newConfig(), newWarehouse(t), newMockUploader(t, tableName, schemaInUpload, schemaInWarehouse, whutils.LoadFileTypeCsv), map[string][]string{
+					tableName: {"../testdata/dedup.csv.gz"},
+				})
+				require.NoError(t, d.CreateSchema(ctx))
+				require.NoError(t, d.CreateTable(ctx, tableName, schemaInWarehouse))
+
+				loadTableStat, err := d.LoadTable(ctx, tableName)
+				require.NoError(t, err)
+				require.Equal(t, loadTableStat.RowsInserted, int64(14))
+				require.Equal(t, loadTableStat.RowsUpdated, int64(0))
+
+				loadTableStat, err = d.LoadTable(ctx, tableName)
+				require.NoError(t, err)
+				require.Equal(t, loadTableStat.RowsInserted, int64(0))
+				require.Equal(t, loadTableStat.RowsUpdated, int64(14))
+
+				require.Equal(t, selectRecords(t, d, tableName), whth.DedupTestRecords())
+			})
+		})
+		t.Run("append", func(t *testing.T) {
+			ctx := context.Background()
+			tableName := "append_test_table"
+
+			c := newConfig()
+			c.Set("Warehouse.duckdb.skipDedupDestinationIDs", "test_destination_id")
+
+			appendWarehouse := newWarehouse(t)
+			appendWarehouse.Destination.Config[model.PreferAppendSetting.String()] = true
+
+			d := setup(t, c, appendWarehouse, newMockUploader(t, tableName, schemaInUpload, schemaInWarehouse, whutils.LoadFileTypeCsv), map[string][]string{
+				tableName: {"../testdata/load.csv.gz"},
+			})
+			require.NoError(t, d.CreateSchema(ctx))
+			require.NoError(t, d.CreateTable(ctx, tableName, schemaInWarehouse))
+
+			loadTableStat, err := d.LoadTable(ctx, tableName)
+			require.NoError(t, err)
+			require.Equal(t, loadTableStat.RowsInserted, int64(14))
+			require.Equal(t, loadTableStat.RowsUpdated, int64(0))
+
+			loadTableStat, err = d.LoadTable(ctx, tableName)
+			require.NoError(t, err)
+			require.Equal(t, loadTableStat.RowsInserted, int64(14))
+			require.Equal(t, loadTableStat.RowsUpdated, int64(0))
+
+			require.Equal(t, selectRecords(t, d, tableName), whth.AppendTestRecords())
+		})
+		t.Run("parquet", func(t *testing.T) {
+			ctx := context.Background()
+			tableName := "parquet_test_table"
+
+			d := setup(t, newConfig(), newWarehouse(t), newMockUploader(t, tableName, schemaInUpload, schemaInWarehouse, whutils.LoadFileTypeParquet), map[string][]string{
+				tableName: {"../testdata/load.parquet"},
+			})
+			require.NoError(t, d.CreateSchema(ctx))
+			require.NoError(t, d.CreateTable(ctx, tableName, schemaInWarehouse))
+
+			loadTableStat, err := d.LoadTable(ctx, tableName)
+			require.NoError(t, err)
+			require.Equal(t, loadTableStat.RowsInserted, int64(14))
+			require.Equal(t, loadTableStat.RowsUpdated, int64(0))
+
+			require.Equal(t, selectRecords(t, d, tableName), whth.SampleTestRecords())
+		})
+		t.Run("mismatch in number of columns", func(t *testing.T) {
+			ctx := context.Background()
+			tableName := "mismatch_columns_test_table"
+
+			d := setup(t, newConfig(), newWarehouse(t), newMockUploader(t, tableName, schemaInUpload, schemaInWarehouse, whutils.LoadFileTypeCsv), map[string][]string{
+				tableName: {"../testdata/mismatch-columns.csv.gz"},
+			})
+			require.NoError(t, d.CreateSchema(ctx))
+			require.NoError(t, d.CreateTable(ctx, tableName, schemaInWarehouse))
+
+			loadTableStat, err := d.LoadTable(ctx, tableName)
+			require.Error(t, err)
+			require.Nil(t, loadTableStat)
+		})
+		t.Run("mismatch in schema", func(t *testing.T) {
+			ctx := context.Background()
+			tableName := "mismatch_schema_test_table"
+
+			d := setup(t, newConfig(), newWarehouse(t), newMockUploader(t, tableName, schemaInUpload, schemaInWarehouse, whutils.LoadFileTypeCsv), map[string][]string{
+				tableName: {"../testdata/mismatch-schema.csv.gz"},
+			})
+			require.NoError(t, d.CreateSchema(ctx))
+			require.NoError(t, d.CreateTable(ctx, tableName, schemaInWarehouse))
+
+			loadTableStat, err := d.LoadTable(ctx, tableName)
+			require.Error(t, err)
+			require.Nil(t, loadTableStat)
+		})
+		t.Run("discards", func(t *testing.T) {
+			ctx := context.Background()
+			tableName := whutils.DiscardsTable
+
+			d := setup(t, newConfig(), newWarehouse(t), newMockUploader(t, tableName, whutils.DiscardsSchema, whutils.DiscardsSchema, whutils.LoadFileTypeCsv), map[string][]string{
+				tableName: {"../testdata/discards.csv.gz"},
+			})
+			require.NoError(t, d.CreateSchema(ctx))
+			require.NoError(t, d.CreateTable(ctx, tableName, whutils.DiscardsSchema))
+
+			loadTableStat, err := d.LoadTable(ctx, tableName)
+			require.NoError(t, err)
+			require.Equal(t, loadTableStat.RowsInserted, int64(6))
+			require.Equal(t, loadTableStat.RowsUpdated, int64(0))
+
+			records := whth.RetrieveRecordsFromWarehouse(t, d.DB.DB,
+				fmt.Sprintf(`
+					SELECT
+					  column_name,
+					  column_value,
+					  received_at,
+					  row_id,
+					  table_name,
+					  uuid_ts
+					FROM
+					  %q.%q
+					ORDER BY row_id ASC;
+					`,
+					namespace,
+					tableName,
+				),
+			)
+			require.Equal(t, records, whth.DiscardTestRecords())
+		})
+	})
+
+	t.Run("Load User Tables", func(t *testing.T) {
+		ctx := context.Background()
+
+		identifiesSchema := model.TableSchema{
+			"test_bool":     "boolean",
+			"test_datetime": "datetime",
+			"test_float":    "float",
+			"test_int":      "int",
+			"test_string":   "string",
+			"id":            "string",
+			"received_at":   "datetime",
+			"user_id":       "string",
+		}
+		usersSchema := model.TableSchema{
+			"test_bool":     "boolean",
+			"test_datetime": "datetime",
+			"test_float":    "float",
+			"test_int":      "int",
+			"test_string":   "string",
+			"id":            "string",
+			"received_at":   "datetime",
+		}
+
+		ctrl := gomock.NewController(t)
+		mockUploader := mockuploader.NewMockUploader(ctrl)
+		mockUploader.EXPECT().UseRudderStorage().Return(false).AnyTimes()
+		mockUploader.EXPECT().GetLoadFileType().Return(whutils.LoadFileTypeCsv).AnyTimes()
+		mockUploader.EXPECT().GetTableSchemaInUpload(whutils.UsersTable).Return(usersSchema).AnyTimes()
+		mockUploader.EXPECT().GetTableSchemaInUpload(whutils.IdentifiesTable).Return(identifiesSchema).AnyTimes()
+		mockUploader.EXPECT().GetTableSchemaInWarehouse(whutils.UsersTable).Return(usersSchema).AnyTimes()
+		mockUploader.EXPECT().GetTableSchemaInWarehouse(whutils.IdentifiesTable).Return(identifiesSchema).AnyTimes()
+		mockUploader.EXPECT().CanAppend().Return(true).AnyTimes()
+
+		d := setup(t, newConfig(), newWarehouse(t), mockUploader, map[string][]string{
+			whutils.IdentifiesTable: {"../postgres/testdata/identifies.csv.gz"},
+		})
+		require.NoError(t, d.CreateSchema(ctx))
+		require.NoError(t, d.CreateTable(ctx, whutils.IdentifiesTable, identifiesSchema))
+		require.NoError(t, d.CreateTable(ctx, whutils.UsersTable, usersSchema))
+
+		for i := 0; i < 2; i++ {
+			errorsMap := d.LoadUserTables(ctx)
+			require.NoError(t, errorsMap[whutils.IdentifiesTable])
+			require.NoError(t, errorsMap[whutils.UsersTable])
+		}
+
+		var identifiesCount, usersCount, distinctUsersCount int
+		require.NoError(t, d.DB.QueryRowContext(ctx, fmt.Sprintf(`SELECT count(*), count(DISTINCT user_id) FROM %q.%q;`, namespace, whutils.IdentifiesTable)).Scan(&identifiesCount, &distinctUsersCount))
+		require.NoError(t, d.DB.QueryRowContext(ctx, fmt.Sprintf(`SELECT count(*) FROM %q.%q;`, namespace, whutils.UsersTable)).Scan(&usersCount))
+		require.Equal(t, 14, identifiesCount)
+		require.Equal(t, distinctUsersCount, usersCount)
+	})
+
+	t.Run("Database file", func(t *testing.T) {
+		ctx := context.Background()
+		tableName := "database_file_test_table"
+		warehouse := newWarehouse(t)
+
+		d := duckdb.New(newConfig(), logger.NOP, stats.NOP)
+		require.NoError(t, d.Setup(ctx, warehouse, newMockUploader(t, tableName, schemaInUpload, schemaInWarehouse, whutils.LoadFileTypeCsv)))
+		d.LoadFileDownloader = &localDownloader{t: t, loadFiles: map[string][]string{tableName: {"../testdata/load.csv.gz"}}}
+		require.NoError(t, d.CreateSchema(ctx))
+		require.NoError(t, d.CreateTable(ctx, tableName, schemaInUpload))
+		_, err := d.LoadTable(ctx, tableName)
+		require.NoError(t, err)
+		d.Cleanup(ctx)
+
+		c, err := duckdb.New(newConfig(), logger.NOP, stats.NOP).Connect(ctx, warehouse)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = c.SQL.Close() })
+
+		records := whth.RetrieveRecordsFromWarehouse(t, c.SQL, fmt.Sprintf(`SELECT id FROM %q.%q;`, namespace, tableName))
+		require.Len(t, records, 14)
+		require.FileExists(t, filepath.Join(baseDir, warehouse.Destination.Config["databasePath"].(string)))
+	})
+
+	t.Run("Database path", func(t *testing.T) {
+		ctx := context.Background()
+		uploader := newMockUploader(t, "database_path_test_table", schemaInUpload, schemaInWarehouse, whutils.LoadFileTypeCsv)
+
+		for _, databasePath := range []string{"", "/tmp/rudder.duckdb", "../rudder.duckdb", "db/../../rudder.duckdb", `db\..\..\rudder.duckdb`} {
+			warehouse := newWarehouse(t)
+			warehouse.Destination.Config["databasePath"] = databasePath
+
+			d := duckdb.New(newConfig(), logger.NOP, stats.NOP)
+			require.Error(t, d.Setup(ctx, warehouse, uploader), databasePath)
+			_, err := d.Connect(ctx, warehouse)
+			require.Error(t, err, databasePath)
+		}
+
+		d := duckdb.New(config.New(), logger.NOP, stats.NOP)
+		require.Error(t, d.Setup(ctx, newWarehouse(t), uploader), "the base directory is required")
+	})
+}
+
+// localDownloader returns copies of local load files, since they are removed after loading them
+type localDownloader struct {
+	t         testing.TB
+	loadFiles map[string][]string
+}
+
+func (l *localDownloader) Download(_ context.Context, tableName string) ([]string, error) {
+	dir := l.t.TempDir()
+
+	var fileNames []string
+	for _, loadFile := range l.loadFiles[tableName] {
+		data, err := os.ReadFile(loadFile)
+		if err != nil {
+			return nil, err
+		}
+		fileName := filepath.Join(dir, fmt.Sprintf("%d-%s", len(fileNames), filepath.Base(loadFile)))
+		if err := os.WriteFile(fileName, data, 0o600); err != nil {
+			return nil, err
+		}
+		fileNames = append(fileNames, fileName)
+	}
+	return fileNames, nil
+}
+
+func newMockUploader(
+	t testing.TB,
+	tableName string,
+	schemaInUpload model.TableSchema,
+	schemaInWarehouse model.TableSchema,
+	loadFileType string,
+) whutils.Uploader {
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	mockUploader := mockuploader.NewMockUploader(ctrl)
+	mockUploader.EXPECT().UseRudderStorage().Return(false).AnyTimes()
+	mockUploader.EXPECT().GetLoadFileType().Return(loadFileType).AnyTimes()
+	mockUploader.EXPECT().GetTableSchemaInUpload(tableName).Return(schemaInUpload).AnyTimes()
+	mockUploader.EXPECT().GetTableSchemaInWarehouse(tableName).Return(schemaInWarehouse).AnyTimes()
+	mockUploader.EXPECT().CanAppend().Return(true).AnyTimes()
+
+	return mockUploader
+}
@@ -0,0 +1,543 @@
+package duckdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/samber/lo"
+
+	"github.com/rudderlabs/rudder-server/utils/misc"
+	sqlmiddleware "github.com/rudderlabs/rudder-server/warehouse/integrations/middleware/sqlquerywrapper"
+	"github.com/rudderlabs/rudder-server/warehouse/integrations/types"
+	"github.com/rudderlabs/rudder-server/warehouse/internal/model"
+	"github.com/rudderlabs/rudder-server/warehouse/logfield"
+	"github.com/rudderlabs/rudder-server/warehouse/safeguard"
+	warehouseutils "github.com/rudderlabs/rudder-server/warehouse/utils"
+)
+
+type loadUsersTableResponse struct {
+	identifiesError error
+	usersError      error
+}
+
+func (d *DuckDB) LoadTable(ctx context.Context, tableName string) (*types.LoadTableStats, error) {
+	var loadTableStats *types.LoadTableStats
+	cancel := safeguard.MustStop(ctx, 5*time.Minute)
+	defer cancel()
+
+	err := d.DB.WithTx(ctx, func(tx *sqlmiddleware.Tx) error {
+		var err error
+		loadTableStats, _, err = d.loadTable(
+			ctx,
+			tx,
+			tableName,
+			d.Uploader.GetTableSchemaInUpload(tableName),
+		)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("loading table: %w", err)
+	}
+	if err := d.sync(ctx); err != nil {
+		return nil, fmt.Errorf("syncing database: %w", err)
+	}
+
+	return loadTableStats, nil
+}
+
+func (d *DuckDB) loadTable(
+	ctx context.Context,
+	txn *sqlmiddleware.Tx,
+	tableName string,
+	tableSchemaInUpload model.TableSchema,
+) (*types.LoadTableStats, string, error) {
+	log := d.logger.With(
+		logfield.SourceID, d.Warehouse.Source.ID,
+		logfield.SourceType, d.Warehouse.Source.SourceDefinition.Name,
+		logfield.DestinationID, d.Warehouse.Destination.ID,
+		logfield.DestinationType, d.Warehouse.Destination.DestinationDefinition.Name,
+		logfield.WorkspaceID, d.Warehouse.WorkspaceID,
+		logfield.Namespace, d.Namespace,
+		logfield.TableName, tableName,
+		logfield.ShouldMerge, d.shouldMerge(tableName),
+	)
+	log.Infow("started loading")
+	defer log.Infow("completed loading")
+
+	loadFiles, err := d.LoadFileDownloader.Download(ctx, tableName)
+	if err != nil {
+		return nil, "", fmt.Errorf("downloading load files: %w", err)
+	}
+	defer func() {
+		misc.RemoveFilePaths(loadFiles...)
+	}()
+
+	stagingTableName := warehouseutils.StagingTableName(
+		provider,
+		tableName,
+		tableNameLimit,
+	)
+
+	log.Debugw("creating staging table")
+	createStagingTableStmt := fmt.Sprintf(
+		`CREATE TEMPORARY TABLE %[3]q AS
+		SELECT * FROM %[1]q.%[2]q LIMIT 0;`,
+		d.Namespace,
+		tableName,
+		stagingTableName,
+	)
+	if _, err := txn.ExecContext(ctx, createStagingTableStmt); err != nil {
+		return nil, "", fmt.Errorf("creating temporary table: %w", err)
+	}
+
+	sortedColumnKeys := warehouseutils.SortColumnKeysFromColumnMap(
+		tableSchemaInUpload,
+	)
+
+	log.Infow("loading data into staging table")
+	if err := d.loadDataIntoStagingTable(
+		ctx, txn, stagingTableName,
+		loadFiles, sortedColumnKeys,
+	); err != nil {
+		return nil, "", fmt.Errorf("loading data into staging table: %w", err)
+	}
+
+	var rowsDeleted int64
+	if d.shouldMerge(tableName) {
+		log.Infow("deleting from load table")
+		rowsDeleted, err = d.deleteFromLoadTable(
+			ctx, txn, tableName,
+			stagingTableName,
+		)
+		if err != nil {
+			return nil, "", fmt.Errorf("delete from load table: %w", err)
+		}
+	}
+
+	log.Infow("inserting into load table")
+	rowsInserted, err := d.insertIntoLoadTable(
+		ctx, txn, tableName,
+		stagingTableName, sortedColumnKeys,
+	)
+	if err != nil {
+		return nil, "", fmt.Errorf("insert into: %w", err)
+	}
+
+	return &types.LoadTableStats{
+		RowsInserted: rowsInserted - rowsDeleted,
+		RowsUpdated:  rowsDeleted,
+	}, stagingTableName, nil
+}
+
+// loadDataIntoStagingTable reads the load files through the csv and parquet readers of DuckDB.
+// CSV files are read as text, leaving the conversion of the values to the insertion into the typed staging table.
+func (d *DuckDB) loadDataIntoStagingTable(
+	ctx context.Context,
+	txn *sqlmiddleware.Tx,
+	stagingTableName string,
+	loadFiles []string,
+	sortedColumnKeys []string,
+) error {
+	if len(loadFiles) == 0 {
+		return nil
+	}
+
+	quotedColumnNames := warehouseutils.DoubleQuoteAndJoinByComma(sortedColumnKeys)
+	files := strings.Join(lo.Map(loadFiles, func(file string, _ int) string {
+		return quoteLiteral(file)
+	}), ", ")
+
+	var query string
+	if d.Uploader.GetLoadFileType() == warehouseutils.LoadFileTypeParquet {
+		query = fmt.Sprintf(`
+			INSERT INTO %[1]q (%[2]s)
+			SELECT
+			  %[2]s
+			FROM
+			  read_parquet([%[3]s], union_by_name = true);`,
+			stagingTableName,
+			quotedColumnNames,
+			files,
+		)
+	} else {
+		columns := strings.Join(lo.Map(sortedColumnKeys, func(column string, _ int) string {
+			return fmt.Sprintf(`%s: 'VARCHAR'`, quoteLiteral(column))
+		}), ", ")
+
+		query = fmt.Sprintf(`
+			INSERT INTO %[1]q (%[2]s)
+			SELECT
+			  *
+			FROM
+			  read_csv(
+				[%[3]s],
+				columns = {%[4]s},
+				header = false,
+				auto_detect = false,
+				compression = 'gzip',
+				delim = ',',
+				quote = '"',
+				escape = '"'
+			  );`,
+			stagingTableName,
+			quotedColumnNames,
+			files,
+			columns,
+		)
+	}
+
+	if _, err := txn.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("reading load files: %w", err)
+	}
+	return nil
+}
+
+func (d *DuckDB) deleteFromLoadTable(
+	ctx context.Context,
+	txn *sqlmiddleware.Tx,
+	tableName string,
+	stagingTableName string,
+) (int64, error) {
+	primaryKey := "id"
+	if column, ok := primaryKeyMap[tableName]; ok {
+		primaryKey = column
+	}
+
+	var additionalJoinClause string
+	if tableName == warehouseutils.DiscardsTable {
+		additionalJoinClause = fmt.Sprintf(
+			`AND _source.%[3]s = %[1]q.%[2]q.%[3]q AND _source.%[4]s = %[1]q.%[2]q.%[4]q`,
+			d.Namespace,
+			tableName,
+			"table_name",
+			"column_name",
+		)
+	}
+
+	deleteStmt := fmt.Sprintf(`
+		DELETE FROM
+		  %[1]q.%[2]q USING %[3]q AS _source
+		WHERE
+		  (
+			_source.%[4]s = %[1]q.%[2]q.%[4]q %[5]s
+		  );`,
+		d.Namespace,
+		tableName,
+		stagingTableName,
+		primaryKey,
+		additionalJoinClause,
+	)
+
+	result, err := txn.ExecContext(ctx, deleteStmt)
+	if err != nil {
+		return 0, fmt.Errorf("deleting from main table for dedup: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+func (d *DuckDB) insertIntoLoadTable(
+	ctx context.Context,
+	txn *sqlmiddleware.Tx,
+	tableName string,
+	stagingTableName string,
+	sortedColumnKeys []string,
+) (int64, error) {
+	partitionKey := "id"
+	if column, ok := partitionKeyMap[tableName]; ok {
+		partitionKey = column
+	}
+
+	quotedColumnNames := warehouseutils.DoubleQuoteAndJoinByComma(
+		sortedColumnKeys,
+	)
+
+	insertStmt := fmt.Sprintf(`
+		INSERT INTO %[1]q.%[2]q (%[3]s)
+		SELECT
+		  %[3]s
+		FROM
+		  (
+			SELECT
+			  *,
+			  ROW_NUMBER() OVER (
+				PARTITION BY %[5]s
+				ORDER BY
+				  received_at DESC
+			  ) AS _rudder_staging_row_number
+			FROM
+			  %[4]q
+		  ) AS _
+		WHERE
+		  _rudder_staging_row_number = 1;`,
+		d.Namespace,
+		tableName,
+		quotedColumnNames,
+		stagingTableName,
+		partitionKey,
+	)
+
+	result, err := txn.ExecContext(ctx, insertStmt)
+	if err != nil {
+		return 0, fmt.Errorf("inserting into main table: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+func (d *DuckDB) LoadUserTables(ctx context.Context) map[string]error {
+	d.logger.Infow("started loading for identifies and users tables",
+		logfield.SourceID, d.Warehouse.Source.ID,
+		logfield.SourceType, d.Warehouse.Source.SourceDefinition.Name,
+		logfield.DestinationID, d.Warehouse.Destination.ID,
+		logfield.DestinationType, d.Warehouse.Destination.DestinationDefinition.Name,
+		logfield.WorkspaceID, d.Warehouse.WorkspaceID,
+		logfield.Namespace, d.Namespace,
+	)
+
+	identifiesSchemaInUpload := d.Uploader.GetTableSchemaInUpload(warehouseutils.IdentifiesTable)
+	usersSchemaInUpload := d.Uploader.GetTableSchemaInUpload(warehouseutils.UsersTable)
+	usersSchemaInWarehouse := d.Uploader.GetTableSchemaInWarehouse(warehouseutils.UsersTable)
+
+	var loadingError loadUsersTableResponse
+	_ = d.DB.WithTx(ctx, func(tx *sqlmiddleware.Tx) error {
+		loadingError = d.loadUsersTable(ctx, tx, identifiesSchemaInUpload, usersSchemaInUpload, usersSchemaInWarehouse)
+		if loadingError.identifiesError != nil || loadingError.usersError != nil {
+			return errors.New("loading users and identifies table")
+		}
+
+		return nil
+	})
+	if loadingError.identifiesError != nil {
+		return map[string]error{
+			warehouseutils.IdentifiesTable: loadingError.identifiesError,
+		}
+	}
+	if err := d.sync(ctx); err != nil {
+		err = fmt.Errorf("syncing database: %w", err)
+		return map[string]error{
+			warehouseutils.IdentifiesTable: err,
+			warehouseutils.UsersTable:      err,
+		}
+	}
+	if len(usersSchemaInUpload) == 0 {
+		return map[string]error{
+			warehouseutils.IdentifiesTable: nil,
+		}
+	}
+	if loadingError.usersError != nil {
+		return map[string]error{
+			warehouseutils.IdentifiesTable: nil,
+			warehouseutils.UsersTable:      loadingError.usersError,
+		}
+	}
+
+	d.logger.Infow("completed loading for users and identities table",
+		logfield.SourceID, d.Warehouse.Source.ID,
+		logfield.SourceType, d.Warehouse.Source.SourceDefinition.Name,
+		logfield.DestinationID, d.Warehouse.Destination.ID,
+		logfield.DestinationType, d.Warehouse.Destination.DestinationDefinition.Name,
+		logfield.WorkspaceID, d.Warehouse.WorkspaceID,
+		logfield.Namespace, d.Namespace,
+	)
+
+	return map[string]error{
+		warehouseutils.IdentifiesTable: nil,
+		warehouseutils.UsersTable:      nil,
+	}
+}
+
+func (d *DuckDB) loadUsersTable(
+	ctx context.Context,
+	tx *sqlmiddleware.Tx,
+	identifiesSchemaInUpload,
+	usersSchemaInUpload,
+	usersSchemaInWarehouse model.TableSchema,
+) loadUsersTableResponse {
+	_, identifyStagingTable, err := d.loadTable(ctx, tx, warehouseutils.IdentifiesTable, identifiesSchemaInUpload)
+	if err != nil {
+		return loadUsersTableResponse{
+			identifiesError: fmt.Errorf("loading identifies table: %w", err),
+		}
+	}
+
+	if len(usersSchemaInUpload) == 0 {
+		return loadUsersTableResponse{}
+	}
+
+	canSkipComputingLatestUserTraits := d.config.skipComputingUserLatestTraits ||
+		slices.Contains(d.config.skipComputingUserLatestTraitsWorkspaceIDs, d.Warehouse.WorkspaceID)
+	if canSkipComputingLatestUserTraits {
+		if _, _, err = d.loadTable(ctx, tx, warehouseutils.UsersTable, usersSchemaInUpload); err != nil {
+			return loadUsersTableResponse{
+				usersError: fmt.Errorf("loading users table: %w", err),
+			}
+		}
+		return loadUsersTableResponse{}
+	}
+
+	unionStagingTableName := warehouseutils.StagingTableName(provider, "users_identifies_union", tableNameLimit)
+	usersStagingTableName := warehouseutils.StagingTableName(provider, warehouseutils.UsersTable, tableNameLimit)
+
+	var userColNames, lastValProps []string
+	for colName := range usersSchemaInWarehouse {
+		if colName == "id" {
+			continue
+		}
+		userColNames = append(userColNames, fmt.Sprintf(`%q`, colName))
+		lastValProps = append(lastValProps, fmt.Sprintf(
+			`arg_max(%[1]q, received_at) FILTER (WHERE %[1]q IS NOT NULL) AS %[1]q`,
+			colName,
+		))
+	}
+
+	query := fmt.Sprintf(
+		`CREATE TEMPORARY TABLE %[5]q AS
+			SELECT id, %[4]s
+			FROM %[1]q.%[2]q
+			WHERE id IN (
+				SELECT user_id
+				FROM %[3]q
+				WHERE user_id IS NOT NULL
+			)
+			UNION
+			SELECT user_id, %[4]s
+			FROM %[3]q
+			WHERE user_id IS NOT NULL;`,
+		d.Namespace,
+		warehouseutils.UsersTable,
+		identifyStagingTable,
+		strings.Join(userColNames, ","),
+		unionStagingTableName,
+	)
+
+	d.logger.Infow("creating union staging users table",
+		logfield.SourceID, d.Warehouse.Source.ID,
+		logfield.SourceType, d.Warehouse.Source.SourceDefinition.Name,
+		logfield.DestinationID, d.Warehouse.Destination.ID,
+		logfield.DestinationType, d.Warehouse.Destination.DestinationDefinition.Name,
+		logfield.WorkspaceID, d.Warehouse.WorkspaceID,
+		logfield.TableName, warehouseutils.UsersTable,
+		logfield.StagingTableName, unionStagingTableName,
+		logfield.Namespace, d.Namespace,
+		logfield.Query, query,
+	)
+	if _, err = tx.ExecContext(ctx, query); err != nil {
+		return loadUsersTableResponse{
+			usersError: fmt.Errorf("creating union staging users table: %w", err),
+		}
+	}
+
+	// The latest non-null value of every trait is picked for each user
+	query = fmt.Sprintf(`
+		CREATE TEMPORARY TABLE %[1]q AS
+		SELECT
+		  id,
+		  %[2]s
+		FROM
+		  %[3]q
+		GROUP BY
+		  id;`,
+		usersStagingTableName,
+		strings.Join(lastValProps, ","),
+		unionStagingTableName,
+	)
+
+	d.logger.Debugw("creating temporary users table",
+		logfield.SourceID, d.Warehouse.Source.ID,
+		logfield.SourceType, d.Warehouse.Source.SourceDefinition.Name,
+		logfield.DestinationID, d.Warehouse.Destination.ID,
+		logfield.DestinationType, d.Warehouse.Destination.DestinationDefinition.Name,
+		logfield.WorkspaceID, d.Warehouse.WorkspaceID,
+		logfield.TableName, warehouseutils.UsersTable,
+		logfield.StagingTableName, usersStagingTableName,
+		logfield.Query, query,
+	)
+	if _, err = tx.ExecContext(ctx, query); err != nil {
+		return loadUsersTableResponse{
+			usersError: fmt.Errorf("creating temporary users table: %w", err),
+		}
+	}
+
+	// Deduplication
+	// Delete from users table if the id is present in the staging table
+	query = fmt.Sprintf(`
+		DELETE FROM %[1]q.%[2]q USING %[3]q AS _source
+		WHERE _source.id = %[1]q.%[2]q.id;`,
+		d.Namespace,
+		warehouseutils.UsersTable,
+		usersStagingTableName,
+	)
+
+	d.logger.Infow("deduplication for users table",
+		logfield.SourceID, d.Warehouse.Source.ID,
+		logfield.SourceType, d.Warehouse.Source.SourceDefinition.Name,
+		logfield.DestinationID, d.Warehouse.Destination.ID,
+		logfield.DestinationType, d.Warehouse.Destination.DestinationDefinition.Name,
+		logfield.WorkspaceID, d.Warehouse.WorkspaceID,
+		logfield.TableName, warehouseutils.UsersTable,
+		logfield.StagingTableName, usersStagingTableName,
+		logfield.Namespace, d.Namespace,
+		logfield.Query, query,
+	)
+	if _, err = tx.ExecContext(ctx, query); err != nil {
+		return loadUsersTableResponse{
+			usersError: fmt.Errorf("deduplication for users table: %w", err),
+		}
+	}
+
+	// Insert rows from staging table to users table
+	query = fmt.Sprintf(`
+		INSERT INTO %[1]q.%[2]q (%[4]s)
+		SELECT
+		  %[4]s
+		FROM
+		  %[3]q;`,
+		d.Namespace,
+		warehouseutils.UsersTable,
+		usersStagingTableName,
+		strings.Join(append([]string{"id"}, userColNames...), ","),
+	)
+	d.logger.Infow("inserting records to users table",
+		logfield.SourceID, d.Warehouse.Source.ID,
+		logfield.SourceType, d.Warehouse.Source.SourceDefinition.Name,
+		logfield.DestinationID, d.Warehouse.Destination.ID,
+		logfield.DestinationType, d.Warehouse.Destination.DestinationDefinition.Name,
+		logfield.WorkspaceID, d.Warehouse.WorkspaceID,
+		logfield.TableName, warehouseutils.UsersTable,
+		logfield.StagingTableName, usersStagingTableName,
+		logfield.Namespace, d.Namespace,
+		logfield.Query, query,
+	)
+	if _, err = tx.ExecContext(ctx, query); err != nil {
+		return loadUsersTableResponse{
+			usersError: fmt.Errorf("inserting records to users table: %w", err),
+		}
+	}
+
+	return loadUsersTableResponse{}
+}
+
+func (d *DuckDB) shouldMerge(tableName string) bool {
+	if !d.config.allowMerge {
+		return false
+	}
+	if tableName == warehouseutils.UsersTable {
+		// If we are here it's because canSkipComputingLatestUserTraits is true.
+		// preferAppend doesn't apply to the users table, so we are just checking skipDedupDestinationIDs for
+		// backwards compatibility.
+		return !slices.Contains(d.config.skipDedupDestinationIDs, d.Warehouse.Destination.ID)
+	}
+	if !d.Uploader.CanAppend() {
+		return true
+	}
+	return !d.Warehouse.GetPreferAppendSetting() &&
+		!slices.Contains(d.config.skipDedupDestinationIDs, d.Warehouse.Destination.ID)
+}
+
+// quoteLiteral quotes a string as an SQL string literal
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,30 @@
+package duckdb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockDestination(t *testing.T) {
+	ctx := context.Background()
+
+	unlock, err := lockDestination(ctx, "destination_id")
+	require.NoError(t, err)
+
+	otherUnlock, err := lockDestination(ctx, "other_destination_id")
+	require.NoError(t, err, "destinations are locked independently")
+	otherUnlock()
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = lockDestination(timeoutCtx, "destination_id")
+	require.ErrorIs(t, err, context.DeadlineExceeded, "uploads of the same destination are serialized")
+
+	unlock()
+	unlock, err = lockDestination(ctx, "destination_id")
+	require.NoError(t, err)
+	unlock()
+}
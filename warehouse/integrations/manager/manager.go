@@ -18,6 +18,7 @@ import (
 	"github.com/rudderlabs/rudder-server/warehouse/integrations/clickhouse"
 	"github.com/rudderlabs/rudder-server/warehouse/integrations/datalake"
 	"github.com/rudderlabs/rudder-server/warehouse/integrations/deltalake"
+	"github.com/rudderlabs/rudder-server/warehouse/integrations/duckdb"
 	"github.com/rudderlabs/rudder-server/warehouse/integrations/mssql"
 	"github.com/rudderlabs/rudder-server/warehouse/integrations/postgres"
 	"github.com/rudderlabs/rudder-server/warehouse/integrations/redshift"
@@ -79,6 +80,8 @@ func New(destType string, conf *config.Config, logger logger.Logger, stats stats
 		return datalake.New(conf, logger), nil
 	case warehouseutils.DELTALAKE:
 		return deltalake.New(conf, logger, stats), nil
+	case warehouseutils.DUCKDB:
+		return duckdb.New(conf, logger, stats), nil
 	}
 	return nil, fmt.Errorf("provider of type %s is not configured for WarehouseManager", destType)
 }
@@ -104,6 +107,8 @@ func NewWarehouseOperations(destType string, conf *config.Config, logger logger.
 		return datalake.New(conf, logger), nil
 	case warehouseutils.DELTALAKE:
 		return deltalake.New(conf, logger, stats), nil
+	case warehouseutils.DUCKDB:
+		return duckdb.New(conf, logger, stats), nil
 	}
 	return nil, fmt.Errorf("provider of type %s is not configured for WarehouseManager", destType)
 }
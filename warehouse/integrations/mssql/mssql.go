@@ -266,6 +266,16 @@ func (ms *MSSQL) loadTable(
 	)
 	log.Infow("started loading")
 
+	mergeMode, err := warehouseutils.MergeMode(ms.Warehouse, tableName)
+	if err != nil {
+		return nil, "", fmt.Errorf("getting merge mode: %w", err)
+	}
+	if mergeMode.Enabled {
+		if err := mergeMode.Validate(tableName, tableSchemaInUpload); err != nil {
+			return nil, "", fmt.Errorf("validating merge mode: %w", err)
+		}
+	}
+
 	fileNames, err := ms.LoadFileDownLoader.Download(ctx, tableName)
 	if err != nil {
 		return nil, "", fmt.Errorf("downloading load files: %w", err)
@@ -349,7 +359,7 @@ func (ms *MSSQL) loadTable(
 	log.Infow("deleting from load table")
 	rowsDeleted, err := ms.deleteFromLoadTable(
 		ctx, txn, tableName,
-		stagingTableName,
+		stagingTableName, mergeMode,
 	)
 	if err != nil {
 		return nil, "", fmt.Errorf("delete from load table: %w", err)
@@ -359,6 +369,7 @@ func (ms *MSSQL) loadTable(
 	rowsInserted, err := ms.insertIntoLoadTable(
 		ctx, txn, tableName,
 		stagingTableName, sortedColumnKeys,
+		mergeMode,
 	)
 	if err != nil {
 		return nil, "", fmt.Errorf("insert into: %w", err)
@@ -502,11 +513,15 @@ func (ms *MSSQL) deleteFromLoadTable(
 	txn *sqlmw.Tx,
 	tableName string,
 	stagingTableName string,
+	mergeMode model.MergeMode,
 ) (int64, error) {
 	primaryKey := "id"
 	if column, ok := primaryKeyMap[tableName]; ok {
 		primaryKey = column
 	}
+	if mergeMode.Enabled {
+		primaryKey = mergeMode.PrimaryKey
+	}
 
 	var additionalDeleteStmtClause string
 	if tableName == warehouseutils.DiscardsTable {
@@ -547,11 +562,15 @@ func (ms *MSSQL) insertIntoLoadTable(
 	tableName string,
 	stagingTableName string,
 	sortedColumnKeys []string,
+	mergeMode model.MergeMode,
 ) (int64, error) {
 	partitionKey := "id"
 	if column, ok := partitionKeyMap[tableName]; ok {
 		partitionKey = column
 	}
+	if mergeMode.Enabled {
+		partitionKey = mergeMode.PrimaryKey
+	}
 
 	quotedColumnNames := warehouseutils.DoubleQuoteAndJoinByComma(
 		sortedColumnKeys,
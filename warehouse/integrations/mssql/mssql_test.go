@@ -18,6 +18,7 @@ import (
 	"github.com/rudderlabs/rudder-go-kit/filemanager"
 	"github.com/rudderlabs/rudder-go-kit/logger"
 
+	th "github.com/rudderlabs/rudder-server/testhelper"
 	"github.com/rudderlabs/rudder-server/testhelper/backendconfigtest"
 	"github.com/rudderlabs/rudder-server/warehouse/integrations/mssql"
 	mockuploader "github.com/rudderlabs/rudder-server/warehouse/internal/mocks/utils"
@@ -530,6 +531,104 @@ func TestIntegration(t *testing.T) {
 				)
 				require.Equal(t, records, whth.DedupTestRecords())
 			})
+			t.Run("with merge mode and custom primary key", func(t *testing.T) {
+				tableName := "merge_mode_test_table"
+
+				mergeModeSchema := model.TableSchema{
+					"id":            "string",
+					"message_id":    "string",
+					"received_at":   "datetime",
+					"test_bool":     "boolean",
+					"test_datetime": "datetime",
+					"test_float":    "float",
+					"test_int":      "int",
+					"test_string":   "string",
+				}
+
+				uploadOutput := whth.UploadLoadFile(t, fm, "../testdata/merge-mode.csv.gz", tableName)
+
+				loadFiles := []whutils.LoadFile{{Location: uploadOutput.Location}}
+				mockUploader := newMockUploader(t, loadFiles, tableName, mergeModeSchema, mergeModeSchema)
+
+				mergeModeWarehouse := th.Clone(t, warehouse)
+				mergeModeWarehouse.Destination.Config[model.MergeModeSetting.String()] = map[string]any{
+					"enabled":    true,
+					"primaryKey": "message_id",
+				}
+
+				ms := mssql.New(config.New(), logger.NOP, stats.NOP)
+				err := ms.Setup(ctx, mergeModeWarehouse, mockUploader)
+				require.NoError(t, err)
+
+				err = ms.CreateSchema(ctx)
+				require.NoError(t, err)
+
+				err = ms.CreateTable(ctx, tableName, mergeModeSchema)
+				require.NoError(t, err)
+
+				loadTableStat, err := ms.LoadTable(ctx, tableName)
+				require.NoError(t, err)
+				require.Equal(t, loadTableStat.RowsInserted, int64(7))
+				require.Equal(t, loadTableStat.RowsUpdated, int64(0))
+
+				loadTableStat, err = ms.LoadTable(ctx, tableName)
+				require.NoError(t, err)
+				require.Equal(t, loadTableStat.RowsInserted, int64(0))
+				require.Equal(t, loadTableStat.RowsUpdated, int64(7))
+
+				records := whth.RetrieveRecordsFromWarehouse(t, ms.DB.DB,
+					fmt.Sprintf(`
+						SELECT
+						  id,
+						  message_id,
+						  test_int
+						FROM
+						  %q.%q
+						ORDER BY
+						  message_id;
+						`,
+						namespace,
+						tableName,
+					),
+				)
+				require.Equal(t, records, [][]string{
+					{"1-b", "message-1", "10"},
+					{"2-b", "message-2", "20"},
+					{"3-b", "message-3", "30"},
+					{"4-b", "message-4", "40"},
+					{"5-b", "message-5", "50"},
+					{"6-b", "message-6", "60"},
+					{"7-b", "message-7", "70"},
+				})
+			})
+			t.Run("with merge mode and unknown primary key", func(t *testing.T) {
+				tableName := "merge_mode_unknown_primary_key_test_table"
+
+				uploadOutput := whth.UploadLoadFile(t, fm, "../testdata/load.csv.gz", tableName)
+
+				loadFiles := []whutils.LoadFile{{Location: uploadOutput.Location}}
+				mockUploader := newMockUploader(t, loadFiles, tableName, schemaInUpload, schemaInWarehouse)
+
+				mergeModeWarehouse := th.Clone(t, warehouse)
+				mergeModeWarehouse.Destination.Config[model.MergeModeSetting.String()] = map[string]any{
+					"enabled":    true,
+					"primaryKey": "message_id",
+				}
+
+				ms := mssql.New(config.New(), logger.NOP, stats.NOP)
+				err := ms.Setup(ctx, mergeModeWarehouse, mockUploader)
+				require.NoError(t, err)
+
+				err = ms.CreateSchema(ctx)
+				require.NoError(t, err)
+
+				err = ms.CreateTable(ctx, tableName, schemaInWarehouse)
+				require.NoError(t, err)
+
+				loadTableStat, err := ms.LoadTable(ctx, tableName)
+				require.ErrorContains(t, err, "merge mode primary key message_id is not a column of table "+tableName)
+				require.Nil(t, loadTableStat)
+			})
 		})
 		t.Run("load file does not exists", func(t *testing.T) {
 			tableName := "load_file_not_exists_test_table"
@@ -60,6 +60,12 @@ func (pg *Postgres) loadTable(
 	tableName string,
 	tableSchemaInUpload model.TableSchema,
 ) (*types.LoadTableStats, string, error) {
+	mergeMode, err := warehouseutils.MergeMode(pg.Warehouse, tableName)
+	if err != nil {
+		return nil, "", fmt.Errorf("getting merge mode: %w", err)
+	}
+	shouldMerge := pg.shouldMerge(tableName, mergeMode)
+
 	log := pg.logger.With(
 		logfield.SourceID, pg.Warehouse.Source.ID,
 		logfield.SourceType, pg.Warehouse.Source.SourceDefinition.Name,
@@ -68,11 +74,17 @@ func (pg *Postgres) loadTable(
 		logfield.WorkspaceID, pg.Warehouse.WorkspaceID,
 		logfield.Namespace, pg.Namespace,
 		logfield.TableName, tableName,
-		logfield.ShouldMerge, pg.shouldMerge(tableName),
+		logfield.ShouldMerge, shouldMerge,
 	)
 	log.Infow("started loading")
 	defer log.Infow("completed loading")
 
+	if mergeMode.Enabled {
+		if err := mergeMode.Validate(tableName, tableSchemaInUpload); err != nil {
+			return nil, "", fmt.Errorf("validating merge mode: %w", err)
+		}
+	}
+
 	log.Debugw("setting search path")
 	searchPathStmt := fmt.Sprintf(`SET search_path TO %q;`,
 		pg.Namespace,
@@ -133,11 +145,11 @@ func (pg *Postgres) loadTable(
 	}
 
 	var rowsDeleted int64
-	if pg.shouldMerge(tableName) {
+	if shouldMerge {
 		log.Infow("deleting from load table")
 		rowsDeleted, err = pg.deleteFromLoadTable(
 			ctx, txn, tableName,
-			stagingTableName,
+			stagingTableName, mergeMode,
 		)
 		if err != nil {
 			return nil, "", fmt.Errorf("delete from load table: %w", err)
@@ -148,6 +160,7 @@ func (pg *Postgres) loadTable(
 	rowsInserted, err := pg.insertIntoLoadTable(
 		ctx, txn, tableName,
 		stagingTableName, sortedColumnKeys,
+		mergeMode,
 	)
 	if err != nil {
 		return nil, "", fmt.Errorf("insert into: %w", err)
@@ -220,11 +233,15 @@ func (pg *Postgres) deleteFromLoadTable(
 	txn *sqlmiddleware.Tx,
 	tableName string,
 	stagingTableName string,
+	mergeMode model.MergeMode,
 ) (int64, error) {
 	primaryKey := "id"
 	if column, ok := primaryKeyMap[tableName]; ok {
 		primaryKey = column
 	}
+	if mergeMode.Enabled {
+		primaryKey = mergeMode.PrimaryKey
+	}
 
 	var additionalJoinClause string
 	if tableName == warehouseutils.DiscardsTable {
@@ -264,11 +281,15 @@ func (pg *Postgres) insertIntoLoadTable(
 	tableName string,
 	stagingTableName string,
 	sortedColumnKeys []string,
+	mergeMode model.MergeMode,
 ) (int64, error) {
 	partitionKey := "id"
 	if column, ok := partitionKeyMap[tableName]; ok {
 		partitionKey = column
 	}
+	if mergeMode.Enabled {
+		partitionKey = mergeMode.PrimaryKey
+	}
 
 	quotedColumnNames := warehouseutils.DoubleQuoteAndJoinByComma(
 		sortedColumnKeys,
@@ -574,7 +595,7 @@ func (pg *Postgres) loadUsersTable(
 	return loadUsersTableResponse{}
 }
 
-func (pg *Postgres) shouldMerge(tableName string) bool {
+func (pg *Postgres) shouldMerge(tableName string, mergeMode model.MergeMode) bool {
 	if !pg.config.allowMerge {
 		return false
 	}
@@ -584,6 +605,9 @@ func (pg *Postgres) shouldMerge(tableName string) bool {
 		// backwards compatibility.
 		return !slices.Contains(pg.config.skipDedupDestinationIDs, pg.Warehouse.Destination.ID)
 	}
+	if mergeMode.Enabled {
+		return true
+	}
 	if !pg.Uploader.CanAppend() {
 		return true
 	}
@@ -897,6 +897,102 @@ func TestIntegration(t *testing.T) {
 				)
 				require.Equal(t, records, whth.DedupTestRecords())
 			})
+			t.Run("with merge mode", func(t *testing.T) {
+				ctx := context.Background()
+				tableName := "merge_mode_test_table"
+
+				loadUploader := mockUploader(t, []whutils.LoadFile{
+					{Location: whth.UploadLoadFile(t, fm, "../testdata/load.csv.gz", tableName).Location},
+				}, tableName, schemaInUpload, schemaInWarehouse)
+
+				mergeModeWarehouse := th.Clone(t, warehouse)
+				mergeModeWarehouse.Destination.Config[model.PreferAppendSetting.String()] = true
+				mergeModeWarehouse.Destination.Config[model.MergeModeSetting.String()] = map[string]any{
+					"tables": map[string]any{
+						tableName: map[string]any{
+							"enabled":    true,
+							"primaryKey": "id",
+						},
+					},
+				}
+
+				pg := postgres.New(config.New(), logger.NOP, stats.NOP)
+				err := pg.Setup(ctx, mergeModeWarehouse, loadUploader)
+				require.NoError(t, err)
+
+				err = pg.CreateSchema(ctx)
+				require.NoError(t, err)
+
+				err = pg.CreateTable(ctx, tableName, schemaInWarehouse)
+				require.NoError(t, err)
+
+				loadTableStat, err := pg.LoadTable(ctx, tableName)
+				require.NoError(t, err)
+				require.Equal(t, loadTableStat.RowsInserted, int64(14))
+				require.Equal(t, loadTableStat.RowsUpdated, int64(0))
+
+				dedupUploader := mockUploader(t, []whutils.LoadFile{
+					{Location: whth.UploadLoadFile(t, fm, "../testdata/dedup.csv.gz", tableName).Location},
+				}, tableName, schemaInUpload, schemaInWarehouse)
+
+				pg = postgres.New(config.New(), logger.NOP, stats.NOP)
+				err = pg.Setup(ctx, mergeModeWarehouse, dedupUploader)
+				require.NoError(t, err)
+
+				loadTableStat, err = pg.LoadTable(ctx, tableName)
+				require.NoError(t, err)
+				require.Equal(t, loadTableStat.RowsInserted, int64(0))
+				require.Equal(t, loadTableStat.RowsUpdated, int64(14))
+
+				records := whth.RetrieveRecordsFromWarehouse(t, pg.DB.DB,
+					fmt.Sprintf(`
+					SELECT
+					  id,
+					  received_at,
+					  test_bool,
+					  test_datetime,
+					  test_float,
+					  test_int,
+					  test_string
+					FROM
+					  %q.%q
+					ORDER BY
+					  id;
+					`,
+						namespace,
+						tableName,
+					),
+				)
+				require.Equal(t, records, whth.DedupTestRecords())
+			})
+			t.Run("with merge mode and unknown primary key", func(t *testing.T) {
+				ctx := context.Background()
+				tableName := "merge_mode_unknown_primary_key_test_table"
+
+				mockUploader := mockUploader(t, []whutils.LoadFile{
+					{Location: whth.UploadLoadFile(t, fm, "../testdata/load.csv.gz", tableName).Location},
+				}, tableName, schemaInUpload, schemaInWarehouse)
+
+				mergeModeWarehouse := th.Clone(t, warehouse)
+				mergeModeWarehouse.Destination.Config[model.MergeModeSetting.String()] = map[string]any{
+					"enabled":    true,
+					"primaryKey": "message_id",
+				}
+
+				pg := postgres.New(config.New(), logger.NOP, stats.NOP)
+				err := pg.Setup(ctx, mergeModeWarehouse, mockUploader)
+				require.NoError(t, err)
+
+				err = pg.CreateSchema(ctx)
+				require.NoError(t, err)
+
+				err = pg.CreateTable(ctx, tableName, schemaInWarehouse)
+				require.NoError(t, err)
+
+				loadTableStat, err := pg.LoadTable(ctx, tableName)
+				require.ErrorContains(t, err, "merge mode primary key message_id is not a column of table "+tableName)
+				require.Nil(t, loadTableStat)
+			})
 		})
 		t.Run("append", func(t *testing.T) {
 			ctx := context.Background()
@@ -9,4 +9,5 @@ var (
 	ErrNoWarehouseFound            = errors.New("no warehouse found")
 	ErrWorkspaceFromSourceNotFound = errors.New("workspace from source not found")
 	ErrMarshallResponse            = errors.New("can't marshall response")
+	ErrInvalidUploadID             = errors.New("invalid upload id")
 )
@@ -0,0 +1,106 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type ColumnPolicyType = string
+
+const (
+	HashColumnPolicy     ColumnPolicyType = "hash"
+	TruncateColumnPolicy ColumnPolicyType = "truncate"
+	NullColumnPolicy     ColumnPolicyType = "null"
+	TokenizeColumnPolicy ColumnPolicyType = "tokenize"
+)
+
+// ColumnPolicy is applied to the values of a column while generating load files
+type ColumnPolicy struct {
+	Type ColumnPolicyType `json:"type"`
+	// Length is the number of characters kept by the truncate policy
+	Length int `json:"length,omitempty"`
+}
+
+// TableColumnPolicies are the column policies of a table, keyed by lowercase column name
+type TableColumnPolicies map[string]ColumnPolicy
+
+// ColumnPolicies are the column policies of a destination, keyed by lowercase table name
+type ColumnPolicies map[string]TableColumnPolicies
+
+// Table returns the column policies of the table, table names are case-insensitive
+func (cp ColumnPolicies) Table(tableName string) TableColumnPolicies {
+	return cp[strings.ToLower(tableName)]
+}
+
+// Column returns the policy of the column, column names are case-insensitive
+func (tp TableColumnPolicies) Column(columnName string) (ColumnPolicy, bool) {
+	policy, ok := tp[strings.ToLower(columnName)]
+	return policy, ok
+}
+
+// ParseColumnPolicies parses the column policies from the destination config, which are defined as
+//
+//	"columnPolicies": {"<table>": {"<column>": {"type": "hash|truncate|null|tokenize", "length": <int>}}}
+//
+// Hashing and tokenization require the columnPolicySalt setting to be defined as well.
+// Since hashing, tokenizing and truncating produce strings, they are rejected for columns which aren't strings in the schema.
+func ParseColumnPolicies(destConfig map[string]interface{}, schema Schema) (ColumnPolicies, error) {
+	rawPolicies, ok := destConfig[ColumnPoliciesSetting.String()]
+	if !ok || rawPolicies == nil {
+		return nil, nil
+	}
+
+	marshalledPolicies, err := json.Marshal(rawPolicies)
+	if err != nil {
+		return nil, fmt.Errorf("marshal column policies: %w", err)
+	}
+
+	var policies ColumnPolicies
+	if err := json.Unmarshal(marshalledPolicies, &policies); err != nil {
+		return nil, fmt.Errorf("unmarshal column policies: %w", err)
+	}
+
+	salt, _ := destConfig[ColumnPolicySaltSetting.String()].(string)
+
+	columnPolicies := make(ColumnPolicies, len(policies))
+	for tableName, tablePolicies := range policies {
+		tableColumnPolicies := make(TableColumnPolicies, len(tablePolicies))
+		for columnName, policy := range tablePolicies {
+			switch policy.Type {
+			case NullColumnPolicy:
+			case TruncateColumnPolicy:
+				if policy.Length <= 0 {
+					return nil, fmt.Errorf("truncate policy for column %s.%s: length should be greater than 0", tableName, columnName)
+				}
+			case HashColumnPolicy, TokenizeColumnPolicy:
+				if salt == "" {
+					return nil, fmt.Errorf("%s policy for column %s.%s: %s is not defined", policy.Type, tableName, columnName, ColumnPolicySaltSetting)
+				}
+			default:
+				return nil, fmt.Errorf("unknown policy %q for column %s.%s", policy.Type, tableName, columnName)
+			}
+			if columnType, ok := schema.columnType(tableName, columnName); ok && policy.Type != NullColumnPolicy && columnType != StringDataType && columnType != TextDataType {
+				return nil, fmt.Errorf("%s policy for column %s.%s: not supported for columns of type %s", policy.Type, tableName, columnName, columnType)
+			}
+			tableColumnPolicies[strings.ToLower(columnName)] = policy
+		}
+		columnPolicies[strings.ToLower(tableName)] = tableColumnPolicies
+	}
+	return columnPolicies, nil
+}
+
+// columnType returns the type of the column in the schema, table and column names are case-insensitive
+func (s Schema) columnType(tableName, columnName string) (string, bool) {
+	for table, tableSchema := range s {
+		if !strings.EqualFold(table, tableName) {
+			continue
+		}
+		for column, columnType := range tableSchema {
+			if strings.EqualFold(column, columnName) {
+				return columnType, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,152 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rudderlabs/rudder-server/warehouse/internal/model"
+)
+
+func TestParseColumnPolicies(t *testing.T) {
+	testCases := []struct {
+		name          string
+		destConfig    map[string]interface{}
+		schema        model.Schema
+		expected      model.ColumnPolicies
+		expectedError string
+	}{
+		{
+			name:       "no policies",
+			destConfig: map[string]interface{}{},
+		},
+		{
+			name: "policies",
+			destConfig: map[string]interface{}{
+				"columnPolicies": map[string]interface{}{
+					"TRACKS": map[string]interface{}{
+						"Context_IP": map[string]interface{}{"type": "hash"},
+						"email":      map[string]interface{}{"type": "tokenize"},
+					},
+					"users": map[string]interface{}{
+						"phone":   map[string]interface{}{"type": "truncate", "length": 4},
+						"address": map[string]interface{}{"type": "null"},
+					},
+				},
+				"columnPolicySalt": "salt",
+			},
+			schema: model.Schema{
+				"tracks": {"context_ip": "string", "email": "text"},
+				"users":  {"phone": "string", "address": "int"},
+			},
+			expected: model.ColumnPolicies{
+				"tracks": {
+					"context_ip": {Type: model.HashColumnPolicy},
+					"email":      {Type: model.TokenizeColumnPolicy},
+				},
+				"users": {
+					"phone":   {Type: model.TruncateColumnPolicy, Length: 4},
+					"address": {Type: model.NullColumnPolicy},
+				},
+			},
+		},
+		{
+			name: "unknown policy",
+			destConfig: map[string]interface{}{
+				"columnPolicies": map[string]interface{}{
+					"tracks": map[string]interface{}{
+						"context_ip": map[string]interface{}{"type": "encrypt"},
+					},
+				},
+			},
+			expectedError: `unknown policy "encrypt" for column tracks.context_ip`,
+		},
+		{
+			name: "truncate without length",
+			destConfig: map[string]interface{}{
+				"columnPolicies": map[string]interface{}{
+					"tracks": map[string]interface{}{
+						"context_ip": map[string]interface{}{"type": "truncate"},
+					},
+				},
+			},
+			expectedError: "truncate policy for column tracks.context_ip: length should be greater than 0",
+		},
+		{
+			name: "hash without salt",
+			destConfig: map[string]interface{}{
+				"columnPolicies": map[string]interface{}{
+					"tracks": map[string]interface{}{
+						"context_ip": map[string]interface{}{"type": "hash"},
+					},
+				},
+			},
+			expectedError: "hash policy for column tracks.context_ip: columnPolicySalt is not defined",
+		},
+		{
+			name: "hash of non-string column",
+			destConfig: map[string]interface{}{
+				"columnPolicies": map[string]interface{}{
+					"tracks": map[string]interface{}{
+						"Age": map[string]interface{}{"type": "hash"},
+					},
+				},
+				"columnPolicySalt": "salt",
+			},
+			schema: model.Schema{
+				"TRACKS": {"AGE": "int"},
+			},
+			expectedError: "hash policy for column tracks.Age: not supported for columns of type int",
+		},
+		{
+			name: "truncate of non-string column",
+			destConfig: map[string]interface{}{
+				"columnPolicies": map[string]interface{}{
+					"tracks": map[string]interface{}{
+						"sent_at": map[string]interface{}{"type": "truncate", "length": 4},
+					},
+				},
+			},
+			schema: model.Schema{
+				"tracks": {"sent_at": "datetime"},
+			},
+			expectedError: "truncate policy for column tracks.sent_at: not supported for columns of type datetime",
+		},
+		{
+			name: "invalid policies",
+			destConfig: map[string]interface{}{
+				"columnPolicies": []interface{}{"context_ip"},
+			},
+			expectedError: "unmarshal column policies: json: cannot unmarshal array into Go value of type model.ColumnPolicies",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			policies, err := model.ParseColumnPolicies(tc.destConfig, tc.schema)
+			if tc.expectedError != "" {
+				require.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, policies)
+		})
+	}
+
+	t.Run("lookup", func(t *testing.T) {
+		policies := model.ColumnPolicies{
+			"tracks": {
+				"context_ip": {Type: model.HashColumnPolicy},
+			},
+		}
+
+		policy, ok := policies.Table("TRACKS").Column("CONTEXT_IP")
+		require.True(t, ok)
+		require.Equal(t, model.ColumnPolicy{Type: model.HashColumnPolicy}, policy)
+
+		_, ok = policies.Table("tracks").Column("event")
+		require.False(t, ok)
+		_, ok = policies.Table("pages").Column("context_ip")
+		require.False(t, ok)
+	})
+}
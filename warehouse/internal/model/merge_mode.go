@@ -0,0 +1,72 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const defaultMergeModePrimaryKey = "id"
+
+// MergeMode is the merge mode of a table.
+// When enabled, loading the table replaces the rows having the same primary key instead of appending them.
+type MergeMode struct {
+	Enabled    bool
+	PrimaryKey string
+}
+
+type mergeModeConfig struct {
+	Enabled    *bool                      `json:"enabled"`
+	PrimaryKey string                     `json:"primaryKey"`
+	Tables     map[string]mergeModeConfig `json:"tables"`
+}
+
+// GetMergeMode returns the merge mode of the table, which is configured for the destination as
+//
+//	"mergeMode": {"enabled": true, "primaryKey": "id", "tables": {"<table>": {"enabled": false, "primaryKey": "<column>"}}}
+//
+// Table settings take precedence over the destination ones, table names are case-insensitive and the primary key defaults to id.
+// A malformed configuration returns an error instead of falling back to appending, which would silently introduce duplicates.
+func (w *Warehouse) GetMergeMode(tableName string) (MergeMode, error) {
+	mergeMode := MergeMode{PrimaryKey: defaultMergeModePrimaryKey}
+
+	rawConfig, ok := w.Destination.Config[MergeModeSetting.String()]
+	if !ok || rawConfig == nil {
+		return mergeMode, nil
+	}
+
+	marshalledConfig, err := json.Marshal(rawConfig)
+	if err != nil {
+		return MergeMode{}, fmt.Errorf("marshalling merge mode config: %w", err)
+	}
+	var conf mergeModeConfig
+	if err = json.Unmarshal(marshalledConfig, &conf); err != nil {
+		return MergeMode{}, fmt.Errorf("unmarshalling merge mode config: %w", err)
+	}
+
+	apply := func(conf mergeModeConfig) {
+		if conf.Enabled != nil {
+			mergeMode.Enabled = *conf.Enabled
+		}
+		if conf.PrimaryKey != "" {
+			mergeMode.PrimaryKey = conf.PrimaryKey
+		}
+	}
+
+	apply(conf)
+	for name, tableConf := range conf.Tables {
+		if strings.EqualFold(name, tableName) {
+			apply(tableConf)
+			break
+		}
+	}
+	return mergeMode, nil
+}
+
+// Validate returns an error if the primary key is not a column of the table
+func (m MergeMode) Validate(tableName string, tableSchema TableSchema) error {
+	if _, ok := tableSchema[m.PrimaryKey]; !ok {
+		return fmt.Errorf("merge mode primary key %s is not a column of table %s", m.PrimaryKey, tableName)
+	}
+	return nil
+}
@@ -28,6 +28,7 @@ type WHSchema struct {
 	DestinationID   string
 	DestinationType string
 	Schema          Schema
+	ColumnPolicies  ColumnPolicies
 	CreatedAt       time.Time
 	UpdatedAt       time.Time
 }
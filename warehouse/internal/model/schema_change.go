@@ -0,0 +1,164 @@
+package model
+
+import (
+	"maps"
+	"time"
+)
+
+type SchemaChangeStatus = string
+
+const (
+	SchemaChangePending  SchemaChangeStatus = "pending"
+	SchemaChangeApproved SchemaChangeStatus = "approved"
+	SchemaChangeRejected SchemaChangeStatus = "rejected"
+)
+
+// TableSchemaChange is the change an upload introduces to a table in the warehouse
+type TableSchemaChange struct {
+	TableToBeCreated bool        `json:"table_to_be_created"`
+	AddedColumns     TableSchema `json:"added_columns"`
+	AlteredColumns   TableSchema `json:"altered_columns"`
+}
+
+// TableSchemaChanges are the table schema changes introduced by an upload, keyed by table name
+type TableSchemaChanges map[string]TableSchemaChange
+
+// SchemaChanges are the schema changes introduced by an upload which are held for approval
+type SchemaChanges struct {
+	Status     SchemaChangeStatus `json:"status"`
+	Tables     TableSchemaChanges `json:"tables"`
+	ReviewedAt time.Time          `json:"reviewed_at"`
+}
+
+// Reviewed returns true if the schema changes were either approved or rejected and they include all the proposed changes
+func (sc SchemaChanges) Reviewed(proposed TableSchemaChanges) bool {
+	if sc.Status != SchemaChangeApproved && sc.Status != SchemaChangeRejected {
+		return false
+	}
+	return sc.Tables.Includes(proposed)
+}
+
+// Includes returns true if every table, column and column type in other is part of the changes
+func (tc TableSchemaChanges) Includes(other TableSchemaChanges) bool {
+	for tableName, change := range other {
+		included, ok := tc[tableName]
+		if !ok {
+			return false
+		}
+		if change.TableToBeCreated && !included.TableToBeCreated {
+			return false
+		}
+		for columnName, columnType := range change.AddedColumns {
+			if included.AddedColumns[columnName] != columnType {
+				return false
+			}
+		}
+		for columnName, columnType := range change.AlteredColumns {
+			if included.AlteredColumns[columnName] != columnType {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Discard returns a copy of the upload schema without the changes.
+// New tables and columns are removed, whereas altered columns keep the type they have in the warehouse.
+func (tc TableSchemaChanges) Discard(uploadSchema, warehouseSchema Schema) Schema {
+	discarded := make(Schema, len(uploadSchema))
+	for tableName, tableSchema := range uploadSchema {
+		change, ok := tc[tableName]
+		if !ok {
+			discarded[tableName] = tableSchema
+			continue
+		}
+		if change.TableToBeCreated {
+			continue
+		}
+
+		discardedTableSchema := make(TableSchema, len(tableSchema))
+		for columnName, columnType := range tableSchema {
+			if _, ok := change.AddedColumns[columnName]; ok {
+				continue
+			}
+			if _, ok := change.AlteredColumns[columnName]; ok {
+				if warehouseType, ok := warehouseSchema[tableName][columnName]; ok {
+					columnType = warehouseType
+				}
+			}
+			discardedTableSchema[columnName] = columnType
+		}
+		discarded[tableName] = discardedTableSchema
+	}
+	return discarded
+}
+
+// Merge returns the changes combined with the other ones, where the column types of other take precedence
+func (tc TableSchemaChanges) Merge(other TableSchemaChanges) TableSchemaChanges {
+	merged := make(TableSchemaChanges, len(tc)+len(other))
+	for tableName, change := range tc {
+		merged[tableName] = TableSchemaChange{
+			TableToBeCreated: change.TableToBeCreated,
+			AddedColumns:     maps.Clone(change.AddedColumns),
+			AlteredColumns:   maps.Clone(change.AlteredColumns),
+		}
+	}
+	for tableName, change := range other {
+		mergedChange, ok := merged[tableName]
+		if !ok {
+			mergedChange = TableSchemaChange{AddedColumns: TableSchema{}, AlteredColumns: TableSchema{}}
+		}
+		if mergedChange.AddedColumns == nil {
+			mergedChange.AddedColumns = TableSchema{}
+		}
+		if mergedChange.AlteredColumns == nil {
+			mergedChange.AlteredColumns = TableSchema{}
+		}
+		mergedChange.TableToBeCreated = mergedChange.TableToBeCreated || change.TableToBeCreated
+		maps.Copy(mergedChange.AddedColumns, change.AddedColumns)
+		maps.Copy(mergedChange.AlteredColumns, change.AlteredColumns)
+		merged[tableName] = mergedChange
+	}
+	return merged
+}
+
+// Split splits the changes into the ones which have already been rejected and the ones which are yet to be reviewed.
+// Tables whose creation has been rejected are rejected as a whole, whereas columns are rejected only if they have been rejected with the same type.
+func (tc TableSchemaChanges) Split(rejected TableSchemaChanges) (alreadyRejected, remaining TableSchemaChanges) {
+	alreadyRejected, remaining = make(TableSchemaChanges), make(TableSchemaChanges)
+	for tableName, change := range tc {
+		rejectedChange, ok := rejected[tableName]
+		if !ok {
+			remaining[tableName] = change
+			continue
+		}
+		if change.TableToBeCreated && rejectedChange.TableToBeCreated {
+			alreadyRejected[tableName] = change
+			continue
+		}
+
+		rejectedColumns := TableSchemaChange{AddedColumns: TableSchema{}, AlteredColumns: TableSchema{}}
+		remainingColumns := TableSchemaChange{TableToBeCreated: change.TableToBeCreated, AddedColumns: TableSchema{}, AlteredColumns: TableSchema{}}
+		for columnName, columnType := range change.AddedColumns {
+			if rejectedChange.AddedColumns[columnName] == columnType {
+				rejectedColumns.AddedColumns[columnName] = columnType
+			} else {
+				remainingColumns.AddedColumns[columnName] = columnType
+			}
+		}
+		for columnName, columnType := range change.AlteredColumns {
+			if rejectedChange.AlteredColumns[columnName] == columnType {
+				rejectedColumns.AlteredColumns[columnName] = columnType
+			} else {
+				remainingColumns.AlteredColumns[columnName] = columnType
+			}
+		}
+		if len(rejectedColumns.AddedColumns) > 0 || len(rejectedColumns.AlteredColumns) > 0 {
+			alreadyRejected[tableName] = rejectedColumns
+		}
+		if remainingColumns.TableToBeCreated || len(remainingColumns.AddedColumns) > 0 || len(remainingColumns.AlteredColumns) > 0 {
+			remaining[tableName] = remainingColumns
+		}
+	}
+	return alreadyRejected, remaining
+}
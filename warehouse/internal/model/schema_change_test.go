@@ -0,0 +1,193 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rudderlabs/rudder-server/warehouse/internal/model"
+)
+
+func TestSchemaChanges(t *testing.T) {
+	proposed := model.TableSchemaChanges{
+		"tracks": {
+			AddedColumns:   model.TableSchema{"context_ip": "string"},
+			AlteredColumns: model.TableSchema{"event_text": "text"},
+		},
+		"product_viewed": {
+			TableToBeCreated: true,
+			AddedColumns:     model.TableSchema{"id": "string", "price": "float"},
+		},
+	}
+
+	t.Run("reviewed", func(t *testing.T) {
+		testCases := []struct {
+			name          string
+			schemaChanges model.SchemaChanges
+			reviewed      bool
+		}{
+			{
+				name:          "no review",
+				schemaChanges: model.SchemaChanges{},
+				reviewed:      false,
+			},
+			{
+				name:          "pending",
+				schemaChanges: model.SchemaChanges{Status: model.SchemaChangePending, Tables: proposed},
+				reviewed:      false,
+			},
+			{
+				name:          "approved",
+				schemaChanges: model.SchemaChanges{Status: model.SchemaChangeApproved, Tables: proposed},
+				reviewed:      true,
+			},
+			{
+				name:          "rejected",
+				schemaChanges: model.SchemaChanges{Status: model.SchemaChangeRejected, Tables: proposed},
+				reviewed:      true,
+			},
+			{
+				name: "approved without a table",
+				schemaChanges: model.SchemaChanges{Status: model.SchemaChangeApproved, Tables: model.TableSchemaChanges{
+					"tracks": proposed["tracks"],
+				}},
+				reviewed: false,
+			},
+			{
+				name: "approved without a column",
+				schemaChanges: model.SchemaChanges{Status: model.SchemaChangeApproved, Tables: model.TableSchemaChanges{
+					"tracks": {
+						AddedColumns: model.TableSchema{"context_ip": "string"},
+					},
+					"product_viewed": proposed["product_viewed"],
+				}},
+				reviewed: false,
+			},
+			{
+				name: "approved with a different column type",
+				schemaChanges: model.SchemaChanges{Status: model.SchemaChangeApproved, Tables: model.TableSchemaChanges{
+					"tracks": {
+						AddedColumns:   model.TableSchema{"context_ip": "int"},
+						AlteredColumns: model.TableSchema{"event_text": "text"},
+					},
+					"product_viewed": proposed["product_viewed"],
+				}},
+				reviewed: false,
+			},
+			{
+				name: "approved with more changes",
+				schemaChanges: model.SchemaChanges{Status: model.SchemaChangeApproved, Tables: model.TableSchemaChanges{
+					"tracks": {
+						AddedColumns:   model.TableSchema{"context_ip": "string", "context_locale": "string"},
+						AlteredColumns: model.TableSchema{"event_text": "text"},
+					},
+					"product_viewed": proposed["product_viewed"],
+					"pages":          {TableToBeCreated: true, AddedColumns: model.TableSchema{"id": "string"}},
+				}},
+				reviewed: true,
+			},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				require.Equal(t, tc.reviewed, tc.schemaChanges.Reviewed(proposed))
+			})
+		}
+	})
+
+	t.Run("discard", func(t *testing.T) {
+		uploadSchema := model.Schema{
+			"tracks": {
+				"id":         "string",
+				"context_ip": "string",
+				"event_text": "text",
+			},
+			"product_viewed": {
+				"id":    "string",
+				"price": "float",
+			},
+			"pages": {
+				"id": "string",
+			},
+		}
+		warehouseSchema := model.Schema{
+			"tracks": {
+				"id":         "string",
+				"event_text": "string",
+			},
+			"pages": {
+				"id": "string",
+			},
+		}
+
+		require.Equal(t, model.Schema{
+			"tracks": {
+				"id":         "string",
+				"event_text": "string",
+			},
+			"pages": {
+				"id": "string",
+			},
+		}, proposed.Discard(uploadSchema, warehouseSchema))
+		require.Len(t, uploadSchema, 3, "upload schema should not be modified")
+		require.Len(t, uploadSchema["tracks"], 3, "upload schema should not be modified")
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		merged := proposed.Merge(model.TableSchemaChanges{
+			"tracks": {
+				AddedColumns: model.TableSchema{"context_ip": "int", "context_locale": "string"},
+			},
+			"pages": {
+				TableToBeCreated: true,
+				AddedColumns:     model.TableSchema{"id": "string"},
+			},
+		})
+		require.Equal(t, model.TableSchemaChanges{
+			"tracks": {
+				AddedColumns:   model.TableSchema{"context_ip": "int", "context_locale": "string"},
+				AlteredColumns: model.TableSchema{"event_text": "text"},
+			},
+			"product_viewed": {
+				TableToBeCreated: true,
+				AddedColumns:     model.TableSchema{"id": "string", "price": "float"},
+			},
+			"pages": {
+				TableToBeCreated: true,
+				AddedColumns:     model.TableSchema{"id": "string"},
+				AlteredColumns:   model.TableSchema{},
+			},
+		}, merged)
+		require.Equal(t, "string", proposed["tracks"].AddedColumns["context_ip"], "changes should not be modified")
+	})
+
+	t.Run("split", func(t *testing.T) {
+		alreadyRejected, remaining := proposed.Split(model.TableSchemaChanges{
+			"tracks": {
+				AddedColumns:   model.TableSchema{"context_ip": "int"},
+				AlteredColumns: model.TableSchema{"event_text": "text"},
+			},
+			"product_viewed": {
+				TableToBeCreated: true,
+				AddedColumns:     model.TableSchema{"id": "string"},
+			},
+		})
+		require.Equal(t, model.TableSchemaChanges{
+			"tracks": {
+				AddedColumns:   model.TableSchema{},
+				AlteredColumns: model.TableSchema{"event_text": "text"},
+			},
+			"product_viewed": proposed["product_viewed"],
+		}, alreadyRejected)
+		require.Equal(t, model.TableSchemaChanges{
+			"tracks": {
+				AddedColumns:   model.TableSchema{"context_ip": "string"},
+				AlteredColumns: model.TableSchema{},
+			},
+		}, remaining)
+
+		alreadyRejected, remaining = proposed.Split(nil)
+		require.Empty(t, alreadyRejected)
+		require.Equal(t, proposed, remaining)
+	})
+}
@@ -22,6 +22,7 @@ const (
 	ExportingDataFailed       = "exporting_data_failed"
 	Aborted                   = "aborted"
 	Failed                    = "failed"
+	PendingSchemaApproval     = "pending_schema_approval"
 )
 
 type JobErrorType = string
@@ -68,6 +69,8 @@ var (
 	ErrSourcesJobNotFound = errors.New("sources job not found")
 	ErrLoadFileNotFound   = errors.New("load file not found")
 	ErrNoUploadsFound     = errors.New("no uploads found")
+
+	ErrNoPendingSchemaChanges = errors.New("no pending schema changes")
 )
 
 type Upload struct {
@@ -103,7 +106,8 @@ type Upload struct {
 	LastAttemptAt  time.Time
 	Attempts       int64
 
-	UploadSchema Schema
+	UploadSchema  Schema
+	SchemaChanges SchemaChanges
 }
 
 type Timings []map[string]time.Time
@@ -63,8 +63,18 @@ var (
 	SyncFrequencySetting          DestinationConfigSetting = destConfSetting("syncFrequency")
 	SyncStartAtSetting            DestinationConfigSetting = destConfSetting("syncStartAt")
 	ExcludeWindowSetting          DestinationConfigSetting = destConfSetting("excludeWindow")
+	TableFormatSetting            DestinationConfigSetting = destConfSetting("tableFormat")
+	DatabasePathSetting           DestinationConfigSetting = destConfSetting("databasePath")
+	DatabaseInBucketSetting       DestinationConfigSetting = destConfSetting("storeDatabaseInBucket")
+	SchemaChangeApprovalSetting   DestinationConfigSetting = destConfSetting("requireSchemaChangeApproval")
+	ColumnPoliciesSetting         DestinationConfigSetting = destConfSetting("columnPolicies")
+	ColumnPolicySaltSetting       DestinationConfigSetting = destConfSetting("columnPolicySalt")
+	MergeModeSetting              DestinationConfigSetting = destConfSetting("mergeMode")
 )
 
+// IcebergTableFormat is the table format through which datalake uploads are committed as snapshots of iceberg tables
+const IcebergTableFormat = "iceberg"
+
 type Warehouse struct {
 	WorkspaceID string
 	Source      backendconfig.SourceT
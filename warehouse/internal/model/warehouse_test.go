@@ -209,3 +209,124 @@ func TestWarehouse_GetStringDestinationConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestWarehouse_GetMergeMode(t *testing.T) {
+	mergeModeWarehouse := func(mergeMode map[string]interface{}) Warehouse {
+		return Warehouse{
+			Destination: backendconfig.DestinationT{
+				Config: map[string]interface{}{
+					"mergeMode": mergeMode,
+				},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name      string
+		warehouse Warehouse
+		tableName string
+		expected  MergeMode
+		wantError bool
+	}{
+		{
+			name:      "not configured",
+			warehouse: Warehouse{},
+			tableName: "tracks",
+			expected:  MergeMode{PrimaryKey: "id"},
+		},
+		{
+			name: "enabled for destination",
+			warehouse: mergeModeWarehouse(map[string]interface{}{
+				"enabled": true,
+			}),
+			tableName: "tracks",
+			expected:  MergeMode{Enabled: true, PrimaryKey: "id"},
+		},
+		{
+			name: "enabled for destination with primary key",
+			warehouse: mergeModeWarehouse(map[string]interface{}{
+				"enabled":    true,
+				"primaryKey": "message_id",
+			}),
+			tableName: "tracks",
+			expected:  MergeMode{Enabled: true, PrimaryKey: "message_id"},
+		},
+		{
+			name: "enabled for table",
+			warehouse: mergeModeWarehouse(map[string]interface{}{
+				"tables": map[string]interface{}{
+					"TRACKS": map[string]interface{}{
+						"enabled":    true,
+						"primaryKey": "event_id",
+					},
+				},
+			}),
+			tableName: "tracks",
+			expected:  MergeMode{Enabled: true, PrimaryKey: "event_id"},
+		},
+		{
+			name: "enabled for another table",
+			warehouse: mergeModeWarehouse(map[string]interface{}{
+				"tables": map[string]interface{}{
+					"pages": map[string]interface{}{
+						"enabled": true,
+					},
+				},
+			}),
+			tableName: "tracks",
+			expected:  MergeMode{PrimaryKey: "id"},
+		},
+		{
+			name: "disabled for table",
+			warehouse: mergeModeWarehouse(map[string]interface{}{
+				"enabled":    true,
+				"primaryKey": "message_id",
+				"tables": map[string]interface{}{
+					"tracks": map[string]interface{}{
+						"enabled": false,
+					},
+				},
+			}),
+			tableName: "tracks",
+			expected:  MergeMode{PrimaryKey: "message_id"},
+		},
+		{
+			name: "invalid config",
+			warehouse: mergeModeWarehouse(map[string]interface{}{
+				"enabled": "true",
+			}),
+			tableName: "tracks",
+			wantError: true,
+		},
+		{
+			name: "not an object",
+			warehouse: Warehouse{
+				Destination: backendconfig.DestinationT{
+					Config: map[string]interface{}{
+						"mergeMode": true,
+					},
+				},
+			},
+			tableName: "tracks",
+			wantError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mergeMode, err := tc.warehouse.GetMergeMode(tc.tableName)
+			if tc.wantError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, mergeMode)
+		})
+	}
+
+	t.Run("validate", func(t *testing.T) {
+		mergeMode := MergeMode{Enabled: true, PrimaryKey: "message_id"}
+		require.NoError(t, mergeMode.Validate("tracks", TableSchema{"message_id": "string"}))
+		require.EqualError(t, mergeMode.Validate("tracks", TableSchema{"id": "string"}), "merge mode primary key message_id is not a column of table tracks")
+	})
+}
@@ -0,0 +1,134 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rudderlabs/rudder-server/utils/timeutil"
+	sqlmw "github.com/rudderlabs/rudder-server/warehouse/integrations/middleware/sqlquerywrapper"
+	"github.com/rudderlabs/rudder-server/warehouse/internal/model"
+	whutils "github.com/rudderlabs/rudder-server/warehouse/utils"
+)
+
+const rejectedSchemaChangesTableName = whutils.WarehouseRejectedSchemaChangesTable
+
+// RejectedSchemaChanges keeps track of the schema changes which have been rejected for a destination's namespace,
+// so that uploads introducing them again are not held for approval.
+type RejectedSchemaChanges repo
+
+func NewRejectedSchemaChanges(db *sqlmw.DB, opts ...Opt) *RejectedSchemaChanges {
+	r := &RejectedSchemaChanges{
+		db:  db,
+		now: timeutil.Now,
+	}
+	for _, opt := range opts {
+		opt((*repo)(r))
+	}
+	return r
+}
+
+// Get returns the rejected schema changes of the destination's namespace, keyed by table name
+func (r *RejectedSchemaChanges) Get(ctx context.Context, destinationID, namespace string) (model.TableSchemaChanges, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT
+			table_name,
+			changes
+		FROM
+			`+rejectedSchemaChangesTableName+`
+		WHERE
+			destination_id = $1 AND
+			namespace = $2;
+`,
+		destinationID,
+		namespace,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying rejected schema changes: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	rejected := make(model.TableSchemaChanges)
+	for rows.Next() {
+		var (
+			tableName  string
+			changesRaw []byte
+			change     model.TableSchemaChange
+		)
+		if err := rows.Scan(&tableName, &changesRaw); err != nil {
+			return nil, fmt.Errorf("scanning rejected schema changes: %w", err)
+		}
+		if err := json.Unmarshal(changesRaw, &change); err != nil {
+			return nil, fmt.Errorf("unmarshal rejected schema changes of table %s: %w", tableName, err)
+		}
+		rejected[tableName] = change
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating rejected schema changes: %w", err)
+	}
+	return rejected, nil
+}
+
+// rejectSchemaChangesWithTx adds the changes to the rejected schema changes of the destination's namespace
+func rejectSchemaChangesWithTx(ctx context.Context, tx *sqlmw.Tx, now time.Time, destinationID, namespace string, changes model.TableSchemaChanges) error {
+	for tableName, change := range changes {
+		var changesRaw []byte
+		err := tx.QueryRowContext(ctx, `
+			SELECT
+				changes
+			FROM
+				`+rejectedSchemaChangesTableName+`
+			WHERE
+				destination_id = $1 AND
+				namespace = $2 AND
+				table_name = $3
+			FOR UPDATE;
+`,
+			destinationID,
+			namespace,
+			tableName,
+		).Scan(&changesRaw)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("querying rejected schema changes of table %s: %w", tableName, err)
+		}
+
+		rejected := model.TableSchemaChanges{}
+		if len(changesRaw) > 0 {
+			var rejectedChange model.TableSchemaChange
+			if err := json.Unmarshal(changesRaw, &rejectedChange); err != nil {
+				return fmt.Errorf("unmarshal rejected schema changes of table %s: %w", tableName, err)
+			}
+			rejected[tableName] = rejectedChange
+		}
+
+		changesRaw, err = json.Marshal(rejected.Merge(model.TableSchemaChanges{tableName: change})[tableName])
+		if err != nil {
+			return fmt.Errorf("marshal rejected schema changes of table %s: %w", tableName, err)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO `+rejectedSchemaChangesTableName+` (
+				destination_id, namespace, table_name,
+				changes, created_at, updated_at
+			)
+			VALUES
+				($1, $2, $3, $4, $5, $5)
+			ON CONFLICT (destination_id, namespace, table_name)
+			DO UPDATE SET
+				changes = EXCLUDED.changes,
+				updated_at = EXCLUDED.updated_at;
+`,
+			destinationID,
+			namespace,
+			tableName,
+			changesRaw,
+			now,
+		)
+		if err != nil {
+			return fmt.Errorf("upserting rejected schema changes of table %s: %w", tableName, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package repo_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rudderlabs/rudder-server/warehouse/internal/model"
+	"github.com/rudderlabs/rudder-server/warehouse/internal/repo"
+)
+
+func TestRejectedSchemaChanges(t *testing.T) {
+	const (
+		destinationID   = "destination_id"
+		destinationType = "destination_type"
+		namespace       = "namespace"
+	)
+
+	db, ctx := setupDB(t), context.Background()
+
+	repoUpload := repo.NewUploads(db)
+	repoStaging := repo.NewStagingFiles(db)
+	repoRejected := repo.NewRejectedSchemaChanges(db)
+
+	review := func(t *testing.T, namespace string, tables model.TableSchemaChanges, schemaChangeStatus model.SchemaChangeStatus) {
+		t.Helper()
+
+		stagingID, err := repoStaging.Insert(ctx, &model.StagingFileWithSchema{})
+		require.NoError(t, err)
+
+		uploadID, err := repoUpload.CreateWithStagingFiles(ctx, model.Upload{
+			DestinationID:   destinationID,
+			DestinationType: destinationType,
+			Namespace:       namespace,
+			Status:          model.Waiting,
+		}, []*model.StagingFile{{ID: stagingID}})
+		require.NoError(t, err)
+
+		schemaChanges, err := json.Marshal(model.SchemaChanges{
+			Status: model.SchemaChangePending,
+			Tables: tables,
+		})
+		require.NoError(t, err)
+		require.NoError(t, repoUpload.Update(ctx, uploadID, []repo.UpdateKeyValue{
+			repo.UploadFieldStatus(model.PendingSchemaApproval),
+			repo.UploadFieldSchemaChanges(schemaChanges),
+		}))
+		require.NoError(t, repoUpload.ReviewSchemaChanges(ctx, uploadID, schemaChangeStatus))
+	}
+
+	rejected, err := repoRejected.Get(ctx, destinationID, namespace)
+	require.NoError(t, err)
+	require.Empty(t, rejected)
+
+	review(t, namespace, model.TableSchemaChanges{
+		"tracks": {
+			AddedColumns:   model.TableSchema{"context_ip": "string"},
+			AlteredColumns: model.TableSchema{"event_text": "text"},
+		},
+	}, model.SchemaChangeRejected)
+	review(t, namespace, model.TableSchemaChanges{
+		"tracks": {
+			AddedColumns: model.TableSchema{"context_locale": "string"},
+		},
+		"product_viewed": {
+			TableToBeCreated: true,
+			AddedColumns:     model.TableSchema{"id": "string"},
+		},
+	}, model.SchemaChangeRejected)
+	review(t, namespace, model.TableSchemaChanges{
+		"pages": {
+			TableToBeCreated: true,
+			AddedColumns:     model.TableSchema{"id": "string"},
+		},
+	}, model.SchemaChangeApproved)
+	review(t, "other_namespace", model.TableSchemaChanges{
+		"screens": {
+			TableToBeCreated: true,
+			AddedColumns:     model.TableSchema{"id": "string"},
+		},
+	}, model.SchemaChangeRejected)
+
+	rejected, err = repoRejected.Get(ctx, destinationID, namespace)
+	require.NoError(t, err)
+	require.Equal(t, model.TableSchemaChanges{
+		"tracks": {
+			AddedColumns:   model.TableSchema{"context_ip": "string", "context_locale": "string"},
+			AlteredColumns: model.TableSchema{"event_text": "text"},
+		},
+		"product_viewed": {
+			TableToBeCreated: true,
+			AddedColumns:     model.TableSchema{"id": "string"},
+			AlteredColumns:   model.TableSchema{},
+		},
+	}, rejected)
+
+	t.Run("context cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		_, err := repoRejected.Get(ctx, destinationID, namespace)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -23,6 +23,7 @@ const whSchemaTableColumns = `
    	destination_id,
 	destination_type,
 	schema,
+	column_policies,
    	created_at,
    	updated_at
 `
@@ -50,21 +51,26 @@ func (sh *WHSchema) Insert(ctx context.Context, whSchema *model.WHSchema) (int64
 	if err != nil {
 		return id, fmt.Errorf("marshaling schema: %w", err)
 	}
+	columnPoliciesPayload, err := json.Marshal(whSchema.ColumnPolicies)
+	if err != nil {
+		return id, fmt.Errorf("marshaling column policies: %w", err)
+	}
 
 	err = sh.db.QueryRowContext(ctx, `
 		INSERT INTO `+whSchemaTableName+` (
 		  wh_upload_id, source_id, namespace, destination_id,
-		  destination_type, schema, created_at,
-		  updated_at
+		  destination_type, schema, column_policies,
+		  created_at, updated_at
 		)
 		VALUES
-		  ($1, $2, $3, $4, $5, $6, $7, $8) ON CONFLICT (
+		  ($1, $2, $3, $4, $5, $6, $7, $8, $9) ON CONFLICT (
 			source_id, destination_id, namespace
 		  ) DO
 		UPDATE
 		SET
 		  schema = $6,
-		  updated_at = $7 RETURNING id;
+		  column_policies = $7,
+		  updated_at = $8 RETURNING id;
 `,
 		whSchema.UploadID,
 		whSchema.SourceID,
@@ -72,6 +78,7 @@ func (sh *WHSchema) Insert(ctx context.Context, whSchema *model.WHSchema) (int64
 		whSchema.DestinationID,
 		whSchema.DestinationType,
 		schemaPayload,
+		columnPoliciesPayload,
 		now.UTC(),
 		now.UTC(),
 	).Scan(&id)
@@ -122,6 +129,7 @@ func parseWHSchemas(rows *sqlmiddleware.Rows) ([]*model.WHSchema, error) {
 		var (
 			whSchema            model.WHSchema
 			schemaPayloadRawRaw []byte
+			columnPoliciesRaw   []byte
 		)
 		err := rows.Scan(
 			&whSchema.ID,
@@ -131,6 +139,7 @@ func parseWHSchemas(rows *sqlmiddleware.Rows) ([]*model.WHSchema, error) {
 			&whSchema.DestinationID,
 			&whSchema.DestinationType,
 			&schemaPayloadRawRaw,
+			&columnPoliciesRaw,
 			&whSchema.CreatedAt,
 			&whSchema.UpdatedAt,
 		)
@@ -149,6 +158,13 @@ func parseWHSchemas(rows *sqlmiddleware.Rows) ([]*model.WHSchema, error) {
 
 		whSchema.Schema = schemaPayload
 
+		if len(columnPoliciesRaw) > 0 {
+			err = json.Unmarshal(columnPoliciesRaw, &whSchema.ColumnPolicies)
+			if err != nil {
+				return nil, fmt.Errorf("unmarshal column policies: %w", err)
+			}
+		}
+
 		whSchemas = append(whSchemas, &whSchema)
 	}
 
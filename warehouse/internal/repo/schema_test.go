@@ -63,8 +63,14 @@ func TestWHSchemasRepo(t *testing.T) {
 			DestinationID:   destinationID,
 			DestinationType: destinationType,
 			Schema:          schemaModel,
-			CreatedAt:       now,
-			UpdatedAt:       now,
+			ColumnPolicies: model.ColumnPolicies{
+				"table_name_1": {
+					"column_name_1": {Type: model.HashColumnPolicy},
+					"column_name_7": {Type: model.TruncateColumnPolicy, Length: 4},
+				},
+			},
+			CreatedAt: now,
+			UpdatedAt: now,
 		}
 	)
 
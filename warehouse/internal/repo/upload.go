@@ -54,7 +54,8 @@ const (
 		timings,
 		COALESCE(metadata->>'priority', '100')::int,
 		first_event_at,
-		last_event_at
+		last_event_at,
+		schema_changes
 	`
 )
 
@@ -70,6 +71,7 @@ var (
 	UploadFieldMetadata        UpdateField = func(v interface{}) UpdateKeyValue { return keyValue{"metadata", v} }
 	UploadFieldError           UpdateField = func(v interface{}) UpdateKeyValue { return keyValue{"error", v} }
 	UploadFieldErrorCategory   UpdateField = func(v interface{}) UpdateKeyValue { return keyValue{"error_category", v} }
+	UploadFieldSchemaChanges   UpdateField = func(v interface{}) UpdateKeyValue { return keyValue{"schema_changes", v} }
 )
 
 type Uploads repo
@@ -136,12 +138,17 @@ func (u *Uploads) CreateWithStagingFiles(ctx context.Context, upload model.Uploa
 		lastEventAt = files[len(files)-1].LastEventAt
 	}
 
+	loadFileType := upload.LoadFileType
+	if loadFileType == "" {
+		loadFileType = warehouseutils.GetLoadFileType(upload.DestinationType)
+	}
+
 	metadataMap := UploadMetadata{
 		UseRudderStorage: files[0].UseRudderStorage,
 		SourceTaskRunID:  files[0].SourceTaskRunID,
 		SourceJobID:      files[0].SourceJobID,
 		SourceJobRunID:   files[0].SourceJobRunID,
-		LoadFileType:     warehouseutils.GetLoadFileType(upload.DestinationType),
+		LoadFileType:     loadFileType,
 		Retried:          upload.Retried,
 		Priority:         upload.Priority,
 		NextRetryTime:    upload.NextRetryTime,
@@ -284,16 +291,17 @@ func (u *Uploads) GetToProcess(ctx context.Context, destType string, limit int,
 	partitionIdentifierSQL := `destination_id, namespace`
 
 	if len(opts.SkipIdentifiers) > 0 {
-		skipIdentifiersSQL = `AND ((destination_id || '_' || namespace)) != ALL($5)`
+		skipIdentifiersSQL = `AND ((destination_id || '_' || namespace)) != ALL($6)`
 	}
 
 	if opts.AllowMultipleSourcesForJobsPickup {
 		if len(opts.SkipIdentifiers) > 0 {
-			skipIdentifiersSQL = `AND ((source_id || '_' || destination_id || '_' || namespace)) != ALL($5)`
+			skipIdentifiersSQL = `AND ((source_id || '_' || destination_id || '_' || namespace)) != ALL($6)`
 		}
 		partitionIdentifierSQL = fmt.Sprintf(`%s, %s`, "source_id", partitionIdentifierSQL)
 	}
 
+	// uploads pending schema approval are not picked up, and they hold back the uploads queued after them
 	sqlStatement := fmt.Sprintf(`
 			SELECT
 			`+uploadColumns+`
@@ -312,7 +320,8 @@ func (u *Uploads) GetToProcess(ctx context.Context, destType string, limit int,
           			workspace_id <> ALL ($4)
 			) grouped_uploads
 			WHERE
-				grouped_uploads.row_number = 1
+				grouped_uploads.row_number = 1 AND
+				grouped_uploads.status != $5
 			ORDER BY
 				COALESCE(metadata->>'priority', '100')::int ASC,
 				COALESCE(first_event_at, NOW()) ASC,
@@ -337,6 +346,7 @@ func (u *Uploads) GetToProcess(ctx context.Context, destType string, limit int,
 		model.ExportedData,
 		model.Aborted,
 		pq.Array(opts.SkipWorkspaces),
+		model.PendingSchemaApproval,
 	}
 
 	if len(opts.SkipIdentifiers) > 0 {
@@ -388,6 +398,7 @@ func (u *Uploads) UploadJobsStats(ctx context.Context, destType string, opts Pro
 			in_progress = false AND
 			status != $3 AND
 			status != $4  AND
+			status != $6 AND
 			COALESCE((metadata->>'nextRetryTime')::TIMESTAMPTZ, $1::TIMESTAMPTZ) <= $1::TIMESTAMPTZ AND
 			workspace_id <> ALL ($5)`
 
@@ -401,10 +412,11 @@ func (u *Uploads) UploadJobsStats(ctx context.Context, destType string, opts Pro
 		model.ExportedData,
 		model.Aborted,
 		pq.Array(opts.SkipWorkspaces),
+		model.PendingSchemaApproval,
 	}
 
 	if len(opts.SkipIdentifiers) > 0 {
-		query += `AND ((destination_id || '_' || namespace)) != ALL($6)`
+		query += `AND ((destination_id || '_' || namespace)) != ALL($7)`
 		args = append(args, pq.Array(opts.SkipIdentifiers))
 	}
 
@@ -483,6 +495,7 @@ func scanUpload(scan scanFn, upload *model.Upload) error {
 		firstEventAt, lastEventAt sql.NullTime
 		metadataRaw               []byte
 		timingsRaw                []byte
+		schemaChangesRaw          []byte
 	)
 
 	err := scan(
@@ -506,6 +519,7 @@ func scanUpload(scan scanFn, upload *model.Upload) error {
 		&upload.Priority,
 		&firstEventAt,
 		&lastEventAt,
+		&schemaChangesRaw,
 	)
 	if err != nil {
 		return err
@@ -526,6 +540,11 @@ func scanUpload(scan scanFn, upload *model.Upload) error {
 			return fmt.Errorf("unmarshal timings: %w", err)
 		}
 	}
+	if len(schemaChangesRaw) > 0 {
+		if err := json.Unmarshal(schemaChangesRaw, &upload.SchemaChanges); err != nil {
+			return fmt.Errorf("unmarshal schema changes: %w", err)
+		}
+	}
 	upload.SourceTaskRunID = metadata.SourceTaskRunID
 	upload.SourceJobID = metadata.SourceJobID
 	upload.SourceJobRunID = metadata.SourceJobRunID
@@ -960,6 +979,59 @@ func (u *Uploads) TriggerUpload(ctx context.Context, uploadID int64) error {
 	return nil
 }
 
+// ReviewSchemaChanges records the review of the schema changes of an upload pending schema approval
+// and moves the upload back to waiting, so that it gets picked up again.
+// Rejected schema changes are also recorded for the upload's destination and namespace,
+// so that later uploads introducing them again are not held for approval.
+func (u *Uploads) ReviewSchemaChanges(ctx context.Context, uploadID int64, schemaChangeStatus model.SchemaChangeStatus) error {
+	return (*repo)(u).WithTx(ctx, func(tx *sqlmiddleware.Tx) error {
+		var (
+			destinationID, namespace string
+			tablesRaw                []byte
+		)
+		now := u.now()
+		err := tx.QueryRowContext(ctx, `
+			UPDATE
+				`+uploadsTableName+`
+			SET
+				schema_changes = schema_changes || jsonb_build_object('status', $1::TEXT, 'reviewed_at', $2::TIMESTAMPTZ),
+				status = $3,
+				updated_at = $2
+			WHERE
+				id = $4 AND
+				status = $5
+			RETURNING
+				destination_id,
+				namespace,
+				COALESCE(schema_changes->'tables', '{}'::JSONB);
+`,
+			schemaChangeStatus,
+			now,
+			model.Waiting,
+			uploadID,
+			model.PendingSchemaApproval,
+		).Scan(&destinationID, &namespace, &tablesRaw)
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.ErrNoPendingSchemaChanges
+		}
+		if err != nil {
+			return fmt.Errorf("review schema changes: update: %w", err)
+		}
+		if schemaChangeStatus != model.SchemaChangeRejected {
+			return nil
+		}
+
+		var tables model.TableSchemaChanges
+		if err := json.Unmarshal(tablesRaw, &tables); err != nil {
+			return fmt.Errorf("review schema changes: unmarshal: %w", err)
+		}
+		if err := rejectSchemaChangesWithTx(ctx, tx, now, destinationID, namespace, tables); err != nil {
+			return fmt.Errorf("review schema changes: %w", err)
+		}
+		return nil
+	})
+}
+
 func (u *Uploads) Retry(ctx context.Context, opts model.RetryOptions) (int64, error) {
 	filterQuery, filterArgs := retryQueryArgs(&opts)
 
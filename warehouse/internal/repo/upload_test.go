@@ -1191,6 +1191,115 @@ func TestUploads_TriggerUpload(t *testing.T) {
 	})
 }
 
+func TestUploads_ReviewSchemaChanges(t *testing.T) {
+	const (
+		sourceID        = "source_id"
+		destinationID   = "destination_id"
+		destinationType = "destination_type"
+		workspaceID     = "workspace_id"
+	)
+
+	db, ctx := setupDB(t), context.Background()
+
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	repoUpload := repo.NewUploads(db, repo.WithNow(func() time.Time {
+		return now
+	}))
+	repoStaging := repo.NewStagingFiles(db, repo.WithNow(func() time.Time {
+		return now
+	}))
+
+	stagingID, err := repoStaging.Insert(ctx, &model.StagingFileWithSchema{})
+	require.NoError(t, err)
+
+	uploadID, err := repoUpload.CreateWithStagingFiles(ctx, model.Upload{
+		SourceID:        sourceID,
+		DestinationID:   destinationID,
+		DestinationType: destinationType,
+		WorkspaceID:     workspaceID,
+		Status:          model.Waiting,
+	}, []*model.StagingFile{
+		{
+			ID:            stagingID,
+			SourceID:      sourceID,
+			DestinationID: destinationID,
+			WorkspaceID:   workspaceID,
+		},
+	})
+	require.NoError(t, err)
+
+	upload, err := repoUpload.Get(ctx, uploadID)
+	require.NoError(t, err)
+	require.Equal(t, model.SchemaChanges{}, upload.SchemaChanges)
+
+	tables := model.TableSchemaChanges{
+		"tracks": {
+			AddedColumns:   model.TableSchema{"context_ip": "string"},
+			AlteredColumns: model.TableSchema{},
+		},
+	}
+	schemaChanges, err := json.Marshal(model.SchemaChanges{
+		Status: model.SchemaChangePending,
+		Tables: tables,
+	})
+	require.NoError(t, err)
+
+	t.Run("not pending", func(t *testing.T) {
+		err := repoUpload.ReviewSchemaChanges(ctx, uploadID, model.SchemaChangeApproved)
+		require.ErrorIs(t, err, model.ErrNoPendingSchemaChanges)
+	})
+
+	t.Run("unknown id", func(t *testing.T) {
+		err := repoUpload.ReviewSchemaChanges(ctx, -1, model.SchemaChangeApproved)
+		require.ErrorIs(t, err, model.ErrNoPendingSchemaChanges)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		require.NoError(t, repoUpload.Update(ctx, uploadID, []repo.UpdateKeyValue{
+			repo.UploadFieldStatus(model.PendingSchemaApproval),
+			repo.UploadFieldSchemaChanges(schemaChanges),
+		}))
+
+		upload, err := repoUpload.Get(ctx, uploadID)
+		require.NoError(t, err)
+		require.Equal(t, model.PendingSchemaApproval, upload.Status)
+		require.Equal(t, model.SchemaChanges{Status: model.SchemaChangePending, Tables: tables}, upload.SchemaChanges)
+
+		uploads, err := repoUpload.GetToProcess(ctx, destinationType, 10, repo.ProcessOptions{})
+		require.NoError(t, err)
+		require.Empty(t, uploads)
+
+		err = repoUpload.ReviewSchemaChanges(ctx, uploadID, model.SchemaChangeRejected)
+		require.NoError(t, err)
+
+		upload, err = repoUpload.Get(ctx, uploadID)
+		require.NoError(t, err)
+		require.Equal(t, model.Waiting, upload.Status)
+		require.Equal(t, model.SchemaChangeRejected, upload.SchemaChanges.Status)
+		require.Equal(t, tables, upload.SchemaChanges.Tables)
+		require.Equal(t, now, upload.SchemaChanges.ReviewedAt.UTC())
+
+		rejected, err := repo.NewRejectedSchemaChanges(db).Get(ctx, destinationID, upload.Namespace)
+		require.NoError(t, err)
+		require.Equal(t, tables, rejected)
+
+		uploads, err = repoUpload.GetToProcess(ctx, destinationType, 10, repo.ProcessOptions{})
+		require.NoError(t, err)
+		require.Len(t, uploads, 1)
+
+		err = repoUpload.ReviewSchemaChanges(ctx, uploadID, model.SchemaChangeApproved)
+		require.ErrorIs(t, err, model.ErrNoPendingSchemaChanges)
+	})
+
+	t.Run("context cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		err := repoUpload.ReviewSchemaChanges(ctx, uploadID, model.SchemaChangeApproved)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
 func TestUploads_Retry(t *testing.T) {
 	const (
 		sourceID        = "source_id"
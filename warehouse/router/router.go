@@ -104,6 +104,7 @@ type Router struct {
 		stagingFilesBatchSize             config.ValueLoader[int]
 		warehouseSyncFreqIgnore           config.ValueLoader[bool]
 		cronTrackerRetries                config.ValueLoader[int64]
+		useParquetLoadFiles               bool
 	}
 
 	stats struct {
@@ -643,7 +644,7 @@ func (r *Router) createUploadJobsFromStagingFiles(ctx context.Context, warehouse
 			DestinationType: r.destType,
 			Status:          model.Waiting,
 
-			LoadFileType:  warehouseutils.GetLoadFileType(r.destType),
+			LoadFileType:  r.loadFileType(),
 			NextRetryTime: uploadStartAfter,
 			Priority:      priority,
 
@@ -691,6 +692,15 @@ func (r *Router) uploadFreqInS(syncFrequency string) int64 {
 	return freqInMin * 60
 }
 
+// loadFileType returns the type of load files to generate for the uploads of the destination type.
+// DuckDB loads parquet load files instead of csv ones when Warehouse.duckdb.useParquetLoadFiles is enabled.
+func (r *Router) loadFileType() string {
+	if r.destType == warehouseutils.DUCKDB && r.config.useParquetLoadFiles {
+		return warehouseutils.LoadFileTypeParquet
+	}
+	return warehouseutils.GetLoadFileType(r.destType)
+}
+
 func (r *Router) updateCreateJobMarker(warehouse model.Warehouse, lastProcessedTime time.Time) {
 	r.createJobMarkerMapLock.Lock()
 	defer r.createJobMarkerMapLock.Unlock()
@@ -713,6 +723,7 @@ func (r *Router) loadReloadableConfig(whName string) {
 	r.config.enableJitterForSyncs = r.conf.GetReloadableBoolVar(false, "Warehouse.enableJitterForSyncs")
 	r.config.warehouseSyncFreqIgnore = r.conf.GetReloadableBoolVar(false, "Warehouse.warehouseSyncFreqIgnore")
 	r.config.cronTrackerRetries = r.conf.GetReloadableInt64Var(5, 1, "Warehouse.cronTrackerRetries")
+	r.config.useParquetLoadFiles = r.conf.GetBoolVar(false, fmt.Sprintf(`Warehouse.%v.useParquetLoadFiles`, whName))
 }
 
 func (r *Router) loadStats() {
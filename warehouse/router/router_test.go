@@ -1227,3 +1227,25 @@ func TestRouter(t *testing.T) {
 		)
 	})
 }
+
+func TestRouter_LoadFileType(t *testing.T) {
+	testCases := []struct {
+		name                string
+		destType            string
+		useParquetLoadFiles bool
+		expected            string
+	}{
+		{name: "duckdb", destType: warehouseutils.DUCKDB, expected: warehouseutils.LoadFileTypeCsv},
+		{name: "duckdb with parquet load files", destType: warehouseutils.DUCKDB, useParquetLoadFiles: true, expected: warehouseutils.LoadFileTypeParquet},
+		{name: "bigquery", destType: warehouseutils.BQ, useParquetLoadFiles: true, expected: warehouseutils.LoadFileTypeJson},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := Router{}
+			r.destType = tc.destType
+			r.config.useParquetLoadFiles = tc.useParquetLoadFiles
+			require.Equal(t, tc.expected, r.loadFileType())
+		})
+	}
+}
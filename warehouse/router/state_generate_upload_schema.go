@@ -3,8 +3,12 @@ package router
 import (
 	"encoding/json"
 	"fmt"
+	"slices"
 
+	"github.com/rudderlabs/rudder-server/warehouse/internal/model"
 	"github.com/rudderlabs/rudder-server/warehouse/internal/repo"
+	"github.com/rudderlabs/rudder-server/warehouse/logfield"
+	whutils "github.com/rudderlabs/rudder-server/warehouse/utils"
 )
 
 func (job *UploadJob) generateUploadSchema() error {
@@ -12,7 +16,10 @@ func (job *UploadJob) generateUploadSchema() error {
 	if err != nil {
 		return fmt.Errorf("consolidate staging files schema using warehouse schema: %w", err)
 	}
+	return job.setUploadSchema(uploadSchema)
+}
 
+func (job *UploadJob) setUploadSchema(uploadSchema model.Schema) error {
 	marshalledSchema, err := json.Marshal(uploadSchema)
 	if err != nil {
 		return fmt.Errorf("marshal upload schema: %w", err)
@@ -33,3 +40,103 @@ func (job *UploadJob) generateUploadSchema() error {
 
 	return nil
 }
+
+// reviewSchemaChanges returns true if the upload needs to wait for its schema changes to be approved.
+// This is the case for destinations requiring schema changes to be approved, whenever the upload schema
+// introduces new tables, new columns or widens column types which are yet to be reviewed.
+// Once reviewed, approved changes are applied as usual, whereas rejected ones are discarded from the upload schema.
+// Changes which have already been rejected for the destination's namespace by an earlier upload are discarded right away.
+func (job *UploadJob) reviewSchemaChanges() (bool, error) {
+	if !job.warehouse.GetBoolDestinationConfig(model.SchemaChangeApprovalSetting) {
+		return false, nil
+	}
+
+	proposed := job.proposedSchemaChanges()
+	if len(proposed) == 0 {
+		return false, nil
+	}
+
+	rejected, err := job.rejectedSchemaRepo.Get(job.ctx, job.upload.DestinationID, job.upload.Namespace)
+	if err != nil {
+		return false, fmt.Errorf("get rejected schema changes: %w", err)
+	}
+	alreadyRejected, proposed := proposed.Split(rejected)
+	if len(alreadyRejected) > 0 {
+		if err := job.discardSchemaChanges(alreadyRejected); err != nil {
+			return false, fmt.Errorf("discarding already rejected schema changes: %w", err)
+		}
+	}
+	if len(proposed) == 0 {
+		return false, nil
+	}
+
+	if !job.upload.SchemaChanges.Reviewed(proposed) {
+		schemaChanges := model.SchemaChanges{
+			Status: model.SchemaChangePending,
+			Tables: proposed,
+		}
+		marshalledSchemaChanges, err := json.Marshal(schemaChanges)
+		if err != nil {
+			return false, fmt.Errorf("marshal schema changes: %w", err)
+		}
+
+		err = job.uploadsRepo.Update(
+			job.ctx,
+			job.upload.ID,
+			[]repo.UpdateKeyValue{
+				repo.UploadFieldSchemaChanges(marshalledSchemaChanges),
+			},
+		)
+		if err != nil {
+			return false, fmt.Errorf("set schema changes: %w", err)
+		}
+
+		job.upload.SchemaChanges = schemaChanges
+		job.logger.Infow("upload is pending schema approval", logfield.Schema, string(marshalledSchemaChanges))
+		return true, nil
+	}
+
+	if job.upload.SchemaChanges.Status == model.SchemaChangeRejected {
+		if err := job.discardSchemaChanges(proposed); err != nil {
+			return false, fmt.Errorf("discarding rejected schema changes: %w", err)
+		}
+	}
+	return false, nil
+}
+
+// discardSchemaChanges discards the changes from the upload schema
+func (job *UploadJob) discardSchemaChanges(changes model.TableSchemaChanges) error {
+	warehouseSchema := make(model.Schema, len(changes))
+	for tableName := range changes {
+		warehouseSchema[tableName] = job.schemaHandle.GetTableSchemaInWarehouse(tableName)
+	}
+	return job.setUploadSchema(changes.Discard(job.upload.UploadSchema, warehouseSchema))
+}
+
+// proposedSchemaChanges returns the changes the upload schema introduces to the schema in the warehouse.
+// Tables managed by rudder, like the discards and the identity resolution ones, are not subject to approval.
+func (job *UploadJob) proposedSchemaChanges() model.TableSchemaChanges {
+	skipTables := []string{
+		whutils.ToProviderCase(job.warehouse.Type, whutils.DiscardsTable),
+		whutils.IdentityMergeRulesWarehouseTableName(job.warehouse.Type),
+		whutils.IdentityMappingsWarehouseTableName(job.warehouse.Type),
+	}
+
+	proposed := make(model.TableSchemaChanges)
+	for tableName, tableSchema := range job.upload.UploadSchema {
+		if slices.Contains(skipTables, tableName) {
+			continue
+		}
+
+		diff := job.schemaHandle.TableSchemaDiff(tableName, tableSchema)
+		if !diff.Exists {
+			continue
+		}
+		proposed[tableName] = model.TableSchemaChange{
+			TableToBeCreated: diff.TableToBeCreated,
+			AddedColumns:     diff.ColumnMap,
+			AlteredColumns:   diff.AlteredColumnMap,
+		}
+	}
+	return proposed
+}
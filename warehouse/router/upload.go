@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 	"strconv"
 	"sync"
 	"time"
@@ -73,6 +74,7 @@ type UploadJob struct {
 	uploadsRepo          *repo.Uploads
 	stagingFileRepo      *repo.StagingFiles
 	loadFilesRepo        *repo.LoadFiles
+	rejectedSchemaRepo   *repo.RejectedSchemaChanges
 	recovery             *service.Recovery
 	whManager            manager.Manager
 	schemaHandle         *schema.Schema
@@ -168,6 +170,7 @@ func (f *UploadJobFactory) NewUploadJob(ctx context.Context, dto *model.UploadJo
 		uploadsRepo:          repo.NewUploads(f.db),
 		stagingFileRepo:      repo.NewStagingFiles(f.db),
 		loadFilesRepo:        repo.NewLoadFiles(f.db),
+		rejectedSchemaRepo:   repo.NewRejectedSchemaChanges(f.db),
 		schemaHandle: schema.New(
 			f.db,
 			dto.Warehouse,
@@ -339,6 +342,14 @@ func (job *UploadJob) run() (err error) {
 			if err = job.generateUploadSchema(); err != nil {
 				break
 			}
+			var pendingApproval bool
+			if pendingApproval, err = job.reviewSchemaChanges(); err != nil {
+				break
+			}
+			if pendingApproval {
+				newStatus = model.PendingSchemaApproval
+				break
+			}
 			newStatus = nextUploadState.completed
 
 		case model.CreatedTableUploads:
@@ -427,11 +438,14 @@ func (job *UploadJob) run() (err error) {
 			_ = job.loadFilesRepo.DeleteByStagingFiles(job.ctx, job.stagingFileIDs)
 			break
 		}
+		if newStatus == model.PendingSchemaApproval {
+			break
+		}
 
 		nextUploadState = nextState(newStatus)
 	}
 
-	if newStatus != model.ExportedData {
+	if newStatus != model.ExportedData && newStatus != model.PendingSchemaApproval {
 		return fmt.Errorf("upload Job failed: %w", err)
 	}
 
@@ -797,11 +811,17 @@ func (job *UploadJob) GetLoadFilesMetadata(ctx context.Context, options whutils.
 		limitSQL = fmt.Sprintf(`LIMIT %d`, options.Limit)
 	}
 
+	metadataSQL := `metadata`
+	if slices.Contains(whutils.TimeWindowDestinations, job.warehouse.Type) && job.warehouse.GetStringDestinationConfig(job.conf, model.TableFormatSetting) == model.IcebergTableFormat {
+		// iceberg snapshots record the row counts of their data files
+		metadataSQL = `COALESCE(metadata, '{}') || jsonb_build_object('total_rows', total_events)`
+	}
+
 	sqlStatement := fmt.Sprintf(`
 		WITH row_numbered_load_files as (
 		  SELECT
 			location,
-			metadata,
+			%[5]s AS metadata,
 			row_number() OVER (
 			  PARTITION BY staging_file_id,
 			  table_name
@@ -826,6 +846,7 @@ func (job *UploadJob) GetLoadFilesMetadata(ctx context.Context, options whutils.
 		misc.IntArrayToString(job.stagingFileIDs, ","),
 		tableFilterSQL,
 		limitSQL,
+		metadataSQL,
 	)
 
 	job.logger.Debugf(`Fetching loadFileLocations: %v`, sqlStatement)
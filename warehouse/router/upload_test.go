@@ -10,6 +10,7 @@ import (
 
 	"github.com/ory/dockertest/v3"
 	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
 
 	"github.com/rudderlabs/rudder-go-kit/config"
 	"github.com/rudderlabs/rudder-go-kit/logger"
@@ -19,9 +20,11 @@ import (
 
 	backendconfig "github.com/rudderlabs/rudder-server/backend-config"
 	"github.com/rudderlabs/rudder-server/services/alerta"
+	migrator "github.com/rudderlabs/rudder-server/services/sql-migrator"
 	sqlmiddleware "github.com/rudderlabs/rudder-server/warehouse/integrations/middleware/sqlquerywrapper"
 	"github.com/rudderlabs/rudder-server/warehouse/integrations/redshift"
 	"github.com/rudderlabs/rudder-server/warehouse/internal/model"
+	"github.com/rudderlabs/rudder-server/warehouse/internal/repo"
 	"github.com/rudderlabs/rudder-server/warehouse/schema"
 	warehouseutils "github.com/rudderlabs/rudder-server/warehouse/utils"
 )
@@ -695,3 +698,369 @@ func TestUploadJob_CanAppend(t *testing.T) {
 		})
 	}
 }
+
+func TestUploadJob_ReviewSchemaChanges(t *testing.T) {
+	t.Parallel()
+
+	pool, err := dockertest.NewPool("")
+	require.NoError(t, err)
+
+	pgResource, err := postgres.Setup(pool, t)
+	require.NoError(t, err)
+
+	err = (&migrator.Migrator{
+		Handle:          pgResource.DB,
+		MigrationsTable: "wh_schema_migrations",
+	}).Migrate("warehouse")
+	require.NoError(t, err)
+
+	db := sqlmiddleware.New(pgResource.DB)
+	ctx := context.Background()
+
+	uploadsRepo := repo.NewUploads(db)
+	stagingFilesRepo := repo.NewStagingFiles(db)
+
+	uploadSchema := model.Schema{
+		"tracks": {
+			"id":         "string",
+			"context_ip": "string",
+			"event_text": "text",
+		},
+		"product_viewed": {
+			"id":    "string",
+			"price": "float",
+		},
+		"rudder_discards": {
+			"column_name": "string",
+		},
+	}
+	proposed := model.TableSchemaChanges{
+		"tracks": {
+			AddedColumns:   model.TableSchema{"context_ip": "string"},
+			AlteredColumns: model.TableSchema{"event_text": "text"},
+		},
+		"product_viewed": {
+			TableToBeCreated: true,
+			AddedColumns:     model.TableSchema{"id": "string", "price": "float"},
+			AlteredColumns:   model.TableSchema{},
+		},
+	}
+
+	newJob := func(t *testing.T, requireApproval bool) *UploadJob {
+		t.Helper()
+
+		stagingFileID, err := stagingFilesRepo.Insert(ctx, &model.StagingFileWithSchema{})
+		require.NoError(t, err)
+
+		uploadID, err := uploadsRepo.CreateWithStagingFiles(ctx, model.Upload{
+			DestinationID:   t.Name(),
+			DestinationType: warehouseutils.POSTGRES,
+			Status:          model.Waiting,
+		}, []*model.StagingFile{{ID: stagingFileID}})
+		require.NoError(t, err)
+
+		upload, err := uploadsRepo.Get(ctx, uploadID)
+		require.NoError(t, err)
+		upload.UploadSchema = uploadSchema
+
+		ujf := &UploadJobFactory{
+			conf:         config.New(),
+			logger:       logger.NOP,
+			statsFactory: stats.NOP,
+			db:           db,
+		}
+		job := ujf.NewUploadJob(ctx, &model.UploadJob{
+			Upload: upload,
+			Warehouse: model.Warehouse{
+				Type: warehouseutils.POSTGRES,
+				Destination: backendconfig.DestinationT{
+					Config: map[string]interface{}{
+						model.SchemaChangeApprovalSetting.String(): requireApproval,
+					},
+				},
+			},
+		}, nil)
+		job.schemaHandle.UpdateWarehouseTableSchema("tracks", model.TableSchema{
+			"id":         "string",
+			"event_text": "string",
+		})
+		return job
+	}
+
+	review := func(t *testing.T, job *UploadJob, schemaChangeStatus model.SchemaChangeStatus) {
+		t.Helper()
+
+		require.NoError(t, uploadsRepo.ReviewSchemaChanges(ctx, job.upload.ID, schemaChangeStatus))
+
+		upload, err := uploadsRepo.Get(ctx, job.upload.ID)
+		require.NoError(t, err)
+		job.upload.SchemaChanges = upload.SchemaChanges
+	}
+
+	t.Run("approval not required", func(t *testing.T) {
+		t.Parallel()
+
+		job := newJob(t, false)
+
+		pendingApproval, err := job.reviewSchemaChanges()
+		require.NoError(t, err)
+		require.False(t, pendingApproval)
+	})
+
+	t.Run("no schema changes", func(t *testing.T) {
+		t.Parallel()
+
+		job := newJob(t, true)
+		job.upload.UploadSchema = model.Schema{
+			"tracks": {
+				"id": "string",
+			},
+		}
+
+		pendingApproval, err := job.reviewSchemaChanges()
+		require.NoError(t, err)
+		require.False(t, pendingApproval)
+	})
+
+	t.Run("pending approval", func(t *testing.T) {
+		t.Parallel()
+
+		job := newJob(t, true)
+
+		pendingApproval, err := job.reviewSchemaChanges()
+		require.NoError(t, err)
+		require.True(t, pendingApproval)
+
+		upload, err := uploadsRepo.Get(ctx, job.upload.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.SchemaChanges{Status: model.SchemaChangePending, Tables: proposed}, upload.SchemaChanges)
+
+		pendingApproval, err = job.reviewSchemaChanges()
+		require.NoError(t, err)
+		require.True(t, pendingApproval)
+	})
+
+	t.Run("approved", func(t *testing.T) {
+		t.Parallel()
+
+		job := newJob(t, true)
+
+		pendingApproval, err := job.reviewSchemaChanges()
+		require.NoError(t, err)
+		require.True(t, pendingApproval)
+		require.NoError(t, job.setUploadStatus(UploadStatusOpts{Status: model.PendingSchemaApproval}))
+
+		review(t, job, model.SchemaChangeApproved)
+
+		pendingApproval, err = job.reviewSchemaChanges()
+		require.NoError(t, err)
+		require.False(t, pendingApproval)
+		require.Equal(t, uploadSchema, job.upload.UploadSchema)
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		t.Parallel()
+
+		job := newJob(t, true)
+
+		pendingApproval, err := job.reviewSchemaChanges()
+		require.NoError(t, err)
+		require.True(t, pendingApproval)
+		require.NoError(t, job.setUploadStatus(UploadStatusOpts{Status: model.PendingSchemaApproval}))
+
+		review(t, job, model.SchemaChangeRejected)
+
+		pendingApproval, err = job.reviewSchemaChanges()
+		require.NoError(t, err)
+		require.False(t, pendingApproval)
+
+		expectedSchema := model.Schema{
+			"tracks": {
+				"id":         "string",
+				"event_text": "string",
+			},
+			"rudder_discards": {
+				"column_name": "string",
+			},
+		}
+		require.Equal(t, expectedSchema, job.upload.UploadSchema)
+
+		upload, err := uploadsRepo.Get(ctx, job.upload.ID)
+		require.NoError(t, err)
+		require.Equal(t, expectedSchema, upload.UploadSchema)
+	})
+
+	t.Run("rejected by an earlier upload", func(t *testing.T) {
+		t.Parallel()
+
+		job := newJob(t, true)
+
+		pendingApproval, err := job.reviewSchemaChanges()
+		require.NoError(t, err)
+		require.True(t, pendingApproval)
+		require.NoError(t, job.setUploadStatus(UploadStatusOpts{Status: model.PendingSchemaApproval}))
+
+		review(t, job, model.SchemaChangeRejected)
+
+		job = newJob(t, true)
+		job.upload.UploadSchema = model.Schema{
+			"tracks": {
+				"id":             "string",
+				"context_ip":     "string",
+				"context_locale": "string",
+				"event_text":     "text",
+			},
+			"product_viewed": {
+				"id":       "string",
+				"price":    "float",
+				"quantity": "int",
+			},
+		}
+
+		pendingApproval, err = job.reviewSchemaChanges()
+		require.NoError(t, err)
+		require.True(t, pendingApproval)
+		require.Equal(t, model.SchemaChanges{
+			Status: model.SchemaChangePending,
+			Tables: model.TableSchemaChanges{
+				"tracks": {
+					AddedColumns:   model.TableSchema{"context_locale": "string"},
+					AlteredColumns: model.TableSchema{},
+				},
+			},
+		}, job.upload.SchemaChanges)
+		require.Equal(t, model.Schema{
+			"tracks": {
+				"id":             "string",
+				"context_locale": "string",
+				"event_text":     "string",
+			},
+		}, job.upload.UploadSchema)
+
+		job = newJob(t, true)
+
+		pendingApproval, err = job.reviewSchemaChanges()
+		require.NoError(t, err)
+		require.False(t, pendingApproval)
+		require.Equal(t, model.Schema{
+			"tracks": {
+				"id":         "string",
+				"event_text": "string",
+			},
+			"rudder_discards": {
+				"column_name": "string",
+			},
+		}, job.upload.UploadSchema)
+	})
+
+	t.Run("new schema changes after review", func(t *testing.T) {
+		t.Parallel()
+
+		job := newJob(t, true)
+
+		pendingApproval, err := job.reviewSchemaChanges()
+		require.NoError(t, err)
+		require.True(t, pendingApproval)
+		require.NoError(t, job.setUploadStatus(UploadStatusOpts{Status: model.PendingSchemaApproval}))
+
+		review(t, job, model.SchemaChangeApproved)
+
+		job.upload.UploadSchema = model.Schema{
+			"tracks": {
+				"id":             "string",
+				"context_ip":     "string",
+				"context_locale": "string",
+			},
+		}
+
+		pendingApproval, err = job.reviewSchemaChanges()
+		require.NoError(t, err)
+		require.True(t, pendingApproval)
+		require.Equal(t, model.SchemaChanges{
+			Status: model.SchemaChangePending,
+			Tables: model.TableSchemaChanges{
+				"tracks": {
+					AddedColumns:   model.TableSchema{"context_ip": "string", "context_locale": "string"},
+					AlteredColumns: model.TableSchema{},
+				},
+			},
+		}, job.upload.SchemaChanges)
+	})
+}
+
+func TestUploadJob_GetLoadFilesMetadata(t *testing.T) {
+	t.Parallel()
+
+	pool, err := dockertest.NewPool("")
+	require.NoError(t, err)
+
+	pgResource, err := postgres.Setup(pool, t)
+	require.NoError(t, err)
+
+	err = (&migrator.Migrator{
+		Handle:          pgResource.DB,
+		MigrationsTable: "wh_schema_migrations",
+	}).Migrate("warehouse")
+	require.NoError(t, err)
+
+	db := sqlmiddleware.New(pgResource.DB)
+	ctx := context.Background()
+
+	stagingFileID, err := repo.NewStagingFiles(db).Insert(ctx, &model.StagingFileWithSchema{})
+	require.NoError(t, err)
+	require.NoError(t, repo.NewLoadFiles(db).Insert(ctx, []model.LoadFile{{
+		TableName:       "tracks",
+		Location:        "s3://bucket/tracks.parquet",
+		TotalRows:       42,
+		ContentLength:   1000,
+		StagingFileID:   stagingFileID,
+		DestinationType: warehouseutils.S3Datalake,
+	}}))
+
+	testCases := []struct {
+		name            string
+		destinationType string
+		tableFormat     string
+		wantTotalRows   bool
+	}{
+		{name: "iceberg datalake", destinationType: warehouseutils.S3Datalake, tableFormat: model.IcebergTableFormat, wantTotalRows: true},
+		{name: "datalake", destinationType: warehouseutils.S3Datalake},
+		{name: "warehouse", destinationType: warehouseutils.POSTGRES, tableFormat: model.IcebergTableFormat},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ujf := &UploadJobFactory{
+				conf:         config.New(),
+				logger:       logger.NOP,
+				statsFactory: stats.NOP,
+				db:           db,
+			}
+			job := ujf.NewUploadJob(ctx, &model.UploadJob{
+				Upload: model.Upload{
+					DestinationType: tc.destinationType,
+				},
+				Warehouse: model.Warehouse{
+					Type: tc.destinationType,
+					Destination: backendconfig.DestinationT{
+						Config: map[string]interface{}{
+							model.TableFormatSetting.String(): tc.tableFormat,
+						},
+					},
+				},
+				StagingFiles: []*model.StagingFile{{ID: stagingFileID}},
+			}, nil)
+
+			loadFiles, err := job.GetLoadFilesMetadata(ctx, warehouseutils.GetLoadFilesOptions{Table: "tracks"})
+			require.NoError(t, err)
+			require.Len(t, loadFiles, 1)
+			require.Equal(t, "s3://bucket/tracks.parquet", loadFiles[0].Location)
+			require.EqualValues(t, 1000, gjson.GetBytes(loadFiles[0].Metadata, "content_length").Int())
+			require.Equal(t, tc.wantTotalRows, gjson.GetBytes(loadFiles[0].Metadata, "total_rows").Exists())
+			if tc.wantTotalRows {
+				require.EqualValues(t, 42, gjson.GetBytes(loadFiles[0].Metadata, "total_rows").Int())
+			}
+		})
+	}
+}
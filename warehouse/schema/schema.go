@@ -263,7 +263,7 @@ func (sh *Schema) UpdateLocalSchema(ctx context.Context, uploadID int64, updated
 }
 
 // updateLocalSchema
-// 1. Inserts the updated schema into the local schema table
+// 1. Inserts the updated schema along with the column policies of the destination into the local schema table
 // 2. Updates the local schema instance
 func (sh *Schema) updateLocalSchema(ctx context.Context, uploadId int64, updatedSchema model.Schema) error {
 	updatedSchemaInBytes, err := json.Marshal(updatedSchema)
@@ -272,6 +272,11 @@ func (sh *Schema) updateLocalSchema(ctx context.Context, uploadId int64, updated
 	}
 	sh.stats.schemaSize.Observe(float64(len(updatedSchemaInBytes)))
 
+	columnPolicies, err := model.ParseColumnPolicies(sh.warehouse.Destination.Config, updatedSchema)
+	if err != nil {
+		return fmt.Errorf("parsing column policies: %w", err)
+	}
+
 	_, err = sh.schemaRepo.Insert(ctx, &model.WHSchema{
 		UploadID:        uploadId,
 		SourceID:        sh.warehouse.Source.ID,
@@ -279,6 +284,7 @@ func (sh *Schema) updateLocalSchema(ctx context.Context, uploadId int64, updated
 		DestinationID:   sh.warehouse.Destination.ID,
 		DestinationType: sh.warehouse.Type,
 		Schema:          updatedSchema,
+		ColumnPolicies:  columnPolicies,
 	})
 	if err != nil {
 		return fmt.Errorf("updating local schema: %w", err)
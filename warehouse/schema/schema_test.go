@@ -97,11 +97,13 @@ func TestSchema_UpdateLocalSchema(t *testing.T) {
 	}
 
 	testCases := []struct {
-		name          string
-		mockSchema    model.WHSchema
-		mockSchemaErr error
-		wantSchema    model.Schema
-		wantError     error
+		name               string
+		mockSchema         model.WHSchema
+		mockSchemaErr      error
+		destinationConfig  map[string]interface{}
+		wantSchema         model.Schema
+		wantColumnPolicies model.ColumnPolicies
+		wantError          error
 	}{
 		{
 			name:          "no schema in db",
@@ -142,6 +144,52 @@ func TestSchema_UpdateLocalSchema(t *testing.T) {
 			},
 			wantError: nil,
 		},
+		{
+			name: "schema in db with column policies",
+			mockSchema: model.WHSchema{
+				Schema: model.Schema{
+					tableName: model.TableSchema{
+						"column1": "string",
+					},
+				},
+			},
+			destinationConfig: map[string]interface{}{
+				"columnPolicies": map[string]interface{}{
+					tableName: map[string]interface{}{
+						"column1": map[string]interface{}{"type": "null"},
+					},
+				},
+			},
+			wantSchema: model.Schema{
+				tableName: model.TableSchema{
+					"column1": "string",
+				},
+			},
+			wantColumnPolicies: model.ColumnPolicies{
+				tableName: {
+					"column1": {Type: model.NullColumnPolicy},
+				},
+			},
+		},
+		{
+			name: "invalid column policies",
+			mockSchema: model.WHSchema{
+				Schema: model.Schema{
+					tableName: model.TableSchema{
+						"column1": "string",
+					},
+				},
+			},
+			destinationConfig: map[string]interface{}{
+				"columnPolicies": map[string]interface{}{
+					tableName: map[string]interface{}{
+						"column1": map[string]interface{}{"type": "hash"},
+					},
+				},
+			},
+			wantSchema: nil,
+			wantError:  errors.New("parsing column policies: hash policy for column test_table.column1: columnPolicySalt is not defined"),
+		},
 	}
 
 	for _, tc := range testCases {
@@ -165,7 +213,8 @@ func TestSchema_UpdateLocalSchema(t *testing.T) {
 						ID: sourceID,
 					},
 					Destination: backendconfig.DestinationT{
-						ID: destinationID,
+						ID:     destinationID,
+						Config: tc.destinationConfig,
 					},
 					Namespace: namespace,
 					Type:      warehouseType,
@@ -189,6 +238,7 @@ func TestSchema_UpdateLocalSchema(t *testing.T) {
 				require.NoError(t, err)
 				require.Equal(t, tc.wantSchema, s.localSchema)
 				require.Equal(t, tc.wantSchema, mockRepo.schemaMap[schemaKey(sourceID, destinationID, namespace)].Schema)
+				require.Equal(t, tc.wantColumnPolicies, mockRepo.schemaMap[schemaKey(sourceID, destinationID, namespace)].ColumnPolicies)
 			} else {
 				require.Error(t, err, fmt.Sprintf("got error %v, want error %v", err, tc.wantError))
 				require.Empty(t, s.localSchema)
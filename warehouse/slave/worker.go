@@ -235,6 +235,10 @@ func (w *worker) processStagingFile(ctx context.Context, job payload) ([]uploadR
 
 	jr.uuidTS = jr.now()
 
+	if jr.columnPolicies, err = model.ParseColumnPolicies(job.DestinationConfig, job.UploadSchema); err != nil {
+		return nil, fmt.Errorf("parsing column policies: %w", err)
+	}
+
 	// Initialize Discards Table
 	discardsTable := job.discardsTable()
 	jr.tableEventCountMap[discardsTable] = 0
@@ -288,7 +292,11 @@ func (w *worker) processStagingFile(ctx context.Context, job payload) ([]uploadR
 			return nil, err
 		}
 
-		eventLoader := w.encodingFactory.NewEventLoader(writer, job.LoadFileType, job.DestinationType)
+		eventLoader := encoding.WithColumnPolicies(
+			w.encodingFactory.NewEventLoader(writer, job.LoadFileType, job.DestinationType),
+			jr.columnPolicies.Table(tableName),
+			job.columnPolicySalt(),
+		)
 
 		for _, columnName := range sortedTableColumnMap[tableName] {
 			if eventLoader.IsLoadTimeColumn(columnName) {
@@ -66,6 +66,12 @@ func (p *payload) columnName(columnName string) string {
 	return warehouseutils.ToProviderCase(p.DestinationType, columnName)
 }
 
+// columnPolicySalt returns the salt used for hashing and tokenizing the values of columns with policies
+func (p *payload) columnPolicySalt() string {
+	salt, _ := p.DestinationConfig[model.ColumnPolicySaltSetting.String()].(string)
+	return salt
+}
+
 // sortedColumnMapForAllTables Sort columns per table to maintain same order in load file (needed in case of csv load file)
 func (p *payload) sortedColumnMapForAllTables() map[string][]string {
 	return lo.MapValues(p.UploadSchema, func(value model.TableSchema, key string) []string {
@@ -101,6 +107,7 @@ type jobRun struct {
 	uuidTS               time.Time
 	outputFileWritersMap map[string]encoding.LoadFileWriter
 	tableEventCountMap   map[string]int
+	columnPolicies       model.ColumnPolicies
 	stagingFileReader    *gzip.Reader
 	identifier           string
 	since                func(time.Time) time.Duration
@@ -467,6 +474,10 @@ func (jr *jobRun) handleDiscardTypes(tableName, columnName string, columnVal int
 	}
 	if hasID && hasReceivedAt {
 		eventLoader := jr.encodingFactory.NewEventLoader(discardWriter, jr.job.LoadFileType, jr.job.DestinationType)
+		// discarded values of columns with policies are subject to the same policies
+		if policy, ok := jr.columnPolicies.Table(tableName).Column(columnName); ok {
+			eventLoader = encoding.WithColumnPolicies(eventLoader, model.TableColumnPolicies{"column_value": policy}, jr.job.columnPolicySalt())
+		}
 		eventLoader.AddColumn("column_name", warehouseutils.DiscardsSchema["column_name"], columnName)
 		eventLoader.AddColumn("column_value", warehouseutils.DiscardsSchema["column_value"], fmt.Sprintf("%v", columnVal))
 		eventLoader.AddColumn("received_at", warehouseutils.DiscardsSchema["received_at"], receivedAt)
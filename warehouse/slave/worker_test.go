@@ -470,6 +470,79 @@ func TestSlaveWorker(t *testing.T) {
 
 			<-claimedJobDone
 		})
+
+		t.Run("invalid column policies", func(t *testing.T) {
+			subscribeCh := make(chan *notifier.ClaimJobResponse)
+			defer close(subscribeCh)
+
+			slaveNotifier := &mockSlaveNotifier{
+				subscribeCh: subscribeCh,
+			}
+
+			tenantManager := multitenant.New(config.New(), backendconfig.DefaultBackendConfig)
+
+			slaveWorker := newWorker(
+				config.New(),
+				logger.NOP,
+				stats.NOP,
+				slaveNotifier,
+				bcm.New(config.New(), nil, tenantManager, logger.NOP, stats.NOP),
+				constraints.New(config.New()),
+				ef,
+				workerIdx,
+			)
+
+			p := payload{
+				UploadID:             1,
+				StagingFileID:        1,
+				StagingFileLocation:  jobLocation,
+				UploadSchema:         schemaMap,
+				WorkspaceID:          workspaceID,
+				SourceID:             sourceID,
+				SourceName:           sourceName,
+				DestinationID:        destinationID,
+				DestinationName:      destinationName,
+				DestinationType:      destinationType,
+				DestinationNamespace: namespace,
+				DestinationConfig: lo.Assign(destConf, map[string]interface{}{
+					"columnPolicies": map[string]interface{}{
+						"tracks": map[string]interface{}{
+							"context_ip": map[string]interface{}{"type": "hash"},
+						},
+					},
+				}),
+				StagingDestinationConfig: map[string]interface{}{},
+				UniqueLoadGenID:          uuid.New().String(),
+				RudderStoragePrefix:      misc.GetRudderObjectStoragePrefix(),
+				LoadFileType:             "csv",
+			}
+
+			payloadJson, err := json.Marshal(p)
+			require.NoError(t, err)
+
+			claim := &notifier.ClaimJob{
+				Job: &notifier.Job{
+					ID:                  1,
+					BatchID:             uuid.New().String(),
+					Payload:             payloadJson,
+					Status:              model.Waiting,
+					WorkspaceIdentifier: "test_workspace",
+					Type:                notifier.JobTypeUpload,
+				},
+			}
+
+			claimedJobDone := make(chan struct{})
+			go func() {
+				defer close(claimedJobDone)
+
+				slaveWorker.processClaimedUploadJob(ctx, claim)
+			}()
+
+			response := <-subscribeCh
+			require.ErrorContains(t, response.Err, "parsing column policies: hash policy for column tracks.context_ip: columnPolicySalt is not defined")
+
+			<-claimedJobDone
+		})
 	})
 
 	t.Run("async job", func(t *testing.T) {
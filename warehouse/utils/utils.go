@@ -50,6 +50,7 @@ const (
 	S3Datalake    = "S3_DATALAKE"
 	GCSDatalake   = "GCS_DATALAKE"
 	AzureDatalake = "AZURE_DATALAKE"
+	DUCKDB        = "DUCKDB"
 )
 
 const (
@@ -62,12 +63,13 @@ const (
 
 // warehouse table names
 const (
-	WarehouseStagingFilesTable = "wh_staging_files"
-	WarehouseLoadFilesTable    = "wh_load_files"
-	WarehouseUploadsTable      = "wh_uploads"
-	WarehouseTableUploadsTable = "wh_table_uploads"
-	WarehouseSchemasTable      = "wh_schemas"
-	WarehouseAsyncJobTable     = "wh_async_jobs"
+	WarehouseStagingFilesTable          = "wh_staging_files"
+	WarehouseLoadFilesTable             = "wh_load_files"
+	WarehouseUploadsTable               = "wh_uploads"
+	WarehouseTableUploadsTable          = "wh_table_uploads"
+	WarehouseSchemasTable               = "wh_schemas"
+	WarehouseAsyncJobTable              = "wh_async_jobs"
+	WarehouseRejectedSchemaChangesTable = "wh_rejected_schema_changes"
 )
 
 const (
@@ -124,7 +126,7 @@ var (
 	awsCredsExpiryInS  config.ValueLoader[int64]
 
 	TimeWindowDestinations    = []string{S3Datalake, GCSDatalake, AzureDatalake}
-	WarehouseDestinations     = []string{RS, BQ, SNOWFLAKE, POSTGRES, CLICKHOUSE, MSSQL, AzureSynapse, S3Datalake, GCSDatalake, AzureDatalake, DELTALAKE}
+	WarehouseDestinations     = []string{RS, BQ, SNOWFLAKE, POSTGRES, CLICKHOUSE, MSSQL, AzureSynapse, S3Datalake, GCSDatalake, AzureDatalake, DELTALAKE, DUCKDB}
 	IdentityEnabledWarehouses = []string{SNOWFLAKE, BQ}
 	S3PathStyleRegex          = regexp.MustCompile(`https?://s3([.-](?P<region>[^.]+))?.amazonaws\.com/(?P<bucket>[^/]+)/(?P<keyname>.*)`)
 	S3VirtualHostedRegex      = regexp.MustCompile(`https?://(?P<bucket>[^/]+).s3([.-](?P<region>[^.]+))?.amazonaws\.com/(?P<keyname>.*)`)
@@ -146,6 +148,7 @@ var WHDestNameMap = map[string]string{
 	GCSDatalake:   "gcs_datalake",
 	AzureDatalake: "azure_datalake",
 	AzureSynapse:  "azure_synapse",
+	DUCKDB:        "duckdb",
 }
 
 var ObjectStorageMap = map[string]string{
@@ -604,6 +607,16 @@ func IdentityMappingsUniqueMappingConstraintName(warehouse model.Warehouse) stri
 	return fmt.Sprintf(`unique_merge_property_%s_%s`, warehouse.Namespace, warehouse.Destination.ID)
 }
 
+// MergeMode returns the merge mode of the table.
+// The users, discards and identity resolution tables have their own deduplication logic, so merge mode doesn't apply to them.
+func MergeMode(warehouse model.Warehouse, tableName string) (model.MergeMode, error) {
+	switch strings.ToLower(tableName) {
+	case UsersTable, DiscardsTable, IdentityMergeRulesTable, IdentityMappingsTable:
+		return model.MergeMode{}, nil
+	}
+	return warehouse.GetMergeMode(tableName)
+}
+
 func GetWarehouseIdentifier(destType, sourceID, destinationID string) string {
 	return fmt.Sprintf("%s:%s:%s", destType, sourceID, destinationID)
 }
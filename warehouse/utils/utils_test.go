@@ -1098,6 +1098,47 @@ func TestGetWarehouseIdentifier(t *testing.T) {
 	}
 }
 
+func TestMergeMode(t *testing.T) {
+	warehouse := model.Warehouse{
+		Destination: backendconfig.DestinationT{
+			Config: map[string]interface{}{
+				"mergeMode": map[string]interface{}{
+					"enabled":    true,
+					"primaryKey": "message_id",
+				},
+			},
+		},
+	}
+
+	inputs := []struct {
+		tableName string
+		expected  model.MergeMode
+	}{
+		{tableName: "tracks", expected: model.MergeMode{Enabled: true, PrimaryKey: "message_id"}},
+		{tableName: "USERS", expected: model.MergeMode{}},
+		{tableName: "rudder_discards", expected: model.MergeMode{}},
+		{tableName: "rudder_identity_merge_rules", expected: model.MergeMode{}},
+		{tableName: "rudder_identity_mappings", expected: model.MergeMode{}},
+	}
+	for _, input := range inputs {
+		mergeMode, err := MergeMode(warehouse, input.tableName)
+		require.NoError(t, err)
+		require.Equal(t, input.expected, mergeMode)
+	}
+
+	t.Run("malformed config", func(t *testing.T) {
+		warehouse := model.Warehouse{
+			Destination: backendconfig.DestinationT{
+				Config: map[string]interface{}{
+					"mergeMode": "enabled",
+				},
+			},
+		}
+		_, err := MergeMode(warehouse, "tracks")
+		require.Error(t, err)
+	})
+}
+
 func TestCreateAWSSessionConfig(t *testing.T) {
 	rudderAccessKeyID := "rudderAccessKeyID"
 	rudderAccessKey := "rudderAccessKey"